@@ -0,0 +1,127 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package api
+
+import (
+	"context"
+	"errors"
+
+	logger "github.com/d2r2/go-logger"
+	"github.com/d2r2/go-rsync/backup"
+	"github.com/d2r2/go-rsync/locale"
+	"github.com/d2r2/go-rsync/rsync"
+)
+
+// Notifier receive backup process events,
+// to map them to user interface of embedding program.
+type Notifier = backup.Notifier
+
+// Options keep everything required to run backup session.
+type Options struct {
+	// Backup settings, shared by all modules.
+	Config *backup.Config
+	// RSYNC sources to backup.
+	Modules []backup.Module
+	// Destination root path, where session folder is created.
+	DestPath string
+	// Session log, package log is used if not specified.
+	Log logger.PackageLog
+	// Optional backup events receiver.
+	Notifier Notifier
+	// Optional hook to recover from RSYNC errors,
+	// such as out of disk space.
+	ErrorHook rsync.ErrorHookCall
+	// Language of session log, system one if empty.
+	Language string
+}
+
+// Planner perform 1st stage (plan stage) of backup session.
+type Planner interface {
+	BuildPlan(ctx context.Context) (*backup.Plan, *backup.Progress, error)
+}
+
+// Runner perform 2nd stage (backup stage) of backup session,
+// or retry folders failed in previous session.
+type Runner interface {
+	RunBackup(plan *backup.Plan, progress *backup.Progress) error
+	RetryFailedFolders(ctx context.Context, sessionPath string) (*backup.Progress, error)
+}
+
+// Engine is a default implementation of Planner and Runner.
+type Engine struct {
+	options Options
+}
+
+// Static check that interfaces are implemented.
+var _ Planner = &Engine{}
+var _ Runner = &Engine{}
+
+// NewEngine validate options and create Engine instance.
+func NewEngine(options *Options) (*Engine, error) {
+	if options.Config == nil {
+		return nil, errors.New(locale.T(MsgEngineConfigIsEmptyError, nil))
+	}
+	if len(options.Modules) == 0 {
+		return nil, errors.New(locale.T(MsgEngineModulesAreEmptyError, nil))
+	}
+	if options.DestPath == "" {
+		return nil, errors.New(locale.T(MsgEngineDestPathIsEmptyError, nil))
+	}
+	err := rsync.IsInstalled()
+	if err != nil {
+		return nil, err
+	}
+	if options.Language != "" {
+		locale.SetLanguage(options.Language)
+	}
+	v := &Engine{options: *options}
+	if v.options.Log == nil {
+		v.options.Log = lg
+	}
+	return v, nil
+}
+
+// BuildPlan measure RSYNC sources and prepare backup plan.
+func (v *Engine) BuildPlan(ctx context.Context) (*backup.Plan, *backup.Progress, error) {
+	return backup.BuildBackupPlan(ctx, v.options.Log, v.options.Config,
+		v.options.Modules, v.options.Notifier)
+}
+
+// RunBackup perform backup according to the plan.
+func (v *Engine) RunBackup(plan *backup.Plan, progress *backup.Progress) error {
+	return plan.RunBackup(progress, v.options.DestPath, v.options.ErrorHook)
+}
+
+// RetryFailedFolders backup again folders failed in previous
+// session, which is located in sessionPath.
+func (v *Engine) RetryFailedFolders(ctx context.Context, sessionPath string) (*backup.Progress, error) {
+	progress, err := backup.RetryFailedFolders(ctx, v.options.Log, v.options.Config,
+		v.options.Modules, sessionPath, v.options.Notifier, v.options.ErrorHook)
+	progress.Close()
+	return progress, err
+}
+
+// Backup run both stages of backup session.
+// Progress returned to get session statistics.
+func (v *Engine) Backup(ctx context.Context) (*backup.Progress, error) {
+	plan, progress, err := v.BuildPlan(ctx)
+	if err != nil {
+		// Progress is returned along with interrupted plan.
+		if progress != nil {
+			progress.Close()
+		}
+		return nil, err
+	}
+	defer progress.Close()
+	err = v.RunBackup(plan, progress)
+	return progress, err
+}
@@ -0,0 +1,264 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package api
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/d2r2/go-rsync/backup"
+	"github.com/d2r2/go-rsync/core"
+	"github.com/d2r2/go-rsync/rsync"
+)
+
+// testNotifier record backup events to verify reported progress.
+type testNotifier struct {
+	sync.Mutex
+	inquired  []string
+	started   map[string]core.FolderSize
+	done      map[string]core.FolderSize
+	folders   int
+	errors    []error
+	totalDone core.FolderSize
+}
+
+func newTestNotifier() *testNotifier {
+	return &testNotifier{started: make(map[string]core.FolderSize),
+		done: make(map[string]core.FolderSize)}
+}
+
+func (v *testNotifier) NotifyPlanStage_NodeStructureStartInquiry(sourceID int,
+	sourceRsync string) error {
+	v.Lock()
+	defer v.Unlock()
+	v.inquired = append(v.inquired, sourceRsync)
+	return nil
+}
+
+func (v *testNotifier) NotifyPlanStage_NodeStructureDoneInquiry(sourceID int,
+	sourceRsync string, dir *core.Dir) error {
+	return nil
+}
+
+func (v *testNotifier) NotifyBackupStage_NodeStartBackup(sourceID int,
+	sourceRsync string, totalSize core.FolderSize) error {
+	v.Lock()
+	defer v.Unlock()
+	v.started[sourceRsync] = totalSize
+	return nil
+}
+
+func (v *testNotifier) NotifyBackupStage_NodeDoneBackup(sourceID int,
+	sourceRsync string, sizeDone core.SizeProgress, sessionErr error) error {
+	v.Lock()
+	defer v.Unlock()
+	v.done[sourceRsync] = sizeDone.GetTotal()
+	if sessionErr != nil {
+		v.errors = append(v.errors, sessionErr)
+	}
+	return nil
+}
+
+func (v *testNotifier) NotifyBackupStage_FolderStartBackup(rootDest string,
+	paths core.SrcDstPath, backupType core.FolderBackupType,
+	leftToBackup core.FolderSize, timePassed time.Duration, eta *backup.ETA) error {
+	return nil
+}
+
+func (v *testNotifier) NotifyBackupStage_FolderDoneBackup(rootDest string,
+	paths core.SrcDstPath, backupType core.FolderBackupType,
+	leftToBackup core.FolderSize, sizeDone core.SizeProgress,
+	timePassed time.Duration, eta *backup.ETA, sessionErr error) error {
+	v.Lock()
+	defer v.Unlock()
+	v.folders++
+	v.totalDone = v.totalDone.AddSizeProgress(sizeDone)
+	if sessionErr != nil {
+		v.errors = append(v.errors, sessionErr)
+	}
+	return nil
+}
+
+func (v *testNotifier) NotifyBackupStage_FolderRsyncOutput(rootDest string,
+	paths core.SrcDstPath, output []string) error {
+	return nil
+}
+
+func (v *testNotifier) NotifyBackupStage_NetworkStateChanged(sourceRsync string,
+	waiting bool) error {
+	return nil
+}
+
+// RSYNC output reported by every call of fake executor:
+// dry run report same size for each folder measured.
+const testRsyncOutput = "sent 64 bytes  received 32 bytes  0.00 bytes/sec\n" +
+	"total size is 1,000  speedup is 1.00 (DRY RUN)\n"
+
+// setupFakeRsync replace RSYNC calls with FakeExecutor,
+// and keep application files in temporary folders.
+func setupFakeRsync(t *testing.T) (*rsync.FakeExecutor, string) {
+	root, err := ioutil.TempDir("", "api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(root) })
+	t.Setenv("HOME", root)
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(root, "cache"))
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(root, "config"))
+	t.Setenv("TMPDIR", filepath.Join(root, "tmp"))
+	err = os.MkdirAll(filepath.Join(root, "tmp"), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	caps, err := rsync.ParseCapabilities("rsync  version 3.2.7  protocol version 31\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsync.SetCapabilities(caps)
+	t.Cleanup(func() { rsync.SetCapabilities(nil) })
+	fake := &rsync.FakeExecutor{StdOut: testRsyncOutput}
+	rsync.SetExecutor(fake)
+	t.Cleanup(func() { rsync.SetExecutor(&rsync.SystemExecutor{}) })
+	return fake, root
+}
+
+func TestEngineBackup(t *testing.T) {
+	fake, root := setupFakeRsync(t)
+	destPath := filepath.Join(root, "backup")
+	err := os.MkdirAll(destPath, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	modules := []backup.Module{
+		{SourceRsync: "rsync://nas/photo", DestSubPath: "photo"},
+		{SourceRsync: "rsync://nas/music", DestSubPath: "music"},
+	}
+
+	var sessions []string
+	for i := 0; i < 2; i++ {
+		// Session folder name include time up to seconds.
+		time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second)))
+		notifier := newTestNotifier()
+		engine, err := NewEngine(&Options{Config: &backup.Config{}, Modules: modules,
+			DestPath: destPath, Notifier: notifier})
+		if err != nil {
+			t.Fatal(err)
+		}
+		fake.Reset()
+		plan, progress, err := engine.BuildPlan(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !plan.IsComplete() || len(plan.Nodes) != len(modules) {
+			t.Fatalf("session %d: plan is incomplete: %d of %d modules estimated",
+				i, len(plan.Nodes), len(modules))
+		}
+		// Each source is measured as single folder with 1000 bytes content.
+		if plan.BackupSize != 2000 {
+			t.Errorf("session %d: expected backup size 2000, got %d", i, plan.BackupSize)
+		}
+		err = engine.RunBackup(plan, progress)
+		progress.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(notifier.errors) > 0 {
+			t.Errorf("session %d: errors reported: %v", i, notifier.errors)
+		}
+		for _, module := range modules {
+			if notifier.started[module.SourceRsync] != 1000 {
+				t.Errorf("session %d: expected %q backup of 1000 bytes, started %d",
+					i, module.SourceRsync, notifier.started[module.SourceRsync])
+			}
+			if notifier.done[module.SourceRsync] != 1000 {
+				t.Errorf("session %d: expected %q backup of 1000 bytes, done %d",
+					i, module.SourceRsync, notifier.done[module.SourceRsync])
+			}
+		}
+		if len(notifier.inquired) != len(modules) || notifier.folders != len(modules) ||
+			notifier.totalDone != 2000 || progress.SizeBackedUp() != 2000 {
+			t.Errorf("session %d: unexpected progress: %d sources, %d folders, %d bytes, %d bytes",
+				i, len(notifier.inquired), notifier.folders, notifier.totalDone,
+				progress.SizeBackedUp())
+		}
+
+		items, err := ioutil.ReadDir(destPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(items) != i+1 {
+			t.Fatalf("session %d: expected %d session folders, found %d", i, i+1, len(items))
+		}
+		for _, item := range items {
+			if !contains(sessions, item.Name()) {
+				sessions = append(sessions, item.Name())
+			}
+		}
+
+		// Second session deduplicate data against the first one.
+		var transfers []string
+		for _, cmd := range fake.GetCommands() {
+			line := strings.Join(cmd.Args, " ")
+			if strings.Contains(line, "--itemize-changes") && !strings.Contains(line, "--dry-run") {
+				transfers = append(transfers, line)
+			}
+		}
+		if len(transfers) != len(modules) {
+			t.Fatalf("session %d: expected transfers of %d sources, captured:\n%s",
+				i, len(modules), fake.Dump())
+		}
+		for _, line := range transfers {
+			linked := strings.Contains(line, "--link-dest="+filepath.Join(destPath, sessions[0]))
+			if linked != (i > 0) {
+				t.Errorf("session %d: unexpected deduplication in %q", i, line)
+			}
+		}
+	}
+}
+
+func TestNewEngineOptions(t *testing.T) {
+	setupFakeRsync(t)
+	modules := []backup.Module{{SourceRsync: "rsync://nas/photo"}}
+	tests := []struct {
+		name    string
+		options Options
+		valid   bool
+	}{
+		{"valid", Options{Config: &backup.Config{}, Modules: modules, DestPath: "/backup"}, true},
+		{"no config", Options{Modules: modules, DestPath: "/backup"}, false},
+		{"no modules", Options{Config: &backup.Config{}, DestPath: "/backup"}, false},
+		{"no destination", Options{Config: &backup.Config{}, Modules: modules}, false},
+	}
+	for _, test := range tests {
+		_, err := NewEngine(&test.options)
+		if (err == nil) != test.valid {
+			t.Errorf("%s: unexpected result: %v", test.name, err)
+		}
+	}
+}
+
+func contains(items []string, item string) bool {
+	for _, value := range items {
+		if value == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+// Package api provide stable public interface to Gorsync Backup engine,
+// so backup, rsync and core packages can be embedded into third-party
+// Go programs without GTK dependencies.
+//
+// Backup session consist of 2 stages: plan stage (Planner), where RSYNC
+// sources are measured and optimal traverse path is found, and backup
+// stage (Runner), where data is copied with deduplication against
+// previous backups. Engine implement both interfaces:
+//
+//	config := &backup.Config{}
+//	modules := []backup.Module{{SourceRsync: "rsync://server/data"}}
+//	engine, err := api.NewEngine(&api.Options{
+//		Config:   config,
+//		Modules:  modules,
+//		DestPath: "/mnt/backup",
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	progress, err := engine.Backup(context.Background())
+//
+// Progress events might be received implementing Notifier interface.
+// See example folder for complete program.
+package api
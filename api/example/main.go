@@ -0,0 +1,118 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+// Example demonstrate how to embed Gorsync Backup engine
+// into console program, without GTK dependencies:
+//
+//	go run ./api/example -src rsync://server/data -dest /mnt/backup
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/d2r2/go-rsync/api"
+	"github.com/d2r2/go-rsync/backup"
+	"github.com/d2r2/go-rsync/core"
+)
+
+// consoleNotifier print backup progress to standard output.
+type consoleNotifier struct{}
+
+func (v *consoleNotifier) NotifyPlanStage_NodeStructureStartInquiry(sourceID int,
+	sourceRsync string) error {
+	fmt.Printf("Measuring %s...\n", sourceRsync)
+	return nil
+}
+
+func (v *consoleNotifier) NotifyPlanStage_NodeStructureDoneInquiry(sourceID int,
+	sourceRsync string, dir *core.Dir) error {
+	fmt.Printf("Measured %s: %s\n", sourceRsync, core.GetReadableSize(dir.GetTotalSize()))
+	return nil
+}
+
+func (v *consoleNotifier) NotifyBackupStage_NodeStartBackup(sourceID int,
+	sourceRsync string, totalSize core.FolderSize) error {
+	fmt.Printf("Backing up %s...\n", sourceRsync)
+	return nil
+}
+
+func (v *consoleNotifier) NotifyBackupStage_NodeDoneBackup(sourceID int,
+	sourceRsync string, sizeDone core.SizeProgress, sessionErr error) error {
+	fmt.Printf("Done %s\n", sourceRsync)
+	return nil
+}
+
+func (v *consoleNotifier) NotifyBackupStage_FolderStartBackup(rootDest string,
+	paths core.SrcDstPath, backupType core.FolderBackupType,
+	leftToBackup core.FolderSize, timePassed time.Duration, eta *backup.ETA) error {
+	return nil
+}
+
+func (v *consoleNotifier) NotifyBackupStage_FolderDoneBackup(rootDest string,
+	paths core.SrcDstPath, backupType core.FolderBackupType,
+	leftToBackup core.FolderSize, sizeDone core.SizeProgress,
+	timePassed time.Duration, eta *backup.ETA, sessionErr error) error {
+	fmt.Printf("%s (left %s)\n", paths.RsyncSourcePath, core.GetReadableSize(leftToBackup))
+	return nil
+}
+
+func (v *consoleNotifier) NotifyBackupStage_FolderRsyncOutput(rootDest string,
+	paths core.SrcDstPath, output []string) error {
+	return nil
+}
+
+func (v *consoleNotifier) NotifyBackupStage_NetworkStateChanged(sourceRsync string,
+	waiting bool) error {
+	if waiting {
+		fmt.Printf("Waiting for network to reach %s...\n", sourceRsync)
+	}
+	return nil
+}
+
+func main() {
+	var src, dest string
+	flag.StringVar(&src, "src", "", "RSYNC source to backup.")
+	flag.StringVar(&dest, "dest", "", "Destination folder.")
+	flag.Parse()
+
+	engine, err := api.NewEngine(&api.Options{
+		Config:   &backup.Config{},
+		Modules:  []backup.Module{{SourceRsync: src}},
+		DestPath: dest,
+		Notifier: &consoleNotifier{},
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	// Terminate backup on Ctrl+C.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		cancel()
+	}()
+
+	progress, err := engine.Backup(ctx)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Backed up %s in %v\n", core.GetReadableSize(progress.SizeBackedUp()), progress.GetTotalTimeTaken())
+}
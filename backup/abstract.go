@@ -28,15 +28,32 @@ type Notifier interface {
 	NotifyPlanStage_NodeStructureDoneInquiry(sourceID int,
 		sourceRsync string, dir *core.Dir) error
 
+	// Pair of calls to report about backup start and completion
+	// of each RSYNC source in 2nd pass.
+	NotifyBackupStage_NodeStartBackup(sourceID int,
+		sourceRsync string, totalSize core.FolderSize) error
+	NotifyBackupStage_NodeDoneBackup(sourceID int,
+		sourceRsync string, sizeDone core.SizeProgress, sessionErr error) error
+
 	// Pair of calls to report about 2nd pass start and completion.
 	NotifyBackupStage_FolderStartBackup(rootDest string,
 		paths core.SrcDstPath, backupType core.FolderBackupType,
 		leftToBackup core.FolderSize,
-		timePassed time.Duration, eta *time.Duration,
+		timePassed time.Duration, eta *ETA,
 	) error
 	NotifyBackupStage_FolderDoneBackup(rootDest string,
 		paths core.SrcDstPath, backupType core.FolderBackupType,
 		leftToBackup core.FolderSize, sizeDone core.SizeProgress,
-		timePassed time.Duration, eta *time.Duration,
+		timePassed time.Duration, eta *ETA,
 		sessionErr error) error
+
+	// Report itemized output of RSYNC call, which
+	// finished piece of data backup (2nd pass).
+	NotifyBackupStage_FolderRsyncOutput(rootDest string,
+		paths core.SrcDstPath, output []string) error
+
+	// Report that backup paused waiting for network,
+	// or resumed once network restored (2nd pass).
+	NotifyBackupStage_NetworkStateChanged(sourceRsync string,
+		waiting bool) error
 }
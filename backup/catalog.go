@@ -0,0 +1,257 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package backup
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/d2r2/go-rsync/core"
+	"github.com/d2r2/go-rsync/rsync"
+)
+
+// CatalogEntry describe file backed up in the session.
+// Catalog saved in session folder as compressed JSON Lines file.
+type CatalogEntry struct {
+	// File path relative to session folder, with invalid
+	// UTF-8 bytes escaped to be displayed and searched
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mtime"`
+	// Original file path bytes, if path is not valid UTF-8
+	RawPath []byte `json:"raw_path,omitempty"`
+}
+
+func newCatalogEntry(relPath string, info os.FileInfo) CatalogEntry {
+	entry := CatalogEntry{Path: core.SanitizeUTF8(relPath),
+		Size: info.Size(), ModTime: info.ModTime()}
+	if entry.Path != relPath {
+		entry.RawPath = []byte(relPath)
+	}
+	return entry
+}
+
+// GetPath return original file path relative to session folder.
+func (v *CatalogEntry) GetPath() string {
+	if v.RawPath != nil {
+		return string(v.RawPath)
+	}
+	return v.Path
+}
+
+// CatalogMatch describe file found in backup session catalog.
+type CatalogMatch struct {
+	CatalogEntry
+	// Backup session folder, file belongs to
+	SessionPath string
+	// Comment attached to backup session, if any
+	SessionComment string
+}
+
+// CreateCatalogFile list all files backed up in the session folder,
+// and save them to catalog file there. Return number of files listed.
+func CreateCatalogFile(sessionPath string) (int, error) {
+	file, err := os.Create(filepath.Join(sessionPath, GetCatalogFileName()))
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	writer := gzip.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	count := 0
+	err = walkSessionFiles(sessionPath, func(relPath string, info os.FileInfo) error {
+		count++
+		entry := newCatalogEntry(relPath, info)
+		return encoder.Encode(&entry)
+	})
+	if err != nil {
+		return 0, err
+	}
+	err = writer.Close()
+	if err != nil {
+		return 0, err
+	}
+	return count, file.Close()
+}
+
+// walkSessionFiles call function for each file backed up
+// in the session folder, with path relative to the folder.
+// Session service files (logs, signatures and so on) are skipped,
+// as well as RSYNC partial dirs.
+func walkSessionFiles(sessionPath string, call func(relPath string, info os.FileInfo) error) error {
+	return filepath.Walk(sessionPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			// partially transferred files are not backed up yet
+			if info.Name() == rsync.PARTIAL_DIR {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		relPath, err := filepath.Rel(sessionPath, path)
+		if err != nil {
+			return err
+		}
+		if filepath.Dir(relPath) == "." && strings.HasPrefix(relPath, "~") {
+			return nil
+		}
+		return call(relPath, info)
+	})
+}
+
+// errSearchLimitReached used to stop walking session folder
+// once number of matches reach limit.
+var errSearchLimitReached = errors.New("search limit reached")
+
+// catalogQuery match file path against search query (case-insensitive).
+// Query containing wildcards (*, ?, [) is treated as file name pattern,
+// otherwise file path should contain query.
+type catalogQuery struct {
+	text    string
+	pattern bool
+}
+
+// newCatalogQuery verify and prepare query to search files with.
+func newCatalogQuery(query string) (*catalogQuery, error) {
+	query = strings.ToLower(query)
+	v := &catalogQuery{text: query, pattern: strings.ContainsAny(query, "*?[")}
+	if v.pattern {
+		_, err := filepath.Match(v.text, "")
+		if err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// match verify that file path satisfy query.
+func (v *catalogQuery) match(relPath string) bool {
+	relPath = strings.ToLower(relPath)
+	if v.pattern {
+		ok, _ := filepath.Match(v.text, filepath.Base(relPath))
+		return ok
+	}
+	return strings.Contains(relPath, v.text)
+}
+
+// searchCatalogFile return files from session catalog, which
+// satisfy query. Search stopped when number of matches reach limit.
+func searchCatalogFile(ctx context.Context, sessionPath string, query *catalogQuery,
+	limit int) ([]CatalogMatch, error) {
+
+	file, err := os.Open(filepath.Join(sessionPath, GetCatalogFileName()))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	var matches []CatalogMatch
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() && len(matches) < limit {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+		var entry CatalogEntry
+		err = json.Unmarshal(scanner.Bytes(), &entry)
+		if err != nil {
+			return nil, err
+		}
+		if query.match(entry.Path) {
+			matches = append(matches, CatalogMatch{CatalogEntry: entry,
+				SessionPath: sessionPath})
+		}
+	}
+	return matches, scanner.Err()
+}
+
+// searchSessionFolder return files, which satisfy query, walking
+// session folder. Used for sessions created without catalog.
+func searchSessionFolder(ctx context.Context, sessionPath string, query *catalogQuery,
+	limit int) ([]CatalogMatch, error) {
+
+	var matches []CatalogMatch
+	err := walkSessionFiles(sessionPath, func(relPath string, info os.FileInfo) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if len(matches) >= limit {
+			return errSearchLimitReached
+		}
+		if query.match(relPath) {
+			matches = append(matches, CatalogMatch{SessionPath: sessionPath,
+				CatalogEntry: newCatalogEntry(relPath, info)})
+		}
+		return nil
+	})
+	if err != nil && err != errSearchLimitReached {
+		return nil, err
+	}
+	return matches, nil
+}
+
+// SearchCatalogs search files, which satisfy query, in all backup sessions
+// found in destPath. Query is either part of file path, or file name pattern
+// with wildcards (both case-insensitive). Session catalog is used, if exists,
+// otherwise session folder is walked. Most recent sessions are searched first;
+// no more than limit matches returned.
+func SearchCatalogs(ctx context.Context, destPath, query string,
+	limit int) ([]CatalogMatch, error) {
+
+	q, err := newCatalogQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := ListSessions(destPath)
+	if err != nil {
+		return nil, err
+	}
+	var matches []CatalogMatch
+	for _, session := range sessions {
+		var list []CatalogMatch
+		_, err := os.Stat(filepath.Join(session.Path, GetCatalogFileName()))
+		if err == nil {
+			list, err = searchCatalogFile(ctx, session.Path, q, limit-len(matches))
+		} else {
+			list, err = searchSessionFolder(ctx, session.Path, q, limit-len(matches))
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			LocalLog.Warnf("Can't search files in %q: %v", session.Path, err)
+			continue
+		}
+		for i := range list {
+			list[i].SessionComment = session.Comment
+		}
+		matches = append(matches, list...)
+		if len(matches) >= limit {
+			break
+		}
+	}
+	return matches, nil
+}
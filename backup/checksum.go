@@ -0,0 +1,155 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package backup
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/d2r2/go-rsync/locale"
+)
+
+// Maximum number of corrupted files listed in integrity
+// verification report, the rest is included in summary only.
+const maxIntegrityIssuesReported = 50
+
+// hashFile return SHA-256 checksum of the file in hex form.
+func hashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	_, err = io.Copy(hash, file)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// CreateChecksumManifest calculate checksums of all files backed up
+// in the session folder and save them to manifest file there,
+// compatible with "sha256sum --check". Return number of files hashed.
+func CreateChecksumManifest(ctx context.Context, sessionPath string) (int, error) {
+	file, err := os.Create(filepath.Join(sessionPath, GetChecksumManifestFileName()))
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	writer := bufio.NewWriter(file)
+	count := 0
+	err = walkSessionFiles(sessionPath, func(relPath string, info os.FileInfo) error {
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		sum, err := hashFile(filepath.Join(sessionPath, relPath))
+		if err != nil {
+			return err
+		}
+		count++
+		_, err = writer.WriteString(sum + "  " + relPath + "\n")
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	err = writer.Flush()
+	if err != nil {
+		return 0, err
+	}
+	return count, file.Close()
+}
+
+// VerifySessionIntegrity re-calculate checksums of files in the session
+// folder and compare them with manifest saved in backup stage.
+// Missing and corrupted files are appended to the report.
+// Return error only if process was interrupted via context.
+func VerifySessionIntegrity(ctx context.Context, sessionPath string, report *CheckReport) error {
+	comment, err := GetSessionComment(sessionPath)
+	if err != nil {
+		LocalLog.Warnf("Can't read comment of session %q: %v", sessionPath, err)
+	} else if comment != "" {
+		report.Add(CHECK_SESSION_COMMENT, sessionPath, CheckPassed, comment)
+	}
+
+	file, err := os.Open(filepath.Join(sessionPath, GetChecksumManifestFileName()))
+	if err != nil {
+		if os.IsNotExist(err) {
+			err = errors.New(locale.T(MsgCheckIntegrityManifestNotFound,
+				struct{ Path string }{Path: sessionPath}))
+		}
+		report.Add(CHECK_INTEGRITY_MANIFEST, sessionPath, CheckFailed, err.Error())
+		return nil
+	}
+	defer file.Close()
+
+	total, failed := 0, 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		line := scanner.Text()
+		// line format: "<checksum>  <relative path>"
+		i := strings.Index(line, "  ")
+		if i == -1 {
+			continue
+		}
+		total++
+		sum, relPath := line[:i], line[i+2:]
+		var message string
+		actual, err := hashFile(filepath.Join(sessionPath, relPath))
+		if os.IsNotExist(err) {
+			message = locale.T(MsgCheckIntegrityFileMissing, nil)
+		} else if err != nil {
+			message = err.Error()
+		} else if actual != sum {
+			message = locale.T(MsgCheckIntegrityFileCorrupted, nil)
+		} else {
+			continue
+		}
+		failed++
+		if failed <= maxIntegrityIssuesReported {
+			report.Add(CHECK_INTEGRITY_FILE, relPath, CheckFailed, message)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		report.Add(CHECK_INTEGRITY_MANIFEST, sessionPath, CheckFailed, err.Error())
+		return nil
+	}
+
+	if failed > 0 {
+		report.Add(CHECK_INTEGRITY_SUMMARY, sessionPath, CheckFailed,
+			locale.TP(MsgCheckIntegritySummaryFailed,
+				struct{ FailedCount, FileCount int }{FailedCount: failed, FileCount: total}, total))
+	} else {
+		report.Add(CHECK_INTEGRITY_SUMMARY, sessionPath, CheckPassed,
+			locale.TP(MsgCheckIntegritySummaryPassed,
+				struct{ FileCount int }{FileCount: total}, total))
+	}
+	return nil
+}
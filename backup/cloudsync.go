@@ -0,0 +1,128 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package backup
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	logger "github.com/d2r2/go-logger"
+	"github.com/d2r2/go-rsync/core"
+	"github.com/d2r2/go-rsync/locale"
+	shell "github.com/d2r2/go-shell"
+)
+
+const (
+	RCLONE_APP_CMD = "rclone"
+	// Interval rclone print transfer statistics to session log.
+	CLOUD_SYNC_STATS_INTERVAL = 30 * time.Second
+)
+
+// CloudSync describe rclone remote and path, where completed
+// backup session folder is mirrored to after backup.
+// Remote should be configured in advance with "rclone config".
+type CloudSync struct {
+	Remote string
+	Path   string
+}
+
+// NewCloudSync verify rclone remote specification and create CloudSync object.
+func NewCloudSync(remote, remotePath string) (*CloudSync, error) {
+	remote = strings.TrimSuffix(strings.TrimSpace(remote), ":")
+	if remote == "" {
+		return nil, errors.New(locale.T(MsgLogCloudSyncRemoteIsEmptyError, nil))
+	}
+	v := &CloudSync{Remote: remote, Path: strings.Trim(strings.TrimSpace(remotePath), "/")}
+	return v, nil
+}
+
+// GetDestination return rclone destination, which session folder mirrored to.
+func (v *CloudSync) GetDestination(sessionFolder string) string {
+	return v.Remote + ":" + path.Join(v.Path, sessionFolder)
+}
+
+// trimRcloneLogPrefix remove date and time rclone put
+// in front of each log line: "2006/01/02 15:04:05 NOTICE: ...".
+func trimRcloneLogPrefix(line string) string {
+	fields := strings.SplitN(line, " ", 3)
+	if len(fields) == 3 {
+		if _, err := time.Parse("2006/01/02 15:04:05", fields[0]+" "+fields[1]); err == nil {
+			return fields[2]
+		}
+	}
+	return line
+}
+
+// Run mirror backup session folder to cloud storage with rclone.
+// Transfer statistics reported by rclone are written to session log.
+// Session folder is expected to be complete, so it is never
+// modified here: any failure affect cloud copy only.
+func (v *CloudSync) Run(ctx context.Context, sessionPath string, log logger.PackageLog) error {
+	if shell.NewApp(RCLONE_APP_CMD).CheckIsInstalled() != nil {
+		return errors.New(locale.T(MsgLogCloudSyncAppNotFoundError,
+			struct{ Command string }{Command: RCLONE_APP_CMD}))
+	}
+
+	destination := v.GetDestination(filepath.Base(sessionPath))
+	log.Info(locale.T(MsgLogCloudSyncStarting,
+		struct{ Path, Destination string }{Path: sessionPath, Destination: destination}))
+	startTime := time.Now()
+
+	cmd := exec.CommandContext(ctx, RCLONE_APP_CMD, "sync", sessionPath, destination,
+		"--stats", CLOUD_SYNC_STATS_INTERVAL.String(), "--stats-one-line",
+		"--stats-log-level", "NOTICE")
+	stdErr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+	err = cmd.Start()
+	if err != nil {
+		return err
+	}
+	// rclone write both statistics and errors to stderr
+	var lastLine string
+	scanner := bufio.NewScanner(stdErr)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		lastLine = trimRcloneLogPrefix(line)
+		log.Info(locale.T(MsgLogCloudSyncProgress,
+			struct{ Line string }{Line: lastLine}))
+	}
+	err = cmd.Wait()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return errors.New(locale.T(MsgLogCloudSyncCommandFailedError,
+				struct {
+					ExitCode int
+					Output   string
+				}{ExitCode: exitErr.ExitCode(), Output: lastLine}))
+		}
+		return err
+	}
+
+	log.Info(locale.T(MsgLogCloudSyncCompleted,
+		struct{ Destination, Elapsed string }{Destination: destination,
+			Elapsed: core.FormatDurationToDaysHoursMinsSecs(time.Since(startTime), true, nil)}))
+	return nil
+}
@@ -0,0 +1,84 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package backup
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// readSessionSignatures decode signature file of backup session.
+func readSessionSignatures(sessionPath string) (*NodeSignatures, error) {
+	file, err := os.Open(filepath.Join(sessionPath, GetMetadataSignatureFileName()))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	// Signatures are encoded to single (possibly long) line.
+	scanner.Buffer(nil, 64*1024*1024)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return &NodeSignatures{}, nil
+	}
+	return DecodeSignatures(scanner.Text())
+}
+
+// GetSessionComment return comment attached to backup session,
+// which is kept in session signature file.
+func GetSessionComment(sessionPath string) (string, error) {
+	signs, err := readSessionSignatures(sessionPath)
+	if err != nil {
+		return "", err
+	}
+	return signs.Comment, nil
+}
+
+// SetSessionComment attach free-text comment to backup session,
+// replacing previous one. Empty comment remove it.
+func SetSessionComment(sessionPath, comment string) error {
+	signs, err := readSessionSignatures(sessionPath)
+	if err != nil {
+		return err
+	}
+	signs.Comment = strings.TrimSpace(comment)
+	v, err := EncodeSignatures(*signs)
+	if err != nil {
+		return err
+	}
+	// Replace file atomically, since signature file identify
+	// completed session, so it should never be left broken.
+	fileName := filepath.Join(sessionPath, GetMetadataSignatureFileName())
+	tempFileName := fileName + ".tmp"
+	err = ioutil.WriteFile(tempFileName, []byte(v), 0644)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tempFileName, fileName)
+}
+
+// SetSessionsComment attach the same comment to session folders
+// located in different destination roots.
+func SetSessionsComment(sessionPaths []string, comment string) error {
+	for _, path := range sessionPaths {
+		err := SetSessionComment(path, comment)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
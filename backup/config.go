@@ -12,9 +12,17 @@
 package backup
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
+	logger "github.com/d2r2/go-logger"
 	"github.com/d2r2/go-rsync/core"
+	"github.com/d2r2/go-rsync/locale"
 	"github.com/d2r2/go-rsync/rsync"
 )
 
@@ -36,6 +44,13 @@ type Plan struct {
 	Config     *Config
 	Nodes      []Node
 	BackupSize core.FolderSize
+	// Modules not estimated yet, since plan stage was interrupted.
+	Pending []Module
+}
+
+// IsComplete verify that all modules are estimated.
+func (v *Plan) IsComplete() bool {
+	return len(v.Pending) == 0
 }
 
 // GetModules returns all RSYNC source/destination blocks
@@ -48,6 +63,37 @@ func (v *Plan) GetModules() []Module {
 	return modules
 }
 
+// ModuleErrorPolicy define how to proceed, when backup
+// of RSYNC source failed with critical error.
+type ModuleErrorPolicy string
+
+const (
+	// Terminate backup session.
+	MODULE_ERROR_POLICY_ABORT ModuleErrorPolicy = "abort"
+	// Skip failed RSYNC source and continue with next one.
+	MODULE_ERROR_POLICY_SKIP ModuleErrorPolicy = "skip"
+	// Ask user interactively what to do.
+	MODULE_ERROR_POLICY_ASK ModuleErrorPolicy = "ask"
+)
+
+// LogVerbosity define what backup session log records.
+type LogVerbosity string
+
+const (
+	// Errors and warnings only.
+	LOG_VERBOSITY_QUIET LogVerbosity = "quiet"
+	// Backup stages and RSYNC sources, without per-folder lines.
+	LOG_VERBOSITY_NORMAL LogVerbosity = "normal"
+	// Each folder backed up in addition to normal output.
+	LOG_VERBOSITY_VERBOSE LogVerbosity = "verbose"
+	// Verbose output along with debug lines.
+	LOG_VERBOSITY_DEBUG LogVerbosity = "debug"
+)
+
+// ModuleErrorHookCall is a delegate used to ask user, whether RSYNC source
+// failed with critical error should be skipped to continue backup session.
+type ModuleErrorHookCall func(sourceRsync string, err error) (skip bool)
+
 // Config keeps backup session configuration.
 // Config instance is initialized mainly from
 // GLIB GSettings in ui/gtkui package.
@@ -60,6 +106,7 @@ type Config struct {
 	NumberOfPreviousBackupToUse        *int   `toml:"number_of_previous_backup_to_use"`
 	EnableLowLevelLogForRsync          *bool  `toml:"enable_low_level_log_rsync"`
 	EnableIntensiveLowLevelLogForRsync *bool  `toml:"enable_intensive_low_level_log_rsync"`
+	BuildDirTreeInMemory               *bool  `toml:"build_dir_tree_in_memory"`
 
 	RsyncTransferSourceOwner       *bool `toml:"rsync_transfer_source_owner"`       // rsync --owner
 	RsyncTransferSourceGroup       *bool `toml:"rsync_transfer_source_group"`       // rsync --group
@@ -67,8 +114,72 @@ type Config struct {
 	RsyncRecreateSymlinks          *bool `toml:"rsync_recreate_symlinks"`           // rsync --links
 	RsyncTransferDeviceFiles       *bool `toml:"rsync_transfer_device_files"`       // rsync --devices
 	RsyncTransferSpecialFiles      *bool `toml:"rsync_transfer_special_files"`      // rsync --specials
+	RsyncTransferACLs              *bool `toml:"rsync_transfer_acls"`               // rsync --acls
+	RsyncTransferXattrs            *bool `toml:"rsync_transfer_xattrs"`             // rsync --xattrs
 	RsyncCompressFileTransfer      *bool `toml:"rsync_compress_file_transfer"`      // rsync --compress
 
+	RsyncCompressChoice *string `toml:"rsync_compress_choice"` // rsync --compress-choice
+	RsyncCompressLevel  *int    `toml:"rsync_compress_level"`  // rsync --compress-level
+
+	RsyncPartialTransfer *bool `toml:"rsync_partial_transfer"` // rsync --partial --partial-dir
+	RsyncSparseFiles     *bool `toml:"rsync_sparse_files"`     // rsync --sparse
+	RsyncInplace         *bool `toml:"rsync_inplace"`          // rsync --inplace
+
+	SessionLogFormat    *string `toml:"session_log_format"`      // text or json
+	SessionLogVerbosity *string `toml:"session_log_verbosity"`   // quiet, normal, verbose or debug
+	LogSegmentMaxSizeMb *int    `toml:"log_segment_max_size_mb"` // 0 to disable log segmentation
+
+	PlanStageTempPath *string `toml:"plan_stage_temp_path"` // empty for system default
+	IncrementalPlan   *bool   `toml:"incremental_plan"`     // reuse metrics of unchanged folders
+	PlanTraceEnabled  *bool   `toml:"plan_trace_enabled"`   // explain folder backup types in plan trace file
+
+	GenerateCatalog   *bool `toml:"generate_catalog"`   // list backed up files in session folder
+	GenerateChecksums *bool `toml:"generate_checksums"` // save checksum manifest in session folder
+
+	NetworkWatchdogEnabled  *bool `toml:"network_watchdog_enabled"`    // pause on network outage
+	NetworkOutageMaxWaitMin *int  `toml:"network_outage_max_wait_min"` // 0 to wait until terminated
+
+	RsyncHangTimeoutMin *int `toml:"rsync_hang_timeout_min"` // kill silent RSYNC process, 0 to disable
+
+	RsyncIOTimeoutSec      *int `toml:"rsync_io_timeout_sec"`      // rsync --timeout, 0 to disable
+	RsyncConnectTimeoutSec *int `toml:"rsync_connect_timeout_sec"` // rsync --contimeout, 0 to disable
+
+	RsyncNiceLevel *int    `toml:"rsync_nice_level"` // nice -n, 0 to keep default
+	RsyncIOClass   *string `toml:"rsync_io_class"`   // ionice -c: default, best-effort or idle
+	RsyncIOLevel   *int    `toml:"rsync_io_level"`   // ionice -n, best-effort class only
+
+	ModuleErrorPolicy *string `toml:"module_error_policy"` // abort, skip or ask
+
+	QuotaSizeGb *int    `toml:"quota_size_gb"` // destination usage budget, 0 to disable
+	QuotaPolicy *string `toml:"quota_policy"`  // warn, prune or abort
+
+	SafeDeleteEnabled   *bool `toml:"safe_delete_enabled"`     // dry-run --delete against previous backup
+	SafeDeleteMaxFiles  *int  `toml:"safe_delete_max_files"`   // 0 to disable file count threshold
+	SafeDeleteMaxSizeMb *int  `toml:"safe_delete_max_size_mb"` // 0 to disable size threshold
+	// Called to confirm backup of RSYNC source, which lost too many
+	// files since previous backup; if not specified, source fails.
+	SafeDeleteHook SafeDeleteHookCall `toml:"-"`
+	// Called to resolve RSYNC source critical error with "ask" policy;
+	// if not specified, backup session is terminated.
+	ModuleErrorHook ModuleErrorHookCall `toml:"-"`
+	// Called to confirm override of destination lock left by stale
+	// session or session on another host; if not specified, stale
+	// lock is overridden, otherwise backup fails.
+	DestLockHook DestLockHookCall `toml:"-"`
+	// Closed to stop backup session gracefully: RSYNC call in progress
+	// is completed, but no next folder block is started.
+	GracefulStop <-chan struct{} `toml:"-"`
+	// Closed to interrupt plan stage: estimation of current module
+	// is cancelled, but modules estimated so far are kept in plan.
+	PlanStageStop <-chan struct{} `toml:"-"`
+
+	// RSYNC performance metrics obtained from previous backup sessions,
+	// indexed by source identifier. Used to tune backup block size.
+	ModuleStatistics map[string]ModuleStatistics `toml:"-"`
+
+	// Profile backup session run for, assigned by caller.
+	Profile ProfileIdentity `toml:"-"`
+
 	// BackupNode list contain all RSYNC sources to backup in one session.
 	//Modules []Module `toml:"backup_module"`
 }
@@ -100,6 +211,166 @@ func (conf *Config) numberOfPreviousBackupToUse() int {
 	return numberOfPreviousBackupToUse
 }
 
+func (conf *Config) buildDirTreeInMemory() bool {
+	var buildDirTreeInMemory = false
+	if conf.BuildDirTreeInMemory != nil {
+		buildDirTreeInMemory = *conf.BuildDirTreeInMemory
+	}
+	return buildDirTreeInMemory
+}
+
+func (conf *Config) planStageTempPath() string {
+	var planStageTempPath string
+	if conf.PlanStageTempPath != nil {
+		planStageTempPath = *conf.PlanStageTempPath
+	}
+	return planStageTempPath
+}
+
+func (conf *Config) incrementalPlanEnabled() bool {
+	var incrementalPlan = false
+	if conf.IncrementalPlan != nil {
+		incrementalPlan = *conf.IncrementalPlan
+	}
+	return incrementalPlan
+}
+
+func (conf *Config) planTraceEnabled() bool {
+	var planTrace = false
+	if conf.PlanTraceEnabled != nil {
+		planTrace = *conf.PlanTraceEnabled
+	}
+	return planTrace
+}
+
+func (conf *Config) generateCatalog() bool {
+	var generateCatalog = false
+	if conf.GenerateCatalog != nil {
+		generateCatalog = *conf.GenerateCatalog
+	}
+	return generateCatalog
+}
+
+func (conf *Config) partialTransferEnabled() bool {
+	var partialTransfer = false
+	if conf.RsyncPartialTransfer != nil {
+		partialTransfer = *conf.RsyncPartialTransfer
+	}
+	return partialTransfer
+}
+
+func (conf *Config) sparseFilesEnabled() bool {
+	var sparseFiles = false
+	if conf.RsyncSparseFiles != nil {
+		sparseFiles = *conf.RsyncSparseFiles
+	}
+	return sparseFiles
+}
+
+func (conf *Config) inplaceEnabled() bool {
+	var inplace = false
+	if conf.RsyncInplace != nil {
+		inplace = *conf.RsyncInplace
+	}
+	return inplace
+}
+
+// CheckTransferOptions verify that RSYNC transfer options
+// are not mutually exclusive for installed RSYNC release.
+// Capabilities might be nil, if RSYNC version is unknown.
+func (conf *Config) CheckTransferOptions(caps *rsync.Capabilities) error {
+	if conf.inplaceEnabled() && conf.partialTransferEnabled() {
+		return errors.New(locale.T(MsgLogPlanStageOptionsConflict,
+			struct{ Option1, Option2 string }{Option1: "--inplace", Option2: "--partial-dir"}))
+	}
+	if conf.inplaceEnabled() && conf.sparseFilesEnabled() &&
+		caps != nil && !caps.SupportsSparseInplace() {
+		return errors.New(locale.T(MsgLogPlanStageOptionsVersionConflict,
+			struct{ Option1, Option2, Version string }{Option1: "--sparse",
+				Option2: "--inplace", Version: caps.Version}))
+	}
+	return nil
+}
+
+func (conf *Config) generateChecksums() bool {
+	var generateChecksums = false
+	if conf.GenerateChecksums != nil {
+		generateChecksums = *conf.GenerateChecksums
+	}
+	return generateChecksums
+}
+
+func (conf *Config) networkWatchdogEnabled() bool {
+	var networkWatchdogEnabled = true
+	if conf.NetworkWatchdogEnabled != nil {
+		networkWatchdogEnabled = *conf.NetworkWatchdogEnabled
+	}
+	return networkWatchdogEnabled
+}
+
+func (conf *Config) networkOutageMaxWait() time.Duration {
+	var networkOutageMaxWaitMin = 60
+	if conf.NetworkOutageMaxWaitMin != nil {
+		networkOutageMaxWaitMin = *conf.NetworkOutageMaxWaitMin
+	}
+	return time.Duration(networkOutageMaxWaitMin) * time.Minute
+}
+
+func (conf *Config) rsyncHangTimeout() time.Duration {
+	var rsyncHangTimeoutMin = 30
+	if conf.RsyncHangTimeoutMin != nil {
+		rsyncHangTimeoutMin = *conf.RsyncHangTimeoutMin
+	}
+	return time.Duration(rsyncHangTimeoutMin) * time.Minute
+}
+
+// getRsyncTimeouts return RSYNC I/O and connection timeouts,
+// where module settings override global ones, if specified.
+func (conf *Config) getRsyncTimeouts(module *Module) (ioTimeout, connectTimeout time.Duration) {
+	var ioTimeoutSec, connectTimeoutSec int
+	if conf.RsyncIOTimeoutSec != nil {
+		ioTimeoutSec = *conf.RsyncIOTimeoutSec
+	}
+	if conf.RsyncConnectTimeoutSec != nil {
+		connectTimeoutSec = *conf.RsyncConnectTimeoutSec
+	}
+	if module != nil {
+		if module.RsyncIOTimeoutSec > 0 {
+			ioTimeoutSec = module.RsyncIOTimeoutSec
+		}
+		if module.RsyncConnectTimeoutSec > 0 {
+			connectTimeoutSec = module.RsyncConnectTimeoutSec
+		}
+	}
+	return time.Duration(ioTimeoutSec) * time.Second,
+		time.Duration(connectTimeoutSec) * time.Second
+}
+
+// getRsyncPriority return CPU and I/O priority to launch RSYNC process,
+// where module settings override global ones, if specified.
+func (conf *Config) getRsyncPriority(module *Module) *rsync.Priority {
+	priority := &rsync.Priority{}
+	if conf.RsyncNiceLevel != nil {
+		priority.Nice = *conf.RsyncNiceLevel
+	}
+	if conf.RsyncIOClass != nil {
+		priority.IOClass = *conf.RsyncIOClass
+	}
+	if conf.RsyncIOLevel != nil {
+		priority.IOLevel = *conf.RsyncIOLevel
+	}
+	if module != nil {
+		if module.RsyncNiceLevel > 0 {
+			priority.Nice = module.RsyncNiceLevel
+		}
+		if module.RsyncIOClass != "" {
+			priority.IOClass = module.RsyncIOClass
+			priority.IOLevel = module.RsyncIOLevel
+		}
+	}
+	return priority
+}
+
 func (conf *Config) getRsyncLoggingSettings() *rsync.Logging {
 	logging := &rsync.Logging{}
 	if conf.EnableLowLevelLogForRsync != nil {
@@ -111,8 +382,133 @@ func (conf *Config) getRsyncLoggingSettings() *rsync.Logging {
 	return logging
 }
 
-func (conf *Config) getBackupBlockSizeSettings() *backupBlockSizeSettings {
+func (conf *Config) safeDeleteEnabled() bool {
+	var safeDelete = false
+	if conf.SafeDeleteEnabled != nil {
+		safeDelete = *conf.SafeDeleteEnabled
+	}
+	return safeDelete
+}
+
+// getSafeDeleteThresholds return number and size of files deleted
+// in source since previous backup, which require confirmation to continue.
+func (conf *Config) getSafeDeleteThresholds() (maxFiles int, maxSize core.FolderSize) {
+	maxFiles = 1000
+	if conf.SafeDeleteMaxFiles != nil {
+		maxFiles = *conf.SafeDeleteMaxFiles
+	}
+	var maxSizeMb = 1024
+	if conf.SafeDeleteMaxSizeMb != nil {
+		maxSizeMb = *conf.SafeDeleteMaxSizeMb
+	}
+	maxSize = core.NewFolderSize(int64(maxSizeMb) * core.MB)
+	return maxFiles, maxSize
+}
+
+func (conf *Config) getModuleErrorPolicy() ModuleErrorPolicy {
+	if conf.ModuleErrorPolicy != nil {
+		switch policy := ModuleErrorPolicy(*conf.ModuleErrorPolicy); policy {
+		case MODULE_ERROR_POLICY_SKIP, MODULE_ERROR_POLICY_ASK:
+			return policy
+		}
+	}
+	return MODULE_ERROR_POLICY_ABORT
+}
+
+// getQuotaSize return maximum total size of backup sessions
+// in destination, or zero if quota is disabled.
+func (conf *Config) getQuotaSize() uint64 {
+	if conf.QuotaSizeGb != nil && *conf.QuotaSizeGb > 0 {
+		return uint64(*conf.QuotaSizeGb) * core.GB
+	}
+	return 0
+}
+
+func (conf *Config) getQuotaPolicy() QuotaPolicy {
+	if conf.QuotaPolicy != nil {
+		switch policy := QuotaPolicy(*conf.QuotaPolicy); policy {
+		case QUOTA_POLICY_PRUNE, QUOTA_POLICY_ABORT:
+			return policy
+		}
+	}
+	return QUOTA_POLICY_WARN
+}
+
+// gracefulStopRequested verify that backup session should be
+// stopped before next folder block.
+func (conf *Config) gracefulStopRequested() bool {
+	if conf.GracefulStop == nil {
+		return false
+	}
+	select {
+	case <-conf.GracefulStop:
+		return true
+	default:
+		return false
+	}
+}
+
+// planStageContext return context cancelled either with parent
+// one, or once plan stage interruption is requested.
+func (conf *Config) planStageContext(ctx context.Context) (context.Context, func()) {
+	planCtx, cancel := context.WithCancel(ctx)
+	if conf.PlanStageStop != nil {
+		go func() {
+			select {
+			case <-conf.PlanStageStop:
+				cancel()
+			case <-planCtx.Done():
+			}
+		}()
+	}
+	return planCtx, cancel
+}
+
+func (conf *Config) getSessionLogFormat() core.LogFormat {
+	if conf.SessionLogFormat != nil && core.LogFormat(*conf.SessionLogFormat) == core.LOG_FORMAT_JSON {
+		return core.LOG_FORMAT_JSON
+	}
+	return core.LOG_FORMAT_TEXT
+}
+
+func (conf *Config) getSessionLogVerbosity() LogVerbosity {
+	if conf.SessionLogVerbosity != nil {
+		switch verbosity := LogVerbosity(*conf.SessionLogVerbosity); verbosity {
+		case LOG_VERBOSITY_QUIET, LOG_VERBOSITY_NORMAL, LOG_VERBOSITY_DEBUG:
+			return verbosity
+		}
+	}
+	return LOG_VERBOSITY_VERBOSE
+}
+
+// getSessionLogLevel return maximum level of lines written to backup
+// session log, and whether per-folder lines are recorded.
+func (conf *Config) getSessionLogLevel() (logger.LogLevel, bool) {
+	switch conf.getSessionLogVerbosity() {
+	case LOG_VERBOSITY_QUIET:
+		return logger.WarnLevel, false
+	case LOG_VERBOSITY_NORMAL:
+		return logger.InfoLevel, false
+	case LOG_VERBOSITY_DEBUG:
+		return logger.DebugLevel, true
+	default:
+		return logger.InfoLevel, true
+	}
+}
+
+func (conf *Config) logSegmentMaxSize() int64 {
+	var logSegmentMaxSizeMb = 100
+	if conf.LogSegmentMaxSizeMb != nil {
+		logSegmentMaxSizeMb = *conf.LogSegmentMaxSizeMb
+	}
+	return int64(logSegmentMaxSizeMb) * 1024 * 1024
+}
+
+func (conf *Config) getBackupBlockSizeSettings(module *Module) *backupBlockSizeSettings {
 	blockSize := &backupBlockSizeSettings{AutoManageBackupBlockSize: true, BackupBlockSize: 500}
+	if stats, ok := conf.ModuleStatistics[GenerateSourceID(module.SourceRsync)]; ok {
+		blockSize.Statistics = &stats
+	}
 	if conf.AutoManageBackupBlockSize != nil {
 		blockSize.AutoManageBackupBlockSize = *conf.AutoManageBackupBlockSize
 	}
@@ -128,6 +524,9 @@ func (conf *Config) getBackupBlockSizeSettings() *backupBlockSizeSettings {
 type Module struct {
 	SourceRsync string `toml:"src_rsync"`
 	DestSubPath string `toml:"dst_subpath"`
+	// Destination root path to use instead of profile one,
+	// empty to backup along with other modules.
+	DestRootPath string `toml:"dst_root_path"`
 
 	ChangeFilePermission string  `toml:"rsync_change_file_permission"`
 	AuthPassword         *string `toml:"module_auth_password"`
@@ -138,6 +537,187 @@ type Module struct {
 	RsyncRecreateSymlinks          *bool `toml:"rsync_recreate_symlinks"`           // rsync --links
 	RsyncTransferDeviceFiles       *bool `toml:"rsync_transfer_device_files"`       // rsync --devices
 	RsyncTransferSpecialFiles      *bool `toml:"rsync_transfer_special_files"`      // rsync --specials
+	RsyncTransferACLs              *bool `toml:"rsync_transfer_acls"`               // rsync --acls
+	RsyncTransferXattrs            *bool `toml:"rsync_transfer_xattrs"`             // rsync --xattrs
+
+	MaxFileSizeMb  int `toml:"max_file_size_mb"`  // rsync --max-size
+	MaxFileAgeDays int `toml:"max_file_age_days"` // skip files older than N days
+	MinFileAgeDays int `toml:"min_file_age_days"` // skip files newer than N days
+
+	ExcludePatterns []string `toml:"exclude_patterns"` // rsync --exclude
+
+	UserMap  []string `toml:"rsync_usermap"`  // rsync --usermap, FROM:TO pairs
+	GroupMap []string `toml:"rsync_groupmap"` // rsync --groupmap, FROM:TO pairs
+	Chown    string   `toml:"rsync_chown"`    // rsync --chown, USER:GROUP
+
+	SourceCharset string `toml:"source_charset"` // rsync --iconv, empty if source use UTF-8
+
+	// Previous RSYNC source URLs of the module, to find backup
+	// sessions for deduplication made before source URL changed.
+	SourceAliases []string `toml:"src_rsync_aliases"`
+
+	RsyncIOTimeoutSec      int `toml:"rsync_io_timeout_sec"`      // rsync --timeout, 0 to inherit
+	RsyncConnectTimeoutSec int `toml:"rsync_connect_timeout_sec"` // rsync --contimeout, 0 to inherit
+
+	RsyncNiceLevel int    `toml:"rsync_nice_level"` // nice -n, 0 to inherit
+	RsyncIOClass   string `toml:"rsync_io_class"`   // ionice -c, empty to inherit
+	RsyncIOLevel   int    `toml:"rsync_io_level"`   // ionice -n, along with RsyncIOClass
+
+	// Skip plan stage estimation and backup module
+	// with single recursive RSYNC call.
+	SkipPlanEstimation bool `toml:"skip_plan_estimation"`
+
+	// Tags used to run only part of profile modules.
+	Tags []string `toml:"tags"`
+}
+
+// HasAnyTag verify that module is tagged with any of tags specified.
+func (module *Module) HasAnyTag(tags []string) bool {
+	for _, tag := range tags {
+		for _, item := range module.Tags {
+			if strings.EqualFold(item, tag) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ParseModuleTags split comma separated list of tags,
+// skipping empty and duplicate ones. Tags are case insensitive.
+func ParseModuleTags(str string) []string {
+	var tags []string
+	found := make(map[string]bool)
+	for _, item := range strings.Split(str, ",") {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if item != "" && !found[item] {
+			found[item] = true
+			tags = append(tags, item)
+		}
+	}
+	return tags
+}
+
+// ParseSourceAliases split list of previous RSYNC source URLs
+// separated by semicolon, skipping empty entries.
+func ParseSourceAliases(str string) []string {
+	var aliases []string
+	for _, item := range strings.Split(str, ";") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			aliases = append(aliases, item)
+		}
+	}
+	return aliases
+}
+
+// CollectModuleTags return sorted list of tags used by modules.
+func CollectModuleTags(modules []Module) []string {
+	var tags []string
+	found := make(map[string]bool)
+	for _, module := range modules {
+		for _, tag := range module.Tags {
+			tag = strings.ToLower(tag)
+			if !found[tag] {
+				found[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// FilterModulesByTags return modules tagged with any of tags specified.
+// All modules are returned, if no tags specified.
+func FilterModulesByTags(modules []Module, tags []string) []Module {
+	if len(tags) == 0 {
+		return modules
+	}
+	var filtered []Module
+	for _, module := range modules {
+		if module.HasAnyTag(tags) {
+			filtered = append(filtered, module)
+		}
+	}
+	return filtered
+}
+
+// GetDestRoot return destination root path, where module is backed up:
+// either module own root, or profile destination root specified by destPath.
+func (module *Module) GetDestRoot(destPath string) string {
+	if module.DestRootPath == "" ||
+		filepath.Clean(module.DestRootPath) == filepath.Clean(destPath) {
+		return destPath
+	}
+	return filepath.Clean(module.DestRootPath)
+}
+
+// GroupModulesByDestRoot split modules by destination root paths.
+// Profile destination root specified by destPath always come first,
+// even if all modules override it.
+func GroupModulesByDestRoot(destPath string, modules []Module) ([]string, map[string][]Module) {
+	roots := []string{destPath}
+	groups := map[string][]Module{destPath: nil}
+	for _, module := range modules {
+		root := module.GetDestRoot(destPath)
+		if _, ok := groups[root]; !ok {
+			roots = append(roots, root)
+		}
+		groups[root] = append(groups[root], module)
+	}
+	return roots, groups
+}
+
+// GetFileFilter return files filter by patterns, size and age, or nil if not specified.
+func (module *Module) GetFileFilter() *rsync.FileFilter {
+	filter := &rsync.FileFilter{MaxSizeMb: module.MaxFileSizeMb,
+		MaxAgeDays: module.MaxFileAgeDays, MinAgeDays: module.MinFileAgeDays,
+		Excludes: module.ExcludePatterns}
+	if filter.IsEmpty() {
+		return nil
+	}
+	return filter
+}
+
+// canonizeModules convert RSYNC daemon addresses of modules to the single
+// "rsync://" notation, so the same source is identified consistently
+// across plan stage, backup stage and retry.
+func canonizeModules(modules []Module) ([]Module, error) {
+	list := make([]Module, len(modules))
+	for i, module := range modules {
+		sourceRsync, err := core.CanonicalRsyncPath(module.SourceRsync)
+		if err != nil {
+			return nil, err
+		}
+		module.SourceRsync = sourceRsync
+		list[i] = module
+	}
+	return list, nil
+}
+
+// transferACLs verify that ACLs should be preserved for module (rsync --acls).
+func (conf *Config) transferACLs(module *Module) bool {
+	return module.RsyncTransferACLs != nil && *module.RsyncTransferACLs ||
+		module.RsyncTransferACLs == nil && conf.RsyncTransferACLs != nil &&
+			*conf.RsyncTransferACLs
+}
+
+// transferXattrs verify that extended attributes should be
+// preserved for module (rsync --xattrs).
+func (conf *Config) transferXattrs(module *Module) bool {
+	return module.RsyncTransferXattrs != nil && *module.RsyncTransferXattrs ||
+		module.RsyncTransferXattrs == nil && conf.RsyncTransferXattrs != nil &&
+			*conf.RsyncTransferXattrs
+}
+
+// getCharsetParams return RSYNC options to convert file names
+// from charset of the source to UTF-8, if specified.
+func (module *Module) getCharsetParams() []string {
+	if module.SourceCharset == "" {
+		return nil
+	}
+	return rsync.GetIconvParams(module.SourceCharset)
 }
 
 // GetRsyncParams prepare RSYNC CLI parameters to run console RSYNC process.
@@ -173,13 +753,52 @@ func GetRsyncParams(conf *Config, module *Module, addExtraParams []string) []str
 			*conf.RsyncTransferSpecialFiles {
 		params = append(params, "--specials")
 	}
+	if conf.transferACLs(module) {
+		params = append(params, "--acls")
+	}
+	if conf.transferXattrs(module) {
+		params = append(params, "--xattrs")
+	}
 	if conf.RsyncCompressFileTransfer != nil && *conf.RsyncCompressFileTransfer {
-		params = append(params, "--compress")
+		var choice string
+		if conf.RsyncCompressChoice != nil {
+			choice = *conf.RsyncCompressChoice
+		}
+		var level int
+		if conf.RsyncCompressLevel != nil {
+			level = *conf.RsyncCompressLevel
+		}
+		params = append(params, rsync.GetCompressParams(choice, level)...)
+	}
+	if conf.partialTransferEnabled() {
+		params = append(params, rsync.GetPartialParams()...)
+	}
+	if conf.sparseFilesEnabled() {
+		params = append(params, "--sparse")
+	}
+	if conf.inplaceEnabled() {
+		params = append(params, "--inplace")
 	}
 	if module.ChangeFilePermission != "" {
 		params = append(params, fmt.Sprintf("--chmod=%s", module.ChangeFilePermission))
 	}
+	// RSYNC doesn't allow to combine --chown with --usermap/--groupmap,
+	// so forced ownership take precedence over mapping.
+	if module.Chown != "" {
+		params = append(params, fmt.Sprintf("--chown=%s", module.Chown))
+	} else {
+		if len(module.UserMap) > 0 {
+			params = append(params, fmt.Sprintf("--usermap=%s", strings.Join(module.UserMap, ",")))
+		}
+		if len(module.GroupMap) > 0 {
+			params = append(params, fmt.Sprintf("--groupmap=%s", strings.Join(module.GroupMap, ",")))
+		}
+	}
+
+	params = append(params, module.getCharsetParams()...)
 
 	params = append(params, addExtraParams...)
+	// Drop or replace options unsupported by installed RSYNC release.
+	params = rsync.AdjustParamsToCapabilities(params)
 	return params
 }
@@ -0,0 +1,103 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package backup
+
+import (
+	"context"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/d2r2/go-rsync/core"
+	"github.com/d2r2/go-rsync/rsync"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+const testRsyncVersionOutput = `rsync  version 3.2.7  protocol version 31
+Capabilities:
+    64-bit files, 64-bit inums, 64-bit timestamps, 64-bit long ints,
+    socketpairs, symlinks, symtimes, hardlinks, hardlink-specials,
+    hardlink-symlinks, IPv6, atimes, batchfiles, inplace, append, ACLs,
+    xattrs, optional secluded-args, iconv, prealloc, stop-at, no crtimes
+Checksum list:
+    xxh128 xxh3 xxh64 (xxhash) md5 md4 sha1 none
+Compress list:
+    zstd lz4 zlibx zlib none
+`
+
+func TestGetRsyncParams(t *testing.T) {
+	caps, err := rsync.ParseCapabilities(testRsyncVersionOutput)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsync.SetCapabilities(caps)
+	defer rsync.SetCapabilities(nil)
+	fake := &rsync.FakeExecutor{}
+	rsync.SetExecutor(fake)
+	defer rsync.SetExecutor(&rsync.SystemExecutor{})
+
+	yes, no := true, false
+	zstd, level := rsync.COMPRESS_CHOICE_ZSTD, 30
+	tests := []struct {
+		name   string
+		conf   Config
+		module Module
+	}{
+		{"empty", Config{}, Module{}},
+		{"profile_attributes", Config{RsyncTransferSourceOwner: &yes, RsyncTransferSourceGroup: &yes,
+			RsyncTransferSourcePermissions: &yes, RsyncRecreateSymlinks: &yes,
+			RsyncTransferDeviceFiles: &yes, RsyncTransferSpecialFiles: &yes,
+			RsyncTransferACLs: &yes, RsyncTransferXattrs: &yes}, Module{}},
+		{"module_overrides_profile", Config{RsyncTransferSourceOwner: &yes, RsyncTransferSourceGroup: &yes,
+			RsyncTransferACLs: &yes},
+			Module{RsyncTransferSourceOwner: &no, RsyncTransferSourcePermissions: &yes,
+				RsyncTransferACLs: &no, RsyncTransferXattrs: &yes}},
+		{"compress_default", Config{RsyncCompressFileTransfer: &yes}, Module{}},
+		{"compress_choice_level", Config{RsyncCompressFileTransfer: &yes,
+			RsyncCompressChoice: &zstd, RsyncCompressLevel: &level}, Module{}},
+		{"partial_sparse", Config{RsyncPartialTransfer: &yes, RsyncSparseFiles: &yes}, Module{}},
+		{"inplace", Config{RsyncInplace: &yes}, Module{}},
+		{"chmod_chown_take_precedence", Config{}, Module{ChangeFilePermission: "Dg+s,ug+w",
+			Chown: "backup:backup", UserMap: []string{"1000:backup"}, GroupMap: []string{"*:backup"}}},
+		{"usermap_groupmap", Config{}, Module{UserMap: []string{"1000:backup", "root:nobody"},
+			GroupMap: []string{"*:backup"}}},
+		{"charset", Config{}, Module{SourceCharset: "CP1251"}},
+	}
+	for _, test := range tests {
+		fake.Reset()
+		options := rsync.NewOptions(rsync.WithDefaultParams(
+			GetRsyncParams(&test.conf, &test.module, []string{"--times"})))
+		paths := core.SrcDstPath{RsyncSourcePath: "rsync://nas/data/", DestPath: "/backup/data"}
+		sessionErr, retryErr, criticalErr := rsync.RunRsyncWithRetry(context.Background(),
+			options, nil, nil, paths)
+		if sessionErr != nil || retryErr != nil || criticalErr != nil {
+			t.Fatalf("%s: unexpected errors: %v, %v, %v", test.name, sessionErr, retryErr, criticalErr)
+		}
+
+		fileName := filepath.Join("testdata", "rsync_params_"+test.name+".golden")
+		if *update {
+			if err := ioutil.WriteFile(fileName, []byte(fake.Dump()), 0644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		golden, err := ioutil.ReadFile(fileName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(golden) != fake.Dump() {
+			t.Errorf("%s: command line mismatch\nexpected:\n%s\ngot:\n%s", test.name, golden, fake.Dump())
+		}
+	}
+}
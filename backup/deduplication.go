@@ -39,12 +39,25 @@ import (
 type NodeSignature struct {
 	SourceRsyncCipher string
 	DestSubPath       string
+	// RSYNC performance metrics achieved in backup session,
+	// might be empty for sessions made by previous versions.
+	Statistics *ModuleStatistics
+	// RSYNC source host and path without user and port crypted with
+	// hash function, to recognize source after user or port change;
+	// empty for sessions made by previous versions.
+	SourceHostPathCipher string
+	// Identifiers of previous source URLs of the module,
+	// not serialized.
+	aliasCiphers []string
 }
 
 // GetSignature builds NodeSignature object on the basis of BackupNodePath data.
 func GetSignature(module Module) NodeSignature {
 	signature := NodeSignature{SourceRsyncCipher: GenerateSourceID(module.SourceRsync),
-		DestSubPath: module.DestSubPath}
+		DestSubPath: module.DestSubPath, SourceHostPathCipher: GenerateSourceHostPathID(module.SourceRsync)}
+	for _, alias := range module.SourceAliases {
+		signature.aliasCiphers = append(signature.aliasCiphers, GenerateSourceID(alias))
+	}
 	return signature
 }
 
@@ -53,6 +66,33 @@ func GenerateSourceID(rsyncSource string) string {
 	return chipherStr(rsync.NormalizeRsyncURL(rsyncSource))
 }
 
+// GenerateSourceHostPathID convert RSYNC source URL host and path
+// to identifier, which doesn't depend on user and port. Return empty
+// string, if URL is not recognized.
+func GenerateSourceHostPathID(rsyncSource string) string {
+	path := rsync.GetRsyncURLHostPath(rsyncSource)
+	if path == "" {
+		return ""
+	}
+	return chipherStr(path)
+}
+
+// SignatureMatch describe how signature of previous backup session
+// match to RSYNC source backed up now.
+type SignatureMatch int
+
+const (
+	SIG_MATCH_NONE SignatureMatch = iota
+	// Same RSYNC source URL.
+	SIG_MATCH_EXACT
+	// RSYNC source URL listed in module previous source aliases.
+	SIG_MATCH_ALIAS
+	// Same RSYNC source host, path and destination subpath,
+	// while port changed. Source moved to another
+	// host is matched only via module source aliases.
+	SIG_MATCH_FUZZY
+)
+
 // chipherStr encode str with SHA256 hash function.
 // Used to encode RSYNC source path before file serialization.
 func chipherStr(str string) string {
@@ -70,6 +110,10 @@ func chipherStr(str string) string {
 // NodeSignatures keeps list of RSYNC source to backup in one session.
 type NodeSignatures struct {
 	Signatures []NodeSignature
+	// Free-text comment attached to the session by user.
+	Comment string
+	// Profile session created with; name kept as it was at session time.
+	Profile ProfileIdentity
 }
 
 // GetNodeSignatures convert RSYNC module source URLs to
@@ -95,6 +139,29 @@ func (v NodeSignatures) FindFirstSignature(signature string) *NodeSignature {
 	return nil
 }
 
+// FindMatchingSignature find item which match RSYNC source signature:
+// by source identifier first, then by module previous source aliases,
+// and finally by source host and path along with destination subpath.
+func (v NodeSignatures) FindMatchingSignature(signature NodeSignature) (*NodeSignature, SignatureMatch) {
+	if item := v.FindFirstSignature(signature.SourceRsyncCipher); item != nil {
+		return item, SIG_MATCH_EXACT
+	}
+	for _, alias := range signature.aliasCiphers {
+		if item := v.FindFirstSignature(alias); item != nil {
+			return item, SIG_MATCH_ALIAS
+		}
+	}
+	if signature.SourceHostPathCipher != "" {
+		for _, item := range v.Signatures {
+			if item.SourceHostPathCipher == signature.SourceHostPathCipher &&
+				item.DestSubPath == signature.DestSubPath {
+				return &item, SIG_MATCH_FUZZY
+			}
+		}
+	}
+	return nil, SIG_MATCH_NONE
+}
+
 // PrevBackup describe previous backup found, which contain same RSYNC source.
 // Such previous backups used for RSYNC utility deduplication, which
 // significantly decrease size and time for new backup session.
@@ -102,6 +169,9 @@ type PrevBackup struct {
 	// Full path to signature file name
 	SignatureFileName string
 	Signature         NodeSignature
+	// Identifier of RSYNC source backed up now, signature match to.
+	SourceID string
+	Match    SignatureMatch
 }
 
 // GetDirPath returns full path to data copied in previous successful backup session.
@@ -129,7 +199,7 @@ func (v *PreviousBackups) GetDirPaths() []string {
 func (v *PreviousBackups) FilterBySourceID(sourceID string) *PreviousBackups {
 	var newPreviousBackups []PrevBackup
 	for _, v := range v.Backups {
-		if sourceID == v.Signature.SourceRsyncCipher {
+		if sourceID == v.SourceID {
 			newPreviousBackups = append(newPreviousBackups, v)
 		}
 	}
@@ -190,8 +260,9 @@ func FindPrevBackupPathsByNodeSignatures(lg logger.PackageLog, destPath string,
 					break
 				}
 				for _, item1 := range signs.Signatures {
-					if candidate := signs2.FindFirstSignature(item1.SourceRsyncCipher); candidate != nil {
-						backup := PrevBackup{SignatureFileName: fileName, Signature: *candidate}
+					if candidate, match := signs2.FindMatchingSignature(item1); candidate != nil {
+						backup := PrevBackup{SignatureFileName: fileName, Signature: *candidate,
+							SourceID: item1.SourceRsyncCipher, Match: match}
 						candidates[item1.SourceRsyncCipher] = append(candidates[item1.SourceRsyncCipher],
 							prevBackupEntry{time: stat.ModTime(), backup: backup})
 					}
@@ -238,6 +309,28 @@ func FindPrevBackupPathsByNodeSignatures(lg logger.PackageLog, destPath string,
 	return backups2, nil
 }
 
+// FindPrevBackupPathsInDestRoots search for previous backup sessions in each
+// destination root used by modules, since modules might override profile
+// destination root specified by destPath.
+func FindPrevBackupPathsInDestRoots(lg logger.PackageLog, destPath string,
+	modules []Module, lastN int) (*PreviousBackups, error) {
+
+	roots, groups := GroupModulesByDestRoot(destPath, modules)
+	var backups []PrevBackup
+	for _, root := range roots {
+		if len(groups[root]) == 0 {
+			continue
+		}
+		prevBackups, err := FindPrevBackupPathsByNodeSignatures(lg, root,
+			GetNodeSignatures(groups[root]), lastN)
+		if err != nil {
+			return nil, err
+		}
+		backups = append(backups, prevBackups.Backups...)
+	}
+	return &PreviousBackups{Backups: backups}, nil
+}
+
 // Temporary object used to sort found previous backup sessions by creation/modification date
 // in descending order (the most recent come first).
 type filesSortedByDate struct {
@@ -259,9 +352,16 @@ func (s filesSortedByDate) Swap(i, j int) {
 }
 
 // CreateMetadataSignatureFile serialize RSYNC sources plus destination subpaths
-// to the special "backup session signature" file.
-func CreateMetadataSignatureFile(modules []Module, destPath string) error {
+// to the special "backup session signature" file. RSYNC performance metrics
+// indexed by source identifier saved there as well.
+func CreateMetadataSignatureFile(profile ProfileIdentity, modules []Module,
+	stats map[string]*ModuleStatistics, destPath string) error {
+
 	signs := GetNodeSignatures(modules)
+	signs.Profile = profile
+	for i, item := range signs.Signatures {
+		signs.Signatures[i].Statistics = stats[item.SourceRsyncCipher]
+	}
 	err := createDirAll(destPath)
 	if err != nil {
 		return err
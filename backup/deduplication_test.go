@@ -0,0 +1,51 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package backup
+
+import "testing"
+
+func TestFindMatchingSignature(t *testing.T) {
+	prev := GetNodeSignatures([]Module{
+		{SourceRsync: "rsync://alice@home-pc:873/data/photo", DestSubPath: "photo"},
+		{SourceRsync: "rsync://nas/music", DestSubPath: "music"},
+	})
+	tests := []struct {
+		name   string
+		module Module
+		match  SignatureMatch
+		dest   string
+	}{
+		{"exact", Module{SourceRsync: "rsync://alice@home-pc:873/data/photo", DestSubPath: "photo"},
+			SIG_MATCH_EXACT, "photo"},
+		{"user and port changed", Module{SourceRsync: "rsync://bob@HOME-PC:8873/data/photo/", DestSubPath: "photo"},
+			SIG_MATCH_FUZZY, "photo"},
+		{"host changed", Module{SourceRsync: "rsync://alice@office-pc:873/data/photo", DestSubPath: "photo"},
+			SIG_MATCH_NONE, ""},
+		{"host changed with alias", Module{SourceRsync: "rsync://alice@office-pc:873/data/photo", DestSubPath: "photo",
+			SourceAliases: []string{"rsync://alice@home-pc:873/data/photo"}},
+			SIG_MATCH_ALIAS, "photo"},
+		{"dest subpath changed", Module{SourceRsync: "rsync://bob@home-pc:8873/data/photo", DestSubPath: "pictures"},
+			SIG_MATCH_NONE, ""},
+		{"path changed", Module{SourceRsync: "rsync://nas/music/rock", DestSubPath: "music"},
+			SIG_MATCH_NONE, ""},
+	}
+	for _, test := range tests {
+		item, match := prev.FindMatchingSignature(GetSignature(test.module))
+		if match != test.match {
+			t.Errorf("%s: expected match %v, got %v", test.name, test.match, match)
+			continue
+		}
+		if test.dest == "" && item != nil || test.dest != "" && (item == nil || item.DestSubPath != test.dest) {
+			t.Errorf("%s: unexpected signature matched: %+v", test.name, item)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package backup
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/user"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/d2r2/go-rsync/core"
+	"github.com/d2r2/go-rsync/locale"
+)
+
+// Amount of data written to measure destination write throughput.
+const (
+	destTestWriteSize  = 16 * core.MB
+	destTestWriteChunk = 1 * core.MB
+)
+
+// TestDestination verify destination folder: existence, writability, free space,
+// permissions, file system type, hard-link support (needed for deduplication)
+// and write throughput. Results are appended to the report. Return error
+// only if process was interrupted via context.
+func TestDestination(ctx context.Context, destPath string, report *CheckReport) error {
+	checkDestination(destPath, false, report)
+	if report.GetStatus() == CheckFailed {
+		return nil
+	}
+
+	checkDestPermissions(destPath, report)
+
+	fsType, err := GetFileSystemType(destPath)
+	if err != nil {
+		report.Add(CHECK_DEST_FS_TYPE, destPath, CheckWarning, err.Error())
+	} else {
+		report.Add(CHECK_DEST_FS_TYPE, destPath, CheckPassed,
+			locale.T(MsgCheckDestFileSystemType, struct{ FileSystem string }{FileSystem: fsType}))
+	}
+
+	supported, err := IsHardLinkSupported(destPath)
+	if err != nil {
+		report.Add(CHECK_DEST_HARD_LINKS, destPath, CheckWarning, err.Error())
+	} else if supported {
+		report.Add(CHECK_DEST_HARD_LINKS, destPath, CheckPassed,
+			locale.T(MsgCheckDestHardLinksSupported, nil))
+	} else {
+		report.Add(CHECK_DEST_HARD_LINKS, destPath, CheckWarning,
+			locale.T(MsgCheckDestHardLinksNotSupported, nil))
+	}
+
+	throughput, err := measureWriteThroughput(ctx, destPath)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		report.Add(CHECK_DEST_WRITE_SPEED, destPath, CheckFailed, err.Error())
+	} else {
+		report.Add(CHECK_DEST_WRITE_SPEED, destPath, CheckPassed,
+			locale.T(MsgCheckDestWriteSpeed,
+				struct{ Speed string }{Speed: core.FormatSize(throughput, true)}))
+	}
+	return nil
+}
+
+// checkDestPermissions report folder access mode and owner, and verify
+// that subfolder could be created there, as backup session does.
+func checkDestPermissions(destPath string, report *CheckReport) {
+	stat, err := os.Stat(destPath)
+	if err != nil {
+		report.Add(CHECK_DEST_PERMISSIONS, destPath, CheckFailed, err.Error())
+		return
+	}
+	owner := "?"
+	if sys, ok := stat.Sys().(*syscall.Stat_t); ok {
+		owner = strconv.Itoa(int(sys.Uid))
+		if u, err := user.LookupId(owner); err == nil {
+			owner = u.Username
+		}
+	}
+	dir, err := ioutil.TempDir(destPath, ".gorsync_dir_test_")
+	if err != nil {
+		report.Add(CHECK_DEST_PERMISSIONS, destPath, CheckFailed,
+			locale.T(MsgCheckDestPermissionsCantCreateFolder,
+				struct {
+					Mode, Owner string
+					Error       error
+				}{Mode: stat.Mode().Perm().String(), Owner: owner, Error: err}))
+		return
+	}
+	os.Remove(dir)
+	report.Add(CHECK_DEST_PERMISSIONS, destPath, CheckPassed,
+		locale.T(MsgCheckDestPermissions,
+			struct{ Mode, Owner string }{Mode: stat.Mode().Perm().String(), Owner: owner}))
+}
+
+// measureWriteThroughput write probe file to destination
+// and return write throughput in bytes per second.
+func measureWriteThroughput(ctx context.Context, destPath string) (uint64, error) {
+	file, err := ioutil.TempFile(destPath, ".gorsync_speed_test_")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	chunk := make([]byte, destTestWriteChunk)
+	startTime := time.Now()
+	for written := uint64(0); written < destTestWriteSize; written += destTestWriteChunk {
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		_, err = file.Write(chunk)
+		if err != nil {
+			return 0, err
+		}
+	}
+	// flush data to device to measure real throughput, rather than cache speed
+	err = file.Sync()
+	if err != nil {
+		return 0, err
+	}
+	duration := time.Since(startTime)
+	if duration <= 0 {
+		duration = time.Millisecond
+	}
+	return uint64(float64(destTestWriteSize) / duration.Seconds()), nil
+}
@@ -0,0 +1,253 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/d2r2/go-rsync/core"
+	"github.com/d2r2/go-rsync/locale"
+	"github.com/d2r2/go-rsync/rsync"
+	shell "github.com/d2r2/go-shell"
+)
+
+// CheckStatus signify result of single environment verification.
+type CheckStatus int
+
+// Environment verification could end with next results:
+// 1) check passed;
+// 2) check passed, but some issue might take place;
+// 3) check failed, so backup session will fail either.
+const (
+	CheckPassed CheckStatus = iota
+	CheckWarning
+	CheckFailed
+)
+
+// String implement Stringer interface.
+func (v CheckStatus) String() string {
+	switch v {
+	case CheckPassed:
+		return "passed"
+	case CheckWarning:
+		return "warning"
+	default:
+		return "failed"
+	}
+}
+
+// MarshalText implement encoding.TextMarshaler interface
+// to keep status human readable in JSON output.
+func (v CheckStatus) MarshalText() ([]byte, error) {
+	return []byte(v.String()), nil
+}
+
+// Identifiers of environment verifications,
+// used in machine-readable report.
+const (
+	CHECK_RSYNC_INSTALLED  = "rsync_installed"
+	CHECK_RSYNC_VERSION    = "rsync_version"
+	CHECK_SOURCE_REACHABLE = "source_reachable"
+	CHECK_DEST_MOUNT       = "destination_mount"
+	CHECK_DEST_EXISTS      = "destination_exists"
+	CHECK_DEST_WRITABLE    = "destination_writable"
+	CHECK_DEST_FREE_SPACE  = "destination_free_space"
+	CHECK_DEST_PERMISSIONS = "destination_permissions"
+	CHECK_DEST_FS_TYPE     = "destination_file_system"
+	CHECK_DEST_HARD_LINKS  = "destination_hard_links"
+	CHECK_DEST_WRITE_SPEED = "destination_write_speed"
+
+	CHECK_INTEGRITY_MANIFEST = "integrity_manifest"
+	CHECK_INTEGRITY_FILE     = "integrity_file"
+	CHECK_INTEGRITY_SUMMARY  = "integrity_summary"
+	CHECK_SESSION_COMMENT    = "session_comment"
+)
+
+// Minimum free space in destination, below which
+// environment verification report a warning.
+const minDestFreeSpace = 1 * core.GB
+
+// CheckItem keep result of single environment verification.
+type CheckItem struct {
+	Check   string      `json:"check"`
+	Subject string      `json:"subject,omitempty"`
+	Status  CheckStatus `json:"status"`
+	Message string      `json:"message"`
+}
+
+// CheckReport contains consolidated results of
+// backup profile environment verifications.
+type CheckReport struct {
+	ProfileID   string      `json:"profile_id,omitempty"`
+	ProfileName string      `json:"profile"`
+	Time        time.Time   `json:"time"`
+	Items       []CheckItem `json:"items"`
+}
+
+// NewCheckReport create empty CheckReport.
+func NewCheckReport(profile ProfileIdentity) *CheckReport {
+	v := &CheckReport{ProfileID: profile.ID, ProfileName: profile.Name, Time: time.Now()}
+	return v
+}
+
+// Add append verification result to the report.
+func (v *CheckReport) Add(check, subject string, status CheckStatus, message string) {
+	v.Items = append(v.Items, CheckItem{Check: check, Subject: subject,
+		Status: status, Message: message})
+}
+
+// GetStatus return the worst status found in the report.
+func (v *CheckReport) GetStatus() CheckStatus {
+	status := CheckPassed
+	for _, item := range v.Items {
+		if item.Status > status {
+			status = item.Status
+		}
+	}
+	return status
+}
+
+// JSON return machine-readable representation of the report.
+func (v *CheckReport) JSON() ([]byte, error) {
+	return json.MarshalIndent(struct {
+		*CheckReport
+		Status CheckStatus `json:"status"`
+	}{CheckReport: v, Status: v.GetStatus()}, "", "  ")
+}
+
+// CheckProfileEnvironment run in batch all verifications related to backup session
+// environment: RSYNC availability and version, each source reachability,
+// destination availability, writability and free space.
+// Results are appended to the report. Return error only if process
+// was interrupted via context.
+func CheckProfileEnvironment(ctx context.Context, modules []Module,
+	destPath string, mount *MountTarget, report *CheckReport) error {
+
+	// RSYNC utility
+	err := rsync.IsInstalled()
+	if err != nil {
+		report.Add(CHECK_RSYNC_INSTALLED, rsync.RSYNC_APP_CMD, CheckFailed, err.Error())
+	} else {
+		report.Add(CHECK_RSYNC_INSTALLED, rsync.RSYNC_APP_CMD, CheckPassed,
+			locale.T(MsgCheckRsyncInstalled, nil))
+		version, protocol, err := rsync.GetRsyncVersion()
+		if err != nil {
+			status := CheckFailed
+			if rsync.IsExtractVersionAndProtocolError(err) {
+				status = CheckWarning
+			}
+			report.Add(CHECK_RSYNC_VERSION, rsync.RSYNC_APP_CMD, status, err.Error())
+		} else {
+			report.Add(CHECK_RSYNC_VERSION, rsync.RSYNC_APP_CMD, CheckPassed,
+				locale.T(MsgCheckRsyncVersion,
+					struct{ Version, Protocol string }{Version: version, Protocol: protocol}))
+		}
+	}
+
+	// RSYNC sources
+	for _, module := range modules {
+		if module.SourceRsync == "" {
+			report.Add(CHECK_SOURCE_REACHABLE, module.SourceRsync, CheckFailed,
+				locale.T(MsgCheckSourceIsEmpty, nil))
+			continue
+		}
+		err := rsync.GetPathStatus(ctx, module.AuthPassword, module.SourceRsync, false)
+		if err != nil {
+			if rsync.IsProcessTerminatedError(err) {
+				return err
+			}
+			report.Add(CHECK_SOURCE_REACHABLE, module.SourceRsync, CheckFailed, err.Error())
+		} else {
+			report.Add(CHECK_SOURCE_REACHABLE, module.SourceRsync, CheckPassed,
+				locale.T(MsgCheckSourceReachable, nil))
+		}
+	}
+
+	// Destination
+	mountPending := false
+	if mount != nil {
+		err := mount.Check()
+		if err != nil {
+			report.Add(CHECK_DEST_MOUNT, mount.Spec, CheckFailed, err.Error())
+		} else if mounted, err := mount.IsMounted(); err != nil {
+			report.Add(CHECK_DEST_MOUNT, mount.Spec, CheckWarning, err.Error())
+		} else if !mounted {
+			mountPending = true
+			report.Add(CHECK_DEST_MOUNT, mount.Spec, CheckPassed,
+				locale.T(MsgCheckDestMountWillBeMounted, nil))
+		} else {
+			report.Add(CHECK_DEST_MOUNT, mount.Spec, CheckPassed,
+				locale.T(MsgCheckDestMountAlreadyMounted, nil))
+		}
+	}
+
+	roots, _ := GroupModulesByDestRoot(destPath, modules)
+	for i, root := range roots {
+		// mount relate to profile destination root only
+		checkDestination(root, i == 0 && mountPending, report)
+	}
+
+	return nil
+}
+
+// checkDestination verify destination root path existence,
+// writability and free space.
+func checkDestination(destPath string, mountPending bool, report *CheckReport) {
+	if destPath == "" {
+		report.Add(CHECK_DEST_EXISTS, destPath, CheckFailed,
+			locale.T(MsgCheckDestPathIsEmpty, nil))
+		return
+	}
+	stat, err := os.Stat(destPath)
+	if err != nil {
+		// Destination could be not reachable yet, until mount happens.
+		if mountPending {
+			report.Add(CHECK_DEST_EXISTS, destPath, CheckWarning,
+				locale.T(MsgCheckDestPathUnavailableUntilMount, nil))
+		} else {
+			report.Add(CHECK_DEST_EXISTS, destPath, CheckFailed, err.Error())
+		}
+		return
+	} else if !stat.IsDir() {
+		report.Add(CHECK_DEST_EXISTS, destPath, CheckFailed,
+			locale.T(MsgCheckDestPathIsNotFolder, nil))
+		return
+	}
+	report.Add(CHECK_DEST_EXISTS, destPath, CheckPassed, locale.T(MsgCheckDestPathExists, nil))
+
+	file, err := ioutil.TempFile(destPath, ".gorsync_write_test_")
+	if err != nil {
+		report.Add(CHECK_DEST_WRITABLE, destPath, CheckFailed, err.Error())
+	} else {
+		file.Close()
+		os.Remove(file.Name())
+		report.Add(CHECK_DEST_WRITABLE, destPath, CheckPassed,
+			locale.T(MsgCheckDestPathWritable, nil))
+	}
+
+	freeSpace, err := shell.GetFreeSpace(destPath)
+	if err != nil {
+		report.Add(CHECK_DEST_FREE_SPACE, destPath, CheckWarning, err.Error())
+	} else {
+		status := CheckPassed
+		if freeSpace < minDestFreeSpace {
+			status = CheckWarning
+		}
+		report.Add(CHECK_DEST_FREE_SPACE, destPath, status,
+			locale.T(MsgCheckDestFreeSpace,
+				struct{ FreeSpace string }{FreeSpace: core.FormatSize(freeSpace, true)}))
+	}
+}
@@ -0,0 +1,116 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package backup
+
+import (
+	"math"
+	"time"
+
+	"github.com/d2r2/go-rsync/core"
+)
+
+const (
+	// Smoothing factor of exponentially weighted moving average:
+	// bigger value give more weight to recent samples.
+	etaSmoothingFactor = 0.3
+	// Throughput samples shorter than this are accumulated
+	// with next one, to avoid noise from tiny backup blocks.
+	etaMinSampleDuration = 2 * time.Second
+	// Number of samples, after which smoothed throughput
+	// take half of weight from session average throughput.
+	etaWarmUpSamples = 3
+	// Conservative throughput is never lower than this
+	// fraction of expected throughput.
+	etaMinConservativeRatio = 0.25
+)
+
+// ETA keep estimated time left to complete 2nd stage (backup stage).
+type ETA struct {
+	// Most probable time left.
+	Expected time.Duration
+	// Time left, if throughput would be higher than average.
+	Optimistic time.Duration
+	// Time left, if throughput would be lower than average.
+	Conservative time.Duration
+}
+
+// etaEstimator smooth throughput samples taken on completion of backup
+// blocks with exponentially weighted moving average (EWMA) and track
+// throughput deviation, to predict time left for size which
+// remains to backup according to plan stage.
+type etaEstimator struct {
+	lastTime time.Time
+	lastSize core.FolderSize
+	samples  int
+	// Smoothed throughput in bytes per second.
+	rate float64
+	// Smoothed variance of throughput.
+	variance float64
+}
+
+// addSample register total size processed at specific time.
+func (v *etaEstimator) addSample(now time.Time, totalDone core.FolderSize) {
+	if v.lastTime.IsZero() {
+		v.lastTime = now
+		v.lastSize = totalDone
+		return
+	}
+	dt := now.Sub(v.lastTime)
+	if dt < etaMinSampleDuration || totalDone < v.lastSize {
+		return
+	}
+	sample := float64(totalDone-v.lastSize) / dt.Seconds()
+	if v.samples == 0 {
+		v.rate = sample
+	} else {
+		diff := sample - v.rate
+		v.rate += etaSmoothingFactor * diff
+		v.variance = (1 - etaSmoothingFactor) * (v.variance + etaSmoothingFactor*diff*diff)
+	}
+	v.samples++
+	v.lastTime = now
+	v.lastSize = totalDone
+}
+
+// estimate blend smoothed throughput with session average throughput
+// and return time left for size leftToBackup. Return nil, if throughput
+// is unknown yet.
+func (v *etaEstimator) estimate(timePassed time.Duration, totalDone,
+	leftToBackup core.FolderSize) *ETA {
+
+	if totalDone == 0 || timePassed <= 0 {
+		return nil
+	}
+	average := float64(totalDone) / timePassed.Seconds()
+	rate := average
+	deviation := 0.0
+	if v.samples > 0 {
+		weight := float64(v.samples) / float64(v.samples+etaWarmUpSamples)
+		rate = weight*v.rate + (1-weight)*average
+		deviation = weight * math.Sqrt(v.variance)
+	}
+	if rate <= 0 {
+		return nil
+	}
+	optimistic := rate + deviation
+	conservative := rate - deviation
+	if conservative < rate*etaMinConservativeRatio {
+		conservative = rate * etaMinConservativeRatio
+	}
+	left := float64(leftToBackup)
+	eta := &ETA{
+		Expected:     time.Duration(left / rate * float64(time.Second)),
+		Optimistic:   time.Duration(left / optimistic * float64(time.Second)),
+		Conservative: time.Duration(left / conservative * float64(time.Second)),
+	}
+	return eta
+}
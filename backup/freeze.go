@@ -0,0 +1,85 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package backup
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// BackupSession describe completed backup session found in destination.
+type BackupSession struct {
+	Path    string
+	ModTime time.Time
+	// Session protected from pruning and cleanup.
+	Frozen bool
+	// Comment attached to the session by user.
+	Comment string
+}
+
+// FreezeSession protect backup session from being deleted by pruning
+// or cleanup, creating marker file in the session folder.
+func FreezeSession(sessionPath string) error {
+	fileName := filepath.Join(sessionPath, GetFreezeMarkerFileName())
+	text := time.Now().Format(time.RFC3339) + "\n"
+	return ioutil.WriteFile(fileName, []byte(text), 0644)
+}
+
+// UnfreezeSession remove protection from backup session.
+func UnfreezeSession(sessionPath string) error {
+	err := os.Remove(filepath.Join(sessionPath, GetFreezeMarkerFileName()))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// IsSessionFrozen verify that backup session is protected
+// from pruning and cleanup.
+func IsSessionFrozen(sessionPath string) bool {
+	_, err := os.Stat(filepath.Join(sessionPath, GetFreezeMarkerFileName()))
+	return err == nil
+}
+
+// ListSessions return completed backup sessions (identified
+// by signature file) located in destPath, most recent first.
+func ListSessions(destPath string) ([]BackupSession, error) {
+	items, err := ioutil.ReadDir(destPath)
+	if err != nil {
+		return nil, err
+	}
+	var sessions []BackupSession
+	for _, item := range items {
+		if !item.IsDir() {
+			continue
+		}
+		sessionPath := filepath.Join(destPath, item.Name())
+		stat, err := os.Stat(filepath.Join(sessionPath, GetMetadataSignatureFileName()))
+		if err != nil {
+			continue
+		}
+		comment, err := GetSessionComment(sessionPath)
+		if err != nil {
+			LocalLog.Warnf("Can't read comment of %q: %v", sessionPath, err)
+		}
+		sessions = append(sessions, BackupSession{Path: sessionPath,
+			ModTime: stat.ModTime(), Frozen: IsSessionFrozen(sessionPath),
+			Comment: comment})
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].ModTime.After(sessions[j].ModTime)
+	})
+	return sessions, nil
+}
@@ -0,0 +1,176 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package backup
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// File system magic numbers returned by statfs(2),
+// taken from linux/magic.h.
+var fileSystemTypes = map[int64]string{
+	0xEF53:     "ext2/ext3/ext4",
+	0x9123683E: "btrfs",
+	0x58465342: "xfs",
+	0x2FC12FC1: "zfs",
+	0xF2F52010: "f2fs",
+	0x52654973: "reiserfs",
+	0x3153464A: "jfs",
+	0x01021994: "tmpfs",
+	0x794C7630: "overlayfs",
+	0xF15F:     "ecryptfs",
+	0x4D44:     "vfat",
+	0x2011BAB0: "exfat",
+	0x5346544E: "ntfs",
+	0x65735546: "fuseblk",
+	0x65735543: "fusectl",
+	0x6969:     "nfs",
+	0xFF534D42: "cifs",
+	0xFE534D42: "smb2",
+	0x517B:     "smb",
+	0x9660:     "iso9660",
+	0x15013346: "udf",
+}
+
+// File systems known to lack hard links support.
+var noHardLinkFileSystems = map[string]bool{
+	"vfat":    true,
+	"exfat":   true,
+	"iso9660": true,
+}
+
+// File systems known to lack ACLs and extended attributes support.
+var noXattrFileSystems = map[string]bool{
+	"vfat":    true,
+	"exfat":   true,
+	"iso9660": true,
+}
+
+// GetFileSystemType return name of file system, where path is located.
+func GetFileSystemType(path string) (string, error) {
+	var stat syscall.Statfs_t
+	err := syscall.Statfs(path, &stat)
+	if err != nil {
+		return "", err
+	}
+	fsType := int64(stat.Type)
+	if name, ok := fileSystemTypes[fsType]; ok {
+		return name, nil
+	}
+	return f("0x%X", fsType), nil
+}
+
+// IsHardLinkSupported verify that file system, where path is located,
+// allow to create hard links, which are required for deduplication
+// (RSYNC --link-dest option). File system type obtained via statfs
+// is verified first, then probe file and hard link to it are created,
+// which are deleted afterwards.
+func IsHardLinkSupported(path string) (bool, error) {
+	if fsType, err := GetFileSystemType(path); err == nil && noHardLinkFileSystems[fsType] {
+		return false, nil
+	}
+	file, err := ioutil.TempFile(path, ".gorsync_link_test_")
+	if err != nil {
+		return false, err
+	}
+	file.Close()
+	defer os.Remove(file.Name())
+
+	linkName := filepath.Join(path, filepath.Base(file.Name())+"_link")
+	err = os.Link(file.Name(), linkName)
+	if err != nil {
+		if os.IsPermission(err) || isNotSupportedError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	os.Remove(linkName)
+	return true, nil
+}
+
+// isNotSupportedError detect errors returned by
+// file systems, which can't create hard links.
+func isNotSupportedError(err error) bool {
+	if linkErr, ok := err.(*os.LinkError); ok {
+		err = linkErr.Err
+	}
+	return err == syscall.EPERM || err == syscall.EOPNOTSUPP || err == syscall.ENOSYS
+}
+
+// Extended attribute used to probe extended attributes support.
+const xattrProbeName = "user.gorsync_probe"
+
+// Extended attribute, which keep POSIX access ACL.
+const aclAccessXattrName = "system.posix_acl_access"
+
+// getMinimalACL build POSIX access ACL in kernel xattr format,
+// which is equivalent to file mode rw-r--r--.
+func getMinimalACL() []byte {
+	const (
+		aclVersion  = 2
+		aclUserObj  = 0x01
+		aclGroupObj = 0x04
+		aclOther    = 0x20
+		aclNoID     = 0xFFFFFFFF
+	)
+	entries := []struct{ tag, perm uint16 }{
+		{aclUserObj, 6}, {aclGroupObj, 4}, {aclOther, 4},
+	}
+	buf := make([]byte, 4+8*len(entries))
+	binary.LittleEndian.PutUint32(buf, aclVersion)
+	for i, entry := range entries {
+		item := buf[4+8*i:]
+		binary.LittleEndian.PutUint16(item, entry.tag)
+		binary.LittleEndian.PutUint16(item[2:], entry.perm)
+		binary.LittleEndian.PutUint32(item[4:], aclNoID)
+	}
+	return buf
+}
+
+// probeXattr create probe file in path and try to set
+// extended attribute there, deleting file afterwards.
+func probeXattr(path, name string, value []byte) (bool, error) {
+	if fsType, err := GetFileSystemType(path); err == nil && noXattrFileSystems[fsType] {
+		return false, nil
+	}
+	file, err := ioutil.TempFile(path, ".gorsync_xattr_test_")
+	if err != nil {
+		return false, err
+	}
+	file.Close()
+	defer os.Remove(file.Name())
+
+	err = syscall.Setxattr(file.Name(), name, value, 0)
+	if err != nil {
+		if err == syscall.EOPNOTSUPP || err == syscall.ENOTSUP || err == syscall.ENOSYS {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// IsXattrSupported verify that file system, where path is located,
+// allow to keep extended attributes (RSYNC --xattrs option).
+func IsXattrSupported(path string) (bool, error) {
+	return probeXattr(path, xattrProbeName, []byte("1"))
+}
+
+// IsACLSupported verify that file system, where path is located,
+// allow to keep POSIX ACLs (RSYNC --acls option).
+func IsACLSupported(path string) (bool, error) {
+	return probeXattr(path, aclAccessXattrName, getMinimalACL())
+}
@@ -91,8 +91,8 @@ func getNonMeasuredDir(dir *core.Dir) *core.Dir {
 // measureLocalUpToRoot calculate "local size" metric for chain of parent folders
 // up to root, if not yet defined. Additionally mark all folder's chain up to root
 // with core.FBT_CONTENT attribute.
-func measureLocalUpToRoot(ctx context.Context, password *string, dir *core.Dir, retryCount *int,
-	rsyncProtocol string, log *rsync.Logging) error {
+func measureLocalUpToRoot(ctx context.Context, password *string, filter *rsync.FileFilter,
+	dir *core.Dir, retryCount *int, rsyncProtocol string, log *rsync.Logging) error {
 
 	item := dir
 	for {
@@ -103,7 +103,7 @@ func measureLocalUpToRoot(ctx context.Context, password *string, dir *core.Dir,
 		var err error
 		size := item.Metrics.Size
 		if size == nil {
-			size, err = rsync.ObtainDirLocalSize(ctx, password, item, retryCount, rsyncProtocol, log)
+			size, err = rsync.ObtainDirLocalSize(ctx, password, filter, item, retryCount, rsyncProtocol, log)
 			if err != nil {
 				return err
 			}
@@ -147,12 +147,14 @@ func findDownNonMeasuredDirByWeight(dir *core.Dir, weight int) *core.Dir {
 // like core.FBT_RECURSIVE, core.FBT_CONTENT or core.FBT_SKIP, which lately used in backup stage
 // as a direct instruction what to do. Returning totalCount contains statistics how many times
 // application call RSYNC utility to measure folder size on remote server (with all content).
-func MeasureDir(ctx context.Context, password *string, dir *core.Dir, retryCount *int,
-	rsyncProtocol string, log *rsync.Logging, blockSize *backupBlockSizeSettings) (int, error) {
+func MeasureDir(ctx context.Context, password *string, filter *rsync.FileFilter, dir *core.Dir,
+	retryCount *int, rsyncProtocol string, log *rsync.Logging,
+	blockSize *backupBlockSizeSettings) (int, error) {
 
 	totalCount := 0
 	for {
-		found, count, err := searchDownOptimalDir(ctx, password, dir, retryCount, rsyncProtocol, log, blockSize)
+		found, count, err := searchDownOptimalDir(ctx, password, filter, dir, retryCount,
+			rsyncProtocol, log, blockSize)
 		if err != nil {
 			return 0, err
 		}
@@ -162,18 +164,18 @@ func MeasureDir(ctx context.Context, password *string, dir *core.Dir, retryCount
 		}
 
 		if found.Metrics.IgnoreToBackup {
-			LocalLog.Debugf("Selected for skip (count=%v): %v", count, found.Paths.RsyncSourcePath)
+			LocalLog.Debugf("Selected for skip (count=%v): %v", count, found.GetPaths().RsyncSourcePath)
 			// Mark this folder as "skip to backup" (because it contains special signature file).
 			found.Metrics.BackupType = core.FBT_SKIP
 		} else {
-			LocalLog.Debugf("Selected for full backup (count=%v): %v", count, found.Paths.RsyncSourcePath)
+			LocalLog.Debugf("Selected for full backup (count=%v): %v", count, found.GetPaths().RsyncSourcePath)
 			// Mark this folder as "recursive backup", when this folder and all included content and subfoders
 			// are backing up in single RSYNC call.
 			found.Metrics.BackupType = core.FBT_RECURSIVE
 		}
 
 		markMesuredAll(found)
-		err = measureLocalUpToRoot(ctx, password, found, retryCount, rsyncProtocol, log)
+		err = measureLocalUpToRoot(ctx, password, filter, found, retryCount, rsyncProtocol, log)
 		if err != nil {
 			return 0, err
 		}
@@ -211,13 +213,13 @@ func getRoot(dir *core.Dir) *core.Dir {
 }
 
 // calcFullSizesWithRoot calc "full size" metric for current folder and root, if not defined yet.
-func calcFullSizesWithRoot(ctx context.Context, password *string, dir *core.Dir,
+func calcFullSizesWithRoot(ctx context.Context, password *string, filter *rsync.FileFilter, dir *core.Dir,
 	retryCount *int, rsyncProtocol string, log *rsync.Logging) (int, error) {
 
 	count := 0
 	root := getRoot(dir)
 	if root.Metrics.FullSize == nil {
-		fullSize, err := rsync.ObtainDirFullSize(ctx, password, root, retryCount, rsyncProtocol, log)
+		fullSize, err := rsync.ObtainDirFullSize(ctx, password, filter, root, retryCount, rsyncProtocol, log)
 		if err != nil {
 			return 0, err
 		}
@@ -225,7 +227,7 @@ func calcFullSizesWithRoot(ctx context.Context, password *string, dir *core.Dir,
 		count++
 	}
 	if dir.Metrics.FullSize == nil {
-		fullSize, err := rsync.ObtainDirFullSize(ctx, password, dir, retryCount, rsyncProtocol, log)
+		fullSize, err := rsync.ObtainDirFullSize(ctx, password, filter, dir, retryCount, rsyncProtocol, log)
 		if err != nil {
 			return 0, err
 		}
@@ -306,48 +308,57 @@ func selectChildByWeight(dir *core.Dir) *core.Dir {
 type backupBlockSizeSettings struct {
 	AutoManageBackupBlockSize bool
 	BackupBlockSize           uint64
+	// RSYNC performance metrics learned from previous
+	// backup sessions, if any.
+	Statistics *ModuleStatistics
 }
 
 // calcOptimalBackupBlockSize contains simple formula to
 // gives backup block size low/high limits obtained from
-// total backup size.
-func calcOptimalBackupBlockSize(dir *core.Dir) uint64 {
+// total backup size. Limits are derived from statistics
+// of previous sessions, if available.
+func calcOptimalBackupBlockSize(dir *core.Dir, stats *ModuleStatistics) uint64 {
 	const splitTo = 50
+	var min, max uint64 = defaultMinBackupBlockSize, defaultMaxBackupBlockSize
+	if stats != nil && stats.Throughput > 0 {
+		min, max = stats.getBackupBlockSizeLimits()
+	}
 	root := getRoot(dir)
 	bs := root.Metrics.FullSize.GetByteCount() / splitTo
-	if bs > 5*core.GB {
-		bs = 5 * core.GB
-	} else if bs < 300*core.MB {
-		bs = 300 * core.MB
+	if bs > max {
+		bs = max
+	} else if bs < min {
+		bs = min
 	}
 	return bs
 }
 
 // searchDownOptimalDir is a main recurrent function to find optimal (or close to optimal)
 // walk path of backup source directory tree minimizing number of RSYNC utility calls.
-func searchDownOptimalDir(ctx context.Context, password *string, dir *core.Dir, retryCount *int,
-	rsyncProtocol string, log *rsync.Logging, blockSize *backupBlockSizeSettings) (*core.Dir, int, error) {
+func searchDownOptimalDir(ctx context.Context, password *string, filter *rsync.FileFilter,
+	dir *core.Dir, retryCount *int, rsyncProtocol string, log *rsync.Logging,
+	blockSize *backupBlockSizeSettings) (*core.Dir, int, error) {
 
 	LocalLog.Debugf("Start searching optimal folder from root %v",
-		dir.Paths.RsyncSourcePath)
+		dir.GetPaths().RsyncSourcePath)
 
 	found := getNonMeasuredDir(dir)
 
 	if found != nil {
 		LocalLog.Debugf("Get non-measured candidate %v to test",
-			found.Paths.RsyncSourcePath)
+			found.GetPaths().RsyncSourcePath)
 	}
 
 	totalFullSizeCount := 0
 	if found != nil {
-		count, err := calcFullSizesWithRoot(ctx, password, found, retryCount, rsyncProtocol, log)
+		count, err := calcFullSizesWithRoot(ctx, password, filter, found, retryCount, rsyncProtocol, log)
 		if err != nil {
 			return nil, 0, err
 		}
 		totalFullSizeCount += count
 
 		if blockSize.AutoManageBackupBlockSize {
-			bs := calcOptimalBackupBlockSize(found)
+			bs := calcOptimalBackupBlockSize(found, blockSize.Statistics)
 			if blockSize.BackupBlockSize != bs {
 				blockSize.BackupBlockSize = bs
 			}
@@ -379,7 +390,7 @@ func searchDownOptimalDir(ctx context.Context, password *string, dir *core.Dir,
 			if next == found {
 				return next, totalFullSizeCount, nil
 			} else {
-				count, err := calcFullSizesWithRoot(ctx, password, next, retryCount,
+				count, err := calcFullSizesWithRoot(ctx, password, filter, next, retryCount,
 					rsyncProtocol, log)
 				if err != nil {
 					return nil, 0, err
@@ -387,7 +398,7 @@ func searchDownOptimalDir(ctx context.Context, password *string, dir *core.Dir,
 				totalFullSizeCount += count
 
 				if next.Metrics.FullSize.GetByteCount() > blockSize.BackupBlockSize {
-					next, count, err = searchDownOptimalDir(ctx, password, next, retryCount,
+					next, count, err = searchDownOptimalDir(ctx, password, filter, next, retryCount,
 						rsyncProtocol, log, blockSize)
 					if err != nil {
 						return nil, 0, err
@@ -406,17 +417,17 @@ func searchDownOptimalDir(ctx context.Context, password *string, dir *core.Dir,
 				depth, sizes, depths, blockSize.BackupBlockSize)
 
 			LocalLog.Debugf("Get dir by depth %v starting from %q", depth,
-				found.Paths.RsyncSourcePath)
+				found.GetPaths().RsyncSourcePath)
 
 			next := findDownNonMeasuredDirByDepth(found, depth)
-			count, err := calcFullSizesWithRoot(ctx, password, next, retryCount, rsyncProtocol, log)
+			count, err := calcFullSizesWithRoot(ctx, password, filter, next, retryCount, rsyncProtocol, log)
 			if err != nil {
 				return nil, 0, err
 			}
 			totalFullSizeCount += count
 			if next.Metrics.FullSize.GetByteCount() > blockSize.BackupBlockSize && len(next.Childs) > 0 {
 				next = selectChildByWeight(next)
-				next, count, err = searchDownOptimalDir(ctx, password, next, retryCount, rsyncProtocol,
+				next, count, err = searchDownOptimalDir(ctx, password, filter, next, retryCount, rsyncProtocol,
 					log, blockSize)
 				if err != nil {
 					return nil, 0, err
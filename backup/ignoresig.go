@@ -0,0 +1,142 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package backup
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/d2r2/go-rsync/core"
+	"github.com/d2r2/go-rsync/locale"
+	"github.com/d2r2/go-rsync/rsync"
+	shell "github.com/d2r2/go-shell"
+)
+
+// SSH_APP_CMD keep name of SSH client utility, used to
+// manage "skip backup" signature files in remote sources.
+const SSH_APP_CMD = "ssh"
+
+// SkippedFolder describe source folder marked to skip
+// in backup plan with "skip backup" signature file.
+type SkippedFolder struct {
+	// RSYNC source of the module, folder belongs to
+	SourceRsync string
+	// Folder path in RSYNC format
+	Path string
+	// Folder path relative to module root
+	RelativePath string
+	Size         core.FolderSize
+}
+
+// GetSkippedFolders return all folders marked to skip in backup plan.
+func GetSkippedFolders(plan *Plan) []SkippedFolder {
+	var list []SkippedFolder
+	for _, node := range plan.Nodes {
+		list = appendSkippedFolders(list, node.Module.SourceRsync, node.RootDir, "")
+	}
+	return list
+}
+
+func appendSkippedFolders(list []SkippedFolder, sourceRsync string,
+	dir *core.Dir, relativePath string) []SkippedFolder {
+
+	if dir.Metrics.BackupType == core.FBT_SKIP {
+		var size core.FolderSize
+		if dir.Metrics.FullSize != nil {
+			size = *dir.Metrics.FullSize
+		}
+		return append(list, SkippedFolder{SourceRsync: sourceRsync,
+			Path: dir.GetPaths().RsyncSourcePath, RelativePath: relativePath, Size: size})
+	}
+	for _, item := range dir.Childs {
+		list = appendSkippedFolders(list, sourceRsync, item, path.Join(relativePath, item.Name))
+	}
+	return list
+}
+
+// CanManageIgnoreSignature verify that "skip backup" signature file
+// might be created or deleted in the folder: possible for local
+// sources and sources accessed via SSH, but not for RSYNC daemon.
+func CanManageIgnoreSignature(folderPath string) bool {
+	if rsync.IsLocalSource(folderPath) {
+		return true
+	}
+	_, _, ok := rsync.SplitRemoteShellPath(folderPath)
+	return ok
+}
+
+// quoteShellArg protect argument passed to remote shell.
+func quoteShellArg(arg string) string {
+	return "'" + strings.Replace(arg, "'", `'\''`, -1) + "'"
+}
+
+// runSSHCommand execute command in remote host, failing
+// instead of asking for password, if key authentication is not set up.
+func runSSHCommand(host string, command string) error {
+	args := append([]string{"-o", "BatchMode=yes"}, rsync.GetSSHOptions(host)...)
+	app := shell.NewApp(SSH_APP_CMD, append(args, host, command)...)
+	var stdOut, stdErr bytes.Buffer
+	ec := app.Run(&stdOut, &stdErr)
+	if ec.Error != nil {
+		return ec.Error
+	}
+	if ec.ExitCode != 0 {
+		return errors.New(locale.T(MsgIgnoreSignatureSSHCommandFailedError,
+			struct {
+				Host     string
+				ExitCode int
+				Output   string
+			}{Host: host, ExitCode: ec.ExitCode,
+				Output: strings.TrimSpace(stdErr.String())}))
+	}
+	return nil
+}
+
+// SetIgnoreSignature create (skip is true) or delete "skip backup" signature
+// file in the source folder, so folder is skipped (or not) in next backup sessions.
+func SetIgnoreSignature(folderPath, sigFileName string, skip bool) error {
+	if sigFileName == "" {
+		return errors.New(locale.T(MsgIgnoreSignatureFileNameIsEmptyError, nil))
+	}
+	if rsync.IsLocalSource(folderPath) {
+		fileName := filepath.Join(folderPath, sigFileName)
+		if skip {
+			file, err := os.Create(fileName)
+			if err != nil {
+				return err
+			}
+			return file.Close()
+		}
+		err := os.Remove(fileName)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	host, remotePath, ok := rsync.SplitRemoteShellPath(folderPath)
+	if !ok {
+		return errors.New(locale.T(MsgIgnoreSignatureUnsupportedSourceError,
+			struct{ Path string }{Path: folderPath}))
+	}
+	if remotePath == "" {
+		remotePath = "."
+	}
+	fileName := quoteShellArg(path.Join(remotePath, sigFileName))
+	if skip {
+		return runSSHCommand(host, "touch -- "+fileName)
+	}
+	return runSSHCommand(host, "rm -f -- "+fileName)
+}
@@ -0,0 +1,232 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package backup
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/d2r2/go-logger"
+	"github.com/d2r2/go-rsync/locale"
+)
+
+// DEST_LOCK_WRITE_GRACE is a time, while lock file which can't be parsed
+// is considered to be written right now by another session.
+const DEST_LOCK_WRITE_GRACE = 5 * time.Second
+
+// DestLockInfo describe backup session, which hold destination lock.
+type DestLockInfo struct {
+	PID      int       `json:"pid"`
+	Hostname string    `json:"hostname"`
+	Started  time.Time `json:"started"`
+}
+
+// IsStale verify that lock owner process doesn't exist anymore.
+// Process running on another host can't be verified, so lock
+// is never considered stale then.
+func (v *DestLockInfo) IsStale() bool {
+	// Lock file is corrupted.
+	if v.Hostname == "" || v.PID <= 0 {
+		return true
+	}
+	if !v.IsLocal() {
+		return false
+	}
+	err := syscall.Kill(v.PID, 0)
+	return err == syscall.ESRCH
+}
+
+// IsLocal verify that lock owner process run on this host.
+func (v *DestLockInfo) IsLocal() bool {
+	hostname, err := os.Hostname()
+	return err == nil && hostname == v.Hostname
+}
+
+// DestLockHookCall is a delegate used to ask user, whether destination
+// lock left by stale session (or session on another host) should be overridden.
+type DestLockHookCall func(destPath string, info *DestLockInfo, stale bool) (override bool)
+
+// DestLock is a lock file with owner PID and hostname inside the destination
+// root, which prevent two backup sessions writing to the same destination.
+type DestLock struct {
+	fileName string
+}
+
+// ReadDestLock return lock found in destination root, or nil. Lock file,
+// which can't be parsed, is read again until it's completed by owner, or
+// DEST_LOCK_WRITE_GRACE expire, when it's treated as stale.
+func ReadDestLock(destPath string) (*DestLockInfo, error) {
+	fileName := filepath.Join(destPath, GetDestLockFileName())
+	// Modification time of file on network share might be
+	// shifted, so waiting is limited by local clock too.
+	deadline := time.Now().Add(DEST_LOCK_WRITE_GRACE)
+	for {
+		b, err := os.ReadFile(fileName)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		stat, err := os.Stat(fileName)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		info := &DestLockInfo{}
+		err = json.Unmarshal(b, info)
+		if err == nil || time.Since(stat.ModTime()) >= DEST_LOCK_WRITE_GRACE ||
+			time.Now().After(deadline) {
+			// Corrupted lock file is treated as stale.
+			return info, nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// createDestLock atomically create lock file, which fail,
+// if lock file already exists. Lock is written to temporary file
+// and then hard linked to lock file name, so lock is never seen
+// partially written by other sessions.
+func createDestLock(fileName string) error {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return err
+	}
+	info := &DestLockInfo{PID: os.Getpid(), Hostname: hostname, Started: time.Now()}
+	b, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	file, err := ioutil.TempFile(filepath.Dir(fileName), filepath.Base(fileName)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	_, err = file.Write(b)
+	if err2 := file.Close(); err == nil {
+		err = err2
+	}
+	if err != nil {
+		return err
+	}
+	err = os.Chmod(file.Name(), 0644)
+	if err != nil {
+		return err
+	}
+	err = os.Link(file.Name(), fileName)
+	if err == nil || os.IsExist(err) {
+		return err
+	}
+	// File system doesn't support hard links (FAT, some network shares),
+	// so lock is written in place; other sessions wait for completion.
+	return writeDestLockInPlace(fileName, b)
+}
+
+// writeDestLockInPlace create lock file exclusively and write lock
+// description there, which fail, if lock file already exists.
+func writeDestLockInPlace(fileName string, b []byte) error {
+	file, err := os.OpenFile(fileName, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(b)
+	if err2 := file.Close(); err == nil {
+		err = err2
+	}
+	if err != nil {
+		os.Remove(fileName)
+	}
+	return err
+}
+
+// AcquireDestLock lock destination root for backup session. Lock left by
+// dead process on this host is overridden, unless hook reject it; lock of
+// session on another host is overridden, only when hook confirm it.
+func AcquireDestLock(lg logger.PackageLog, destPath string,
+	hook DestLockHookCall) (*DestLock, error) {
+
+	fileName := filepath.Join(destPath, GetDestLockFileName())
+	err := createDestLock(fileName)
+	if err == nil {
+		return &DestLock{fileName: fileName}, nil
+	}
+	if !os.IsExist(err) {
+		return nil, err
+	}
+	info, err := ReadDestLock(destPath)
+	if err != nil {
+		return nil, err
+	}
+	if info == nil {
+		// Lock was released meanwhile.
+		err = createDestLock(fileName)
+		if err != nil {
+			return nil, err
+		}
+		return &DestLock{fileName: fileName}, nil
+	}
+	lockedErr := errors.New(locale.T(MsgLogBackupStageDestLockedError,
+		struct {
+			Path     string
+			PID      int
+			Hostname string
+			Started  string
+		}{Path: destPath, PID: info.PID, Hostname: info.Hostname,
+			Started: info.Started.Format("2006 Jan 2 15:04:05")}))
+	stale := info.IsStale()
+	if !stale && info.IsLocal() {
+		// Lock owner is alive.
+		return nil, lockedErr
+	}
+	override := stale
+	if hook != nil {
+		override = hook(destPath, info, stale)
+	}
+	if !override {
+		return nil, lockedErr
+	}
+	lg.Warn(locale.T(MsgLogBackupStageDestLockOverridden,
+		struct {
+			PID      int
+			Hostname string
+		}{PID: info.PID, Hostname: info.Hostname}))
+	err = os.Remove(fileName)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	err = createDestLock(fileName)
+	if err != nil {
+		if os.IsExist(err) {
+			// Another session was faster.
+			return nil, lockedErr
+		}
+		return nil, err
+	}
+	return &DestLock{fileName: fileName}, nil
+}
+
+// Release remove lock file.
+func (v *DestLock) Release() error {
+	err := os.Remove(v.fileName)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
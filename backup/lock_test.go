@@ -0,0 +1,98 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package backup
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCreateDestLock(t *testing.T) {
+	root, err := ioutil.TempDir("", "lock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	fileName := filepath.Join(root, GetDestLockFileName())
+
+	err = createDestLock(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = createDestLock(fileName)
+	if !os.IsExist(err) {
+		t.Fatalf("second lock error %v, expected to exist", err)
+	}
+	items, err := ioutil.ReadDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("%d files found, expected lock file only", len(items))
+	}
+	info, err := ReadDestLock(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info == nil || info.PID != os.Getpid() || info.IsStale() {
+		t.Fatalf("lock %+v doesn't belong to current process", info)
+	}
+}
+
+func TestReadDestLockPartial(t *testing.T) {
+	root, err := ioutil.TempDir("", "lock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	fileName := filepath.Join(root, GetDestLockFileName())
+
+	// Lock file is completed by owner shortly.
+	err = ioutil.WriteFile(fileName, []byte(`{"pid":`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		hostname, _ := os.Hostname()
+		_ = ioutil.WriteFile(fileName, []byte(`{"pid":`+
+			`1,"hostname":"`+hostname+`"}`), 0644)
+	}()
+	info, err := ReadDestLock(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info == nil || info.PID != 1 || info.IsStale() {
+		t.Fatalf("lock %+v written meanwhile is treated as stale", info)
+	}
+
+	// Lock file left partially written long ago.
+	err = ioutil.WriteFile(fileName, []byte(`{"pid":`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Now().Add(-time.Hour)
+	err = os.Chtimes(fileName, modTime, modTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err = ReadDestLock(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info == nil || !info.IsStale() {
+		t.Fatalf("corrupted lock %+v is not treated as stale", info)
+	}
+}
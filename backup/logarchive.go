@@ -0,0 +1,188 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package backup
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Maximum number of session logs kept in local archive,
+	// older logs are removed on new session start.
+	LOG_ARCHIVE_MAX_FILES = 50
+	// Session log file name prefix and extension in local archive.
+	logArchiveFilePrefix = "session_"
+	logArchiveFileExt    = ".log"
+	// Time layout used in session log file name.
+	logArchiveTimeLayout = "2006-01-02_15-04-05"
+)
+
+// GetLogArchivePath return folder where session logs are archived:
+// $XDG_DATA_HOME/gorsync/logs, or ~/.local/share/gorsync/logs by default.
+func GetLogArchivePath() (string, error) {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		u, err := user.Current()
+		if err != nil {
+			return "", err
+		}
+		dataHome = filepath.Join(u.HomeDir, ".local", "share")
+	}
+	return filepath.Join(dataHome, "gorsync", "logs"), nil
+}
+
+// SessionLogArchive keep copy of backup session log in local archive,
+// so log survive even when backup destination is unreachable.
+type SessionLogArchive struct {
+	sync.Mutex
+	Path string
+	file *os.File
+}
+
+// NewSessionLogArchive create new session log file in local archive
+// and rotate archive to keep not more than LOG_ARCHIVE_MAX_FILES logs.
+// File name contain profile ID, so logs are found after profile rename.
+func NewSessionLogArchive(profile ProfileIdentity) (*SessionLogArchive, error) {
+	dir, err := GetLogArchivePath()
+	if err != nil {
+		return nil, err
+	}
+	err = os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, err
+	}
+	// Reserve space for new file.
+	err = RotateLogArchive(dir, LOG_ARCHIVE_MAX_FILES-1)
+	if err != nil {
+		return nil, err
+	}
+	name := logArchiveFilePrefix + time.Now().Format(logArchiveTimeLayout)
+	if profile.ID != "" {
+		name += "_" + sanitizeLogArchiveName(profile.ID)
+	}
+	if profile.Name != "" {
+		name += "_" + sanitizeLogArchiveName(profile.Name)
+	}
+	path := filepath.Join(dir, name+logArchiveFileExt)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	v := &SessionLogArchive{Path: path, file: file}
+	return v, nil
+}
+
+// sanitizeLogArchiveName replace characters, which are not
+// welcome in file name, with underscore.
+func sanitizeLogArchiveName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|', ' ':
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+// WriteLine append log line to archived session log.
+func (v *SessionLogArchive) WriteLine(line string) error {
+	v.Lock()
+	defer v.Unlock()
+	if v.file == nil {
+		return nil
+	}
+	_, err := io.WriteString(v.file, line)
+	return err
+}
+
+// Close release session log file.
+func (v *SessionLogArchive) Close() error {
+	v.Lock()
+	defer v.Unlock()
+	if v.file == nil {
+		return nil
+	}
+	err := v.file.Close()
+	v.file = nil
+	return err
+}
+
+// ArchivedLog describe session log file found in local archive.
+type ArchivedLog struct {
+	Path    string
+	Name    string
+	ModTime time.Time
+	Size    int64
+}
+
+// ListArchivedLogs return session logs found in local archive
+// ordered from the most recent to the oldest one.
+func ListArchivedLogs() ([]ArchivedLog, error) {
+	dir, err := GetLogArchivePath()
+	if err != nil {
+		return nil, err
+	}
+	return listArchivedLogs(dir)
+}
+
+func listArchivedLogs(dir string) ([]ArchivedLog, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var logs []ArchivedLog
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || !strings.HasPrefix(name, logArchiveFilePrefix) ||
+			!strings.HasSuffix(name, logArchiveFileExt) {
+			continue
+		}
+		logs = append(logs, ArchivedLog{Path: filepath.Join(dir, name),
+			Name:    strings.TrimSuffix(name, logArchiveFileExt),
+			ModTime: file.ModTime(), Size: file.Size()})
+	}
+	// File name starts with session time, so sort by name.
+	sort.Slice(logs, func(i, j int) bool {
+		return logs[i].Name > logs[j].Name
+	})
+	return logs, nil
+}
+
+// RotateLogArchive remove the oldest session logs,
+// to keep not more than maxFiles in archive folder.
+func RotateLogArchive(dir string, maxFiles int) error {
+	logs, err := listArchivedLogs(dir)
+	if err != nil {
+		return err
+	}
+	if maxFiles < 0 {
+		maxFiles = 0
+	}
+	for i := maxFiles; i < len(logs); i++ {
+		err = os.Remove(logs[i].Path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -12,10 +12,13 @@
 package backup
 
 import (
+	"compress/gzip"
+	"io"
 	"io/ioutil"
 	"os"
 	"path"
 
+	"github.com/d2r2/go-rsync/core"
 	shell "github.com/d2r2/go-shell"
 )
 
@@ -23,14 +26,28 @@ import (
 // It has functionality to relocate log files from
 // one storage to another: used when log files moved
 // from /tmp partition to permanent destination location.
+// Log file exceeding size limit is split to compressed
+// segments ("rsync.log.1.gz", "rsync.log.2.gz" and so on).
 type LogFiles struct {
 	rootPath string
 	logs     map[string]*os.File
+	// Log file size limit, 0 to disable segmentation
+	maxSize  int64
+	sizes    map[string]int64
+	segments map[string]int
 }
 
 // NewLogFiles create new LogFiles instance.
 func NewLogFiles() *LogFiles {
-	v := &LogFiles{logs: make(map[string]*os.File)}
+	v := &LogFiles{logs: make(map[string]*os.File),
+		sizes: make(map[string]int64), segments: make(map[string]int)}
+	return v
+}
+
+// SetMaxSize set log file size limit, after which log content
+// is moved to compressed segment. Zero value disable segmentation.
+func (v *LogFiles) SetMaxSize(maxSize int64) *LogFiles {
+	v.maxSize = maxSize
 	return v
 }
 
@@ -48,10 +65,105 @@ func (v *LogFiles) CreateOrGetLogFile(suffixPath string) (*os.File, error) {
 			return nil, err
 		}
 		v.logs[suffixPath] = file
+		// log file might be appended, so take into account existing content
+		if stat, err := file.Stat(); err == nil {
+			v.sizes[suffixPath] = stat.Size()
+		}
+		if _, ok := v.segments[suffixPath]; !ok {
+			v.segments[suffixPath] = v.countSegments(suffixPath)
+		}
 	}
 	return file, nil
 }
 
+// WriteLineFunc return delegate, which append
+// log lines to the file identified by name.
+func (v *LogFiles) WriteLineFunc(suffixPath string) core.WriteLine {
+	return func(line string) error {
+		if v.maxSize > 0 && v.sizes[suffixPath] > 0 &&
+			v.sizes[suffixPath]+int64(len(line)) > v.maxSize {
+			err := v.rotate(suffixPath)
+			if err != nil {
+				return err
+			}
+		}
+		writer, err := v.CreateOrGetLogFile(suffixPath)
+		if err != nil {
+			return err
+		}
+		// ignore error
+		n, _ := io.WriteString(writer, line)
+		v.sizes[suffixPath] += int64(n)
+		return nil
+	}
+}
+
+// getLogSegmentName return name of compressed log segment.
+func getLogSegmentName(suffixPath string, index int) string {
+	return f("%s.%d.gz", suffixPath, index)
+}
+
+// countSegments return number of log segments created before.
+func (v *LogFiles) countSegments(suffixPath string) int {
+	count := 0
+	for {
+		_, err := os.Stat(v.getFullPath(getLogSegmentName(suffixPath, count+1)))
+		if err != nil {
+			return count
+		}
+		count++
+	}
+}
+
+// rotate move log file content to the next compressed segment,
+// so log file start from scratch.
+func (v *LogFiles) rotate(suffixPath string) error {
+	if file := v.logs[suffixPath]; file != nil {
+		err := file.Close()
+		if err != nil {
+			return err
+		}
+		v.logs[suffixPath] = nil
+	}
+	index := v.segments[suffixPath] + 1
+	fullPath := v.getFullPath(suffixPath)
+	err := compressFile(fullPath, v.getFullPath(getLogSegmentName(suffixPath, index)))
+	if err != nil {
+		return err
+	}
+	err = os.Remove(fullPath)
+	if err != nil {
+		return err
+	}
+	v.segments[suffixPath] = index
+	v.sizes[suffixPath] = 0
+	return nil
+}
+
+// compressFile write gzip-compressed copy of the file.
+func compressFile(srcPath, destPath string) error {
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dest, err := os.Create(destPath)
+	if err != nil {
+		return err
+	}
+	defer dest.Close()
+	writer := gzip.NewWriter(dest)
+	_, err = io.Copy(writer, src)
+	if err != nil {
+		return err
+	}
+	err = writer.Close()
+	if err != nil {
+		return err
+	}
+	return dest.Close()
+}
+
 func (v *LogFiles) getFullPath(suffixPath string) string {
 	return path.Join(v.rootPath, suffixPath)
 }
@@ -87,6 +199,13 @@ func (v *LogFiles) ChangeRootPath(newRootPath string) error {
 			if err != nil {
 				return err
 			}
+			for i := 1; i <= v.segments[suffixPath]; i++ {
+				segment := getLogSegmentName(suffixPath, i)
+				_, err = shell.CopyFile(v.getFullPath(segment), path.Join(newRootPath, segment))
+				if err != nil {
+					return err
+				}
+			}
 		}
 	}
 	v.rootPath = newRootPath
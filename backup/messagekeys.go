@@ -27,16 +27,31 @@ const (
 	MsgFolderBackupTypeRecursiveDescription = "FolderBackupTypeRecursiveDescription"
 	MsgFolderBackupTypeContentDescription   = "FolderBackupTypeContentDescription"
 
-	MsgLogPlanStageStarting                  = "LogPlanStageStarting"
-	MsgLogPlanStageStartTime                 = "LogPlanStageStartTime"
-	MsgLogPlanStageEndTime                   = "LogPlanStageEndTime"
-	MsgLogPlanStartIterateViaNSources        = "LogPlanStartIterateViaNSources"
-	MsgLogPlanStageInquirySource             = "LogPlanStageInquirySource"
-	MsgLogPlanStageSourceFolderCountInfo     = "LogPlanStageSourceFolderCountInfo"
-	MsgLogPlanStageSourceSkipFolderCountInfo = "LogPlanStageSourceSkipFolderCountInfo"
-	MsgLogPlanStageSourceTotalSizeInfo       = "LogPlanStageSourceTotalSizeInfo"
-	MsgLogPlanStageUseTemporaryFolder        = "LogPlanStageUseTemporaryFolder"
-	MsgLogPlanStageBuildFolderError          = "LogPlanStageBuildFolderError"
+	MsgLogPlanStageStarting                   = "LogPlanStageStarting"
+	MsgLogPlanStageStartTime                  = "LogPlanStageStartTime"
+	MsgLogPlanStageEndTime                    = "LogPlanStageEndTime"
+	MsgLogPlanStartIterateViaNSources         = "LogPlanStartIterateViaNSources"
+	MsgLogPlanStageInquirySource              = "LogPlanStageInquirySource"
+	MsgLogPlanStageSourceFolderCountInfo      = "LogPlanStageSourceFolderCountInfo"
+	MsgLogPlanStageSourceSkipFolderCountInfo  = "LogPlanStageSourceSkipFolderCountInfo"
+	MsgLogPlanStageSourceTotalSizeInfo        = "LogPlanStageSourceTotalSizeInfo"
+	MsgLogPlanStageUseTemporaryFolder         = "LogPlanStageUseTemporaryFolder"
+	MsgLogPlanStageCreateTemporaryFolderError = "LogPlanStageCreateTemporaryFolderError"
+	MsgLogPlanStageBuildFolderError           = "LogPlanStageBuildFolderError"
+	MsgLogPlanStageRsyncCapabilities          = "LogPlanStageRsyncCapabilities"
+	MsgLogPlanStageAgeLimitsNotApplicable     = "LogPlanStageAgeLimitsNotApplicable"
+	MsgLogPlanStageSkipEstimation             = "LogPlanStageSkipEstimation"
+	MsgLogPlanStageUseStatistics              = "LogPlanStageUseStatistics"
+	MsgLogPlanStageOptionsConflict            = "LogPlanStageOptionsConflict"
+	MsgLogPlanStageOptionsVersionConflict     = "LogPlanStageOptionsVersionConflict"
+	MsgLogPlanStageIconvNotSupported          = "LogPlanStageIconvNotSupported"
+
+	MsgLogPlanStageResuming         = "LogPlanStageResuming"
+	MsgLogPlanStageInterruptedError = "LogPlanStageInterruptedError"
+
+	MsgLogPlanStageUsePlanCache   = "LogPlanStageUsePlanCache"
+	MsgLogPlanStagePlanCacheError = "LogPlanStagePlanCacheError"
+	MsgLogPlanStagePlanTraceError = "LogPlanStagePlanTraceError"
 
 	MsgLogBackupStageStarting                               = "LogBackupStageStarting"
 	MsgLogBackupStageStartTime                              = "LogBackupStageStartTime"
@@ -47,6 +62,8 @@ const (
 	MsgLogBackupStagePreviousBackupFoundAndWillBeUsed       = "LogBackupStagePreviousBackupFoundAndWillBeUsed"
 	MsgLogBackupStagePreviousBackupFoundButDisabled         = "LogBackupStagePreviousBackupFoundButDisabled"
 	MsgLogBackupStagePreviousBackupNotFound                 = "LogBackupStagePreviousBackupNotFound"
+	MsgLogBackupStagePreviousBackupMatchedByAlias           = "LogBackupStagePreviousBackupMatchedByAlias"
+	MsgLogBackupStagePreviousBackupMatchedBySourceHostPath  = "LogBackupStagePreviousBackupMatchedBySourceHostPath"
 	MsgLogBackupStageStartToBackupFromSource                = "LogBackupStageStartToBackupFromSource"
 	MsgLogBackupStageRenameDestination                      = "LogBackupStageRenameDestination"
 	MsgLogBackupStageFailedToCreateFolder                   = "LogBackupStageFailedToCreateFolder"
@@ -57,10 +74,134 @@ const (
 	MsgLogBackupStageCriticalError                          = "LogBackupStageCriticalError"
 	MsgLogBackupStageDiscoveringPreviousBackups             = "LogBackupStageDiscoveringPreviousBackups"
 	MsgLogBackupStageRecoveredFromError                     = "LogBackupStageRecoveredFromError"
+	MsgLogBackupStageModuleSkipped                          = "LogBackupStageModuleSkipped"
+	MsgLogBackupStageGracefulStopError                      = "LogBackupStageGracefulStopError"
+	MsgLogBackupStageDestLockedError                        = "LogBackupStageDestLockedError"
+	MsgLogBackupStageDestLockOverridden                     = "LogBackupStageDestLockOverridden"
+	MsgLogBackupStageSafeDeleteChecking                     = "LogBackupStageSafeDeleteChecking"
+	MsgLogBackupStageSafeDeleteCheckError                   = "LogBackupStageSafeDeleteCheckError"
+	MsgLogBackupStageSafeDeleteFilesDeleted                 = "LogBackupStageSafeDeleteFilesDeleted"
+	MsgLogBackupStageSafeDeleteThresholdExceeded            = "LogBackupStageSafeDeleteThresholdExceeded"
+	MsgLogBackupStageSafeDeleteConfirmed                    = "LogBackupStageSafeDeleteConfirmed"
+	MsgLogBackupStageSafeDeleteRejectedError                = "LogBackupStageSafeDeleteRejectedError"
 	MsgLogBackupStageSaveRsyncExtraLogTo                    = "LogBackupStageSaveRsyncExtraLogTo"
 	MsgLogBackupStageSaveLogTo                              = "LogBackupStageSaveLogTo"
 	MsgLogBackupStageExitMessage                            = "LogBackupStageExitMessage"
 
+	MsgLogBackupStageWaitingForNetwork  = "LogBackupStageWaitingForNetwork"
+	MsgLogBackupStageNetworkRestored    = "LogBackupStageNetworkRestored"
+	MsgLogBackupStageNetworkWaitTimeout = "LogBackupStageNetworkWaitTimeout"
+
+	MsgLogBackupStageRsyncProcessHung       = "LogBackupStageRsyncProcessHung"
+	MsgLogBackupStageRsyncProcessNoOutput   = "LogBackupStageRsyncProcessNoOutput"
+	MsgLogBackupStageRsyncProcessLastOutput = "LogBackupStageRsyncProcessLastOutput"
+
+	MsgLogBackupStageHardLinksNotSupported = "LogBackupStageHardLinksNotSupported"
+	MsgLogBackupStageHardLinksProbeError   = "LogBackupStageHardLinksProbeError"
+	MsgLogBackupStageCatalogCreated        = "LogBackupStageCatalogCreated"
+	MsgLogBackupStageCatalogError          = "LogBackupStageCatalogError"
+	MsgLogBackupStageChecksumsCreated      = "LogBackupStageChecksumsCreated"
+	MsgLogBackupStageChecksumsError        = "LogBackupStageChecksumsError"
+	MsgLogBackupStagePartialDirsError      = "LogBackupStagePartialDirsError"
+	MsgLogBackupStageRsyncLacksOption      = "LogBackupStageRsyncLacksOption"
+	MsgLogBackupStageACLsNotSupported      = "LogBackupStageACLsNotSupported"
+	MsgLogBackupStageXattrsNotSupported    = "LogBackupStageXattrsNotSupported"
+	MsgLogBackupStageAttributesProbeError  = "LogBackupStageAttributesProbeError"
+
+	MsgLogRetryStageStarting           = "LogRetryStageStarting"
+	MsgLogRetryStageNothingToRetry     = "LogRetryStageNothingToRetry"
+	MsgLogRetryStageSourceNotFound     = "LogRetryStageSourceNotFound"
+	MsgLogRetryStageMeasureFolderError = "LogRetryStageMeasureFolderError"
+	MsgLogRetryStageFolderToRetry      = "LogRetryStageFolderToRetry"
+	MsgLogRetryStageRetryListSaved     = "LogRetryStageRetryListSaved"
+
+	MsgLogMountSpecIsEmptyError    = "LogMountSpecIsEmptyError"
+	MsgLogMountDeviceNotFoundError = "LogMountDeviceNotFoundError"
+	MsgLogMountCommandFailedError  = "LogMountCommandFailedError"
+	MsgLogMountPointNotFoundError  = "LogMountPointNotFoundError"
+	MsgLogMountAlreadyMounted      = "LogMountAlreadyMounted"
+	MsgLogMountStarting            = "LogMountStarting"
+	MsgLogMountSucceeded           = "LogMountSucceeded"
+	MsgLogUnmountStarting          = "LogUnmountStarting"
+	MsgLogSyncFileSystemStarting   = "LogSyncFileSystemStarting"
+	MsgLogSyncFileSystemCompleted  = "LogSyncFileSystemCompleted"
+	MsgLogEjectDeviceStarting      = "LogEjectDeviceStarting"
+
+	MsgLogCloudSyncRemoteIsEmptyError = "LogCloudSyncRemoteIsEmptyError"
+	MsgLogCloudSyncAppNotFoundError   = "LogCloudSyncAppNotFoundError"
+	MsgLogCloudSyncCommandFailedError = "LogCloudSyncCommandFailedError"
+	MsgLogCloudSyncStarting           = "LogCloudSyncStarting"
+	MsgLogCloudSyncProgress           = "LogCloudSyncProgress"
+	MsgLogCloudSyncCompleted          = "LogCloudSyncCompleted"
+
+	MsgLogMirrorStarting          = "LogMirrorStarting"
+	MsgLogMirrorCompleted         = "LogMirrorCompleted"
+	MsgLogMirrorDestinationDone   = "LogMirrorDestinationDone"
+	MsgLogMirrorDestinationFailed = "LogMirrorDestinationFailed"
+
+	MsgLogSnapshotNameTemplateError           = "LogSnapshotNameTemplateError"
+	MsgLogSnapshotNameTemplateNotValidError   = "LogSnapshotNameTemplateNotValidError"
+	MsgLogSnapshotNotBtrfsSubvolumeError      = "LogSnapshotNotBtrfsSubvolumeError"
+	MsgLogSnapshotFileSystemNotSupportedError = "LogSnapshotFileSystemNotSupportedError"
+	MsgLogSnapshotCreating                    = "LogSnapshotCreating"
+	MsgLogSnapshotSessionRemoved              = "LogSnapshotSessionRemoved"
+	MsgLogSnapshotSessionFrozen               = "LogSnapshotSessionFrozen"
+	MsgLogSnapshotPruning                     = "LogSnapshotPruning"
+
+	MsgLogQuotaMeasuringUsage  = "LogQuotaMeasuringUsage"
+	MsgLogQuotaUsage           = "LogQuotaUsage"
+	MsgLogQuotaSessionPruned   = "LogQuotaSessionPruned"
+	MsgLogQuotaExceededWarning = "LogQuotaExceededWarning"
+	MsgLogQuotaExceededError   = "LogQuotaExceededError"
+
+	MsgModulePresetHomeDirectory = "ModulePresetHomeDirectory"
+	MsgModulePresetSystemConfig  = "ModulePresetSystemConfig"
+	MsgModulePresetDockerVolumes = "ModulePresetDockerVolumes"
+	MsgModulePresetMaildir       = "ModulePresetMaildir"
+	MsgModulePresetPhotoLibrary  = "ModulePresetPhotoLibrary"
+
+	MsgCheckRsyncInstalled                = "CheckRsyncInstalled"
+	MsgCheckRsyncVersion                  = "CheckRsyncVersion"
+	MsgCheckSourceIsEmpty                 = "CheckSourceIsEmpty"
+	MsgCheckSourceReachable               = "CheckSourceReachable"
+	MsgCheckDestMountWillBeMounted        = "CheckDestMountWillBeMounted"
+	MsgCheckDestMountAlreadyMounted       = "CheckDestMountAlreadyMounted"
+	MsgCheckDestPathIsEmpty               = "CheckDestPathIsEmpty"
+	MsgCheckDestPathUnavailableUntilMount = "CheckDestPathUnavailableUntilMount"
+	MsgCheckDestPathIsNotFolder           = "CheckDestPathIsNotFolder"
+	MsgCheckDestPathExists                = "CheckDestPathExists"
+	MsgCheckDestPathWritable              = "CheckDestPathWritable"
+	MsgCheckDestFreeSpace                 = "CheckDestFreeSpace"
+
+	MsgCheckDestPermissions                 = "CheckDestPermissions"
+	MsgCheckDestPermissionsCantCreateFolder = "CheckDestPermissionsCantCreateFolder"
+	MsgCheckDestFileSystemType              = "CheckDestFileSystemType"
+	MsgCheckDestHardLinksSupported          = "CheckDestHardLinksSupported"
+	MsgCheckDestHardLinksNotSupported       = "CheckDestHardLinksNotSupported"
+	MsgCheckDestWriteSpeed                  = "CheckDestWriteSpeed"
+
+	MsgCheckIntegrityManifestNotFound = "CheckIntegrityManifestNotFound"
+	MsgCheckIntegrityFileMissing      = "CheckIntegrityFileMissing"
+	MsgCheckIntegrityFileCorrupted    = "CheckIntegrityFileCorrupted"
+	MsgCheckIntegritySummaryPassed    = "CheckIntegritySummaryPassed"
+	MsgCheckIntegritySummaryFailed    = "CheckIntegritySummaryFailed"
+
+	MsgValidationDestPathIsEmpty         = "ValidationDestPathIsEmpty"
+	MsgValidationDestPathIsNotExist      = "ValidationDestPathIsNotExist"
+	MsgValidationDestPathIsNotAccessible = "ValidationDestPathIsNotAccessible"
+	MsgValidationModulesAreEmpty         = "ValidationModulesAreEmpty"
+	MsgValidationSourceIsEmpty           = "ValidationSourceIsEmpty"
+	MsgValidationAgeLimitsNotApplicable  = "ValidationAgeLimitsNotApplicable"
+
+	MsgIgnoreSignatureFileNameIsEmptyError   = "IgnoreSignatureFileNameIsEmptyError"
+	MsgIgnoreSignatureUnsupportedSourceError = "IgnoreSignatureUnsupportedSourceError"
+	MsgIgnoreSignatureSSHCommandFailedError  = "IgnoreSignatureSSHCommandFailedError"
+
+	MsgBackupWindowTimeFormatError = "BackupWindowTimeFormatError"
+	MsgBackupWindowOutOfTimeRange  = "BackupWindowOutOfTimeRange"
+	MsgBackupWindowOnBatteryPower  = "BackupWindowOnBatteryPower"
+	MsgBackupWindowMeteredNetwork  = "BackupWindowMeteredNetwork"
+
 	MsgLogStatisticsSummaryCaption                            = "LogStatisticsSummaryCaption"
 	MsgLogStatisticsEnvironmentCaption                        = "LogStatisticsEnvironmentCaption"
 	MsgLogStatisticsResultsCaption                            = "LogStatisticsResultsCaption"
@@ -82,4 +223,32 @@ const (
 	MsgLogStatisticsBackupStageSkippedSize                    = "LogStatisticsBackupStageSkippedSize"
 	MsgLogStatisticsBackupStageFailedToBackupSize             = "LogStatisticsBackupStageFailedToBackupSize"
 	MsgLogStatisticsBackupStageTimeTaken                      = "LogStatisticsBackupStageTimeTaken"
+
+	MsgLogStatisticsBackupStageDeduplicationDisabled = "LogStatisticsBackupStageDeduplicationDisabled"
+	MsgLogStatisticsBackupStageSkippedModules        = "LogStatisticsBackupStageSkippedModules"
+
+	MsgLogStatisticsReconciliationCaption  = "LogStatisticsReconciliationCaption"
+	MsgLogStatisticsReconciliationSource   = "LogStatisticsReconciliationSource"
+	MsgLogStatisticsReconciliationFolder   = "LogStatisticsReconciliationFolder"
+	MsgLogStatisticsReconciliationBadlyOff = "LogStatisticsReconciliationBadlyOff"
+	MsgLogStatisticsReconciliationNoData   = "LogStatisticsReconciliationNoData"
+	MsgLogBackupStageEstimateBadlyOff      = "LogBackupStageEstimateBadlyOff"
+
+	MsgLogBackupStageSavePlanTraceTo = "LogBackupStageSavePlanTraceTo"
+
+	MsgPlanTraceHeader                   = "PlanTraceHeader"
+	MsgPlanTraceSkipEstimationHeader     = "PlanTraceSkipEstimationHeader"
+	MsgPlanTraceBlockSizeFixed           = "PlanTraceBlockSizeFixed"
+	MsgPlanTraceBlockSizeAuto            = "PlanTraceBlockSizeAuto"
+	MsgPlanTraceBlockSizeStatistics      = "PlanTraceBlockSizeStatistics"
+	MsgPlanTraceSkipSignatureFile        = "PlanTraceSkipSignatureFile"
+	MsgPlanTraceRecursiveNotEstimated    = "PlanTraceRecursiveNotEstimated"
+	MsgPlanTraceRecursiveFitBlockSize    = "PlanTraceRecursiveFitBlockSize"
+	MsgPlanTraceRecursiveNoSubfolders    = "PlanTraceRecursiveNoSubfolders"
+	MsgPlanTraceRecursiveExceedBlockSize = "PlanTraceRecursiveExceedBlockSize"
+	MsgPlanTraceContentExceedBlockSize   = "PlanTraceContentExceedBlockSize"
+	MsgPlanTraceContentSubfoldersSplit   = "PlanTraceContentSubfoldersSplit"
+	MsgPlanTraceInsideSkippedFolder      = "PlanTraceInsideSkippedFolder"
+	MsgPlanTraceInsideRecursiveFolder    = "PlanTraceInsideRecursiveFolder"
+	MsgPlanTraceMetricsReused            = "PlanTraceMetricsReused"
 )
@@ -0,0 +1,156 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package backup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/d2r2/go-logger"
+	"github.com/d2r2/go-rsync/core"
+	"github.com/d2r2/go-rsync/locale"
+	"github.com/d2r2/go-rsync/rsync"
+)
+
+// Maximum number of previous sessions in mirror destination,
+// which unchanged files are hard-linked with (--link-dest).
+const MIRROR_LINK_DEST_MAX = 5
+
+// Prefix of session folder, while it is copied to mirror destination.
+const mirrorIncompletePrefix = "~mirror_(incomplete)"
+
+// MirrorResult describe status of session mirroring to one destination.
+type MirrorResult struct {
+	DestPath string
+	Err      error
+}
+
+// ParseMirrorDestPaths split list of mirror destination roots
+// separated by semicolon, skipping empty entries.
+func ParseMirrorDestPaths(paths string) []string {
+	var list []string
+	for _, item := range strings.Split(paths, ";") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// MirrorSession copy completed backup session folder to another destination
+// root with RSYNC. Hard links are preserved inside session and created to
+// the most recent sessions already mirrored, so mirror keep deduplication
+// of primary destination. Session is copied to temporary folder first,
+// so interrupted copy never look like completed session.
+func MirrorSession(ctx context.Context, sessionPath, mirrorPath string,
+	log logger.PackageLog) error {
+
+	err := createDirInBackupStage(mirrorPath)
+	if err != nil {
+		return err
+	}
+	lock, err := AcquireDestLock(log, mirrorPath, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err2 := lock.Release()
+		if err2 != nil {
+			log.Warn(err2)
+		}
+	}()
+
+	log.Info(locale.T(MsgLogMirrorStarting,
+		struct{ Path, Destination string }{Path: sessionPath, Destination: mirrorPath}))
+	startTime := time.Now()
+
+	folder := filepath.Base(sessionPath)
+	destPath := filepath.Join(mirrorPath, folder)
+	tempPath := filepath.Join(mirrorPath, mirrorIncompletePrefix+folder)
+	// Session mirrored before (retry of failed folders) is updated in place.
+	_, err = os.Stat(destPath)
+	completed := err == nil
+	copyPath := tempPath
+	if completed {
+		copyPath = destPath
+	}
+
+	options := rsync.NewOptions(rsync.WithDefaultParams([]string{"--archive",
+		"--hard-links", "--delete"}))
+	sessions, err := ListSessions(mirrorPath)
+	if err != nil {
+		return err
+	}
+	linkCount := 0
+	for _, session := range sessions {
+		name := filepath.Base(session.Path)
+		if name == folder || strings.HasPrefix(name, mirrorIncompletePrefix) {
+			continue
+		}
+		options.AddParams("--link-dest=" + session.Path)
+		linkCount++
+		if linkCount >= MIRROR_LINK_DEST_MAX {
+			break
+		}
+	}
+
+	paths := core.SrcDstPath{RsyncSourcePath: sessionPath + string(os.PathSeparator),
+		DestPath: copyPath}
+	sessionErr, _, _ := rsync.RunRsyncWithRetry(ctx, options, nil, nil, paths)
+	if sessionErr != nil {
+		return sessionErr
+	}
+	if !completed {
+		err = os.Rename(tempPath, destPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Info(locale.T(MsgLogMirrorCompleted,
+		struct{ Destination, Elapsed string }{Destination: destPath,
+			Elapsed: core.FormatDurationToDaysHoursMinsSecs(time.Since(startTime), true, nil)}))
+	return nil
+}
+
+// MirrorSessionToAll copy completed backup session folder to each
+// mirror destination, and report status of every destination to log.
+// Failure of one destination doesn't prevent mirroring to others.
+func MirrorSessionToAll(ctx context.Context, sessionPath string, mirrorPaths []string,
+	log logger.PackageLog) []MirrorResult {
+
+	var results []MirrorResult
+	for _, mirrorPath := range mirrorPaths {
+		if ctx.Err() != nil {
+			break
+		}
+		err := MirrorSession(ctx, sessionPath, mirrorPath, log)
+		results = append(results, MirrorResult{DestPath: mirrorPath, Err: err})
+	}
+	for _, result := range results {
+		if result.Err != nil {
+			log.Warn(locale.T(MsgLogMirrorDestinationFailed,
+				struct {
+					Destination string
+					Error       error
+				}{Destination: result.DestPath, Error: result.Err}))
+		} else {
+			log.Info(locale.T(MsgLogMirrorDestinationDone,
+				struct{ Destination string }{Destination: result.DestPath}))
+		}
+	}
+	return results
+}
@@ -0,0 +1,370 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	logger "github.com/d2r2/go-logger"
+	"github.com/d2r2/go-rsync/locale"
+	shell "github.com/d2r2/go-shell"
+)
+
+const (
+	UDISKS_APP_CMD  = "udisksctl"
+	MOUNT_APP_CMD   = "mount"
+	UNMOUNT_APP_CMD = "umount"
+	SYNC_APP_CMD    = "sync"
+)
+
+// MountTarget describe device, file system UUID/label
+// or network share, which should be mounted before
+// backup session and might be unmounted after.
+// Spec could be specified in next forms:
+// 1) "/dev/sdb1" - block device path;
+// 2) "UUID=..." or "LABEL=..." - file system identifier;
+// 3) "//host/share" or "host:/export" - network share
+// (should be described in /etc/fstab with "user" option).
+type MountTarget struct {
+	Spec    string
+	Unmount bool
+	// Device path resolved from Spec, empty for network share.
+	device string
+	// Mount point path obtained after successful mount.
+	MountPoint string
+	// Flag signify that mount was done by us, so
+	// we are responsible to unmount it after all.
+	mounted bool
+}
+
+// NewMountTarget verify mount specification and create MountTarget object.
+func NewMountTarget(spec string, unmount bool) (*MountTarget, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, errors.New(locale.T(MsgLogMountSpecIsEmptyError, nil))
+	}
+	v := &MountTarget{Spec: spec, Unmount: unmount}
+	if strings.HasPrefix(spec, "UUID=") {
+		v.device = filepath.Join("/dev/disk/by-uuid", strings.TrimPrefix(spec, "UUID="))
+	} else if strings.HasPrefix(spec, "LABEL=") {
+		v.device = filepath.Join("/dev/disk/by-label", strings.TrimPrefix(spec, "LABEL="))
+	} else if strings.HasPrefix(spec, "/dev/") {
+		v.device = spec
+	}
+	return v, nil
+}
+
+// IsNetworkShare returns true, if mount target is not a block device.
+func (v *MountTarget) IsNetworkShare() bool {
+	return v.device == ""
+}
+
+// findMountPoint look through /proc/mounts to find out
+// where device (or network share) is mounted.
+// Return empty string if nothing found.
+func (v *MountTarget) findMountPoint() (string, error) {
+	source := v.Spec
+	if !v.IsNetworkShare() {
+		device, err := filepath.EvalSymlinks(v.device)
+		if err != nil {
+			return "", err
+		}
+		source = device
+	}
+	b, err := ioutil.ReadFile("/proc/mounts")
+	if err != nil {
+		return "", err
+	}
+	scanner := bufio.NewScanner(bytes.NewBuffer(b))
+	scanner.Split(bufio.ScanLines)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		mountSource := fields[0]
+		if !v.IsNetworkShare() {
+			if device, err := filepath.EvalSymlinks(mountSource); err == nil {
+				mountSource = device
+			}
+		}
+		if mountSource == source {
+			// Mount point in /proc/mounts has spaces escaped as octal codes.
+			return strings.Replace(fields[1], `\040`, " ", -1), nil
+		}
+	}
+	return "", nil
+}
+
+// runMountCommand run external mount utility and
+// convert non-zero exit code to localized error.
+func runMountCommand(cmd string, args ...string) error {
+	app := shell.NewApp(cmd, args...)
+	var stdOut, stdErr bytes.Buffer
+	ec := app.Run(&stdOut, &stdErr)
+	if ec.Error != nil {
+		return ec.Error
+	}
+	if ec.ExitCode != 0 {
+		return errors.New(locale.T(MsgLogMountCommandFailedError,
+			struct {
+				Command  string
+				ExitCode int
+				Output   string
+			}{Command: cmd, ExitCode: ec.ExitCode,
+				Output: strings.TrimSpace(stdErr.String())}))
+	}
+	return nil
+}
+
+// Check perform pre-flight verification, that device
+// to mount is present in the system.
+func (v *MountTarget) Check() error {
+	if !v.IsNetworkShare() {
+		if _, err := os.Stat(v.device); err != nil {
+			return errors.New(locale.T(MsgLogMountDeviceNotFoundError,
+				struct {
+					Spec  string
+					Error error
+				}{Spec: v.Spec, Error: err}))
+		}
+	}
+	return nil
+}
+
+// IsMounted verify that device or network share is mounted already.
+func (v *MountTarget) IsMounted() (bool, error) {
+	mountPoint, err := v.findMountPoint()
+	if err != nil {
+		return false, err
+	}
+	return mountPoint != "", nil
+}
+
+// Mount perform pre-flight checks and mount device or network share,
+// if not mounted yet. Use udisks2 for block devices, when available,
+// to not require superuser privileges, otherwise fallback to mount command,
+// which expect corresponding entry in /etc/fstab.
+func (v *MountTarget) Mount(log logger.PackageLog) error {
+	err := v.Check()
+	if err != nil {
+		return err
+	}
+	mountPoint, err := v.findMountPoint()
+	if err != nil {
+		return err
+	}
+	if mountPoint != "" {
+		log.Info(locale.T(MsgLogMountAlreadyMounted,
+			struct{ Spec, MountPoint string }{Spec: v.Spec, MountPoint: mountPoint}))
+		v.MountPoint = mountPoint
+		return nil
+	}
+
+	log.Info(locale.T(MsgLogMountStarting, struct{ Spec string }{Spec: v.Spec}))
+	if !v.IsNetworkShare() && shell.NewApp(UDISKS_APP_CMD).CheckIsInstalled() == nil {
+		err = runMountCommand(UDISKS_APP_CMD, "mount", "--no-user-interaction", "-b", v.device)
+	} else {
+		err = runMountCommand(MOUNT_APP_CMD, v.Spec)
+	}
+	if err != nil {
+		return err
+	}
+
+	mountPoint, err = v.findMountPoint()
+	if err != nil {
+		return err
+	}
+	if mountPoint == "" {
+		return errors.New(locale.T(MsgLogMountPointNotFoundError,
+			struct{ Spec string }{Spec: v.Spec}))
+	}
+	v.MountPoint = mountPoint
+	v.mounted = true
+	log.Info(locale.T(MsgLogMountSucceeded,
+		struct{ Spec, MountPoint string }{Spec: v.Spec, MountPoint: mountPoint}))
+	return nil
+}
+
+// Release unmount device or network share, if it was mounted
+// by Mount call and unmount option is enabled.
+func (v *MountTarget) Release(log logger.PackageLog) error {
+	if !v.mounted || !v.Unmount {
+		return nil
+	}
+	// Might be unmounted already, for instance by removable drive eject.
+	mountPoint, err := v.findMountPoint()
+	if err != nil {
+		return err
+	}
+	if mountPoint == "" {
+		v.mounted = false
+		return nil
+	}
+	log.Info(locale.T(MsgLogUnmountStarting,
+		struct{ Spec, MountPoint string }{Spec: v.Spec, MountPoint: v.MountPoint}))
+	if !v.IsNetworkShare() && shell.NewApp(UDISKS_APP_CMD).CheckIsInstalled() == nil {
+		err = runMountCommand(UDISKS_APP_CMD, "unmount", "--no-user-interaction", "-b", v.device)
+	} else {
+		err = runMountCommand(UNMOUNT_APP_CMD, v.MountPoint)
+	}
+	if err != nil {
+		return err
+	}
+	v.mounted = false
+	return nil
+}
+
+// SyncFileSystem flush file system buffers of path to disk.
+// Use "sync -f" to flush only file system containing path,
+// otherwise fallback to flush of all file systems.
+func SyncFileSystem(path string, log logger.PackageLog) error {
+	log.Info(locale.T(MsgLogSyncFileSystemStarting, struct{ Path string }{Path: path}))
+	if shell.NewApp(SYNC_APP_CMD).CheckIsInstalled() == nil {
+		err := runMountCommand(SYNC_APP_CMD, "-f", path)
+		if err != nil {
+			return err
+		}
+	} else {
+		syscall.Sync()
+	}
+	log.Info(locale.T(MsgLogSyncFileSystemCompleted, struct{ Path string }{Path: path}))
+	return nil
+}
+
+// findMountEntry look through /proc/mounts to find out
+// mount source (block device, network share, ZFS dataset
+// and so on) and mount point, which contain path.
+func findMountEntry(path string) (source string, mountPoint string, err error) {
+	path, err = filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", "", err
+	}
+	b, err := ioutil.ReadFile("/proc/mounts")
+	if err != nil {
+		return "", "", err
+	}
+	scanner := bufio.NewScanner(bytes.NewBuffer(b))
+	scanner.Split(bufio.ScanLines)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		// Mount point in /proc/mounts has spaces escaped as octal codes.
+		mp := strings.Replace(fields[1], `\040`, " ", -1)
+		if (path == mp || strings.HasPrefix(path, strings.TrimSuffix(mp, "/")+"/")) &&
+			len(mp) >= len(mountPoint) {
+			source, mountPoint = fields[0], mp
+		}
+	}
+	return source, mountPoint, nil
+}
+
+// findMountSource look through /proc/mounts to find out
+// block device, which contain path. Return empty string,
+// if path located on virtual file system or network share.
+func findMountSource(path string) (string, error) {
+	source, _, err := findMountEntry(path)
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(source, "/dev/") {
+		return "", nil
+	}
+	return filepath.EvalSymlinks(source)
+}
+
+// FindRemovableDevice find out block device (partition), which contain
+// path, and verify that device belongs to removable drive (USB stick,
+// external disk and so on). Return device and drive paths or empty strings
+// if path is not located on removable drive.
+func FindRemovableDevice(path string) (device string, drive string, err error) {
+	device, err = findMountSource(path)
+	if err != nil || device == "" {
+		return "", "", err
+	}
+	sysPath, err := filepath.EvalSymlinks(filepath.Join("/sys/class/block", filepath.Base(device)))
+	if err != nil {
+		return "", "", err
+	}
+	drivePath := sysPath
+	// Partition keep drive attributes in parent folder.
+	if _, err := os.Stat(filepath.Join(sysPath, "partition")); err == nil {
+		drivePath = filepath.Dir(sysPath)
+	}
+	removable := strings.Contains(drivePath, "/usb")
+	if buf, err := ioutil.ReadFile(filepath.Join(drivePath, "removable")); err == nil &&
+		strings.TrimSpace(string(buf)) == "1" {
+		removable = true
+	}
+	if !removable {
+		return "", "", nil
+	}
+	return device, filepath.Join("/dev", filepath.Base(drivePath)), nil
+}
+
+// EjectRemovableDevice unmount removable drive partition via udisks2
+// and power off drive, so it might be safely unplugged.
+func EjectRemovableDevice(device, drive string, log logger.PackageLog) error {
+	err := shell.NewApp(UDISKS_APP_CMD).CheckIsInstalled()
+	if err != nil {
+		return err
+	}
+	log.Info(locale.T(MsgLogEjectDeviceStarting, struct{ Device string }{Device: device}))
+	err = runMountCommand(UDISKS_APP_CMD, "unmount", "--no-user-interaction", "-b", device)
+	if err != nil {
+		return err
+	}
+	// Drive might be not capable to power off, which is not critical.
+	err = runMountCommand(UDISKS_APP_CMD, "power-off", "--no-user-interaction", "-b", drive)
+	if err != nil {
+		log.Warn(err)
+	}
+	return nil
+}
+
+// FindFileSystemUUID find out file system UUID of block device (partition),
+// which contain path, and mount point of this device. Return empty strings,
+// if path located on virtual file system or network share.
+func FindFileSystemUUID(path string) (uuid string, mountPoint string, err error) {
+	source, mountPoint, err := findMountEntry(path)
+	if err != nil || !strings.HasPrefix(source, "/dev/") {
+		return "", "", err
+	}
+	device, err := filepath.EvalSymlinks(source)
+	if err != nil {
+		return "", "", err
+	}
+	const byUUID = "/dev/disk/by-uuid"
+	items, err := ioutil.ReadDir(byUUID)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", "", nil
+		}
+		return "", "", err
+	}
+	for _, item := range items {
+		target, err := filepath.EvalSymlinks(filepath.Join(byUUID, item.Name()))
+		if err == nil && target == device {
+			return item.Name(), mountPoint, nil
+		}
+	}
+	return "", "", nil
+}
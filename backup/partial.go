@@ -0,0 +1,65 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package backup
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/d2r2/go-rsync/locale"
+	"github.com/d2r2/go-rsync/rsync"
+)
+
+// RemovePartialDirs delete RSYNC partial dirs, which keep files
+// left by interrupted transfers, from the session folder.
+// Return number of folders removed.
+func RemovePartialDirs(sessionPath string) (int, error) {
+	var found []string
+	err := filepath.Walk(sessionPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && info.Name() == rsync.PARTIAL_DIR {
+			found = append(found, path)
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	for _, path := range found {
+		err = os.RemoveAll(path)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(found), nil
+}
+
+// cleanPartialDirs remove RSYNC partial dirs from session folders
+// once backup completed, since partially transferred files
+// are of no use in the final session.
+func cleanPartialDirs(progress *Progress, sessionPaths []string) {
+	for _, path := range sessionPaths {
+		count, err := RemovePartialDirs(path)
+		if err != nil {
+			progress.Log.Warn(locale.T(MsgLogBackupStagePartialDirsError,
+				struct {
+					Path  string
+					Error error
+				}{Path: path, Error: err}))
+			continue
+		}
+		LocalLog.Debugf("Partial dirs removed from %q: %d", path, count)
+	}
+}
@@ -0,0 +1,226 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package backup
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io/ioutil"
+	"os"
+	"os/user"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/d2r2/go-rsync/core"
+	"github.com/d2r2/go-rsync/rsync"
+)
+
+// PLAN_CACHE_MAX_AGE define how long folder metrics measured in plan stage
+// are reused. Metrics are validated against sizes and modification times
+// of files, but anyway refreshed from time to time completely.
+const PLAN_CACHE_MAX_AGE = 7 * 24 * time.Hour
+
+// GetPlanCachePath return folder where plan stage metrics are cached:
+// $XDG_CACHE_HOME/gorsync/plans, or ~/.cache/gorsync/plans by default.
+func GetPlanCachePath() (string, error) {
+	cacheHome := os.Getenv("XDG_CACHE_HOME")
+	if cacheHome == "" {
+		u, err := user.Current()
+		if err != nil {
+			return "", err
+		}
+		cacheHome = filepath.Join(u.HomeDir, ".cache")
+	}
+	return filepath.Join(cacheHome, "gorsync", "plans"), nil
+}
+
+// planCacheFolder keep metrics of single folder measured in plan stage.
+type planCacheFolder struct {
+	ModTime int64 `json:"mtime"`
+	// Hash of names, sizes and modification times of folder files.
+	FilesHash uint64 `json:"files_hash"`
+	// Hash of folder and file names, sizes and modification
+	// times of the whole subtree.
+	TreeHash uint64           `json:"tree_hash"`
+	Size     *core.FolderSize `json:"size,omitempty"`
+	FullSize *core.FolderSize `json:"full_size,omitempty"`
+}
+
+// planCache keep folder metrics measured in plan stage of previous
+// session for RSYNC source, to skip measurement of folders not changed
+// since then. Folders are indexed by path relative to source root.
+type planCache struct {
+	Source  string                     `json:"source"`
+	Filter  string                     `json:"filter"`
+	Time    time.Time                  `json:"time"`
+	Folders map[string]planCacheFolder `json:"folders"`
+}
+
+// getPlanCacheFilePath return cache file location for RSYNC source.
+func getPlanCacheFilePath(sourceRsync string) (string, error) {
+	dir, err := GetPlanCachePath()
+	if err != nil {
+		return "", err
+	}
+	h := fnv.New64a()
+	h.Write([]byte(sourceRsync))
+	return filepath.Join(dir, fmt.Sprintf("%016x.json", h.Sum64())), nil
+}
+
+// getPlanCacheFilter describe file filter, which affect measured sizes.
+func getPlanCacheFilter(filter *rsync.FileFilter) string {
+	if filter.IsEmpty() {
+		return ""
+	}
+	b, _ := json.Marshal(filter)
+	return string(b)
+}
+
+// loadPlanCache read metrics cached for RSYNC source. Return nil,
+// if nothing cached yet, cache is outdated or made with other filter.
+func loadPlanCache(sourceRsync string, filter *rsync.FileFilter) (*planCache, error) {
+	filePath, err := getPlanCacheFilePath(sourceRsync)
+	if err != nil {
+		return nil, err
+	}
+	b, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	cache := &planCache{}
+	err = json.Unmarshal(b, cache)
+	if err != nil {
+		return nil, err
+	}
+	if cache.Source != sourceRsync || cache.Filter != getPlanCacheFilter(filter) ||
+		time.Since(cache.Time) > PLAN_CACHE_MAX_AGE {
+		return nil, nil
+	}
+	return cache, nil
+}
+
+// getPlanCacheFileHashes calculate hash of names, sizes and modification
+// times of files listed by RSYNC for each folder, indexed by path relative
+// to source root. Hash doesn't depend on listing order.
+func getPlanCacheFileHashes(entries []core.DirTreeEntry) map[string]uint64 {
+	hashes := make(map[string]uint64)
+	var buf [8]byte
+	for _, item := range entries {
+		if item.IsDir {
+			continue
+		}
+		// root folder listed as "."
+		itemPath := strings.Trim(path.Clean("/"+item.Path), "/")
+		parent := path.Dir(itemPath)
+		if parent == "." {
+			parent = ""
+		}
+		h := fnv.New64a()
+		h.Write([]byte(path.Base(itemPath)))
+		binary.LittleEndian.PutUint64(buf[:], uint64(item.Size))
+		h.Write(buf[:])
+		binary.LittleEndian.PutUint64(buf[:], uint64(item.ModTime.Unix()))
+		h.Write(buf[:])
+		hashes[parent] += h.Sum64()
+	}
+	return hashes
+}
+
+// apply assign cached metrics to folders not changed since cache was made:
+// "local size" is reused, if folder itself and its files are not changed,
+// "full size" - if nothing is changed in the whole subtree. Return number
+// of folders with metrics reused.
+func (v *planCache) apply(root *core.Dir, fileHashes map[string]uint64) int {
+	count := 0
+	walkDirTreeHashes(root, "", fileHashes, func(dir *core.Dir, relPath string, treeHash uint64) {
+		item, ok := v.Folders[relPath]
+		if !ok || dir.Metrics.ModTime == 0 || item.ModTime != dir.Metrics.ModTime ||
+			item.FilesHash != fileHashes[relPath] {
+			return
+		}
+		if item.Size != nil {
+			size := *item.Size
+			dir.Metrics.Size = &size
+		}
+		if item.FullSize != nil && item.TreeHash == treeHash {
+			fullSize := *item.FullSize
+			dir.Metrics.FullSize = &fullSize
+		}
+		if dir.Metrics.Size != nil || dir.Metrics.FullSize != nil {
+			count++
+		}
+	})
+	return count
+}
+
+// savePlanCache store metrics of folders measured in plan stage.
+func savePlanCache(sourceRsync string, filter *rsync.FileFilter, root *core.Dir,
+	fileHashes map[string]uint64) error {
+
+	filePath, err := getPlanCacheFilePath(sourceRsync)
+	if err != nil {
+		return err
+	}
+	cache := &planCache{Source: sourceRsync, Filter: getPlanCacheFilter(filter),
+		Time: time.Now(), Folders: make(map[string]planCacheFolder)}
+	walkDirTreeHashes(root, "", fileHashes, func(dir *core.Dir, relPath string, treeHash uint64) {
+		if dir.Metrics.ModTime == 0 || dir.Metrics.Size == nil && dir.Metrics.FullSize == nil {
+			return
+		}
+		cache.Folders[relPath] = planCacheFolder{ModTime: dir.Metrics.ModTime,
+			FilesHash: fileHashes[relPath], TreeHash: treeHash,
+			Size: dir.Metrics.Size, FullSize: dir.Metrics.FullSize}
+	})
+	b, err := json.Marshal(cache)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(filePath), 0700)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filePath, b, 0600)
+}
+
+// walkDirTreeHashes calculate hash of names and modification times
+// of folder subtree, along with hashes of folder files, and call visit
+// for each folder with its relative path, once hash of folder is known.
+// Child folders are ordered by name, so hash doesn't depend on listing order.
+func walkDirTreeHashes(dir *core.Dir, relPath string, fileHashes map[string]uint64,
+	visit func(dir *core.Dir, relPath string, treeHash uint64)) uint64 {
+
+	h := fnv.New64a()
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], uint64(dir.Metrics.ModTime))
+	h.Write(buf[:])
+	if dir.Metrics.IgnoreToBackup {
+		h.Write([]byte{1})
+	}
+	binary.LittleEndian.PutUint64(buf[:], fileHashes[relPath])
+	h.Write(buf[:])
+	for _, item := range dir.Childs {
+		h.Write([]byte(item.Name))
+		binary.LittleEndian.PutUint64(buf[:],
+			walkDirTreeHashes(item, path.Join(relPath, item.Name), fileHashes, visit))
+		h.Write(buf[:])
+	}
+	treeHash := h.Sum64()
+	visit(dir, relPath, treeHash)
+	return treeHash
+}
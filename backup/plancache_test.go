@@ -0,0 +1,110 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package backup
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/d2r2/go-rsync/core"
+)
+
+func TestPlanCacheApply(t *testing.T) {
+	cacheHome, err := ioutil.TempDir("", "plancache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(cacheHome)
+	t.Setenv("XDG_CACHE_HOME", cacheHome)
+
+	const source = "rsync://nas/data/"
+	mtime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	listing := func(changed ...core.DirTreeEntry) []core.DirTreeEntry {
+		entries := []core.DirTreeEntry{
+			{Path: ".", IsDir: true, ModTime: mtime},
+			{Path: "a", IsDir: true, ModTime: mtime},
+			{Path: "a/b", IsDir: true, ModTime: mtime},
+			{Path: "c", IsDir: true, ModTime: mtime},
+			{Path: "root.txt", Size: 10, ModTime: mtime},
+			{Path: "a/b/file.txt", Size: 100, ModTime: mtime},
+			{Path: "c/file.txt", Size: 200, ModTime: mtime},
+		}
+		for _, item := range changed {
+			for i := range entries {
+				if entries[i].Path == item.Path {
+					entries[i] = item
+				}
+			}
+		}
+		return entries
+	}
+	buildTree := func(entries []core.DirTreeEntry) map[string]*core.Dir {
+		root := core.BuildDirTreeFromList(core.SrcDstPath{RsyncSourcePath: source}, "data",
+			entries, "")
+		a := root.Childs[0]
+		return map[string]*core.Dir{"": root, "a": a, "a/b": a.Childs[0], "c": root.Childs[1]}
+	}
+
+	dirs := buildTree(listing())
+	for _, dir := range dirs {
+		size := core.FolderSize(1)
+		dir.Metrics.Size = &size
+		dir.Metrics.FullSize = &size
+	}
+	err = savePlanCache(source, nil, dirs[""], getPlanCacheFileHashes(listing()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		changed  []core.DirTreeEntry
+		size     []string
+		fullSize []string
+	}{
+		{"nothing changed", nil,
+			[]string{"", "a", "a/b", "c"}, []string{"", "a", "a/b", "c"}},
+		{"file modified in place", []core.DirTreeEntry{{Path: "a/b/file.txt", Size: 100,
+			ModTime: mtime.Add(time.Minute)}},
+			[]string{"", "a", "c"}, []string{"c"}},
+		{"file size changed", []core.DirTreeEntry{{Path: "root.txt", Size: 11, ModTime: mtime}},
+			[]string{"a", "a/b", "c"}, []string{"a", "a/b", "c"}},
+		{"folder changed", []core.DirTreeEntry{{Path: "c", IsDir: true,
+			ModTime: mtime.Add(time.Minute)}},
+			[]string{"", "a", "a/b"}, []string{"a", "a/b"}},
+	}
+	for _, test := range tests {
+		cache, err := loadPlanCache(source, nil)
+		if err != nil || cache == nil {
+			t.Fatalf("%s: cache not loaded: %v", test.name, err)
+		}
+		entries := listing(test.changed...)
+		dirs := buildTree(entries)
+		cache.apply(dirs[""], getPlanCacheFileHashes(entries))
+		check := func(kind string, reused []string, get func(dir *core.Dir) *core.FolderSize) {
+			expected := make(map[string]bool)
+			for _, item := range reused {
+				expected[item] = true
+			}
+			for relPath, dir := range dirs {
+				if (get(dir) != nil) != expected[relPath] {
+					t.Errorf("%s: %s of folder %q reused %v, expected %v", test.name, kind,
+						relPath, get(dir) != nil, expected[relPath])
+				}
+			}
+		}
+		check("size", test.size, func(dir *core.Dir) *core.FolderSize { return dir.Metrics.Size })
+		check("full size", test.fullSize, func(dir *core.Dir) *core.FolderSize { return dir.Metrics.FullSize })
+	}
+}
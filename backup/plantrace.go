@@ -0,0 +1,175 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package backup
+
+import (
+	"path"
+
+	"github.com/d2r2/go-rsync/core"
+	"github.com/d2r2/go-rsync/locale"
+)
+
+// Folder decisions written to plan trace file.
+const (
+	planTraceRecursive = "recursive"
+	planTraceContent   = "content"
+	planTraceSkip      = "skip"
+	// Folder backed up within parent folder.
+	planTraceIncluded = "included"
+	// Folder skipped along with parent folder.
+	planTraceSkipped = "skipped"
+)
+
+// collectMeasuredDirs return folders, which metrics are known before
+// heuristic search, i.e. reused from previous session.
+func collectMeasuredDirs(dir *core.Dir, measured map[*core.Dir]bool) map[*core.Dir]bool {
+	if measured == nil {
+		measured = make(map[*core.Dir]bool)
+	}
+	if dir.Metrics.Size != nil || dir.Metrics.FullSize != nil {
+		measured[dir] = true
+	}
+	for _, item := range dir.Childs {
+		collectMeasuredDirs(item, measured)
+	}
+	return measured
+}
+
+// writePlanTrace record to plan trace file, which backup type was assigned
+// in plan stage to every folder of RSYNC source and why. Help to find out
+// why folders are skipped or backed up one-by-one.
+func writePlanTrace(writeLine core.WriteLine, module *Module, dir *core.Dir,
+	blockSize *backupBlockSizeSettings, cached map[*core.Dir]bool, sigFileIgnoreBackup string) error {
+
+	if module.SkipPlanEstimation {
+		// Folder sizes are not known, so whole source backed up in single RSYNC call.
+		err := writeLine(f("# %s\n", locale.T(MsgPlanTraceSkipEstimationHeader,
+			struct{ RsyncSource string }{RsyncSource: module.SourceRsync})))
+		if err != nil {
+			return err
+		}
+		err = writePlanTraceLine(writeLine, planTraceRecursive, nil, nil, ".",
+			locale.T(MsgPlanTraceRecursiveNotEstimated, nil))
+		if err != nil {
+			return err
+		}
+		return writeLine("\n")
+	}
+
+	mode := locale.T(MsgPlanTraceBlockSizeFixed, nil)
+	if blockSize.AutoManageBackupBlockSize && blockSize.Statistics != nil {
+		mode = locale.T(MsgPlanTraceBlockSizeStatistics, nil)
+	} else if blockSize.AutoManageBackupBlockSize {
+		mode = locale.T(MsgPlanTraceBlockSizeAuto, nil)
+	}
+	err := writeLine(f("# %s\n", locale.T(MsgPlanTraceHeader,
+		struct{ RsyncSource, BlockSize, Mode string }{RsyncSource: module.SourceRsync,
+			BlockSize: core.FormatSize(blockSize.BackupBlockSize, true), Mode: mode})))
+	if err != nil {
+		return err
+	}
+	err = writePlanTraceDir(writeLine, dir, ".", "", blockSize.BackupBlockSize,
+		cached, sigFileIgnoreBackup)
+	if err != nil {
+		return err
+	}
+	return writeLine("\n")
+}
+
+// writePlanTraceLine write single folder decision: backup type, full size,
+// local size (files only), path relative to RSYNC source and explanation.
+func writePlanTraceLine(writeLine core.WriteLine, decision string,
+	fullSize, size *core.FolderSize, relPath, reason string) error {
+
+	formatSize := func(size *core.FolderSize) string {
+		if size == nil {
+			return "-"
+		}
+		return core.FormatSize(size.GetByteCount(), true)
+	}
+	return writeLine(f("%-9s %10s %10s  %s  (%s)\n", decision, formatSize(fullSize),
+		formatSize(size), relPath, reason))
+}
+
+func writePlanTraceDir(writeLine core.WriteLine, dir *core.Dir, relPath, parentDecision string,
+	blockSize uint64, cached map[*core.Dir]bool, sigFileIgnoreBackup string) error {
+
+	decision, reason := getPlanTraceDecision(dir, parentDecision, blockSize, sigFileIgnoreBackup)
+	if cached[dir] {
+		reason += "; " + locale.T(MsgPlanTraceMetricsReused, nil)
+	}
+	err := writePlanTraceLine(writeLine, decision, dir.Metrics.FullSize, dir.Metrics.Size,
+		relPath, reason)
+	if err != nil {
+		return err
+	}
+	for _, item := range dir.Childs {
+		err = writePlanTraceDir(writeLine, item, path.Join(relPath, item.Name), decision,
+			blockSize, cached, sigFileIgnoreBackup)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getPlanTraceDecision return folder decision made in plan stage and its explanation.
+func getPlanTraceDecision(dir *core.Dir, parentDecision string, blockSize uint64,
+	sigFileIgnoreBackup string) (string, string) {
+
+	fullSize := func() string {
+		return core.FormatSize(dir.Metrics.FullSize.GetByteCount(), true)
+	}
+	switch dir.Metrics.BackupType {
+	case core.FBT_RECURSIVE:
+		if dir.Metrics.FullSize == nil {
+			return planTraceRecursive, locale.T(MsgPlanTraceRecursiveNotEstimated, nil)
+		}
+		data := struct{ FullSize, BlockSize string }{FullSize: fullSize(),
+			BlockSize: core.FormatSize(blockSize, true)}
+		if dir.Metrics.FullSize.GetByteCount() <= blockSize {
+			return planTraceRecursive, locale.T(MsgPlanTraceRecursiveFitBlockSize, data)
+		} else if len(dir.Childs) == 0 {
+			return planTraceRecursive, locale.T(MsgPlanTraceRecursiveNoSubfolders, data)
+		}
+		return planTraceRecursive, locale.T(MsgPlanTraceRecursiveExceedBlockSize, data)
+	case core.FBT_SKIP:
+		return planTraceSkip, locale.T(MsgPlanTraceSkipSignatureFile,
+			struct{ FileName string }{FileName: sigFileIgnoreBackup})
+	case core.FBT_CONTENT:
+		if dir.Metrics.FullSize != nil && dir.Metrics.FullSize.GetByteCount() > blockSize {
+			return planTraceContent, locale.T(MsgPlanTraceContentExceedBlockSize,
+				struct{ FullSize, BlockSize string }{FullSize: fullSize(),
+					BlockSize: core.FormatSize(blockSize, true)})
+		}
+		return planTraceContent, locale.T(MsgPlanTraceContentSubfoldersSplit, nil)
+	}
+	switch parentDecision {
+	case planTraceSkip, planTraceSkipped:
+		return planTraceSkipped, locale.T(MsgPlanTraceInsideSkippedFolder, nil)
+	default:
+		return planTraceIncluded, locale.T(MsgPlanTraceInsideRecursiveFolder, nil)
+	}
+}
+
+// savePlanTrace append module folders decisions to plan trace file,
+// which is saved later to backup session folder along with logs.
+func savePlanTrace(progress *Progress, module *Module, dir *core.Dir,
+	blockSize *backupBlockSizeSettings, cached map[*core.Dir]bool, sigFileIgnoreBackup string) {
+
+	err := writePlanTrace(progress.LogFiles.WriteLineFunc(GetPlanTraceFileName()),
+		module, dir, blockSize, cached, sigFileIgnoreBackup)
+	if err != nil {
+		progress.Log.Warn(locale.T(MsgLogPlanStagePlanTraceError,
+			struct{ Error error }{Error: err}))
+	}
+}
@@ -0,0 +1,69 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package backup
+
+import (
+	"os/user"
+	"path/filepath"
+
+	"github.com/d2r2/go-rsync/locale"
+)
+
+// ModulePreset describe typical backup source with
+// pre-filled RSYNC source path and exclude patterns.
+type ModulePreset struct {
+	// Message key of preset name.
+	NameKey string
+	// RSYNC source path, relative paths are resolved
+	// against user home directory.
+	SourceRsync     string
+	DestSubPath     string
+	ExcludePatterns []string
+}
+
+// Name return localized preset name.
+func (v *ModulePreset) Name() string {
+	return locale.T(v.NameKey, nil)
+}
+
+// GetSourceRsync return RSYNC source path with
+// relative path resolved against user home directory.
+// Trailing slash is kept to backup folder content.
+func (v *ModulePreset) GetSourceRsync() (string, error) {
+	if filepath.IsAbs(v.SourceRsync) {
+		return v.SourceRsync, nil
+	}
+	u, err := user.Current()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(u.HomeDir, v.SourceRsync) + "/", nil
+}
+
+// GetModulePresets return library of common backup sources.
+func GetModulePresets() []ModulePreset {
+	return []ModulePreset{
+		{NameKey: MsgModulePresetHomeDirectory, SourceRsync: ".", DestSubPath: "home",
+			ExcludePatterns: []string{".cache/", ".local/share/Trash/", ".thumbnails/",
+				"node_modules/", "*~", "*.tmp"}},
+		{NameKey: MsgModulePresetSystemConfig, SourceRsync: "/etc/", DestSubPath: "etc",
+			ExcludePatterns: []string{"*.swp", "*~"}},
+		{NameKey: MsgModulePresetDockerVolumes, SourceRsync: "/var/lib/docker/volumes/",
+			DestSubPath:     "docker_volumes",
+			ExcludePatterns: []string{"*.sock", "*.pid", "*.lock"}},
+		{NameKey: MsgModulePresetMaildir, SourceRsync: "Maildir", DestSubPath: "maildir",
+			ExcludePatterns: []string{"tmp/", "dovecot.index.cache", "dovecot-uidlist.lock"}},
+		{NameKey: MsgModulePresetPhotoLibrary, SourceRsync: "Pictures", DestSubPath: "photos",
+			ExcludePatterns: []string{".thumbnails/", ".cache/", "Thumbs.db", ".DS_Store",
+				"*.tmp", "*.part"}},
+	}
+}
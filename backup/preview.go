@@ -0,0 +1,82 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package backup
+
+import (
+	"time"
+
+	"github.com/d2r2/go-rsync/core"
+	shell "github.com/d2r2/go-shell"
+)
+
+// SourcePreview keep plan stage result for single RSYNC source.
+type SourcePreview struct {
+	SourceRsync string
+	Size        core.FolderSize
+	// Size is unknown, since plan stage estimation skipped.
+	SizeUnknown bool
+	// Estimated duration of backup, zero if no throughput
+	// statistics found in previous sessions.
+	Duration time.Duration
+}
+
+// SessionPreview summarize backup plan before 2nd stage start:
+// size to backup, duration estimated from throughput achieved
+// in previous sessions, and destination free space.
+type SessionPreview struct {
+	Sources   []SourcePreview
+	TotalSize core.FolderSize
+	Duration  time.Duration
+	// Some sources lack throughput statistics or size,
+	// so total duration is underestimated.
+	DurationPartial bool
+	FreeSpace       uint64
+	// Not nil, if destination free space can't be obtained.
+	FreeSpaceErr error
+}
+
+// EnoughSpace verify destination free space exceed size to backup.
+// Return true, if free space is unknown.
+func (v *SessionPreview) EnoughSpace() bool {
+	return v.FreeSpaceErr != nil || v.FreeSpace >= v.TotalSize.GetByteCount()
+}
+
+// Preview summarize plan built in 1st stage to confirm
+// backup start. Throughput statistics taken from
+// Config.ModuleStatistics.
+func (v *Plan) Preview(destPath string) *SessionPreview {
+	preview := &SessionPreview{TotalSize: v.BackupSize}
+	for _, node := range v.Nodes {
+		item := SourcePreview{SourceRsync: node.Module.SourceRsync,
+			SizeUnknown: node.Module.SkipPlanEstimation}
+		if !item.SizeUnknown && node.RootDir != nil {
+			item.Size = node.RootDir.GetTotalSize()
+		}
+		stats, ok := v.Config.ModuleStatistics[GenerateSourceID(node.Module.SourceRsync)]
+		if ok && stats.Throughput > 0 {
+			size := float64(item.Size.GetByteCount())
+			// convert plan stage size to actual one
+			if stats.EstimateRatio > 0 {
+				size *= stats.EstimateRatio
+			}
+			item.Duration = stats.CallOverhead +
+				time.Duration(size/float64(stats.Throughput)*float64(time.Second))
+		}
+		if item.SizeUnknown || (item.Duration == 0 && item.Size > 0) {
+			preview.DurationPartial = true
+		}
+		preview.Duration += item.Duration
+		preview.Sources = append(preview.Sources, item)
+	}
+	preview.FreeSpace, preview.FreeSpaceErr = shell.GetFreeSpace(destPath)
+	return preview
+}
@@ -14,12 +14,11 @@ package backup
 import (
 	"context"
 	"errors"
-	"io"
-	"io/ioutil"
 	"os"
 	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
 	logger "github.com/d2r2/go-logger"
 	"github.com/d2r2/go-rsync/core"
@@ -37,42 +36,18 @@ var (
 // BuildBackupPlan perform 1st stage (plan stage) to measure RSYNC source volume
 // to backup and find optimal traverse path of source directory tree.
 // Use plan built in 1st stage later in 2nd stage.
+// Once plan stage interrupted via Config.PlanStageStop, plan of modules
+// estimated so far is returned along with PlanStageInterruptedError:
+// backup might proceed with them, or estimation resumed with ResumePlan.
 func BuildBackupPlan(ctx context.Context, lg logger.PackageLog, config *Config,
 	modules []Module, notifier Notifier) (*Plan, *Progress, error) {
 
-	progress := &Progress{Context: ctx, Notifier: notifier}
-
-	progress.LogFiles = NewLogFiles()
-
-	// create main log file
-	log := core.NewProxyLog(lg, "backup", 6, "2006-01-02T15:04:05",
-		func(line string) error {
-			writer, err := progress.LogFiles.CreateOrGetLogFile(GetLogFileName())
-			if err != nil {
-				return err
-			}
-			// ignore error
-			_, _ = io.WriteString(writer, line)
-			return nil
-		}, logger.InfoLevel)
-	progress.Log = log
+	progress := newProgress(ctx, lg, config, notifier)
 
-	// create specific RSYNC log file (might be activated in
-	// backup session preference for debug purpose)
-	rsyncLog := config.getRsyncLoggingSettings()
-	if rsyncLog.EnableLog {
-		log = core.NewProxyLog(nil, "rsync", 5, "2006-01-02T15:04:05",
-			func(line string) error {
-				writer, err := progress.LogFiles.CreateOrGetLogFile(GetRsyncLogFileName())
-				if err != nil {
-					return err
-				}
-				// ignore error
-				_, _ = io.WriteString(writer, line)
-				return nil
-			}, logger.InfoLevel)
-		rsyncLog.Log = log
-		progress.RsyncLog = rsyncLog
+	modules, err := canonizeModules(modules)
+	if err != nil {
+		progress.Log.Error(err)
+		return nil, nil, err
 	}
 
 	progress.StartPlanStage()
@@ -82,79 +57,230 @@ func BuildBackupPlan(ctx context.Context, lg logger.PackageLog, config *Config,
 	progress.Log.Info(locale.T(MsgLogPlanStageStartTime,
 		struct{ Time string }{Time: progress.StartPlanTime.Format("2006 Jan 2 15:04:05")}))
 
-	list := []Node{}
-	var totalBackupSize core.FolderSize
 	progress.Log.Info(locale.TP(MsgLogPlanStartIterateViaNSources,
 		struct{ SourceCount int }{SourceCount: len(modules)},
 		len(modules)))
 
-	_, _, err := rsync.GetRsyncVersion()
+	caps, err := rsync.GetCapabilities()
 	if err != nil {
 		if rsync.IsExtractVersionAndProtocolError(err) {
 			progress.Log.Warn(err.Error())
 		} else {
 			return nil, nil, err
 		}
+	} else {
+		progress.Log.Info(locale.T(MsgLogPlanStageRsyncCapabilities,
+			struct{ Version, Protocol, Compressions string }{Version: caps.Version,
+				Protocol: caps.Protocol, Compressions: strings.Join(caps.Compressions, ", ")}))
 	}
 
-	for i, item := range modules {
+	err = config.CheckTransferOptions(caps)
+	if err != nil {
+		progress.Log.Error(err)
+		return nil, nil, err
+	}
+	// File names stay unconverted, if RSYNC built without iconv support.
+	if caps != nil && !caps.HasFeature("iconv") {
+		for _, module := range modules {
+			if module.SourceCharset != "" {
+				progress.Log.Warn(locale.T(MsgLogPlanStageIconvNotSupported,
+					struct{ RsyncSource, Charset string }{RsyncSource: module.SourceRsync,
+						Charset: module.SourceCharset}))
+			}
+		}
+	}
+
+	backup := &Plan{Config: config, Nodes: []Node{}, Pending: modules}
+	err = backup.estimatePending(progress)
+	if err != nil {
+		if IsPlanStageInterruptedError(err) {
+			return backup, progress, err
+		}
+		return nil, nil, err
+	}
+	//progress.Log.Debugf("Plan: %+v", backup)
+	return backup, progress, nil
+}
+
+// ResumePlan continue plan stage interrupted before, to estimate only
+// modules left pending. Config.PlanStageStop should be replaced with
+// new channel before call, to be able to interrupt plan stage again.
+func (v *Plan) ResumePlan(progress *Progress) error {
+	if v.IsComplete() {
+		return nil
+	}
+	progress.Log.Info(locale.TP(MsgLogPlanStageResuming,
+		struct{ SourceCount int }{SourceCount: len(v.Pending)},
+		len(v.Pending)))
+	return v.estimatePending(progress)
+}
+
+// estimatePending estimate modules left pending one by one, moving them
+// to plan nodes. Once plan stage interrupted, modules estimated so far
+// are kept and PlanStageInterruptedError returned.
+func (v *Plan) estimatePending(progress *Progress) error {
+	ctx, cancel := v.Config.planStageContext(progress.Context)
+	defer cancel()
+
+	for len(v.Pending) > 0 {
+		i := len(v.Nodes)
+		item := v.Pending[0]
 		progress.Log.Info(SingleSplitLogLine)
 		err := progress.EventPlanStage_NodeStructureStartInquiry(i, item.SourceRsync)
 		if err != nil {
 			progress.Log.Error(err)
-			return nil, nil, err
+			return err
 		}
 
-		dr, backupSize, err := estimateNode(ctx, item.AuthPassword, item, progress, config)
+		dr, backupSize, err := estimateNode(ctx, item.AuthPassword, item, progress, v.Config)
 		if err != nil {
+			// Interrupted plan stage is not a failure of whole session.
+			if ctx.Err() != nil && progress.Context.Err() == nil {
+				err = &PlanStageInterruptedError{Estimated: len(v.Nodes),
+					Total: len(v.Nodes) + len(v.Pending)}
+				progress.Log.Info(SingleSplitLogLine)
+				progress.Log.Warn(err.Error())
+				return err
+			}
 			progress.Log.Error(err)
-			return nil, nil, err
+			logErrorSuggestion(progress.Log, err)
+			return err
 		}
 		if backupSize != nil {
-			totalBackupSize += *backupSize
+			v.BackupSize += *backupSize
 		}
 
 		err = progress.EventPlanStage_NodeStructureDoneInquiry(i, item.SourceRsync, dr)
 		if err != nil {
 			progress.Log.Error(err)
-			return nil, nil, err
+			return err
 		}
 
-		node := Node{Module: item, RootDir: dr}
-		list = append(list, node)
+		v.Nodes = append(v.Nodes, Node{Module: item, RootDir: dr})
+		v.Pending = v.Pending[1:]
 	}
 	progress.Log.Info(SingleSplitLogLine)
 	progress.FinishPlanStage()
-	//	progress.Log.Debugf("Plan: %+v", list)
+	//	progress.Log.Debugf("Plan: %+v", v.Nodes)
 	progress.Log.Info(locale.T(MsgLogPlanStageEndTime,
 		struct{ Time string }{Time: progress.EndPlanTime.Format("2006 Jan 2 15:04:05")}))
-	backup := &Plan{Config: config, Nodes: list, BackupSize: totalBackupSize}
-	//progress.Log.Debugf("Plan: %+v", backup)
-	return backup, progress, nil
+	return nil
+}
+
+// PlanStageInterruptedError denote a situation, when plan stage
+// was interrupted on request before all modules were estimated.
+type PlanStageInterruptedError struct {
+	Estimated int
+	Total     int
+}
+
+func (v *PlanStageInterruptedError) Error() string {
+	return locale.T(MsgLogPlanStageInterruptedError,
+		struct{ Estimated, Total int }{Estimated: v.Estimated, Total: v.Total})
+}
+
+// IsPlanStageInterruptedError check that error able
+// to cast to PlanStageInterruptedError.
+func IsPlanStageInterruptedError(err error) bool {
+	if err != nil {
+		_, ok := err.(*PlanStageInterruptedError)
+		return ok
+	}
+	return false
+}
+
+// RSYNC_OUTPUT_TAIL_LINES is a maximum number of lines of RSYNC
+// itemized output kept for the most recent backup step.
+const RSYNC_OUTPUT_TAIL_LINES = 200
+
+// newProgress create Progress object with session logs attached.
+// Log files location is assigned later, when session folder is known.
+func newProgress(ctx context.Context, lg logger.PackageLog, config *Config,
+	notifier Notifier) *Progress {
+
+	progress := &Progress{Context: ctx, Notifier: notifier}
+	progress.rsyncOutput = rsync.NewOutputTail(RSYNC_OUTPUT_TAIL_LINES)
+
+	progress.LogFiles = NewLogFiles().SetMaxSize(config.logSegmentMaxSize())
+
+	// create main log file
+	level, details := config.getSessionLogLevel()
+	log := core.NewProxyLog(lg, "backup", 6, "2006-01-02T15:04:05",
+		progress.LogFiles.WriteLineFunc(GetLogFileName()), logger.InfoLevel).
+		SetFormat(config.getSessionLogFormat()).SetVerbosity(level, details)
+	progress.Log = log
+
+	// create specific RSYNC log file (might be activated in
+	// backup session preference for debug purpose)
+	rsyncLog := config.getRsyncLoggingSettings()
+	if rsyncLog.EnableLog {
+		log = core.NewProxyLog(nil, "rsync", 5, "2006-01-02T15:04:05",
+			progress.LogFiles.WriteLineFunc(GetRsyncLogFileName()), logger.InfoLevel)
+		rsyncLog.Log = log
+		progress.RsyncLog = rsyncLog
+	}
+
+	if config.networkWatchdogEnabled() {
+		progress.watchdog = rsync.NewNetworkWatchdog(config.networkOutageMaxWait(),
+			progress.EventBackupStage_NetworkStateChanged)
+	}
+	if timeout := config.rsyncHangTimeout(); timeout > 0 {
+		progress.hangWatchdog = rsync.NewProcessWatchdog(timeout,
+			progress.EventBackupStage_ProcessHung)
+	}
+	return progress
 }
 
 func estimateNode(ctx context.Context, password *string, module Module, progress *Progress,
 	config *Config) (*core.Dir, *core.FolderSize, error) {
 
-	tempDir, err := ioutil.TempDir("", "backup_dir_tree_")
-	if err != nil {
-		return nil, nil, err
+	// Fast mode: size is unknown, so progress and ETA
+	// don't count this module.
+	if module.SkipPlanEstimation {
+		progress.Log.Info(locale.T(MsgLogPlanStageSkipEstimation,
+			struct{ Path string }{Path: module.SourceRsync}))
+		paths := core.SrcDstPath{
+			RsyncSourcePath: core.RsyncPathJoin(module.SourceRsync, ""),
+		}
+		dir := core.NewRecursiveDir(paths, filepath.Base(module.DestSubPath))
+		if config.planTraceEnabled() {
+			savePlanTrace(progress, &module, dir, nil, nil, config.SigFileIgnoreBackup)
+		}
+		var backupSize core.FolderSize
+		return dir, &backupSize, nil
 	}
-	defer os.RemoveAll(tempDir)
 
-	progress.Log.Info(locale.T(MsgLogPlanStageUseTemporaryFolder,
-		struct{ Path string }{Path: tempDir}))
+	var tempDir string
+	if config.buildDirTreeInMemory() {
+		// Folders structure isn't copied, and dry runs
+		// don't require destination to exist.
+		tempDir = getPlanStageDryRunPath(config.planStageTempPath())
+	} else {
+		var err error
+		tempDir, err = createPlanStageTempDir(config.planStageTempPath())
+		if err != nil {
+			err = errors.New(f("%s: %v", locale.T(MsgLogPlanStageCreateTemporaryFolderError,
+				struct{ Path string }{Path: planStageTempLocation(config.planStageTempPath())}), err))
+			return nil, nil, err
+		}
+		defer os.RemoveAll(tempDir)
+
+		progress.Log.Info(locale.T(MsgLogPlanStageUseTemporaryFolder,
+			struct{ Path string }{Path: tempDir}))
+	}
 
 	paths := core.SrcDstPath{
 		RsyncSourcePath: core.RsyncPathJoin(module.SourceRsync, ""),
 		DestPath:        filepath.Join(tempDir, module.DestSubPath),
 	}
 
-	err = createDirAll(paths.DestPath)
-	if err != nil {
-		err = errors.New(f("%s: %v", locale.T(MsgLogPlanStageUseTemporaryFolder,
-			struct{ Path string }{Path: tempDir}), err))
-		return nil, nil, err
+	if !config.buildDirTreeInMemory() {
+		err := createDirAll(paths.DestPath)
+		if err != nil {
+			err = errors.New(f("%s: %v", locale.T(MsgLogPlanStageUseTemporaryFolder,
+				struct{ Path string }{Path: tempDir}), err))
+			return nil, nil, err
+		}
 	}
 
 	// Get RSYNC protocol version to choose console text output parsing approach
@@ -163,31 +289,108 @@ func estimateNode(ctx context.Context, password *string, module Module, progress
 		return nil, nil, err
 	}
 
-	// RSYNC settings to copy only folder's structure and some specific files
-	options := rsync.NewOptions(rsync.WithDefaultParams([]string{"--recursive"})).
-		AddParams(f("--include=%s", "*"+"/")).
-		AddParams(f("--include=%s", config.SigFileIgnoreBackup)).
-		AddParams(f("--exclude=%s", "*")).
-		SetRetryCount(config.RsyncRetryCount).
-		SetAuthPassword(password)
-	sessionErr, _, _ := rsync.RunRsyncWithRetry(ctx, options, progress.RsyncLog, nil, paths)
-	if sessionErr != nil {
-		return nil, nil, sessionErr
-	}
-	dir, err := core.BuildDirTree(paths, config.SigFileIgnoreBackup)
-	if err != nil {
-		return nil, nil, err
+	// Convert file names of sources in legacy charsets, the same way as in backup stage.
+	charsetParams := rsync.AdjustParamsToCapabilities(module.getCharsetParams())
+
+	var dir *core.Dir
+	if config.buildDirTreeInMemory() {
+		// Parse RSYNC listing of folder's structure directly,
+		// no temporary folder is created in this mode.
+		entries, err := rsync.ListDirTree(ctx, password, paths.RsyncSourcePath,
+			config.SigFileIgnoreBackup, config.RsyncRetryCount, progress.RsyncLog, charsetParams)
+		if err != nil {
+			return nil, nil, err
+		}
+		dir = core.BuildDirTreeFromList(paths, filepath.Base(paths.DestPath),
+			entries, config.SigFileIgnoreBackup)
+	} else {
+		// RSYNC settings to copy only folder's structure and some specific files
+		// Keep folder modification times to find folders changed since previous session.
+		options := rsync.NewOptions(rsync.WithDefaultParams([]string{"--recursive", "--times"})).
+			AddParams(f("--include=%s", "*"+"/")).
+			AddParams(f("--include=%s", config.SigFileIgnoreBackup)).
+			AddParams(f("--exclude=%s", "*")).
+			AddParams(charsetParams...).
+			SetRetryCount(config.RsyncRetryCount).
+			SetAuthPassword(password).
+			SetTimeouts(config.getRsyncTimeouts(&module)).
+			SetPriority(config.getRsyncPriority(&module))
+		sessionErr, _, _ := rsync.RunRsyncWithRetry(ctx, options, progress.RsyncLog, nil, paths)
+		if sessionErr != nil {
+			return nil, nil, sessionErr
+		}
+		dir, err = core.BuildDirTree(paths, config.SigFileIgnoreBackup)
+		if err != nil {
+			return nil, nil, err
+		}
 	}
 
 	progress.Log.Debug("---------------------------------")
 	progress.Log.Debug("Start heuristic search")
 	progress.Log.Debug("---------------------------------")
 
-	blockSize := config.getBackupBlockSizeSettings()
-	count, err := MeasureDir(ctx, password, dir, config.RsyncRetryCount, protocol, progress.RsyncLog, blockSize)
+	blockSize := config.getBackupBlockSizeSettings(&module)
+	if blockSize.AutoManageBackupBlockSize && blockSize.Statistics != nil {
+		progress.Log.Info(locale.T(MsgLogPlanStageUseStatistics,
+			struct{ Path, Throughput, CallOverhead string }{Path: module.SourceRsync,
+				Throughput:   core.FormatSize(blockSize.Statistics.Throughput, true),
+				CallOverhead: blockSize.Statistics.CallOverhead.Round(time.Millisecond).String()}))
+	}
+	filter := module.GetFileFilter()
+	if filter.HasAgeLimits() && !rsync.IsLocalSource(paths.RsyncSourcePath) {
+		progress.Log.Warn(locale.T(MsgLogPlanStageAgeLimitsNotApplicable,
+			struct{ Path string }{Path: module.SourceRsync}))
+	}
+	// Reuse metrics of folders not changed since previous session. Age limits
+	// make folder sizes depend on current time, so metrics can't be reused.
+	incremental := config.incrementalPlanEnabled() && !filter.HasAgeLimits()
+	var cached map[*core.Dir]bool
+	var fileHashes map[string]uint64
+	if incremental {
+		// Folder modification time doesn't change, when file is modified
+		// in place, so validate cached metrics against single listing of files.
+		entries, err := rsync.ListFiles(ctx, password, filter, paths.RsyncSourcePath,
+			config.RsyncRetryCount, progress.RsyncLog, charsetParams)
+		if err != nil {
+			if rsync.IsProcessTerminatedError(err) {
+				return nil, nil, err
+			}
+			progress.Log.Warn(locale.T(MsgLogPlanStagePlanCacheError,
+				struct{ Error error }{Error: err}))
+			incremental = false
+		} else {
+			fileHashes = getPlanCacheFileHashes(entries)
+		}
+	}
+	if incremental {
+		cache, err := loadPlanCache(module.SourceRsync, filter)
+		if err != nil {
+			progress.Log.Warn(locale.T(MsgLogPlanStagePlanCacheError,
+				struct{ Error error }{Error: err}))
+		} else if cache != nil {
+			progress.Log.Info(locale.T(MsgLogPlanStageUsePlanCache,
+				struct {
+					Path        string
+					FolderCount int
+				}{Path: module.SourceRsync, FolderCount: cache.apply(dir, fileHashes)}))
+			cached = collectMeasuredDirs(dir, nil)
+		}
+	}
+	count, err := MeasureDir(ctx, password, filter, dir, config.RsyncRetryCount, protocol,
+		progress.RsyncLog, blockSize)
 	if err != nil {
 		return nil, nil, err
 	}
+	if incremental {
+		err = savePlanCache(module.SourceRsync, filter, dir, fileHashes)
+		if err != nil {
+			progress.Log.Warn(locale.T(MsgLogPlanStagePlanCacheError,
+				struct{ Error error }{Error: err}))
+		}
+	}
+	if config.planTraceEnabled() {
+		savePlanTrace(progress, &module, dir, blockSize, cached, config.SigFileIgnoreBackup)
+	}
 	progress.Log.Debugf("Total \"full size\" cycle factor %v, full backup %v, content backup %v", count,
 		core.GetReadableSize(dir.GetFullBackupSize()),
 		core.GetReadableSize(dir.GetContentBackupSize()))
@@ -204,16 +407,35 @@ func estimateNode(ctx context.Context, password *string, module Module, progress
 func (plan *Plan) RunBackup(progress *Progress, destPath string,
 	errorHookCall rsync.ErrorHookCall) error {
 
-	// Execute backup stage
-	err := runBackup(plan, progress, destPath, errorHookCall)
+	// Prevent concurrent sessions writing to the same destination.
+	err := createDirInBackupStage(destPath)
+	if err == nil {
+		var lock *DestLock
+		lock, err = AcquireDestLock(progress.Log, destPath, plan.Config.DestLockHook)
+		if err == nil {
+			// Execute backup stage
+			err = runBackup(plan, progress, destPath, errorHookCall)
+			err2 := lock.Release()
+			if err2 != nil {
+				progress.Log.Warn(err2)
+			}
+		}
+	}
 	if err != nil {
 		progress.Log.Error(locale.T(MsgLogBackupStageCriticalError,
 			struct{ Error error }{Error: err}))
+		logErrorSuggestion(progress.Log, err)
 	}
 
 	// Next lines should be executed even if backup failed and err variable is not empty,
 	// to store log files in backup destination folder.
 
+	if plan.Config.planTraceEnabled() {
+		planTraceFileName := path.Join(progress.GetBackupFullPath(progress.BackupFolder), GetPlanTraceFileName())
+		progress.Log.Info(locale.T(MsgLogBackupStageSavePlanTraceTo,
+			struct{ Path string }{Path: planTraceFileName}))
+	}
+
 	if progress.RsyncLog != nil {
 		rsyncLogFileName := path.Join(progress.GetBackupFullPath(progress.BackupFolder), GetRsyncLogFileName())
 		progress.Log.Info(locale.T(MsgLogBackupStageSaveRsyncExtraLogTo,
@@ -229,6 +451,22 @@ func (plan *Plan) RunBackup(progress *Progress, destPath string,
 	return err
 }
 
+// logPrevBackupsMatch report previous backup sessions used for deduplication,
+// which were made before RSYNC source URL changed.
+func logPrevBackupsMatch(prevBackups *PreviousBackups, lg logger.PackageLog) {
+	for _, item := range prevBackups.Backups {
+		session := filepath.Base(filepath.Dir(item.SignatureFileName))
+		switch item.Match {
+		case SIG_MATCH_ALIAS:
+			lg.Notify(locale.T(MsgLogBackupStagePreviousBackupMatchedByAlias,
+				struct{ Path string }{Path: session}))
+		case SIG_MATCH_FUZZY:
+			lg.Notify(locale.T(MsgLogBackupStagePreviousBackupMatchedBySourceHostPath,
+				struct{ Path string }{Path: session}))
+		}
+	}
+}
+
 // Perform whole 2nd stage (backup stage) here.
 func runBackup(plan *Plan, progress *Progress, destPath string, errorHookCall rsync.ErrorHookCall) error {
 
@@ -261,10 +499,24 @@ func runBackup(plan *Plan, progress *Progress, destPath string, errorHookCall rs
 	progress.Log.Info(locale.T(MsgLogBackupStageBackupToDestination,
 		struct{ Path string }{Path: destPath2}))
 
+	// modules with own destination root get session folder of the same name there
+	roots, groups := GroupModulesByDestRoot(destPath, plan.GetModules())
+	for _, root := range roots[1:] {
+		path := filepath.Join(root, backupFolder)
+		err = createDirInBackupStage(path)
+		if err != nil {
+			return err
+		}
+		progress.Log.Info(locale.T(MsgLogBackupStageBackupToDestination,
+			struct{ Path string }{Path: path}))
+	}
+
+	disableUnsupportedAttributes(plan, progress, destPath)
+
 	// search for previous backup sessions: this might activate deduplication capabilities
 	progress.Log.Info(locale.T(MsgLogBackupStageDiscoveringPreviousBackups, nil))
-	prevBackups, err := FindPrevBackupPathsByNodeSignatures(progress.Log, destPath,
-		GetNodeSignatures(plan.GetModules()), plan.Config.numberOfPreviousBackupToUse())
+	prevBackups, err := FindPrevBackupPathsInDestRoots(progress.Log, destPath,
+		selectModulesToDeduplicate(plan, progress, destPath), plan.Config.numberOfPreviousBackupToUse())
 	if err != nil {
 		return err
 	}
@@ -294,8 +546,26 @@ func runBackup(plan *Plan, progress *Progress, destPath string, errorHookCall rs
 		progress.Log.Notify(locale.T(MsgLogBackupStagePreviousBackupNotFound, nil))
 	}
 
+	// sessions used for deduplication should survive quota pruning
+	keepSessions := getLinkDestSessions(plan, prevBackups)
+
+	// verify destination usage stay within quota before transferring data
+	quota, err := newQuotaGuard(plan, progress, roots)
+	if err != nil {
+		return err
+	}
+	if quota != nil {
+		err = quota.enforce(progress, keepSessions)
+		if err != nil {
+			return err
+		}
+	}
+
 	// loop through all RSYNC source to backup
 	for i, node := range plan.Nodes {
+		if plan.Config.gracefulStopRequested() {
+			return &GracefulStopError{}
+		}
 		progress.Log.Info(SingleSplitLogLine)
 		progress.Log.Info(locale.T(MsgLogBackupStageStartToBackupFromSource,
 			struct {
@@ -306,12 +576,41 @@ func runBackup(plan *Plan, progress *Progress, destPath string, errorHookCall rs
 		// select previous backup sessions to use for deduplication
 		sourceID := GenerateSourceID(node.Module.SourceRsync)
 		prevBackups2 := prevBackups.FilterBySourceID(sourceID)
-		// run specific RSYNC source to backup
-		err := runBackupNode(plan, node, progress, destPath2,
-			errorHookCall, prevBackups2)
+		logPrevBackupsMatch(prevBackups2, progress.Log)
+		err := progress.EventBackupStage_NodeStartBackup(i, node)
 		if err != nil {
 			return err
 		}
+		progress.Progress = &core.SizeProgress{}
+		// protect backup from source accidentally wiped since previous backup
+		err = checkSafeDelete(plan, node, progress, prevBackups2)
+		if err == nil {
+			// run specific RSYNC source to backup
+			err = runBackupNode(plan, node, progress,
+				progress.GetModuleBackupFullPath(&node.Module, progress.BackupFolder),
+				errorHookCall, prevBackups2)
+		}
+		err2 := progress.EventBackupStage_NodeDoneBackup(i, node, err)
+		if err != nil {
+			if !skipFailedNode(plan, progress, node, err) {
+				return err
+			}
+		}
+		if err2 != nil {
+			return err2
+		}
+		if quota != nil {
+			err = quota.nodeDone(progress.BackupFolder, filepath.Join(
+				progress.GetModuleBackupFullPath(&node.Module, progress.BackupFolder),
+				node.Module.DestSubPath))
+			if err != nil {
+				return err
+			}
+			err = quota.enforce(progress, keepSessions)
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	// debug
@@ -321,6 +620,12 @@ func runBackup(plan *Plan, progress *Progress, destPath string, errorHookCall rs
 	// rename backup session folder, when backup process is completed
 	progress.Log.Info(SingleSplitLogLine)
 	newBackupFolder := GetBackupFolderName(false, &progress.StartBackupTime)
+	for _, root := range roots[1:] {
+		err = os.Rename(filepath.Join(root, progress.BackupFolder), filepath.Join(root, newBackupFolder))
+		if err != nil {
+			return err
+		}
+	}
 	destPath3 := progress.GetBackupFullPath(newBackupFolder)
 	err = os.Rename(destPath2, destPath3)
 	if err != nil {
@@ -335,7 +640,72 @@ func runBackup(plan *Plan, progress *Progress, destPath string, errorHookCall rs
 
 	// create signature auxiliary file: used to search for previous backup sessions
 	// in order to activate deduplication capabilities
-	err = CreateMetadataSignatureFile(plan.GetModules(), destPath3)
+	// in each destination root, with modules stored there
+	for _, root := range roots {
+		err = CreateMetadataSignatureFile(plan.Config.Profile, groups[root],
+			progress.GetModuleStatistics(), filepath.Join(root, newBackupFolder))
+		if err != nil {
+			return err
+		}
+		progress.SessionPaths = append(progress.SessionPaths, filepath.Join(root, newBackupFolder))
+	}
+
+	// remove partially transferred files kept to resume interrupted transfers
+	if plan.Config.partialTransferEnabled() {
+		var sessionPaths []string
+		for _, root := range roots {
+			sessionPaths = append(sessionPaths, filepath.Join(root, newBackupFolder))
+		}
+		cleanPartialDirs(progress, sessionPaths)
+	}
+
+	// list files backed up, to find later which session contains specific file
+	if plan.Config.generateCatalog() {
+		for _, root := range roots {
+			path := filepath.Join(root, newBackupFolder)
+			count, err := CreateCatalogFile(path)
+			if err != nil {
+				progress.Log.Warn(locale.T(MsgLogBackupStageCatalogError,
+					struct {
+						Path  string
+						Error error
+					}{Path: path, Error: err}))
+				continue
+			}
+			progress.Log.Info(locale.TP(MsgLogBackupStageCatalogCreated,
+				struct {
+					Path      string
+					FileCount int
+				}{Path: path, FileCount: count}, count))
+		}
+	}
+
+	// save checksums, to verify later backup integrity
+	if plan.Config.generateChecksums() {
+		for _, root := range roots {
+			path := filepath.Join(root, newBackupFolder)
+			count, err := CreateChecksumManifest(progress.Context, path)
+			if err != nil {
+				if progress.Context.Err() != nil {
+					return err
+				}
+				progress.Log.Warn(locale.T(MsgLogBackupStageChecksumsError,
+					struct {
+						Path  string
+						Error error
+					}{Path: path, Error: err}))
+				continue
+			}
+			progress.Log.Info(locale.TP(MsgLogBackupStageChecksumsCreated,
+				struct {
+					Path      string
+					FileCount int
+				}{Path: path, FileCount: count}, count))
+		}
+	}
+
+	// save folders failed to backup, to retry them later in the same session folder
+	err = SaveRetryList(destPath3, &RetryList{Folders: progress.FailedFolders})
 	if err != nil {
 		return err
 	}
@@ -353,6 +723,168 @@ func runBackup(plan *Plan, progress *Progress, destPath string, errorHookCall rs
 	return nil
 }
 
+// selectModulesToDeduplicate return modules, which previous backups could be
+// linked with (RSYNC --link-dest option). Destination roots are probed for
+// hard links support: modules located on file systems without such
+// capability (FAT, exFAT and so on) are excluded, so deduplication
+// is disabled for them in current session.
+func selectModulesToDeduplicate(plan *Plan, progress *Progress, destPath string) []Module {
+	modules := plan.GetModules()
+	if !plan.Config.usePreviousBackupEnabled() {
+		return modules
+	}
+	roots, groups := GroupModulesByDestRoot(destPath, modules)
+	var list []Module
+	for _, root := range roots {
+		if len(groups[root]) == 0 {
+			continue
+		}
+		// probe inside of session folder, to not leave garbage in root
+		supported, err := IsHardLinkSupported(filepath.Join(root, progress.BackupFolder))
+		if err != nil {
+			progress.Log.Warn(locale.T(MsgLogBackupStageHardLinksProbeError,
+				struct {
+					Path  string
+					Error error
+				}{Path: root, Error: err}))
+		} else if !supported {
+			fsType, _ := GetFileSystemType(root)
+			progress.Log.Warn(locale.T(MsgLogBackupStageHardLinksNotSupported,
+				struct{ Path, FileSystem string }{Path: root, FileSystem: fsType}))
+			progress.HardLinksUnsupported = append(progress.HardLinksUnsupported, root)
+			continue
+		}
+		list = append(list, groups[root]...)
+	}
+	return list
+}
+
+// disableUnsupportedAttributes verify that installed RSYNC and destination
+// file systems are capable to keep ACLs and extended attributes, and disable
+// corresponding RSYNC options for modules, where it's not the case.
+// Otherwise RSYNC would fail to backup each file of such modules.
+func disableUnsupportedAttributes(plan *Plan, progress *Progress, destPath string) {
+	rsyncACLs, rsyncXattrs := true, true
+	caps, err := rsync.GetCapabilities()
+	if err == nil {
+		rsyncACLs, rsyncXattrs = caps.SupportsACLs(), caps.SupportsXattrs()
+	}
+	rsyncReported := make(map[string]bool)
+	aclSupport := make(map[string]bool)
+	xattrSupport := make(map[string]bool)
+	disabled := false
+	for i := range plan.Nodes {
+		module := &plan.Nodes[i].Module
+		root := module.GetDestRoot(destPath)
+		// probe inside of session folder, to not leave garbage in root
+		path := filepath.Join(root, progress.BackupFolder)
+		if plan.Config.transferACLs(module) {
+			if !rsyncACLs {
+				reportRsyncLacksOption(progress, rsyncReported, "--acls", caps)
+				module.RsyncTransferACLs = &disabled
+			} else if !probeAttributeSupport(progress, aclSupport, root, path,
+				IsACLSupported, MsgLogBackupStageACLsNotSupported) {
+				module.RsyncTransferACLs = &disabled
+			}
+		}
+		if plan.Config.transferXattrs(module) {
+			if !rsyncXattrs {
+				reportRsyncLacksOption(progress, rsyncReported, "--xattrs", caps)
+				module.RsyncTransferXattrs = &disabled
+			} else if !probeAttributeSupport(progress, xattrSupport, root, path,
+				IsXattrSupported, MsgLogBackupStageXattrsNotSupported) {
+				module.RsyncTransferXattrs = &disabled
+			}
+		}
+	}
+}
+
+// reportRsyncLacksOption report once, that installed RSYNC doesn't support option.
+func reportRsyncLacksOption(progress *Progress, reported map[string]bool,
+	option string, caps *rsync.Capabilities) {
+
+	if !reported[option] {
+		progress.Log.Warn(locale.T(MsgLogBackupStageRsyncLacksOption,
+			struct{ Option, Version string }{Option: option, Version: caps.Version}))
+		reported[option] = true
+	}
+}
+
+// probeAttributeSupport verify once for each destination root, that file system
+// is capable to keep file attributes, and report to the log, if not.
+func probeAttributeSupport(progress *Progress, cache map[string]bool, root, path string,
+	probe func(path string) (bool, error), msgNotSupported string) bool {
+
+	if supported, ok := cache[root]; ok {
+		return supported
+	}
+	supported, err := probe(path)
+	if err != nil {
+		progress.Log.Warn(locale.T(MsgLogBackupStageAttributesProbeError,
+			struct {
+				Path  string
+				Error error
+			}{Path: root, Error: err}))
+		// let RSYNC report the issue, if any
+		supported = true
+	} else if !supported {
+		fsType, _ := GetFileSystemType(root)
+		progress.Log.Warn(locale.T(msgNotSupported,
+			struct{ Path, FileSystem string }{Path: root, FileSystem: fsType}))
+	}
+	cache[root] = supported
+	return supported
+}
+
+// GracefulStopError denote a situation, when backup session
+// was stopped on request before next folder block.
+type GracefulStopError struct {
+}
+
+func (v *GracefulStopError) Error() string {
+	return locale.T(MsgLogBackupStageGracefulStopError, nil)
+}
+
+// IsGracefulStopError check that error able to cast
+// to GracefulStopError.
+func IsGracefulStopError(err error) bool {
+	if err != nil {
+		_, ok := err.(*GracefulStopError)
+		return ok
+	}
+	return false
+}
+
+// skipFailedNode decide according to module error policy, whether backup
+// session should continue, when RSYNC source failed with critical error.
+// Return true, if source is skipped and accounted in session statistics.
+func skipFailedNode(plan *Plan, progress *Progress, node Node, err error) bool {
+	// never continue session terminated by user
+	if progress.Context.Err() != nil || IsGracefulStopError(err) {
+		return false
+	}
+	var skip bool
+	switch plan.Config.getModuleErrorPolicy() {
+	case MODULE_ERROR_POLICY_SKIP:
+		skip = true
+	case MODULE_ERROR_POLICY_ASK:
+		if plan.Config.ModuleErrorHook != nil {
+			skip = plan.Config.ModuleErrorHook(node.Module.SourceRsync, err)
+		}
+	}
+	if !skip {
+		return false
+	}
+	progress.Log.Warn(locale.T(MsgLogBackupStageModuleSkipped,
+		struct {
+			RsyncSource string
+			Error       error
+		}{RsyncSource: node.Module.SourceRsync, Error: err}))
+	progress.ModuleSkipped(node, err)
+	progress.LastSessionError = err
+	return true
+}
+
 // Perform backup of one source defined in backup session preferences.
 func runBackupNode(plan *Plan, node Node, progress *Progress, destRootPath string,
 	errorHookCall rsync.ErrorHookCall, prevBackups *PreviousBackups) error {
@@ -362,7 +894,6 @@ func runBackupNode(plan *Plan, node Node, progress *Progress, destRootPath strin
 		DestPath:        filepath.Join(destRootPath, node.Module.DestSubPath),
 	}
 
-	progress.Progress = &core.SizeProgress{}
 	err := backupDir(node.RootDir, &node.Module,
 		plan, progress, paths, errorHookCall, prevBackups.GetDirPaths())
 	return err
@@ -397,6 +928,15 @@ func formatError(sessionErr error, skipped bool, rootDest string,
 	return str, nil
 }
 
+// logErrorSuggestion log action suggested to fix
+// failed RSYNC call, if any.
+func logErrorSuggestion(lg logger.PackageLog, err error) {
+	if suggestion := rsync.GetErrorSuggestion(err); suggestion != "" {
+		lg.Info(locale.T(rsync.MsgRsyncErrorSuggestion,
+			struct{ Suggestion string }{Suggestion: suggestion}))
+	}
+}
+
 // Report backup progress on each backup step made.
 // Report here not only successfully performed steps, but anything
 // including steps ended with errors.
@@ -404,8 +944,11 @@ func reportProgress(sessionErr, retryErr error, size core.FolderSize,
 	plan *Plan, progress *Progress, paths core.SrcDstPath,
 	backupType core.FolderBackupType, skipped bool) error {
 
+	log := core.LogWithFields(progress.Log,
+		core.LogFields{Folder: paths.RsyncSourcePath, Bytes: &size, Detail: true})
+
 	if retryErr != nil {
-		progress.Log.Info(locale.T(MsgLogBackupStageRecoveredFromError,
+		log.Info(locale.T(MsgLogBackupStageRecoveredFromError,
 			struct{ Error error }{Error: retryErr}))
 	}
 
@@ -415,7 +958,9 @@ func reportProgress(sessionErr, retryErr error, size core.FolderSize,
 		if err != nil {
 			return err
 		}
-		progress.Log.Warn(str)
+		log.Warn(str)
+		logErrorSuggestion(log, sessionErr)
+		progress.LastSessionError = sessionErr
 		err = progress.EventBackupStage_FolderDoneBackup(paths, backupType, plan,
 			core.NewProgressFailed(size), sessionErr)
 		if err != nil {
@@ -447,6 +992,10 @@ func backupDir(dir *core.Dir, module *Module, plan *Plan, progress *Progress,
 	var backupType core.FolderBackupType
 	defParams := []string{"--times"}
 
+	// stop before next folder block, once requested
+	if plan.Config.gracefulStopRequested() {
+		return &GracefulStopError{}
+	}
 	err = createDirInBackupStage(paths.DestPath)
 	if err != nil {
 		return err
@@ -466,6 +1015,10 @@ func backupDir(dir *core.Dir, module *Module, plan *Plan, progress *Progress,
 			AddParams(f("--include=%s", plan.Config.SigFileIgnoreBackup), "--exclude=*").
 			SetRetryCount(plan.Config.RsyncRetryCount).
 			SetAuthPassword(module.AuthPassword).
+			SetNetworkWatchdog(progress.watchdog).
+			SetHangWatchdog(progress.hangWatchdog).
+			SetTimeouts(plan.Config.getRsyncTimeouts(module)).
+			SetPriority(plan.Config.getRsyncPriority(module)).
 			// minimum size for empty signature file
 			SetErrorHook(rsync.NewErrorHook(errorHookCall, core.NewFolderSize(1*core.KB)))
 
@@ -493,6 +1046,12 @@ func backupDir(dir *core.Dir, module *Module, plan *Plan, progress *Progress,
 			// AddParams("--fake-super").
 			SetRetryCount(plan.Config.RsyncRetryCount).
 			SetAuthPassword(module.AuthPassword).
+			SetNetworkWatchdog(progress.watchdog).
+			SetHangWatchdog(progress.hangWatchdog).
+			SetTimeouts(plan.Config.getRsyncTimeouts(module)).
+			SetPriority(plan.Config.getRsyncPriority(module)).
+			SetFileFilter(module.GetFileFilter()).
+			SetOutputTail(progress.rsyncOutput).
 			SetErrorHook(rsync.NewErrorHook(errorHookCall, *dir.Metrics.FullSize))
 
 		if plan.Config.usePreviousBackupEnabled() {
@@ -502,16 +1061,28 @@ func backupDir(dir *core.Dir, module *Module, plan *Plan, progress *Progress,
 			}
 		}
 
+		startTime := time.Now()
 		sessionErr, retryErr, criticalErr := rsync.RunRsyncWithRetry(progress.Context,
 			options, progress.RsyncLog, nil, paths)
 		if criticalErr != nil {
 			return criticalErr
 		}
+		// Calls recovered from errors are not representative,
+		// as well as calls with unknown size in fast mode.
+		if sessionErr == nil && retryErr == nil && !module.SkipPlanEstimation {
+			progress.RsyncCallDone(module, paths, *dir.Metrics.FullSize, true, time.Since(startTime))
+		}
 
 		err = reportProgress(sessionErr, retryErr, *dir.Metrics.FullSize, plan, progress, paths, backupType, false)
 		if err != nil {
 			return err
 		}
+		if sessionErr != nil {
+			err = progress.FolderFailed(module, paths, backupType, *dir.Metrics.FullSize, sessionErr)
+			if err != nil {
+				return err
+			}
+		}
 	} else if dir.Metrics.BackupType == core.FBT_CONTENT {
 		// process only current folder, then go deep to process sub-folders recursively
 		backupType = core.FBT_CONTENT
@@ -526,6 +1097,12 @@ func backupDir(dir *core.Dir, module *Module, plan *Plan, progress *Progress,
 			// AddParams("--fake-super").
 			SetRetryCount(plan.Config.RsyncRetryCount).
 			SetAuthPassword(module.AuthPassword).
+			SetNetworkWatchdog(progress.watchdog).
+			SetHangWatchdog(progress.hangWatchdog).
+			SetTimeouts(plan.Config.getRsyncTimeouts(module)).
+			SetPriority(plan.Config.getRsyncPriority(module)).
+			SetFileFilter(module.GetFileFilter()).
+			SetOutputTail(progress.rsyncOutput).
 			SetErrorHook(rsync.NewErrorHook(errorHookCall, *dir.Metrics.Size))
 
 		if plan.Config.usePreviousBackupEnabled() {
@@ -535,16 +1112,28 @@ func backupDir(dir *core.Dir, module *Module, plan *Plan, progress *Progress,
 			}
 		}
 
+		startTime := time.Now()
 		sessionErr, retryErr, criticalErr := rsync.RunRsyncWithRetry(progress.Context,
 			options, progress.RsyncLog, nil, paths)
 		if criticalErr != nil {
 			return criticalErr
 		}
+		// Calls recovered from errors are not representative,
+		// as well as calls with unknown size in fast mode.
+		if sessionErr == nil && retryErr == nil && !module.SkipPlanEstimation {
+			progress.RsyncCallDone(module, paths, *dir.Metrics.Size, false, time.Since(startTime))
+		}
 
 		err = reportProgress(sessionErr, retryErr, *dir.Metrics.Size, plan, progress, paths, backupType, false)
 		if err != nil {
 			return err
 		}
+		if sessionErr != nil {
+			err = progress.FolderFailed(module, paths, backupType, *dir.Metrics.Size, sessionErr)
+			if err != nil {
+				return err
+			}
+		}
 
 		// process sub-folders recursively
 		for _, item := range dir.Childs {
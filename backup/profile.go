@@ -0,0 +1,70 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package backup
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// ProfileIdentity identify backup profile. ID is stable and never change,
+// while Name is shown to user and might be renamed any time. Anything
+// kept between sessions (signatures, snapshots, metrics, logs)
+// should refer to profile by ID, name used for display only.
+type ProfileIdentity struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// IsEmpty verify that profile is not specified.
+func (v ProfileIdentity) IsEmpty() bool {
+	return v.ID == "" && v.Name == ""
+}
+
+// String return profile name, or ID if name is empty.
+func (v ProfileIdentity) String() string {
+	if v.Name != "" {
+		return v.Name
+	}
+	return v.ID
+}
+
+// isSessionOwnedBy verify that backup session was created with profile
+// specified. Sessions made by previous versions don't keep profile (or
+// keep index of profile settings instead of UUID), so they belong to no
+// profile and never pruned automatically.
+func isSessionOwnedBy(sessionPath string, profile ProfileIdentity) bool {
+	signs, err := readSessionSignatures(sessionPath)
+	if err != nil {
+		LocalLog.Warnf("Can't read signatures of %q: %v", sessionPath, err)
+		return false
+	}
+	return signs.Profile.ID == profile.ID
+}
+
+// NewProfileID generate random (version 4) UUID, to identify profile
+// uniquely among profiles of all hosts sharing backup destination.
+func NewProfileID() (string, error) {
+	var b [16]byte
+	_, err := rand.Read(b[:])
+	if err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+// Match verify that profile is referenced either by ID or by name.
+func (v ProfileIdentity) Match(ref string) bool {
+	return ref != "" && (ref == v.ID || ref == v.Name)
+}
@@ -9,25 +9,27 @@
 // OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
 //--------------------------------------------------------------------------------------------------
 
-package main
+package backup
 
 import (
-	"log"
-
-	"github.com/d2r2/go-rsync/data"
-	"github.com/shurcooL/vfsgen"
+	"regexp"
+	"testing"
 )
 
-// This application is used to generate "assets_vfsdata.go" file for application Release compilation.
-// In Release mode all data files found in "assets" folder is encapsulated to "assets_vfsdata.go".
-
-func main() {
-	err := vfsgen.Generate(data.Assets, vfsgen.Options{
-		PackageName:  "data",
-		BuildTags:    "gorsync_rel",
-		VariableName: "Assets",
-	})
-	if err != nil {
-		log.Fatalln(err)
+func TestNewProfileID(t *testing.T) {
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	ids := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		id, err := NewProfileID()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !uuid.MatchString(id) {
+			t.Fatalf("NewProfileID() = %q, want version 4 UUID", id)
+		}
+		if ids[id] {
+			t.Fatalf("NewProfileID() returned %q twice", id)
+		}
+		ids[id] = true
 	}
 }
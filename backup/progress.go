@@ -48,12 +48,39 @@ type Progress struct {
 
 	// Previous backup sessions found to use for deduplicaton
 	PreviousBackups *PreviousBackups
+	// Destination roots, where deduplication was disabled,
+	// since file system doesn't support hard links
+	HardLinksUnsupported []string
 
 	RootDest     string
 	BackupFolder string
+	// Completed session folders in each destination root
+	SessionPaths []string
 
 	// Notify only once (theoretically it never happens)
 	SizeChangedNotified bool
+
+	// RSYNC calls statistics indexed by source identifier
+	rsyncCallStats map[string]*rsyncCallStatistics
+
+	// Smoothed throughput used to compute ETA
+	eta etaEstimator
+
+	// Folders failed to backup, which might be retried later
+	FailedFolders []FailedFolder
+	// Modules of failed folders indexed by RSYNC source
+	failedModules map[string]Module
+	// The most recent error of folder failed to backup
+	LastSessionError error
+	// RSYNC sources skipped due to critical error
+	SkippedModules []string
+
+	// Pause RSYNC calls in case of network outage
+	watchdog *rsync.NetworkWatchdog
+	// Itemized output of the most recent RSYNC call
+	rsyncOutput *rsync.OutputTail
+	// Kill and repeat hung RSYNC calls
+	hangWatchdog *rsync.ProcessWatchdog
 }
 
 // StartPlanStage save the start time of 1st stage.
@@ -69,6 +96,7 @@ func (v *Progress) FinishPlanStage() {
 // StartBackupStage save the start time of 2nd stage.
 func (v *Progress) StartBackupStage() {
 	v.StartBackupTime = time.Now()
+	v.eta.addSample(v.StartBackupTime, 0)
 }
 
 // FinishBackupStage save the end time of 2nd stage.
@@ -94,26 +122,22 @@ func (v *Progress) GetTotalTimeTaken() time.Duration {
 }
 
 // CalcTimePassedAndETA count total time passed in backup stage (2nd stage)
-// and compute ETA (estimated time of arrival) - time left.
-func (v *Progress) CalcTimePassedAndETA(plan *Plan) (time.Duration, *time.Duration) {
-	// timePassed := time.Now().Sub(v.StartBackupTime)
+// and compute ETA (estimated time of arrival) - time left, based on
+// size predicted in plan stage and smoothed throughput observed.
+func (v *Progress) CalcTimePassedAndETA(plan *Plan) (time.Duration, *ETA) {
 	timePassed := time.Since(v.StartBackupTime)
-	if v.SizeBackedUp() > 0 {
-		totalTime := float32(timePassed) * float32(plan.BackupSize) /
-			float32(v.SizeBackedUp())
-		eta := time.Duration(totalTime) - timePassed
-		// lg.Debugf("Left to backup: %v", v.LeftToBackup())
-		// lg.Debugf("Total time: %v", totalTime)
-		// lg.Debugf("Time pass: %v", timePassed)
-		// lg.Debugf("ETA: %v", time.Duration(totalTime)-timePassed)
-		return timePassed, &eta
+	var left core.FolderSize
+	if plan.BackupSize > v.SizeBackedUp() {
+		left = plan.BackupSize - v.SizeBackedUp()
 	}
-	return timePassed, nil
+	eta := v.eta.estimate(timePassed, v.SizeBackedUp(), left)
+	return timePassed, eta
 }
 
 // PrintTotalStatistics print results on backup session completion. Print all statistics
 // including time taken, volume processed, errors happens and so on.
 func (v *Progress) PrintTotalStatistics(lg logger.PackageLog, plan *Plan) error {
+	v.warnBadEstimates(lg, plan)
 	lines, err := v.getTotalStatistics(plan)
 	if err != nil {
 		lg.Error(err)
@@ -155,6 +179,112 @@ func (v *Progress) PreviousBackupsUsed(prevBackups *PreviousBackups) {
 	v.PreviousBackups = prevBackups
 }
 
+// RsyncCallDone register size and duration of successful RSYNC call
+// made to backup module, to evaluate module performance metrics.
+// Size actually transferred or linked is measured in destination folder
+// to reconcile it with size predicted in plan stage.
+func (v *Progress) RsyncCallDone(module *Module, paths core.SrcDstPath,
+	predictedSize core.FolderSize, recursive bool, duration time.Duration) {
+
+	if v.rsyncCallStats == nil {
+		v.rsyncCallStats = make(map[string]*rsyncCallStatistics)
+	}
+	id := GenerateSourceID(module.SourceRsync)
+	stats, ok := v.rsyncCallStats[id]
+	if !ok {
+		stats = &rsyncCallStatistics{}
+		v.rsyncCallStats[id] = stats
+	}
+	actualSize, err := getDestFolderSize(paths.DestPath, recursive)
+	if err != nil {
+		LocalLog.Debugf("Can't measure size of %q: %v", paths.DestPath, err)
+		stats.add(predictedSize, duration)
+		return
+	}
+	item := sizeDiscrepancy{Path: paths.RsyncSourcePath,
+		Predicted: predictedSize, Actual: actualSize}
+	LocalLog.Debugf("Size of %q predicted %v, actual %v (%s)", item.Path,
+		item.Predicted, item.Actual, item.formatDeviation())
+	stats.sizes.add(item)
+	stats.add(actualSize, duration)
+}
+
+// GetModuleStatistics return performance metrics evaluated
+// for each module, indexed by source identifier.
+func (v *Progress) GetModuleStatistics() map[string]*ModuleStatistics {
+	result := make(map[string]*ModuleStatistics)
+	for id, stats := range v.rsyncCallStats {
+		if s := stats.getModuleStatistics(); s != nil {
+			result[id] = s
+		}
+	}
+	return result
+}
+
+// ModuleSkipped register RSYNC source skipped due to critical error
+// in current session. Size left to backup is accounted as failed,
+// and whole source is scheduled to retry later.
+func (v *Progress) ModuleSkipped(node Node, err error) {
+	v.SkippedModules = append(v.SkippedModules, node.Module.SourceRsync)
+	var left core.FolderSize
+	if node.RootDir.Metrics.FullSize != nil && v.Progress != nil &&
+		*node.RootDir.Metrics.FullSize > v.Progress.GetTotal() {
+		left = *node.RootDir.Metrics.FullSize - v.Progress.GetTotal()
+	}
+	v.TotalProgress.Add(core.NewProgressFailed(left))
+	// replace folders of the source failed before with source root
+	var folders []FailedFolder
+	for _, folder := range v.FailedFolders {
+		if folder.SourceRsync != node.Module.SourceRsync {
+			folders = append(folders, folder)
+		}
+	}
+	v.FailedFolders = append(folders, FailedFolder{SourceRsync: node.Module.SourceRsync,
+		BackupType: core.FBT_RECURSIVE, Size: node.RootDir.GetTotalSize(), Error: err.Error()})
+	v.registerFailedModule(&node.Module)
+}
+
+// registerFailedModule keep module of failed folder
+// to locate folder in destination later.
+func (v *Progress) registerFailedModule(module *Module) {
+	if v.failedModules == nil {
+		v.failedModules = make(map[string]Module)
+	}
+	v.failedModules[module.SourceRsync] = *module
+}
+
+// GetFailedFolderPath return absolute destination path of folder
+// failed to backup in current session.
+func (v *Progress) GetFailedFolderPath(folder FailedFolder) string {
+	module, ok := v.failedModules[folder.SourceRsync]
+	if !ok {
+		return v.GetBackupFullPath(v.BackupFolder)
+	}
+	return filepath.Join(v.GetModuleBackupFullPath(&module, v.BackupFolder),
+		module.DestSubPath, filepath.FromSlash(folder.RelativePath))
+}
+
+// FolderFailed register folder failed to backup in current session
+// with its size and error, to retry it later. Folder path saved
+// relative to module root.
+func (v *Progress) FolderFailed(module *Module, paths core.SrcDstPath,
+	backupType core.FolderBackupType, size core.FolderSize, sessionErr error) error {
+
+	rootPath := filepath.Join(v.GetModuleBackupFullPath(module, v.BackupFolder), module.DestSubPath)
+	relativePath, err := filepath.Rel(rootPath, paths.DestPath)
+	if err != nil {
+		return err
+	}
+	if relativePath == "." {
+		relativePath = ""
+	}
+	v.FailedFolders = append(v.FailedFolders, FailedFolder{SourceRsync: module.SourceRsync,
+		RelativePath: filepath.ToSlash(relativePath), BackupType: backupType,
+		Size: size, Error: sessionErr.Error()})
+	v.registerFailedModule(module)
+	return nil
+}
+
 // SetRootDestination set absolute destination path,
 // where backup session will create it new subfolder and store data.
 func (v *Progress) SetRootDestination(rootDestPath string) {
@@ -178,6 +308,12 @@ func (v *Progress) GetBackupFullPath(backupFolder string) string {
 	return backupFullPath
 }
 
+// GetModuleBackupFullPath return absolute path of backup session subfolder,
+// located in module destination root, which might differ from profile one.
+func (v *Progress) GetModuleBackupFullPath(module *Module, backupFolder string) string {
+	return filepath.Join(module.GetDestRoot(v.RootDest), backupFolder)
+}
+
 // EventPlanStage_NodeStructureStartInquiry report about start inquiry of RSYNC source (1st stage).
 func (v *Progress) EventPlanStage_NodeStructureStartInquiry(sourceID int,
 	sourceRsync string) error {
@@ -204,7 +340,9 @@ func (v *Progress) EventPlanStage_NodeStructureDoneInquiry(sourceID int,
 
 	folderCount := dir.GetFoldersCount()
 	skipFolderCount := dir.GetFoldersIgnoreCount()
-	v.Log.Infof("%s, %s, %s",
+	totalSize := dir.GetTotalSize()
+	log := core.LogWithFields(v.Log, core.LogFields{Folder: sourceRsync, Bytes: &totalSize})
+	log.Infof("%s, %s, %s",
 		locale.TP(MsgLogPlanStageSourceFolderCountInfo,
 			struct{ FolderCount int }{FolderCount: folderCount}, folderCount),
 		locale.TP(MsgLogPlanStageSourceSkipFolderCountInfo,
@@ -212,7 +350,7 @@ func (v *Progress) EventPlanStage_NodeStructureDoneInquiry(sourceID int,
 		locale.T(MsgLogPlanStageSourceTotalSizeInfo,
 			struct {
 				TotalSize string
-			}{TotalSize: core.GetReadableSize(dir.GetTotalSize())}))
+			}{TotalSize: core.GetReadableSize(totalSize)}))
 
 	if v.Notifier != nil {
 		err := v.Notifier.NotifyPlanStage_NodeStructureDoneInquiry(sourceID,
@@ -225,6 +363,84 @@ func (v *Progress) EventPlanStage_NodeStructureDoneInquiry(sourceID int,
 	return nil
 }
 
+// EventBackupStage_NodeStartBackup report about backup start of RSYNC source (2nd stage).
+func (v *Progress) EventBackupStage_NodeStartBackup(sourceID int, node Node) error {
+	if v.Notifier != nil {
+		err := v.Notifier.NotifyBackupStage_NodeStartBackup(sourceID,
+			node.Module.SourceRsync, node.RootDir.GetTotalSize())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// EventBackupStage_NodeDoneBackup report about backup end of RSYNC source (2nd stage).
+func (v *Progress) EventBackupStage_NodeDoneBackup(sourceID int, node Node,
+	sessionErr error) error {
+
+	if v.Notifier != nil {
+		var sizeDone core.SizeProgress
+		if v.Progress != nil {
+			sizeDone = *v.Progress
+		}
+		err := v.Notifier.NotifyBackupStage_NodeDoneBackup(sourceID,
+			node.Module.SourceRsync, sizeDone, sessionErr)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// EventBackupStage_NetworkStateChanged report that backup paused waiting
+// for network, or resumed once network restored or waiting timed out (2nd stage).
+func (v *Progress) EventBackupStage_NetworkStateChanged(sourceRsync string,
+	waiting bool, err error) {
+
+	if waiting {
+		v.Log.Warn(locale.T(MsgLogBackupStageWaitingForNetwork,
+			struct {
+				Path  string
+				Error error
+			}{Path: sourceRsync, Error: err}))
+	} else if err == nil {
+		v.Log.Info(locale.T(MsgLogBackupStageNetworkRestored,
+			struct{ Path string }{Path: sourceRsync}))
+	} else {
+		v.Log.Warn(locale.T(MsgLogBackupStageNetworkWaitTimeout,
+			struct{ Path string }{Path: sourceRsync}))
+	}
+	if v.Notifier != nil {
+		err := v.Notifier.NotifyBackupStage_NetworkStateChanged(sourceRsync, waiting)
+		if err != nil {
+			LocalLog.Warn(err)
+		}
+	}
+}
+
+// EventBackupStage_ProcessHung report that RSYNC process was killed as hung,
+// with diagnostic snapshot: elapsed time and the most recent output (2nd stage).
+func (v *Progress) EventBackupStage_ProcessHung(paths core.SrcDstPath,
+	snapshot *rsync.ProcessSnapshot) {
+
+	sections := 2
+	v.Log.Warn(locale.T(MsgLogBackupStageRsyncProcessHung,
+		struct{ Path, Idle, Elapsed string }{Path: paths.RsyncSourcePath,
+			Idle:    core.FormatDurationToDaysHoursMinsSecs(snapshot.Idle, true, &sections),
+			Elapsed: core.FormatDurationToDaysHoursMinsSecs(snapshot.Elapsed, true, &sections)}))
+	if len(snapshot.LastLines) == 0 {
+		v.Log.Warn(locale.T(MsgLogBackupStageRsyncProcessNoOutput, nil))
+	} else {
+		v.Log.Warn(locale.T(MsgLogBackupStageRsyncProcessLastOutput, nil))
+		for _, line := range snapshot.LastLines {
+			v.Log.Warn(f("    %s", line))
+		}
+	}
+}
+
 // EventBackupStage_FolderStartBackup report about backup folder start (2nd stage).
 func (v *Progress) EventBackupStage_FolderStartBackup(paths core.SrcDstPath,
 	backupType core.FolderBackupType, plan *Plan) error {
@@ -241,7 +457,7 @@ func (v *Progress) EventBackupStage_FolderStartBackup(paths core.SrcDstPath,
 	etaStr := "*"
 	if eta != nil {
 		sections := 2
-		etaStr = core.FormatDurationToDaysHoursMinsSecs(*eta, true, &sections)
+		etaStr = core.FormatDurationToDaysHoursMinsSecs(eta.Expected, true, &sections)
 	}
 
 	msg := locale.T(MsgLogBackupStageProgressBackupSuccess,
@@ -250,10 +466,11 @@ func (v *Progress) EventBackupStage_FolderStartBackup(paths core.SrcDstPath,
 			BackupAction: GetBackupTypeDescription(backupType),
 			FolderPath:   path})
 
+	log := core.LogWithFields(v.Log, core.LogFields{Folder: path, Detail: true})
 	if backupType == core.FBT_SKIP {
-		v.Log.Notify(msg)
+		log.Notify(msg)
 	} else {
-		v.Log.Info(msg)
+		log.Info(msg)
 	}
 
 	if v.Notifier != nil {
@@ -274,6 +491,7 @@ func (v *Progress) EventBackupStage_FolderDoneBackup(paths core.SrcDstPath,
 
 	v.Progress.Add(sizeDone)
 	v.TotalProgress.Add(sizeDone)
+	v.eta.addSample(time.Now(), v.SizeBackedUp())
 
 	timePassed, eta := v.CalcTimePassedAndETA(plan)
 	leftToBackup := v.LeftToBackup(plan)
@@ -285,6 +503,13 @@ func (v *Progress) EventBackupStage_FolderDoneBackup(paths core.SrcDstPath,
 		if err != nil {
 			return err
 		}
+		if backupType != core.FBT_SKIP && v.rsyncOutput != nil {
+			err = v.Notifier.NotifyBackupStage_FolderRsyncOutput(backupFolder,
+				paths, v.rsyncOutput.Lines())
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
@@ -375,6 +600,10 @@ func (v *Progress) getTotalStatistics(plan *Plan) ([]string, error) {
 	} else {
 		wli(&b, 3, locale.T(MsgLogStatisticsBackupStageNoValidPreviousBackupFound, nil))
 	}
+	for _, root := range v.HardLinksUnsupported {
+		wli(&b, 3, locale.T(MsgLogStatisticsBackupStageDeduplicationDisabled,
+			struct{ Path string }{Path: root}))
+	}
 
 	var size core.FolderSize
 	if v.TotalProgress.Completed != nil {
@@ -394,9 +623,17 @@ func (v *Progress) getTotalStatistics(plan *Plan) ([]string, error) {
 	}
 	wli(&b, 3, locale.T(MsgLogStatisticsBackupStageFailedToBackupSize, struct{ FailedToBackupSize string }{
 		FailedToBackupSize: core.GetReadableSize(size)}))
+	if len(v.SkippedModules) > 0 {
+		wli(&b, 3, locale.TP(MsgLogStatisticsBackupStageSkippedModules,
+			struct{ ModuleCount int }{ModuleCount: len(v.SkippedModules)}, len(v.SkippedModules)))
+		for _, sourceRsync := range v.SkippedModules {
+			wli(&b, 4, sourceRsync)
+		}
+	}
 	timeTaken = v.EndBackupTime.Sub(v.StartBackupTime)
 	wli(&b, 3, locale.T(MsgLogStatisticsBackupStageTimeTaken, struct{ TimeTaken string }{
 		TimeTaken: core.FormatDurationToDaysHoursMinsSecs(timeTaken, true, &sections)}))
+	v.writeSizeReconciliation(&b, plan)
 	wli(&b, 0, DoubleSplitLogLine)
 	return splitToLines(&b)
 }
@@ -0,0 +1,343 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"syscall"
+
+	"github.com/d2r2/go-rsync/core"
+	"github.com/d2r2/go-rsync/locale"
+)
+
+// QuotaPolicy define how to proceed, when destination usage
+// would exceed quota (size budget) of the profile.
+type QuotaPolicy string
+
+const (
+	// Log warning and continue backup session.
+	QUOTA_POLICY_WARN QuotaPolicy = "warn"
+	// Delete oldest sessions (except frozen ones) to fit quota.
+	QUOTA_POLICY_PRUNE QuotaPolicy = "prune"
+	// Terminate backup session.
+	QUOTA_POLICY_ABORT QuotaPolicy = "abort"
+)
+
+// QuotaExceededError signify that backup session
+// terminated, since it would exceed quota.
+type QuotaExceededError struct {
+	Usage uint64
+	Quota uint64
+}
+
+func (v *QuotaExceededError) Error() string {
+	return locale.T(MsgLogQuotaExceededError,
+		struct{ Usage, Quota string }{Usage: core.FormatSize(v.Usage, true),
+			Quota: core.FormatSize(v.Quota, true)})
+}
+
+// IsQuotaExceededError check that error able to cast
+// to QuotaExceededError.
+func IsQuotaExceededError(err error) bool {
+	if err != nil {
+		_, ok := err.(*QuotaExceededError)
+		return ok
+	}
+	return false
+}
+
+// fileID identify file regardless of number of hard links.
+type fileID struct {
+	dev, ino uint64
+}
+
+// fileUsage keep file disk usage along with number
+// of backup sessions, which refer to the file.
+type fileUsage struct {
+	size uint64
+	refs int
+}
+
+// destUsage keep disk usage of backup sessions found in destination
+// roots. Files hard linked between sessions (deduplication) are
+// counted only once, and size of blocks allocated is taken instead
+// of file size, so usage reflect actual space occupied, including
+// sparse files.
+type destUsage struct {
+	files map[fileID]*fileUsage
+	// File identifiers referenced by session, either completed or current one.
+	sessions map[string][]fileID
+	// Session folder paths in each destination root, by session folder name.
+	paths map[string][]string
+	// Completed session folder names, oldest first.
+	order  []string
+	frozen map[string]bool
+	total  uint64
+}
+
+// newDestUsage measure disk usage of completed backup sessions
+// located in destination roots. Sessions of other profiles sharing
+// the same destination are ignored, so they are never pruned.
+func newDestUsage(roots []string, profile ProfileIdentity) (*destUsage, error) {
+	v := &destUsage{files: make(map[fileID]*fileUsage),
+		sessions: make(map[string][]fileID), paths: make(map[string][]string),
+		frozen: make(map[string]bool)}
+	modTimes := make(map[string]int64)
+	for _, root := range roots {
+		sessions, err := ListSessions(root)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		for _, session := range sessions {
+			if !isSessionOwnedBy(session.Path, profile) {
+				continue
+			}
+			name := filepath.Base(session.Path)
+			if _, ok := v.paths[name]; !ok {
+				v.order = append(v.order, name)
+				modTimes[name] = session.ModTime.UnixNano()
+			}
+			v.paths[name] = append(v.paths[name], session.Path)
+			v.frozen[name] = v.frozen[name] || session.Frozen
+			_, err = v.addFolder(name, session.Path)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	sort.SliceStable(v.order, func(i, j int) bool {
+		return modTimes[v.order[i]] < modTimes[v.order[j]]
+	})
+	return v, nil
+}
+
+// addFolder take into account files located in folder, which
+// belong to session specified. Return size of files not found
+// in other sessions.
+func (v *destUsage) addFolder(session, path string) (uint64, error) {
+	var added uint64
+	seen := make(map[fileID]bool)
+	for _, id := range v.sessions[session] {
+		seen[id] = true
+	}
+	err := filepath.Walk(path, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		id := fileID{ino: uint64(len(v.files)) + 1}
+		size := uint64(info.Size())
+		if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+			id = fileID{dev: uint64(stat.Dev), ino: uint64(stat.Ino)}
+			// Blocks are counted in 512-byte units regardless of file system.
+			size = uint64(stat.Blocks) * 512
+		}
+		if seen[id] {
+			return nil
+		}
+		seen[id] = true
+		v.sessions[session] = append(v.sessions[session], id)
+		file, ok := v.files[id]
+		if !ok {
+			file = &fileUsage{size: size}
+			v.files[id] = file
+			v.total += file.size
+			added += file.size
+		}
+		file.refs++
+		return nil
+	})
+	return added, err
+}
+
+// exclusiveSize return size of files, which belong to session
+// specified only, so space to free once session deleted.
+func (v *destUsage) exclusiveSize(session string) uint64 {
+	var size uint64
+	for _, id := range v.sessions[session] {
+		if file := v.files[id]; file.refs == 1 {
+			size += file.size
+		}
+	}
+	return size
+}
+
+// removeSession delete session folders in all destination
+// roots. Return size of space freed.
+func (v *destUsage) removeSession(session string) (uint64, error) {
+	for _, path := range v.paths[session] {
+		err := os.RemoveAll(path)
+		if err != nil {
+			return 0, err
+		}
+	}
+	var freed uint64
+	for _, id := range v.sessions[session] {
+		file := v.files[id]
+		file.refs--
+		if file.refs == 0 {
+			freed += file.size
+			delete(v.files, id)
+		}
+	}
+	v.total -= freed
+	delete(v.sessions, session)
+	for i, item := range v.order {
+		if item == session {
+			v.order = append(v.order[:i], v.order[i+1:]...)
+			break
+		}
+	}
+	return freed, nil
+}
+
+// getLinkDestSessions return backup sessions passed to RSYNC as --link-dest
+// base of any source in plan, which must survive quota pruning. No session
+// is protected, when deduplication is disabled.
+func getLinkDestSessions(plan *Plan, prevBackups *PreviousBackups) map[string]bool {
+	keep := make(map[string]bool)
+	if !plan.Config.usePreviousBackupEnabled() {
+		return keep
+	}
+	for _, node := range plan.Nodes {
+		sourceID := GenerateSourceID(node.Module.SourceRsync)
+		for _, item := range prevBackups.FilterBySourceID(sourceID).Backups {
+			// session folder has the same name in each destination root
+			keep[filepath.Base(filepath.Dir(item.SignatureFileName))] = true
+		}
+	}
+	return keep
+}
+
+// quotaGuard enforce quota of destination usage
+// before and during backup session.
+type quotaGuard struct {
+	quota  uint64
+	policy QuotaPolicy
+	usage  *destUsage
+	// Size expected to be added by current session.
+	expected uint64
+	// Size of RSYNC sources estimated in plan stage.
+	backupSize uint64
+	// Current session deduplicate files with previous ones.
+	dedup bool
+	// Size actually added by current session so far.
+	added  uint64
+	warned bool
+}
+
+// newQuotaGuard measure destination usage, if quota specified
+// in configuration, and estimate size to be added by backup session.
+// Return nil, if quota is disabled.
+func newQuotaGuard(plan *Plan, progress *Progress, roots []string) (*quotaGuard, error) {
+	quota := plan.Config.getQuotaSize()
+	if quota == 0 {
+		return nil, nil
+	}
+	progress.Log.Info(locale.T(MsgLogQuotaMeasuringUsage, nil))
+	usage, err := newDestUsage(roots, plan.Config.Profile)
+	if err != nil {
+		return nil, err
+	}
+	v := &quotaGuard{quota: quota, policy: plan.Config.getQuotaPolicy(), usage: usage,
+		backupSize: plan.BackupSize.GetByteCount(), dedup: plan.Config.usePreviousBackupEnabled()}
+	v.estimate()
+	progress.Log.Info(locale.T(MsgLogQuotaUsage,
+		struct{ Usage, Expected, Quota string }{Usage: core.FormatSize(usage.total, true),
+			Expected: core.FormatSize(v.expected, true), Quota: core.FormatSize(quota, true)}))
+	return v, nil
+}
+
+// estimate calculate size expected to be added by current session.
+func (v *quotaGuard) estimate() {
+	if v.dedup && len(v.usage.order) > 0 {
+		// With deduplication session is expected to add as much
+		// as the most recent one exclusively keep.
+		v.expected = v.usage.exclusiveSize(v.usage.order[len(v.usage.order)-1])
+	} else {
+		v.expected = v.backupSize
+	}
+}
+
+// nodeDone take into account files transferred to RSYNC source
+// destination folder.
+func (v *quotaGuard) nodeDone(session, path string) error {
+	added, err := v.usage.addFolder(session, path)
+	// Folder is absent, if RSYNC source failed and skipped.
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	v.added += added
+	return nil
+}
+
+// enforce verify that destination usage along with size left
+// to add stay within quota, otherwise apply quota policy.
+// Sessions listed in keep are never deleted.
+func (v *quotaGuard) enforce(progress *Progress, keep map[string]bool) error {
+	var left uint64
+	if v.expected > v.added {
+		left = v.expected - v.added
+	}
+	if v.usage.total+left <= v.quota {
+		return nil
+	}
+	switch v.policy {
+	case QUOTA_POLICY_ABORT:
+		return &QuotaExceededError{Usage: v.usage.total + left, Quota: v.quota}
+	case QUOTA_POLICY_PRUNE:
+		pruned := false
+		for _, session := range append([]string(nil), v.usage.order...) {
+			if v.usage.total+left <= v.quota {
+				break
+			}
+			if v.usage.frozen[session] || keep[session] {
+				continue
+			}
+			freed, err := v.usage.removeSession(session)
+			if err != nil {
+				return err
+			}
+			pruned = true
+			progress.Log.Info(locale.T(MsgLogQuotaSessionPruned,
+				struct{ Session, Size string }{Session: session,
+					Size: core.FormatSize(freed, true)}))
+		}
+		if pruned {
+			// Files shared with sessions pruned belong now
+			// to remaining sessions only, so estimate again.
+			v.estimate()
+			if v.expected > v.added {
+				left = v.expected - v.added
+			} else {
+				left = 0
+			}
+		}
+		if v.usage.total+left <= v.quota {
+			return nil
+		}
+	}
+	if !v.warned {
+		v.warned = true
+		progress.Log.Warn(locale.T(MsgLogQuotaExceededWarning,
+			struct{ Usage, Quota string }{Usage: core.FormatSize(v.usage.total+left, true),
+				Quota: core.FormatSize(v.quota, true)}))
+	}
+	return nil
+}
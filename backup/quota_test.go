@@ -0,0 +1,229 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package backup
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testSession describe backup session created in destination for test.
+type testSession struct {
+	name    string
+	profile string
+	size    int
+	frozen  bool
+}
+
+// createTestSessions create completed backup sessions in root folder,
+// where sessions listed first are the oldest ones.
+func createTestSessions(t *testing.T, root string, sessions []testSession) {
+	start := time.Now().Add(-time.Hour)
+	for i, item := range sessions {
+		path := filepath.Join(root, item.name)
+		err := os.MkdirAll(filepath.Join(path, "data"), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ioutil.WriteFile(filepath.Join(path, "data", "file"), make([]byte, item.size), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = CreateMetadataSignatureFile(ProfileIdentity{ID: item.profile}, nil, nil, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		modTime := start.Add(time.Duration(i) * time.Minute)
+		err = os.Chtimes(filepath.Join(path, GetMetadataSignatureFileName()), modTime, modTime)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if item.frozen {
+			err = FreezeSession(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestQuotaGuardEnforce(t *testing.T) {
+	sessions := []testSession{
+		{name: "s1", profile: "1", size: 100000},
+		{name: "other", profile: "2", size: 500000},
+		{name: "s2", profile: "1", size: 100000, frozen: true},
+		{name: "s3", profile: "1", size: 100000},
+		{name: "s4", profile: "1", size: 100000},
+	}
+	cases := []struct {
+		name       string
+		policy     QuotaPolicy
+		quota      uint64
+		backupSize uint64
+		keep       map[string]bool
+		err        bool
+		removed    []string
+	}{
+		{name: "within quota", policy: QUOTA_POLICY_PRUNE, quota: 1000000, backupSize: 100000},
+		{name: "warn", policy: QUOTA_POLICY_WARN, quota: 300000, backupSize: 100000},
+		{name: "abort", policy: QUOTA_POLICY_ABORT, quota: 300000, backupSize: 100000, err: true},
+		{name: "prune oldest", policy: QUOTA_POLICY_PRUNE, quota: 450000, backupSize: 100000,
+			removed: []string{"s1"}},
+		{name: "prune skip frozen and kept", policy: QUOTA_POLICY_PRUNE, quota: 440000, backupSize: 100000,
+			keep: map[string]bool{"s1": true}, removed: []string{"s3"}},
+		{name: "prune never other profile", policy: QUOTA_POLICY_PRUNE, quota: 100000, backupSize: 100000,
+			removed: []string{"s1", "s3", "s4"}},
+	}
+	for _, item := range cases {
+		t.Run(item.name, func(t *testing.T) {
+			root, err := ioutil.TempDir("", "quota")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(root)
+			createTestSessions(t, root, sessions)
+
+			usage, err := newDestUsage([]string{root}, ProfileIdentity{ID: "1"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Usage is measured in blocks allocated, so signature
+			// files and markers add a block each to data files size.
+			if usage.total < 400000 || usage.total > 450000 {
+				t.Fatalf("usage %d doesn't match sessions of profile", usage.total)
+			}
+			guard := &quotaGuard{quota: item.quota, policy: item.policy,
+				usage: usage, backupSize: item.backupSize}
+			guard.estimate()
+			err = guard.enforce(&Progress{Log: LocalLog}, item.keep)
+			if item.err != IsQuotaExceededError(err) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			removed := make(map[string]bool)
+			for _, name := range item.removed {
+				removed[name] = true
+			}
+			for _, session := range sessions {
+				_, err := os.Stat(filepath.Join(root, session.name))
+				if exists := err == nil; exists == removed[session.name] {
+					t.Errorf("session %q exists %v, expected %v", session.name,
+						exists, !removed[session.name])
+				}
+			}
+		})
+	}
+}
+
+func TestGetLinkDestSessions(t *testing.T) {
+	sessions := []testSession{
+		{name: "s1", profile: "1", size: 100000},
+		{name: "s2", profile: "1", size: 100000, frozen: true},
+		{name: "s3", profile: "1", size: 100000},
+		{name: "s4", profile: "1", size: 100000},
+	}
+	source := "rsync://host/module"
+	cases := []struct {
+		name    string
+		dedup   bool
+		keep    []string
+		removed []string
+	}{
+		{name: "deduplication enabled", dedup: true, keep: []string{"s1"}, removed: []string{"s3"}},
+		{name: "deduplication disabled", dedup: false, removed: []string{"s1"}},
+	}
+	for _, item := range cases {
+		t.Run(item.name, func(t *testing.T) {
+			root, err := ioutil.TempDir("", "quota")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(root)
+			createTestSessions(t, root, sessions)
+
+			dedup := item.dedup
+			plan := &Plan{Config: &Config{UsePreviousBackup: &dedup},
+				Nodes: []Node{{Module: Module{SourceRsync: source}}}}
+			prevBackups := &PreviousBackups{Backups: []PrevBackup{
+				{SignatureFileName: filepath.Join(root, "s1", GetMetadataSignatureFileName()),
+					SourceID: GenerateSourceID(source)},
+				// matched to source, which is not in plan
+				{SignatureFileName: filepath.Join(root, "s4", GetMetadataSignatureFileName()),
+					SourceID: GenerateSourceID("rsync://host/other")},
+			}}
+			keep := getLinkDestSessions(plan, prevBackups)
+			if len(keep) != len(item.keep) {
+				t.Fatalf("getLinkDestSessions() = %v, expected %v", keep, item.keep)
+			}
+			for _, name := range item.keep {
+				if !keep[name] {
+					t.Fatalf("getLinkDestSessions() = %v, expected %v", keep, item.keep)
+				}
+			}
+
+			usage, err := newDestUsage([]string{root}, ProfileIdentity{ID: "1"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			guard := &quotaGuard{quota: 440000, policy: QUOTA_POLICY_PRUNE,
+				usage: usage, backupSize: 100000, dedup: dedup}
+			guard.estimate()
+			err = guard.enforce(&Progress{Log: LocalLog}, keep)
+			if err != nil {
+				t.Fatal(err)
+			}
+			removed := make(map[string]bool)
+			for _, name := range item.removed {
+				removed[name] = true
+			}
+			for _, session := range sessions {
+				_, err := os.Stat(filepath.Join(root, session.name))
+				if exists := err == nil; exists == removed[session.name] {
+					t.Errorf("session %q exists %v, expected %v", session.name,
+						exists, !removed[session.name])
+				}
+			}
+		})
+	}
+}
+
+func TestDestUsageAllocatedSize(t *testing.T) {
+	root, err := ioutil.TempDir("", "quota")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	createTestSessions(t, root, []testSession{
+		{name: "s1", profile: "1", size: 100000},
+		{name: "s2", profile: "1", size: 0},
+	})
+	// sparse file occupy almost no space
+	err = os.Truncate(filepath.Join(root, "s2", "data", "file"), 100000000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// hard linked file (deduplication) is counted once
+	err = os.Link(filepath.Join(root, "s1", "data", "file"),
+		filepath.Join(root, "s2", "data", "linked"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	usage, err := newDestUsage([]string{root}, ProfileIdentity{ID: "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage.total < 100000 || usage.total > 150000 {
+		t.Fatalf("usage %d doesn't match space allocated", usage.total)
+	}
+}
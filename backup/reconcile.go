@@ -0,0 +1,196 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package backup
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+
+	logger "github.com/d2r2/go-logger"
+	"github.com/d2r2/go-rsync/core"
+	"github.com/d2r2/go-rsync/locale"
+)
+
+const (
+	// Relative deviation of actual size from predicted one,
+	// which signify that plan stage estimate is badly off.
+	estimateDeviationThreshold = 0.25
+	// Deviation less than this size is not taken into account,
+	// since small folders give too noisy ratio.
+	estimateDeviationMinSize = 10 * core.MB
+	// Number of folders with largest deviation reported for each source.
+	reconcileFoldersReportCount = 5
+)
+
+// sizeDiscrepancy keep size predicted in plan stage and size
+// actually transferred or linked in backup stage.
+type sizeDiscrepancy struct {
+	Path      string
+	Predicted core.FolderSize
+	Actual    core.FolderSize
+}
+
+// diff return absolute difference between actual and predicted size.
+func (v sizeDiscrepancy) diff() uint64 {
+	if v.Actual > v.Predicted {
+		return uint64(v.Actual - v.Predicted)
+	}
+	return uint64(v.Predicted - v.Actual)
+}
+
+// deviation return relative deviation of actual size from predicted one.
+func (v sizeDiscrepancy) deviation() float64 {
+	if v.Predicted == 0 {
+		if v.Actual == 0 {
+			return 0
+		}
+		return 1
+	}
+	return (float64(v.Actual) - float64(v.Predicted)) / float64(v.Predicted)
+}
+
+// badlyOff verify that estimate deviate from actual size significantly.
+func (v sizeDiscrepancy) badlyOff() bool {
+	return v.diff() >= estimateDeviationMinSize &&
+		math.Abs(v.deviation()) >= estimateDeviationThreshold
+}
+
+// formatDeviation return deviation as signed percentage.
+func (v sizeDiscrepancy) formatDeviation() string {
+	return f("%+.0f%%", v.deviation()*100)
+}
+
+// sizeReconciliation accumulate predicted and actual sizes
+// of RSYNC calls made to backup single source.
+type sizeReconciliation struct {
+	total sizeDiscrepancy
+	// folders with largest deviation, sorted in descending order
+	folders []sizeDiscrepancy
+}
+
+// add register predicted and actual size of single RSYNC call.
+func (v *sizeReconciliation) add(item sizeDiscrepancy) {
+	v.total.Predicted += item.Predicted
+	v.total.Actual += item.Actual
+	if !item.badlyOff() {
+		return
+	}
+	v.folders = append(v.folders, item)
+	sort.SliceStable(v.folders, func(i, j int) bool {
+		return v.folders[i].diff() > v.folders[j].diff()
+	})
+	if len(v.folders) > reconcileFoldersReportCount {
+		v.folders = v.folders[:reconcileFoldersReportCount]
+	}
+}
+
+// getEstimateRatio return ratio of actual size to predicted one,
+// or zero if nothing predicted.
+func (v *sizeReconciliation) getEstimateRatio() float64 {
+	if v.total.Predicted == 0 || v.total.Actual == 0 {
+		return 0
+	}
+	return float64(v.total.Actual) / float64(v.total.Predicted)
+}
+
+// getDestFolderSize return total size of files found in destination
+// folder, either transferred or hard linked to previous backup.
+// Nested folders are taken into account only if recursive is true.
+func getDestFolderSize(destPath string, recursive bool) (core.FolderSize, error) {
+	var size core.FolderSize
+	if recursive {
+		err := filepath.Walk(destPath, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() {
+				size += core.NewFolderSize(info.Size())
+			}
+			return nil
+		})
+		return size, err
+	}
+	items, err := ioutil.ReadDir(destPath)
+	if err != nil {
+		return 0, err
+	}
+	for _, item := range items {
+		if !item.IsDir() {
+			size += core.NewFolderSize(item.Size())
+		}
+	}
+	return size, nil
+}
+
+// getSizeReconciliation return predicted and actual sizes accumulated
+// for module, or nil if nothing predicted.
+func (v *Progress) getSizeReconciliation(module Module) *sizeReconciliation {
+	stats, ok := v.rsyncCallStats[GenerateSourceID(module.SourceRsync)]
+	if !ok || stats.sizes.total.Predicted == 0 {
+		return nil
+	}
+	return &stats.sizes
+}
+
+// warnBadEstimates report sources, which size was badly
+// predicted in plan stage.
+func (v *Progress) warnBadEstimates(lg logger.PackageLog, plan *Plan) {
+	for _, node := range plan.Nodes {
+		sizes := v.getSizeReconciliation(node.Module)
+		if sizes != nil && sizes.total.badlyOff() {
+			lg.Warn(locale.T(MsgLogBackupStageEstimateBadlyOff,
+				struct{ RsyncSource, Deviation string }{
+					RsyncSource: node.Module.SourceRsync,
+					Deviation:   sizes.total.formatDeviation()}))
+		}
+	}
+}
+
+// writeSizeReconciliation add to the report size predicted in plan stage
+// against size actually transferred or linked for each source, with
+// folders which estimate was badly off.
+func (v *Progress) writeSizeReconciliation(b *bytes.Buffer, plan *Plan) {
+	wli := writeLineIndent
+	wli(b, 2, locale.T(MsgLogStatisticsReconciliationCaption, nil))
+	reported := false
+	for _, node := range plan.Nodes {
+		sizes := v.getSizeReconciliation(node.Module)
+		if sizes == nil {
+			continue
+		}
+		wli(b, 3, locale.T(MsgLogStatisticsReconciliationSource,
+			struct{ RsyncSource, PredictedSize, ActualSize, Deviation string }{
+				RsyncSource:   node.Module.SourceRsync,
+				PredictedSize: core.GetReadableSize(sizes.total.Predicted),
+				ActualSize:    core.GetReadableSize(sizes.total.Actual),
+				Deviation:     sizes.total.formatDeviation()}))
+		if sizes.total.badlyOff() {
+			wli(b, 4, locale.T(MsgLogStatisticsReconciliationBadlyOff, nil))
+		}
+		for _, folder := range sizes.folders {
+			wli(b, 4, locale.T(MsgLogStatisticsReconciliationFolder,
+				struct{ Path, PredictedSize, ActualSize, Deviation string }{
+					Path:          folder.Path,
+					PredictedSize: core.GetReadableSize(folder.Predicted),
+					ActualSize:    core.GetReadableSize(folder.Actual),
+					Deviation:     folder.formatDeviation()}))
+		}
+		reported = true
+	}
+	if !reported {
+		wli(b, 3, locale.T(MsgLogStatisticsReconciliationNoData, nil))
+	}
+}
@@ -0,0 +1,371 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package backup
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/d2r2/go-logger"
+	"github.com/d2r2/go-rsync/core"
+	"github.com/d2r2/go-rsync/locale"
+	"github.com/d2r2/go-rsync/rsync"
+)
+
+// FailedFolder describe folder failed to backup in the session,
+// which might be retried later into the same session folder.
+type FailedFolder struct {
+	// RSYNC source of the module, folder belongs to
+	SourceRsync string
+	// Folder path relative to module root ("" for module root)
+	RelativePath string
+	// Type of backup applied to the folder
+	BackupType core.FolderBackupType
+	// Size of the folder estimated in plan stage
+	Size core.FolderSize
+	// Error message of the last attempt
+	Error string
+}
+
+// IsSameFolder verify both items refer to the same folder.
+func (v FailedFolder) IsSameFolder(folder FailedFolder) bool {
+	return v.SourceRsync == folder.SourceRsync && v.RelativePath == folder.RelativePath
+}
+
+// RetryList keeps folders failed to backup in the session.
+// Saved in backup session folder next to signature file.
+type RetryList struct {
+	Folders []FailedFolder
+}
+
+// SaveRetryList serialize list of failed folders to the session folder.
+// If list is empty, previously saved file removed.
+func SaveRetryList(sessionPath string, list *RetryList) error {
+	fileName := filepath.Join(sessionPath, GetRetryListFileName())
+	if list == nil || len(list.Folders) == 0 {
+		err := os.Remove(fileName)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	e := gob.NewEncoder(file)
+	return e.Encode(list)
+}
+
+// LoadRetryList read list of failed folders saved in the session folder.
+func LoadRetryList(sessionPath string) (*RetryList, error) {
+	file, err := os.Open(filepath.Join(sessionPath, GetRetryListFileName()))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	list := &RetryList{}
+	d := gob.NewDecoder(file)
+	err = d.Decode(list)
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// RetryListExists verify that session folder contains folders to retry.
+func RetryListExists(sessionPath string) bool {
+	_, err := os.Stat(filepath.Join(sessionPath, GetRetryListFileName()))
+	return err == nil
+}
+
+// findModuleBySource return module with RSYNC source specified, or nil.
+func findModuleBySource(modules []Module, sourceRsync string) *Module {
+	for i := range modules {
+		if modules[i].SourceRsync == sourceRsync {
+			return &modules[i]
+		}
+	}
+	return nil
+}
+
+// RetryFailedFolders run backup of folders failed in previous session,
+// which is stored in sessionPath. Data and logs are written to the same
+// session folder; folders failed again are kept in retry list.
+// Progress returned even if error occurred, to report completion status.
+func RetryFailedFolders(ctx context.Context, lg logger.PackageLog, config *Config,
+	modules []Module, sessionPath string, notifier Notifier,
+	errorHookCall rsync.ErrorHookCall) (*Progress, error) {
+
+	return retryFolders(ctx, lg, config, modules, sessionPath, nil, notifier, errorHookCall)
+}
+
+// RetryFailedFolder run backup of single folder failed in previous
+// session, which is stored in sessionPath. Other folders are kept
+// in retry list untouched.
+func RetryFailedFolder(ctx context.Context, lg logger.PackageLog, config *Config,
+	modules []Module, sessionPath string, folder FailedFolder, notifier Notifier,
+	errorHookCall rsync.ErrorHookCall) (*Progress, error) {
+
+	return retryFolders(ctx, lg, config, modules, sessionPath, &folder, notifier, errorHookCall)
+}
+
+// retryFolders run backup of folders failed in previous session:
+// either all of them, or the one specified by folder.
+func retryFolders(ctx context.Context, lg logger.PackageLog, config *Config,
+	modules []Module, sessionPath string, folder *FailedFolder, notifier Notifier,
+	errorHookCall rsync.ErrorHookCall) (*Progress, error) {
+
+	progress := newProgress(ctx, lg, config, notifier)
+	progress.SetRootDestination(filepath.Dir(sessionPath))
+	// Log files root is not assigned yet, so existing
+	// session logs will be appended.
+	err := progress.SetBackupFolder(filepath.Base(sessionPath))
+	if err != nil {
+		return progress, err
+	}
+
+	list, err := LoadRetryList(sessionPath)
+	if err != nil {
+		return progress, err
+	}
+	// Folders not selected to retry are saved back to retry list.
+	var kept []FailedFolder
+	if folder != nil {
+		var selected []FailedFolder
+		for _, item := range list.Folders {
+			if item.IsSameFolder(*folder) {
+				selected = append(selected, item)
+			} else {
+				kept = append(kept, item)
+			}
+		}
+		list = &RetryList{Folders: selected}
+	}
+	if len(list.Folders) == 0 {
+		return progress, errors.New(locale.T(MsgLogRetryStageNothingToRetry,
+			struct{ Path string }{Path: sessionPath}))
+	}
+
+	modules, err = canonizeModules(modules)
+	if err != nil {
+		progress.Log.Error(err)
+		return progress, err
+	}
+
+	plan, err := buildRetryPlan(ctx, config, modules, list, progress)
+	if err != nil {
+		progress.Log.Error(err)
+		return progress, err
+	}
+
+	lock, err := AcquireDestLock(progress.Log, progress.RootDest, config.DestLockHook)
+	if err == nil {
+		err = runRetry(plan, progress, errorHookCall, kept)
+		err2 := lock.Release()
+		if err2 != nil {
+			progress.Log.Warn(err2)
+		}
+	}
+	if err != nil {
+		progress.Log.Error(locale.T(MsgLogBackupStageCriticalError,
+			struct{ Error error }{Error: err}))
+		logErrorSuggestion(progress.Log, err)
+	}
+
+	logFileName := filepath.Join(sessionPath, GetLogFileName())
+	progress.Log.Info(locale.T(MsgLogBackupStageSaveLogTo,
+		struct{ Path string }{Path: logFileName}))
+
+	progress.SayGoodbye(progress.Log)
+
+	return progress, err
+}
+
+// buildRetryPlan create plan, where each node is a single folder to retry.
+// Folder size measured again, since it might change from previous session.
+func buildRetryPlan(ctx context.Context, config *Config, modules []Module,
+	list *RetryList, progress *Progress) (*Plan, error) {
+
+	progress.StartPlanStage()
+
+	progress.Log.Info(DoubleSplitLogLine)
+	progress.Log.Info(locale.T(MsgLogRetryStageStarting,
+		struct {
+			FolderCount int
+			Path        string
+		}{FolderCount: len(list.Folders),
+			Path: progress.GetBackupFullPath(progress.BackupFolder)}))
+	progress.Log.Info(locale.T(MsgLogPlanStageStartTime,
+		struct{ Time string }{Time: progress.StartPlanTime.Format("2006 Jan 2 15:04:05")}))
+
+	// Get RSYNC protocol version to choose console text output parsing approach
+	_, protocol, err := rsync.GetRsyncVersion()
+	if err != nil && !rsync.IsExtractVersionAndProtocolError(err) {
+		return nil, err
+	}
+
+	var nodes []Node
+	var totalBackupSize core.FolderSize
+	for _, item := range list.Folders {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		module := findModuleBySource(modules, item.SourceRsync)
+		if module == nil {
+			progress.Log.Warn(locale.T(MsgLogRetryStageSourceNotFound,
+				struct{ Path string }{Path: item.SourceRsync}))
+			continue
+		}
+		paths := core.SrcDstPath{
+			RsyncSourcePath: core.RsyncPathJoin(module.SourceRsync, item.RelativePath),
+			DestPath: filepath.Join(progress.GetModuleBackupFullPath(module, progress.BackupFolder),
+				module.DestSubPath, item.RelativePath),
+		}
+		dir := core.NewRecursiveDir(paths, filepath.Base(paths.DestPath))
+		dir.Metrics.BackupType = item.BackupType
+
+		var size *core.FolderSize
+		if !module.SkipPlanEstimation {
+			if item.BackupType == core.FBT_CONTENT {
+				size, err = rsync.ObtainDirLocalSize(ctx, module.AuthPassword, module.GetFileFilter(),
+					dir, config.RsyncRetryCount, protocol, progress.RsyncLog)
+			} else {
+				size, err = rsync.ObtainDirFullSize(ctx, module.AuthPassword, module.GetFileFilter(),
+					dir, config.RsyncRetryCount, protocol, progress.RsyncLog)
+			}
+			if err != nil {
+				// Size is used only to track progress, so keep going.
+				progress.Log.Warn(locale.T(MsgLogRetryStageMeasureFolderError,
+					struct {
+						Path  string
+						Error error
+					}{Path: paths.RsyncSourcePath, Error: err}))
+			}
+		}
+		if size != nil {
+			*dir.Metrics.Size = *size
+			*dir.Metrics.FullSize = *size
+			totalBackupSize += *size
+		}
+
+		nodes = append(nodes, Node{Module: *module, RootDir: dir})
+		progress.Log.Info(locale.T(MsgLogRetryStageFolderToRetry,
+			struct{ Path, Size string }{Path: paths.RsyncSourcePath,
+				Size: core.GetReadableSize(dir.GetTotalSize())}))
+	}
+
+	progress.Log.Info(SingleSplitLogLine)
+	progress.FinishPlanStage()
+	progress.Log.Info(locale.T(MsgLogPlanStageEndTime,
+		struct{ Time string }{Time: progress.EndPlanTime.Format("2006 Jan 2 15:04:05")}))
+	plan := &Plan{Config: config, Nodes: nodes, BackupSize: totalBackupSize}
+	return plan, nil
+}
+
+// runRetry perform backup stage of retry session. Folders failed again
+// are saved to retry list together with kept ones.
+func runRetry(plan *Plan, progress *Progress, errorHookCall rsync.ErrorHookCall,
+	kept []FailedFolder) error {
+
+	progress.TotalProgress = &core.SizeProgress{}
+	progress.Progress = &core.SizeProgress{}
+	progress.StartBackupStage()
+
+	progress.Log.Info(locale.T(MsgLogBackupStageStartTime,
+		struct{ Time string }{Time: progress.StartBackupTime.Format("2006 Jan 2 15:04:05")}))
+
+	sessionPath := progress.GetBackupFullPath(progress.BackupFolder)
+	disableUnsupportedAttributes(plan, progress, progress.RootDest)
+
+	// Search for previous backup sessions to use for deduplication,
+	// excluding the session being repaired.
+	prevBackups, err := FindPrevBackupPathsInDestRoots(progress.Log, progress.RootDest,
+		selectModulesToDeduplicate(plan, progress, progress.RootDest),
+		plan.Config.numberOfPreviousBackupToUse())
+	if err != nil {
+		return err
+	}
+	var backups []PrevBackup
+	for _, item := range prevBackups.Backups {
+		// session folder has the same name in each destination root
+		if filepath.Base(filepath.Dir(item.SignatureFileName)) != progress.BackupFolder {
+			backups = append(backups, item)
+		}
+	}
+	prevBackups = &PreviousBackups{Backups: backups}
+	progress.PreviousBackupsUsed(prevBackups)
+
+	for i, node := range plan.Nodes {
+		progress.Log.Info(SingleSplitLogLine)
+		err := progress.EventBackupStage_NodeStartBackup(i, node)
+		if err != nil {
+			return err
+		}
+		paths := node.RootDir.GetPaths()
+		relativePath, err := filepath.Rel(filepath.Join(progress.GetModuleBackupFullPath(&node.Module,
+			progress.BackupFolder), node.Module.DestSubPath), paths.DestPath)
+		if err != nil {
+			return err
+		}
+		prevBackupPaths := prevBackups.FilterBySourceID(
+			GenerateSourceID(node.Module.SourceRsync)).GetDirPaths()
+		for j, path := range prevBackupPaths {
+			prevBackupPaths[j] = filepath.Join(path, relativePath)
+		}
+
+		progress.Progress = &core.SizeProgress{}
+		err = backupDir(node.RootDir, &node.Module, plan, progress, paths,
+			errorHookCall, prevBackupPaths)
+		err2 := progress.EventBackupStage_NodeDoneBackup(i, node, err)
+		if err != nil {
+			return err
+		}
+		if err2 != nil {
+			return err2
+		}
+	}
+
+	progress.Log.Info(SingleSplitLogLine)
+	if plan.Config.partialTransferEnabled() {
+		var sessionPaths []string
+		found := make(map[string]bool)
+		for _, node := range plan.Nodes {
+			path := progress.GetModuleBackupFullPath(&node.Module, progress.BackupFolder)
+			if !found[path] {
+				found[path] = true
+				sessionPaths = append(sessionPaths, path)
+			}
+		}
+		cleanPartialDirs(progress, sessionPaths)
+	}
+	// Keep folders failed again to retry them next time.
+	folders := append(kept, progress.FailedFolders...)
+	err = SaveRetryList(sessionPath, &RetryList{Folders: folders})
+	if err != nil {
+		return err
+	}
+	if len(folders) > 0 {
+		progress.Log.Info(locale.T(MsgLogRetryStageRetryListSaved,
+			struct{ Path string }{Path: filepath.Join(sessionPath, GetRetryListFileName())}))
+	}
+
+	progress.FinishBackupStage()
+	progress.Log.Info(locale.T(MsgLogBackupStageEndTime,
+		struct{ Time string }{Time: progress.EndBackupTime.Format("2006 Jan 2 15:04:05")}))
+
+	return progress.PrintTotalStatistics(progress.Log, plan)
+}
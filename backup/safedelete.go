@@ -0,0 +1,135 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package backup
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/d2r2/go-rsync/core"
+	"github.com/d2r2/go-rsync/locale"
+	"github.com/d2r2/go-rsync/rsync"
+)
+
+// SafeDeleteHookCall is a delegate used to ask user, whether backup
+// of RSYNC source should proceed, when too many files were deleted
+// in source since previous backup.
+type SafeDeleteHookCall func(sourceRsync string, fileCount int, size core.FolderSize) (proceed bool)
+
+// deletionEstimate keep files found in previous backup,
+// which are absent in RSYNC source now.
+type deletionEstimate struct {
+	FileCount int
+	Size      core.FolderSize
+}
+
+// exceeds verify that deletion estimate is above safe delete thresholds.
+func (v *deletionEstimate) exceeds(conf *Config) bool {
+	maxFiles, maxSize := conf.getSafeDeleteThresholds()
+	return maxFiles > 0 && v.FileCount > maxFiles ||
+		maxSize > 0 && v.Size > maxSize
+}
+
+// estimateDeletions run RSYNC in dry-run mode with --delete option against
+// module data kept in previous backup session, to find out how many files
+// would be removed, if previous backup was updated from source.
+func estimateDeletions(plan *Plan, module *Module, progress *Progress,
+	prevBackupPath string) (*deletionEstimate, error) {
+
+	options := rsync.NewOptions(rsync.WithDefaultParams(
+		GetRsyncParams(plan.Config, module, []string{"--times"}))).
+		AddParams("--recursive", "--delete", "--dry-run", "--itemize-changes").
+		SetRetryCount(plan.Config.RsyncRetryCount).
+		SetAuthPassword(module.AuthPassword).
+		SetNetworkWatchdog(progress.watchdog).
+		SetTimeouts(plan.Config.getRsyncTimeouts(module)).
+		SetPriority(plan.Config.getRsyncPriority(module)).
+		SetFileFilter(module.GetFileFilter())
+	paths := core.SrcDstPath{
+		RsyncSourcePath: core.RsyncPathJoin(module.SourceRsync, ""),
+		DestPath:        prevBackupPath,
+	}
+	var stdOut bytes.Buffer
+	sessionErr, _, criticalErr := rsync.RunRsyncWithRetry(progress.Context,
+		options, progress.RsyncLog, &stdOut, paths)
+	if criticalErr != nil {
+		return nil, criticalErr
+	}
+	if sessionErr != nil {
+		return nil, sessionErr
+	}
+	lines, err := splitToLines(&stdOut)
+	if err != nil {
+		return nil, err
+	}
+	estimate := &deletionEstimate{}
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "*deleting") {
+			continue
+		}
+		relPath := strings.TrimSpace(strings.TrimPrefix(line, "*deleting"))
+		// folders reported with trailing slash
+		if relPath == "" || strings.HasSuffix(relPath, "/") {
+			continue
+		}
+		estimate.FileCount++
+		if stat, err := os.Lstat(filepath.Join(prevBackupPath, relPath)); err == nil {
+			estimate.Size += core.NewFolderSize(stat.Size())
+		}
+	}
+	return estimate, nil
+}
+
+// checkSafeDelete verify, that too many files haven't disappeared in RSYNC
+// source since previous backup (for instance, as a result of accidental wipe).
+// If thresholds exceeded, user is asked to confirm backup continuation.
+func checkSafeDelete(plan *Plan, node Node, progress *Progress,
+	prevBackups *PreviousBackups) error {
+
+	if !plan.Config.safeDeleteEnabled() || len(prevBackups.Backups) == 0 {
+		return nil
+	}
+	// the most recent backup go first
+	prevBackupPath := prevBackups.Backups[0].GetDirPath()
+	progress.Log.Info(locale.T(MsgLogBackupStageSafeDeleteChecking,
+		struct{ Path string }{Path: prevBackupPath}))
+	estimate, err := estimateDeletions(plan, &node.Module, progress, prevBackupPath)
+	if err != nil {
+		if progress.Context.Err() != nil {
+			return err
+		}
+		progress.Log.Warn(locale.T(MsgLogBackupStageSafeDeleteCheckError,
+			struct{ Error error }{Error: err}))
+		return nil
+	}
+	progress.Log.Info(locale.TP(MsgLogBackupStageSafeDeleteFilesDeleted,
+		struct {
+			FileCount int
+			Size      string
+		}{FileCount: estimate.FileCount, Size: core.GetReadableSize(estimate.Size)},
+		estimate.FileCount))
+	if !estimate.exceeds(plan.Config) {
+		return nil
+	}
+	progress.Log.Warn(locale.T(MsgLogBackupStageSafeDeleteThresholdExceeded,
+		struct{ RsyncSource string }{RsyncSource: node.Module.SourceRsync}))
+	if plan.Config.SafeDeleteHook != nil &&
+		plan.Config.SafeDeleteHook(node.Module.SourceRsync, estimate.FileCount, estimate.Size) {
+		progress.Log.Info(locale.T(MsgLogBackupStageSafeDeleteConfirmed, nil))
+		return nil
+	}
+	return errors.New(locale.T(MsgLogBackupStageSafeDeleteRejectedError,
+		struct{ RsyncSource string }{RsyncSource: node.Module.SourceRsync}))
+}
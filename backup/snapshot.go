@@ -0,0 +1,358 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package backup
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"syscall"
+	"text/template"
+	"time"
+
+	logger "github.com/d2r2/go-logger"
+	"github.com/d2r2/go-rsync/locale"
+	shell "github.com/d2r2/go-shell"
+)
+
+const (
+	BTRFS_APP_CMD = "btrfs"
+	ZFS_APP_CMD   = "zfs"
+
+	// Snapshot destination in addition to timestamped session folders.
+	SNAPSHOT_MODE_APPEND = "append"
+	// Snapshot destination instead of keeping timestamped session folders:
+	// only latest session folder kept in destination (to deduplicate next
+	// backup against it), while previous sessions survive in snapshots.
+	SNAPSHOT_MODE_REPLACE = "replace"
+
+	// Default template contain profile ID, so profiles sharing the same
+	// btrfs subvolume or ZFS dataset never prune snapshots of each other.
+	SNAPSHOT_DEFAULT_NAME_TEMPLATE = "gorsync_{{.ProfileID}}_{{.Date}}-{{.Time}}"
+	// Folder in destination root, where btrfs snapshots are created.
+	BTRFS_SNAPSHOT_FOLDER = ".snapshots"
+	// Inode number of btrfs subvolume root folder.
+	btrfsSubvolumeRootInode = 256
+)
+
+// snapshotNameFields contain fields available in snapshot name template.
+type snapshotNameFields struct {
+	Profile   string
+	ProfileID string
+	Date      string
+	Time      string
+}
+
+// Snapshot describe file system snapshot created by application.
+type Snapshot struct {
+	Name string
+	Time time.Time
+}
+
+// SnapshotTarget describe how to snapshot destination located
+// on btrfs subvolume or ZFS dataset after backup session,
+// and how many snapshots to keep.
+type SnapshotTarget struct {
+	Mode    string
+	Profile ProfileIdentity
+	// Number of snapshots to keep, 0 - keep all.
+	Keep        int
+	template    *template.Template
+	namePattern *regexp.Regexp
+}
+
+// NewSnapshotTarget verify snapshot name template and create SnapshotTarget object.
+// Template should contain {{.Date}} and {{.Time}} fields to make names unique,
+// optional {{.Profile}} field is substituted with profile name, and {{.ProfileID}}
+// with stable profile ID, so snapshots are still pruned after profile rename.
+func NewSnapshotTarget(mode, nameTemplate string, keep int,
+	profile ProfileIdentity) (*SnapshotTarget, error) {
+	nameTemplate = strings.TrimSpace(nameTemplate)
+	if nameTemplate == "" {
+		nameTemplate = SNAPSHOT_DEFAULT_NAME_TEMPLATE
+	}
+	tmpl, err := template.New("snapshot").Option("missingkey=error").Parse(nameTemplate)
+	if err != nil {
+		return nil, errors.New(locale.T(MsgLogSnapshotNameTemplateError,
+			struct {
+				Template string
+				Error    error
+			}{Template: nameTemplate, Error: err}))
+	}
+	v := &SnapshotTarget{Mode: mode, Profile: profile, Keep: keep, template: tmpl}
+	// Render template with markers in place of date and time,
+	// to build pattern matching names of snapshots created before.
+	const dateMarker, timeMarker = "\x00date\x00", "\x00time\x00"
+	name, err := v.formatName(dateMarker, timeMarker)
+	if err != nil {
+		return nil, errors.New(locale.T(MsgLogSnapshotNameTemplateError,
+			struct {
+				Template string
+				Error    error
+			}{Template: nameTemplate, Error: err}))
+	}
+	if strings.Count(name, dateMarker) != 1 || strings.Count(name, timeMarker) != 1 ||
+		strings.ContainsAny(name, "/@") {
+		return nil, errors.New(locale.T(MsgLogSnapshotNameTemplateNotValidError,
+			struct{ Template string }{Template: nameTemplate}))
+	}
+	pattern := regexp.QuoteMeta(name)
+	pattern = strings.Replace(pattern, dateMarker, `(?P<date>\d{8})`, 1)
+	pattern = strings.Replace(pattern, timeMarker, `(?P<time>\d{6})`, 1)
+	v.namePattern = regexp.MustCompile("^" + pattern + "$")
+	return v, nil
+}
+
+// formatName substitute fields to snapshot name template.
+func (v *SnapshotTarget) formatName(dateText, timeText string) (string, error) {
+	var buf bytes.Buffer
+	err := v.template.Execute(&buf, snapshotNameFields{Profile: v.Profile.Name,
+		ProfileID: v.Profile.ID, Date: dateText, Time: timeText})
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// parseName return snapshot creation time, if name
+// was produced by snapshot name template.
+func (v *SnapshotTarget) parseName(name string) (time.Time, bool) {
+	m := v.namePattern.FindStringSubmatch(name)
+	if m == nil {
+		return time.Time{}, false
+	}
+	dt, err := time.ParseInLocation("20060102150405",
+		m[v.namePattern.SubexpIndex("date")]+m[v.namePattern.SubexpIndex("time")], time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return dt, true
+}
+
+// snapshotDriver implement snapshot operations specific to file system.
+type snapshotDriver interface {
+	create(name string) error
+	list() ([]string, error)
+	delete(name string) error
+}
+
+// btrfsDriver create read-only snapshots of btrfs subvolume
+// in BTRFS_SNAPSHOT_FOLDER located in the subvolume root.
+type btrfsDriver struct {
+	subvolume string
+}
+
+func (v *btrfsDriver) folder() string {
+	return filepath.Join(v.subvolume, BTRFS_SNAPSHOT_FOLDER)
+}
+
+func (v *btrfsDriver) create(name string) error {
+	err := createDirAll(v.folder())
+	if err != nil {
+		return err
+	}
+	return runMountCommand(BTRFS_APP_CMD, "subvolume", "snapshot", "-r",
+		v.subvolume, filepath.Join(v.folder(), name))
+}
+
+func (v *btrfsDriver) list() ([]string, error) {
+	items, err := ioutil.ReadDir(v.folder())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var names []string
+	for _, item := range items {
+		if item.IsDir() {
+			names = append(names, item.Name())
+		}
+	}
+	return names, nil
+}
+
+func (v *btrfsDriver) delete(name string) error {
+	return runMountCommand(BTRFS_APP_CMD, "subvolume", "delete",
+		filepath.Join(v.folder(), name))
+}
+
+// zfsDriver create snapshots of ZFS dataset.
+type zfsDriver struct {
+	dataset string
+}
+
+func (v *zfsDriver) create(name string) error {
+	return runMountCommand(ZFS_APP_CMD, "snapshot", v.dataset+"@"+name)
+}
+
+func (v *zfsDriver) list() ([]string, error) {
+	app := shell.NewApp(ZFS_APP_CMD, "list", "-H", "-t", "snapshot", "-o", "name",
+		"-d", "1", v.dataset)
+	var stdOut, stdErr bytes.Buffer
+	ec := app.Run(&stdOut, &stdErr)
+	if ec.Error != nil {
+		return nil, ec.Error
+	}
+	if ec.ExitCode != 0 {
+		return nil, errors.New(locale.T(MsgLogMountCommandFailedError,
+			struct {
+				Command  string
+				ExitCode int
+				Output   string
+			}{Command: ZFS_APP_CMD, ExitCode: ec.ExitCode,
+				Output: strings.TrimSpace(stdErr.String())}))
+	}
+	lines, err := splitToLines(&stdOut)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, line := range lines {
+		if i := strings.Index(line, "@"); i != -1 {
+			names = append(names, line[i+1:])
+		}
+	}
+	return names, nil
+}
+
+func (v *zfsDriver) delete(name string) error {
+	return runMountCommand(ZFS_APP_CMD, "destroy", v.dataset+"@"+name)
+}
+
+// getSnapshotDriver verify that destination located on
+// btrfs subvolume root or ZFS dataset, and return driver
+// to manage its snapshots.
+func getSnapshotDriver(destPath string) (snapshotDriver, error) {
+	fsType, err := GetFileSystemType(destPath)
+	if err != nil {
+		return nil, err
+	}
+	switch fsType {
+	case "btrfs":
+		var stat syscall.Stat_t
+		err = syscall.Stat(destPath, &stat)
+		if err != nil {
+			return nil, err
+		}
+		if stat.Ino != btrfsSubvolumeRootInode {
+			return nil, errors.New(locale.T(MsgLogSnapshotNotBtrfsSubvolumeError,
+				struct{ Path string }{Path: destPath}))
+		}
+		return &btrfsDriver{subvolume: destPath}, shell.NewApp(BTRFS_APP_CMD).CheckIsInstalled()
+	case "zfs":
+		dataset, _, err := findMountEntry(destPath)
+		if err != nil {
+			return nil, err
+		}
+		return &zfsDriver{dataset: dataset}, shell.NewApp(ZFS_APP_CMD).CheckIsInstalled()
+	default:
+		return nil, errors.New(locale.T(MsgLogSnapshotFileSystemNotSupportedError,
+			struct{ Path, FileSystem string }{Path: destPath, FileSystem: fsType}))
+	}
+}
+
+// Apply create snapshot of destination, containing completed backup
+// session folder. In SNAPSHOT_MODE_REPLACE previous session folders are
+// removed from destination then, since they are kept in snapshots.
+// Finally snapshots exceeding retention limit are deleted, oldest first.
+// Only snapshots, which names match name template, are taken into account.
+func (v *SnapshotTarget) Apply(destPath, sessionFolder string, log logger.PackageLog) error {
+	driver, err := getSnapshotDriver(destPath)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	name, err := v.formatName(now.Format("20060102"), now.Format("150405"))
+	if err != nil {
+		return err
+	}
+	log.Info(locale.T(MsgLogSnapshotCreating,
+		struct{ Path, Name string }{Path: destPath, Name: name}))
+	err = driver.create(name)
+	if err != nil {
+		return err
+	}
+
+	if v.Mode == SNAPSHOT_MODE_REPLACE {
+		err = removeSupersededSessions(destPath, sessionFolder, v.Profile, log)
+		if err != nil {
+			return err
+		}
+	}
+
+	if v.Keep > 0 {
+		names, err := driver.list()
+		if err != nil {
+			return err
+		}
+		var snapshots []Snapshot
+		for _, item := range names {
+			if dt, ok := v.parseName(item); ok {
+				snapshots = append(snapshots, Snapshot{Name: item, Time: dt})
+			}
+		}
+		sort.Slice(snapshots, func(i, j int) bool {
+			return snapshots[i].Time.Before(snapshots[j].Time)
+		})
+		for i := 0; i < len(snapshots)-v.Keep; i++ {
+			log.Info(locale.T(MsgLogSnapshotPruning,
+				struct{ Name string }{Name: snapshots[i].Name}))
+			err = driver.delete(snapshots[i].Name)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// removeSupersededSessions delete from destination completed backup
+// session folders (identified by signature file) of the profile, except
+// current one and frozen ones. Sessions of other profiles sharing
+// the same destination are left intact.
+func removeSupersededSessions(destPath, sessionFolder string, profile ProfileIdentity,
+	log logger.PackageLog) error {
+
+	items, err := ioutil.ReadDir(destPath)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if !item.IsDir() || item.Name() == sessionFolder {
+			continue
+		}
+		path := filepath.Join(destPath, item.Name())
+		_, err = os.Stat(filepath.Join(path, GetMetadataSignatureFileName()))
+		if err != nil || !isSessionOwnedBy(path, profile) {
+			continue
+		}
+		if IsSessionFrozen(path) {
+			log.Info(locale.T(MsgLogSnapshotSessionFrozen,
+				struct{ Path string }{Path: path}))
+			continue
+		}
+		log.Info(locale.T(MsgLogSnapshotSessionRemoved,
+			struct{ Path string }{Path: path}))
+		err = os.RemoveAll(path)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
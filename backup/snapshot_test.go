@@ -0,0 +1,87 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package backup
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRemoveSupersededSessions(t *testing.T) {
+	root, err := ioutil.TempDir("", "snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	createTestSessions(t, root, []testSession{
+		{name: "old", profile: "1", size: 10},
+		{name: "frozen", profile: "1", size: 10, frozen: true},
+		{name: "other", profile: "2", size: 10},
+		{name: "current", profile: "1", size: 10},
+	})
+	// Folder without signature file is not a backup session.
+	err = os.Mkdir(filepath.Join(root, "folder"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = removeSupersededSessions(root, "current", ProfileIdentity{ID: "1"}, LocalLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name   string
+		exists bool
+	}{
+		{"old", false},
+		{"frozen", true},
+		{"other", true},
+		{"current", true},
+		{"folder", true},
+	}
+	for _, item := range cases {
+		_, err := os.Stat(filepath.Join(root, item.name))
+		if exists := err == nil; exists != item.exists {
+			t.Errorf("folder %q exists %v, expected %v", item.name, exists, item.exists)
+		}
+	}
+}
+
+func TestSnapshotTargetParseName(t *testing.T) {
+	target, err := NewSnapshotTarget(SNAPSHOT_MODE_APPEND, "", 0, ProfileIdentity{ID: "1", Name: "home"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name  string
+		match bool
+	}{
+		{"gorsync_1_20240102-030405", true},
+		{"gorsync_2_20240102-030405", false},
+		{"gorsync_20240102-030405", false},
+		{"gorsync_1_2024-030405", false},
+	}
+	for _, item := range cases {
+		_, ok := target.parseName(item.name)
+		if ok != item.match {
+			t.Errorf("snapshot %q match %v, expected %v", item.name, ok, item.match)
+		}
+	}
+
+	_, err = NewSnapshotTarget(SNAPSHOT_MODE_APPEND, "backup_{{.Date}}", 0, ProfileIdentity{ID: "1"})
+	if err == nil {
+		t.Error("template without .Time field accepted")
+	}
+}
@@ -0,0 +1,156 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package backup
+
+import (
+	"time"
+
+	logger "github.com/d2r2/go-logger"
+	"github.com/d2r2/go-rsync/core"
+)
+
+const (
+	// Number of recent backup sessions used
+	// to average RSYNC performance metrics.
+	statisticsHistoryDepth = 5
+	// RSYNC call overhead should not exceed
+	// this fraction of backup block processing time.
+	maxCallOverheadRatio = 0.05
+	// Backup block should be processed not longer than this time,
+	// to keep progress and ETA responsive.
+	maxBackupBlockDuration = 10 * time.Minute
+	// Backup block size limits used, when no statistics available.
+	defaultMinBackupBlockSize = 300 * core.MB
+	defaultMaxBackupBlockSize = 5 * core.GB
+)
+
+// ModuleStatistics keep RSYNC performance metrics
+// achieved in backup session for specific source.
+// Saved in backup session signature file to tune
+// backup block size in next sessions.
+type ModuleStatistics struct {
+	// Throughput in bytes per second.
+	Throughput uint64
+	// Time spent by single RSYNC call regardless of data size.
+	CallOverhead time.Duration
+	// Ratio of actual backup size to size predicted in plan stage,
+	// zero if unknown.
+	EstimateRatio float64
+}
+
+// getBackupBlockSizeLimits calculate backup block size low/high limits:
+// low limit keep RSYNC call overhead small enough, high limit keep
+// single RSYNC call short enough. Limits are converted to the size
+// predicted in plan stage, once estimate ratio is known.
+func (v *ModuleStatistics) getBackupBlockSizeLimits() (uint64, uint64) {
+	min := uint64(v.CallOverhead.Seconds() * float64(v.Throughput) / maxCallOverheadRatio)
+	max := uint64(maxBackupBlockDuration.Seconds() * float64(v.Throughput))
+	if v.EstimateRatio > 0 {
+		min = uint64(float64(min) / v.EstimateRatio)
+		max = uint64(float64(max) / v.EstimateRatio)
+	}
+	if min < core.MB {
+		min = core.MB
+	}
+	if max < min {
+		max = min
+	}
+	return min, max
+}
+
+// rsyncCallStatistics accumulate size and duration of RSYNC calls
+// to fit linear model: duration = overhead + size / throughput.
+// Predicted sizes are accumulated as well to reconcile them
+// with actual ones.
+type rsyncCallStatistics struct {
+	count                    int
+	sumX, sumY, sumXX, sumXY float64
+	sizes                    sizeReconciliation
+}
+
+// add register one RSYNC call.
+func (v *rsyncCallStatistics) add(size core.FolderSize, duration time.Duration) {
+	x := float64(size.GetByteCount())
+	y := duration.Seconds()
+	v.count++
+	v.sumX += x
+	v.sumY += y
+	v.sumXX += x * x
+	v.sumXY += x * y
+}
+
+// getModuleStatistics evaluate throughput and RSYNC call overhead
+// with least squares method. Fall back to average throughput without
+// overhead, when data is not enough to build linear model.
+func (v *rsyncCallStatistics) getModuleStatistics() *ModuleStatistics {
+	if v.count == 0 || v.sumX == 0 || v.sumY == 0 {
+		return nil
+	}
+	stats := &ModuleStatistics{Throughput: uint64(v.sumX / v.sumY),
+		EstimateRatio: v.sizes.getEstimateRatio()}
+	n := float64(v.count)
+	denom := n*v.sumXX - v.sumX*v.sumX
+	if denom > 0 {
+		// seconds per byte
+		slope := (n*v.sumXY - v.sumX*v.sumY) / denom
+		overhead := (v.sumY - slope*v.sumX) / n
+		if slope > 0 && overhead >= 0 {
+			stats.Throughput = uint64(1 / slope)
+			stats.CallOverhead = time.Duration(overhead * float64(time.Second))
+		}
+	}
+	return stats
+}
+
+// FindModuleStatistics load RSYNC performance metrics saved
+// in recent backup sessions found in destPath and average them
+// for each source. Result is indexed by source identifier.
+func FindModuleStatistics(lg logger.PackageLog, destPath string,
+	modules []Module) (map[string]ModuleStatistics, error) {
+
+	prevBackups, err := FindPrevBackupPathsInDestRoots(lg, destPath,
+		modules, statisticsHistoryDepth)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int)
+	ratioCounts := make(map[string]int)
+	sums := make(map[string]ModuleStatistics)
+	for _, item := range prevBackups.Backups {
+		stats := item.Signature.Statistics
+		if stats == nil || stats.Throughput == 0 {
+			continue
+		}
+		id := item.SourceID
+		sum := sums[id]
+		sum.Throughput += stats.Throughput
+		sum.CallOverhead += stats.CallOverhead
+		// sessions made by previous versions lack estimate ratio
+		if stats.EstimateRatio > 0 {
+			sum.EstimateRatio += stats.EstimateRatio
+			ratioCounts[id]++
+		}
+		sums[id] = sum
+		counts[id]++
+	}
+	result := make(map[string]ModuleStatistics)
+	for id, sum := range sums {
+		count := counts[id]
+		stats := ModuleStatistics{Throughput: sum.Throughput / uint64(count),
+			CallOverhead: sum.CallOverhead / time.Duration(count)}
+		if ratioCount := ratioCounts[id]; ratioCount > 0 {
+			stats.EstimateRatio = sum.EstimateRatio / float64(ratioCount)
+		}
+		result[id] = stats
+	}
+	return result, nil
+}
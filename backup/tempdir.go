@@ -0,0 +1,80 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package backup
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// PLAN_STAGE_TEMP_DIR_PREFIX is a name prefix of temporary folders
+// created in 1st stage (plan stage) to analyze directory structure.
+const PLAN_STAGE_TEMP_DIR_PREFIX = "backup_dir_tree_"
+
+// planStageTempLocation return folder, where plan stage
+// temporary folders are created.
+func planStageTempLocation(tempPath string) string {
+	if tempPath == "" {
+		return os.TempDir()
+	}
+	return tempPath
+}
+
+// createPlanStageTempDir create new temporary folder in tempPath,
+// or in default system location if tempPath is empty.
+func createPlanStageTempDir(tempPath string) (string, error) {
+	return ioutil.TempDir(planStageTempLocation(tempPath), PLAN_STAGE_TEMP_DIR_PREFIX)
+}
+
+// getPlanStageDryRunPath return unique path in tempPath (or default system
+// location), which is never created: RSYNC dry run doesn't require destination
+// to exist, so folders structure built in memory is measured without
+// touching local file system.
+func getPlanStageDryRunPath(tempPath string) string {
+	return filepath.Join(planStageTempLocation(tempPath),
+		fmt.Sprintf("%s%d_%d", PLAN_STAGE_TEMP_DIR_PREFIX, os.Getpid(), time.Now().UnixNano()))
+}
+
+// RemoveStalePlanStageTempDirs delete temporary folders left by
+// plan stage of aborted (crashed) sessions. Both tempPath and default
+// system location are examined, since location might be changed
+// after crash. Should be called only when no backup session is running.
+func RemoveStalePlanStageTempDirs(tempPath string) error {
+	locations := []string{os.TempDir()}
+	if tempPath != "" && filepath.Clean(tempPath) != filepath.Clean(os.TempDir()) {
+		locations = append(locations, tempPath)
+	}
+	for _, location := range locations {
+		items, err := ioutil.ReadDir(location)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+		for _, item := range items {
+			if item.IsDir() && strings.HasPrefix(item.Name(), PLAN_STAGE_TEMP_DIR_PREFIX) {
+				path := filepath.Join(location, item.Name())
+				LocalLog.Debugf("Remove stale temporary folder %q", path)
+				err = os.RemoveAll(path)
+				if err != nil && !os.IsPermission(err) {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -107,6 +107,42 @@ func GetLogFileName() string {
 	return "~backup_log~.log"
 }
 
+// GetRetryListFileName return the name of specific file, which keeps
+// folders failed to backup in the session, to retry them later.
+func GetRetryListFileName() string {
+	return "~backup_failed~.retry"
+}
+
+// GetCatalogFileName return the name of specific file, which
+// list all files backed up in the session.
+func GetCatalogFileName() string {
+	return "~backup_catalog~.jsonl.gz"
+}
+
+// GetChecksumManifestFileName return the name of specific file, which keeps
+// checksums of files backed up in the session, in "sha256sum" utility format.
+func GetChecksumManifestFileName() string {
+	return "~backup_checksums~.sha256"
+}
+
+// GetFreezeMarkerFileName return the name of specific file, which
+// protect the session from being deleted by pruning or cleanup.
+func GetFreezeMarkerFileName() string {
+	return "~backup_frozen~.freeze"
+}
+
+// GetDestLockFileName return the name of specific file, which
+// prevent concurrent backup sessions to the same destination.
+func GetDestLockFileName() string {
+	return "~backup_session~.lock"
+}
+
+// GetPlanTraceFileName return the name of plan stage trace file,
+// which explain backup type chosen for each folder.
+func GetPlanTraceFileName() string {
+	return "~plan_trace~.log"
+}
+
 // GetRsyncLogFileName return the name of specific low-level RSYNC utility log.
 func GetRsyncLogFileName() string {
 	return "~rsync_log~.log"
@@ -0,0 +1,184 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package backup
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+
+	"github.com/d2r2/go-rsync/locale"
+	"github.com/d2r2/go-rsync/rsync"
+)
+
+// IssueSeverity signify how critical profile configuration issue is.
+type IssueSeverity int
+
+// Profile configuration issue could be:
+// 1) a warning, which does not prevent backup session to start;
+// 2) an error, so backup session can't be started.
+const (
+	IssueWarning IssueSeverity = iota
+	IssueError
+)
+
+// String implement Stringer interface.
+func (v IssueSeverity) String() string {
+	if v == IssueWarning {
+		return "warning"
+	}
+	return "error"
+}
+
+// MarshalText implement encoding.TextMarshaler interface
+// to keep severity human readable in JSON output.
+func (v IssueSeverity) MarshalText() ([]byte, error) {
+	return []byte(v.String()), nil
+}
+
+// Identifiers of profile configuration issues,
+// used in machine-readable validation results.
+const (
+	ISSUE_DEST_IS_EMPTY       = "destination_is_empty"
+	ISSUE_DEST_NOT_EXIST      = "destination_not_exist"
+	ISSUE_DEST_NOT_ACCESSIBLE = "destination_not_accessible"
+	ISSUE_MODULES_ARE_EMPTY   = "modules_are_empty"
+	ISSUE_SOURCE_IS_EMPTY     = "source_is_empty"
+	// Age limits can't be applied to remote source.
+	ISSUE_AGE_LIMITS_NOT_APPLICABLE = "age_limits_not_applicable"
+)
+
+// PROFILE_ISSUE is a module index of issue,
+// which relate to whole profile rather than specific module.
+const PROFILE_ISSUE = -1
+
+// ValidationIssue describe single problem found in backup profile.
+type ValidationIssue struct {
+	Code     string        `json:"code"`
+	Severity IssueSeverity `json:"severity"`
+	// Index of module in profile, or PROFILE_ISSUE.
+	ModuleIndex int `json:"module"`
+	// Message identifier from "locale" package,
+	// issue itself is used as a template data.
+	MessageKey string `json:"message_key"`
+	Path       string `json:"path,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Message return localized issue description.
+func (v ValidationIssue) Message() string {
+	return locale.T(v.MessageKey, v)
+}
+
+// MarshalJSON implement json.Marshaler interface
+// to add localized description to machine-readable output.
+func (v ValidationIssue) MarshalJSON() ([]byte, error) {
+	type issue ValidationIssue
+	return json.Marshal(struct {
+		issue
+		Message string `json:"message"`
+	}{issue: issue(v), Message: v.Message()})
+}
+
+// ValidationIssues is a list of problems found in backup profile.
+type ValidationIssues []ValidationIssue
+
+// HasErrors return true, if any issue prevent backup session to start.
+func (v ValidationIssues) HasErrors() bool {
+	return v.FirstError() != nil
+}
+
+// FirstError return first issue with error severity, or nil.
+func (v ValidationIssues) FirstError() *ValidationIssue {
+	for i := range v {
+		if v[i].Severity == IssueError {
+			return &v[i]
+		}
+	}
+	return nil
+}
+
+// Err convert first issue with error severity to error, if any found.
+func (v ValidationIssues) Err() error {
+	if issue := v.FirstError(); issue != nil {
+		return errors.New(issue.Message())
+	}
+	return nil
+}
+
+// ValidateProfile verify backup profile configuration: destination path
+// and modules list. If checkPaths is true, verify that profile and module
+// destination paths are reachable either, otherwise only
+// configuration itself is verified.
+func ValidateProfile(destPath string, modules []Module, checkPaths bool) ValidationIssues {
+	var issues ValidationIssues
+	if destPath == "" {
+		issues = append(issues, ValidationIssue{Code: ISSUE_DEST_IS_EMPTY, Severity: IssueError,
+			ModuleIndex: PROFILE_ISSUE, MessageKey: MsgValidationDestPathIsEmpty})
+	} else if checkPaths {
+		issues = append(issues, validateDestPath(destPath, PROFILE_ISSUE)...)
+	}
+	if len(modules) == 0 {
+		issues = append(issues, ValidationIssue{Code: ISSUE_MODULES_ARE_EMPTY, Severity: IssueError,
+			ModuleIndex: PROFILE_ISSUE, MessageKey: MsgValidationModulesAreEmpty})
+	}
+	issues = append(issues, ValidateModules(modules, checkPaths)...)
+	return issues
+}
+
+// ValidateModules verify each module configuration. If checkPaths is true,
+// verify that module own destination root path is reachable either.
+func ValidateModules(modules []Module, checkPaths bool) ValidationIssues {
+	var issues ValidationIssues
+	for i, module := range modules {
+		if module.SourceRsync == "" {
+			issues = append(issues, ValidationIssue{Code: ISSUE_SOURCE_IS_EMPTY, Severity: IssueError,
+				ModuleIndex: i, MessageKey: MsgValidationSourceIsEmpty})
+		} else if module.GetFileFilter().HasAgeLimits() && !rsync.IsLocalSource(module.SourceRsync) {
+			// Remote source is not scanned to build age exclude list,
+			// so backup would silently transfer all files.
+			issues = append(issues, ValidationIssue{Code: ISSUE_AGE_LIMITS_NOT_APPLICABLE,
+				Severity: IssueError, ModuleIndex: i,
+				MessageKey: MsgValidationAgeLimitsNotApplicable, Path: module.SourceRsync})
+		}
+		if checkPaths && module.DestRootPath != "" {
+			issues = append(issues, validateDestPath(module.DestRootPath, i)...)
+		}
+	}
+	return issues
+}
+
+// ValidateDestPath verify that destination path
+// is specified and reachable.
+func ValidateDestPath(destPath string) ValidationIssues {
+	if destPath == "" {
+		return ValidationIssues{{Code: ISSUE_DEST_IS_EMPTY, Severity: IssueError,
+			ModuleIndex: PROFILE_ISSUE, MessageKey: MsgValidationDestPathIsEmpty}}
+	}
+	return validateDestPath(destPath, PROFILE_ISSUE)
+}
+
+func validateDestPath(destPath string, moduleIndex int) ValidationIssues {
+	_, err := os.Stat(destPath)
+	if err == nil {
+		return nil
+	}
+	issue := ValidationIssue{Code: ISSUE_DEST_NOT_ACCESSIBLE, Severity: IssueError,
+		ModuleIndex: moduleIndex, MessageKey: MsgValidationDestPathIsNotAccessible,
+		Path: destPath, Error: err.Error()}
+	if os.IsNotExist(err) {
+		issue.Code = ISSUE_DEST_NOT_EXIST
+		issue.MessageKey = MsgValidationDestPathIsNotExist
+		issue.Error = ""
+	}
+	return ValidationIssues{issue}
+}
@@ -0,0 +1,35 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package backup
+
+import "testing"
+
+func TestValidateModulesAgeLimits(t *testing.T) {
+	tests := []struct {
+		module Module
+		code   string
+	}{
+		{Module{SourceRsync: "/home/user/", MaxFileAgeDays: 30}, ""},
+		{Module{SourceRsync: "rsync://nas/data/", MaxFileAgeDays: 30}, ISSUE_AGE_LIMITS_NOT_APPLICABLE},
+		{Module{SourceRsync: "server:/srv/www/", MinFileAgeDays: 1}, ISSUE_AGE_LIMITS_NOT_APPLICABLE},
+		{Module{SourceRsync: "rsync://nas/data/"}, ""},
+	}
+	for _, test := range tests {
+		var code string
+		if issue := ValidateModules([]Module{test.module}, false).FirstError(); issue != nil {
+			code = issue.Code
+		}
+		if code != test.code {
+			t.Errorf("%q: expected issue %q, got %q", test.module.SourceRsync, test.code, code)
+		}
+	}
+}
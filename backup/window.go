@@ -0,0 +1,134 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package backup
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/d2r2/go-rsync/locale"
+)
+
+// Time of day layout used to specify backup window bounds.
+const BACKUP_WINDOW_TIME_LAYOUT = "15:04"
+
+// BackupWindow describe conditions, which should be met
+// to let backup session start: time of day range,
+// AC power and unmetered network connection.
+type BackupWindow struct {
+	// Time of day range in "HH:MM" format. Range might
+	// cross midnight, for instance "22:00"-"06:00".
+	// Equal bounds mean whole day.
+	Start string
+	End   string
+	// Backup allowed only when computer is plugged in.
+	RequireACPower bool
+	// Backup allowed only when network connection is not metered.
+	RequireUnmetered bool
+	start            time.Duration
+	end              time.Duration
+}
+
+// parseTimeOfDay convert "HH:MM" to duration passed since midnight.
+func parseTimeOfDay(str string) (time.Duration, error) {
+	t, err := time.Parse(BACKUP_WINDOW_TIME_LAYOUT, strings.TrimSpace(str))
+	if err != nil {
+		return 0, errors.New(locale.T(MsgBackupWindowTimeFormatError,
+			struct{ Time string }{Time: str}))
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// NewBackupWindow verify time range bounds and create BackupWindow object.
+func NewBackupWindow(start, end string, requireACPower, requireUnmetered bool) (*BackupWindow, error) {
+	v := &BackupWindow{Start: strings.TrimSpace(start), End: strings.TrimSpace(end),
+		RequireACPower: requireACPower, RequireUnmetered: requireUnmetered}
+	var err error
+	v.start, err = parseTimeOfDay(start)
+	if err != nil {
+		return nil, err
+	}
+	v.end, err = parseTimeOfDay(end)
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// InTimeRange returns true, if time of day fall in backup window.
+func (v *BackupWindow) InTimeRange(now time.Time) bool {
+	if v.start == v.end {
+		return true
+	}
+	t := time.Duration(now.Hour())*time.Hour + time.Duration(now.Minute())*time.Minute
+	if v.start < v.end {
+		return t >= v.start && t < v.end
+	}
+	// Range cross midnight.
+	return t >= v.start || t < v.end
+}
+
+// Check verify all backup window conditions and return
+// localized list of violated ones. Empty list means
+// that backup is allowed to run. Network metered state
+// is provided by caller, since it's obtained from desktop
+// environment.
+func (v *BackupWindow) Check(now time.Time, metered bool) []string {
+	var reasons []string
+	if !v.InTimeRange(now) {
+		reasons = append(reasons, locale.T(MsgBackupWindowOutOfTimeRange,
+			struct{ Start, End string }{Start: v.Start, End: v.End}))
+	}
+	if v.RequireACPower {
+		ac, err := IsOnACPower()
+		if err != nil {
+			LocalLog.Warn(err)
+		} else if !ac {
+			reasons = append(reasons, locale.T(MsgBackupWindowOnBatteryPower, nil))
+		}
+	}
+	if v.RequireUnmetered && metered {
+		reasons = append(reasons, locale.T(MsgBackupWindowMeteredNetwork, nil))
+	}
+	return reasons
+}
+
+// IsOnACPower look through /sys/class/power_supply to find out
+// whether computer is powered from the mains. Systems without
+// any mains power supply reported (desktops) treated as plugged in.
+func IsOnACPower() (bool, error) {
+	const powerSupplyPath = "/sys/class/power_supply"
+	items, err := ioutil.ReadDir(powerSupplyPath)
+	if err != nil {
+		return false, err
+	}
+	var mainsFound bool
+	for _, item := range items {
+		path := filepath.Join(powerSupplyPath, item.Name())
+		buf, err := ioutil.ReadFile(filepath.Join(path, "type"))
+		if err != nil || strings.TrimSpace(string(buf)) != "Mains" {
+			continue
+		}
+		mainsFound = true
+		buf, err = ioutil.ReadFile(filepath.Join(path, "online"))
+		if err != nil {
+			return false, err
+		}
+		if strings.TrimSpace(string(buf)) == "1" {
+			return true, nil
+		}
+	}
+	return !mainsFound, nil
+}
@@ -15,6 +15,10 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
 )
 
 // DirMetrics keeps metrics defined in 1st pass of folders tree.
@@ -39,20 +43,105 @@ type DirMetrics struct {
 	// Type of backup for current folder defined
 	// as a result of traverse path search.
 	BackupType FolderBackupType
+	// Folder modification time (Unix time) reported by the source,
+	// 0 if unknown. Used to find folders changed since previous session.
+	ModTime int64
 }
 
 // Dir is a "tree data structure" to describe folder's tree
 // received from the source in 1st pass of backup process to measure
 // counts/sizes and to predict time necessary for backup process (ETA).
 // https://en.wikipedia.org/wiki/Tree_%28data_structure%29
+//
+// Source could contain millions of folders, so node is kept compact:
+// only root keep full paths, while node paths are built from names
+// on request; names are shared between nodes. On-disk spillover of
+// nodes is deferred: whole tree is kept in memory, which takes about
+// 110 bytes per folder (see BenchmarkBuildDirTreeFromList).
 type Dir struct {
-	Paths   SrcDstPath
+	// Defined for root only, use GetPaths() instead.
+	paths   *SrcDstPath
 	Name    string
 	Parent  *Dir
 	Childs  []*Dir
 	Metrics DirMetrics
 }
 
+// GetPaths return source and destination paths of the folder.
+func (v *Dir) GetPaths() SrcDstPath {
+	if v.paths != nil {
+		return *v.paths
+	}
+	var names []string
+	dir := v
+	for ; dir.paths == nil; dir = dir.Parent {
+		names = append(names, dir.Name)
+	}
+	paths := *dir.paths
+	for i := len(names) - 1; i >= 0; i-- {
+		paths = paths.Join(names[i])
+	}
+	return paths
+}
+
+// dirTreeBuilder allocate folder nodes in blocks and share equal names,
+// to reduce memory consumed by huge folder trees.
+type dirTreeBuilder struct {
+	names map[string]string
+	block []Dir
+}
+
+// Number of nodes allocated at once.
+const dirTreeBlockSize = 1024
+
+func newDirTreeBuilder() *dirTreeBuilder {
+	return &dirTreeBuilder{names: make(map[string]string)}
+}
+
+// newDir create child node of parent folder.
+func (v *dirTreeBuilder) newDir(parent *Dir, name string) *Dir {
+	if len(v.block) == 0 {
+		v.block = make([]Dir, dirTreeBlockSize)
+	}
+	dir := &v.block[0]
+	v.block = v.block[1:]
+	dir.Parent = parent
+	dir.Name = v.intern(name)
+	dir.Metrics.Depth = parent.Metrics.Depth + 1
+	return dir
+}
+
+// intern return shared copy of name. Copy is made to not
+// keep in memory larger string, which name is cut from.
+func (v *dirTreeBuilder) intern(name string) string {
+	if item, ok := v.names[name]; ok {
+		return item
+	}
+	item := string([]byte(name))
+	v.names[item] = item
+	return item
+}
+
+// packChilds move child lists of all nodes to single
+// slice of exact size, to drop extra capacity left by append.
+func packChilds(root *Dir) {
+	all := make([]*Dir, 0, getFoldersCount(root))
+	var pack func(dir *Dir)
+	pack = func(dir *Dir) {
+		if len(dir.Childs) == 0 {
+			dir.Childs = nil
+			return
+		}
+		start := len(all)
+		all = append(all, dir.Childs...)
+		dir.Childs = all[start:len(all):len(all)]
+		for _, item := range dir.Childs {
+			pack(item)
+		}
+	}
+	pack(root)
+}
+
 // BuildDirTree scans and creates Dir object which reflects
 // real recursive directory structure defined by file system path
 // in paths argument.
@@ -65,14 +154,122 @@ func BuildDirTree(paths SrcDstPath, ignoreBackupFileSigName string) (*Dir, error
 		// does not translate this message, since it is very unlikely
 		return nil, fmt.Errorf("path %q should be a folder", paths.DestPath)
 	}
-	root := &Dir{Name: info.Name(), Paths: paths, Metrics: DirMetrics{Depth: 0}}
-	_, err = createOffsprings(root, paths, ignoreBackupFileSigName, 1)
+	root := &Dir{Name: info.Name(), paths: &paths,
+		Metrics: DirMetrics{Depth: 0, ModTime: info.ModTime().Unix()}}
+	_, err = createOffsprings(newDirTreeBuilder(), root, paths, ignoreBackupFileSigName)
 	if err != nil {
 		return nil, err
 	}
+	packChilds(root)
 	return root, nil
 }
 
+// DirTreeEntry describe single item of RSYNC source
+// listing, with path relative to the source root.
+type DirTreeEntry struct {
+	Path    string
+	IsDir   bool
+	ModTime time.Time
+	// File size in bytes, 0 if not recognized.
+	Size int64
+}
+
+// BuildDirTreeFromList creates Dir object from folders (and
+// "skip backup" signature files) listed by RSYNC, so directory
+// structure is built in memory without temporary files involved.
+func BuildDirTreeFromList(paths SrcDstPath, name string, entries []DirTreeEntry,
+	ignoreBackupFileSigName string) *Dir {
+
+	root := &Dir{Name: name, paths: &paths, Metrics: DirMetrics{Depth: 0}}
+	builder := newDirTreeBuilder()
+	// Index folders by parent and name, rather than by full
+	// relative path, which takes much more memory.
+	dirs := make(map[listedDirKey]*Dir)
+	for _, item := range entries {
+		// root folder listed as "."
+		itemPath := strings.Trim(path.Clean("/"+item.Path), "/")
+		if item.IsDir {
+			dir := getOrCreateListedDir(builder, dirs, root, itemPath)
+			if !item.ModTime.IsZero() {
+				dir.Metrics.ModTime = item.ModTime.Unix()
+			}
+		} else if path.Base(itemPath) == ignoreBackupFileSigName {
+			dir := getOrCreateListedDir(builder, dirs, root, parentListedPath(itemPath))
+			dir.Metrics.IgnoreToBackup = true
+		}
+	}
+	countListedOffsprings(root)
+	packChilds(root)
+	return root
+}
+
+func parentListedPath(itemPath string) string {
+	parent := path.Dir(itemPath)
+	if parent == "." {
+		return ""
+	}
+	return parent
+}
+
+// listedDirKey identify folder found in RSYNC listing.
+type listedDirKey struct {
+	parent *Dir
+	name   string
+}
+
+// getOrCreateListedDir find folder by relative path or create
+// it together with all missing parents.
+func getOrCreateListedDir(builder *dirTreeBuilder, dirs map[listedDirKey]*Dir,
+	root *Dir, itemPath string) *Dir {
+
+	dir := root
+	if itemPath == "" {
+		return dir
+	}
+	for _, name := range strings.Split(itemPath, "/") {
+		key := listedDirKey{parent: dir, name: name}
+		child, ok := dirs[key]
+		if !ok {
+			child = builder.newDir(dir, name)
+			dir.Childs = append(dir.Childs, child)
+			key.name = child.Name
+			dirs[key] = child
+		}
+		dir = child
+	}
+	return dir
+}
+
+// countListedOffsprings order child folders by name and calculate
+// "children count" metric, the same way as createOffsprings do.
+func countListedOffsprings(parent *Dir) int {
+	if parent.Metrics.IgnoreToBackup {
+		parent.Childs = nil
+		parent.Metrics.ChildrenCount = 1
+		return 1
+	}
+	sort.Slice(parent.Childs, func(i, j int) bool {
+		return parent.Childs[i].Name < parent.Childs[j].Name
+	})
+	totalCount := 1
+	for _, item := range parent.Childs {
+		totalCount += countListedOffsprings(item)
+	}
+	parent.Metrics.ChildrenCount = totalCount
+	return totalCount
+}
+
+// NewRecursiveDir creates root Dir object with unknown
+// sizes, which backed up with single recursive RSYNC call
+// without 1st pass measurements.
+func NewRecursiveDir(paths SrcDstPath, name string) *Dir {
+	var size, fullSize FolderSize
+	root := &Dir{Name: name, paths: &paths,
+		Metrics: DirMetrics{Depth: 0, Size: &size, FullSize: &fullSize,
+			Measured: true, BackupType: FBT_RECURSIVE}}
+	return root
+}
+
 // GetTotalSize calculates total size of data
 // to backup, including all subfolders.
 func (v *Dir) GetTotalSize() FolderSize {
@@ -208,8 +405,8 @@ func getFoldersCount(dir *Dir) int {
 	return count
 }
 
-func createOffsprings(parent *Dir, paths SrcDstPath,
-	sigFileIgnoreBackup string, depth int) (int, error) {
+func createOffsprings(builder *dirTreeBuilder, parent *Dir, paths SrcDstPath,
+	sigFileIgnoreBackup string) (int, error) {
 
 	// lg.Debug(f("Iterate path: %q", path))
 	items, err := ioutil.ReadDir(paths.DestPath)
@@ -225,11 +422,10 @@ func createOffsprings(parent *Dir, paths SrcDstPath,
 	for _, item := range items {
 		if item.IsDir() {
 			name := item.Name()
-			paths2 := paths.Join(name)
-			dir := &Dir{Parent: parent, Name: name, Paths: paths2,
-				Metrics: DirMetrics{Depth: depth}}
-			count, err := createOffsprings(dir, paths2,
-				sigFileIgnoreBackup, depth+1)
+			dir := builder.newDir(parent, name)
+			dir.Metrics.ModTime = item.ModTime().Unix()
+			count, err := createOffsprings(builder, dir, paths.Join(name),
+				sigFileIgnoreBackup)
 			if err != nil {
 				return 0, err
 			}
@@ -0,0 +1,137 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package core
+
+import (
+	"fmt"
+	"path"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// syntheticDirTreeList return RSYNC listing of folder tree, where each
+// folder contain fanout child folders up to specified depth, and
+// every 10th folder of the last level contain "skip backup" signature.
+func syntheticDirTreeList(fanout, depth int) []DirTreeEntry {
+	mtime := time.Now()
+	entries := []DirTreeEntry{{Path: ".", IsDir: true, ModTime: mtime}}
+	var add func(parent string, level int)
+	add = func(parent string, level int) {
+		for i := 0; i < fanout; i++ {
+			item := path.Join(parent, fmt.Sprintf("folder-%02d", i))
+			entries = append(entries, DirTreeEntry{Path: item, IsDir: true, ModTime: mtime})
+			if level < depth {
+				add(item, level+1)
+			} else if i%10 == 0 {
+				entries = append(entries, DirTreeEntry{Path: path.Join(item, ".backupignore")})
+			}
+		}
+	}
+	add("", 1)
+	return entries
+}
+
+func TestBuildDirTreeFromList(t *testing.T) {
+	paths := SrcDstPath{RsyncSourcePath: "rsync://nas/data", DestPath: "/backup/data"}
+	root := BuildDirTreeFromList(paths, "data", syntheticDirTreeList(10, 3), ".backupignore")
+	if count := root.GetFoldersCount(); count != 1110 {
+		t.Errorf("expected 1110 nested folders, got %d", count)
+	}
+	var countIgnored func(dir *Dir) int
+	countIgnored = func(dir *Dir) int {
+		count := 0
+		if dir.Metrics.IgnoreToBackup {
+			count++
+		}
+		for _, item := range dir.Childs {
+			count += countIgnored(item)
+		}
+		return count
+	}
+	if count := countIgnored(root); count != 100 {
+		t.Errorf("expected 100 folders to skip, got %d", count)
+	}
+	if root.Metrics.ChildrenCount != 1111 {
+		t.Errorf("expected children count 1111, got %d", root.Metrics.ChildrenCount)
+	}
+	leaf := root.Childs[9].Childs[8].Childs[7]
+	expected := SrcDstPath{RsyncSourcePath: "rsync://nas/data/folder-09/folder-08/folder-07/",
+		DestPath: "/backup/data/folder-09/folder-08/folder-07"}
+	if leaf.GetPaths() != expected || leaf.Metrics.Depth != 3 {
+		t.Errorf("expected %+v at depth 3, got %+v at depth %d", expected,
+			leaf.GetPaths(), leaf.Metrics.Depth)
+	}
+	// Names are shared between nodes.
+	if root.Childs[0].Name != root.Childs[1].Childs[0].Name {
+		t.Error("folder names differ")
+	}
+}
+
+// Synthetic tree of 10^5 leaf folders, 111111 folders in total.
+const (
+	benchmarkTreeFanout = 10
+	benchmarkTreeDepth  = 5
+)
+
+func BenchmarkBuildDirTreeFromList(b *testing.B) {
+	paths := SrcDstPath{RsyncSourcePath: "rsync://nas/data", DestPath: "/backup/data"}
+	entries := syntheticDirTreeList(benchmarkTreeFanout, benchmarkTreeDepth)
+	b.ReportAllocs()
+	b.ResetTimer()
+	var heap uint64
+	for i := 0; i < b.N; i++ {
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		root := BuildDirTreeFromList(paths, "data", entries, ".backupignore")
+		runtime.GC()
+		runtime.ReadMemStats(&after)
+		heap += after.HeapAlloc - before.HeapAlloc
+		runtime.KeepAlive(root)
+	}
+	// Memory retained by the tree, which is what matters for huge sources.
+	b.ReportMetric(float64(heap)/float64(b.N)/float64(len(entries)), "heap-B/folder")
+}
+
+func BenchmarkWalkDirTree(b *testing.B) {
+	paths := SrcDstPath{RsyncSourcePath: "rsync://nas/data", DestPath: "/backup/data"}
+	root := BuildDirTreeFromList(paths, "data",
+		syntheticDirTreeList(benchmarkTreeFanout, benchmarkTreeDepth), ".backupignore")
+	size := FolderSize(1024)
+	var measure func(dir *Dir)
+	measure = func(dir *Dir) {
+		dir.Metrics.Size = &size
+		dir.Metrics.FullSize = &size
+		for _, item := range dir.Childs {
+			measure(item)
+		}
+	}
+	measure(root)
+	// Paths of leaf folders are built, when RSYNC is called.
+	var walk func(dir *Dir)
+	walk = func(dir *Dir) {
+		if len(dir.Childs) == 0 {
+			dir.GetPaths()
+		}
+		for _, item := range dir.Childs {
+			walk(item)
+		}
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		walk(root)
+		root.GetTotalSize()
+		root.GetFoldersCount()
+	}
+}
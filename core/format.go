@@ -13,7 +13,7 @@ package core
 
 import (
 	"bytes"
-	"math"
+	"sync/atomic"
 	"time"
 
 	"github.com/d2r2/go-rsync/locale"
@@ -37,9 +37,9 @@ func FormatDurationToDaysHoursMinsSecs(dur time.Duration, short bool, sections *
 			a = int(Round(days))
 		}
 		if short {
-			buf.WriteString(f("%d %s", a, locale.TP(MsgDaysShort, nil, a)))
+			buf.WriteString(f("%s %s", locale.N(float64(a), 0), locale.TP(MsgDaysShort, nil, a)))
 		} else {
-			buf.WriteString(f("%d %s", a, locale.TP(MsgDaysLong, nil, a)))
+			buf.WriteString(f("%s %s", locale.N(float64(a), 0), locale.TP(MsgDaysLong, nil, a)))
 		}
 	}
 	hours := totalHrs - float64(int(days)*24)
@@ -55,9 +55,9 @@ func FormatDurationToDaysHoursMinsSecs(dur time.Duration, short bool, sections *
 			a = int(Round(hours))
 		}
 		if short {
-			buf.WriteString(f("%d %s", a, locale.TP(MsgHoursShort, nil, a)))
+			buf.WriteString(f("%s %s", locale.N(float64(a), 0), locale.TP(MsgHoursShort, nil, a)))
 		} else {
-			buf.WriteString(f("%d %s", a, locale.TP(MsgHoursLong, nil, a)))
+			buf.WriteString(f("%s %s", locale.N(float64(a), 0), locale.TP(MsgHoursLong, nil, a)))
 		}
 	}
 	var totalSecsLeft float64 = (dur - time.Duration(days)*24*time.Hour -
@@ -75,9 +75,9 @@ func FormatDurationToDaysHoursMinsSecs(dur time.Duration, short bool, sections *
 			a = int(Round(minutes))
 		}
 		if short {
-			buf.WriteString(f("%d %s", a, locale.TP(MsgMinutesShort, nil, a)))
+			buf.WriteString(f("%s %s", locale.N(float64(a), 0), locale.TP(MsgMinutesShort, nil, a)))
 		} else {
-			buf.WriteString(f("%d %s", a, locale.TP(MsgMinutesLong, nil, a)))
+			buf.WriteString(f("%s %s", locale.N(float64(a), 0), locale.TP(MsgMinutesLong, nil, a)))
 		}
 	}
 	seconds := int(totalSecsLeft - float64(int(minutes)*60))
@@ -86,28 +86,14 @@ func FormatDurationToDaysHoursMinsSecs(dur time.Duration, short bool, sections *
 			buf.WriteString(" ")
 		}
 		if short {
-			buf.WriteString(f("%d %s", seconds, locale.TP(MsgSecondsShort, nil, seconds)))
+			buf.WriteString(f("%s %s", locale.N(float64(seconds), 0), locale.TP(MsgSecondsShort, nil, seconds)))
 		} else {
-			buf.WriteString(f("%d %s", seconds, locale.TP(MsgSecondsLong, nil, seconds)))
+			buf.WriteString(f("%s %s", locale.N(float64(seconds), 0), locale.TP(MsgSecondsLong, nil, seconds)))
 		}
 	}
 	return buf.String()
 }
 
-// pluralFloatToInt is doing some workaround how to interpret
-// float amounts in context of plural forms.
-func pluralFloatToInt(val float64) int {
-	if val == 1 {
-		return 1
-	} else if val < 1 {
-		return 0
-	} else if val < 2 {
-		return 2
-	} else {
-		return int(Round(math.Floor(val)))
-	}
-}
-
 // byte count in corresponding data measurements
 const (
 	KB = 1000
@@ -118,72 +104,122 @@ const (
 	EB = 1000 * PB
 )
 
+// byte count in corresponding binary data measurements
+const (
+	KiB = 1024
+	MiB = 1024 * KiB
+	GiB = 1024 * MiB
+	TiB = 1024 * GiB
+	PiB = 1024 * TiB
+	EiB = 1024 * PiB
+)
+
+// SizeUnits define convention used to display data sizes.
+type SizeUnits int32
+
+const (
+	// SIZE_UNITS_SI print sizes in decimal units (kB, MB, GB, ...),
+	// which is default.
+	SIZE_UNITS_SI SizeUnits = iota
+	// SIZE_UNITS_BINARY print sizes in binary units (KiB, MiB, GiB, ...),
+	// the same way as df and du utilities do.
+	SIZE_UNITS_BINARY
+)
+
+// String implement Stringer interface.
+func (v SizeUnits) String() string {
+	if v == SIZE_UNITS_BINARY {
+		return "binary"
+	}
+	return "si"
+}
+
+// ParseSizeUnits convert text representation to SizeUnits.
+// Unknown values fall back to SIZE_UNITS_SI.
+func ParseSizeUnits(units string) SizeUnits {
+	if units == SIZE_UNITS_BINARY.String() {
+		return SIZE_UNITS_BINARY
+	}
+	return SIZE_UNITS_SI
+}
+
+var sizeUnits int32 = int32(SIZE_UNITS_SI)
+
+// SetSizeUnits change convention used by FormatSize
+// and GetReadableSize application-wide.
+func SetSizeUnits(units SizeUnits) {
+	atomic.StoreInt32(&sizeUnits, int32(units))
+}
+
+// GetSizeUnits return convention currently used to display data sizes.
+func GetSizeUnits() SizeUnits {
+	return SizeUnits(atomic.LoadInt32(&sizeUnits))
+}
+
+// sizeUnit describe data measurement and its localized names.
+type sizeUnit struct {
+	multiplier     uint64
+	fractionDigits int
+	shortMessageID string
+	longMessageID  string
+}
+
+var siSizeUnits = []sizeUnit{
+	{EB, 2, MsgExaBytesShort, MsgExaBytesLong},
+	{PB, 2, MsgPetaBytesShort, MsgPetaBytesLong},
+	{TB, 2, MsgTeraBytesShort, MsgTeraBytesLong},
+	{GB, 1, MsgGigaBytesShort, MsgGigaBytesLong},
+	{MB, 0, MsgMegaBytesShort, MsgMegaBytesLong},
+	{KB, 0, MsgKiloBytesShort, MsgKiloBytesLong},
+}
+
+var binarySizeUnits = []sizeUnit{
+	{EiB, 2, MsgExbiBytesShort, MsgExbiBytesLong},
+	{PiB, 2, MsgPebiBytesShort, MsgPebiBytesLong},
+	{TiB, 2, MsgTebiBytesShort, MsgTebiBytesLong},
+	{GiB, 1, MsgGibiBytesShort, MsgGibiBytesLong},
+	{MiB, 0, MsgMebiBytesShort, MsgMebiBytesLong},
+	{KiB, 0, MsgKibiBytesShort, MsgKibiBytesLong},
+}
+
 // FormatSize convert byte count amount to human-readable (short) string representation.
+// Number is formatted according to application language conventions,
+// measurement units are selected according to SetSizeUnits preference.
 func FormatSize(byteCount uint64, short bool) string {
-	if byteCount > EB {
-		a := float64(byteCount) / EB
-		if short {
-			return f("%v %s", a,
-				locale.TP(MsgExaBytesShort, nil, pluralFloatToInt(a)))
-		} else {
-			return f("%v %s", a,
-				locale.TP(MsgExaBytesLong, nil, pluralFloatToInt(a)))
-		}
-	} else if byteCount > PB {
-		a := float64(byteCount) / PB
-		if short {
-			return f("%v %s", a,
-				locale.TP(MsgPetaBytesShort, nil, pluralFloatToInt(a)))
-		} else {
-			return f("%v %s", a,
-				locale.TP(MsgPetaBytesLong, nil, pluralFloatToInt(a)))
-		}
-	} else if byteCount > TB {
-		a := float64(byteCount) / TB
-		if short {
-			return f("%v %s", a,
-				locale.TP(MsgTeraBytesShort, nil, pluralFloatToInt(a)))
-		} else {
-			return f("%v %s", a,
-				locale.TP(MsgTeraBytesLong, nil, pluralFloatToInt(a)))
-		}
-	} else if byteCount > GB {
-		a := float64(byteCount) / GB
-		if short {
-			return f("%.1f %s", a,
-				locale.TP(MsgGigaBytesShort, nil, pluralFloatToInt(a)))
-		} else {
-			return f("%.1f %s", a,
-				locale.TP(MsgGigaBytesLong, nil, pluralFloatToInt(a)))
-		}
-	} else if byteCount > MB {
-		a := int(Round(float64(byteCount) / MB))
-		if short {
-			return f("%v %s", a,
-				locale.TP(MsgMegaBytesShort, nil, a))
-		} else {
-			return f("%v %s", a,
-				locale.TP(MsgMegaBytesLong, nil, a))
-		}
-	} else if byteCount > KB {
-		a := int(Round(float64(byteCount) / KB))
-		if short {
-			return f("%v %s", a,
-				locale.TP(MsgKiloBytesShort, nil, a))
-		} else {
-			return f("%v %s", a,
-				locale.TP(MsgKiloBytesLong, nil, a))
+	units := siSizeUnits
+	if GetSizeUnits() == SIZE_UNITS_BINARY {
+		units = binarySizeUnits
+	}
+	for _, unit := range units {
+		if byteCount > unit.multiplier {
+			return formatSizeUnit(float64(byteCount)/float64(unit.multiplier),
+				unit.fractionDigits, short, unit.shortMessageID, unit.longMessageID)
 		}
+	}
+	return formatSizeUnit(float64(byteCount), 0, short,
+		MsgBytesShort, MsgBytesLong)
+}
+
+// formatSizeUnit print amount rounded to fractionDigits followed
+// by localized measurement unit in corresponding plural form.
+func formatSizeUnit(amount float64, fractionDigits int, short bool,
+	shortMessageID, longMessageID string) string {
+
+	// Fractional amounts are passed as formatted numbers to
+	// select plural form according to CLDR rules.
+	var pluralCount interface{}
+	if fractionDigits > 0 {
+		pluralCount = f("%.*f", fractionDigits, amount)
 	} else {
-		a := int(byteCount)
-		if short {
-			return f("%v %s", a,
-				locale.TP(MsgBytesShort, nil, a))
-		} else {
-			return f("%v %s", a,
-				locale.TP(MsgBytesLong, nil, a))
-		}
+		amount = Round(amount)
+		pluralCount = int(amount)
+	}
+	messageID := longMessageID
+	if short {
+		messageID = shortMessageID
 	}
+	return f("%s %s", locale.N(amount, fractionDigits),
+		locale.TP(messageID, nil, pluralCount))
 }
 
 // GetReadableSize convert FolderSize to human readable string representation.
@@ -45,4 +45,20 @@ const (
 	MsgPetaBytesShort = "PetaBytesShort"
 	MsgExaBytesLong   = "ExaBytesLong"
 	MsgExaBytesShort  = "ExaBytesShort"
+	MsgKibiBytesLong  = "KibiBytesLong"
+	MsgKibiBytesShort = "KibiBytesShort"
+	MsgMebiBytesLong  = "MebiBytesLong"
+	MsgMebiBytesShort = "MebiBytesShort"
+	MsgGibiBytesLong  = "GibiBytesLong"
+	MsgGibiBytesShort = "GibiBytesShort"
+	MsgTebiBytesLong  = "TebiBytesLong"
+	MsgTebiBytesShort = "TebiBytesShort"
+	MsgPebiBytesLong  = "PebiBytesLong"
+	MsgPebiBytesShort = "PebiBytesShort"
+	MsgExbiBytesLong  = "ExbiBytesLong"
+	MsgExbiBytesShort = "ExbiBytesShort"
+
+	MsgRsyncURLNotDaemonError   = "RsyncURLNotDaemonError"
+	MsgRsyncURLInvalidHostError = "RsyncURLInvalidHostError"
+	MsgRsyncURLInvalidPortError = "RsyncURLInvalidPortError"
 )
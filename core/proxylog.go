@@ -12,7 +12,10 @@
 package core
 
 import (
+	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 
 	logger "github.com/d2r2/go-logger"
 	"github.com/davecgh/go-spew/spew"
@@ -21,6 +24,36 @@ import (
 // WriteLine is a delegate to describe log output call.
 type WriteLine func(line string) error
 
+// LogFormat define how ProxyLog write lines to custom output.
+type LogFormat string
+
+const (
+	// Human readable plain text lines.
+	LOG_FORMAT_TEXT LogFormat = "text"
+	// Structured JSON lines, one object per line,
+	// suitable for ingestion to external monitoring systems.
+	LOG_FORMAT_JSON LogFormat = "json"
+)
+
+// LogFields keep optional context attached to structured log records.
+type LogFields struct {
+	Folder string
+	Bytes  *FolderSize
+	// Detail mark per-folder records, which might
+	// be omitted from custom output to keep it short.
+	Detail bool
+}
+
+// LogRecord describe single line of structured (JSON) log.
+type LogRecord struct {
+	Timestamp string  `json:"timestamp"`
+	Level     string  `json:"level"`
+	Module    string  `json:"module"`
+	Message   string  `json:"message"`
+	Folder    string  `json:"folder,omitempty"`
+	Bytes     *uint64 `json:"bytes,omitempty"`
+}
+
 // ProxyLog is used to substitute regular log console output
 // with output to the file, either to the GUI window.
 // ProxyLog implements logger.PackageLog interface which
@@ -33,6 +66,9 @@ type ProxyLog struct {
 
 	customWriteLine WriteLine
 	customLogLevel  logger.LogLevel
+	customFormat    LogFormat
+	customNoDetails bool
+	fields          LogFields
 }
 
 // Static cast to verify that type implement specific interface
@@ -43,29 +79,99 @@ func NewProxyLog(parent logger.PackageLog, packageName string, packageLen int,
 
 	v := &ProxyLog{parent: parent, packageName: packageName, packageLen: packageLen,
 		timeFormat: timeFormat, customLogLevel: customLogLevel,
-		customWriteLine: writeLine}
+		customWriteLine: writeLine, customFormat: LOG_FORMAT_TEXT}
+	return v
+}
+
+// SetFormat change format of lines written to custom output.
+func (v *ProxyLog) SetFormat(format LogFormat) *ProxyLog {
+	v.customFormat = format
 	return v
 }
 
+// SetVerbosity change maximum level of lines written to custom output.
+// When details are disabled, per-folder records (see LogFields.Detail)
+// are written to custom output only if they report warnings or errors.
+func (v *ProxyLog) SetVerbosity(level logger.LogLevel, details bool) *ProxyLog {
+	v.customLogLevel = level
+	v.customNoDetails = !details
+	return v
+}
+
+// WithFields return copy of ProxyLog, which attach context fields
+// to each structured log record. Text output stay unchanged.
+func (v *ProxyLog) WithFields(fields LogFields) *ProxyLog {
+	log := *v
+	log.fields = fields
+	return &log
+}
+
+// LogWithFields attach context fields to log records, if log
+// is ProxyLog instance, otherwise return log unchanged.
+func LogWithFields(log logger.PackageLog, fields LogFields) logger.PackageLog {
+	if proxy, ok := log.(*ProxyLog); ok {
+		return proxy.WithFields(fields)
+	}
+	return log
+}
+
 func (v *ProxyLog) getFormat() logger.FormatOptions {
 	options := logger.FormatOptions{TimeFormat: v.timeFormat,
 		LevelLength: logger.LevelShort, PackageLength: v.packageLen}
 	return options
 }
 
+// formatLine build line for custom output in requested format.
+func (v *ProxyLog) formatLine(level logger.LogLevel, msg string) (string, error) {
+	if v.customFormat == LOG_FORMAT_JSON {
+		record := LogRecord{Timestamp: time.Now().Format(time.RFC3339),
+			Level: strings.ToLower(level.String()), Module: v.packageName,
+			Message: msg, Folder: SanitizeUTF8(v.fields.Folder)}
+		if v.fields.Bytes != nil {
+			bytes := v.fields.Bytes.GetByteCount()
+			record.Bytes = &bytes
+		}
+		b, err := json.Marshal(record)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	}
+	return logger.FormatMessage(v.getFormat(), level, v.packageName, msg, false), nil
+}
+
+// acceptLevel verify that message should be written to custom output.
+func (v *ProxyLog) acceptLevel(level logger.LogLevel) bool {
+	if level > v.customLogLevel {
+		return false
+	}
+	return !v.customNoDetails || !v.fields.Detail || level <= logger.WarnLevel
+}
+
+// writeLine format and write message to custom output.
+func (v *ProxyLog) writeLine(level logger.LogLevel, msg string) {
+	// Decorative separator lines make no sense in structured log.
+	if v.customFormat == LOG_FORMAT_JSON && strings.Trim(msg, "=-") == "" {
+		return
+	}
+	// File names in legacy encodings might break log consumers.
+	out, err := v.formatLine(level, SanitizeUTF8(msg))
+	if err == nil {
+		err = v.customWriteLine(out + fmt.Sprintln())
+	}
+	if err != nil {
+		v.parent.Fatal(err)
+	}
+}
+
 // Printf implement logger.PackageLog.Printf method.
 func (v *ProxyLog) Printf(level logger.LogLevel, format string, args ...interface{}) {
 	if v.parent != nil {
 		v.parent.Printf(level, format, args...)
 	}
-	if v.customWriteLine != nil && level <= v.customLogLevel {
+	if v.customWriteLine != nil && v.acceptLevel(level) {
 		msg := spew.Sprintf(format, args...)
-		packageName := v.packageName
-		out := logger.FormatMessage(v.getFormat(), level, packageName, msg, false)
-		err := v.customWriteLine(out + fmt.Sprintln())
-		if err != nil {
-			v.parent.Fatal(err)
-		}
+		v.writeLine(level, msg)
 	}
 }
 
@@ -74,14 +180,9 @@ func (v *ProxyLog) Print(level logger.LogLevel, args ...interface{}) {
 	if v.parent != nil {
 		v.parent.Print(level, args...)
 	}
-	if v.customWriteLine != nil && level <= v.customLogLevel {
+	if v.customWriteLine != nil && v.acceptLevel(level) {
 		msg := fmt.Sprint(args...)
-		packageName := v.packageName
-		out := logger.FormatMessage(v.getFormat(), level, packageName, msg, false)
-		err := v.customWriteLine(out + fmt.Sprintln())
-		if err != nil {
-			v.parent.Fatal(err)
-		}
+		v.writeLine(level, msg)
 	}
 }
 
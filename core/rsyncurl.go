@@ -0,0 +1,218 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package core
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/d2r2/go-rsync/locale"
+)
+
+// RSYNC_DEFAULT_PORT is a TCP port used by RSYNC daemon,
+// if not specified explicitly in URL.
+const RSYNC_DEFAULT_PORT = 873
+
+const rsyncURLScheme = "rsync://"
+
+// RsyncURL keep components of RSYNC daemon address, which might be
+// specified either as "rsync://[user@]host[:port]/module/path"
+// or as "[user@]host::module/path". IPv6 address must be
+// enclosed in square brackets, for instance "rsync://[fe80::1]:874/module".
+type RsyncURL struct {
+	User string
+	// Host name or IP address, IPv6 address kept without brackets.
+	Host string
+	// Port is 0, if not specified.
+	Port int
+	// Path contain module name and path inside module, starting from '/'.
+	Path string
+}
+
+// IsRsyncDaemonURL verify that path refer to RSYNC daemon module,
+// rather than to local folder or remote shell location.
+func IsRsyncDaemonURL(rsyncPath string) bool {
+	rsyncPath = strings.TrimSpace(rsyncPath)
+	if hasRsyncScheme(rsyncPath) {
+		return true
+	}
+	_, rest := splitRsyncUser(rsyncPath)
+	if strings.HasPrefix(rest, "[") {
+		i := strings.Index(rest, "]")
+		return i > 0 && strings.HasPrefix(rest[i+1:], "::")
+	}
+	i := strings.Index(rest, "::")
+	return i > 0 && !strings.ContainsAny(rest[:i], "/:")
+}
+
+// ParseRsyncURL disassemble RSYNC daemon address to components.
+// Return error, if address is malformed.
+func ParseRsyncURL(rsyncURL string) (*RsyncURL, error) {
+	rsyncURL = strings.TrimSpace(rsyncURL)
+	if !IsRsyncDaemonURL(rsyncURL) {
+		return nil, errors.New(locale.T(MsgRsyncURLNotDaemonError,
+			struct{ URL string }{URL: rsyncURL}))
+	}
+	url := &RsyncURL{}
+	var hostPort string
+	if hasRsyncScheme(rsyncURL) {
+		var rest string
+		url.User, rest = splitRsyncUser(rsyncURL[len(rsyncURLScheme):])
+		if i := strings.Index(rest, "/"); i >= 0 {
+			hostPort, url.Path = rest[:i], rest[i:]
+		} else {
+			hostPort = rest
+		}
+	} else {
+		var rest string
+		url.User, rest = splitRsyncUser(rsyncURL)
+		i := strings.Index(rest, "::")
+		if strings.HasPrefix(rest, "[") {
+			i = strings.Index(rest, "]") + 1
+		}
+		hostPort, url.Path = rest[:i], "/"+rest[i+2:]
+	}
+	var err error
+	url.Host, url.Port, err = splitRsyncHostPort(hostPort)
+	if err != nil {
+		return nil, err
+	}
+	return url, nil
+}
+
+// CanonicalRsyncPath convert RSYNC daemon address to "rsync://" notation,
+// validating host and port. Other paths are returned intact.
+func CanonicalRsyncPath(rsyncPath string) (string, error) {
+	rsyncPath = strings.TrimSpace(rsyncPath)
+	if !IsRsyncDaemonURL(rsyncPath) {
+		return rsyncPath, nil
+	}
+	url, err := ParseRsyncURL(rsyncPath)
+	if err != nil {
+		return "", err
+	}
+	return url.String(), nil
+}
+
+// HostPort return host with port (if specified), where
+// IPv6 address is enclosed in square brackets.
+func (v *RsyncURL) HostPort() string {
+	host := v.Host
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if v.Port != 0 {
+		host += ":" + strconv.Itoa(v.Port)
+	}
+	return host
+}
+
+// String assemble RSYNC URL back in "rsync://" notation,
+// which is the only one supporting non-standard port.
+func (v *RsyncURL) String() string {
+	var user string
+	if v.User != "" {
+		user = v.User + "@"
+	}
+	return rsyncURLScheme + user + v.HostPort() + v.Path
+}
+
+// Normalize return RSYNC URL stripped of user specification, default port
+// and excess '/' chars, with host name in lower case. Different notations
+// of the same RSYNC source produce identical normalized URL.
+func (v *RsyncURL) Normalize() string {
+	url := &RsyncURL{Host: strings.ToLower(v.Host), Port: v.Port,
+		Path: RemoveExcessSlashChars(v.Path)}
+	if url.Port == RSYNC_DEFAULT_PORT {
+		url.Port = 0
+	}
+	return url.String()
+}
+
+// RemoveExcessSlashChars remove repeated path divider in RSYNC path.
+func RemoveExcessSlashChars(path string) string {
+	var buf bytes.Buffer
+	lastCharIsSlash := false
+	for _, ch := range path {
+		if ch == '/' {
+			if lastCharIsSlash {
+				continue
+			}
+			lastCharIsSlash = true
+		} else {
+			lastCharIsSlash = false
+		}
+		buf.WriteRune(ch)
+	}
+	return buf.String()
+}
+
+func hasRsyncScheme(rsyncPath string) bool {
+	return strings.HasPrefix(strings.ToLower(rsyncPath), rsyncURLScheme)
+}
+
+// splitRsyncUser cut off user specification "user@", if found.
+func splitRsyncUser(rsyncPath string) (user, rest string) {
+	i := strings.Index(rsyncPath, "@")
+	if i >= 0 && !strings.ContainsAny(rsyncPath[:i], "/:[") {
+		return rsyncPath[:i], rsyncPath[i+1:]
+	}
+	return "", rsyncPath
+}
+
+// splitRsyncHostPort disassemble "host", "host:port",
+// "[ipv6]" or "[ipv6]:port" to components.
+func splitRsyncHostPort(hostPort string) (host string, port int, err error) {
+	var portStr string
+	if strings.HasPrefix(hostPort, "[") {
+		i := strings.Index(hostPort, "]")
+		if i < 0 {
+			return "", 0, errors.New(locale.T(MsgRsyncURLInvalidHostError,
+				struct{ Host string }{Host: hostPort}))
+		}
+		host = hostPort[1:i]
+		rest := hostPort[i+1:]
+		if rest != "" {
+			if !strings.HasPrefix(rest, ":") {
+				return "", 0, errors.New(locale.T(MsgRsyncURLInvalidHostError,
+					struct{ Host string }{Host: hostPort}))
+			}
+			portStr = rest[1:]
+		}
+	} else {
+		switch strings.Count(hostPort, ":") {
+		case 0:
+			host = hostPort
+		case 1:
+			i := strings.Index(hostPort, ":")
+			host, portStr = hostPort[:i], hostPort[i+1:]
+		default:
+			// IPv6 address without square brackets
+			return "", 0, errors.New(locale.T(MsgRsyncURLInvalidHostError,
+				struct{ Host string }{Host: hostPort}))
+		}
+	}
+	if host == "" {
+		return "", 0, errors.New(locale.T(MsgRsyncURLInvalidHostError,
+			struct{ Host string }{Host: hostPort}))
+	}
+	if portStr != "" {
+		port, err = strconv.Atoi(portStr)
+		if err != nil || port <= 0 || port > 65535 {
+			return "", 0, errors.New(locale.T(MsgRsyncURLInvalidPortError,
+				struct{ Port string }{Port: portStr}))
+		}
+	}
+	return host, port, nil
+}
@@ -0,0 +1,125 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RELEASES_LATEST_URL is a GitHub API link to the latest project release.
+const RELEASES_LATEST_URL = "https://api.github.com/repos/d2r2/go-rsync/releases/latest"
+
+// Time to wait for GitHub API response.
+const releaseCheckTimeout = 15 * time.Second
+
+// ReleaseInfo describe project release published on GitHub.
+type ReleaseInfo struct {
+	Version    string `json:"tag_name"`
+	Name       string `json:"name"`
+	URL        string `json:"html_url"`
+	Draft      bool   `json:"draft"`
+	Prerelease bool   `json:"prerelease"`
+}
+
+// GetLatestRelease query GitHub releases API for the latest published release.
+func GetLatestRelease(ctx context.Context) (*ReleaseInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, releaseCheckTimeout)
+	defer cancel()
+	req, err := http.NewRequest(http.MethodGet, RELEASES_LATEST_URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("User-Agent", fmt.Sprintf("%s/%s", GetAppTitle(), GetAppVersion()))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GitHub releases API responded with status %q", resp.Status)
+	}
+	var release ReleaseInfo
+	err = json.NewDecoder(resp.Body).Decode(&release)
+	if err != nil {
+		return nil, err
+	}
+	if release.Version == "" {
+		return nil, errors.New("GitHub releases API responded without release tag")
+	}
+	return &release, nil
+}
+
+// parseVersion extract numeric components from version string like
+// "v0.3.3" or "0.3.3+12~g1234567", ignoring build suffix.
+func parseVersion(version string) ([]int, bool) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if i := strings.IndexAny(version, "+-~ "); i != -1 {
+		version = version[:i]
+	}
+	if version == "" {
+		return nil, false
+	}
+	var parts []int
+	for _, item := range strings.Split(version, ".") {
+		n, err := strconv.Atoi(item)
+		if err != nil {
+			return nil, false
+		}
+		parts = append(parts, n)
+	}
+	return parts, true
+}
+
+// CompareVersions return -1, 0 or 1, when version1 is older,
+// the same or newer than version2. Second result is false,
+// if any version can't be parsed.
+func CompareVersions(version1, version2 string) (int, bool) {
+	parts1, ok1 := parseVersion(version1)
+	parts2, ok2 := parseVersion(version2)
+	if !ok1 || !ok2 {
+		return 0, false
+	}
+	for i := 0; i < len(parts1) || i < len(parts2); i++ {
+		var a, b int
+		if i < len(parts1) {
+			a = parts1[i]
+		}
+		if i < len(parts2) {
+			b = parts2[i]
+		}
+		if a < b {
+			return -1, true
+		} else if a > b {
+			return 1, true
+		}
+	}
+	return 0, true
+}
+
+// IsNewerThanAppVersion verify that release is newer than running
+// application. Development builds without version never report updates.
+func (v *ReleaseInfo) IsNewerThanAppVersion() bool {
+	if v.Draft || v.Prerelease {
+		return false
+	}
+	cmp, ok := CompareVersions(v.Version, GetAppVersion())
+	return ok && cmp > 0
+}
@@ -12,9 +12,12 @@
 package core
 
 import (
+	"bytes"
+	"fmt"
 	"math"
 	"regexp"
 	"strings"
+	"unicode/utf8"
 
 	shell "github.com/d2r2/go-shell"
 )
@@ -66,3 +69,23 @@ func FindStringSubmatchIndexes(re *regexp.Regexp, s string) map[string][2]int {
 	}
 	return captures
 }
+
+// SanitizeUTF8 replace bytes, which are not valid UTF-8, with "\xNN"
+// escape sequences. File names in legacy encodings make GTK widgets
+// and desktop notifications fail, so text should be sanitized first.
+func SanitizeUTF8(s string) string {
+	if utf8.ValidString(s) {
+		return s
+	}
+	var buf bytes.Buffer
+	for len(s) > 0 {
+		r, size := utf8.DecodeRuneInString(s)
+		if r == utf8.RuneError && size == 1 {
+			buf.WriteString(fmt.Sprintf("\\x%02X", s[0]))
+		} else {
+			buf.WriteString(s[:size])
+		}
+		s = s[size:]
+	}
+	return buf.String()
+}
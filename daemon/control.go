@@ -0,0 +1,166 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package daemon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/d2r2/go-rsync/locale"
+)
+
+// Commands accepted via control socket.
+const (
+	CONTROL_CMD_START  = "start"
+	CONTROL_CMD_STOP   = "stop"
+	CONTROL_CMD_STATUS = "status"
+	// Reload profiles from profiles folder, for instance
+	// once profile exported from GUI application.
+	CONTROL_CMD_RELOAD = "reload"
+)
+
+// Time allowed to exchange request and response via control socket.
+const controlTimeout = 10 * time.Second
+
+// ControlRequest is a command sent to daemon via control socket.
+type ControlRequest struct {
+	Command string `json:"command"`
+	// Profile name or ID to start or stop; for status and reload
+	// commands optional profile limit response to single profile.
+	Profile string `json:"profile,omitempty"`
+	// Start backup of modules tagged with any of tags only.
+	Tags []string `json:"tags,omitempty"`
+}
+
+// ProfileStatus describe profile state in response to status command.
+type ProfileStatus struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Running bool   `json:"running"`
+	// Overall backup progress in range [0..1] of running session.
+	Progress *float32   `json:"progress,omitempty"`
+	LastRun  *time.Time `json:"last_run,omitempty"`
+	// Completion status of last session: done, done_with_errors,
+	// failed or terminated.
+	LastStatus string     `json:"last_status,omitempty"`
+	LastError  string     `json:"last_error,omitempty"`
+	NextRun    *time.Time `json:"next_run,omitempty"`
+	// Reasons, why due backup is postponed by profile backup window.
+	Postponed string `json:"postponed,omitempty"`
+	// Mirroring status of last session per destination.
+	Mirrors []MirrorStatus `json:"mirrors,omitempty"`
+}
+
+// MirrorStatus describe result of session mirroring to destination.
+type MirrorStatus struct {
+	Path  string `json:"path"`
+	Error string `json:"error,omitempty"`
+}
+
+// ControlResponse is a daemon reply to control request.
+type ControlResponse struct {
+	Error    string          `json:"error,omitempty"`
+	Profiles []ProfileStatus `json:"profiles,omitempty"`
+	// Folder, profiles are loaded from, so client (GUI application)
+	// might save profile there and request reload.
+	ProfilesPath string `json:"profiles_path,omitempty"`
+}
+
+// GetControlSocketPath return default location of control socket:
+// $XDG_RUNTIME_DIR/gorsync/control.sock, or per-user folder
+// in system temporary location, if runtime folder is not defined.
+func GetControlSocketPath() string {
+	runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+	if runtimeDir == "" {
+		return filepath.Join(os.TempDir(), fmt.Sprintf("gorsync-%d", os.Getuid()), "control.sock")
+	}
+	return filepath.Join(runtimeDir, "gorsync", "control.sock")
+}
+
+// SendControlRequest send command to daemon listening on socketPath and return its reply.
+func SendControlRequest(socketPath string, request *ControlRequest) (*ControlResponse, error) {
+	conn, err := net.DialTimeout("unix", socketPath, controlTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	err = conn.SetDeadline(time.Now().Add(controlTimeout))
+	if err != nil {
+		return nil, err
+	}
+	err = json.NewEncoder(conn).Encode(request)
+	if err != nil {
+		return nil, err
+	}
+	response := &ControlResponse{}
+	err = json.NewDecoder(conn).Decode(response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// listenControlSocket create unix socket accessible by current user only.
+// Socket left by crashed daemon is replaced.
+func listenControlSocket(socketPath string) (net.Listener, error) {
+	err := os.MkdirAll(filepath.Dir(socketPath), 0700)
+	if err != nil {
+		return nil, err
+	}
+	// Verify that no other daemon is listening to socket, before removing it.
+	if conn, err := net.DialTimeout("unix", socketPath, time.Second); err == nil {
+		conn.Close()
+		return nil, errors.New(locale.T(MsgDaemonControlSocketInUseError,
+			struct{ SocketPath string }{SocketPath: socketPath}))
+	}
+	err = os.Remove(socketPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return nil, err
+	}
+	err = os.Chmod(socketPath, 0600)
+	if err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
+
+// serveControlConn read single request from connection and write reply.
+func serveControlConn(conn net.Conn, handler func(request *ControlRequest) *ControlResponse) {
+	defer conn.Close()
+	err := conn.SetDeadline(time.Now().Add(controlTimeout))
+	if err != nil {
+		lg.Warn(err)
+		return
+	}
+	request := &ControlRequest{}
+	err = json.NewDecoder(conn).Decode(request)
+	var response *ControlResponse
+	if err != nil {
+		response = &ControlResponse{Error: err.Error()}
+	} else {
+		response = handler(request)
+	}
+	err = json.NewEncoder(conn).Encode(response)
+	if err != nil {
+		lg.Warn(err)
+	}
+}
@@ -0,0 +1,646 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package daemon
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	logger "github.com/d2r2/go-logger"
+
+	"github.com/d2r2/go-rsync/api"
+	"github.com/d2r2/go-rsync/backup"
+	"github.com/d2r2/go-rsync/core"
+	"github.com/d2r2/go-rsync/locale"
+)
+
+// Completion status of backup session.
+const (
+	SESSION_STATUS_DONE             = "done"
+	SESSION_STATUS_DONE_WITH_ERRORS = "done_with_errors"
+	SESSION_STATUS_FAILED           = "failed"
+	SESSION_STATUS_TERMINATED       = "terminated"
+	SESSION_STATUS_STOPPED          = "stopped"
+)
+
+// How often scheduler verify that profile backup is due.
+const schedulerInterval = 30 * time.Second
+
+// sessionNotifier track overall progress of running backup session.
+type sessionNotifier struct {
+	sync.Mutex
+	totalDone core.FolderSize
+	progress  float32
+	// Not nil, when session is logged to journal.
+	journal *JournalLog
+}
+
+// Static cast to verify that struct implement specific interface.
+var _ backup.Notifier = &sessionNotifier{}
+
+func (v *sessionNotifier) getProgress() float32 {
+	v.Lock()
+	defer v.Unlock()
+	return v.progress
+}
+
+func (v *sessionNotifier) NotifyPlanStage_NodeStructureStartInquiry(sourceID int,
+	sourceRsync string) error {
+	return nil
+}
+
+func (v *sessionNotifier) NotifyPlanStage_NodeStructureDoneInquiry(sourceID int,
+	sourceRsync string, dir *core.Dir) error {
+	return nil
+}
+
+func (v *sessionNotifier) NotifyBackupStage_NodeStartBackup(sourceID int,
+	sourceRsync string, totalSize core.FolderSize) error {
+	if v.journal != nil {
+		v.journal.SetStage(SESSION_STAGE_BACKUP)
+	}
+	return nil
+}
+
+func (v *sessionNotifier) NotifyBackupStage_NodeDoneBackup(sourceID int,
+	sourceRsync string, sizeDone core.SizeProgress, sessionErr error) error {
+	return nil
+}
+
+func (v *sessionNotifier) NotifyBackupStage_FolderStartBackup(rootDest string,
+	paths core.SrcDstPath, backupType core.FolderBackupType,
+	leftToBackup core.FolderSize, timePassed time.Duration, eta *backup.ETA) error {
+	return nil
+}
+
+func (v *sessionNotifier) NotifyBackupStage_FolderDoneBackup(rootDest string,
+	paths core.SrcDstPath, backupType core.FolderBackupType,
+	leftToBackup core.FolderSize, sizeDone core.SizeProgress,
+	timePassed time.Duration, eta *backup.ETA, sessionErr error) error {
+
+	v.Lock()
+	defer v.Unlock()
+	v.totalDone = v.totalDone.AddSizeProgress(sizeDone)
+	// Total size is unknown, if plan stage skipped for all modules.
+	if v.totalDone+leftToBackup > 0 {
+		v.progress = float32(float64(v.totalDone) / float64(v.totalDone+leftToBackup))
+	}
+	return nil
+}
+
+func (v *sessionNotifier) NotifyBackupStage_FolderRsyncOutput(rootDest string,
+	paths core.SrcDstPath, output []string) error {
+	return nil
+}
+
+func (v *sessionNotifier) NotifyBackupStage_NetworkStateChanged(sourceRsync string,
+	waiting bool) error {
+	return nil
+}
+
+// scheduleState keep profile schedule and time of next run.
+type scheduleState struct {
+	schedule *Schedule
+	// Backup only modules tagged with any of tags, all modules if empty.
+	tags    []string
+	nextRun time.Time
+}
+
+// profileState keep profile schedules and state of backup sessions.
+type profileState struct {
+	profile   *Profile
+	schedules []*scheduleState
+	// Not nil, if scheduled backups are restricted by backup window.
+	window *backup.BackupWindow
+	// Reasons, why due backup is postponed by backup window.
+	postponed string
+	// Not nil, while backup session is running.
+	cancel   context.CancelFunc
+	notifier *sessionNotifier
+	log      logger.PackageLog
+	lastRun  time.Time
+	// Completion status and error of last session.
+	lastStatus string
+	lastErr    error
+	// Mirroring status of last session.
+	lastMirrors []backup.MirrorResult
+}
+
+// Daemon run backup sessions of profiles by schedule
+// and on request received via control socket.
+type Daemon struct {
+	sync.Mutex
+	profiles []*profileState
+	// Folder, profiles are reloaded from on reload command.
+	profilesPath string
+	// Daemon lifetime context, backup sessions are derived from.
+	ctx context.Context
+	// Closed on graceful stop request.
+	stopping chan struct{}
+	stopOnce sync.Once
+	// Wait for running backup sessions completion.
+	wg sync.WaitGroup
+}
+
+// newProfileState verify profile schedule and backup window
+// and create profile state.
+func newProfileState(profile *Profile, now time.Time) (*profileState, error) {
+	state := &profileState{profile: profile}
+	window, err := profile.GetBackupWindow()
+	if err != nil {
+		return nil, errors.New(locale.T(MsgDaemonProfileBackupWindowError,
+			struct {
+				ProfileName string
+				Error       error
+			}{ProfileName: profile.Name, Error: err}))
+	}
+	state.window = window
+	items := append([]ProfileSchedule{{Every: profile.ScheduleEvery, At: profile.ScheduleAt}},
+		profile.Schedules...)
+	for _, item := range items {
+		schedule, err := NewSchedule(item.Every, item.At)
+		if err != nil {
+			return nil, errors.New(locale.T(MsgDaemonProfileScheduleError,
+				struct {
+					ProfileName string
+					Error       error
+				}{ProfileName: profile.Name, Error: err}))
+		}
+		if schedule != nil {
+			state.schedules = append(state.schedules, &scheduleState{schedule: schedule,
+				tags:    backup.ParseModuleTags(strings.Join(item.Tags, ",")),
+				nextRun: schedule.Next(now)})
+		}
+	}
+	return state, nil
+}
+
+// update replace profile configuration with one of state, keeping
+// backup session state. Schedules not changed keep time of next run.
+func (v *profileState) update(state *profileState) {
+	for i, item := range state.schedules {
+		if i < len(v.schedules) && reflect.DeepEqual(item.schedule, v.schedules[i].schedule) &&
+			reflect.DeepEqual(item.tags, v.schedules[i].tags) {
+			item.nextRun = v.schedules[i].nextRun
+		}
+	}
+	v.profile = state.profile
+	v.schedules = state.schedules
+	v.window = state.window
+	v.postponed = ""
+}
+
+// NewDaemon verify profiles schedule and create daemon instance.
+func NewDaemon(profiles []*Profile) (*Daemon, error) {
+	v := &Daemon{stopping: make(chan struct{})}
+	now := time.Now()
+	for _, profile := range profiles {
+		state, err := newProfileState(profile, now)
+		if err != nil {
+			return nil, err
+		}
+		v.profiles = append(v.profiles, state)
+	}
+	return v, nil
+}
+
+// SetProfilesPath specify folder, profiles are reloaded from
+// on reload command. Reload is refused, if folder is not specified.
+func (v *Daemon) SetProfilesPath(profilesPath string) {
+	v.Lock()
+	defer v.Unlock()
+	v.profilesPath = profilesPath
+}
+
+// reload replace profiles with ones loaded from profiles folder.
+// Profiles kept by ID preserve backup session state, so running
+// session continue with previous configuration. Profile with backup
+// running can't be removed. Should be called under lock.
+func (v *Daemon) reload() error {
+	if v.profilesPath == "" {
+		return errors.New(locale.T(MsgDaemonProfilesPathNotSetError, nil))
+	}
+	profiles, err := LoadProfiles(v.profilesPath)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	states := make([]*profileState, len(profiles))
+	ids := make(map[string]bool)
+	for i, profile := range profiles {
+		states[i], err = newProfileState(profile, now)
+		if err != nil {
+			return err
+		}
+		ids[profile.ID] = true
+	}
+	previous := make(map[string]*profileState)
+	for _, item := range v.profiles {
+		if !ids[item.profile.ID] && item.cancel != nil {
+			return errors.New(locale.T(MsgDaemonProfileIsRunningError,
+				struct{ ProfileName string }{ProfileName: item.profile.Name}))
+		}
+		previous[item.profile.ID] = item
+	}
+	for i, state := range states {
+		if item, ok := previous[state.profile.ID]; ok {
+			item.update(state)
+			states[i] = item
+		}
+	}
+	v.profiles = states
+	lg.Info(locale.TP(MsgDaemonProfilesReloaded,
+		struct{ ProfileCount int }{ProfileCount: len(states)}, len(states)))
+	return nil
+}
+
+// findProfile return profile state by name or ID, or nil.
+func (v *Daemon) findProfile(ref string) *profileState {
+	for _, state := range v.profiles {
+		if state.profile.GetIdentity().Match(ref) {
+			return state
+		}
+	}
+	return nil
+}
+
+// Stop request graceful daemon shutdown: no new backup sessions
+// are started, running sessions complete current folder block.
+func (v *Daemon) Stop() {
+	v.stopOnce.Do(func() {
+		lg.Info(locale.T(MsgDaemonStoppingGracefully, nil))
+		close(v.stopping)
+	})
+}
+
+// isStopping verify that graceful shutdown was requested.
+func (v *Daemon) isStopping() bool {
+	select {
+	case <-v.stopping:
+		return true
+	default:
+		return false
+	}
+}
+
+// Run serve control socket and start scheduled backups, until
+// context is cancelled or Stop is called. Running backup sessions
+// are terminated either stopped gracefully then.
+func (v *Daemon) Run(ctx context.Context, socketPath string) error {
+	listener, err := listenControlSocket(socketPath)
+	if err != nil {
+		return err
+	}
+	v.Lock()
+	v.ctx = ctx
+	v.Unlock()
+	lg.Info(locale.TP(MsgDaemonStarted,
+		struct {
+			ProfileCount int
+			SocketPath   string
+		}{ProfileCount: len(v.profiles), SocketPath: socketPath}, len(v.profiles)))
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				// Listener is closed on exit.
+				if ctx.Err() == nil && !v.isStopping() {
+					lg.Warn(err)
+				}
+				return
+			}
+			go serveControlConn(conn, v.handleRequest)
+		}
+	}()
+
+	// Report readiness to systemd and keep watchdog alive.
+	v.notifyStatus("READY=1")
+	done := make(chan struct{})
+	defer close(done)
+	if interval := sdWatchdogInterval(); interval > 0 {
+		go runWatchdog(interval, done)
+	}
+
+	ticker := time.NewTicker(schedulerInterval)
+	defer ticker.Stop()
+	for {
+		v.startDueSessions()
+		select {
+		case <-ctx.Done():
+		case <-v.stopping:
+		case <-ticker.C:
+			continue
+		}
+		v.shutdown(ctx, listener)
+		return nil
+	}
+}
+
+// shutdown close control socket and wait for running backup sessions.
+// Sessions are terminated immediately, once context is cancelled.
+func (v *Daemon) shutdown(ctx context.Context, listener net.Listener) {
+	listener.Close()
+	lg.Info(locale.T(MsgDaemonStopping, nil))
+	err := sdNotify("STOPPING=1")
+	if err != nil {
+		lg.Warn(err)
+	}
+	sessionsDone := make(chan struct{})
+	go func() {
+		v.wg.Wait()
+		close(sessionsDone)
+	}()
+	select {
+	case <-sessionsDone:
+	case <-ctx.Done():
+		v.stopAll()
+		<-sessionsDone
+	}
+}
+
+// notifyStatus send state to systemd, extended with
+// status line, which list running backup sessions.
+func (v *Daemon) notifyStatus(state string) {
+	v.Lock()
+	var running []string
+	for _, item := range v.profiles {
+		if item.cancel != nil {
+			running = append(running, item.profile.Name)
+		}
+	}
+	v.Unlock()
+	var status string
+	if len(running) > 0 {
+		status = locale.T(MsgDaemonStatusRunning,
+			struct{ Profiles string }{Profiles: strings.Join(running, ", ")})
+	} else {
+		status = locale.T(MsgDaemonStatusIdle, nil)
+	}
+	status = "STATUS=" + status
+	if state != "" {
+		status = state + "\n" + status
+	}
+	err := sdNotify(status)
+	if err != nil {
+		lg.Warn(err)
+	}
+}
+
+// startDueSessions start backup of profiles, which schedule is due.
+// Profile with backup running postpone schedule to next time.
+// Backup due out of profile backup window is started, once window open.
+// Schedules due at once are combined in one session.
+func (v *Daemon) startDueSessions() {
+	v.Lock()
+	defer v.Unlock()
+	now := time.Now()
+	for _, state := range v.profiles {
+		var due, allModules bool
+		var tags []string
+		for _, item := range state.schedules {
+			if now.Before(item.nextRun) {
+				continue
+			}
+			due = true
+			if len(item.tags) == 0 {
+				allModules = true
+			}
+			tags = append(tags, item.tags...)
+		}
+		if !due {
+			continue
+		}
+		if state.cancel == nil && state.window != nil {
+			if reasons := state.window.Check(now, false); len(reasons) > 0 {
+				postponed := strings.Join(reasons, "; ")
+				if state.postponed != postponed {
+					lg.Info(locale.T(MsgDaemonSessionPostponed,
+						struct{ ProfileName, Reason string }{ProfileName: state.profile.Name,
+							Reason: postponed}))
+				}
+				state.postponed = postponed
+				continue
+			}
+		}
+		state.postponed = ""
+		for _, item := range state.schedules {
+			if !now.Before(item.nextRun) {
+				item.nextRun = item.schedule.Next(now)
+			}
+		}
+		if state.cancel == nil {
+			if allModules {
+				tags = nil
+			}
+			v.startSession(v.ctx, state, tags)
+		}
+	}
+}
+
+// startSession run profile backup in background; if tags specified,
+// only modules tagged with any of them are backed up.
+// Should be called under lock.
+func (v *Daemon) startSession(ctx context.Context, state *profileState, tags []string) {
+	sessionCtx, cancel := context.WithCancel(ctx)
+	// Profile might be replaced on reload, while session is running.
+	profile := state.profile
+	state.cancel = cancel
+	state.lastRun = time.Now()
+	// Session start time identify session records in journal.
+	state.log = newSessionLog(lg, state.profile.GetIdentity(),
+		state.lastRun.Format("2006-01-02T15:04:05"))
+	state.notifier = &sessionNotifier{}
+	if journal, ok := state.log.(*JournalLog); ok {
+		journal.SetStage(SESSION_STAGE_PLAN)
+		state.notifier.journal = journal
+	}
+	state.log.Info(locale.T(MsgDaemonSessionStarted,
+		struct{ ProfileName string }{ProfileName: state.profile.Name}))
+	v.wg.Add(1)
+	go func() {
+		defer v.wg.Done()
+		v.notifyStatus("")
+		status, mirrors, err := runSession(sessionCtx, profile, tags, state.notifier,
+			state.log, v.stopping)
+		v.Lock()
+		state.cancel = nil
+		state.lastStatus = status
+		state.lastErr = err
+		state.lastMirrors = mirrors
+		v.Unlock()
+		cancel()
+		v.notifyStatus("")
+		if err != nil {
+			state.log.Warn(locale.T(MsgDaemonSessionFailed,
+				struct {
+					ProfileName string
+					Status      string
+					Error       error
+				}{ProfileName: profile.Name, Status: status, Error: err}))
+		} else {
+			state.log.Info(locale.T(MsgDaemonSessionCompleted,
+				struct{ ProfileName, Status string }{ProfileName: profile.Name,
+					Status: status}))
+		}
+	}()
+}
+
+// runSession perform both stages of profile backup, mirror completed session
+// and return completion status. Session is stopped before next folder block,
+// once gracefulStop is closed.
+func runSession(ctx context.Context, profile *Profile, tags []string, notifier *sessionNotifier,
+	log logger.PackageLog, gracefulStop <-chan struct{}) (string, []backup.MirrorResult, error) {
+
+	// Copy configuration, since engine might modify it.
+	config := profile.Config
+	config.Profile = profile.GetIdentity()
+	config.GracefulStop = gracefulStop
+	filtered := backup.FilterModulesByTags(profile.Modules, tags)
+	if len(filtered) == 0 {
+		return SESSION_STATUS_FAILED, nil, errors.New(locale.T(MsgDaemonNoModuleWithTagsError,
+			struct{ Tags string }{Tags: strings.Join(tags, ", ")}))
+	}
+	modules := make([]backup.Module, len(filtered))
+	copy(modules, filtered)
+	engine, err := api.NewEngine(&api.Options{
+		Config:   &config,
+		Modules:  modules,
+		DestPath: profile.DestPath,
+		Log:      log,
+		Notifier: notifier,
+	})
+	if err != nil {
+		return SESSION_STATUS_FAILED, nil, err
+	}
+	progress, err := engine.Backup(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return SESSION_STATUS_TERMINATED, nil, err
+		}
+		if backup.IsGracefulStopError(err) {
+			return SESSION_STATUS_STOPPED, nil, err
+		}
+		return SESSION_STATUS_FAILED, nil, err
+	}
+	var mirrors []backup.MirrorResult
+	if len(profile.MirrorPaths) > 0 {
+		mirrors = backup.MirrorSessionToAll(ctx,
+			progress.GetBackupFullPath(progress.BackupFolder), profile.MirrorPaths, log)
+	}
+	if progress.TotalProgress.Failed != nil {
+		return SESSION_STATUS_DONE_WITH_ERRORS, mirrors, progress.LastSessionError
+	}
+	return SESSION_STATUS_DONE, mirrors, nil
+}
+
+// stopAll terminate all running backup sessions.
+func (v *Daemon) stopAll() {
+	v.Lock()
+	defer v.Unlock()
+	for _, state := range v.profiles {
+		if state.cancel != nil {
+			state.cancel()
+		}
+	}
+}
+
+// getStatus return profile state. Should be called under lock.
+func (v *Daemon) getStatus(state *profileState) ProfileStatus {
+	status := ProfileStatus{ID: state.profile.ID, Name: state.profile.Name,
+		Running: state.cancel != nil, LastStatus: state.lastStatus, Postponed: state.postponed}
+	if state.cancel != nil {
+		progress := state.notifier.getProgress()
+		status.Progress = &progress
+	}
+	if !state.lastRun.IsZero() {
+		lastRun := state.lastRun
+		status.LastRun = &lastRun
+	}
+	if state.lastErr != nil {
+		status.LastError = state.lastErr.Error()
+	}
+	for _, item := range state.schedules {
+		if status.NextRun == nil || item.nextRun.Before(*status.NextRun) {
+			nextRun := item.nextRun
+			status.NextRun = &nextRun
+		}
+	}
+	for _, mirror := range state.lastMirrors {
+		item := MirrorStatus{Path: mirror.DestPath}
+		if mirror.Err != nil {
+			item.Error = mirror.Err.Error()
+		}
+		status.Mirrors = append(status.Mirrors, item)
+	}
+	return status
+}
+
+// handleRequest execute command received via control socket.
+func (v *Daemon) handleRequest(request *ControlRequest) *ControlResponse {
+	v.Lock()
+	defer v.Unlock()
+	if request.Command == CONTROL_CMD_RELOAD {
+		err := v.reload()
+		if err != nil {
+			return &ControlResponse{Error: err.Error()}
+		}
+	}
+	var state *profileState
+	if request.Profile != "" {
+		state = v.findProfile(request.Profile)
+		if state == nil {
+			return &ControlResponse{Error: locale.T(MsgDaemonProfileNotFoundError,
+				struct{ ProfileName string }{ProfileName: request.Profile})}
+		}
+	}
+	switch request.Command {
+	case CONTROL_CMD_START:
+		if state == nil {
+			return &ControlResponse{Error: locale.T(MsgDaemonProfileIsNotSpecifiedError, nil)}
+		}
+		if v.isStopping() {
+			return &ControlResponse{Error: locale.T(MsgDaemonIsStoppingError, nil)}
+		}
+		if state.cancel != nil {
+			return &ControlResponse{Error: locale.T(MsgDaemonProfileIsRunningError,
+				struct{ ProfileName string }{ProfileName: state.profile.Name})}
+		}
+		v.startSession(v.ctx, state,
+			backup.ParseModuleTags(strings.Join(request.Tags, ",")))
+	case CONTROL_CMD_STOP:
+		if state == nil {
+			return &ControlResponse{Error: locale.T(MsgDaemonProfileIsNotSpecifiedError, nil)}
+		}
+		if state.cancel == nil {
+			return &ControlResponse{Error: locale.T(MsgDaemonProfileIsNotRunningError,
+				struct{ ProfileName string }{ProfileName: state.profile.Name})}
+		}
+		state.cancel()
+	case CONTROL_CMD_STATUS, CONTROL_CMD_RELOAD:
+	default:
+		return &ControlResponse{Error: locale.T(MsgDaemonUnknownCommandError,
+			struct{ Command string }{Command: request.Command})}
+	}
+	response := &ControlResponse{ProfilesPath: v.profilesPath}
+	for _, item := range v.profiles {
+		if state == nil || item == state {
+			response.Profiles = append(response.Profiles, v.getStatus(item))
+		}
+	}
+	return response
+}
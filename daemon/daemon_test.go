@@ -0,0 +1,125 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/d2r2/go-rsync/backup"
+)
+
+func TestStartDueSessionsPostponedByBackupWindow(t *testing.T) {
+	now := time.Now()
+	window := &ProfileBackupWindow{Start: now.Add(2 * time.Hour).Format("15:04"),
+		End: now.Add(3 * time.Hour).Format("15:04")}
+	v, err := NewDaemon([]*Profile{{ID: "home", Name: "Home", ScheduleEvery: "1h",
+		BackupWindow: window}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := v.profiles[0]
+	due := now.Add(-time.Minute)
+	state.schedules[0].nextRun = due
+
+	v.startDueSessions()
+	if state.cancel != nil {
+		t.Fatal("backup started out of backup window")
+	}
+	if state.postponed == "" {
+		t.Error("postponed reason is not reported")
+	}
+	if !state.schedules[0].nextRun.Equal(due) {
+		t.Errorf("due schedule moved to %v, expected to keep %v", state.schedules[0].nextRun, due)
+	}
+	if status := v.getStatus(state); status.Postponed != state.postponed {
+		t.Errorf("status report postponed %q, expected %q", status.Postponed, state.postponed)
+	}
+
+	_, err = NewDaemon([]*Profile{{ID: "home", Name: "Home",
+		BackupWindow: &ProfileBackupWindow{Start: "25:00", End: "06:00"}}})
+	if err == nil {
+		t.Error("invalid backup window accepted")
+	}
+}
+
+func TestReloadProfiles(t *testing.T) {
+	profilesPath := t.TempDir()
+	save := func(profile *Profile) {
+		t.Helper()
+		err := SaveProfile(profilesPath, profile)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	save(&Profile{ID: "home", Name: "Home", DestPath: "/mnt/backup/home", ScheduleEvery: "1h",
+		Modules: []backup.Module{{SourceRsync: "/home", MaxFileAgeDays: 30}}})
+	save(&Profile{ID: "work", Name: "Work", DestPath: "/mnt/backup/work",
+		Modules: []backup.Module{{SourceRsync: "/work"}}})
+	profiles, err := LoadProfiles(profilesPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profiles) != 2 || profiles[0].Modules[0].MaxFileAgeDays != 30 {
+		t.Fatalf("saved profiles are not loaded back: %+v", profiles)
+	}
+	v, err := NewDaemon(profiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.SetProfilesPath(profilesPath)
+	home := v.profiles[0]
+	nextRun := time.Now().Add(time.Minute)
+	home.schedules[0].nextRun = nextRun
+	// Emulate running session.
+	home.cancel = func() {}
+	home.notifier = &sessionNotifier{}
+
+	save(&Profile{ID: "home", Name: "Home renamed", DestPath: "/mnt/backup/home",
+		ScheduleEvery: "1h", Modules: []backup.Module{{SourceRsync: "/home"}}})
+	response := v.handleRequest(&ControlRequest{Command: CONTROL_CMD_RELOAD, Profile: "home"})
+	if response.Error != "" {
+		t.Fatal(response.Error)
+	}
+	if len(response.Profiles) != 1 || response.Profiles[0].Name != "Home renamed" ||
+		!response.Profiles[0].Running {
+		t.Errorf("unexpected status of reloaded profile: %+v", response.Profiles)
+	}
+	if v.profiles[0] != home || !home.schedules[0].nextRun.Equal(nextRun) {
+		t.Error("state of reloaded profile is not preserved")
+	}
+
+	err = os.Remove(filepath.Join(profilesPath, "home"+PROFILE_FILE_EXT))
+	if err != nil {
+		t.Fatal(err)
+	}
+	response = v.handleRequest(&ControlRequest{Command: CONTROL_CMD_RELOAD})
+	if response.Error == "" || len(v.profiles) != 2 {
+		t.Error("profile with backup running removed on reload")
+	}
+	if profile, err := ReadProfile(profilesPath, "home"); profile != nil || err != nil {
+		t.Errorf("removed profile read: %+v, %v", profile, err)
+	}
+
+	err = SaveProfile(profilesPath, &Profile{ID: "other", Name: "Work",
+		DestPath: "/mnt/backup/other", Modules: []backup.Module{{SourceRsync: "/other"}}})
+	if err == nil {
+		t.Error("profile with duplicate name saved")
+	}
+	err = SaveProfile(profilesPath, &Profile{ID: "../home", Name: "Escape",
+		DestPath: "/mnt/backup/other", Modules: []backup.Module{{SourceRsync: "/other"}}})
+	if err == nil {
+		t.Error("profile ID with path separator accepted")
+	}
+}
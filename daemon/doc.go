@@ -0,0 +1,81 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+// Package daemon run Gorsync Backup without GUI: backup profiles are loaded
+// from TOML files, backup sessions are started by schedule, and controlled
+// with start/stop/status commands via unix socket. Suitable for server
+// deployments managed by systemd ("gorsync daemon" command).
+//
+// Each profile is described by separate file in profiles folder
+// ($XDG_CONFIG_HOME/gorsync/profiles by default), for instance:
+//
+//	# stable identifier, profile file name by default
+//	id = "home"
+//	name = "Home folders"
+//	dest_path = "/mnt/backup/home"
+//	# run backup every 24 hours at 02:30
+//	schedule_every = "24h"
+//	schedule_at = "02:30"
+//	# mirror completed session to USB drive
+//	mirror_paths = ["/media/usb/backup/home"]
+//
+//	# postpone scheduled backups until night and AC power
+//	[backup_window]
+//	start = "22:00"
+//	end = "06:00"
+//	require_ac_power = true
+//
+//	# additionally backup modules tagged "critical" every hour
+//	[[schedule]]
+//	every = "1h"
+//	tags = ["critical"]
+//
+//	[config]
+//	number_of_previous_backup_to_use = 2
+//
+//	[[module]]
+//	src_rsync = "rsync://server/home"
+//	dst_subpath = "home"
+//	tags = ["critical"]
+//
+// Control socket accept one JSON request per connection and reply with
+// JSON response (see ControlRequest and ControlResponse):
+//
+//	echo '{"command":"status"}' | socat - UNIX-CONNECT:$XDG_RUNTIME_DIR/gorsync/control.sock
+//
+// Command "reload" re-read profiles folder: profiles kept by ID preserve
+// state and running sessions, while profile with backup running can't be
+// removed. Response report profiles folder in use, so clients know where
+// to save profiles. Backup started with control command is not restricted
+// by profile backup window.
+//
+// GUI application keep own profiles in GSettings, but is a client of
+// control socket: "Backup daemon" window show daemon profiles status,
+// start and stop their backup, and export profile selected in main window
+// to daemon profiles folder (named by profile UUID) followed by reload,
+// so daemon might backup it. Exported profile run on request only, until
+// schedule is specified in profile file; repeated export keep schedules.
+//
+// Started as systemd Type=notify service, daemon report readiness and
+// status line via sd_notify protocol, and ping watchdog, if WatchdogSec
+// is set. Backup session logs are written directly to journal with
+// GORSYNC_PROFILE, GORSYNC_PROFILE_ID, GORSYNC_SESSION and GORSYNC_STAGE
+// fields, for instance:
+//
+//	journalctl --user -u gorsync GORSYNC_PROFILE_ID=home
+//
+// Profile might be renamed any time, but ID should be kept: backup sessions,
+// snapshots and journal records refer to profile by ID. Control commands
+// accept either profile name or ID.
+//
+// First SIGTERM stop daemon gracefully: running backup sessions complete
+// current folder block before exit. Second SIGTERM terminate them at once.
+package daemon
@@ -0,0 +1,249 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package daemon
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	logger "github.com/d2r2/go-logger"
+	"github.com/d2r2/go-rsync/backup"
+	"github.com/davecgh/go-spew/spew"
+)
+
+// Native protocol socket of systemd-journald.
+const journalSocketPath = "/run/systemd/journal/socket"
+
+// SYSLOG_IDENTIFIER field value of journal records.
+const journalIdentifier = "gorsync"
+
+// Backup session stage reported in GORSYNC_STAGE journal field.
+const (
+	SESSION_STAGE_PLAN   = "plan"
+	SESSION_STAGE_BACKUP = "backup"
+)
+
+var (
+	journalOnce sync.Once
+	journalConn *net.UnixConn
+)
+
+// getJournalConn return connection to systemd-journald, when
+// daemon output is connected to journal, otherwise nil.
+func getJournalConn() *net.UnixConn {
+	journalOnce.Do(func() {
+		// Set by systemd, when stdout/stderr is connected to journal.
+		if os.Getenv("JOURNAL_STREAM") == "" {
+			return
+		}
+		conn, err := net.DialUnix("unixgram", nil,
+			&net.UnixAddr{Name: journalSocketPath, Net: "unixgram"})
+		if err != nil {
+			lg.Debugf("Journal socket is not available: %v", err)
+			return
+		}
+		journalConn = conn
+	})
+	return journalConn
+}
+
+// journalPriority convert log level to syslog priority.
+func journalPriority(level logger.LogLevel) int {
+	switch level {
+	case logger.FatalLevel, logger.PanicLevel:
+		return 2
+	case logger.ErrorLevel:
+		return 3
+	case logger.WarnLevel:
+		return 4
+	case logger.NotifyLevel:
+		return 5
+	case logger.InfoLevel:
+		return 6
+	default:
+		return 7
+	}
+}
+
+// appendJournalField serialize field in journal native protocol format.
+// Values with line breaks are written in binary safe form.
+func appendJournalField(buf *bytes.Buffer, name, value string) {
+	if strings.ContainsRune(value, '\n') {
+		buf.WriteString(name)
+		buf.WriteByte('\n')
+		binary.Write(buf, binary.LittleEndian, uint64(len(value)))
+		buf.WriteString(value)
+		buf.WriteByte('\n')
+	} else {
+		buf.WriteString(name + "=" + value + "\n")
+	}
+}
+
+// JournalLog write log records directly to systemd-journald with
+// structured fields: profile, session and stage of backup process.
+// JournalLog implements logger.PackageLog interface.
+type JournalLog struct {
+	sync.Mutex
+	conn     *net.UnixConn
+	parent   logger.PackageLog
+	logLevel logger.LogLevel
+	profile  backup.ProfileIdentity
+	session  string
+	stage    string
+}
+
+// Static cast to verify that type implement specific interface
+var _ logger.PackageLog = &JournalLog{}
+
+// newSessionLog return log of profile backup session, which write
+// to journal, if available. Otherwise parent log is returned.
+func newSessionLog(parent logger.PackageLog, profile backup.ProfileIdentity,
+	session string) logger.PackageLog {
+
+	conn := getJournalConn()
+	if conn == nil {
+		return parent
+	}
+	v := &JournalLog{conn: conn, parent: parent, logLevel: logger.InfoLevel,
+		profile: profile, session: session}
+	return v
+}
+
+// SetStage change stage reported with next log records.
+func (v *JournalLog) SetStage(stage string) {
+	v.Lock()
+	defer v.Unlock()
+	v.stage = stage
+}
+
+// send write message to journal. Fall back to parent log,
+// if message can't be delivered (for instance, it's too long).
+// Panic and fatal messages are passed to parent log as well,
+// to keep their behavior.
+func (v *JournalLog) send(level logger.LogLevel, msg string) {
+	if level > v.logLevel {
+		return
+	}
+	v.Lock()
+	stage := v.stage
+	v.Unlock()
+	var buf bytes.Buffer
+	appendJournalField(&buf, "MESSAGE", msg)
+	appendJournalField(&buf, "PRIORITY", strconv.Itoa(journalPriority(level)))
+	appendJournalField(&buf, "SYSLOG_IDENTIFIER", journalIdentifier)
+	appendJournalField(&buf, "GORSYNC_PROFILE", v.profile.Name)
+	appendJournalField(&buf, "GORSYNC_PROFILE_ID", v.profile.ID)
+	appendJournalField(&buf, "GORSYNC_SESSION", v.session)
+	if stage != "" {
+		appendJournalField(&buf, "GORSYNC_STAGE", stage)
+	}
+	_, err := v.conn.Write(buf.Bytes())
+	if err != nil || level <= logger.PanicLevel {
+		v.parent.Print(level, msg)
+	}
+}
+
+// Printf implement logger.PackageLog.Printf method.
+func (v *JournalLog) Printf(level logger.LogLevel, format string, args ...interface{}) {
+	v.send(level, spew.Sprintf(format, args...))
+}
+
+// Print implement logger.PackageLog.Print method.
+func (v *JournalLog) Print(level logger.LogLevel, args ...interface{}) {
+	v.send(level, fmt.Sprint(args...))
+}
+
+// Debugf implement logger.PackageLog.Debugf method.
+func (v *JournalLog) Debugf(format string, args ...interface{}) {
+	v.Printf(logger.DebugLevel, format, args...)
+}
+
+// Debug implement logger.PackageLog.Debug method.
+func (v *JournalLog) Debug(args ...interface{}) {
+	v.Print(logger.DebugLevel, args...)
+}
+
+// Infof implement logger.PackageLog.Infof method.
+func (v *JournalLog) Infof(format string, args ...interface{}) {
+	v.Printf(logger.InfoLevel, format, args...)
+}
+
+// Info implement logger.PackageLog.Info method.
+func (v *JournalLog) Info(args ...interface{}) {
+	v.Print(logger.InfoLevel, args...)
+}
+
+// Notifyf implement logger.PackageLog.Notifyf method.
+func (v *JournalLog) Notifyf(format string, args ...interface{}) {
+	v.Printf(logger.NotifyLevel, format, args...)
+}
+
+// Notify implement logger.PackageLog.Notify method.
+func (v *JournalLog) Notify(args ...interface{}) {
+	v.Print(logger.NotifyLevel, args...)
+}
+
+// Warningf implement logger.PackageLog.Warningf method.
+func (v *JournalLog) Warningf(format string, args ...interface{}) {
+	v.Printf(logger.WarnLevel, format, args...)
+}
+
+// Warnf implement logger.PackageLog.Warnf method.
+func (v *JournalLog) Warnf(format string, args ...interface{}) {
+	v.Printf(logger.WarnLevel, format, args...)
+}
+
+// Warning implement logger.PackageLog.Warning method.
+func (v *JournalLog) Warning(args ...interface{}) {
+	v.Print(logger.WarnLevel, args...)
+}
+
+// Warn implement logger.PackageLog.Warn method.
+func (v *JournalLog) Warn(args ...interface{}) {
+	v.Print(logger.WarnLevel, args...)
+}
+
+// Errorf implement logger.PackageLog.Errorf method.
+func (v *JournalLog) Errorf(format string, args ...interface{}) {
+	v.Printf(logger.ErrorLevel, format, args...)
+}
+
+// Error implement logger.PackageLog.Error method.
+func (v *JournalLog) Error(args ...interface{}) {
+	v.Print(logger.ErrorLevel, args...)
+}
+
+// Panicf implement logger.PackageLog.Panicf method.
+func (v *JournalLog) Panicf(format string, args ...interface{}) {
+	v.Printf(logger.PanicLevel, format, args...)
+}
+
+// Panic implement logger.PackageLog.Panic method.
+func (v *JournalLog) Panic(args ...interface{}) {
+	v.Print(logger.PanicLevel, args...)
+}
+
+// Fatalf implement logger.PackageLog.Fatalf method.
+func (v *JournalLog) Fatalf(format string, args ...interface{}) {
+	v.Printf(logger.FatalLevel, format, args...)
+}
+
+// Fatal implement logger.PackageLog.Fatal method.
+func (v *JournalLog) Fatal(args ...interface{}) {
+	v.Print(logger.FatalLevel, args...)
+}
@@ -0,0 +1,277 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package daemon
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/d2r2/go-rsync/backup"
+	"github.com/d2r2/go-rsync/locale"
+)
+
+// PROFILE_FILE_EXT is an extension of profile files in profiles folder.
+const PROFILE_FILE_EXT = ".toml"
+
+// Profile describe backup profile served by daemon.
+type Profile struct {
+	// Stable profile identifier, which should never change, since backup
+	// sessions, snapshots and journal records refer to it; profile file
+	// name (without extension) by default.
+	ID string `toml:"id"`
+	// Profile name, might be renamed any time; profile ID by default.
+	Name     string `toml:"name"`
+	DestPath string `toml:"dest_path"`
+	// Interval between scheduled backups (Go duration format,
+	// for instance "24h"); empty to run backup on request only.
+	ScheduleEvery string `toml:"schedule_every"`
+	// Time of day "HH:MM" scheduled backups are aligned to, optional.
+	ScheduleAt string `toml:"schedule_at"`
+	// Destination roots, completed session is mirrored to.
+	MirrorPaths []string `toml:"mirror_paths"`
+	// Additional schedules to backup tagged modules only.
+	Schedules []ProfileSchedule `toml:"schedule"`
+	// Conditions, which should be met to start scheduled backup; optional.
+	BackupWindow *ProfileBackupWindow `toml:"backup_window"`
+
+	Config  backup.Config   `toml:"config"`
+	Modules []backup.Module `toml:"module"`
+}
+
+// ProfileSchedule define additional profile schedule, which backup
+// only modules tagged with any of tags specified (all modules, if empty).
+type ProfileSchedule struct {
+	Every string   `toml:"every"`
+	At    string   `toml:"at"`
+	Tags  []string `toml:"tags"`
+}
+
+// ProfileBackupWindow define time of day range (might cross midnight),
+// when scheduled backups may run, and whether AC power is required.
+// Network metered state is not known without desktop session,
+// so it is not taken into account in daemon mode.
+type ProfileBackupWindow struct {
+	Start          string `toml:"start"`
+	End            string `toml:"end"`
+	RequireACPower bool   `toml:"require_ac_power"`
+}
+
+// GetBackupWindow verify and return profile backup window,
+// or nil, if backup window is not specified.
+func (v *Profile) GetBackupWindow() (*backup.BackupWindow, error) {
+	if v.BackupWindow == nil {
+		return nil, nil
+	}
+	return backup.NewBackupWindow(v.BackupWindow.Start, v.BackupWindow.End,
+		v.BackupWindow.RequireACPower, false)
+}
+
+// GetProfilesPath return folder where daemon profiles are located:
+// $XDG_CONFIG_HOME/gorsync/profiles, or ~/.config/gorsync/profiles by default.
+func GetProfilesPath() (string, error) {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		u, err := user.Current()
+		if err != nil {
+			return "", err
+		}
+		configHome = filepath.Join(u.HomeDir, ".config")
+	}
+	return filepath.Join(configHome, "gorsync", "profiles"), nil
+}
+
+// SaveProfile write profile to file named by profile ID in profiles folder,
+// replacing previous version of profile. File is replaced atomically,
+// so daemon never load partially written profile. File is readable
+// by current user only, since modules might contain rsync passwords.
+func SaveProfile(profilesPath string, profile *Profile) error {
+	if profile.ID == "" || profile.ID != filepath.Base(profile.ID) ||
+		strings.HasPrefix(profile.ID, ".") {
+		return errors.New(locale.T(MsgDaemonProfileIDNotValidError,
+			struct{ ProfileID string }{ProfileID: profile.ID}))
+	}
+	err := profile.Validate(false).Err()
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(profilesPath, 0700)
+	if err != nil {
+		return err
+	}
+	// Verify that profile name is not taken by another profile,
+	// otherwise daemon refuse to load profiles.
+	items, err := ioutil.ReadDir(profilesPath)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if item.IsDir() || filepath.Ext(item.Name()) != PROFILE_FILE_EXT {
+			continue
+		}
+		other, err := decodeProfile(filepath.Join(profilesPath, item.Name()))
+		if err == nil && other.ID != profile.ID && other.Name == profile.Name {
+			return errors.New(locale.T(MsgDaemonProfileNameDuplicateError,
+				struct{ ProfileName string }{ProfileName: profile.Name}))
+		}
+	}
+	// Temporary file extension differ from profile one,
+	// so it is ignored by LoadProfiles.
+	file, err := ioutil.TempFile(profilesPath, profile.ID+".tmp")
+	if err != nil {
+		return err
+	}
+	err = toml.NewEncoder(file).Encode(profile)
+	err2 := file.Close()
+	if err == nil {
+		err = err2
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), filepath.Join(profilesPath, profile.ID+PROFILE_FILE_EXT))
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	return nil
+}
+
+// ReadProfile read profile with specified ID from profiles folder,
+// or return nil, if profile file doesn't exist.
+func ReadProfile(profilesPath, profileID string) (*Profile, error) {
+	profile, err := decodeProfile(filepath.Join(profilesPath, profileID+PROFILE_FILE_EXT))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return profile, err
+}
+
+// decodeProfile read profile from TOML file.
+func decodeProfile(filePath string) (*Profile, error) {
+	profile := &Profile{}
+	_, err := toml.DecodeFile(filePath, profile)
+	if err != nil {
+		return nil, err
+	}
+	if profile.ID == "" {
+		profile.ID = strings.TrimSuffix(filepath.Base(filePath), PROFILE_FILE_EXT)
+	}
+	if profile.Name == "" {
+		profile.Name = profile.ID
+	}
+	return profile, nil
+}
+
+// GetIdentity return profile identity passed to backup engine.
+func (v *Profile) GetIdentity() backup.ProfileIdentity {
+	return backup.ProfileIdentity{ID: v.ID, Name: v.Name}
+}
+
+// loadProfile read and verify profile from TOML file.
+func loadProfile(filePath string) (*Profile, error) {
+	profile, err := decodeProfile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	err = profile.Validate(false).Err()
+	if err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// Validate verify profile configuration. If checkPaths is true,
+// destination paths availability is verified either.
+func (v *Profile) Validate(checkPaths bool) backup.ValidationIssues {
+	return backup.ValidateProfile(v.DestPath, v.Modules, checkPaths)
+}
+
+// LoadProfiles read all profiles located in folder, sorted by name.
+func LoadProfiles(profilesPath string) ([]*Profile, error) {
+	items, err := ioutil.ReadDir(profilesPath)
+	if err != nil {
+		return nil, err
+	}
+	var profiles []*Profile
+	ids := make(map[string]bool)
+	names := make(map[string]bool)
+	for _, item := range items {
+		if item.IsDir() || filepath.Ext(item.Name()) != PROFILE_FILE_EXT {
+			continue
+		}
+		filePath := filepath.Join(profilesPath, item.Name())
+		profile, err := loadProfile(filePath)
+		if err != nil {
+			return nil, errors.New(locale.T(MsgDaemonProfileLoadError,
+				struct {
+					Path  string
+					Error error
+				}{Path: filePath, Error: err}))
+		}
+		if ids[profile.ID] {
+			return nil, errors.New(locale.T(MsgDaemonProfileIDDuplicateError,
+				struct{ ProfileID string }{ProfileID: profile.ID}))
+		}
+		ids[profile.ID] = true
+		if names[profile.Name] {
+			return nil, errors.New(locale.T(MsgDaemonProfileNameDuplicateError,
+				struct{ ProfileName string }{ProfileName: profile.Name}))
+		}
+		names[profile.Name] = true
+		profiles = append(profiles, profile)
+	}
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].Name < profiles[j].Name
+	})
+	return profiles, nil
+}
+
+// ProfileValidation describe profile file verification result.
+type ProfileValidation struct {
+	Profile string                  `json:"profile"`
+	Path    string                  `json:"path"`
+	Error   string                  `json:"error,omitempty"`
+	Issues  backup.ValidationIssues `json:"issues"`
+}
+
+// ValidateProfiles verify all profiles located in folder, including
+// destination paths availability. Unlike LoadProfiles, profile issues
+// do not interrupt verification, but are collected in results.
+func ValidateProfiles(profilesPath string) ([]ProfileValidation, error) {
+	items, err := ioutil.ReadDir(profilesPath)
+	if err != nil {
+		return nil, err
+	}
+	var results []ProfileValidation
+	for _, item := range items {
+		if item.IsDir() || filepath.Ext(item.Name()) != PROFILE_FILE_EXT {
+			continue
+		}
+		filePath := filepath.Join(profilesPath, item.Name())
+		result := ProfileValidation{Path: filePath,
+			Profile: strings.TrimSuffix(item.Name(), PROFILE_FILE_EXT)}
+		profile, err := decodeProfile(filePath)
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Profile = profile.Name
+			result.Issues = profile.Validate(true)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
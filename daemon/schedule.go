@@ -0,0 +1,67 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package daemon
+
+import (
+	"errors"
+	"time"
+
+	"github.com/d2r2/go-rsync/locale"
+)
+
+// Schedule define when profile backup run automatically.
+type Schedule struct {
+	Every time.Duration
+	// Offset from midnight, scheduled backups are aligned to.
+	At *time.Duration
+}
+
+// NewSchedule parse profile schedule settings. Return nil, if
+// schedule is not specified, so backup run on request only.
+func NewSchedule(every, at string) (*Schedule, error) {
+	if every == "" && at == "" {
+		return nil, nil
+	}
+	// Daily backup, if only time of day specified.
+	v := &Schedule{Every: 24 * time.Hour}
+	if every != "" {
+		d, err := time.ParseDuration(every)
+		if err != nil || d < time.Minute {
+			return nil, errors.New(locale.T(MsgDaemonScheduleEveryNotValidError,
+				struct{ Every string }{Every: every}))
+		}
+		v.Every = d
+	}
+	if at != "" {
+		t, err := time.Parse("15:04", at)
+		if err != nil {
+			return nil, errors.New(locale.T(MsgDaemonScheduleAtNotValidError,
+				struct{ At string }{At: at}))
+		}
+		offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+		v.At = &offset
+	}
+	return v, nil
+}
+
+// Next return time of next scheduled backup after specified moment.
+func (v *Schedule) Next(after time.Time) time.Time {
+	if v.At == nil {
+		return after.Add(v.Every)
+	}
+	y, m, d := after.Date()
+	next := time.Date(y, m, d, 0, 0, 0, 0, after.Location()).Add(*v.At)
+	for !next.After(after) {
+		next = next.Add(v.Every)
+	}
+	return next
+}
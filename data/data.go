@@ -9,15 +9,27 @@
 // OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
 //--------------------------------------------------------------------------------------------------
 
-// +build !gorsync_rel
-
 package data
 
 import (
+	"embed"
+	"io/fs"
 	"net/http"
 )
 
+// Files found in "assets" folder are embedded to application binary
+// in any build type, so application doesn't depend on working folder.
+//
+//go:embed assets
+var assets embed.FS
+
 // Assets contains project assets.
-// Depending on "gorsync_rel" build constraint tag take data
-// from file system folder, either from embedded code.
-var Assets http.FileSystem = http.Dir("data/assets")
+var Assets http.FileSystem = http.FS(mustSubFS(assets, "assets"))
+
+func mustSubFS(fsys fs.FS, dir string) fs.FS {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
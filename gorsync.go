@@ -23,15 +23,27 @@ import (
 	"github.com/d2r2/go-rsync/core"
 	"github.com/d2r2/go-rsync/locale"
 	"github.com/d2r2/go-rsync/rsync"
-	"github.com/d2r2/go-rsync/ui/gtkui"
-	"github.com/d2r2/gotk3/libnotify"
 )
 
 const (
 	MsgMainAppSubsystemInitialized = "MainAppSubsystemInitialized"
 	MsgMainAppExitedNormally       = "MainAppExitedNormally"
+	MsgMainAppHeadlessBuild        = "MainAppHeadlessBuild"
+	MsgRsyncInfo                   = "RsyncInfo"
+	MsgGolangInfo                  = "GolangInfo"
 )
 
+// frontend is a user interface implementation, selected at build time,
+// while backup engine is shared. GTK+ 3 frontend (ui/gtkui) is built,
+// unless "gorsync_headless" tag specified, which exclude GUI (daemon
+// mode only).
+type frontend interface {
+	// AddFlags register command line options handled by user interface.
+	AddFlags(fs *flag.FlagSet)
+	// Run user interface main loop until application exit.
+	Run() error
+}
+
 // You can manage verbosity of log output
 // in the package by changing last parameter value
 // (comment/uncomment corresponding lines).
@@ -55,6 +67,11 @@ func main() {
 	core.SetVersion(version)
 	core.SetBuildNum(buildnum)
 
+	// Headless mode without GUI, to run on servers.
+	if len(os.Args) > 1 && os.Args[1] == DAEMON_COMMAND {
+		os.Exit(runDaemon(os.Args[2:]))
+	}
+
 	var cpuprofile string
 	flag.StringVar(&cpuprofile, "cpuprofile", "", `Write cpu profile to "file" for debugging purpose.
 Generate CPU profile for debugging. Use command "go tool pprof --pdf <path to binary exec> ./cpu.pprof > ./profile.pdf"
@@ -65,6 +82,11 @@ Generate memory profile for debugging. Use command "go tool pprof --pdf <path to
 to create memory usage graph in pdf document.`)
 	var versionFlag bool
 	flag.BoolVar(&versionFlag, "version", false, `Print environment and version information.`)
+	var printCommands bool
+	flag.BoolVar(&printCommands, "print-commands", false, `Print each RSYNC command line with environment
+to STDERR before run, for debugging purpose.`)
+	ui := newFrontend()
+	ui.AddFlags(flag.CommandLine)
 
 	flag.Parse()
 
@@ -81,6 +103,21 @@ to create memory usage graph in pdf document.`)
 		defer pprof.StopCPUProfile()
 	}
 
+	// Replace RSYNC calls with simulation, if requested by environment.
+	var executor rsync.Executor = &rsync.SystemExecutor{}
+	simulation, err := rsync.SimulationFromEnv()
+	if err != nil {
+		lg.Fatal(err)
+	}
+	if simulation != nil {
+		lg.Warnf("RSYNC calls are simulated, since %s is set", rsync.SIMULATION_ENV_VAR)
+		executor = simulation
+	}
+	if printCommands {
+		executor = rsync.NewPrintExecutor(executor, os.Stderr)
+	}
+	rsync.SetExecutor(executor)
+
 	// Print application version information.
 	if versionFlag {
 		localizer := locale.CreateLocalizer("EN")
@@ -96,9 +133,9 @@ to create memory usage graph in pdf document.`)
 				lg.Fatal(err)
 			}
 		}
-		b.WriteString("\t" + localizer.Translate(gtkui.MsgRsyncInfo, struct{ RSYNCDetectedVer, RSYNCDetectedProtocol string }{
+		b.WriteString("\t" + localizer.Translate(MsgRsyncInfo, struct{ RSYNCDetectedVer, RSYNCDetectedProtocol string }{
 			RSYNCDetectedVer: version, RSYNCDetectedProtocol: protocol}) + "\n")
-		b.WriteString("\t" + localizer.Translate(gtkui.MsgGolangInfo, struct{ GolangVersion, AppArchitecture string }{
+		b.WriteString("\t" + localizer.Translate(MsgGolangInfo, struct{ GolangVersion, AppArchitecture string }{
 			GolangVersion:   core.GetGolangVersion(),
 			AppArchitecture: core.GetAppArchitecture()}) + "\n")
 		print(b.String())
@@ -109,35 +146,10 @@ to create memory usage graph in pdf document.`)
 	// might be reinitialized from application preferences.
 	locale.SetLanguage("")
 
-	// Initialize libnotify subsystem.
-	err := libnotify.Init(core.GetAppTitle())
+	err = ui.Run()
 	if err != nil {
 		lg.Fatal(err)
 	}
-	lg.Info(locale.T(MsgMainAppSubsystemInitialized,
-		struct{ Subsystem string }{Subsystem: "Libnotify"}))
-
-	for {
-		// Create application.
-		app, err := gtkui.CreateApp()
-		if err != nil {
-			lg.Fatal(err)
-		}
-
-		// Run application.
-		app.Run([]string{})
-
-		// If request was made to reload app, then we re-run app
-		// without exiting (can be used for changing app UI language).
-		if core.GetAppRunMode() == core.AppRegularRun {
-			break
-		} else if core.GetAppRunMode() == core.AppRunReload {
-			core.SetAppRunMode(core.AppRegularRun)
-		}
-	}
-
-	// Uninitialize libnotify subsystem on application exit.
-	libnotify.Uninit()
 
 	// Save memory profile to investigate leaked memory.
 	if memprofile != "" {
@@ -110,13 +110,12 @@ APP_VERSION=$GIT_TAG
 shopt -s nocasematch
 if [[ "$BUILD_TYPE" == "$RELEASE_TYPE" ]]; then
   echo "RELEASE type build in progress..."
-  go run data/generate/generate.go && mv ./assets_vfsdata.go ./data
   # Add extra options here (-s -w), to decrease release binary size, read here https://golang.org/cmd/link/
-  go build -v $RACE -ldflags="-X main.version=$APP_VERSION -X main.buildnum=$(date -u +%Y%m%d%H%M%S) -s -w" -tags "gorsync_rel $BUILD_TAGS" $OUTPUT gorsync.go
+  go build -v $RACE -ldflags="-X main.version=$APP_VERSION -X main.buildnum=$(date -u +%Y%m%d%H%M%S) -s -w" -tags "$BUILD_TAGS" $OUTPUT .
 else
   [[ -z "$BUILD_TYPE" ]] || [[ "$BUILD_TYPE" == "$DEV_TYPE" ]] || echo "WARNING: unknown build type provided: $BUILD_TYPE"
   echo "DEVELOPMENT type build in progress..."
-  go build -v $RACE -ldflags="-X main.version=$APP_VERSION -X main.buildnum=$(date -u +%Y%m%d%H%M%S)" -tags "$BUILD_TAGS" $OUTPUT gorsync.go
+  go build -v $RACE -ldflags="-X main.version=$APP_VERSION -X main.buildnum=$(date -u +%Y%m%d%H%M%S)" -tags "$BUILD_TAGS" $OUTPUT .
 fi
 shopt -u nocasematch
 
@@ -0,0 +1,159 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/d2r2/go-rsync/backup"
+	"github.com/d2r2/go-rsync/core"
+	"github.com/d2r2/go-rsync/daemon"
+	"github.com/d2r2/go-rsync/locale"
+	"github.com/d2r2/go-rsync/rsync"
+)
+
+// DAEMON_COMMAND is a first command line argument,
+// which run application in headless daemon mode.
+const DAEMON_COMMAND = "daemon"
+
+// runDaemon run headless daemon, which start backup of profiles by schedule
+// and listen control socket, either send command to running daemon.
+// Return process exit code.
+func runDaemon(args []string) int {
+	fs := flag.NewFlagSet(DAEMON_COMMAND, flag.ExitOnError)
+	var profilesPath string
+	fs.StringVar(&profilesPath, "profiles", "", `Folder with profile files, $XDG_CONFIG_HOME/gorsync/profiles by default.`)
+	socketPath := daemon.GetControlSocketPath()
+	fs.StringVar(&socketPath, "socket", socketPath, `Control socket path.`)
+	var command string
+	fs.StringVar(&command, "send", "", `Send "start", "stop", "status" or "reload" command to running daemon and exit.`)
+	var profileName string
+	fs.StringVar(&profileName, "profile", "", `Profile name to start or stop with "send" option.`)
+	var tags string
+	fs.StringVar(&tags, "tags", "", `Comma-separated module tags to start backup of tagged modules only.`)
+	var validate bool
+	fs.BoolVar(&validate, "validate", false, `Verify profiles, print issues found in JSON format and exit.
+Exit code is non-zero, if any profile can't be used to run backup.`)
+	var sizeUnits string
+	fs.StringVar(&sizeUnits, "size-units", core.SIZE_UNITS_SI.String(), `Display sizes in decimal ("si": kB, MB, GB)
+or binary ("binary": KiB, MiB, GiB) units.`)
+	var printCommands bool
+	fs.BoolVar(&printCommands, "print-commands", false, `Print each RSYNC command line with environment
+to STDERR before run, for debugging purpose.`)
+	fs.Parse(args)
+
+	// Replace RSYNC calls with simulation, if requested by environment.
+	var executor rsync.Executor = &rsync.SystemExecutor{}
+	simulation, err := rsync.SimulationFromEnv()
+	if err != nil {
+		lg.Error(err)
+		return 1
+	}
+	if simulation != nil {
+		lg.Warnf("RSYNC calls are simulated, since %s is set", rsync.SIMULATION_ENV_VAR)
+		executor = simulation
+	}
+	if printCommands {
+		executor = rsync.NewPrintExecutor(executor, os.Stderr)
+	}
+	rsync.SetExecutor(executor)
+
+	locale.SetLanguage("")
+	core.SetSizeUnits(core.ParseSizeUnits(sizeUnits))
+
+	if command != "" {
+		response, err := daemon.SendControlRequest(socketPath,
+			&daemon.ControlRequest{Command: command, Profile: profileName,
+				Tags: backup.ParseModuleTags(tags)})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if response.Error != "" {
+			fmt.Fprintln(os.Stderr, response.Error)
+			return 1
+		}
+		b, err := json.MarshalIndent(response.Profiles, "", "  ")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Println(string(b))
+		return 0
+	}
+
+	if profilesPath == "" {
+		var err error
+		profilesPath, err = daemon.GetProfilesPath()
+		if err != nil {
+			lg.Fatal(err)
+		}
+	}
+	if validate {
+		results, err := daemon.ValidateProfiles(profilesPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		b, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Println(string(b))
+		for _, result := range results {
+			if result.Error != "" || result.Issues.HasErrors() {
+				return 1
+			}
+		}
+		return 0
+	}
+
+	profiles, err := daemon.LoadProfiles(profilesPath)
+	if err != nil {
+		lg.Error(err)
+		return 1
+	}
+	d, err := daemon.NewDaemon(profiles)
+	if err != nil {
+		lg.Error(err)
+		return 1
+	}
+	d.SetProfilesPath(profilesPath)
+
+	// Stop gracefully on first request from systemd or Ctrl+C,
+	// so running backup sessions complete current folder block.
+	// Terminate them immediately on repeated request.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-interrupt
+		d.Stop()
+		<-interrupt
+		cancel()
+	}()
+
+	err = d.Run(ctx, socketPath)
+	if err != nil {
+		lg.Error(err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,78 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+//go:build !gorsync_headless
+// +build !gorsync_headless
+
+package main
+
+import (
+	"flag"
+	"os"
+
+	"github.com/d2r2/go-rsync/core"
+	"github.com/d2r2/go-rsync/locale"
+	"github.com/d2r2/go-rsync/ui/gtkui"
+	"github.com/d2r2/gotk3/libnotify"
+)
+
+// gtk3Frontend run application with GTK+ 3 user interface.
+type gtk3Frontend struct {
+	// Options forwarded to running application instance.
+	cmdLine gtkui.CommandLine
+}
+
+// Static cast to verify that struct implement specific interface.
+var _ frontend = &gtk3Frontend{}
+
+func newFrontend() frontend {
+	return &gtk3Frontend{}
+}
+
+func (v *gtk3Frontend) AddFlags(fs *flag.FlagSet) {
+	v.cmdLine.AddFlags(fs)
+}
+
+func (v *gtk3Frontend) Run() error {
+	// Initialize libnotify subsystem.
+	err := libnotify.Init(core.GetAppTitle())
+	if err != nil {
+		return err
+	}
+	lg.Info(locale.T(MsgMainAppSubsystemInitialized,
+		struct{ Subsystem string }{Subsystem: "Libnotify"}))
+	// Uninitialize libnotify subsystem on application exit.
+	defer libnotify.Uninit()
+
+	args := append([]string{os.Args[0]}, v.cmdLine.Args()...)
+	for {
+		// Create application.
+		app, err := gtkui.CreateApp()
+		if err != nil {
+			return err
+		}
+
+		// Run application. If application instance is already running,
+		// then command line is forwarded there and new instance exit.
+		app.Run(args)
+		// Command line options should not be repeated on app reload.
+		args = args[:1]
+
+		// If request was made to reload app, then we re-run app
+		// without exiting (can be used for changing app UI language).
+		if core.GetAppRunMode() == core.AppRegularRun {
+			break
+		} else if core.GetAppRunMode() == core.AppRunReload {
+			core.SetAppRunMode(core.AppRegularRun)
+		}
+	}
+	return nil
+}
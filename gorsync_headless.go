@@ -0,0 +1,46 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+//go:build gorsync_headless
+// +build gorsync_headless
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+
+	"github.com/d2r2/go-rsync/locale"
+)
+
+// headlessFrontend is used, when application built without GUI (with
+// "gorsync_headless" tag), to run on servers or ARM boards with no GTK+
+// libraries installed. Only daemon mode is available in such build.
+type headlessFrontend struct {
+}
+
+// Static cast to verify that struct implement specific interface.
+var _ frontend = &headlessFrontend{}
+
+func newFrontend() frontend {
+	return &headlessFrontend{}
+}
+
+func (v *headlessFrontend) AddFlags(fs *flag.FlagSet) {
+}
+
+func (v *headlessFrontend) Run() error {
+	return errors.New(locale.T(MsgMainAppHeadlessBuild,
+		struct{ App, DaemonCommand string }{App: filepath.Base(os.Args[0]),
+			DaemonCommand: DAEMON_COMMAND}))
+}
@@ -21,6 +21,8 @@ import (
 	"github.com/d2r2/go-rsync/data"
 	"github.com/nicksnyder/go-i18n/v2/i18n"
 	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
 )
 
 // Localizer is a stub to get access to *i18n.Localizer.
@@ -30,21 +32,48 @@ type Localizer struct {
 	sync.Mutex
 	syncCalls bool
 	localizer *i18n.Localizer
-	Lang      string
+	// English localizer used when translation
+	// lack plural form required by CLDR rules.
+	fallback *i18n.Localizer
+	// Format numbers according to language conventions.
+	printer     *message.Printer
+	Lang        string
+	RightToLeft bool
 }
 
 // substituteLang change empty language "" with system defined.
 func substituteLang(lang string) string {
 	if lang == "" {
 		lang = os.Getenv("LANG")
-		// remove ".UTF-8" suffix from language if found, as "en-US.UTF-8"
-		if i := strings.Index(lang, ".UTF-8"); i != -1 {
+		// remove encoding and modifier suffixes from language
+		// if found, as "en_US.UTF-8" or "de_DE.utf8@euro"
+		if i := strings.IndexAny(lang, ".@"); i != -1 {
 			lang = lang[:i]
 		}
+		if lang == "C" || lang == "POSIX" {
+			lang = "en"
+		}
 	}
 	return lang
 }
 
+// Scripts written from right to left.
+var rtlScripts = map[string]bool{
+	"Arab": true, "Hebr": true, "Syrc": true, "Thaa": true,
+	"Nkoo": true, "Adlm": true, "Rohg": true, "Mand": true,
+}
+
+// IsRightToLeft verify that language is written from right to left,
+// detecting language script (specified or most likely one).
+func IsRightToLeft(lang string) bool {
+	tag, err := language.Parse(strings.Replace(lang, "_", "-", -1))
+	if err != nil {
+		return false
+	}
+	script, _ := tag.Script()
+	return rtlScripts[script.String()]
+}
+
 // CreateLocalizer create localizer object to generate text messages.
 func CreateLocalizer(lang string) *Localizer {
 	bundle := i18n.NewBundle(language.English)
@@ -56,7 +85,14 @@ func CreateLocalizer(lang string) *Localizer {
 	localizer := i18n.NewLocalizer(bundle, lang)
 	// Test translation
 	// fmt.Println(Localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "HelloWorld"}))
-	v := &Localizer{localizer: localizer, Lang: lang, syncCalls: false}
+	fallback := i18n.NewLocalizer(bundle, language.English.String())
+	// Identify translation actually used, to keep layout
+	// left to right, when English is used as a fallback.
+	matcher := language.NewMatcher(bundle.LanguageTags())
+	tag, _, _ := matcher.Match(language.Make(strings.Replace(lang, "_", "-", -1)))
+	printer := message.NewPrinter(language.Make(strings.Replace(lang, "_", "-", -1)))
+	v := &Localizer{localizer: localizer, fallback: fallback, printer: printer,
+		Lang: lang, RightToLeft: IsRightToLeft(tag.String()), syncCalls: false}
 	return v
 }
 
@@ -83,14 +119,39 @@ func (v *Localizer) TranslatePlural(messageID string, template interface{},
 		defer v.Unlock()
 	}
 
-	// get localized message
-	msg := v.localizer.MustLocalize(&i18n.LocalizeConfig{
+	config := &i18n.LocalizeConfig{
 		MessageID:    messageID,
 		TemplateData: template,
-		PluralCount:  pluralCount})
+		PluralCount:  pluralCount}
+	// get localized message
+	msg, err := v.localizer.Localize(config)
+	if err != nil {
+		// Translation might miss plural category required by
+		// CLDR rules for specific count (fractional numbers
+		// for instance), so use English message instead.
+		lg.Debugf("Can't translate %q with plural count %v: %v",
+			messageID, pluralCount, err)
+		msg = v.fallback.MustLocalize(config)
+	}
 	return msg
 }
 
+// FormatNumber output number with decimal and group separators according
+// to language conventions. Negative fractionDigits keep number as is,
+// otherwise number is rounded to fixed amount of fractional digits.
+func (v *Localizer) FormatNumber(value float64, fractionDigits int) string {
+	if v.syncCalls {
+		v.Lock()
+		defer v.Unlock()
+	}
+
+	var opts []number.Option
+	if fractionDigits >= 0 {
+		opts = append(opts, number.Scale(fractionDigits))
+	}
+	return v.printer.Sprint(number.Decimal(value, opts...))
+}
+
 // GlobalLocalizer is a global variable to translate everything in application
 var GlobalLocalizer *Localizer
 
@@ -117,6 +178,16 @@ var TP = func(messageID string, template interface{}, pluralCount interface{}) s
 	return GlobalLocalizer.TranslatePlural(messageID, template, pluralCount)
 }
 
+// N format number according to application language conventions.
+// Use fractionDigits to round number to fixed amount of fractional digits.
+var N = func(value float64, fractionDigits int) string {
+	// if Localizer isn't initialized, set up with system language
+	if GlobalLocalizer == nil {
+		SetLanguage("")
+	}
+	return GlobalLocalizer.FormatNumber(value, fractionDigits)
+}
+
 func mustParseMessageFile(bundle *i18n.Bundle, assetIconName string) {
 	file, err := data.Assets.Open(assetIconName)
 	if err != nil {
@@ -132,6 +203,16 @@ func mustParseMessageFile(bundle *i18n.Bundle, assetIconName string) {
 	bundle.MustParseMessageFileBytes(buf, assetIconName)
 }
 
+// IsRTL returns true, if application language
+// is written from right to left.
+func IsRTL() bool {
+	// if Localizer isn't initialized, set up with system language
+	if GlobalLocalizer == nil {
+		SetLanguage("")
+	}
+	return GlobalLocalizer.RightToLeft
+}
+
 // SetLanguage set up language globally for application localization.
 func SetLanguage(lang string) {
 	lang = substituteLang(lang)
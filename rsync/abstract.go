@@ -12,6 +12,9 @@
 package rsync
 
 import (
+	"fmt"
+	"time"
+
 	logger "github.com/d2r2/go-logger"
 	"github.com/d2r2/go-rsync/core"
 )
@@ -43,12 +46,21 @@ func NewErrorHook(call ErrorHookCall, predictedSize core.FolderSize) *ErrorHook
 
 // Options keep settings for RSYNC call.
 // Settings include: retry count, parameters, ErrorHook object
-// for recover attempt if issue thrown.
+// for recover attempt if issue thrown, files filter, network watchdog,
+// I/O and connection timeouts, tail of itemized output, process priority,
+// hung process watchdog.
 type Options struct {
-	RetryCount int
-	Params     []string
-	ErrorHook  *ErrorHook
-	Password   *string
+	RetryCount     int
+	Params         []string
+	ErrorHook      *ErrorHook
+	Password       *string
+	Filter         *FileFilter
+	Watchdog       *NetworkWatchdog
+	IOTimeout      time.Duration
+	ConnectTimeout time.Duration
+	Output         *OutputTail
+	Priority       *Priority
+	HangWatchdog   *ProcessWatchdog
 }
 
 func NewOptions(params []string) *Options {
@@ -96,6 +108,63 @@ func (v *Options) SetErrorHook(errorHook *ErrorHook) *Options {
 	return v
 }
 
+// SetFileFilter define files to skip by size and modification age.
+func (v *Options) SetFileFilter(filter *FileFilter) *Options {
+	v.Filter = filter
+	return v
+}
+
+// SetNetworkWatchdog set watchdog, which pause RSYNC
+// calls in case of network outage.
+func (v *Options) SetNetworkWatchdog(watchdog *NetworkWatchdog) *Options {
+	v.Watchdog = watchdog
+	return v
+}
+
+// SetTimeouts set RSYNC I/O timeout (--timeout) and
+// daemon connection timeout (--contimeout). Zero value
+// disable corresponding timeout. Connection timeout
+// is taken into account only for RSYNC daemon sources.
+func (v *Options) SetTimeouts(ioTimeout, connectTimeout time.Duration) *Options {
+	v.IOTimeout = ioTimeout
+	v.ConnectTimeout = connectTimeout
+	return v
+}
+
+// SetOutputTail set buffer to keep itemized output
+// (--itemize-changes) of the most recent RSYNC call.
+func (v *Options) SetOutputTail(output *OutputTail) *Options {
+	v.Output = output
+	return v
+}
+
+// SetPriority set CPU niceness and I/O priority
+// to launch RSYNC process with.
+func (v *Options) SetPriority(priority *Priority) *Options {
+	v.Priority = priority
+	return v
+}
+
+// SetHangWatchdog set watchdog, which kill hung RSYNC process,
+// so call is repeated according to retry count.
+func (v *Options) SetHangWatchdog(watchdog *ProcessWatchdog) *Options {
+	v.HangWatchdog = watchdog
+	return v
+}
+
+// timeoutParams return RSYNC command line options
+// to limit I/O and connection waiting time.
+func (v *Options) timeoutParams(rsyncSourcePath string) []string {
+	var params []string
+	if v.IOTimeout > 0 {
+		params = append(params, fmt.Sprintf("--timeout=%d", int(v.IOTimeout.Seconds())))
+	}
+	if v.ConnectTimeout > 0 && core.IsRsyncDaemonURL(rsyncSourcePath) {
+		params = append(params, fmt.Sprintf("--contimeout=%d", int(v.ConnectTimeout.Seconds())))
+	}
+	return params
+}
+
 // WithDefaultParams return list of obligatory options
 // for each run of RSYNC utility.
 func WithDefaultParams(params []string) []string {
@@ -0,0 +1,371 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package rsync
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	shell "github.com/d2r2/go-shell"
+)
+
+// Capabilities keep RSYNC utility version, protocol and
+// features support, obtained from "rsync --version" output.
+type Capabilities struct {
+	Version  string
+	Protocol string
+	// Parsed version numbers: major, minor, patch.
+	Numbers [3]int
+	// Items from "Capabilities:" section (ACLs, xattrs, iconv, prealloc...).
+	Features []string
+	// Items from "Compress list:" section (zstd, lz4, zlibx, zlib...).
+	// For RSYNC releases before 3.2.0 only zlib is supported.
+	Compressions []string
+	// Items from "Checksum list:" section.
+	Checksums []string
+}
+
+// VersionAtLeast verify that RSYNC version is equal or greater than specified.
+func (v *Capabilities) VersionAtLeast(major, minor, patch int) bool {
+	required := [3]int{major, minor, patch}
+	for i := range required {
+		if v.Numbers[i] != required[i] {
+			return v.Numbers[i] > required[i]
+		}
+	}
+	return true
+}
+
+// HasFeature verify that feature is found in "Capabilities:" section
+// (case insensitive). Feature might be prefixed with "optional".
+func (v *Capabilities) HasFeature(feature string) bool {
+	for _, item := range v.Features {
+		item = strings.TrimPrefix(item, "optional ")
+		if strings.EqualFold(item, feature) {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportsCompression verify that compression algorithm is supported.
+func (v *Capabilities) SupportsCompression(name string) bool {
+	for _, item := range v.Compressions {
+		if strings.EqualFold(item, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportsACLs verify that RSYNC is built with ACLs support (--acls option).
+func (v *Capabilities) SupportsACLs() bool {
+	return v.HasFeature("ACLs")
+}
+
+// SupportsXattrs verify that RSYNC is built with extended
+// attributes support (--xattrs option).
+func (v *Capabilities) SupportsXattrs() bool {
+	return v.HasFeature("xattrs")
+}
+
+// SupportsSparseInplace verify that RSYNC allow to combine
+// --sparse with --inplace options, which is the case since 3.1.3.
+func (v *Capabilities) SupportsSparseInplace() bool {
+	return v.VersionAtLeast(3, 1, 3)
+}
+
+// SupportsInfoProgress2 verify that "--info=progress2" option is available,
+// which implemented since RSYNC 3.1.0.
+func (v *Capabilities) SupportsInfoProgress2() bool {
+	return v.VersionAtLeast(3, 1, 0)
+}
+
+// Compression algorithms, which might be specified
+// with "--compress-choice" option.
+const (
+	COMPRESS_CHOICE_AUTO = "auto"
+	COMPRESS_CHOICE_ZLIB = "zlib"
+	COMPRESS_CHOICE_ZSTD = "zstd"
+	COMPRESS_CHOICE_LZ4  = "lz4"
+)
+
+// compressLevelRange return valid compression level range
+// for algorithm. Return false, if algorithm has no levels.
+func compressLevelRange(choice string) (min, max int, ok bool) {
+	switch choice {
+	case COMPRESS_CHOICE_ZSTD:
+		return 1, 22, true
+	case COMPRESS_CHOICE_LZ4:
+		return 0, 0, false
+	default:
+		return 1, 9, true
+	}
+}
+
+// CompressParams build compression options compatible with installed
+// RSYNC release. Empty choice or "auto" let RSYNC negotiate algorithm itself.
+// Level equal to 0 keep algorithm default level. If algorithm is not supported,
+// option is dropped and level is limited to classic zlib range.
+func (v *Capabilities) CompressParams(choice string, level int) []string {
+	params := []string{"--compress"}
+	if choice == COMPRESS_CHOICE_AUTO {
+		choice = ""
+	}
+	if choice != "" {
+		if v.VersionAtLeast(3, 2, 0) && v.SupportsCompression(choice) {
+			params = append(params, fmt.Sprintf("--compress-choice=%s", choice))
+		} else {
+			lg.Debugf("RSYNC compression %q is not supported by RSYNC %s", choice, v.Version)
+			choice = ""
+		}
+	}
+	if level > 0 {
+		if min, max, ok := compressLevelRange(choice); ok {
+			if level < min {
+				level = min
+			} else if level > max {
+				level = max
+			}
+			params = append(params, fmt.Sprintf("--compress-level=%d", level))
+		}
+	}
+	return params
+}
+
+// paramRequirement describe minimal conditions to use RSYNC option.
+// Fallback contains compatible replacement for the option,
+// or empty string, if option should be dropped.
+type paramRequirement struct {
+	Prefix   string
+	Version  [3]int
+	Feature  string
+	Fallback string
+}
+
+// Options, which are not supported by all RSYNC releases.
+var paramRequirements = []paramRequirement{
+	{Prefix: "--info=progress2", Version: [3]int{3, 1, 0}, Fallback: "--progress"},
+	{Prefix: "--info=", Version: [3]int{3, 1, 0}},
+	{Prefix: "--debug=", Version: [3]int{3, 1, 0}},
+	{Prefix: "--compress-choice=", Version: [3]int{3, 2, 0}},
+	{Prefix: "--checksum-choice=", Version: [3]int{3, 2, 0}},
+	{Prefix: "--chmod=", Version: [3]int{2, 6, 7}},
+	{Prefix: "--usermap=", Version: [3]int{3, 1, 0}},
+	{Prefix: "--groupmap=", Version: [3]int{3, 1, 0}},
+	{Prefix: "--chown=", Version: [3]int{3, 1, 0}},
+	{Prefix: "--acls", Feature: "ACLs"},
+	{Prefix: "--xattrs", Feature: "xattrs"},
+	{Prefix: "--preallocate", Feature: "prealloc"},
+	{Prefix: "--iconv=", Feature: "iconv"},
+	{Prefix: "--protect-args", Version: [3]int{3, 0, 0}},
+}
+
+// isSupported verify that option meets requirement.
+func (v *Capabilities) isSupported(req paramRequirement) bool {
+	if !v.VersionAtLeast(req.Version[0], req.Version[1], req.Version[2]) {
+		return false
+	}
+	if req.Feature != "" && !v.HasFeature(req.Feature) {
+		return false
+	}
+	return true
+}
+
+// AdjustParams replace or drop RSYNC options which are not
+// supported by installed RSYNC release. Compression algorithm
+// specified via "--compress-choice" is verified against compress list.
+func (v *Capabilities) AdjustParams(params []string) []string {
+	var adjusted []string
+	for _, param := range params {
+		keep := true
+		for _, req := range paramRequirements {
+			if strings.HasPrefix(param, req.Prefix) {
+				if !v.isSupported(req) {
+					keep = false
+					if req.Fallback != "" && !containsParam(adjusted, req.Fallback) &&
+						!containsParam(params, req.Fallback) {
+						adjusted = append(adjusted, req.Fallback)
+					}
+					lg.Debugf("RSYNC option %q is not supported by RSYNC %s, replace with %q",
+						param, v.Version, req.Fallback)
+				}
+				break
+			}
+		}
+		if keep && strings.HasPrefix(param, "--compress-choice=") {
+			if !v.SupportsCompression(strings.TrimPrefix(param, "--compress-choice=")) {
+				keep = false
+				lg.Debugf("RSYNC compression %q is not supported by RSYNC %s", param, v.Version)
+			}
+		}
+		if keep {
+			adjusted = append(adjusted, param)
+		}
+	}
+	return adjusted
+}
+
+// containsParam verify that option is found in the list.
+func containsParam(params []string, param string) bool {
+	for _, item := range params {
+		if item == param {
+			return true
+		}
+	}
+	return false
+}
+
+// parseCapabilities decode "rsync --version" output.
+func parseCapabilities(stdOut *bytes.Buffer) (*Capabilities, error) {
+	v := &Capabilities{}
+	scanner := bufio.NewScanner(stdOut)
+	scanner.Split(bufio.ScanLines)
+	var section *[]string
+	for scanner.Scan() {
+		line := scanner.Text()
+		if v.Version == "" {
+			v.Version, v.Protocol = parseVersionAndProtocol(line)
+			continue
+		}
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "Capabilities:"):
+			section = &v.Features
+		case strings.HasPrefix(trimmed, "Compress list:"):
+			section = &v.Compressions
+		case strings.HasPrefix(trimmed, "Checksum list:"):
+			section = &v.Checksums
+		case trimmed == "" || !strings.HasPrefix(line, " "):
+			// any non-indented line close section
+			section = nil
+		case section == &v.Features:
+			for _, item := range strings.Split(trimmed, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					v.Features = append(v.Features, item)
+				}
+			}
+		case section != nil:
+			for _, item := range strings.Fields(trimmed) {
+				// skip implementation details in brackets, like "(xxhash)"
+				if !strings.HasPrefix(item, "(") {
+					*section = append(*section, item)
+				}
+			}
+		}
+	}
+	// Extracted RSYNC version cannot be empty.
+	if v.Version == "" {
+		// Return error which should be treated as a warning in the main,
+		// when RSYNC version (and protocol) is undetected for some reason.
+		return nil, &ExtractVersionAndProtocolError{}
+	}
+	for i, item := range strings.SplitN(v.Version, ".", 3) {
+		v.Numbers[i], _ = strconv.Atoi(item)
+	}
+	if len(v.Compressions) == 0 {
+		v.Compressions = []string{"zlib"}
+	}
+	return v, nil
+}
+
+var (
+	capabilitiesLock sync.Mutex
+	capabilities     *Capabilities
+)
+
+// GetCapabilities run "rsync --version" once and return
+// cached RSYNC utility capabilities.
+func GetCapabilities() (*Capabilities, error) {
+	capabilitiesLock.Lock()
+	defer capabilitiesLock.Unlock()
+
+	if capabilities == nil && isSimulation() {
+		caps, err := getSimulationCapabilities()
+		if err != nil {
+			return nil, err
+		}
+		capabilities = caps
+	}
+	if capabilities == nil {
+		app := shell.NewApp(RSYNC_APP_CMD, "--version")
+		var stdOut, stdErr bytes.Buffer
+		exitCode := app.Run(&stdOut, &stdErr)
+		if exitCode.Error != nil {
+			return nil, exitCode.Error
+		}
+		caps, err := parseCapabilities(&stdOut)
+		if err != nil {
+			return nil, err
+		}
+		capabilities = caps
+	}
+	return capabilities, nil
+}
+
+// ParseCapabilities build RSYNC capabilities from "rsync --version" output.
+func ParseCapabilities(versionOutput string) (*Capabilities, error) {
+	return parseCapabilities(bytes.NewBufferString(versionOutput))
+}
+
+// SetCapabilities replace cached RSYNC utility capabilities, so RSYNC
+// options are built for specific RSYNC release, rather than installed one.
+// Specify nil to detect capabilities again with following call.
+func SetCapabilities(caps *Capabilities) {
+	capabilitiesLock.Lock()
+	defer capabilitiesLock.Unlock()
+	capabilities = caps
+}
+
+// AdjustParamsToCapabilities adapt RSYNC options to installed RSYNC release.
+// If RSYNC capabilities can't be identified, return options unchanged.
+func AdjustParamsToCapabilities(params []string) []string {
+	caps, err := GetCapabilities()
+	if err != nil {
+		lg.Debugf("Can't identify RSYNC capabilities: %v", err)
+		return params
+	}
+	return caps.AdjustParams(params)
+}
+
+// PARTIAL_DIR is a folder, where RSYNC keep partially transferred files,
+// to resume interrupted transfer of large files instead of restarting it.
+// Relative path is created in each destination folder.
+const PARTIAL_DIR = ".rsync-partial"
+
+// GetPartialParams return options to keep partially transferred files.
+func GetPartialParams() []string {
+	return []string{"--partial", "--partial-dir=" + PARTIAL_DIR}
+}
+
+// GetIconvParams return options to convert file names from charset
+// of RSYNC source to UTF-8. Protected args let RSYNC convert
+// source path specified in command line as well.
+func GetIconvParams(sourceCharset string) []string {
+	return []string{"--iconv=UTF-8," + sourceCharset, "--protect-args"}
+}
+
+// GetCompressParams build compression options compatible with installed
+// RSYNC release. If RSYNC capabilities can't be identified,
+// return classic "--compress" option only.
+func GetCompressParams(choice string, level int) []string {
+	caps, err := GetCapabilities()
+	if err != nil {
+		lg.Debugf("Can't identify RSYNC capabilities: %v", err)
+		return []string{"--compress"}
+	}
+	return caps.CompressParams(choice, level)
+}
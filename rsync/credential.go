@@ -0,0 +1,120 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package rsync
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/d2r2/go-rsync/core"
+)
+
+// HostCredential keep authentication settings shared by all
+// RSYNC sources located on the same host.
+type HostCredential struct {
+	Host string
+	// User name, used if not specified in source path.
+	User string
+	// Password for RSYNC daemon modules.
+	Password string
+	// Private key file for SSH connections.
+	KeyPath string
+}
+
+// Credentials applied to all RSYNC calls, where
+// source path refer to corresponding host.
+var (
+	credentialsMutex sync.Mutex
+	credentials      []HostCredential
+)
+
+// SetHostCredentials replace list of host credentials.
+func SetHostCredentials(list []HostCredential) {
+	credentialsMutex.Lock()
+	defer credentialsMutex.Unlock()
+	credentials = append([]HostCredential{}, list...)
+}
+
+// FindHostCredential return credential of the host, which RSYNC path
+// refer to (either RSYNC daemon, or remote shell), if any.
+func FindHostCredential(rsyncPath string) *HostCredential {
+	var host string
+	if core.IsRsyncDaemonURL(rsyncPath) {
+		url, err := core.ParseRsyncURL(rsyncPath)
+		if err != nil {
+			return nil
+		}
+		host = url.Host
+	} else if shellHost, _, ok := SplitRemoteShellPath(rsyncPath); ok {
+		host = shellHost
+	} else {
+		return nil
+	}
+	return findCredentialByHost(host)
+}
+
+// findCredentialByHost return credential of the host specified
+// in "[user@]host" notation, if any.
+func findCredentialByHost(host string) *HostCredential {
+	if i := strings.Index(host, "@"); i >= 0 {
+		host = host[i+1:]
+	}
+	credentialsMutex.Lock()
+	defer credentialsMutex.Unlock()
+	for _, item := range credentials {
+		if strings.EqualFold(strings.TrimSpace(item.Host), host) {
+			cred := item
+			return &cred
+		}
+	}
+	return nil
+}
+
+// applyHostCredential complete RSYNC daemon source path with user name
+// and substitute password, if they are not specified at module level.
+func applyHostCredential(rsyncSourcePath string, password *string) (string, *string) {
+	if !core.IsRsyncDaemonURL(rsyncSourcePath) {
+		return rsyncSourcePath, password
+	}
+	cred := FindHostCredential(rsyncSourcePath)
+	if cred == nil {
+		return rsyncSourcePath, password
+	}
+	if (password == nil || *password == "") && cred.Password != "" {
+		password = &cred.Password
+	}
+	if cred.User != "" {
+		url, err := core.ParseRsyncURL(rsyncSourcePath)
+		if err == nil && url.User == "" {
+			url.User = cred.User
+			rsyncSourcePath = url.String()
+		}
+	}
+	return rsyncSourcePath, password
+}
+
+// sshCredentialOptions return SSH client options to login
+// with user name and private key specified by host credential.
+func sshCredentialOptions(host string) []string {
+	cred := findCredentialByHost(host)
+	if cred == nil {
+		return nil
+	}
+	var options []string
+	if cred.User != "" && !strings.Contains(host, "@") {
+		options = append(options, "-l", cred.User)
+	}
+	if cred.KeyPath != "" {
+		options = append(options, "-i", cred.KeyPath, "-o", "IdentitiesOnly=yes")
+	}
+	return options
+}
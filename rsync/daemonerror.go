@@ -0,0 +1,138 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package rsync
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/d2r2/go-rsync/locale"
+)
+
+// DaemonErrorKind classify errors reported by RSYNC daemon,
+// when it refuse to serve module or path requested.
+type DaemonErrorKind int
+
+const (
+	// Error not recognized, see daemon message
+	DE_OTHER DaemonErrorKind = iota
+	// Module not found in daemon configuration
+	DE_UNKNOWN_MODULE
+	// Host not allowed by "hosts allow" and "hosts deny" options
+	DE_ACCESS_DENIED
+	// Wrong user name or password
+	DE_AUTH_FAILED
+	// "max connections" limit reached
+	DE_MAX_CONNECTIONS
+	// Module configured with "read only" or "write only" options
+	DE_READ_ONLY
+	// Option prohibited by "refuse options"
+	DE_OPTION_REFUSED
+	// Path excluded by "filter", "exclude" or "include" options
+	DE_FILTERED
+)
+
+// Maximum number of daemon-excluded paths kept in DaemonError.
+const maxDaemonExcludedPaths = 10
+
+// daemonErrorKinds describe how to recognize daemon errors in RSYNC STDERR
+// output. Patterns are verified in the order specified.
+var daemonErrorKinds = []struct {
+	kind        DaemonErrorKind
+	pattern     *regexp.Regexp
+	explanation string
+	suggestion  string
+}{
+	{DE_UNKNOWN_MODULE, regexp.MustCompile(`(?m)^@ERROR:\s*Unknown module.*$`),
+		MsgRsyncDaemonErrorUnknownModule, MsgRsyncErrorSuggestionDaemonUnknownModule},
+	{DE_ACCESS_DENIED, regexp.MustCompile(`(?m)^@ERROR:\s*access denied to .*$`),
+		MsgRsyncDaemonErrorAccessDenied, MsgRsyncErrorSuggestionDaemonAccessDenied},
+	{DE_AUTH_FAILED, regexp.MustCompile(`(?m)^@ERROR:\s*auth failed on module.*$`),
+		MsgRsyncDaemonErrorAuthFailed, MsgRsyncErrorSuggestionDaemonAuthFailed},
+	{DE_MAX_CONNECTIONS, regexp.MustCompile(`(?m)^@ERROR:\s*max connections .*$`),
+		MsgRsyncDaemonErrorMaxConnections, MsgRsyncErrorSuggestionDaemonMaxConnections},
+	{DE_READ_ONLY, regexp.MustCompile(`(?m)^.*ERROR:\s*module is (?:read|write) only.*$`),
+		MsgRsyncDaemonErrorReadOnly, MsgRsyncErrorSuggestionDaemonConfig},
+	{DE_OPTION_REFUSED, regexp.MustCompile(`(?m)^.*configured to refuse .*$`),
+		MsgRsyncDaemonErrorOptionRefused, MsgRsyncErrorSuggestionDaemonOptionRefused},
+	{DE_FILTERED, regexp.MustCompile(`(?m)^.*(?:skipping daemon-excluded|daemon refused to receive) \S+ "(.*)".*$`),
+		MsgRsyncDaemonErrorFiltered, MsgRsyncErrorSuggestionDaemonConfig},
+	{DE_OTHER, regexp.MustCompile(`(?m)^@ERROR:.*$`),
+		MsgRsyncDaemonErrorOther, ""},
+}
+
+// DaemonError denote a situation when RSYNC daemon
+// refused request due to its own configuration.
+type DaemonError struct {
+	Kind DaemonErrorKind
+	// Message as reported by daemon.
+	Message string
+	// Paths excluded by daemon filters, if any.
+	Paths []string
+}
+
+// parseDaemonError find error reported by RSYNC daemon
+// in STDERR output, return nil if not found.
+func parseDaemonError(stdErr string) *DaemonError {
+	for _, item := range daemonErrorKinds {
+		m := item.pattern.FindAllStringSubmatch(stdErr, -1)
+		if len(m) == 0 {
+			continue
+		}
+		message := strings.TrimSpace(m[0][0])
+		message = strings.TrimSpace(strings.TrimPrefix(message, "@ERROR:"))
+		v := &DaemonError{Kind: item.kind, Message: message}
+		if item.kind == DE_FILTERED {
+			for i := 0; i < len(m) && i < maxDaemonExcludedPaths; i++ {
+				v.Paths = append(v.Paths, m[i][1])
+			}
+		}
+		return v
+	}
+	return nil
+}
+
+// Explanation return localized description of the daemon error.
+func (v *DaemonError) Explanation() string {
+	for _, item := range daemonErrorKinds {
+		if item.kind == v.Kind {
+			return locale.T(item.explanation, nil)
+		}
+	}
+	return ""
+}
+
+// Suggestion return localized description of action, which might
+// fix the issue, or empty string if nothing to suggest.
+func (v *DaemonError) Suggestion() string {
+	for _, item := range daemonErrorKinds {
+		if item.kind == v.Kind && item.suggestion != "" {
+			return locale.T(item.suggestion, nil)
+		}
+	}
+	return ""
+}
+
+func (v *DaemonError) Error() string {
+	return locale.T(MsgRsyncDaemonError,
+		struct{ Explanation, Message string }{Explanation: v.Explanation(),
+			Message: v.Message})
+}
+
+// GetDaemonError return error reported by RSYNC daemon,
+// if error is a failed RSYNC call, or nil otherwise.
+func GetDaemonError(err error) *DaemonError {
+	if IsCallFailedError(err) {
+		return err.(*CallFailedError).Daemon
+	}
+	return nil
+}
@@ -13,10 +13,7 @@ package rsync
 
 import (
 	"bytes"
-	"regexp"
-	"strings"
 
-	"github.com/d2r2/go-rsync/core"
 	"github.com/d2r2/go-rsync/locale"
 )
 
@@ -43,39 +40,23 @@ func IsProcessTerminatedError(err error) bool {
 type CallFailedError struct {
 	ExitCode    int
 	Description string
-}
-
-// extractError used to extract textual description of error
-// which improve understanding of error root cause.
-func extractError(stdErr *bytes.Buffer) string {
-	var descr string
-	buf := stdErr.String()
-	re := regexp.MustCompile(`(?m:^@ERROR:(?P<error>.*)$)`)
-	m := core.FindStringSubmatchIndexes(re, buf)
-	if len(m) > 0 {
-		grErr := "error"
-		if _, ok := m[grErr]; ok {
-			start := m[grErr][0]
-			end := m[grErr][1]
-			descr = strings.TrimSpace(buf[start:end])
-		}
-	}
-	return descr
+	// Error reported by RSYNC daemon, if any.
+	Daemon *DaemonError
 }
 
 // NewCallFailedError creates error object based on ExitCode from RSYNC.
 // Use STDERR variable to extract more human readable error description.
 func NewCallFailedError(exitCode int, stdErr *bytes.Buffer) *CallFailedError {
-	descr := extractError(stdErr)
-	if descr != "" {
-		descr += ", " + getRsyncExitCodeDesc(exitCode)
-	} else {
-		descr = getRsyncExitCodeDesc(exitCode)
+	daemon := parseDaemonError(stdErr.String())
+	descr := getRsyncExitCodeDesc(exitCode)
+	if daemon != nil {
+		descr = daemon.Error() + ", " + descr
 	}
 
 	v := &CallFailedError{
 		ExitCode:    exitCode,
 		Description: descr,
+		Daemon:      daemon,
 	}
 	return v
 }
@@ -98,39 +79,137 @@ func IsCallFailedError(err error) bool {
 	return false
 }
 
-// GetRsyncExitCodeDesc return RSYNC exit code descriptions
+// Localized RSYNC exit code descriptions
 // taken from here: http://wpkg.org/Rsync_exit_codes
+var exitCodeDescriptions = map[int]string{
+	0:   MsgRsyncExitCodeSuccess,
+	1:   MsgRsyncExitCodeSyntaxOrUsageError,
+	2:   MsgRsyncExitCodeProtocolIncompatibility,
+	3:   MsgRsyncExitCodeSelectingFilesError,
+	4:   MsgRsyncExitCodeActionNotSupported,
+	5:   MsgRsyncExitCodeStartingProtocolError,
+	6:   MsgRsyncExitCodeDaemonLogFileError,
+	10:  MsgRsyncExitCodeSocketIOError,
+	11:  MsgRsyncExitCodeFileIOError,
+	12:  MsgRsyncExitCodeProtocolDataStreamError,
+	13:  MsgRsyncExitCodeProgramDiagnosticsError,
+	14:  MsgRsyncExitCodeIPCError,
+	20:  MsgRsyncExitCodeSignalReceived,
+	21:  MsgRsyncExitCodeWaitpidError,
+	22:  MsgRsyncExitCodeMemoryAllocationError,
+	23:  MsgRsyncExitCodePartialTransfer,
+	24:  MsgRsyncExitCodeVanishedSourceFiles,
+	25:  MsgRsyncExitCodeMaxDeleteLimit,
+	30:  MsgRsyncExitCodeDataTimeout,
+	35:  MsgRsyncExitCodeDaemonConnectionTimeout,
+	255: MsgRsyncExitCodeUnexplainedError,
+}
+
+// getRsyncExitCodeDesc return localized RSYNC exit code description.
 func getRsyncExitCodeDesc(exitCode int) string {
-	codes := map[int]string{
-		0: "success",
-		1: "syntax or usage error",
-		2: "protocol incompatibility",
-		3: "errors selecting input/output files, dirs",
-		4: "requested action not supported: an attempt was made to manipulate " +
-			"64-bit files on a platform that cannot support them; or an option was " +
-			"specified that is supported by the client and not by the server",
-		5:   "error starting client-server protocol",
-		6:   "daemon unable to append to log-file",
-		10:  "error in socket I/O",
-		11:  "error in file I/O",
-		12:  "error in rsync protocol data stream",
-		13:  "errors with program diagnostics",
-		14:  "error in IPC code",
-		20:  "received SIGUSR1 or SIGINT",
-		21:  "some error returned by waitpid()",
-		22:  "error allocating core memory buffers",
-		23:  "partial transfer due to error",
-		24:  "partial transfer due to vanished source files",
-		25:  "the --max-delete limit stopped deletions",
-		30:  "timeout in data send/receive",
-		35:  "timeout waiting for daemon connection",
-		255: "unexplained error",
-	}
-	if v, ok := codes[exitCode]; ok {
-		return v
+	if v, ok := exitCodeDescriptions[exitCode]; ok {
+		return locale.T(v, nil)
 	} else {
-		return f("Undefined rsync exit code: %d", exitCode)
+		return locale.T(MsgRsyncExitCodeUndefined,
+			struct{ ExitCode int }{ExitCode: exitCode})
+	}
+}
+
+// ErrorClass group RSYNC exit codes by the root cause,
+// which define action to take to fix the issue.
+type ErrorClass int
+
+const (
+	EC_UNKNOWN ErrorClass = iota
+	// Wrong options or incompatible RSYNC versions
+	EC_USAGE
+	// Network or RSYNC daemon connection issues
+	EC_CONNECTION
+	// Source or destination files can't be read or written
+	EC_FILE_ACCESS
+	// Source files changed during transfer
+	EC_VANISHED_FILES
+	// RSYNC stopped by signal
+	EC_INTERRUPTED
+	// RSYNC internal issues and resource exhaustion
+	EC_INTERNAL
+	// RSYNC daemon refused request due to its configuration
+	EC_DAEMON_REFUSED
+)
+
+// GetErrorClass identify error class by RSYNC exit code.
+func GetErrorClass(exitCode int) ErrorClass {
+	switch exitCode {
+	case 1, 2, 4:
+		return EC_USAGE
+	case 5, 10, 12, 30, 35:
+		return EC_CONNECTION
+	case 3, 11, 23:
+		return EC_FILE_ACCESS
+	case 24:
+		return EC_VANISHED_FILES
+	case 20:
+		return EC_INTERRUPTED
+	case 6, 13, 14, 21, 22, 25, 255:
+		return EC_INTERNAL
+	default:
+		return EC_UNKNOWN
+	}
+}
+
+// Class return error class identified by RSYNC daemon
+// error, if any, or by RSYNC exit code otherwise.
+func (v *CallFailedError) Class() ErrorClass {
+	if v.Daemon != nil {
+		switch v.Daemon.Kind {
+		case DE_UNKNOWN_MODULE, DE_ACCESS_DENIED, DE_AUTH_FAILED,
+			DE_READ_ONLY, DE_OPTION_REFUSED, DE_FILTERED:
+			return EC_DAEMON_REFUSED
+		}
+	}
+	return GetErrorClass(v.ExitCode)
+}
+
+// Suggestion return localized description of action, which might
+// fix the issue, or empty string if nothing to suggest.
+func (v *CallFailedError) Suggestion() string {
+	if v.Daemon != nil {
+		if suggestion := v.Daemon.Suggestion(); suggestion != "" {
+			return suggestion
+		}
+	}
+	suggestions := map[ErrorClass]string{
+		EC_USAGE:          MsgRsyncErrorSuggestionUsage,
+		EC_CONNECTION:     MsgRsyncErrorSuggestionConnection,
+		EC_FILE_ACCESS:    MsgRsyncErrorSuggestionFileAccess,
+		EC_VANISHED_FILES: MsgRsyncErrorSuggestionVanishedFiles,
+		EC_INTERRUPTED:    MsgRsyncErrorSuggestionInterrupted,
+		EC_INTERNAL:       MsgRsyncErrorSuggestionInternal,
+	}
+	if v, ok := suggestions[v.Class()]; ok {
+		return locale.T(v, nil)
+	}
+	return ""
+}
+
+// GetErrorSuggestion return localized suggested action,
+// if error is a failed RSYNC call, or empty string otherwise.
+func GetErrorSuggestion(err error) string {
+	if IsCallFailedError(err) {
+		return err.(*CallFailedError).Suggestion()
+	}
+	return ""
+}
+
+// FormatErrorWithSuggestion return error description followed
+// by suggested action on the next line, if any.
+func FormatErrorWithSuggestion(err error) string {
+	str := err.Error()
+	if suggestion := GetErrorSuggestion(err); suggestion != "" {
+		str += "\n" + locale.T(MsgRsyncErrorSuggestion,
+			struct{ Suggestion string }{Suggestion: suggestion})
 	}
+	return str
 }
 
 // ExtractVersionAndProtocolError denote a situation when attempt
@@ -0,0 +1,156 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package rsync
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FileFilter describe files to skip by patterns, size and modification age.
+// Patterns are mapped to RSYNC --exclude options, size limit is mapped
+// to RSYNC --max-size option. RSYNC has no
+// options to filter by modification time, so age limits work
+// in find-style: source folder is scanned and files out of age
+// range are passed to RSYNC via --exclude-from list. Thus age
+// limits are applicable to local sources only.
+type FileFilter struct {
+	// Skip files larger than specified size in MB, 0 to disable.
+	MaxSizeMb int
+	// Skip files older than specified number of days, 0 to disable.
+	MaxAgeDays int
+	// Skip files newer than specified number of days, 0 to disable.
+	MinAgeDays int
+	// Skip files and folders matching RSYNC exclude patterns.
+	Excludes []string
+}
+
+// IsEmpty returns true, if no any limit specified.
+func (v *FileFilter) IsEmpty() bool {
+	return v == nil || v.MaxSizeMb <= 0 && v.MaxAgeDays <= 0 && v.MinAgeDays <= 0 &&
+		len(v.Excludes) == 0
+}
+
+// HasAgeLimits returns true, if any age limit specified.
+func (v *FileFilter) HasAgeLimits() bool {
+	return v != nil && (v.MaxAgeDays > 0 || v.MinAgeDays > 0)
+}
+
+// IsLocalSource returns true, if RSYNC source is a path
+// in local file system, rather than remote module or host.
+func IsLocalSource(rsyncSourcePath string) bool {
+	return strings.HasPrefix(rsyncSourcePath, "/")
+}
+
+// isOutOfAge verify that file modification time is out of age limits.
+func (v *FileFilter) isOutOfAge(modTime, now time.Time) bool {
+	const day = 24 * time.Hour
+	age := now.Sub(modTime)
+	if v.MaxAgeDays > 0 && age > time.Duration(v.MaxAgeDays)*day {
+		return true
+	}
+	if v.MinAgeDays > 0 && age < time.Duration(v.MinAgeDays)*day {
+		return true
+	}
+	return false
+}
+
+// writeAgeExcludeList scan local source folder and save to temporary file
+// list of files, which are out of age limits. Each path in the list
+// is anchored to the transfer root. Return empty string if nothing found.
+func (v *FileFilter) writeAgeExcludeList(sourcePath string) (string, error) {
+	now := time.Now()
+	var excludes []string
+	err := filepath.Walk(sourcePath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			// Skip unreadable items, RSYNC will report them itself.
+			if info != nil && info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() || !v.isOutOfAge(info.ModTime(), now) {
+			return nil
+		}
+		rel, err := filepath.Rel(sourcePath, path)
+		if err != nil {
+			return err
+		}
+		excludes = append(excludes, "/"+filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil || len(excludes) == 0 {
+		return "", err
+	}
+
+	file, err := ioutil.TempFile("", "backup_age_exclude_")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	writer := bufio.NewWriter(file)
+	for _, item := range excludes {
+		// Escape wildcard characters to match file name literally. RSYNC treat
+		// backslash as escape character only when pattern contains wildcards.
+		if strings.ContainsAny(item, "*?[") {
+			item = strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`).Replace(item)
+		}
+		_, err = writer.WriteString(item + "\n")
+		if err != nil {
+			os.Remove(file.Name())
+			return "", err
+		}
+	}
+	err = writer.Flush()
+	if err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+	return file.Name(), nil
+}
+
+// Params build RSYNC options for specific source path. Release function
+// should be called to remove temporary files, once RSYNC process completed.
+func (v *FileFilter) Params(rsyncSourcePath string) (params []string, release func(), err error) {
+	release = func() {}
+	if v.IsEmpty() {
+		return nil, release, nil
+	}
+	if v.MaxSizeMb > 0 {
+		params = append(params, fmt.Sprintf("--max-size=%dM", v.MaxSizeMb))
+	}
+	for _, pattern := range v.Excludes {
+		params = append(params, fmt.Sprintf("--exclude=%s", pattern))
+	}
+	if v.HasAgeLimits() {
+		if IsLocalSource(rsyncSourcePath) {
+			fileName, err := v.writeAgeExcludeList(rsyncSourcePath)
+			if err != nil {
+				return nil, release, err
+			}
+			if fileName != "" {
+				params = append(params, fmt.Sprintf("--exclude-from=%s", fileName))
+				release = func() {
+					os.Remove(fileName)
+				}
+			}
+		} else {
+			lg.Debugf("Skip age limits for non-local source %q", rsyncSourcePath)
+		}
+	}
+	return params, release, nil
+}
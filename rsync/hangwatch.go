@@ -0,0 +1,158 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package rsync
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/d2r2/go-rsync/core"
+	"github.com/d2r2/go-rsync/locale"
+)
+
+const (
+	// Interval between verifications that RSYNC process is alive.
+	PROCESS_CHECK_INTERVAL = 15 * time.Second
+	// Number of the most recent output lines
+	// kept for diagnostic snapshot of hung process.
+	PROCESS_SNAPSHOT_LINES = 10
+)
+
+// ProcessHung is a call-back function, which report that RSYNC
+// process was killed as hung, providing diagnostic snapshot.
+type ProcessHung func(paths core.SrcDstPath, snapshot *ProcessSnapshot)
+
+// ProcessSnapshot keep diagnostic information about hung RSYNC process.
+type ProcessSnapshot struct {
+	// Time passed since process start
+	Elapsed time.Duration
+	// Time passed since the most recent output
+	Idle time.Duration
+	// The most recent output lines
+	LastLines []string
+}
+
+// ProcessWatchdog kill RSYNC process, which print nothing, including
+// transfer progress, for longer than timeout, so RSYNC call could be
+// repeated. Single instance shared by all calls of the session.
+type ProcessWatchdog struct {
+	Timeout       time.Duration
+	CheckInterval time.Duration
+	Hung          ProcessHung
+}
+
+// NewProcessWatchdog create watchdog, which kill RSYNC
+// process being silent for longer than timeout.
+func NewProcessWatchdog(timeout time.Duration, hung ProcessHung) *ProcessWatchdog {
+	v := &ProcessWatchdog{Timeout: timeout, CheckInterval: PROCESS_CHECK_INTERVAL,
+		Hung: hung}
+	return v
+}
+
+// ProcessHungError denote a situation, when RSYNC process
+// was killed, since no output received for a long time.
+type ProcessHungError struct {
+	Snapshot *ProcessSnapshot
+}
+
+func (v *ProcessHungError) Error() string {
+	sections := 2
+	return locale.T(MsgRsyncProcessHungError,
+		struct{ Idle string }{Idle: core.FormatDurationToDaysHoursMinsSecs(
+			v.Snapshot.Idle, true, &sections)})
+}
+
+// IsProcessHungError check that error able to cast
+// to ProcessHungError.
+func IsProcessHungError(err error) bool {
+	if err != nil {
+		_, ok := err.(*ProcessHungError)
+		return ok
+	}
+	return false
+}
+
+// activityMonitor register output of single RSYNC process
+// to track time of the most recent activity.
+type activityMonitor struct {
+	sync.Mutex
+	start   time.Time
+	last    time.Time
+	lines   []string
+	partial string
+}
+
+func newActivityMonitor() *activityMonitor {
+	now := time.Now()
+	v := &activityMonitor{start: now, last: now}
+	return v
+}
+
+// register output chunk. RSYNC update progress with carriage
+// return, so it's considered as line separator either.
+func (v *activityMonitor) register(p []byte) {
+	v.Lock()
+	defer v.Unlock()
+	v.last = time.Now()
+	items := strings.FieldsFunc(v.partial+string(p), func(r rune) bool {
+		return r == '\n' || r == '\r'
+	})
+	v.partial = ""
+	if len(p) > 0 && p[len(p)-1] != '\n' && p[len(p)-1] != '\r' && len(items) > 0 {
+		v.partial = items[len(items)-1]
+		items = items[:len(items)-1]
+	}
+	for _, item := range items {
+		if strings.TrimSpace(item) != "" {
+			v.lines = append(v.lines, item)
+		}
+	}
+	if len(v.lines) > PROCESS_SNAPSHOT_LINES {
+		v.lines = v.lines[len(v.lines)-PROCESS_SNAPSHOT_LINES:]
+	}
+}
+
+// idle return time passed since the most recent output.
+func (v *activityMonitor) idle() time.Duration {
+	v.Lock()
+	defer v.Unlock()
+	return time.Since(v.last)
+}
+
+// snapshot return diagnostic information about the process.
+func (v *activityMonitor) snapshot() *ProcessSnapshot {
+	v.Lock()
+	defer v.Unlock()
+	lines := append([]string{}, v.lines...)
+	if v.partial != "" {
+		lines = append(lines, v.partial)
+	}
+	return &ProcessSnapshot{Elapsed: time.Since(v.start),
+		Idle: time.Since(v.last), LastLines: lines}
+}
+
+// activityWriter pass output to the writer,
+// registering it in activity monitor.
+type activityWriter struct {
+	monitor *activityMonitor
+	writer  io.Writer
+}
+
+func (v *activityWriter) Write(p []byte) (int, error) {
+	v.monitor.register(p)
+	if v.writer != nil {
+		return v.writer.Write(p)
+	}
+	return len(p), nil
+}
@@ -0,0 +1,290 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package rsync
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/d2r2/go-rsync/core"
+	"github.com/d2r2/go-rsync/locale"
+	shell "github.com/d2r2/go-shell"
+)
+
+const (
+	SSH_APP_CMD         = "ssh"
+	SSH_KEYSCAN_APP_CMD = "ssh-keyscan"
+	SSH_KEYGEN_APP_CMD  = "ssh-keygen"
+)
+
+// Seconds to wait for SSH host response, while scanning host keys.
+const sshKeyScanTimeoutSec = 10
+
+// HostKey describe SSH host public key, as it
+// is stored in known_hosts file.
+type HostKey struct {
+	// Host name in known_hosts notation: "host" or "[host]:port".
+	Host    string
+	KeyType string
+	// Public key encoded with base64.
+	Key string
+}
+
+// Fingerprint return SHA256 fingerprint of the key,
+// in the same notation, as OpenSSH display it.
+func (v *HostKey) Fingerprint() string {
+	blob, err := base64.StdEncoding.DecodeString(v.Key)
+	if err != nil {
+		return v.Key
+	}
+	sum := sha256.Sum256(blob)
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])
+}
+
+// SplitRemoteShellPath split RSYNC path accessed via remote shell
+// ("[user@]host:path") to host and path parts.
+func SplitRemoteShellPath(rsyncPath string) (host, remotePath string, ok bool) {
+	if IsLocalSource(rsyncPath) || core.IsRsyncDaemonURL(rsyncPath) {
+		return "", "", false
+	}
+	i := strings.Index(rsyncPath, ":")
+	if i <= 0 || strings.Contains(rsyncPath[:i], "/") {
+		return "", "", false
+	}
+	return rsyncPath[:i], rsyncPath[i+1:], true
+}
+
+// Known hosts file used to verify SSH host keys,
+// verification is delegated to SSH defaults if empty.
+var (
+	knownHostsMutex sync.Mutex
+	knownHostsPath  string
+)
+
+// SetKnownHostsPath enable strict SSH host key verification against
+// known hosts file specified (along with user's default one)
+// for all RSYNC calls via remote shell. Empty path disable it.
+func SetKnownHostsPath(path string) {
+	knownHostsMutex.Lock()
+	defer knownHostsMutex.Unlock()
+	knownHostsPath = path
+}
+
+func getKnownHostsPath() string {
+	knownHostsMutex.Lock()
+	defer knownHostsMutex.Unlock()
+	return knownHostsPath
+}
+
+// GetKnownHostsPath return location of application known hosts file:
+// $XDG_CONFIG_HOME/gorsync/known_hosts, or ~/.config/gorsync/known_hosts by default.
+func GetKnownHostsPath() (string, error) {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		u, err := user.Current()
+		if err != nil {
+			return "", err
+		}
+		configHome = filepath.Join(u.HomeDir, ".config")
+	}
+	return filepath.Join(configHome, "gorsync", "known_hosts"), nil
+}
+
+// getUserKnownHostsPath return location of user's default SSH known hosts file.
+func getUserKnownHostsPath() (string, error) {
+	u, err := user.Current()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(u.HomeDir, ".ssh", "known_hosts"), nil
+}
+
+// GetSSHOptions return SSH client options to connect to the host ("[user@]host"):
+// verify host key strictly, when enabled with SetKnownHostsPath, so host with
+// unknown or changed key is rejected, instead of silent acceptance or hidden
+// prompt; login with user and key from host credential, if any.
+func GetSSHOptions(host string) []string {
+	options := sshCredentialOptions(host)
+	path := getKnownHostsPath()
+	if path == "" {
+		return options
+	}
+	files := path
+	if userPath, err := getUserKnownHostsPath(); err == nil {
+		files += " " + userPath
+	}
+	return append([]string{"-o", "StrictHostKeyChecking=yes",
+		"-o", fmt.Sprintf("UserKnownHostsFile=%s", files)}, options...)
+}
+
+// sshParams return RSYNC option to run remote shell with strict
+// host key verification and host credential, if source accessed via SSH.
+func sshParams(rsyncSourcePath string) []string {
+	host, _, ok := SplitRemoteShellPath(rsyncSourcePath)
+	if !ok {
+		return nil
+	}
+	options := GetSSHOptions(host)
+	if options == nil {
+		return nil
+	}
+	// RSYNC split remote shell command by spaces, honoring quotes.
+	rsh := []string{SSH_APP_CMD}
+	for _, item := range options {
+		if strings.Contains(item, " ") {
+			item = `"` + item + `"`
+		}
+		rsh = append(rsh, item)
+	}
+	return []string{fmt.Sprintf("--rsh=%s", strings.Join(rsh, " "))}
+}
+
+// resolveSSHHost find out real host name and port, taking into
+// account SSH client configuration (host aliases and so on).
+// Host in "[user@]host" notation is expected.
+func resolveSSHHost(host string) (hostName string, port int) {
+	if i := strings.Index(host, "@"); i >= 0 {
+		host = host[i+1:]
+	}
+	hostName, port = host, 22
+	app := shell.NewApp(SSH_APP_CMD, "-G", host)
+	var stdOut, stdErr bytes.Buffer
+	ec := app.Run(&stdOut, &stdErr)
+	if ec.Error != nil || ec.ExitCode != 0 {
+		return hostName, port
+	}
+	scanner := bufio.NewScanner(&stdOut)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		switch fields[0] {
+		case "hostname":
+			hostName = fields[1]
+		case "port":
+			if p, err := strconv.Atoi(fields[1]); err == nil {
+				port = p
+			}
+		}
+	}
+	return hostName, port
+}
+
+// knownHostName return host name in known_hosts notation.
+func knownHostName(hostName string, port int) string {
+	if port == 22 {
+		return hostName
+	}
+	return fmt.Sprintf("[%s]:%d", hostName, port)
+}
+
+// IsHostKnown verify that key of SSH host ("[user@]host") is
+// stored either in application known hosts file, or in user's default one.
+func IsHostKnown(host string) (bool, error) {
+	hostName, port := resolveSSHHost(host)
+	name := knownHostName(hostName, port)
+	var files []string
+	if path, err := GetKnownHostsPath(); err == nil {
+		files = append(files, path)
+	}
+	if path, err := getUserKnownHostsPath(); err == nil {
+		files = append(files, path)
+	}
+	for _, path := range files {
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		app := shell.NewApp(SSH_KEYGEN_APP_CMD, "-F", name, "-f", path)
+		var stdOut, stdErr bytes.Buffer
+		ec := app.Run(&stdOut, &stdErr)
+		if ec.Error != nil {
+			return false, ec.Error
+		}
+		if ec.ExitCode == 0 && stdOut.Len() > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ScanHostKeys obtain public keys of SSH host ("[user@]host")
+// to show them to user for confirmation.
+func ScanHostKeys(host string) ([]HostKey, error) {
+	hostName, port := resolveSSHHost(host)
+	app := shell.NewApp(SSH_KEYSCAN_APP_CMD, "-T", strconv.Itoa(sshKeyScanTimeoutSec),
+		"-p", strconv.Itoa(port), hostName)
+	var stdOut, stdErr bytes.Buffer
+	ec := app.Run(&stdOut, &stdErr)
+	if ec.Error != nil {
+		return nil, ec.Error
+	}
+	var keys []HostKey
+	scanner := bufio.NewScanner(&stdOut)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		keys = append(keys, HostKey{Host: fields[0], KeyType: fields[1], Key: fields[2]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, errors.New(locale.T(MsgRsyncHostKeyScanFailedError,
+			struct{ Host, Output string }{Host: host,
+				Output: strings.TrimSpace(stdErr.String())}))
+	}
+	return keys, nil
+}
+
+// TrustHostKeys append host keys accepted by user to application known hosts file.
+func TrustHostKeys(keys []HostKey) error {
+	path, err := GetKnownHostsPath()
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	for _, item := range keys {
+		buf.WriteString(fmt.Sprintf("%s %s %s\n", item.Host, item.KeyType, item.Key))
+	}
+	_, err = file.Write(buf.Bytes())
+	if err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -22,7 +22,56 @@ package rsync
 const (
 	MsgRsyncCallFailedError                  = "RsyncCallFailedError"
 	MsgRsyncProcessTerminatedError           = "RsyncProcessTerminatedError"
+	MsgRsyncProcessHungError                 = "RsyncProcessHungError"
 	MsgRsyncCannotFindFolderSizeOutputError  = "RsyncCannotFindFolderSizeOutputError"
 	MsgRsyncCannotParseFolderSizeOutputError = "RsyncCannotParseFolderSizeOutputError"
 	MsgRsyncExtractVersionAndProtocolError   = "RsyncExtractVersionAndProtocolError"
+	MsgRsyncHostKeyScanFailedError           = "RsyncHostKeyScanFailedError"
+
+	MsgRsyncExitCodeSuccess                 = "RsyncExitCodeSuccess"
+	MsgRsyncExitCodeSyntaxOrUsageError      = "RsyncExitCodeSyntaxOrUsageError"
+	MsgRsyncExitCodeProtocolIncompatibility = "RsyncExitCodeProtocolIncompatibility"
+	MsgRsyncExitCodeSelectingFilesError     = "RsyncExitCodeSelectingFilesError"
+	MsgRsyncExitCodeActionNotSupported      = "RsyncExitCodeActionNotSupported"
+	MsgRsyncExitCodeStartingProtocolError   = "RsyncExitCodeStartingProtocolError"
+	MsgRsyncExitCodeDaemonLogFileError      = "RsyncExitCodeDaemonLogFileError"
+	MsgRsyncExitCodeSocketIOError           = "RsyncExitCodeSocketIOError"
+	MsgRsyncExitCodeFileIOError             = "RsyncExitCodeFileIOError"
+	MsgRsyncExitCodeProtocolDataStreamError = "RsyncExitCodeProtocolDataStreamError"
+	MsgRsyncExitCodeProgramDiagnosticsError = "RsyncExitCodeProgramDiagnosticsError"
+	MsgRsyncExitCodeIPCError                = "RsyncExitCodeIPCError"
+	MsgRsyncExitCodeSignalReceived          = "RsyncExitCodeSignalReceived"
+	MsgRsyncExitCodeWaitpidError            = "RsyncExitCodeWaitpidError"
+	MsgRsyncExitCodeMemoryAllocationError   = "RsyncExitCodeMemoryAllocationError"
+	MsgRsyncExitCodePartialTransfer         = "RsyncExitCodePartialTransfer"
+	MsgRsyncExitCodeVanishedSourceFiles     = "RsyncExitCodeVanishedSourceFiles"
+	MsgRsyncExitCodeMaxDeleteLimit          = "RsyncExitCodeMaxDeleteLimit"
+	MsgRsyncExitCodeDataTimeout             = "RsyncExitCodeDataTimeout"
+	MsgRsyncExitCodeDaemonConnectionTimeout = "RsyncExitCodeDaemonConnectionTimeout"
+	MsgRsyncExitCodeUnexplainedError        = "RsyncExitCodeUnexplainedError"
+	MsgRsyncExitCodeUndefined               = "RsyncExitCodeUndefined"
+
+	MsgRsyncErrorSuggestion                     = "RsyncErrorSuggestion"
+	MsgRsyncErrorSuggestionUsage                = "RsyncErrorSuggestionUsage"
+	MsgRsyncErrorSuggestionConnection           = "RsyncErrorSuggestionConnection"
+	MsgRsyncErrorSuggestionFileAccess           = "RsyncErrorSuggestionFileAccess"
+	MsgRsyncErrorSuggestionVanishedFiles        = "RsyncErrorSuggestionVanishedFiles"
+	MsgRsyncErrorSuggestionInterrupted          = "RsyncErrorSuggestionInterrupted"
+	MsgRsyncErrorSuggestionInternal             = "RsyncErrorSuggestionInternal"
+	MsgRsyncErrorSuggestionDaemonUnknownModule  = "RsyncErrorSuggestionDaemonUnknownModule"
+	MsgRsyncErrorSuggestionDaemonAccessDenied   = "RsyncErrorSuggestionDaemonAccessDenied"
+	MsgRsyncErrorSuggestionDaemonAuthFailed     = "RsyncErrorSuggestionDaemonAuthFailed"
+	MsgRsyncErrorSuggestionDaemonMaxConnections = "RsyncErrorSuggestionDaemonMaxConnections"
+	MsgRsyncErrorSuggestionDaemonOptionRefused  = "RsyncErrorSuggestionDaemonOptionRefused"
+	MsgRsyncErrorSuggestionDaemonConfig         = "RsyncErrorSuggestionDaemonConfig"
+
+	MsgRsyncDaemonError               = "RsyncDaemonError"
+	MsgRsyncDaemonErrorUnknownModule  = "RsyncDaemonErrorUnknownModule"
+	MsgRsyncDaemonErrorAccessDenied   = "RsyncDaemonErrorAccessDenied"
+	MsgRsyncDaemonErrorAuthFailed     = "RsyncDaemonErrorAuthFailed"
+	MsgRsyncDaemonErrorMaxConnections = "RsyncDaemonErrorMaxConnections"
+	MsgRsyncDaemonErrorReadOnly       = "RsyncDaemonErrorReadOnly"
+	MsgRsyncDaemonErrorOptionRefused  = "RsyncDaemonErrorOptionRefused"
+	MsgRsyncDaemonErrorFiltered       = "RsyncDaemonErrorFiltered"
+	MsgRsyncDaemonErrorOther          = "RsyncDaemonErrorOther"
 )
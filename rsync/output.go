@@ -0,0 +1,78 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package rsync
+
+import (
+	"bufio"
+	"bytes"
+	"sync"
+)
+
+// OutputTail keep last lines of STDOUT output produced by
+// the most recent RSYNC call. Number of lines is limited,
+// so the oldest lines are dropped once buffer is full.
+// Safe to use from multiple goroutines.
+type OutputTail struct {
+	sync.Mutex
+	maxLines int
+	lines    []string
+	// index of the oldest line, once buffer is full
+	start int
+}
+
+// NewOutputTail create OutputTail to keep maxLines lines at most.
+func NewOutputTail(maxLines int) *OutputTail {
+	if maxLines < 1 {
+		maxLines = 1
+	}
+	v := &OutputTail{maxLines: maxLines}
+	return v
+}
+
+// Reset drop all lines kept.
+func (v *OutputTail) Reset() {
+	v.Lock()
+	defer v.Unlock()
+	v.lines = nil
+	v.start = 0
+}
+
+// Set replace lines kept with the output of RSYNC call.
+func (v *OutputTail) Set(output []byte) {
+	v.Lock()
+	defer v.Unlock()
+	v.lines = nil
+	v.start = 0
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		if len(v.lines) < v.maxLines {
+			v.lines = append(v.lines, line)
+		} else {
+			v.lines[v.start] = line
+			v.start = (v.start + 1) % v.maxLines
+		}
+	}
+}
+
+// Lines return copy of lines kept, from the oldest to the most recent one.
+func (v *OutputTail) Lines() []string {
+	v.Lock()
+	defer v.Unlock()
+	lines := make([]string, 0, len(v.lines))
+	lines = append(lines, v.lines[v.start:]...)
+	lines = append(lines, v.lines[:v.start]...)
+	return lines
+}
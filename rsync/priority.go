@@ -0,0 +1,113 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package rsync
+
+import (
+	"strconv"
+	"sync"
+
+	shell "github.com/d2r2/go-shell"
+)
+
+// System utilities to run process with modified CPU and I/O priority.
+const (
+	NICE_APP_CMD   = "nice"
+	IONICE_APP_CMD = "ionice"
+)
+
+// I/O scheduling classes, which might be specified for RSYNC process.
+// Realtime class is not supported, since it requires root privileges.
+const (
+	IO_CLASS_DEFAULT     = "default"
+	IO_CLASS_BEST_EFFORT = "best-effort"
+	IO_CLASS_IDLE        = "idle"
+)
+
+// Allowed ranges of CPU niceness and I/O priority level.
+const (
+	NICE_LEVEL_MAX = 19
+	IO_LEVEL_MAX   = 7
+)
+
+// Priority keep CPU niceness and I/O scheduling
+// class and level to launch RSYNC process with.
+type Priority struct {
+	// CPU niceness from 1 to 19, 0 to keep default.
+	Nice int
+	// I/O scheduling class, empty to keep default.
+	IOClass string
+	// I/O priority level from 0 (highest) to 7 (lowest),
+	// taken into account for best-effort class only.
+	IOLevel int
+}
+
+// IsEmpty verify that priority doesn't change anything.
+func (v *Priority) IsEmpty() bool {
+	return v.Nice <= 0 && (v.IOClass == "" || v.IOClass == IO_CLASS_DEFAULT)
+}
+
+var (
+	priorityAppsOnce sync.Once
+	niceInstalled    bool
+	ioniceInstalled  bool
+)
+
+// checkPriorityApps verify once that nice and ionice utilities present in the system.
+func checkPriorityApps() {
+	priorityAppsOnce.Do(func() {
+		niceInstalled = shell.NewApp(NICE_APP_CMD).CheckIsInstalled() == nil
+		ioniceInstalled = shell.NewApp(IONICE_APP_CMD).CheckIsInstalled() == nil
+		if !niceInstalled {
+			lg.Warnf("%q utility not found, CPU priority of RSYNC will not be changed", NICE_APP_CMD)
+		}
+		if !ioniceInstalled {
+			lg.Warnf("%q utility not found, I/O priority of RSYNC will not be changed", IONICE_APP_CMD)
+		}
+	})
+}
+
+// wrapCommand return application with arguments to run, so that
+// command is launched via nice and ionice utilities, if required.
+func (v *Priority) wrapCommand(cmd string, args []string) (string, []string) {
+	if v == nil || v.IsEmpty() {
+		return cmd, args
+	}
+	checkPriorityApps()
+	var prefix []string
+	if v.Nice > 0 && niceInstalled {
+		nice := v.Nice
+		if nice > NICE_LEVEL_MAX {
+			nice = NICE_LEVEL_MAX
+		}
+		prefix = append(prefix, NICE_APP_CMD, "-n", strconv.Itoa(nice))
+	}
+	if ioniceInstalled {
+		switch v.IOClass {
+		case IO_CLASS_BEST_EFFORT:
+			level := v.IOLevel
+			if level < 0 {
+				level = 0
+			} else if level > IO_LEVEL_MAX {
+				level = IO_LEVEL_MAX
+			}
+			prefix = append(prefix, IONICE_APP_CMD, "-c", "2", "-n", strconv.Itoa(level))
+		case IO_CLASS_IDLE:
+			prefix = append(prefix, IONICE_APP_CMD, "-c", "3")
+		}
+	}
+	if len(prefix) == 0 {
+		return cmd, args
+	}
+	args2 := append(prefix[1:], cmd)
+	args2 = append(args2, args...)
+	return prefix[0], args2
+}
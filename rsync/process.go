@@ -0,0 +1,258 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package rsync
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// ProcessStatus keep exit code of the process,
+// or error, if process failed to complete.
+type ProcessStatus struct {
+	ExitCode int
+	Error    error
+}
+
+// Command describe single application call: name, arguments
+// and extra environment variables passed to the process.
+type Command struct {
+	Name string
+	Args []string
+	Env  []string
+}
+
+// String return command line in shell notation, prefixed with environment
+// variables. Values of password variables are masked.
+func (v *Command) String() string {
+	var items []string
+	for _, item := range v.Env {
+		i := strings.Index(item, "=")
+		if i < 0 {
+			continue
+		}
+		value := quoteCommandArg(item[i+1:])
+		if strings.Contains(item[:i], "PASSWORD") {
+			value = "***"
+		}
+		items = append(items, item[:i+1]+value)
+	}
+	items = append(items, quoteCommandArg(v.Name))
+	for _, item := range v.Args {
+		items = append(items, quoteCommandArg(item))
+	}
+	return strings.Join(items, " ")
+}
+
+// quoteCommandArg protect argument with quotes, if it contain special chars.
+func quoteCommandArg(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, " \t\n'\"\\$`*?[]{}()<>|&;#~") {
+		return arg
+	}
+	return "'" + strings.Replace(arg, "'", `'\''`, -1) + "'"
+}
+
+// Process is an application started by Executor.
+type Process interface {
+	// Done return channel to receive exit status, once process completed.
+	Done() <-chan ProcessStatus
+	// Kill terminate the process.
+	Kill() error
+}
+
+// Executor start applications. Default one spawn system processes,
+// but it might be replaced with SetExecutor to trace or fake calls.
+type Executor interface {
+	Start(cmd *Command, stdOut, stdErr io.Writer) (Process, error)
+}
+
+// Executor used by all RSYNC calls.
+var (
+	executorMutex sync.Mutex
+	executor      Executor = &SystemExecutor{}
+)
+
+// SetExecutor replace executor used by all RSYNC calls.
+func SetExecutor(value Executor) {
+	executorMutex.Lock()
+	defer executorMutex.Unlock()
+	executor = value
+}
+
+func getExecutor() Executor {
+	executorMutex.Lock()
+	defer executorMutex.Unlock()
+	return executor
+}
+
+// isSystemExecutor verify that RSYNC calls run system processes.
+func isSystemExecutor() bool {
+	executor := getExecutor()
+	if print, ok := executor.(*PrintExecutor); ok {
+		executor = print.executor
+	}
+	_, ok := executor.(*SystemExecutor)
+	return ok
+}
+
+// SystemExecutor run applications as system processes.
+type SystemExecutor struct {
+}
+
+// Start run application with extra environment variables
+// and return process to track exit status.
+func (v *SystemExecutor) Start(cmd *Command, stdOut, stdErr io.Writer) (Process, error) {
+	return startProcess(cmd.Name, cmd.Args, cmd.Env, stdOut, stdErr)
+}
+
+// PrintExecutor print each command to the writer,
+// before it is started with another executor.
+type PrintExecutor struct {
+	executor Executor
+	mutex    sync.Mutex
+	out      io.Writer
+}
+
+// NewPrintExecutor create PrintExecutor, which wrap executor specified.
+func NewPrintExecutor(executor Executor, out io.Writer) *PrintExecutor {
+	v := &PrintExecutor{executor: executor, out: out}
+	return v
+}
+
+// Start print command and run it with wrapped executor.
+func (v *PrintExecutor) Start(cmd *Command, stdOut, stdErr io.Writer) (Process, error) {
+	v.mutex.Lock()
+	fmt.Fprintln(v.out, cmd.String())
+	v.mutex.Unlock()
+	return v.executor.Start(cmd, stdOut, stdErr)
+}
+
+// FakeExecutor capture commands without running them, and complete
+// each call immediately with predefined output and exit code.
+// Intended to verify RSYNC calls in tests.
+type FakeExecutor struct {
+	sync.Mutex
+	// Output written to STDOUT and STDERR by each call.
+	StdOut string
+	StdErr string
+	// Exit code returned by each call.
+	ExitCode int
+	commands []Command
+}
+
+// Start save copy of the command and return completed process.
+func (v *FakeExecutor) Start(cmd *Command, stdOut, stdErr io.Writer) (Process, error) {
+	v.Lock()
+	defer v.Unlock()
+	v.commands = append(v.commands, Command{Name: cmd.Name,
+		Args: append([]string{}, cmd.Args...), Env: append([]string{}, cmd.Env...)})
+	if stdOut != nil {
+		io.WriteString(stdOut, v.StdOut)
+	}
+	if stdErr != nil {
+		io.WriteString(stdErr, v.StdErr)
+	}
+	p := &fakeProcess{done: make(chan ProcessStatus, 1)}
+	p.done <- ProcessStatus{ExitCode: v.ExitCode}
+	return p, nil
+}
+
+// GetCommands return commands captured so far.
+func (v *FakeExecutor) GetCommands() []Command {
+	v.Lock()
+	defer v.Unlock()
+	return append([]Command{}, v.commands...)
+}
+
+// Reset forget commands captured so far.
+func (v *FakeExecutor) Reset() {
+	v.Lock()
+	defer v.Unlock()
+	v.commands = nil
+}
+
+// Dump return commands captured so far in shell notation, one per line.
+func (v *FakeExecutor) Dump() string {
+	var buf bytes.Buffer
+	for _, cmd := range v.GetCommands() {
+		buf.WriteString(cmd.String())
+		buf.WriteString("\n")
+	}
+	return buf.String()
+}
+
+// fakeProcess is a process completed immediately.
+type fakeProcess struct {
+	done chan ProcessStatus
+}
+
+func (v *fakeProcess) Done() <-chan ProcessStatus {
+	return v.done
+}
+
+func (v *fakeProcess) Kill() error {
+	return nil
+}
+
+// process run application asynchronously, streaming output to writers,
+// so output could be tracked while application is running.
+type process struct {
+	cmd    *exec.Cmd
+	waitCh chan ProcessStatus
+}
+
+// startProcess run application with extra environment
+// variables and return process to track exit status.
+func startProcess(name string, args []string, env []string,
+	stdOut, stdErr io.Writer) (*process, error) {
+
+	cmd := exec.Command(name, args...)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdout = stdOut
+	cmd.Stderr = stdErr
+	err := cmd.Start()
+	if err != nil {
+		return nil, err
+	}
+	v := &process{cmd: cmd, waitCh: make(chan ProcessStatus, 1)}
+	go v.wait()
+	return v, nil
+}
+
+// wait for process completion and send exit status to the channel.
+func (v *process) wait() {
+	err := v.cmd.Wait()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			v.waitCh <- ProcessStatus{ExitCode: exitErr.ExitCode()}
+			return
+		}
+		v.waitCh <- ProcessStatus{Error: err}
+		return
+	}
+	v.waitCh <- ProcessStatus{}
+}
+
+// Done return channel to receive exit status.
+func (v *process) Done() <-chan ProcessStatus {
+	return v.waitCh
+}
+
+// Kill terminate the process.
+func (v *process) Kill() error {
+	return v.cmd.Process.Kill()
+}
@@ -12,12 +12,13 @@
 package rsync
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/d2r2/go-rsync/core"
 	shell "github.com/d2r2/go-shell"
@@ -34,13 +35,44 @@ func RunRsyncWithRetry(ctx context.Context, options *Options, log *Logging, stdO
 	if options != nil {
 		retryCount = options.RetryCount
 	}
+	params := options.Params
+	if timeoutParams := options.timeoutParams(paths.RsyncSourcePath); len(timeoutParams) > 0 {
+		params = append(append([]string{}, params...), timeoutParams...)
+	}
+	if sshParams := sshParams(paths.RsyncSourcePath); len(sshParams) > 0 {
+		params = append(append([]string{}, params...), sshParams...)
+	}
+	if options.Filter != nil {
+		filterParams, release, err := options.Filter.Params(paths.RsyncSourcePath)
+		if err != nil {
+			sessionErr = err
+			return
+		}
+		defer release()
+		params = append(append([]string{}, params...), filterParams...)
+	}
+	if options.Output != nil {
+		params = append(append([]string{}, params...), "--itemize-changes")
+	}
+	// Module level password and user (if any) take precedence over host credential.
+	source, password := applyHostCredential(paths.RsyncSourcePath, options.Password)
 	index := 0
 	for {
-		err := runSystemRsync(ctx, options.Password,
-			options.Params, log, stdOut,
-			paths.RsyncSourcePath, paths.DestPath)
+		stdOut2 := stdOut
+		if options.Output != nil && stdOut2 == nil {
+			stdOut2 = bytes.NewBuffer(nil)
+		}
+		err := runSystemRsync(ctx, password, options.Priority, options.HangWatchdog,
+			params, log, stdOut2,
+			source, paths.DestPath)
+		if options.Output != nil {
+			options.Output.Set(stdOut2.Bytes())
+		}
 
 		if err == nil {
+			if options.Watchdog != nil {
+				options.Watchdog.reset()
+			}
 			return
 		} else if IsProcessTerminatedError(err) {
 			sessionErr = err
@@ -52,6 +84,26 @@ func RunRsyncWithRetry(ctx context.Context, options *Options, log *Logging, stdO
 			retryErr = err
 		}
 
+		// hung process killed: report diagnostic snapshot
+		// and repeat call, spending retry count
+		if IsProcessHungError(err) && options.HangWatchdog.Hung != nil {
+			options.HangWatchdog.Hung(paths, err.(*ProcessHungError).Snapshot)
+		}
+
+		// in case of network outage wait until source become
+		// reachable again, and repeat call without spending retry count
+		if options.Watchdog != nil && options.Watchdog.failed(err, paths) {
+			restored, err2 := options.Watchdog.waitForNetwork(ctx, options.Password, err, paths)
+			if err2 != nil {
+				sessionErr = err2
+				criticalErr = err2
+				return
+			}
+			if restored {
+				continue
+			}
+		}
+
 		// in case of error we are trying to recover from
 		// fail state via call to ErrorHook call-back function
 		if options != nil && options.ErrorHook != nil {
@@ -78,58 +130,59 @@ func RunRsyncWithRetry(ctx context.Context, options *Options, log *Logging, stdO
 }
 
 // IsInstalled do verify that RSYNC application present in the system.
+// RSYNC is not required, when calls are simulated or faked.
 func IsInstalled() error {
+	if !isSystemExecutor() {
+		return nil
+	}
 	app := shell.NewApp(RSYNC_APP_CMD)
 	return app.CheckIsInstalled()
 }
 
 // GetRsyncVersion run RSYNC to get version and protocol.
 func GetRsyncVersion() (version string, protocol string, err error) {
-	app := shell.NewApp(RSYNC_APP_CMD, "--version")
-	var stdOut, stdErr bytes.Buffer
-	exitCode := app.Run(&stdOut, &stdErr)
-	if exitCode.Error != nil {
-		return "", "", exitCode.Error
-	}
-	scanner := bufio.NewScanner(&stdOut)
-	scanner.Split(bufio.ScanLines)
-
-	// Expression should parse a line variant:
-	//		rsync  version 3.1.3  protocol version 31
-	//		rsync  version v3.2.3  protocol version 31
-	re := regexp.MustCompile(`version\s+v?(?P<version>\d+\.\d+(\.\d+)?)(\s+protocol\s+version\s+(?P<protocol>\d+))?`)
-	for scanner.Scan() {
-		line := scanner.Text()
-		m := core.FindStringSubmatchIndexes(re, line)
-		if len(m) > 0 {
-			grName := "version"
-			if _, ok := m[grName]; ok {
-				start := m[grName][0]
-				end := m[grName][1]
-				version = line[start:end]
-			}
-			grName = "protocol"
-			if _, ok := m[grName]; ok {
-				start := m[grName][0]
-				end := m[grName][1]
-				protocol = line[start:end]
-			}
-			break
-		}
+	caps, err := GetCapabilities()
+	if err != nil {
+		return "", "", err
 	}
-	// Extracted RSYNC version cannot be empty.
-	if version == "" {
-		// Return error which should be treated as a warning in the main,
-		// when RSYNC version (and protocol) is undetected for some reason.
-		return "", "", &ExtractVersionAndProtocolError{}
+	return caps.Version, caps.Protocol, nil
+}
+
+// Expression should parse a line variant:
+//		rsync  version 3.1.3  protocol version 31
+//		rsync  version v3.2.3  protocol version 31
+var versionAndProtocolRegexp = regexp.MustCompile(
+	`version\s+v?(?P<version>\d+\.\d+(\.\d+)?)(\s+protocol\s+version\s+(?P<protocol>\d+))?`)
+
+// parseVersionAndProtocol extract RSYNC version and protocol
+// from the line, if found.
+func parseVersionAndProtocol(line string) (version string, protocol string) {
+	m := core.FindStringSubmatchIndexes(versionAndProtocolRegexp, line)
+	if len(m) > 0 {
+		grName := "version"
+		if _, ok := m[grName]; ok {
+			start := m[grName][0]
+			end := m[grName][1]
+			version = line[start:end]
+		}
+		grName = "protocol"
+		if _, ok := m[grName]; ok {
+			start := m[grName][0]
+			end := m[grName][1]
+			protocol = line[start:end]
+		}
 	}
-	return version, protocol, nil
+	return version, protocol
 }
 
 // runSystemRsync run RSYNC utility.
 // Parameters:
 //	- Save console output to stdOut variable.
-func runSystemRsync(ctx context.Context, password *string,
+//	- Launch process with priority, if specified.
+//	- Kill process being silent for too long, if watchdog specified.
+//	- Start process with executor replaceable via SetExecutor.
+func runSystemRsync(ctx context.Context, password *string, priority *Priority,
+	watchdog *ProcessWatchdog,
 	params []string, log *Logging, stdOut *bytes.Buffer,
 	source, dest string) error {
 
@@ -137,7 +190,11 @@ func runSystemRsync(ctx context.Context, password *string,
 	if params != nil {
 		args = params
 	}
-	args = append(args, source, dest)
+	args = append(args, source)
+	// No destination specified in listing mode.
+	if dest != "" {
+		args = append(args, dest)
+	}
 	stdOut2 := stdOut
 	stdErr := bytes.NewBuffer(nil)
 
@@ -150,7 +207,6 @@ func runSystemRsync(ctx context.Context, password *string,
 		}
 	}
 
-	app := shell.NewApp(RSYNC_APP_CMD, args...)
 	var passwd string
 	if password != nil {
 		passwd = *password
@@ -158,48 +214,79 @@ func runSystemRsync(ctx context.Context, password *string,
 	// Always add password variable RSYNC_PASSWORD, even when password not specified
 	// by configuration, for protection from console password stdin input request
 	// for RSYNC module with authentication.
-	app.AddEnvironments([]string{fmt.Sprintf("RSYNC_PASSWORD=%s", passwd)})
+	env := []string{fmt.Sprintf("RSYNC_PASSWORD=%s", passwd)}
 	if passwd != "" {
 		lg.Debugf("PASSWD: %v", passwd)
 	}
 	lg.Debugf("Args: %v", args)
-	waitCh, err := app.Start(stdOut2, stdErr)
+
+	var stdOutWriter io.Writer
+	if stdOut2 != nil {
+		stdOutWriter = stdOut2
+	}
+	var stdErrWriter io.Writer = stdErr
+	// Track output to find out that process hung.
+	var monitor *activityMonitor
+	var checkCh <-chan time.Time
+	if watchdog != nil && watchdog.Timeout > 0 {
+		monitor = newActivityMonitor()
+		stdOutWriter = &activityWriter{monitor: monitor, writer: stdOutWriter}
+		stdErrWriter = &activityWriter{monitor: monitor, writer: stdErr}
+		ticker := time.NewTicker(watchdog.CheckInterval)
+		defer ticker.Stop()
+		checkCh = ticker.C
+	}
+
+	cmd, cmdArgs := priority.wrapCommand(RSYNC_APP_CMD, args)
+	app, err := getExecutor().Start(&Command{Name: cmd, Args: cmdArgs, Env: env},
+		stdOutWriter, stdErrWriter)
 	if err != nil {
 		return err
 	}
 
-	select {
-	case <-ctx.Done():
-		lg.Debugf("Killing rsync: %v", args)
-		err := app.Kill()
-		if err != nil {
-			return err
-		}
-		return &ProcessTerminatedError{}
-	case st := <-waitCh:
-		// Enable RSYNC log output
-		if logEnabled {
-			logBuf.WriteString(RSYNC_APP_CMD)
-			if len(args) > 0 {
-				logBuf.WriteString(" ")
-				logBuf.WriteString(strings.Join(args, " "))
+	for {
+		select {
+		case <-ctx.Done():
+			lg.Debugf("Killing rsync: %v", args)
+			err := app.Kill()
+			if err != nil {
+				return err
 			}
-			// Enable intensive RSYNC log output, when we save
-			// whole stdout print.
-			if log.EnableIntensiveLog {
-				logBuf.WriteString(fmt.Sprintln())
-				logBuf.WriteString(fmt.Sprintln(">>>>>>>>>>>>>>>> Stdout start >>>>>>>>>>>>>>>>"))
-				logBuf.WriteString(fmt.Sprintln(strings.TrimRight(stdOut2.String(), "\n")))
-				logBuf.WriteString(fmt.Sprint("<<<<<<<<<<<<<<<< Stdout end <<<<<<<<<<<<<<<<"))
+			return &ProcessTerminatedError{}
+		case <-checkCh:
+			if monitor.idle() > watchdog.Timeout {
+				lg.Debugf("Killing hung rsync: %v", args)
+				err := app.Kill()
+				if err != nil {
+					return err
+				}
+				return &ProcessHungError{Snapshot: monitor.snapshot()}
 			}
-			log.Log.Info(logBuf.String())
-		}
-		if st.Error != nil {
-			return st.Error
-		} else if st.ExitCode != 0 {
-			lg.Debugf("STDERR: %v", stdErr.String())
-			return NewCallFailedError(st.ExitCode, stdErr)
+		case st := <-app.Done():
+			// Enable RSYNC log output
+			if logEnabled {
+				logBuf.WriteString(RSYNC_APP_CMD)
+				if len(args) > 0 {
+					logBuf.WriteString(" ")
+					logBuf.WriteString(strings.Join(args, " "))
+				}
+				// Enable intensive RSYNC log output, when we save
+				// whole stdout print.
+				if log.EnableIntensiveLog {
+					logBuf.WriteString(fmt.Sprintln())
+					logBuf.WriteString(fmt.Sprintln(">>>>>>>>>>>>>>>> Stdout start >>>>>>>>>>>>>>>>"))
+					logBuf.WriteString(fmt.Sprintln(strings.TrimRight(stdOut2.String(), "\n")))
+					logBuf.WriteString(fmt.Sprint("<<<<<<<<<<<<<<<< Stdout end <<<<<<<<<<<<<<<<"))
+				}
+				log.Log.Info(logBuf.String())
+			}
+			if st.Error != nil {
+				return st.Error
+			} else if st.ExitCode != 0 {
+				lg.Debugf("STDERR: %v", stdErr.String())
+				return NewCallFailedError(st.ExitCode, stdErr)
+			}
+			return nil
 		}
-		return nil
 	}
 }
@@ -0,0 +1,278 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package rsync
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/d2r2/go-rsync/core"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+// checkGolden compare text with testdata/<name>.golden file,
+// or rewrite file, if test run with -update flag.
+func checkGolden(t *testing.T, name, text string) {
+	t.Helper()
+	fileName := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := ioutil.WriteFile(fileName, []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	golden, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(golden) != text {
+		t.Errorf("%s: command line mismatch\nexpected:\n%s\ngot:\n%s", name, golden, text)
+	}
+}
+
+// runFake run RSYNC with FakeExecutor and return captured commands.
+func runFake(t *testing.T, options *Options, paths core.SrcDstPath) string {
+	t.Helper()
+	fake := &FakeExecutor{}
+	SetExecutor(fake)
+	defer SetExecutor(&SystemExecutor{})
+	sessionErr, retryErr, criticalErr := RunRsyncWithRetry(context.Background(),
+		options, nil, nil, paths)
+	if sessionErr != nil || retryErr != nil || criticalErr != nil {
+		t.Fatalf("unexpected errors: %v, %v, %v", sessionErr, retryErr, criticalErr)
+	}
+	return fake.Dump()
+}
+
+func TestRunRsyncCommandLine(t *testing.T) {
+	caps, err := ParseCapabilities(simulationVersionOutput)
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetCapabilities(caps)
+	defer SetCapabilities(nil)
+	// Pretend nice and ionice are installed, to get the same result everywhere.
+	checkPriorityApps()
+	niceInstalled, ioniceInstalled = true, true
+
+	password := "secret"
+	local := core.SrcDstPath{RsyncSourcePath: "/home/user/data/", DestPath: "/backup/data"}
+	daemon := core.SrcDstPath{RsyncSourcePath: "rsync://user@nas:8873/data/photo/",
+		DestPath: "/backup/photo"}
+	shell := core.SrcDstPath{RsyncSourcePath: "server:/srv/www/", DestPath: "/backup/www"}
+	tests := []struct {
+		name    string
+		options *Options
+		paths   core.SrcDstPath
+	}{
+		{"default", NewOptions(WithDefaultParams([]string{"--recursive"})), local},
+		{"daemon_password_timeouts", NewOptions(WithDefaultParams(nil)).
+			SetAuthPassword(&password).SetTimeouts(60*time.Second, 30*time.Second), daemon},
+		{"shell_timeouts", NewOptions(WithDefaultParams(nil)).
+			SetTimeouts(60*time.Second, 30*time.Second), shell},
+		{"filter", NewOptions(WithDefaultParams(nil)).
+			SetFileFilter(&FileFilter{MaxSizeMb: 100, MaxAgeDays: 30,
+				Excludes: []string{"*.tmp", "cache/"}}), daemon},
+		{"itemize", NewOptions(WithDefaultParams(nil)).SetOutputTail(NewOutputTail(10)), local},
+		{"priority_best_effort", NewOptions(WithDefaultParams(nil)).
+			SetPriority(&Priority{Nice: 25, IOClass: IO_CLASS_BEST_EFFORT, IOLevel: 9}), local},
+		{"priority_idle", NewOptions(WithDefaultParams(nil)).
+			SetPriority(&Priority{IOClass: IO_CLASS_IDLE}), local},
+		{"compress_partial_iconv", NewOptions(WithDefaultParams(
+			append(append(GetCompressParams(COMPRESS_CHOICE_AUTO, 30),
+				GetPartialParams()...), GetIconvParams("CP1251")...))), daemon},
+		{"compress_choice", NewOptions(WithDefaultParams(
+			AdjustParamsToCapabilities(GetCompressParams(COMPRESS_CHOICE_ZSTD, 30)))), daemon},
+	}
+	for _, test := range tests {
+		checkGolden(t, test.name, runFake(t, test.options, test.paths))
+	}
+}
+
+// excludeListExecutor capture content of exclude lists passed to RSYNC,
+// since temporary list files are removed once RSYNC completed.
+type excludeListExecutor struct {
+	FakeExecutor
+	excludes []string
+}
+
+func (v *excludeListExecutor) Start(cmd *Command, stdOut, stdErr io.Writer) (Process, error) {
+	for _, arg := range cmd.Args {
+		if strings.HasPrefix(arg, "--exclude-from=") {
+			b, err := ioutil.ReadFile(strings.TrimPrefix(arg, "--exclude-from="))
+			if err != nil {
+				return nil, err
+			}
+			v.excludes = append(v.excludes, string(b))
+		}
+	}
+	return v.FakeExecutor.Start(cmd, stdOut, stdErr)
+}
+
+func TestRunRsyncAgeFilterCommandLine(t *testing.T) {
+	caps, err := ParseCapabilities(simulationVersionOutput)
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetCapabilities(caps)
+	defer SetCapabilities(nil)
+
+	source := t.TempDir()
+	now := time.Now()
+	files := []struct {
+		path string
+		age  time.Duration
+	}{
+		{"old.txt", 60 * 24 * time.Hour},
+		{"recent.txt", 10 * 24 * time.Hour},
+		{"new.txt", time.Hour},
+		{"logs/old[1].log", 90 * 24 * time.Hour},
+	}
+	for _, item := range files {
+		path := filepath.Join(source, item.path)
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ioutil.WriteFile(path, []byte(item.path), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.Chtimes(path, now.Add(-item.age), now.Add(-item.age))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	executor := &excludeListExecutor{}
+	SetExecutor(executor)
+	defer SetExecutor(&SystemExecutor{})
+	options := NewOptions(WithDefaultParams(nil)).SetFileFilter(&FileFilter{MaxSizeMb: 100,
+		MaxAgeDays: 30, MinAgeDays: 1, Excludes: []string{"*.tmp"}})
+	sessionErr, retryErr, criticalErr := RunRsyncWithRetry(context.Background(), options, nil, nil,
+		core.SrcDstPath{RsyncSourcePath: source + "/", DestPath: "/backup/data"})
+	if sessionErr != nil || retryErr != nil || criticalErr != nil {
+		t.Fatalf("unexpected errors: %v, %v, %v", sessionErr, retryErr, criticalErr)
+	}
+	if len(executor.excludes) != 1 {
+		t.Fatalf("expected single age exclude list, got %d", len(executor.excludes))
+	}
+	// Replace temporary paths to get the same result everywhere.
+	text := executor.Dump()
+	for _, cmd := range executor.GetCommands() {
+		for _, arg := range cmd.Args {
+			if strings.HasPrefix(arg, "--exclude-from=") {
+				text = strings.Replace(text, strings.TrimPrefix(arg, "--exclude-from="),
+					"/tmp/backup_age_exclude", -1)
+			}
+		}
+	}
+	text = strings.Replace(text, source, "/home/user/data", -1)
+	checkGolden(t, "filter_local_age", text+"# /tmp/backup_age_exclude:\n"+executor.excludes[0])
+}
+
+func TestAdjustParamsToOldRelease(t *testing.T) {
+	caps, err := ParseCapabilities("rsync  version 3.1.3  protocol version 31\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetCapabilities(caps)
+	defer SetCapabilities(nil)
+
+	params := AdjustParamsToCapabilities(append(GetCompressParams(COMPRESS_CHOICE_ZSTD, 5),
+		"--acls", "--xattrs"))
+	checkGolden(t, "old_release", runFake(t, NewOptions(WithDefaultParams(params)),
+		core.SrcDstPath{RsyncSourcePath: "/home/user/data/", DestPath: "/backup/data"}))
+}
+
+func TestParseListOnlyOutput(t *testing.T) {
+	output := "drwxr-xr-x          4,096 2019/03/01 12:00:00 .\n" +
+		"-rw-r--r--      1.234.567 2019/03/01 12:00:01 dir/file name\n" +
+		"-rw-r--r--              0 2019/03/01 12:00:02 dir/\\#303\\#251t\\#303\\#251\n"
+	entries, err := parseListOnlyOutput(bytes.NewBufferString(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []struct {
+		path  string
+		isDir bool
+		size  int64
+		time  string
+	}{
+		{".", true, 4096, "12:00:00"},
+		{"dir/file name", false, 1234567, "12:00:01"},
+		{"dir/été", false, 0, "12:00:02"},
+	}
+	if len(entries) != len(expected) {
+		t.Fatalf("expected %d entries, got %d", len(expected), len(entries))
+	}
+	for i, item := range expected {
+		entry := entries[i]
+		if entry.Path != item.path || entry.IsDir != item.isDir || entry.Size != item.size ||
+			entry.ModTime.Format("15:04:05") != item.time {
+			t.Errorf("entry %d: expected %+v, got %+v", i, item, entry)
+		}
+	}
+}
+
+func TestSimulationTransferSize(t *testing.T) {
+	dest, err := ioutil.TempDir("", "simulate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dest)
+	simulation, err := NewSimulationExecutor("speed=100G,seed=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetExecutor(simulation)
+	defer SetExecutor(&SystemExecutor{})
+
+	paths := core.SrcDstPath{RsyncSourcePath: "rsync://demo/sample/", DestPath: dest}
+	var stdOut bytes.Buffer
+	sessionErr, _, _ := RunRsyncWithRetry(context.Background(),
+		NewOptions(WithDefaultParams([]string{"--dry-run", "--recursive"})), nil, &stdOut, paths)
+	if sessionErr != nil {
+		t.Fatal(sessionErr)
+	}
+	predicted, err := extractBackupSize(&stdOut, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessionErr, _, _ = RunRsyncWithRetry(context.Background(),
+		NewOptions(WithDefaultParams([]string{"--recursive"})), nil, nil, paths)
+	if sessionErr != nil {
+		t.Fatal(sessionErr)
+	}
+	var actual int64
+	err = filepath.Walk(dest, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			actual += info.Size()
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual != int64(*predicted) {
+		t.Errorf("expected %d bytes in destination, got %d", *predicted, actual)
+	}
+}
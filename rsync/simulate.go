@@ -0,0 +1,412 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package rsync
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math/rand"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SIMULATION_ENV_VAR is an environment variable, which enable simulation
+// of RSYNC calls with synthetic folder trees and transfer output,
+// to demonstrate application without RSYNC installed or real data.
+// Value is a comma-separated list of settings, for instance:
+//
+//	GORSYNC_SIMULATE=speed=20M,errors=0.1,seed=7
+//
+// where speed is transfer speed per second, errors is a probability
+// of RSYNC call failure and seed initialize random generator.
+// Any other value, like "1", enable simulation with defaults.
+const SIMULATION_ENV_VAR = "GORSYNC_SIMULATE"
+
+const (
+	// Default transfer speed of simulated RSYNC calls.
+	SIMULATION_DEFAULT_SPEED = 50 * 1024 * 1024
+	// Synthetic folder tree depth, counted from RSYNC source root.
+	simulationTreeDepth = 3
+	// Maximum size of files contained in single synthetic folder.
+	simulationMaxFolderSize = 64 * 1024 * 1024
+	// Name template of synthetic folders and files.
+	simulationFolderName = "folder-%02d"
+	simulationFileName   = "file-%02d.dat"
+)
+
+// Output of "rsync --version" reported in simulation mode.
+const simulationVersionOutput = `rsync  version 3.2.7  protocol version 31
+Copyright (C) 1996-2022 by Andrew Tridgell, Wayne Davison, and others.
+Capabilities:
+    64-bit files, 64-bit inums, 64-bit timestamps, 64-bit long ints,
+    socketpairs, symlinks, symtimes, hardlinks, hardlink-specials,
+    hardlink-symlinks, IPv6, atimes, batchfiles, inplace, append, ACLs,
+    xattrs, optional secluded-args, iconv, prealloc, stop-at, no crtimes
+Checksum list:
+    xxh128 xxh3 xxh64 (xxhash) md5 md4 sha1 none
+Compress list:
+    zstd lz4 zlibx zlib none
+`
+
+var simulationFolderRegexp = regexp.MustCompile(`^folder-\d{2}$`)
+
+// SimulationExecutor emulate RSYNC calls without running any process.
+// Each source path is treated as a root of synthetic folder tree,
+// generated from path hash, so repeated calls are consistent:
+//   - listing (--list-only) print folders of the tree;
+//   - dry run print total size of the folder (or whole subtree);
+//   - transfer print itemized output of synthetic files with
+//     configured speed, create folders and sparse files of the same
+//     size in local destination, so destination size match prediction,
+//     and fail randomly with configured probability.
+type SimulationExecutor struct {
+	sync.Mutex
+	// Transfer speed in bytes per second.
+	Speed int64
+	// Probability of transfer failure in range [0..1].
+	ErrorRate float64
+	random    *rand.Rand
+}
+
+// NewSimulationExecutor create SimulationExecutor with settings
+// in format described for SIMULATION_ENV_VAR.
+func NewSimulationExecutor(settings string) (*SimulationExecutor, error) {
+	v := &SimulationExecutor{Speed: SIMULATION_DEFAULT_SPEED}
+	seed := time.Now().UnixNano()
+	for _, item := range strings.Split(settings, ",") {
+		i := strings.Index(item, "=")
+		if i < 0 {
+			continue
+		}
+		name := strings.TrimSpace(item[:i])
+		value := strings.TrimSpace(item[i+1:])
+		var err error
+		switch name {
+		case "speed":
+			v.Speed, err = parseSimulationSize(value)
+			if err == nil && v.Speed <= 0 {
+				err = fmt.Errorf("speed should be positive")
+			}
+		case "errors":
+			v.ErrorRate, err = strconv.ParseFloat(value, 64)
+			if err == nil && (v.ErrorRate < 0 || v.ErrorRate > 1) {
+				err = fmt.Errorf("error rate should be in range [0..1]")
+			}
+		case "seed":
+			seed, err = strconv.ParseInt(value, 10, 64)
+		default:
+			err = fmt.Errorf("unknown setting")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: can't parse %q: %v", SIMULATION_ENV_VAR, item, err)
+		}
+	}
+	v.random = rand.New(rand.NewSource(seed))
+	return v, nil
+}
+
+// SimulationFromEnv create SimulationExecutor, if simulation
+// enabled by SIMULATION_ENV_VAR, otherwise return nil.
+func SimulationFromEnv() (*SimulationExecutor, error) {
+	settings, ok := os.LookupEnv(SIMULATION_ENV_VAR)
+	if !ok || settings == "" || settings == "0" {
+		return nil, nil
+	}
+	return NewSimulationExecutor(settings)
+}
+
+// parseSimulationSize decode size with optional K, M or G suffix.
+func parseSimulationSize(value string) (int64, error) {
+	multiplier := int64(1)
+	switch strings.ToUpper(value[len(value)-1:]) {
+	case "K":
+		multiplier = 1024
+	case "M":
+		multiplier = 1024 * 1024
+	case "G":
+		multiplier = 1024 * 1024 * 1024
+	}
+	if multiplier > 1 {
+		value = value[:len(value)-1]
+	}
+	size, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return size * multiplier, nil
+}
+
+// isSimulation verify that RSYNC calls are simulated.
+func isSimulation() bool {
+	executor := getExecutor()
+	if print, ok := executor.(*PrintExecutor); ok {
+		executor = print.executor
+	}
+	_, ok := executor.(*SimulationExecutor)
+	return ok
+}
+
+// Start emulate RSYNC call. Command might be prefixed with "nice"
+// and "ionice" calls, so options are looked for after RSYNC name.
+func (v *SimulationExecutor) Start(cmd *Command, stdOut, stdErr io.Writer) (Process, error) {
+	args := append([]string{cmd.Name}, cmd.Args...)
+	for i, item := range args {
+		if item == RSYNC_APP_CMD {
+			args = args[i+1:]
+			break
+		}
+	}
+	options := map[string]bool{}
+	var paths []string
+	for _, item := range args {
+		if strings.HasPrefix(item, "-") {
+			options[item] = true
+		} else {
+			paths = append(paths, item)
+		}
+	}
+	if stdOut == nil {
+		stdOut = io.Discard
+	}
+	if stdErr == nil {
+		stdErr = io.Discard
+	}
+	p := &simulationProcess{done: make(chan ProcessStatus, 1), kill: make(chan struct{})}
+	if len(paths) == 0 {
+		p.done <- ProcessStatus{ExitCode: 1}
+		return p, nil
+	}
+	source := strings.TrimRight(paths[0], "/")
+	recursive := options["--recursive"]
+	switch {
+	case options["--list-only"]:
+		io.WriteString(stdOut, v.listing(source))
+		p.done <- ProcessStatus{}
+	case len(paths) == 1:
+		io.WriteString(stdOut, "demo\tSimulated module\nsample\tSimulated sample data\n")
+		p.done <- ProcessStatus{}
+	case options["--dry-run"]:
+		size := v.folderSize(source, recursive)
+		fmt.Fprintf(stdOut, "\nsent %d bytes  received %d bytes  0.00 bytes/sec\n", 64, 32)
+		fmt.Fprintf(stdOut, "total size is %d  speedup is 1.00 (DRY RUN)\n", size)
+		p.done <- ProcessStatus{}
+	default:
+		dest := paths[len(paths)-1]
+		folders := []string{""}
+		if recursive {
+			folders = v.folders(source, "")
+		}
+		if IsLocalSource(dest) {
+			for _, folder := range folders {
+				err := os.MkdirAll(filepath.Join(dest, folder), 0777)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+		// Only folders structure copied.
+		if options["--exclude=*"] {
+			p.done <- ProcessStatus{}
+			return p, nil
+		}
+		if !IsLocalSource(dest) {
+			dest = ""
+		}
+		v.Lock()
+		fail := v.random.Float64() < v.ErrorRate
+		v.Unlock()
+		go v.transfer(p, source, dest, folders, fail, stdOut, stdErr)
+	}
+	return p, nil
+}
+
+// listing return output of "rsync --list-only" for synthetic folder tree.
+func (v *SimulationExecutor) listing(source string) string {
+	var buf bytes.Buffer
+	date := time.Now().Format("2006/01/02 15:04:05")
+	for _, folder := range v.folders(source, "") {
+		if folder == "" {
+			folder = "."
+		}
+		fmt.Fprintf(&buf, "drwxr-xr-x          4,096 %s %s\n", date, folder)
+	}
+	return buf.String()
+}
+
+// transfer print itemized output of synthetic files with configured
+// speed and create them as sparse files in local destination, if specified.
+// Fail halfway with "partial transfer" exit code, if requested.
+func (v *SimulationExecutor) transfer(p *simulationProcess, source, dest string,
+	folders []string, fail bool, stdOut, stdErr io.Writer) {
+
+	type file struct {
+		name  string
+		size  int64
+		isDir bool
+	}
+	var files []file
+	for _, folder := range folders {
+		if folder != "" {
+			files = append(files, file{name: folder + "/", isDir: true})
+		}
+		sizes := simulationFileSizes(path.Join(source, folder))
+		for i, size := range sizes {
+			files = append(files, file{name: path.Join(folder, fmt.Sprintf(simulationFileName, i+1)),
+				size: size})
+		}
+	}
+	for i, item := range files {
+		if fail && i >= len(files)/2 {
+			fmt.Fprintf(stdErr, "rsync: [sender] send_files failed to open %q: Permission denied (13)\n",
+				item.name)
+			io.WriteString(stdErr, "rsync error: some files/attrs were not transferred "+
+				"(see previous errors) (code 23) at main.c(1338) [sender=3.2.7]\n")
+			p.done <- ProcessStatus{ExitCode: 23}
+			return
+		}
+		select {
+		case <-p.kill:
+			p.done <- ProcessStatus{ExitCode: 20}
+			return
+		case <-time.After(time.Duration(item.size * int64(time.Second) / v.Speed)):
+		}
+		if dest != "" && !item.isDir {
+			err := createSparseFile(filepath.Join(dest, item.name), item.size)
+			if err != nil {
+				fmt.Fprintf(stdErr, "rsync: [receiver] write failed on %q: %v\n", item.name, err)
+				io.WriteString(stdErr, "rsync error: error in file IO (code 11) "+
+					"at receiver.c(378) [receiver=3.2.7]\n")
+				p.done <- ProcessStatus{ExitCode: 11}
+				return
+			}
+		}
+		if item.isDir {
+			fmt.Fprintf(stdOut, "cd+++++++++ %s\n", item.name)
+		} else {
+			fmt.Fprintf(stdOut, ">f+++++++++ %s\n", item.name)
+		}
+	}
+	p.done <- ProcessStatus{}
+}
+
+// createSparseFile create file of specified size, which doesn't
+// occupy disk space, since no data is written.
+func createSparseFile(filePath string, size int64) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	err = file.Truncate(size)
+	if err2 := file.Close(); err == nil {
+		err = err2
+	}
+	return err
+}
+
+// folders return relative paths of synthetic folder and all nested folders.
+func (v *SimulationExecutor) folders(source, folder string) []string {
+	list := []string{folder}
+	for _, child := range simulationChildFolders(path.Join(source, folder)) {
+		list = append(list, v.folders(source, path.Join(folder, child))...)
+	}
+	return list
+}
+
+// folderSize return size of files in synthetic folder,
+// including nested folders, if recursive requested.
+func (v *SimulationExecutor) folderSize(source string, recursive bool) int64 {
+	folders := []string{""}
+	if recursive {
+		folders = v.folders(source, "")
+	}
+	var size int64
+	for _, folder := range folders {
+		for _, item := range simulationFileSizes(path.Join(source, folder)) {
+			size += item
+		}
+	}
+	return size
+}
+
+// simulationHash return hash of path, used as a seed
+// to generate synthetic content of the folder.
+func simulationHash(folderPath, salt string) uint64 {
+	h := fnv.New64a()
+	io.WriteString(h, strings.TrimRight(folderPath, "/"))
+	io.WriteString(h, salt)
+	return h.Sum64()
+}
+
+// simulationChildFolders return names of synthetic folders nested to the folder.
+// Depth is identified by the count of synthetic names at the end of the path.
+func simulationChildFolders(folderPath string) []string {
+	depth := 0
+	items := strings.Split(strings.TrimRight(folderPath, "/"), "/")
+	for i := len(items) - 1; i >= 0 && simulationFolderRegexp.MatchString(items[i]); i-- {
+		depth++
+	}
+	if depth >= simulationTreeDepth {
+		return nil
+	}
+	count := int(simulationHash(folderPath, "folders") % 4)
+	if depth == 0 {
+		count += 2
+	}
+	var names []string
+	for i := 1; i <= count; i++ {
+		names = append(names, fmt.Sprintf(simulationFolderName, i))
+	}
+	return names
+}
+
+// simulationFileSizes return sizes of synthetic files located in the folder.
+func simulationFileSizes(folderPath string) []int64 {
+	h := simulationHash(folderPath, "files")
+	count := 1 + int(h%12)
+	total := int64(h>>8) % simulationMaxFolderSize
+	sizes := make([]int64, count)
+	for i := range sizes {
+		sizes[i] = total/int64(count) + 1
+	}
+	return sizes
+}
+
+// simulationProcess is an emulated RSYNC call.
+type simulationProcess struct {
+	done     chan ProcessStatus
+	kill     chan struct{}
+	killOnce sync.Once
+}
+
+func (v *simulationProcess) Done() <-chan ProcessStatus {
+	return v.done
+}
+
+func (v *simulationProcess) Kill() error {
+	v.killOnce.Do(func() {
+		close(v.kill)
+	})
+	return nil
+}
+
+// getSimulationCapabilities return RSYNC capabilities reported in simulation mode.
+func getSimulationCapabilities() (*Capabilities, error) {
+	return parseCapabilities(bytes.NewBufferString(simulationVersionOutput))
+}
@@ -12,6 +12,7 @@
 package rsync
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"errors"
@@ -21,13 +22,14 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/d2r2/go-rsync/core"
 	"github.com/d2r2/go-rsync/locale"
 )
 
 // ObtainDirLocalSize parse STDOUT from RSYNC dry-run execution to extract local size of directory without nested folders.
-func ObtainDirLocalSize(ctx context.Context, password *string, dir *core.Dir,
+func ObtainDirLocalSize(ctx context.Context, password *string, filter *FileFilter, dir *core.Dir,
 	retryCount *int, rsyncProtocol string, log *Logging) (*core.FolderSize, error) {
 
 	// RSYNC "dry run" to get total size of backup
@@ -35,8 +37,9 @@ func ObtainDirLocalSize(ctx context.Context, password *string, dir *core.Dir,
 	options := NewOptions(WithDefaultParams([]string{"--dry-run", "--compress"})).
 		AddParams("--dirs").
 		SetRetryCount(retryCount).
-		SetAuthPassword(password)
-	sessionErr, _, _ := RunRsyncWithRetry(ctx, options, log, &stdOut, dir.Paths)
+		SetAuthPassword(password).
+		SetFileFilter(filter)
+	sessionErr, _, _ := RunRsyncWithRetry(ctx, options, log, &stdOut, dir.GetPaths())
 	if sessionErr != nil {
 		return nil, sessionErr
 	}
@@ -45,14 +48,14 @@ func ObtainDirLocalSize(ctx context.Context, password *string, dir *core.Dir,
 		return nil, err
 	}
 	if backupSize != nil {
-		lg.Debugf("Get rsync %q size: %v", dir.Paths.RsyncSourcePath,
+		lg.Debugf("Get rsync %q size: %v", dir.GetPaths().RsyncSourcePath,
 			core.GetReadableSize(*backupSize))
 	}
 	return backupSize, nil
 }
 
 // ObtainDirLocalSize parse STDOUT from RSYNC dry-run execution to extract full size of directory.
-func ObtainDirFullSize(ctx context.Context, password *string, dir *core.Dir,
+func ObtainDirFullSize(ctx context.Context, password *string, filter *FileFilter, dir *core.Dir,
 	retryCount *int, rsyncProtocol string, log *Logging) (*core.FolderSize, error) {
 
 	// RSYNC "dry run" to get total size of backup
@@ -60,8 +63,9 @@ func ObtainDirFullSize(ctx context.Context, password *string, dir *core.Dir,
 	options := NewOptions(WithDefaultParams([]string{"--dry-run", "--compress"})).
 		AddParams("--recursive", "--include=*/").
 		SetRetryCount(retryCount).
-		SetAuthPassword(password)
-	sessionErr, _, _ := RunRsyncWithRetry(ctx, options, log, &stdOut, dir.Paths)
+		SetAuthPassword(password).
+		SetFileFilter(filter)
+	sessionErr, _, _ := RunRsyncWithRetry(ctx, options, log, &stdOut, dir.GetPaths())
 	if sessionErr != nil {
 		return nil, sessionErr
 	}
@@ -94,11 +98,116 @@ func extractBackupSize(stdOut *bytes.Buffer, rsyncProtocol string) (*core.Folder
 	}
 }
 
+// Expression should parse a line variant:
+//
+//	drwxr-xr-x          4,096 2019/03/01 12:00:00 folder/subfolder
+//	-rw-r--r--              0 2019/03/01 12:00:00 folder/.backupignore
+var listOnlyRegexp = regexp.MustCompile(
+	`^(?P<perms>\S+)\s+(?P<size>[\d,.]+[KMGTP]?)\s+(?P<date>\d{4}/\d{2}/\d{2})\s+(?P<time>\d{2}:\d{2}:\d{2})\s(?P<path>.+)$`)
+
+// Layout of modification time in RSYNC listing, which is
+// printed in local time zone of RSYNC source.
+const listOnlyTimeLayout = "2006/01/02 15:04:05"
+
+// RSYNC escape non-printable chars in file names as "\#ooo" (octal code).
+var listOnlyEscapeRegexp = regexp.MustCompile(`\\#[0-7]{3}`)
+
+// ListDirTree run RSYNC in listing mode to obtain recursively all folders
+// of the source, plus files with name sigFileIgnoreBackup. Nothing is
+// copied to the local file system. Extra params might be nil.
+func ListDirTree(ctx context.Context, password *string, rsyncSourcePath string,
+	sigFileIgnoreBackup string, retryCount *int, log *Logging,
+	extraParams []string) ([]core.DirTreeEntry, error) {
+
+	var stdOut bytes.Buffer
+	options := NewOptions(WithDefaultParams([]string{"--list-only", "--recursive"})).
+		AddParams("--include=*/").
+		AddParams(fmt.Sprintf("--include=%s", sigFileIgnoreBackup)).
+		AddParams("--exclude=*").
+		AddParams(extraParams...).
+		SetRetryCount(retryCount).
+		SetAuthPassword(password)
+	paths := core.SrcDstPath{RsyncSourcePath: rsyncSourcePath}
+	sessionErr, _, _ := RunRsyncWithRetry(ctx, options, log, &stdOut, paths)
+	if sessionErr != nil {
+		return nil, sessionErr
+	}
+	return parseListOnlyOutput(&stdOut)
+}
+
+// ListFiles run RSYNC in listing mode to obtain recursively all files
+// and folders of the source, which pass file filter (if specified).
+// Nothing is copied to the local file system. Extra params might be nil.
+func ListFiles(ctx context.Context, password *string, filter *FileFilter,
+	rsyncSourcePath string, retryCount *int, log *Logging,
+	extraParams []string) ([]core.DirTreeEntry, error) {
+
+	var stdOut bytes.Buffer
+	options := NewOptions(WithDefaultParams([]string{"--list-only", "--recursive"})).
+		AddParams(extraParams...).
+		SetRetryCount(retryCount).
+		SetAuthPassword(password).
+		SetFileFilter(filter)
+	paths := core.SrcDstPath{RsyncSourcePath: rsyncSourcePath}
+	sessionErr, _, _ := RunRsyncWithRetry(ctx, options, log, &stdOut, paths)
+	if sessionErr != nil {
+		return nil, sessionErr
+	}
+	return parseListOnlyOutput(&stdOut)
+}
+
+// parseListOnlyOutput decode RSYNC STDOUT output produced with --list-only option.
+func parseListOnlyOutput(stdOut *bytes.Buffer) ([]core.DirTreeEntry, error) {
+	var entries []core.DirTreeEntry
+	scanner := bufio.NewScanner(stdOut)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		m := core.FindStringSubmatchIndexes(listOnlyRegexp, line)
+		perms, ok1 := m["perms"]
+		path, ok2 := m["path"]
+		if !ok1 || !ok2 {
+			// skip empty lines and extra messages
+			continue
+		}
+		name := listOnlyEscapeRegexp.ReplaceAllStringFunc(line[path[0]:path[1]],
+			func(code string) string {
+				b, _ := strconv.ParseUint(code[2:], 8, 8)
+				return string([]byte{byte(b)})
+			})
+		entry := core.DirTreeEntry{Path: name,
+			IsDir: strings.HasPrefix(line[perms[0]:perms[1]], "d")}
+		if size, ok := m["size"]; ok {
+			// ignore error, since size is used to detect changes only;
+			// digits might be grouped with locale specific separator
+			entry.Size, _ = strconv.ParseInt(strings.NewReplacer(",", "", ".", "").
+				Replace(line[size[0]:size[1]]), 10, 64)
+		}
+		if date, ok := m["date"]; ok {
+			if tm, ok := m["time"]; ok {
+				// ignore error, since time is used to detect changes only
+				entry.ModTime, _ = time.ParseInLocation(listOnlyTimeLayout,
+					line[date[0]:date[1]]+" "+line[tm[0]:tm[1]], time.Local)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
 // GetPathStatus verify that RSYNC source path is valid.
 // For this RSYNC is launched, than exit status is evaluated.
 func GetPathStatus(ctx context.Context, password *string,
 	sourceRSync string, recursive bool) error {
 
+	sourceRSync, err := core.CanonicalRsyncPath(sourceRSync)
+	if err != nil {
+		return err
+	}
+
 	tempDir, err := ioutil.TempDir("", "backup_dir_status_")
 	if err != nil {
 		return err
@@ -123,65 +232,93 @@ func GetPathStatus(ctx context.Context, password *string,
 
 // NormalizeRsyncURL normalize RSYNC URL by:
 // 1) remove user specification (if found).
-// 2) remove excess '/' chars in path following host.
+// 2) remove default port and excess '/' chars in path following host.
+// 3) convert "host::module" notation to "rsync://host/module".
 func NormalizeRsyncURL(rsyncURL string) string {
-	_, host, path := parseRsyncURL(strings.TrimSpace(rsyncURL))
-	path = removeExcessSlashChars(path)
-	// assemble RSYNC URL path back, but without user specification
-	newRsyncURL := fmt.Sprintf("rsync://%s%s", host, path)
-	// lg.Debugf("Original RSYNC URL: %s", rsyncURL)
-	// lg.Debugf("Modified RSYNC URL: %s", newRsyncURL)
-	return newRsyncURL
+	url, err := core.ParseRsyncURL(rsyncURL)
+	if err != nil {
+		// Path not recognized as RSYNC URL was reduced
+		// to bare prefix before, so keep it to match
+		// signatures of previously made backups.
+		return "rsync://"
+	}
+	return url.Normalize()
 }
 
-// parseRsyncURL disassemble RSYNC URL to the parts.
-// This parts include: rsync prefix, user (if specified), host and path.
-func parseRsyncURL(rsyncURL string) (user, host, path string) {
-	re := regexp.MustCompile(`(?i:^rsync://(?P<user>[^@]*@)?(?P<host>[^/]*)(?P<path>.*)$)`)
-	m := core.FindStringSubmatchIndexes(re, rsyncURL)
-	if len(m) > 0 {
-		grUser := "user"
-		if _, ok := m[grUser]; ok {
-			start := m[grUser][0]
-			end := m[grUser][1]
-			user = rsyncURL[start:end]
-		}
-		grHost := "host"
-		if _, ok := m[grHost]; ok {
-			start := m[grHost][0]
-			end := m[grHost][1]
-			host = rsyncURL[start:end]
-		}
-		grPath := "path"
-		if _, ok := m[grPath]; ok {
-			start := m[grPath][0]
-			end := m[grPath][1]
-			path = rsyncURL[start:end]
-		}
+// GetRsyncURLHostPath return normalized host, module name and path
+// of RSYNC URL without user and port. Return empty string, if path is not
+// recognized as RSYNC URL or refer to daemon root.
+func GetRsyncURLHostPath(rsyncURL string) string {
+	url, err := core.ParseRsyncURL(rsyncURL)
+	if err != nil {
+		return ""
 	}
-	return
+	path := strings.TrimSuffix(core.RemoveExcessSlashChars(url.Path), "/")
+	if path == "" || url.Host == "" {
+		return ""
+	}
+	return strings.ToLower(url.Host) + path
 }
 
-// removeExcessSlashChars remove excess path divider in RSYNC path.
-func removeExcessSlashChars(path string) string {
-	var buf bytes.Buffer
-	lastCharIsSlash := false
-	for _, ch := range path {
-		if ch == '/' {
-			if lastCharIsSlash {
-				continue
-			}
-			lastCharIsSlash = true
-		} else {
-			lastCharIsSlash = false
-		}
-		buf.WriteRune(ch)
+// DaemonModule describe module exported by RSYNC daemon.
+type DaemonModule struct {
+	Name    string
+	Comment string
+	// RSYNC URL of module to use as source path.
+	URL string
+}
+
+// Time to wait for RSYNC daemon response, while listing modules.
+const listModulesTimeout = 30 * time.Second
+
+// ListDaemonModules query RSYNC daemon for exported modules, as "rsync host::" does.
+// Host might be specified as "[user@]host[:port]", "host::" or "rsync://host".
+func ListDaemonModules(ctx context.Context, password *string, host string) ([]DaemonModule, error) {
+	host = strings.TrimSpace(host)
+	if !core.IsRsyncDaemonURL(host) {
+		host = "rsync://" + host
+	}
+	url, err := core.ParseRsyncURL(host)
+	if err != nil {
+		return nil, err
 	}
+	url.Path = "/"
 
-	path = buf.String()
-	if path[len(path)-1] == '/' {
-		path = path[:len(path)-1]
+	var stdOut bytes.Buffer
+	options := NewOptions(nil).
+		SetAuthPassword(password).
+		SetTimeouts(listModulesTimeout, listModulesTimeout)
+	paths := core.SrcDstPath{RsyncSourcePath: url.String()}
+	sessionErr, _, _ := RunRsyncWithRetry(ctx, options, nil, &stdOut, paths)
+	if sessionErr != nil {
+		return nil, sessionErr
 	}
+	return parseDaemonModules(&stdOut, url)
+}
 
-	return path
+// parseDaemonModules decode RSYNC STDOUT output with list of daemon modules.
+// Each module is printed as "name<TAB>comment", so lines without
+// TAB char (for instance, daemon greeting message) are skipped.
+func parseDaemonModules(stdOut *bytes.Buffer, url *core.RsyncURL) ([]DaemonModule, error) {
+	var modules []DaemonModule
+	scanner := bufio.NewScanner(stdOut)
+	for scanner.Scan() {
+		line := scanner.Text()
+		i := strings.Index(line, "\t")
+		if i < 0 {
+			continue
+		}
+		name := strings.TrimSpace(line[:i])
+		if name == "" || strings.ContainsAny(name, " /") {
+			continue
+		}
+		moduleURL := *url
+		moduleURL.Path = "/" + name + "/"
+		modules = append(modules, DaemonModule{Name: name,
+			Comment: strings.TrimSpace(line[i+1:]), URL: moduleURL.String()})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return modules, nil
 }
@@ -0,0 +1,118 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package rsync
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/d2r2/go-rsync/core"
+)
+
+const (
+	// Number of consecutive connection failures,
+	// which signify network outage.
+	NETWORK_FAILURE_THRESHOLD = 2
+	// Interval between probes to verify that source is reachable again.
+	NETWORK_PROBE_INTERVAL = 15 * time.Second
+)
+
+// NetworkStateChanged is a call-back function, which report that
+// backup is paused waiting for network, or resumed once network restored.
+type NetworkStateChanged func(sourceRsync string, waiting bool, err error)
+
+// NetworkWatchdog detect repeated connection failures of RSYNC calls
+// and pause them until source become reachable again, instead of
+// spending retry count. Single instance shared by all calls of the session.
+type NetworkWatchdog struct {
+	sync.Mutex
+	// Maximum time to wait for network, zero to wait until terminated
+	MaxWait       time.Duration
+	ProbeInterval time.Duration
+	StateChanged  NetworkStateChanged
+	failures      int
+}
+
+// NewNetworkWatchdog create watchdog, which wait
+// no longer than maxWait for network to restore.
+func NewNetworkWatchdog(maxWait time.Duration, stateChanged NetworkStateChanged) *NetworkWatchdog {
+	v := &NetworkWatchdog{MaxWait: maxWait, ProbeInterval: NETWORK_PROBE_INTERVAL,
+		StateChanged: stateChanged}
+	return v
+}
+
+// IsConnectionError verify that RSYNC call
+// failed due to network or daemon connection issue.
+func IsConnectionError(err error) bool {
+	if IsCallFailedError(err) {
+		return err.(*CallFailedError).Class() == EC_CONNECTION
+	}
+	return false
+}
+
+// reset consecutive connection failures counter.
+func (v *NetworkWatchdog) reset() {
+	v.Lock()
+	defer v.Unlock()
+	v.failures = 0
+}
+
+// failed register RSYNC call failure and return true, if
+// consecutive connection failures signify network outage.
+func (v *NetworkWatchdog) failed(err error, paths core.SrcDstPath) bool {
+	v.Lock()
+	defer v.Unlock()
+	if !IsConnectionError(err) || IsLocalSource(paths.RsyncSourcePath) {
+		v.failures = 0
+		return false
+	}
+	v.failures++
+	return v.failures >= NETWORK_FAILURE_THRESHOLD
+}
+
+// waitForNetwork probe source periodically until it become reachable.
+// Return true, if network restored and RSYNC call should be repeated,
+// false if maximum waiting time exceeded.
+func (v *NetworkWatchdog) waitForNetwork(ctx context.Context, password *string,
+	err error, paths core.SrcDstPath) (bool, error) {
+
+	if v.StateChanged != nil {
+		v.StateChanged(paths.RsyncSourcePath, true, err)
+	}
+	start := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return false, &ProcessTerminatedError{}
+		case <-time.After(v.ProbeInterval):
+		}
+		err := GetPathStatus(ctx, password, paths.RsyncSourcePath, false)
+		if err == nil {
+			v.reset()
+			if v.StateChanged != nil {
+				v.StateChanged(paths.RsyncSourcePath, false, nil)
+			}
+			return true, nil
+		} else if IsProcessTerminatedError(err) {
+			return false, err
+		}
+		lg.Debugf("Source %q is still unreachable: %v", paths.RsyncSourcePath, err)
+		if v.MaxWait > 0 && time.Since(start) > v.MaxWait {
+			v.reset()
+			if v.StateChanged != nil {
+				v.StateChanged(paths.RsyncSourcePath, false, err)
+			}
+			return false, nil
+		}
+	}
+}
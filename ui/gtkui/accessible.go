@@ -0,0 +1,43 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package gtkui
+
+// #cgo pkg-config: gtk+-3.0
+// #include <stdlib.h>
+// #include <gtk/gtk.h>
+import "C"
+import (
+	"unsafe"
+
+	"github.com/d2r2/gotk3/gtk"
+)
+
+// SetAccessibleNameAndDescription assign name and description of ATK accessible
+// object bound to the widget, which are announced by screen readers.
+// Used for icon-only buttons and composite widgets, which have no text to
+// provide accessible name automatically. Empty values are ignored.
+func SetAccessibleNameAndDescription(widget *gtk.Widget, name, description string) {
+	acc := C.gtk_widget_get_accessible((*C.GtkWidget)(unsafe.Pointer(widget.GObject)))
+	if acc == nil {
+		return
+	}
+	if name != "" {
+		cstr := C.CString(name)
+		defer C.free(unsafe.Pointer(cstr))
+		C.atk_object_set_name(acc, cstr)
+	}
+	if description != "" {
+		cstr := C.CString(description)
+		defer C.free(unsafe.Pointer(cstr))
+		C.atk_object_set_description(acc, cstr)
+	}
+}
@@ -15,6 +15,8 @@ import (
 	"context"
 	"errors"
 	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"sync"
 	"syscall"
@@ -26,7 +28,6 @@ import (
 	"github.com/d2r2/go-rsync/locale"
 	"github.com/d2r2/go-rsync/rsync"
 	shell "github.com/d2r2/go-shell"
-	"github.com/d2r2/gotk3/gdk"
 	"github.com/d2r2/gotk3/glib"
 	"github.com/d2r2/gotk3/gtk"
 	"github.com/davecgh/go-spew/spew"
@@ -79,20 +80,23 @@ func createQuitAction(win *gtk.Window, backupSync *BackupSessionStatus, supplime
 	_, err = act.Connect("activate", func(action *glib.SimpleAction, param *glib.Variant) {
 		name, state, err := GetActionNameAndState(action)
 		if err != nil {
-			lg.Fatal(err)
+			reportError(win, err)
+			return
 		}
 		lg.Debugf("%v action activated with current state %v and args %v",
 			name, state, param)
 
 		quit, err := interruptBackupProcess(win, backupSync)
 		if err != nil {
-			lg.Fatal(err)
+			reportError(win, err)
+			return
 		}
 
 		if quit {
 			app, err := win.GetApplication()
 			if err != nil {
-				lg.Fatal(err)
+				reportError(win, err)
+				return
 			}
 			if backupSync.IsRunning() {
 				backupSync.Stop()
@@ -122,14 +126,16 @@ func createAboutAction(win *gtk.Window, appSettings *SettingsStore) (glib.IActio
 	_, err = act.Connect("activate", func(action *glib.SimpleAction, param *glib.Variant) {
 		name, state, err := GetActionNameAndState(action)
 		if err != nil {
-			lg.Fatal(err)
+			reportError(win, err)
+			return
 		}
 		lg.Debugf("%v action activated with current state %v and args %v",
 			name, state, param)
 
 		dlg, err := CreateAboutDialog(appSettings)
 		if err != nil {
-			lg.Fatal(err)
+			reportError(win, err)
+			return
 		}
 
 		dlg.SetTransientFor(win)
@@ -153,7 +159,8 @@ func createHelpAction(win *gtk.Window) (glib.IAction, error) {
 	_, err = act.Connect("activate", func(action *glib.SimpleAction, param *glib.Variant) {
 		name, state, err := GetActionNameAndState(action)
 		if err != nil {
-			lg.Fatal(err)
+			reportError(win, err)
+			return
 		}
 		lg.Debugf("%v action activated with current state %v and args %v",
 			name, state, param)
@@ -168,6 +175,28 @@ func createHelpAction(win *gtk.Window) (glib.IAction, error) {
 	return act, nil
 }
 
+// Keyboard shortcuts of main window actions.
+var mainWindowAccels = []struct {
+	action string
+	accels []string
+}{
+	{"win.RunBackupAction", []string{"F5", "<Primary>r"}},
+	{"win.StopBackupAction", []string{"<Shift>F5", "<Primary>period"}},
+	{"win.PreferenceAction", []string{"<Primary>comma"}},
+	{"win.CheckProfileAction", []string{"F7"}},
+	{"win.LogViewerAction", []string{"<Primary>l"}},
+	{"win.CatalogSearchAction", []string{"<Primary>f"}},
+	{"win.HelpAction", []string{"F1"}},
+	{"win.QuitAction", []string{"<Primary>q"}},
+}
+
+// setMainWindowAccels assign keyboard shortcuts to main window actions.
+func setMainWindowAccels(app *gtk.Application) {
+	for _, item := range mainWindowAccels {
+		app.SetAccelsForAction(item.action, item.accels)
+	}
+}
+
 // createMenuModelForPopover construct menu for popover button.
 func createMenuModelForPopover() (glib.IMenuModel, error) {
 	main, err := glib.MenuNew()
@@ -184,12 +213,21 @@ func createMenuModelForPopover() (glib.IMenuModel, error) {
 	}
 	section.Append(locale.T(MsgAppWindowAboutMenuCaption, nil), "win.AboutAction")
 	section.Append(locale.T(MsgAppWindowHelpMenuCaption, nil), "win.HelpAction")
+	section.Append(locale.T(MsgAppWindowDiagnosticsMenuCaption, nil), "win.CollectDiagnosticsAction")
 	main.AppendSection("", section)
 
 	section, err = glib.MenuNew()
 	if err != nil {
 		return nil, err
 	}
+	section.Append(locale.T(MsgAppWindowCheckProfileMenuCaption, nil), "win.CheckProfileAction")
+	section.Append(locale.T(MsgAppWindowLogViewerMenuCaption, nil), "win.LogViewerAction")
+	section.Append(locale.T(MsgAppWindowDiskUsageMenuCaption, nil), "win.DiskUsageAction")
+	section.Append(locale.T(MsgAppWindowSkippedMenuCaption, nil), "win.IgnoreSignatureAction")
+	section.Append(locale.T(MsgAppWindowSearchFilesMenuCaption, nil), "win.CatalogSearchAction")
+	section.Append(locale.T(MsgAppWindowSessionListMenuCaption, nil), "win.SessionListAction")
+	section.Append(locale.T(MsgAppWindowBackupDaemonMenuCaption, nil), "win.BackupDaemonAction")
+	section.Append(locale.T(MsgAppWindowIntegrityMenuCaption, nil), "win.VerifySessionAction")
 	section.Append(locale.T(MsgAppWindowPreferencesMenuCaption, nil), "win.PreferenceAction")
 	main.AppendSection("", section)
 
@@ -205,12 +243,15 @@ func createMenuModelForPopover() (glib.IMenuModel, error) {
 	return main, nil
 }
 
+// Preference dialog opened, if any.
+var preferenceDialog *gtk.ApplicationWindow
+
 // createPreferenceAction constructs multi-page preference dialog
 // with save/restore functionality to/from the GLib GSettings object.
 // Action activation require to have GLib Setting Schema
 // preliminary installed, otherwise will not work raising error.
 // Installation bash script from app folder must be performed in advance.
-func createPreferenceAction(mainWin *gtk.ApplicationWindow, profile *gtk.ComboBox) (glib.IAction, error) {
+func createPreferenceAction(mainWin *gtk.ApplicationWindow) (glib.IAction, error) {
 	act, err := glib.SimpleActionNew("PreferenceAction", nil)
 	if err != nil {
 		return nil, err
@@ -219,65 +260,31 @@ func createPreferenceAction(mainWin *gtk.ApplicationWindow, profile *gtk.ComboBo
 	_, err = act.Connect("activate", func(action *glib.SimpleAction, param *glib.Variant) {
 		name, state, err := GetActionNameAndState(action)
 		if err != nil {
-			lg.Fatal(err)
+			reportError(mainWin, err)
+			return
 		}
 		lg.Debugf("%v action activated with current state %v and args %v",
 			name, state, param)
 
 		app, err := mainWin.GetApplication()
 		if err != nil {
-			lg.Fatal(err)
+			reportError(mainWin, err)
+			return
 		}
 
 		extraMsg := locale.T(MsgSchemaConfigDlgSchemaErrorAdvise,
 			struct{ ScriptName string }{ScriptName: "gs_schema_install.sh"})
 		found, err := CheckSchemaSettingsIsInstalled(SETTINGS_SCHEMA_ID, app, &extraMsg)
 		if err != nil {
-			lg.Fatal(err)
+			reportError(mainWin, err)
+			return
 		}
 
 		if found {
-
-			profileChanged := make(chan struct{})
-			var once sync.Once
-			changedFunc := func() {
-				once.Do(func() {
-					close(profileChanged)
-				})
-			}
-
-			win, err := CreatePreferenceDialog(SETTINGS_SCHEMA_ID, SETTINGS_SCHEMA_PATH, mainWin, changedFunc)
-			if err != nil {
-				lg.Fatal(err)
-			}
-
-			win.ShowAll()
-			win.Show()
-
-			_, err = win.Connect("destroy", func(window *gtk.ApplicationWindow) {
-				lg.Debug("Destroy window")
-
-				changed := false
-				select {
-				case <-profileChanged:
-					changed = true
-				default:
-				}
-
-				if changed {
-					lst, err := getProfileList()
-					if err != nil {
-						lg.Fatal(err)
-					}
-					err = UpdateNameValueCombo(profile, lst)
-					if err != nil {
-						lg.Fatal(err)
-					}
-					profile.SetActiveID("")
-				}
-			})
+			err = showPreferenceDialog(mainWin, "")
 			if err != nil {
-				lg.Fatal(err)
+				reportError(mainWin, err)
+				return
 			}
 		}
 
@@ -289,6 +296,31 @@ func createPreferenceAction(mainWin *gtk.ApplicationWindow, profile *gtk.ComboBo
 	return act, nil
 }
 
+// showPreferenceDialog open preference dialog with page pageID selected
+// (first one, if empty). Changes are applied to main window on the fly
+// by SettingsWatcher.
+func showPreferenceDialog(mainWin *gtk.ApplicationWindow, pageID string) error {
+	win, err := CreatePreferenceDialog(SETTINGS_SCHEMA_ID, SETTINGS_SCHEMA_PATH, mainWin, nil, pageID)
+	if err != nil {
+		return err
+	}
+
+	win.ShowAll()
+	win.Show()
+
+	preferenceDialog = win
+	_, err = win.Connect("destroy", func(window *gtk.ApplicationWindow) {
+		lg.Debug("Destroy window")
+		if preferenceDialog == window {
+			preferenceDialog = nil
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 // enableAction finds GAction by name and enable/disable it.
 func enableAction(win *gtk.ApplicationWindow, actionName string, enable bool) error {
 	act := win.LookupAction(actionName)
@@ -305,6 +337,24 @@ func enableAction(win *gtk.ApplicationWindow, actionName string, enable bool) er
 	return nil
 }
 
+// activateAction find action by name and activate it, if enabled.
+func activateAction(win *gtk.ApplicationWindow, actionName string) error {
+	act := win.LookupAction(actionName)
+	if act == nil {
+		err := errors.New(locale.T(MsgActionDoesNotFound,
+			struct{ ActionName string }{ActionName: actionName}))
+		return err
+	}
+	action, err := glib.SimpleActionFromAction(act)
+	if err != nil {
+		return err
+	}
+	if action.GetEnabled() {
+		action.Activate(nil)
+	}
+	return nil
+}
+
 // EmptySpaceRecover used to try to recover from RSYNC critical error, caused
 // by out of space state. Main entry ErrorHook is trying heuristically
 // identify out of space symptoms and then check free space size.
@@ -338,9 +388,9 @@ func (v *EmptySpaceRecover) ErrorHook(err error, paths core.SrcDstPath, predicte
 			v.backupLog.Notifyf(locale.T(MsgLogBackupStageOutOfSpaceWarning,
 				struct{ SizeLeft string }{SizeLeft: core.FormatSize(freeSpace, true)}))
 
-			response, err2 := outOfSpaceDialogAsync(&v.main.Window, paths, freeSpace)
+			response, err2 := outOfSpaceDialogAsync(&v.main.Window, paths, freeSpace, erro)
 			if err2 != nil {
-				lg.Fatal(err2)
+				return retryLeft, err2
 			}
 
 			if response == OutOfSpaceRetry {
@@ -375,12 +425,193 @@ func traceLongRunningContext(ctx *ContextPack) chan struct{} {
 
 // performFullBackup run backup process, which include 1st and 2nd passes.
 func performFullBackup(backupSync *BackupSessionStatus, notifier *NotifierUI,
-	win *gtk.ApplicationWindow, config *backup.Config, modules []backup.Module, destPath string) {
+	win *gtk.ApplicationWindow, config *backup.Config, modules []backup.Module, destPath string,
+	mount *backup.MountTarget, release *destinationRelease) {
 
 	ctx := backupSync.Start()
 	done := traceLongRunningContext(ctx)
 	defer close(done)
 	defer backupSync.Done(ctx.Context)
+	metricsServer.SessionStarted(notifier.profile)
+
+	backupLog, archive := createSessionLog(notifier)
+	if archive != nil {
+		defer archive.Close()
+	}
+
+	// Mount destination device or network share, if requested by profile.
+	if mount != nil {
+		err := mountDestination(mount, destPath, backupLog)
+		if err != nil {
+			notifier.ReportCompletion(0, err, nil, true)
+			return
+		}
+		defer releaseDestinationMount(mount, backupLog)
+	}
+	// Flush and eject destination before mount release.
+	defer release.apply(destPath, notifier, backupLog)
+
+	// Load RSYNC performance statistics of previous sessions
+	// to tune automatic backup block size.
+	stats, err := backup.FindModuleStatistics(backup.LocalLog, destPath, modules)
+	if err != nil {
+		lg.Warn(err)
+	}
+	config.ModuleStatistics = stats
+	// Ask whether to skip RSYNC source failed with critical error.
+	config.ModuleErrorHook = func(sourceRsync string, err error) bool {
+		skip, err2 := moduleErrorDialogAsync(&win.Window, sourceRsync, err)
+		if err2 != nil {
+			lg.Panic(err2)
+		}
+		return skip
+	}
+	// Ask whether to proceed with RSYNC source lost too many files.
+	config.SafeDeleteHook = func(sourceRsync string, fileCount int, size core.FolderSize) bool {
+		proceed, err2 := safeDeleteDialogAsync(&win.Window, sourceRsync, fileCount, size)
+		if err2 != nil {
+			lg.Panic(err2)
+		}
+		return proceed
+	}
+	config.DestLockHook = createDestLockHook(win)
+
+	// Run 1st stage to prepare backup plan.
+	config.PlanStageStop = backupSync.StartPlanStage()
+	plan, progress, err := backup.BuildBackupPlan(ctx.Context, backupLog, config, modules, notifier)
+	// Plan stage interrupted by user: ask whether to proceed with
+	// RSYNC sources estimated so far, or resume estimation of the rest.
+	for backup.IsPlanStageInterruptedError(err) {
+		response, err2 := planInterruptedDialogAsync(&win.Window, plan)
+		if err2 != nil {
+			lg.Panic(err2)
+		}
+		if response == PlanInterruptedResume {
+			config.PlanStageStop = backupSync.StartPlanStage()
+			err = plan.ResumePlan(progress)
+		} else if response == PlanInterruptedProceed {
+			backupLog.Info(locale.T(MsgAppWindowPartialPlanAccepted,
+				struct{ Estimated, Total int }{Estimated: len(plan.Nodes),
+					Total: len(plan.Nodes) + len(plan.Pending)}))
+			err = nil
+		} else {
+			backupSync.FinishPlanStage()
+			backupLog.Info(locale.T(MsgAppWindowBackupPlanRejected, nil))
+			notifier.ReportCompletion(0, &rsync.ProcessTerminatedError{}, nil, true)
+			progress.Close()
+			return
+		}
+	}
+	backupSync.FinishPlanStage()
+	if err == nil {
+		lg.Debugf("Backup node's dir trees: %+v", plan)
+
+		// Ask to start data transfer with plan summary, if requested.
+		start, err2 := confirmBackupPlan(win, plan, destPath)
+		if err2 != nil {
+			lg.Panic(err2)
+		}
+		if !start {
+			backupLog.Info(locale.T(MsgAppWindowBackupPlanRejected, nil))
+			notifier.ReportCompletion(0, &rsync.ProcessTerminatedError{}, nil, true)
+			progress.Close()
+			return
+		}
+
+		// Create empty space recover hook.
+		emptySpaceRecover := &EmptySpaceRecover{main: win, backupLog: backupLog}
+		// Run 2nd stage to perform backup itself.
+		err = plan.RunBackup(progress, destPath, emptySpaceRecover.ErrorHook)
+		if err == nil {
+			// Ask for session comment before mirroring,
+			// so copies of the session keep it as well.
+			askSessionComment(win, progress.SessionPaths, backupLog)
+			sessionPath := progress.GetBackupFullPath(progress.BackupFolder)
+			release.takeSnapshot(sessionPath, backupLog)
+			release.mirrorToDestinations(ctx.Context, sessionPath, backupLog)
+			release.mirrorToCloud(ctx.Context, sessionPath, backupLog)
+		}
+
+		notifier.ReportCompletion(1, err, progress, true)
+		progress.Close()
+	} else {
+		notifier.ReportCompletion(0, err, nil, true)
+		// Progress is kept, if resumed plan stage failed.
+		if progress != nil {
+			progress.Close()
+		}
+	}
+}
+
+// confirmBackupPlan show backup plan summary and ask to start
+// data transfer, if enabled in preferences.
+func confirmBackupPlan(win *gtk.ApplicationWindow, plan *backup.Plan, destPath string) (bool, error) {
+	appSettings, err := glib.SettingsNew(SETTINGS_SCHEMA_ID)
+	if err != nil {
+		return false, err
+	}
+	if !appSettings.GetBoolean(CFG_CONFIRM_BACKUP_PLAN) {
+		return true, nil
+	}
+	return backupPlanDialogAsync(&win.Window, plan.Preview(destPath))
+}
+
+// askSessionComment ask for comment to attach to completed
+// backup session, if enabled in preferences.
+func askSessionComment(win *gtk.ApplicationWindow, sessionPaths []string,
+	backupLog logger.PackageLog) {
+
+	appSettings, err := glib.SettingsNew(SETTINGS_SCHEMA_ID)
+	if err != nil {
+		MustIdleAdd(func() {
+			reportError(win, err)
+		})
+		return
+	}
+	if !appSettings.GetBoolean(CFG_ASK_SESSION_COMMENT) || len(sessionPaths) == 0 {
+		return
+	}
+	comment, err := sessionCommentDialogAsync(&win.Window)
+	if err != nil {
+		MustIdleAdd(func() {
+			reportError(win, err)
+		})
+		return
+	}
+	if comment == "" {
+		return
+	}
+	err = backup.SetSessionsComment(sessionPaths, comment)
+	if err != nil {
+		backupLog.Warn(locale.T(MsgAppWindowSessionCommentError,
+			struct{ Error error }{Error: err}))
+		return
+	}
+	backupLog.Info(locale.T(MsgAppWindowSessionCommentSaved,
+		struct{ Comment string }{Comment: comment}))
+}
+
+// createDestLockHook return hook, which ask whether to override
+// destination lock left by stale session or session on another host.
+func createDestLockHook(win *gtk.ApplicationWindow) backup.DestLockHookCall {
+	return func(destPath string, info *backup.DestLockInfo, stale bool) bool {
+		override, err := destLockDialogAsync(&win.Window, destPath, info, stale)
+		if err != nil {
+			lg.Panic(err)
+		}
+		return override
+	}
+}
+
+// createSessionLog create backup session log, which output lines to GUI.
+// Copy of session log kept in local archive, so it survive even
+// when destination is unreachable; archive might be nil.
+func createSessionLog(notifier *NotifierUI) (logger.PackageLog, *backup.SessionLogArchive) {
+	archive, err := backup.NewSessionLogArchive(notifier.profile)
+	if err != nil {
+		lg.Warnf("Can't create session log archive: %v", err)
+		archive = nil
+	}
 
 	backupLog := core.NewProxyLog(backup.LocalLog, "backup", 6, "15:04:05",
 		func(line string) error {
@@ -388,25 +619,229 @@ func performFullBackup(backupSync *BackupSessionStatus, notifier *NotifierUI,
 			if err != nil {
 				return err
 			}
+			if archive != nil {
+				// ignore error
+				_ = archive.WriteLine(line)
+			}
 			return nil
 		}, logger.InfoLevel,
 	)
+	return backupLog, archive
+}
 
-	// Run 1st stage to prepare backup plan.
-	plan, progress, err := backup.BuildBackupPlan(ctx.Context, backupLog, config, modules, notifier)
+// mountDestination mount destination device or network share and
+// verify destination path is available. Mount released on failure.
+func mountDestination(mount *backup.MountTarget, destPath string,
+	backupLog logger.PackageLog) error {
+
+	err := mount.Mount(backupLog)
 	if err == nil {
-		lg.Debugf("Backup node's dir trees: %+v", plan)
+		// Destination path might become available only after mount.
+		if errFound, msg := isDestPathError(destPath, false); errFound {
+			err = errors.New(msg)
+		}
+	}
+	if err != nil {
+		backupLog.Error(locale.T(MsgAppWindowMountDestinationError,
+			struct{ Error error }{Error: err}))
+		releaseDestinationMount(mount, backupLog)
+		return err
+	}
+	return nil
+}
 
-		// Create empty space recover hook.
-		emptySpaceRecover := &EmptySpaceRecover{main: win, backupLog: backupLog}
-		// Run 2nd stage to perform backup itself.
-		err = plan.RunBackup(progress, destPath, emptySpaceRecover.ErrorHook)
+// performRetryFailedFolders run session to backup again folders,
+// failed in previous session stored in sessionPath.
+func performRetryFailedFolders(backupSync *BackupSessionStatus, notifier *NotifierUI,
+	win *gtk.ApplicationWindow, config *backup.Config, modules []backup.Module, sessionPath string,
+	folder *backup.FailedFolder, mount *backup.MountTarget, release *destinationRelease) {
+
+	ctx := backupSync.Start()
+	done := traceLongRunningContext(ctx)
+	defer close(done)
+	defer backupSync.Done(ctx.Context)
+	metricsServer.SessionStarted(notifier.profile)
 
+	backupLog, archive := createSessionLog(notifier)
+	if archive != nil {
+		defer archive.Close()
+	}
+
+	if mount != nil {
+		err := mountDestination(mount, sessionPath, backupLog)
+		if err != nil {
+			notifier.ReportCompletion(0, err, nil, true)
+			return
+		}
+		defer releaseDestinationMount(mount, backupLog)
+	}
+	// Flush and eject destination before mount release.
+	defer release.apply(sessionPath, notifier, backupLog)
+
+	config.DestLockHook = createDestLockHook(win)
+	// Create empty space recover hook.
+	emptySpaceRecover := &EmptySpaceRecover{main: win, backupLog: backupLog}
+	var progress *backup.Progress
+	var err error
+	if folder != nil {
+		progress, err = backup.RetryFailedFolder(ctx.Context, backupLog, config, modules,
+			sessionPath, *folder, notifier, emptySpaceRecover.ErrorHook)
+	} else {
+		progress, err = backup.RetryFailedFolders(ctx.Context, backupLog, config, modules,
+			sessionPath, notifier, emptySpaceRecover.ErrorHook)
+	}
+	if err == nil {
+		release.takeSnapshot(sessionPath, backupLog)
+		release.mirrorToDestinations(ctx.Context, sessionPath, backupLog)
+		release.mirrorToCloud(ctx.Context, sessionPath, backupLog)
+	}
+	if progress.TotalProgress != nil {
 		notifier.ReportCompletion(1, err, progress, true)
-		progress.Close()
 	} else {
 		notifier.ReportCompletion(0, err, nil, true)
 	}
+	progress.Close()
+}
+
+// releaseDestinationMount unmount destination device or network share,
+// if it was mounted before backup session start.
+func releaseDestinationMount(mount *backup.MountTarget, backupLog logger.PackageLog) {
+	err := mount.Release(backupLog)
+	if err != nil {
+		backupLog.Error(locale.T(MsgAppWindowUnmountDestinationError,
+			struct{ Error error }{Error: err}))
+	}
+}
+
+// destinationRelease keep profile options, which should be applied
+// to destination on backup session completion.
+type destinationRelease struct {
+	sync        bool
+	eject       bool
+	cloudSync   bool
+	cloudRemote string
+	cloudPath   string
+	// Destination roots, backup session is mirrored to
+	mirrorPaths []string
+	// Snapshot options of btrfs/ZFS destination
+	snapshotMode     string
+	snapshotTemplate string
+	snapshotKeep     int
+	profile          backup.ProfileIdentity
+}
+
+// readDestinationRelease reads from app glib.Settings configuration
+// options applied to destination on backup session completion.
+func readDestinationRelease(profileID string) (*destinationRelease, error) {
+	appSettings, err := NewSettingsStore(SETTINGS_SCHEMA_ID, SETTINGS_SCHEMA_PATH, nil)
+	if err != nil {
+		return nil, err
+	}
+	profileSettings, err := getProfileSettings(appSettings, profileID, nil)
+	if err != nil {
+		return nil, err
+	}
+	identity, err := getProfileIdentity(profileSettings)
+	if err != nil {
+		return nil, err
+	}
+	v := &destinationRelease{
+		sync:        profileSettings.settings.GetBoolean(CFG_PROFILE_DEST_SYNC_AFTER_BACKUP),
+		eject:       profileSettings.settings.GetBoolean(CFG_PROFILE_DEST_EJECT_AFTER_BACKUP),
+		cloudSync:   profileSettings.settings.GetBoolean(CFG_PROFILE_CLOUD_SYNC_ENABLED),
+		cloudRemote: profileSettings.settings.GetString(CFG_PROFILE_CLOUD_SYNC_REMOTE),
+		cloudPath:   profileSettings.settings.GetString(CFG_PROFILE_CLOUD_SYNC_PATH),
+		mirrorPaths: backup.ParseMirrorDestPaths(
+			profileSettings.settings.GetString(CFG_PROFILE_MIRROR_DEST_PATHS)),
+
+		snapshotMode:     profileSettings.settings.GetString(CFG_PROFILE_SNAPSHOT_MODE),
+		snapshotTemplate: profileSettings.settings.GetString(CFG_PROFILE_SNAPSHOT_NAME_TEMPLATE),
+		snapshotKeep:     profileSettings.settings.GetInt(CFG_PROFILE_SNAPSHOT_KEEP),
+		profile:          identity,
+	}
+	return v, nil
+}
+
+// takeSnapshot create btrfs/ZFS snapshot of destination containing
+// completed backup session and prune old snapshots. Failure doesn't
+// affect local backup completion status.
+func (v *destinationRelease) takeSnapshot(sessionPath string, backupLog logger.PackageLog) {
+	if v.snapshotMode == "" {
+		return
+	}
+	target, err := backup.NewSnapshotTarget(v.snapshotMode, v.snapshotTemplate,
+		v.snapshotKeep, v.profile)
+	if err == nil {
+		err = target.Apply(filepath.Dir(sessionPath), filepath.Base(sessionPath), backupLog)
+	}
+	if err != nil {
+		backupLog.Error(locale.T(MsgAppWindowSnapshotError,
+			struct{ Error error }{Error: err}))
+	}
+}
+
+// mirrorToDestinations copy completed backup session folder to other
+// destination roots. Failure only reported in session log and doesn't
+// affect primary destination backup completion status.
+func (v *destinationRelease) mirrorToDestinations(ctx context.Context, sessionPath string,
+	backupLog logger.PackageLog) {
+
+	if len(v.mirrorPaths) == 0 {
+		return
+	}
+	backup.MirrorSessionToAll(ctx, sessionPath, v.mirrorPaths, backupLog)
+}
+
+// mirrorToCloud copy completed backup session folder to cloud storage.
+// Failure only reported in session log and doesn't affect
+// local backup completion status.
+func (v *destinationRelease) mirrorToCloud(ctx context.Context, sessionPath string,
+	backupLog logger.PackageLog) {
+
+	if !v.cloudSync {
+		return
+	}
+	cloud, err := backup.NewCloudSync(v.cloudRemote, v.cloudPath)
+	if err == nil {
+		err = cloud.Run(ctx, sessionPath, backupLog)
+	}
+	if err != nil {
+		backupLog.Warn(locale.T(MsgAppWindowCloudSyncError,
+			struct{ Error error }{Error: err}))
+	}
+}
+
+// apply flush destination file system buffers and eject
+// removable drive, so it might be safely unplugged.
+func (v *destinationRelease) apply(destPath string, notifier *NotifierUI,
+	backupLog logger.PackageLog) {
+
+	if v.sync {
+		err := backup.SyncFileSystem(destPath, backupLog)
+		if err != nil {
+			backupLog.Error(locale.T(MsgAppWindowSyncDestinationError,
+				struct{ Error error }{Error: err}))
+		}
+	}
+	if v.eject {
+		device, drive, err := backup.FindRemovableDevice(destPath)
+		if err == nil && device != "" {
+			err = backup.EjectRemovableDevice(device, drive, backupLog)
+			if err == nil {
+				msg := locale.T(MsgAppWindowEjectDestinationSafeToUnplug,
+					struct{ Device string }{Device: drive})
+				backupLog.Notify(msg)
+				err = notifier.NotifySafeToUnplug(msg)
+				if err != nil {
+					lg.Warn(err)
+				}
+			}
+		}
+		if err != nil {
+			backupLog.Error(locale.T(MsgAppWindowEjectDestinationError,
+				struct{ Error error }{Error: err}))
+		}
+	}
 }
 
 // setControlStateOnBackupStarted enable/disable actions according to backup
@@ -416,15 +851,18 @@ func setControlStateOnBackupStarted(win *gtk.ApplicationWindow,
 
 	err := enableAction(win, "RunBackupAction", false)
 	if err != nil {
-		lg.Fatal(err)
+		reportError(win, err)
+		return
 	}
 	err = enableAction(win, "PreferenceAction", false)
 	if err != nil {
-		lg.Fatal(err)
+		reportError(win, err)
+		return
 	}
 	err = enableAction(win, "StopBackupAction", true)
 	if err != nil {
-		lg.Fatal(err)
+		reportError(win, err)
+		return
 	}
 	profile.SetSensitive(false)
 	selectFolder.SetSensitive(false)
@@ -436,19 +874,28 @@ func setControlStateOnBackupEnded(win *gtk.ApplicationWindow, selectFolder *gtk.
 	profile *gtk.ComboBox, notifier *NotifierUI) {
 
 	call := func() {
+		if trayIcon != nil {
+			trayIcon.SetIdle()
+		}
+		if launcherEntry != nil {
+			launcherEntry.SetIdle()
+		}
 		profile.SetSensitive(true)
 		selectFolder.SetSensitive(true)
 		err := enableAction(win, "StopBackupAction", false)
 		if err != nil {
-			lg.Fatal(err)
+			reportError(win, err)
+			return
 		}
 		err = enableAction(win, "PreferenceAction", true)
 		if err != nil {
-			lg.Fatal(err)
+			reportError(win, err)
+			return
 		}
 		err = enableAction(win, "RunBackupAction", true)
 		if err != nil {
-			lg.Fatal(err)
+			reportError(win, err)
+			return
 		}
 	}
 
@@ -456,9 +903,142 @@ func setControlStateOnBackupEnded(win *gtk.ApplicationWindow, selectFolder *gtk.
 	MustIdleAdd(call)
 }
 
+// inhibitSuspend ask session manager to prevent logout and suspend
+// while backup session is running. Return cookie to release inhibitor
+// with uninhibitSuspend, or 0 if option is disabled or request failed.
+func inhibitSuspend(win *gtk.ApplicationWindow, profileName string) uint {
+	appSettings, err := glib.SettingsNew(SETTINGS_SCHEMA_ID)
+	if err != nil {
+		reportError(win, err)
+		return 0
+	}
+	if !appSettings.GetBoolean(CFG_INHIBIT_SUSPEND_DURING_BACKUP) {
+		return 0
+	}
+	app, err := win.GetApplication()
+	if err != nil {
+		reportError(win, err)
+		return 0
+	}
+	reason := locale.T(MsgAppWindowInhibitSuspendReason,
+		struct{ ProfileName string }{ProfileName: profileName})
+	cookie := app.Inhibited(win, gtk.APPLICATION_INHIBIT_LOGOUT|gtk.APPLICATION_INHIBIT_SUSPEND, reason)
+	if cookie == 0 {
+		lg.Warn(locale.T(MsgAppWindowInhibitSuspendError, nil))
+	}
+	return cookie
+}
+
+// uninhibitSuspend release inhibitor taken with inhibitSuspend.
+func uninhibitSuspend(win *gtk.ApplicationWindow, cookie uint) {
+	if cookie == 0 {
+		return
+	}
+	app, err := win.GetApplication()
+	if err != nil {
+		reportError(win, err)
+		return
+	}
+	app.Uninhibit(cookie)
+}
+
+// backupSessionControls keeps GUI controls and profile
+// used to run backup session and retry its failed folders.
+type backupSessionControls struct {
+	win          *gtk.ApplicationWindow
+	gridUI       *gtk.Grid
+	selectFolder *gtk.FileChooserButton
+	profile      *gtk.ComboBox
+	backupSync   *BackupSessionStatus
+	// Index of profile settings
+	profileID string
+	identity  backup.ProfileIdentity
+}
+
+// start prepare progress controls and run backup session in background.
+func (v *backupSessionControls) start(perform func(notifier *NotifierUI)) {
+	// enable/disable corresponding UI elements
+	setControlStateOnBackupStarted(v.win, v.selectFolder, v.profile)
+
+	appSettings, err := glib.SettingsNew(SETTINGS_SCHEMA_ID)
+	if err != nil {
+		reportError(v.win, err)
+		return
+	}
+	notifier := NewNotifierUI(v.identity, v.win, v.gridUI)
+	err = notifier.ClearProgressGrid()
+	if err != nil {
+		reportError(v.win, err)
+		return
+	}
+	fontSize := appSettings.GetString(CFG_SESSION_LOG_WIDGET_FONT_SIZE)
+	err = notifier.CreateProgressControls(fontSize)
+	if err != nil {
+		reportError(v.win, err)
+		return
+	}
+	err = notifier.UpdateBackupProgress(nil, locale.T(MsgAppWindowBackupProgressStartMessage, nil), false)
+	if err != nil {
+		reportError(v.win, err)
+		return
+	}
+	notifier.SetRetryHandler(v.retry)
+	// long backup shouldn't be silently killed by laptop sleep
+	cookie := inhibitSuspend(v.win, v.identity.String())
+
+	go func() {
+		defer func() {
+			// enable/disable corresponding UI elements
+			setControlStateOnBackupEnded(v.win, v.selectFolder, v.profile, notifier)
+			MustIdleAdd(func() {
+				uninhibitSuspend(v.win, cookie)
+			})
+		}()
+		defer recoverPanic("backup")
+		perform(notifier)
+	}()
+}
+
+// retry run session to backup again folders failed in sessionPath,
+// or only folder specified. Profile settings are read again,
+// since they might be fixed meantime.
+func (v *backupSessionControls) retry(sessionPath string, folder *backup.FailedFolder) {
+	if v.backupSync.IsRunning() {
+		return
+	}
+	config, modules, err := readBackupConfig(v.profileID)
+	if err != nil {
+		reportError(v.win, err)
+		return
+	}
+	mount, err := readDestinationMount(v.profileID)
+	if err != nil {
+		title := locale.T(MsgAppWindowCannotStartBackupProcessTitle, nil)
+		titleMarkup := NewMarkup(MARKUP_SIZE_LARGER, 0, 0, nil, nil,
+			NewMarkup(MARKUP_SIZE_LARGER, 0, 0, title, nil))
+		err = ErrorMessage(&v.win.Window, titleMarkup.String(),
+			[]*DialogParagraph{NewDialogParagraph(err.Error())})
+		if err != nil {
+			reportError(v.win, err)
+			return
+		}
+		return
+	}
+	release, err := readDestinationRelease(v.profileID)
+	if err != nil {
+		reportError(v.win, err)
+		return
+	}
+	v.start(func(notifier *NotifierUI) {
+		performRetryFailedFolders(v.backupSync, notifier, v.win, config, modules, sessionPath,
+			folder, mount, release)
+	})
+}
+
 // createRunBackupAction creates action - entry point for data backup process start.
 func createRunBackupAction(win *gtk.ApplicationWindow, gridUI *gtk.Grid,
 	destPath *string, selectFolder *gtk.FileChooserButton, profile *gtk.ComboBox,
+	moduleSelector *ModuleSelector, destHistory *DestinationHistory,
 	backupSync *BackupSessionStatus) (glib.IAction, error) {
 
 	act, err := glib.SimpleActionNew("RunBackupAction", nil)
@@ -470,7 +1050,8 @@ func createRunBackupAction(win *gtk.ApplicationWindow, gridUI *gtk.Grid,
 	_, err = act.Connect("activate", func(action *glib.SimpleAction, param *glib.Variant) {
 		name, state, err := GetActionNameAndState(action)
 		if err != nil {
-			lg.Fatal(err)
+			reportError(win, err)
+			return
 		}
 		lg.Debugf("%v action activated with current state %v and args %v",
 			name, state, param)
@@ -481,7 +1062,17 @@ func createRunBackupAction(win *gtk.ApplicationWindow, gridUI *gtk.Grid,
 		if profileID != "" {
 			config, modules, err := readBackupConfig(profileID)
 			if err != nil {
-				lg.Fatal(err)
+				reportError(win, err)
+				return
+			}
+			// Modules temporarily deselected in main window are skipped.
+			selected, selectErr := moduleSelector.FilterModules(modules)
+			mount, mountErr := readDestinationMount(profileID)
+			restriction, windowErr := checkBackupWindow(profileID)
+			release, err := readDestinationRelease(profileID)
+			if err != nil {
+				reportError(win, err)
+				return
 			}
 			// verify that RSYNC modules configuration is valid, otherwise show error dialog
 			if errFound, msg := isModulesConfigError(modules, true); errFound {
@@ -490,9 +1081,42 @@ func createRunBackupAction(win *gtk.ApplicationWindow, gridUI *gtk.Grid,
 					NewMarkup(MARKUP_SIZE_LARGER, 0, 0, title, nil))
 				err = ErrorMessage(&win.Window, titleMarkup.String(), []*DialogParagraph{NewDialogParagraph(msg)})
 				if err != nil {
-					lg.Fatal(err)
+					reportError(win, err)
+					return
+				}
+			} else if selectErr != nil {
+				title := locale.T(MsgAppWindowCannotStartBackupProcessTitle, nil)
+				titleMarkup := NewMarkup(MARKUP_SIZE_LARGER, 0, 0, nil, nil,
+					NewMarkup(MARKUP_SIZE_LARGER, 0, 0, title, nil))
+				err = ErrorMessage(&win.Window, titleMarkup.String(),
+					[]*DialogParagraph{NewDialogParagraph(selectErr.Error())})
+				if err != nil {
+					reportError(win, err)
+					return
+				}
+			} else if mountErr != nil {
+				title := locale.T(MsgAppWindowCannotStartBackupProcessTitle, nil)
+				titleMarkup := NewMarkup(MARKUP_SIZE_LARGER, 0, 0, nil, nil,
+					NewMarkup(MARKUP_SIZE_LARGER, 0, 0, title, nil))
+				err = ErrorMessage(&win.Window, titleMarkup.String(),
+					[]*DialogParagraph{NewDialogParagraph(mountErr.Error())})
+				if err != nil {
+					reportError(win, err)
+					return
+				}
+			} else if windowErr != nil {
+				title := locale.T(MsgAppWindowCannotStartBackupProcessTitle, nil)
+				titleMarkup := NewMarkup(MARKUP_SIZE_LARGER, 0, 0, nil, nil,
+					NewMarkup(MARKUP_SIZE_LARGER, 0, 0, title, nil))
+				err = ErrorMessage(&win.Window, titleMarkup.String(),
+					[]*DialogParagraph{NewDialogParagraph(windowErr.Error())})
+				if err != nil {
+					reportError(win, err)
+					return
 				}
-			} else if errFound, msg := isDestPathError(*destPath, true); errFound {
+			} else if errFound, msg := isDestPathError(*destPath, true); errFound &&
+				// destination existence would be verified after mount
+				(mount == nil || *destPath == "") {
 				title := locale.T(MsgAppWindowCannotStartBackupProcessTitle, nil)
 				titleMarkup := NewMarkup(MARKUP_SIZE_LARGER, 0, 0, nil, nil,
 					NewMarkup(MARKUP_SIZE_LARGER, 0, 0, title, nil))
@@ -504,45 +1128,54 @@ func createRunBackupAction(win *gtk.ApplicationWindow, gridUI *gtk.Grid,
 				}
 				err = ErrorMessage(&win.Window, titleMarkup.String(), []*DialogParagraph{NewDialogParagraph(text)})
 				if err != nil {
-					lg.Fatal(err)
+					reportError(win, err)
+					return
 				}
 			} else {
-				// enable/disable corresponding UI elements
-				setControlStateOnBackupStarted(win, selectFolder, profile)
-
-				appSettings, err := glib.SettingsNew(SETTINGS_SCHEMA_ID)
-				if err != nil {
-					lg.Fatal(err)
-				}
-				val, err := GetComboValue(profile, 0)
-				if err != nil {
-					lg.Fatal(err)
+				// backup window conditions are not met, so ask for override
+				if restriction != "" {
+					title := locale.T(MsgAppWindowBackupWindowDlgTitle, nil)
+					titleMarkup := NewMarkup(MARKUP_SIZE_LARGER, 0, 0, nil, nil,
+						NewMarkup(MARKUP_SIZE_LARGER, 0, 0, title, nil))
+					textMarkup := locale.T(MsgAppWindowBackupWindowDlgText,
+						struct{ Reasons string }{Reasons: NewMarkup(0, 0, 0, restriction, nil).String()})
+					responseYes, err := questionDialog(&win.Window, titleMarkup.String(),
+						textMarkup, true, false, true)
+					if err != nil {
+						reportError(win, err)
+						return
+					}
+					if !responseYes {
+						return
+					}
 				}
-				profileName, err := val.GetString()
+				// SSH hosts unknown yet should be trusted by user explicitly.
+				trusted, err := verifySSHHostKeys(win, selected)
 				if err != nil {
-					lg.Fatal(err)
+					reportError(win, err)
+					return
 				}
-				notifier := NewNotifierUI(profileName, gridUI)
-				err = notifier.ClearProgressGrid()
-				if err != nil {
-					lg.Fatal(err)
+				if !trusted {
+					return
 				}
-				fontSize := appSettings.GetString(CFG_SESSION_LOG_WIDGET_FONT_SIZE)
-				err = notifier.CreateProgressControls(fontSize)
+				// Remember destination to quickly return to it next time.
+				err = addDestinationHistory(profileID, *destPath)
 				if err != nil {
-					lg.Fatal(err)
+					reportError(win, err)
+					return
 				}
-				err = notifier.UpdateBackupProgress(nil, locale.T(MsgAppWindowBackupProgressStartMessage, nil), false)
+				err = destHistory.Update(profileID)
 				if err != nil {
-					lg.Fatal(err)
+					reportError(win, err)
+					return
 				}
-
-				go func() {
+				session := &backupSessionControls{win: win, gridUI: gridUI, selectFolder: selectFolder,
+					profile: profile, backupSync: backupSync, profileID: profileID,
+					identity: config.Profile}
+				session.start(func(notifier *NotifierUI) {
 					// perform a full backup cycle in one closure
-					performFullBackup(backupSync, notifier, win, config, modules, *destPath)
-					// enable/disable corresponding UI elements
-					setControlStateOnBackupEnded(win, selectFolder, profile, notifier)
-				}()
+					performFullBackup(backupSync, notifier, win, config, selected, *destPath, mount, release)
+				})
 			}
 		}
 	})
@@ -567,22 +1200,35 @@ func createStopBackupAction(win *gtk.ApplicationWindow, grid *gtk.Grid,
 	_, err = act.Connect("activate", func(action *glib.SimpleAction, param *glib.Variant) {
 		name, state, err := GetActionNameAndState(action)
 		if err != nil {
-			lg.Fatal(err)
+			reportError(win, err)
+			return
 		}
 		lg.Debugf("%v action activated with current state %v and args %v",
 			name, state, param)
 
 		err = enableAction(win, "StopBackupAction", false)
 		if err != nil {
-			lg.Fatal(err)
+			reportError(win, err)
+			return
 		}
 
 		quit, err := interruptBackupProcess(&win.Window, backupSync)
 		if err != nil {
-			lg.Fatal(err)
+			reportError(win, err)
+			return
 		}
 
 		if quit {
+			// Interrupt plan stage only, to let user decide,
+			// whether to proceed with partial plan.
+			if backupSync.StopPlanStage() {
+				err = enableAction(win, "StopBackupAction", true)
+				if err != nil {
+					reportError(win, err)
+					return
+				}
+				return
+			}
 			if backupSync.IsRunning() {
 				backupSync.Stop()
 
@@ -590,18 +1236,21 @@ func createStopBackupAction(win *gtk.ApplicationWindow, grid *gtk.Grid,
 				selectFolder.SetSensitive(true)
 				err = enableAction(win, "PreferenceAction", true)
 				if err != nil {
-					lg.Fatal(err)
+					reportError(win, err)
+					return
 				}
 				err = enableAction(win, "RunBackupAction", true)
 				if err != nil {
-					lg.Fatal(err)
+					reportError(win, err)
+					return
 				}
 			}
 		} else {
 			if backupSync.IsRunning() {
 				err = enableAction(win, "StopBackupAction", true)
 				if err != nil {
-					lg.Fatal(err)
+					reportError(win, err)
+					return
 				}
 			}
 		}
@@ -614,6 +1263,264 @@ func createStopBackupAction(win *gtk.ApplicationWindow, grid *gtk.Grid,
 	return act, nil
 }
 
+// Identifiers of environment verifications specific to GUI application,
+// used in machine-readable report.
+const (
+	CHECK_SETTINGS_SCHEMA     = "settings_schema"
+	CHECK_NOTIFICATION_SCRIPT = "notification_script"
+)
+
+// checkApplicationEnvironment verify application level settings,
+// which are not related to backup engine: GLIB GSettings schema
+// and backup completion notification script.
+func checkApplicationEnvironment(report *backup.CheckReport) error {
+	schemaSource := glib.SettingsSchemaSourceGetDefault()
+	if schemaSource == nil || schemaSource.Lookup(SETTINGS_SCHEMA_ID, false) == nil {
+		report.Add(CHECK_SETTINGS_SCHEMA, SETTINGS_SCHEMA_ID, backup.CheckFailed,
+			locale.T(MsgSchemaConfigDlgSchemaDoesNotFoundError, nil))
+	} else {
+		report.Add(CHECK_SETTINGS_SCHEMA, SETTINGS_SCHEMA_ID, backup.CheckPassed,
+			locale.T(MsgCheckProfileSchemaInstalled, nil))
+	}
+
+	appSettings, err := glib.SettingsNew(SETTINGS_SCHEMA_ID)
+	if err != nil {
+		return err
+	}
+	if appSettings.GetBoolean(CFG_RUN_NOTIFICATION_SCRIPT) {
+		scriptPath := getNotificationScriptPath(appSettings)
+		if err := verifyNotificationScript(scriptPath); err != nil {
+			report.Add(CHECK_NOTIFICATION_SCRIPT, scriptPath, backup.CheckWarning, err.Error())
+		} else {
+			report.Add(CHECK_NOTIFICATION_SCRIPT, scriptPath, backup.CheckPassed,
+				locale.T(MsgCheckProfileNotificationScriptReady, nil))
+		}
+	}
+	return nil
+}
+
+// createCheckProfileAction creates action to verify in batch backup profile environment
+// (RSYNC utility, sources, destination, application settings) and show consolidated report.
+func createCheckProfileAction(win *gtk.ApplicationWindow, destPath *string,
+	profile *gtk.ComboBox, supplimentary *RunningContexts) (glib.IAction, error) {
+
+	act, err := glib.SimpleActionNew("CheckProfileAction", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	act.SetEnabled(false)
+	_, err = act.Connect("activate", func(action *glib.SimpleAction, param *glib.Variant) {
+		name, state, err := GetActionNameAndState(action)
+		if err != nil {
+			reportError(win, err)
+			return
+		}
+		lg.Debugf("%v action activated with current state %v and args %v",
+			name, state, param)
+
+		profileID := profile.GetActiveID()
+		if profileID == "" {
+			return
+		}
+		config, modules, err := readBackupConfig(profileID)
+		if err != nil {
+			reportError(win, err)
+			return
+		}
+		report := backup.NewCheckReport(config.Profile)
+		err = checkApplicationEnvironment(report)
+		if err != nil {
+			reportError(win, err)
+			return
+		}
+		mount, mountErr := readDestinationMount(profileID)
+		if mountErr != nil {
+			report.Add(backup.CHECK_DEST_MOUNT, "", backup.CheckFailed, mountErr.Error())
+		}
+
+		err = enableAction(win, "CheckProfileAction", false)
+		if err != nil {
+			reportError(win, err)
+			return
+		}
+		dest := *destPath
+
+		go func() {
+			ctx := ForkContext(context.Background())
+			supplimentary.AddContext(ctx)
+			defer supplimentary.RemoveContext(ctx.Context)
+
+			err := backup.CheckProfileEnvironment(ctx.Context, modules, dest, mount, report)
+			MustIdleAdd(func() {
+				err2 := enableAction(win, "CheckProfileAction", profile.GetActiveID() != "")
+				if err2 != nil {
+					reportError(win, err2)
+					return
+				}
+				// Verification interrupted (profile changed or application is closing).
+				if err != nil {
+					return
+				}
+				if buf, err2 := report.JSON(); err2 == nil {
+					lg.Debugf("Profile check report: %s", buf)
+				}
+				err2 = checkProfileReportDialog(&win.Window, report)
+				if err2 != nil {
+					reportError(win, err2)
+					return
+				}
+			})
+		}()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return act, nil
+}
+
+// createTestDestinationAction creates action to test destination folder: write
+// and remove probe files, measure write throughput, report permissions,
+// file system type and hard-link support, needed for deduplication.
+func createTestDestinationAction(win *gtk.ApplicationWindow, destPath *string,
+	profile *gtk.ComboBox, supplimentary *RunningContexts) (glib.IAction, error) {
+
+	act, err := glib.SimpleActionNew("TestDestinationAction", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	act.SetEnabled(false)
+	_, err = act.Connect("activate", func(action *glib.SimpleAction, param *glib.Variant) {
+		name, state, err := GetActionNameAndState(action)
+		if err != nil {
+			reportError(win, err)
+			return
+		}
+		lg.Debugf("%v action activated with current state %v and args %v",
+			name, state, param)
+
+		identity, err := readProfileIdentity(profile.GetActiveID())
+		if err != nil {
+			reportError(win, err)
+			return
+		}
+		report := backup.NewCheckReport(identity)
+
+		err = enableAction(win, "TestDestinationAction", false)
+		if err != nil {
+			reportError(win, err)
+			return
+		}
+		dest := *destPath
+
+		go func() {
+			ctx := ForkContext(context.Background())
+			supplimentary.AddContext(ctx)
+			defer supplimentary.RemoveContext(ctx.Context)
+
+			err := backup.TestDestination(ctx.Context, dest, report)
+			MustIdleAdd(func() {
+				err2 := enableAction(win, "TestDestinationAction", profile.GetActiveID() != "")
+				if err2 != nil {
+					reportError(win, err2)
+					return
+				}
+				// Test interrupted (profile changed or application is closing).
+				if err != nil {
+					return
+				}
+				err2 = testDestinationReportDialog(&win.Window, dest, report)
+				if err2 != nil {
+					reportError(win, err2)
+					return
+				}
+			})
+		}()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return act, nil
+}
+
+// createVerifySessionAction creates action to verify integrity of selected
+// backup session: files are re-hashed and compared with checksum manifest.
+func createVerifySessionAction(win *gtk.ApplicationWindow, destPath *string,
+	supplimentary *RunningContexts) (glib.IAction, error) {
+
+	act, err := glib.SimpleActionNew("VerifySessionAction", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = act.Connect("activate", func(action *glib.SimpleAction, param *glib.Variant) {
+		name, state, err := GetActionNameAndState(action)
+		if err != nil {
+			reportError(win, err)
+			return
+		}
+		lg.Debugf("%v action activated with current state %v and args %v",
+			name, state, param)
+
+		dialog, err := gtk.FileChooserDialogNewWith2Buttons(
+			locale.T(MsgVerifySessionSelectFolderTitle, nil), &win.Window,
+			gtk.FILE_CHOOSER_ACTION_SELECT_FOLDER,
+			"_Cancel", gtk.RESPONSE_CANCEL, "_Open", gtk.RESPONSE_ACCEPT)
+		if err != nil {
+			reportError(win, err)
+			return
+		}
+		if *destPath != "" {
+			dialog.SetCurrentFolder(*destPath)
+		}
+		response := dialog.Run()
+		sessionPath := dialog.GetFilename()
+		dialog.Destroy()
+		if response != gtk.RESPONSE_ACCEPT || sessionPath == "" {
+			return
+		}
+
+		report := backup.NewCheckReport(backup.ProfileIdentity{})
+		err = enableAction(win, "VerifySessionAction", false)
+		if err != nil {
+			reportError(win, err)
+			return
+		}
+
+		go func() {
+			ctx := ForkContext(context.Background())
+			supplimentary.AddContext(ctx)
+			defer supplimentary.RemoveContext(ctx.Context)
+
+			err := backup.VerifySessionIntegrity(ctx.Context, sessionPath, report)
+			MustIdleAdd(func() {
+				err2 := enableAction(win, "VerifySessionAction", true)
+				if err2 != nil {
+					reportError(win, err2)
+					return
+				}
+				// Verification interrupted (application is closing).
+				if err != nil {
+					return
+				}
+				err2 = verifySessionReportDialog(&win.Window, sessionPath, report)
+				if err2 != nil {
+					reportError(win, err2)
+					return
+				}
+			})
+		}()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return act, nil
+}
+
 // getProfileList reads from app configuration profile's identifiers and names
 // to use as a source for GtkComboBox widget.
 func getProfileList() ([]struct{ value, key string }, error) {
@@ -635,6 +1542,49 @@ func getProfileList() ([]struct{ value, key string }, error) {
 	return arr, nil
 }
 
+// getProfileIdentity return persistent identity of profile. Unlike profileID,
+// which is an index of profile settings reused once profile deleted, UUID
+// never change and doesn't clash with profiles of other hosts. Profiles
+// created by previous application versions receive UUID on first access.
+func getProfileIdentity(profileSettings *SettingsStore) (backup.ProfileIdentity, error) {
+	id := profileSettings.settings.GetString(CFG_PROFILE_UUID)
+	if id == "" {
+		var err error
+		id, err = assignProfileUUID(profileSettings)
+		if err != nil {
+			return backup.ProfileIdentity{}, err
+		}
+	}
+	return backup.ProfileIdentity{ID: id,
+		Name: profileSettings.settings.GetString(CFG_PROFILE_NAME)}, nil
+}
+
+// assignProfileUUID generate and save new UUID of profile. Must be called
+// on profile creation, since copied profile settings contain UUID of
+// original profile.
+func assignProfileUUID(profileSettings *SettingsStore) (string, error) {
+	id, err := backup.NewProfileID()
+	if err != nil {
+		return "", err
+	}
+	profileSettings.settings.SetString(CFG_PROFILE_UUID, id)
+	return id, nil
+}
+
+// readProfileIdentity reads from app configuration persistent identity
+// of profile profileID.
+func readProfileIdentity(profileID string) (backup.ProfileIdentity, error) {
+	appSettings, err := NewSettingsStore(SETTINGS_SCHEMA_ID, SETTINGS_SCHEMA_PATH, nil)
+	if err != nil {
+		return backup.ProfileIdentity{}, err
+	}
+	profileSettings, err := getProfileSettings(appSettings, profileID, nil)
+	if err != nil {
+		return backup.ProfileIdentity{}, err
+	}
+	return getProfileIdentity(profileSettings)
+}
+
 // readBackupConfig reads from app glib.Settings configuration to Config object
 // which contains all settings necessary to run new backup session.
 func readBackupConfig(profileID string) (*backup.Config, []backup.Module, error) {
@@ -653,18 +1603,50 @@ func readBackupConfig(profileID string) (*backup.Config, []backup.Module, error)
 	maxBackupBlockSize := appSettings.settings.GetInt(CFG_MAX_BACKUP_BLOCK_SIZE_MB)
 	cfg.MaxBackupBlockSizeMb = &maxBackupBlockSize
 
+	buildDirTreeInMemory := appSettings.settings.GetBoolean(CFG_BUILD_DIR_TREE_IN_MEMORY)
+	cfg.BuildDirTreeInMemory = &buildDirTreeInMemory
+
+	planStageTempPath := appSettings.settings.GetString(CFG_PLAN_STAGE_TEMP_PATH)
+	cfg.PlanStageTempPath = &planStageTempPath
+
+	incrementalPlan := appSettings.settings.GetBoolean(CFG_INCREMENTAL_PLAN)
+	cfg.IncrementalPlan = &incrementalPlan
+	planTrace := appSettings.settings.GetBoolean(CFG_PLAN_TRACE)
+	cfg.PlanTraceEnabled = &planTrace
+
 	usePreviousBackup := appSettings.settings.GetBoolean(CFG_ENABLE_USE_OF_PREVIOUS_BACKUP)
 	cfg.UsePreviousBackup = &usePreviousBackup
 
 	numberOfPreviousBackupToUse := appSettings.settings.GetInt(CFG_NUMBER_OF_PREVIOUS_BACKUP_TO_USE)
 	cfg.NumberOfPreviousBackupToUse = &numberOfPreviousBackupToUse
 
+	safeDelete := appSettings.settings.GetBoolean(CFG_SAFE_DELETE_ENABLED)
+	cfg.SafeDeleteEnabled = &safeDelete
+
+	safeDeleteMaxFiles := appSettings.settings.GetInt(CFG_SAFE_DELETE_MAX_FILES)
+	cfg.SafeDeleteMaxFiles = &safeDeleteMaxFiles
+
+	safeDeleteMaxSize := appSettings.settings.GetInt(CFG_SAFE_DELETE_MAX_SIZE_MB)
+	cfg.SafeDeleteMaxSizeMb = &safeDeleteMaxSize
+
 	enableLowLevelLog := appSettings.settings.GetBoolean(CFG_ENABLE_LOW_LEVEL_LOG_OF_RSYNC)
 	cfg.EnableLowLevelLogForRsync = &enableLowLevelLog
 
 	enableIntensiveLowLevelLog := appSettings.settings.GetBoolean(CFG_ENABLE_INTENSIVE_LOW_LEVEL_LOG_OF_RSYNC)
 	cfg.EnableIntensiveLowLevelLogForRsync = &enableIntensiveLowLevelLog
 
+	sessionLogFormat := appSettings.settings.GetString(CFG_SESSION_LOG_FORMAT)
+	cfg.SessionLogFormat = &sessionLogFormat
+
+	logSegmentMaxSize := appSettings.settings.GetInt(CFG_LOG_SEGMENT_MAX_SIZE_MB)
+	cfg.LogSegmentMaxSizeMb = &logSegmentMaxSize
+
+	generateCatalog := appSettings.settings.GetBoolean(CFG_GENERATE_BACKUP_CATALOG)
+	cfg.GenerateCatalog = &generateCatalog
+
+	generateChecksums := appSettings.settings.GetBoolean(CFG_GENERATE_CHECKSUM_MANIFEST)
+	cfg.GenerateChecksums = &generateChecksums
+
 	transferSourceOwner := appSettings.settings.GetBoolean(CFG_RSYNC_TRANSFER_SOURCE_OWNER)
 	cfg.RsyncTransferSourceOwner = &transferSourceOwner
 
@@ -683,18 +1665,76 @@ func readBackupConfig(profileID string) (*backup.Config, []backup.Module, error)
 	transferSpecialFiles := appSettings.settings.GetBoolean(CFG_RSYNC_TRANSFER_SPECIAL_FILES)
 	cfg.RsyncTransferSpecialFiles = &transferSpecialFiles
 
+	transferACLs := appSettings.settings.GetBoolean(CFG_RSYNC_TRANSFER_ACLS)
+	cfg.RsyncTransferACLs = &transferACLs
+
+	transferXattrs := appSettings.settings.GetBoolean(CFG_RSYNC_TRANSFER_XATTRS)
+	cfg.RsyncTransferXattrs = &transferXattrs
+
 	compressFileTransfer := appSettings.settings.GetBoolean(CFG_RSYNC_COMPRESS_FILE_TRANSFER)
 	cfg.RsyncCompressFileTransfer = &compressFileTransfer
 
+	compressChoice := appSettings.settings.GetString(CFG_RSYNC_COMPRESS_CHOICE)
+	cfg.RsyncCompressChoice = &compressChoice
+
+	compressLevel := appSettings.settings.GetInt(CFG_RSYNC_COMPRESS_LEVEL)
+	cfg.RsyncCompressLevel = &compressLevel
+
+	partialTransfer := appSettings.settings.GetBoolean(CFG_RSYNC_PARTIAL_TRANSFER)
+	cfg.RsyncPartialTransfer = &partialTransfer
+
+	sparseFiles := appSettings.settings.GetBoolean(CFG_RSYNC_SPARSE_FILES)
+	cfg.RsyncSparseFiles = &sparseFiles
+
+	inplace := appSettings.settings.GetBoolean(CFG_RSYNC_INPLACE)
+	cfg.RsyncInplace = &inplace
+
 	retry := appSettings.settings.GetInt(CFG_RSYNC_RETRY_COUNT)
 	cfg.RsyncRetryCount = &retry
 
+	networkWatchdogEnabled := appSettings.settings.GetBoolean(CFG_NETWORK_WATCHDOG_ENABLED)
+	cfg.NetworkWatchdogEnabled = &networkWatchdogEnabled
+
+	networkOutageMaxWait := appSettings.settings.GetInt(CFG_NETWORK_OUTAGE_MAX_WAIT_MIN)
+	cfg.NetworkOutageMaxWaitMin = &networkOutageMaxWait
+
+	hangTimeout := appSettings.settings.GetInt(CFG_RSYNC_HANG_TIMEOUT_MIN)
+	cfg.RsyncHangTimeoutMin = &hangTimeout
+
+	ioTimeout := appSettings.settings.GetInt(CFG_RSYNC_IO_TIMEOUT_SEC)
+	cfg.RsyncIOTimeoutSec = &ioTimeout
+
+	connectTimeout := appSettings.settings.GetInt(CFG_RSYNC_CONNECT_TIMEOUT_SEC)
+	cfg.RsyncConnectTimeoutSec = &connectTimeout
+
+	niceLevel := appSettings.settings.GetInt(CFG_RSYNC_NICE_LEVEL)
+	cfg.RsyncNiceLevel = &niceLevel
+
+	ioClass := appSettings.settings.GetString(CFG_RSYNC_IO_CLASS)
+	cfg.RsyncIOClass = &ioClass
+
+	ioLevel := appSettings.settings.GetInt(CFG_RSYNC_IO_LEVEL)
+	cfg.RsyncIOLevel = &ioLevel
+
 	modules := []backup.Module{}
 
 	profileSettings, err := getProfileSettings(appSettings, profileID, nil)
 	if err != nil {
 		return nil, nil, err
 	}
+
+	cfg.Profile, err = getProfileIdentity(profileSettings)
+	if err != nil {
+		return nil, nil, err
+	}
+	moduleErrorPolicy := profileSettings.settings.GetString(CFG_PROFILE_MODULE_ERROR_POLICY)
+	cfg.ModuleErrorPolicy = &moduleErrorPolicy
+	sessionLogVerbosity := profileSettings.settings.GetString(CFG_PROFILE_SESSION_LOG_VERBOSITY)
+	cfg.SessionLogVerbosity = &sessionLogVerbosity
+	quotaSize := profileSettings.settings.GetInt(CFG_PROFILE_QUOTA_SIZE_GB)
+	cfg.QuotaSizeGb = &quotaSize
+	quotaPolicy := profileSettings.settings.GetString(CFG_PROFILE_QUOTA_POLICY)
+	cfg.QuotaPolicy = &quotaPolicy
 	sarr := profileSettings.NewSettingsArray(CFG_SOURCE_LIST)
 	sourceIDs := sarr.GetArrayIDs()
 
@@ -709,6 +1749,7 @@ func readBackupConfig(profileID string) (*backup.Config, []backup.Module, error)
 			module.SourceRsync = strings.TrimSpace(sourceSettings.settings.GetString(CFG_MODULE_RSYNC_SOURCE_PATH))
 			subpath := sourceSettings.settings.GetString(CFG_MODULE_DEST_SUBPATH)
 			module.DestSubPath = normalizeSubpath(subpath)
+			module.DestRootPath = strings.TrimSpace(sourceSettings.settings.GetString(CFG_MODULE_DEST_ROOT_PATH))
 
 			if !sourceSettings.settings.GetBoolean(CFG_RSYNC_TRANSFER_SOURCE_OWNER_INCONSISTENT) {
 				value := sourceSettings.settings.GetBoolean(CFG_RSYNC_TRANSFER_SOURCE_OWNER)
@@ -734,6 +1775,33 @@ func readBackupConfig(profileID string) (*backup.Config, []backup.Module, error)
 				value := sourceSettings.settings.GetBoolean(CFG_RSYNC_TRANSFER_SPECIAL_FILES)
 				module.RsyncTransferSpecialFiles = &value
 			}
+			if !sourceSettings.settings.GetBoolean(CFG_RSYNC_TRANSFER_ACLS_INCONSISTENT) {
+				value := sourceSettings.settings.GetBoolean(CFG_RSYNC_TRANSFER_ACLS)
+				module.RsyncTransferACLs = &value
+			}
+			if !sourceSettings.settings.GetBoolean(CFG_RSYNC_TRANSFER_XATTRS_INCONSISTENT) {
+				value := sourceSettings.settings.GetBoolean(CFG_RSYNC_TRANSFER_XATTRS)
+				module.RsyncTransferXattrs = &value
+			}
+
+			module.MaxFileSizeMb = sourceSettings.settings.GetInt(CFG_MODULE_MAX_FILE_SIZE_MB)
+			module.MaxFileAgeDays = sourceSettings.settings.GetInt(CFG_MODULE_MAX_FILE_AGE_DAYS)
+			module.MinFileAgeDays = sourceSettings.settings.GetInt(CFG_MODULE_MIN_FILE_AGE_DAYS)
+			module.ExcludePatterns = splitExcludePatterns(
+				sourceSettings.settings.GetString(CFG_MODULE_EXCLUDE_PATTERNS))
+			module.UserMap = splitOwnershipMap(sourceSettings.settings.GetString(CFG_MODULE_USER_MAP))
+			module.GroupMap = splitOwnershipMap(sourceSettings.settings.GetString(CFG_MODULE_GROUP_MAP))
+			module.Chown = strings.TrimSpace(sourceSettings.settings.GetString(CFG_MODULE_CHOWN))
+			module.SourceCharset = sourceSettings.settings.GetString(CFG_MODULE_SOURCE_CHARSET)
+			module.RsyncIOTimeoutSec = sourceSettings.settings.GetInt(CFG_MODULE_IO_TIMEOUT_SEC)
+			module.RsyncConnectTimeoutSec = sourceSettings.settings.GetInt(CFG_MODULE_CONNECT_TIMEOUT_SEC)
+			module.RsyncNiceLevel = sourceSettings.settings.GetInt(CFG_MODULE_NICE_LEVEL)
+			module.RsyncIOClass = sourceSettings.settings.GetString(CFG_MODULE_IO_CLASS)
+			module.RsyncIOLevel = sourceSettings.settings.GetInt(CFG_MODULE_IO_LEVEL)
+			module.SkipPlanEstimation = sourceSettings.settings.GetBoolean(CFG_MODULE_SKIP_PLAN_ESTIMATION)
+			module.Tags = backup.ParseModuleTags(sourceSettings.settings.GetString(CFG_MODULE_TAGS))
+			module.SourceAliases = backup.ParseSourceAliases(
+				sourceSettings.settings.GetString(CFG_MODULE_SOURCE_ALIASES))
 
 			module.ChangeFilePermission = sourceSettings.settings.GetString(CFG_MODULE_CHANGE_FILE_PERMISSION)
 			authPass := sourceSettings.settings.GetString(CFG_MODULE_AUTH_PASSWORD)
@@ -748,6 +1816,42 @@ func readBackupConfig(profileID string) (*backup.Config, []backup.Module, error)
 	return cfg, modules, nil
 }
 
+// Separator of RSYNC exclude patterns kept in single setting.
+const EXCLUDE_PATTERNS_SEPARATOR = ";"
+
+// splitExcludePatterns convert semicolon separated
+// RSYNC exclude patterns to list, skipping empty ones.
+func splitExcludePatterns(str string) []string {
+	var patterns []string
+	for _, item := range strings.Split(str, EXCLUDE_PATTERNS_SEPARATOR) {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			patterns = append(patterns, item)
+		}
+	}
+	return patterns
+}
+
+// readDestinationMount reads from app glib.Settings configuration
+// device or network share, which should be mounted before backup session.
+// Return nil, if mount option is disabled in profile.
+func readDestinationMount(profileID string) (*backup.MountTarget, error) {
+	appSettings, err := NewSettingsStore(SETTINGS_SCHEMA_ID, SETTINGS_SCHEMA_PATH, nil)
+	if err != nil {
+		return nil, err
+	}
+	profileSettings, err := getProfileSettings(appSettings, profileID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !profileSettings.settings.GetBoolean(CFG_PROFILE_DEST_MOUNT_ENABLED) {
+		return nil, nil
+	}
+	spec := profileSettings.settings.GetString(CFG_PROFILE_DEST_MOUNT_SPEC)
+	unmount := profileSettings.settings.GetBoolean(CFG_PROFILE_DEST_UNMOUNT_AFTER_BACKUP)
+	return backup.NewMountTarget(spec, unmount)
+}
+
 // getPlanInfoMarkup formats backup process totals.
 func getPlanInfoMarkup(plan *backup.Plan) *Markup {
 	var sourceCount int = len(plan.Nodes)
@@ -793,6 +1897,8 @@ func createHeader(title, subtitle string, showCloseButton bool) (*gtk.HeaderBar,
 	}
 	menuBtn.SetUsePopover(true)
 	menuBtn.SetMenuModel(menu)
+	menuBtn.SetTooltipText(locale.T(MsgAppWindowMainMenuHint, nil))
+	SetAccessibleNameAndDescription(&menuBtn.Widget, locale.T(MsgAppWindowMainMenuHint, nil), "")
 	hdr.PackEnd(menuBtn)
 
 	btn, err := SetupButtonWithThemedImage("preferences-other-symbolic")
@@ -801,6 +1907,7 @@ func createHeader(title, subtitle string, showCloseButton bool) (*gtk.HeaderBar,
 	}
 	btn.SetActionName("win.PreferenceAction")
 	btn.SetTooltipText(locale.T(MsgAppWindowPreferencesHint, nil))
+	SetAccessibleNameAndDescription(&btn.Widget, locale.T(MsgAppWindowPreferencesHint, nil), "")
 	hdr.PackStart(btn)
 
 	div, err := gtk.SeparatorNew(gtk.ORIENTATION_VERTICAL)
@@ -815,6 +1922,7 @@ func createHeader(title, subtitle string, showCloseButton bool) (*gtk.HeaderBar,
 	}
 	btn.SetActionName("win.RunBackupAction")
 	btn.SetTooltipText(locale.T(MsgAppWindowRunBackupHint, nil))
+	SetAccessibleNameAndDescription(&btn.Widget, locale.T(MsgAppWindowRunBackupHint, nil), "")
 	hdr.PackStart(btn)
 
 	btn, err = SetupButtonWithThemedImage("media-playback-stop-symbolic")
@@ -823,6 +1931,7 @@ func createHeader(title, subtitle string, showCloseButton bool) (*gtk.HeaderBar,
 	}
 	btn.SetActionName("win.StopBackupAction")
 	btn.SetTooltipText(locale.T(MsgAppWindowStopBackupHint, nil))
+	SetAccessibleNameAndDescription(&btn.Widget, locale.T(MsgAppWindowStopBackupHint, nil), "")
 	hdr.PackStart(btn)
 
 	return hdr, nil
@@ -897,7 +2006,15 @@ type ProfileObjects struct {
 	profileControl *ControlWithStatus
 	destControl    *ControlWithStatus
 	lastDestPath   string
-	reselect       chan struct{}
+	// Configuration of selected profile read on selection,
+	// to detect changes made afterwards in preferences.
+	profileDestPath string
+	lastConfig      *backup.Config
+	lastModules     []backup.Module
+	// Backup plan of selected profile, available
+	// once plan stage completed successfully.
+	lastPlan *backup.Plan
+	reselect chan struct{}
 }
 
 func (v *ProfileObjects) CheckAndClearReselect() bool {
@@ -913,12 +2030,33 @@ func (v *ProfileObjects) SetReselect() {
 	v.reselect <- struct{}{}
 }
 
+// isProfileChanged verify that configuration of profile differs
+// from one read, when profile was selected.
+func (v *ProfileObjects) isProfileChanged(profileID string) (bool, error) {
+	appSettings, err := NewSettingsStore(SETTINGS_SCHEMA_ID, SETTINGS_SCHEMA_PATH, nil)
+	if err != nil {
+		return false, err
+	}
+	profileSettings, err := getProfileSettings(appSettings, profileID, nil)
+	if err != nil {
+		return false, err
+	}
+	config, modules, err := readBackupConfig(profileID)
+	if err != nil {
+		return false, err
+	}
+	changed := profileSettings.settings.GetString(CFG_PROFILE_DEST_ROOT_PATH) != v.profileDestPath ||
+		!reflect.DeepEqual(config, v.lastConfig) || !reflect.DeepEqual(modules, v.lastModules)
+	return changed, nil
+}
+
 func getProfileWidgetHint() string {
 	return locale.T(MsgAppWindowProfileHint, nil)
 }
 
-func (v *ProfileObjects) PerformBackupPlanStage(ctx *ContextPack, supplimentary *RunningContexts,
-	config *backup.Config, modules []backup.Module, cbProfile *gtk.ComboBox) error {
+func (v *ProfileObjects) PerformBackupPlanStage(win *gtk.ApplicationWindow, ctx *ContextPack,
+	supplimentary *RunningContexts, config *backup.Config, modules []backup.Module,
+	cbProfile *gtk.ComboBox) error {
 
 	supplimentary.AddContext(ctx)
 	done := traceLongRunningContext(ctx)
@@ -943,16 +2081,25 @@ func (v *ProfileObjects) PerformBackupPlanStage(ctx *ContextPack, supplimentary
 			MustIdleAdd(func() {
 				cbProfile.SetTooltipMarkup(markup.String())
 				v.profileControl.ReplaceStatus(statusBox)
+				v.lastPlan = plan
+				for _, action := range []string{"DiskUsageAction", "IgnoreSignatureAction"} {
+					err := enableAction(win, action, true)
+					if err != nil {
+						reportError(win, err)
+						return
+					}
+				}
 			})
 		} else {
-			msg := err2.Error()
+			msg := rsync.FormatErrorWithSuggestion(err2)
 			markup := markupTooltip(NewMarkup(MARKUP_WEIGHT_BOLD, MARKUP_COLOR_ORANGE_RED, 0,
 				msg, nil), getProfileWidgetHint())
 			MustIdleAdd(func() {
 				statusBox, err := createBoxWithThemedIcon(STOCK_IMPORTANT_ICON,
 					[]string{"image-error", "image-shake"})
 				if err != nil {
-					lg.Fatal(err)
+					reportError(win, err)
+					return
 				}
 				cbProfile.SetTooltipMarkup(markup.String())
 				v.profileControl.ReplaceStatus(statusBox)
@@ -976,24 +2123,36 @@ func setWidgetsSensitive(sensitive bool, widgets []*gtk.Widget) {
 	}
 }
 
+// Set while main window is replaced to apply new UI language,
+// to keep application running, once old window destroyed.
+var mainFormReloading bool
+
 // createMainForm creates main form of application.
 // This method is a main entry point for all GUI activity construction and display.
-func createMainForm(parent context.Context, cancel func(),
-	app *gtk.Application, appSettings *SettingsStore) (*gtk.ApplicationWindow, error) {
+// Return main window and profile selector widget.
+func createMainForm(parent context.Context, cancel func(), app *gtk.Application,
+	appSettings *SettingsStore, watcher *SettingsWatcher) (*gtk.ApplicationWindow, *gtk.ComboBox, error) {
 
 	backupSync := NewBackupSessionStatus(parent)
 	supplimentary := &RunningContexts{}
 
 	win, err := gtk.ApplicationWindowNew(app)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	win.SetDefaultSize(800, 150)
+	enableCrashReports(&win.Window)
 
 	_, err = win.Connect("destroy", func(window *gtk.ApplicationWindow) {
+		if mainFormReloading {
+			// Window is replaced with new one, so keep application running.
+			supplimentary.CancelAll()
+			return
+		}
 		application, err := window.GetApplication()
 		if err != nil {
-			lg.Fatal(err)
+			reportError(win, err)
+			return
 		}
 		if backupSync.IsRunning() {
 			backupSync.Stop()
@@ -1002,21 +2161,27 @@ func createMainForm(parent context.Context, cancel func(),
 		application.Quit()
 	})
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	_, err = win.Connect("delete-event", func(window *gtk.ApplicationWindow) bool {
+		// Keep application running in background with tray icon.
+		if trayIcon != nil && trayIcon.IsEnabled() {
+			window.Hide()
+			return true
+		}
 		quit := true
 		if backupSync.IsRunning() {
 			quit, err = interruptBackupProcess(&win.Window, backupSync)
 			if err != nil {
-				lg.Fatal(err)
+				reportError(win, err)
+				return true
 			}
 		}
 		return !quit
 	})
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	var act glib.IAction
@@ -1024,31 +2189,58 @@ func createMainForm(parent context.Context, cancel func(),
 
 	act, err = createAboutAction(&win.Window, appSettings)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	win.AddAction(act)
 
 	act, err = createHelpAction(&win.Window)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	win.AddAction(act)
+
+	act, err = createLogViewerAction(win, appSettings)
+	if err != nil {
+		return nil, nil, err
+	}
+	win.AddAction(act)
+
+	act, err = createCollectDiagnosticsAction(win, appSettings)
+	if err != nil {
+		return nil, nil, err
 	}
 	win.AddAction(act)
 
 	hdr, err := createHeader(core.GetAppTitle(), core.GetAppExtraTitle(), true)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	win.SetTitlebar(hdr)
 
 	box, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 0)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	box.SetVAlign(gtk.ALIGN_FILL)
 
+	// Show errors raised in signal handlers.
+	errorBar, err := NewErrorBar(&win.Window)
+	if err != nil {
+		return nil, nil, err
+	}
+	box.Add(errorBar.GetWidget())
+
+	// Notify about newer application release, if enabled.
+	updateBar, err := NewUpdateBar(&win.Window, appSettings)
+	if err != nil {
+		return nil, nil, err
+	}
+	box.Add(updateBar.GetWidget())
+	updateBar.CheckInBackground(parent)
+
 	box2, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 6)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	SetAllMargins(box2, 18)
 	box2.SetVExpand(true)
@@ -1056,108 +2248,180 @@ func createMainForm(parent context.Context, cancel func(),
 
 	grid, err := gtk.GridNew()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	grid.SetColumnSpacing(12)
 	grid.SetRowSpacing(9)
 	row := 0
 
-	lbl, err := SetupLabelJustifyRight(locale.T(MsgAppWindowProfileCaption, nil))
+	lbl, err := SetupLabelMnemonicJustifyRight(locale.T(MsgAppWindowProfileCaption, nil))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	grid.Attach(lbl, 0, row, 1, 1)
 
 	lst, err := getProfileList()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	cbProfile, err := CreateNameValueCombo(lst)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	cbProfile.SetTooltipText(getProfileWidgetHint())
 	cbProfile.SetActiveID("")
 	cbProfile.SetHExpand(true)
+	lbl.SetMnemonicWidget(cbProfile)
 	profileCtrl, err := NewControlWithStatus(&cbProfile.Widget)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
+	profileCtrl.SetAccessible(removeUndescore(locale.T(MsgAppWindowProfileCaption, nil)),
+		getProfileWidgetHint())
 	grid.Attach(profileCtrl.GetBox(), 1, row, 1, 1)
 	row++
 
+	// Modules of selected profile, which might be temporarily deselected.
+	moduleSelector, err = NewModuleSelector()
+	if err != nil {
+		return nil, nil, err
+	}
+	grid.Attach(moduleSelector.GetWidget(), 1, row, 1, 1)
+	row++
+	// Probe modules reachability in background, while profile is selected.
+	if moduleHealth != nil {
+		moduleHealth.Stop()
+	}
+	moduleHealth = NewModuleHealthMonitor(parent, backupSync.IsRunning)
+	// Lock module selection, while backup session is running.
+	_, err = cbProfile.Connect("notify::sensitive", func(profile *gtk.ComboBox) {
+		moduleSelector.GetWidget().SetSensitive(profile.GetSensitive())
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
 	box2.Add(grid)
 
 	box.Add(box2)
 
 	box3, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 6)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	box3.SetVExpand(true)
 	box3.SetVAlign(gtk.ALIGN_FILL)
 
 	box2.Add(box3)
 
-	lblDestFolder, err := SetupLabelJustifyRight(locale.T(MsgAppWindowDestPathCaption, nil))
+	lblDestFolder, err := SetupLabelMnemonicJustifyRight(locale.T(MsgAppWindowDestPathCaption, nil))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	grid.Attach(lblDestFolder, 0, row, 1, 1)
 	destFolder, err := gtk.FileChooserButtonNew("Select destination folder", gtk.FILE_CHOOSER_ACTION_SELECT_FOLDER)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	DEST_PATH_DESCRIPTION := locale.T(MsgAppWindowDestPathHint, nil)
 	destFolder.SetTooltipText(DEST_PATH_DESCRIPTION)
 	destFolder.SetHExpand(true)
 	destFolder.SetHAlign(gtk.ALIGN_FILL)
+	lblDestFolder.SetMnemonicWidget(destFolder)
 	destCtrl, err := NewControlWithStatus(&destFolder.Widget)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	destCtrl.SetAccessible(removeUndescore(locale.T(MsgAppWindowDestPathCaption, nil)),
+		DEST_PATH_DESCRIPTION)
+	boxDest, err := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 6)
+	if err != nil {
+		return nil, nil, err
+	}
+	boxDest.PackStart(destCtrl.GetBox(), true, true, 0)
+	profileObjects := &ProfileObjects{profileControl: profileCtrl, destControl: destCtrl,
+		reselect: make(chan struct{}, 1)}
+	// Quick switch between destinations recently used by profile.
+	destHistory, err := NewDestinationHistory(func(destPath string) {
+		destFolder.SetFilename(destPath)
+		err := updateDestPathWidget(destFolder, profileObjects.destControl)
+		if err != nil {
+			reportError(win, err)
+			return
+		}
+		profileObjects.lastDestPath = destFolder.GetFilename()
+		lg.Debugf("history: assign last dest path to %q", profileObjects.lastDestPath)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	boxDest.PackStart(destHistory.GetWidget(), false, false, 0)
+	_, err = destFolder.Connect("notify::sensitive", func(dest *gtk.FileChooserButton) {
+		destHistory.GetWidget().SetSensitive(dest.GetSensitive())
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	btnTestDest, err := SetupButtonWithThemedImage("drive-harddisk-symbolic")
+	if err != nil {
+		return nil, nil, err
 	}
-	grid.Attach(destCtrl.GetBox(), 1, row, 1, 1)
+	btnTestDest.SetActionName("win.TestDestinationAction")
+	btnTestDest.SetTooltipText(locale.T(MsgAppWindowTestDestinationHint, nil))
+	SetAccessibleNameAndDescription(&btnTestDest.Widget, locale.T(MsgAppWindowTestDestinationHint, nil), "")
+	boxDest.PackStart(btnTestDest, false, false, 0)
+	grid.Attach(boxDest, 1, row, 1, 1)
 	grid.ShowAll()
 	row++
 
 	// Make widgets disabled, until backup profile not selected.
 	setWidgetsSensitive(false, []*gtk.Widget{&box3.Widget, &lblDestFolder.Widget, &destFolder.Widget})
 
-	profileObjects := &ProfileObjects{profileControl: profileCtrl, destControl: destCtrl,
-		reselect: make(chan struct{}, 1)}
-
 	_, err = destFolder.Connect("file-set", func(dest *gtk.FileChooserButton, profileObjects *ProfileObjects) {
 		destPath := dest.GetFilename()
 
 		if profileObjects.lastDestPath != destPath {
 			err := updateDestPathWidget(dest, profileObjects.destControl)
 			if err != nil {
-				lg.Fatal(err)
+				reportError(win, err)
+				return
 			}
 			profileObjects.lastDestPath = destPath
 			lg.Debugf("file-set: assign last dest path to %q", profileObjects.lastDestPath)
 		}
 	}, profileObjects)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	_, err = cbProfile.Connect("changed", func(profile *gtk.ComboBox, profileObjects *ProfileObjects) {
 		cbProfile.SetTooltipText(getProfileWidgetHint())
+		// Plan of previous profile is not relevant anymore.
+		profileObjects.lastPlan = nil
+		for _, action := range []string{"DiskUsageAction", "IgnoreSignatureAction"} {
+			err := enableAction(win, action, false)
+			if err != nil {
+				reportError(win, err)
+				return
+			}
+		}
 		profileID := profile.GetActiveID()
 		if profileID != "" {
 			val, err := GetComboValue(profile, 0)
 			if err != nil {
-				lg.Fatal(err)
+				reportError(win, err)
+				return
 			}
 			profileName, err := val.GetString()
 			if err != nil {
-				lg.Fatal(err)
+				reportError(win, err)
+				return
 			}
 
 			profileSettings, err := getProfileSettings(appSettings, profileID, nil)
 			if err != nil {
-				lg.Fatal(err)
+				reportError(win, err)
+				return
 			}
 			setWidgetsSensitive(true, []*gtk.Widget{&box3.Widget, &lblDestFolder.Widget, &destFolder.Widget})
 			destPath := profileSettings.settings.GetString(CFG_PROFILE_DEST_ROOT_PATH)
@@ -1166,12 +2430,29 @@ func createMainForm(parent context.Context, cancel func(),
 			destFolder.SetFilename(destPath)
 			err = updateDestPathWidget(destFolder, profileObjects.destControl)
 			if err != nil {
-				lg.Fatal(err)
+				reportError(win, err)
+				return
+			}
+			err = destHistory.Update(profileID)
+			if err != nil {
+				reportError(win, err)
+				return
 			}
 
 			err = enableAction(win, "RunBackupAction", true)
 			if err != nil {
-				lg.Fatal(err)
+				reportError(win, err)
+				return
+			}
+			err = enableAction(win, "CheckProfileAction", true)
+			if err != nil {
+				reportError(win, err)
+				return
+			}
+			err = enableAction(win, "TestDestinationAction", true)
+			if err != nil {
+				reportError(win, err)
+				return
 			}
 
 			msg := locale.T(MsgAppWindowInquiringProfileStatus,
@@ -1180,15 +2461,24 @@ func createMainForm(parent context.Context, cancel func(),
 			cbProfile.SetTooltipMarkup(markup.String())
 			statusBox, err := createBoxWithThemedIcon(STOCK_SYNCHRONIZING_ICON, []string{"image-spin"})
 			if err != nil {
-				lg.Fatal(err)
+				reportError(win, err)
+				return
 			}
 			profileObjects.profileControl.ReplaceStatus(statusBox)
 
 			config, modules, err := readBackupConfig(profileID)
 			if err != nil {
-				lg.Fatal(err)
+				reportError(win, err)
+				return
 			}
+			profileObjects.profileDestPath = destPath
+			profileObjects.lastConfig, profileObjects.lastModules = config, modules
 			lg.Debugf("Modules: %+v", modules)
+			err = moduleSelector.SetModules(modules)
+			if err != nil {
+				reportError(win, err)
+				return
+			}
 
 			// Verify that RSYNC modules configuration is valid, otherwise show error in cbProfile hint.
 			if errFound, msg := isModulesConfigError(modules, false); errFound {
@@ -1199,22 +2489,26 @@ func createMainForm(parent context.Context, cancel func(),
 				statusBox, err = createBoxWithThemedIcon(STOCK_IMPORTANT_ICON,
 					[]string{"image-error", "image-shake"})
 				if err != nil {
-					lg.Fatal(err)
+					reportError(win, err)
+					return
 				}
 				profileObjects.profileControl.ReplaceStatus(statusBox)
+				moduleHealth.SetModules(nil)
 			} else {
+				moduleHealth.SetModules(modules)
 
 				profileObjects.SetReselect()
 				supplimentary.CancelAll()
 
 				go func() {
+					defer recoverPanic("backup plan")
 					ctx := ForkContext(parent)
 
 					// perform backup plan stage in one closure
-					err := profileObjects.PerformBackupPlanStage(ctx, supplimentary,
+					err := profileObjects.PerformBackupPlanStage(win, ctx, supplimentary,
 						config, modules, cbProfile)
 					if err != nil {
-						lg.Fatal(err)
+						lg.Panic(err)
 					}
 				}()
 			}
@@ -1223,32 +2517,94 @@ func createMainForm(parent context.Context, cancel func(),
 			setWidgetsSensitive(false, []*gtk.Widget{&box3.Widget, &lblDestFolder.Widget, &destFolder.Widget})
 			err = enableAction(win, "RunBackupAction", false)
 			if err != nil {
-				lg.Fatal(err)
+				reportError(win, err)
+				return
+			}
+			err = enableAction(win, "CheckProfileAction", false)
+			if err != nil {
+				reportError(win, err)
+				return
+			}
+			err = enableAction(win, "TestDestinationAction", false)
+			if err != nil {
+				reportError(win, err)
+				return
 			}
 			supplimentary.CancelAll()
 			profileObjects.profileControl.ReplaceStatus(nil)
+			err = moduleSelector.Clear()
+			if err != nil {
+				reportError(win, err)
+				return
+			}
+			moduleHealth.SetModules(nil)
+			err = destHistory.Update("")
+			if err != nil {
+				reportError(win, err)
+				return
+			}
 		}
 
 	}, profileObjects)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	act, err = createPreferenceAction(win, cbProfile)
+	act, err = createPreferenceAction(win)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	win.AddAction(act)
 
+	// Reflect profile list and backup configuration changed in preferences.
+	watcher.SetProfilesHandler(func() bool {
+		if backupSync.IsRunning() {
+			return false
+		}
+		profileID := cbProfile.GetActiveID()
+		lst2, err := getProfileList()
+		if err != nil {
+			reportError(win, err)
+			return true
+		}
+		if !reflect.DeepEqual(lst, lst2) {
+			lst = lst2
+			err = UpdateNameValueCombo(cbProfile, lst)
+			if err != nil {
+				reportError(win, err)
+				return true
+			}
+			// Select profile again (unless deleted) to re-read configuration.
+			cbProfile.SetActiveID("")
+			for _, item := range lst {
+				if item.key != "" && item.key == profileID {
+					cbProfile.SetActiveID(profileID)
+					break
+				}
+			}
+		} else if profileID != "" {
+			changed, err := profileObjects.isProfileChanged(profileID)
+			if err != nil {
+				reportError(win, err)
+				return true
+			}
+			if changed {
+				cbProfile.SetActiveID("")
+				cbProfile.SetActiveID(profileID)
+			}
+		}
+		return true
+	})
+
 	div, err = gtk.SeparatorNew(gtk.ORIENTATION_HORIZONTAL)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	box3.Add(div)
 
 	grid3, err := gtk.GridNew()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	grid3.SetVExpand(true)
 	grid3.SetVAlign(gtk.ALIGN_FILL)
@@ -1259,32 +2615,115 @@ func createMainForm(parent context.Context, cancel func(),
 
 	act, err = createQuitAction(&win.Window, backupSync, supplimentary)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	win.AddAction(act)
 
 	act, err = createRunBackupAction(win, grid3,
-		&profileObjects.lastDestPath, destFolder, cbProfile, backupSync)
+		&profileObjects.lastDestPath, destFolder, cbProfile, moduleSelector, destHistory, backupSync)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	win.AddAction(act)
 
 	act, err = createStopBackupAction(win, grid3,
 		destFolder, cbProfile, backupSync)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	win.AddAction(act)
+
+	act, err = createCheckProfileAction(win, &profileObjects.lastDestPath,
+		cbProfile, supplimentary)
+	if err != nil {
+		return nil, nil, err
+	}
+	win.AddAction(act)
+
+	act, err = createTestDestinationAction(win, &profileObjects.lastDestPath,
+		cbProfile, supplimentary)
+	if err != nil {
+		return nil, nil, err
+	}
+	win.AddAction(act)
+
+	act, err = createCatalogSearchAction(win, &profileObjects.lastDestPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	win.AddAction(act)
+
+	act, err = createSessionListAction(win, &profileObjects.lastDestPath)
+	if err != nil {
+		return nil, nil, err
 	}
 	win.AddAction(act)
 
+	act, err = createBackupDaemonAction(win, cbProfile)
+	if err != nil {
+		return nil, nil, err
+	}
+	win.AddAction(act)
+
+	act, err = createVerifySessionAction(win, &profileObjects.lastDestPath, supplimentary)
+	if err != nil {
+		return nil, nil, err
+	}
+	win.AddAction(act)
+
+	act, err = createDiskUsageAction(win, &profileObjects.lastPlan)
+	if err != nil {
+		return nil, nil, err
+	}
+	win.AddAction(act)
+
+	act, err = createIgnoreSignatureAction(win, &profileObjects.lastPlan, cbProfile)
+	if err != nil {
+		return nil, nil, err
+	}
+	win.AddAction(act)
+
+	trayIcon, err = NewTrayIcon(win, cbProfile, backupSync,
+		appSettings.settings.GetBoolean(CFG_ENABLE_TRAY_ICON))
+	if err != nil {
+		return nil, nil, err
+	}
+	launcherEntry, err = NewLauncherEntry(win)
+	if err != nil {
+		return nil, nil, err
+	}
+	if driveMonitor == nil {
+		driveMonitor, err = NewDriveMonitor()
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	// Select profile bound to removable drive, once plugged in.
+	driveMonitor.SetHandler(func(uuid, mountPoint string) {
+		MustIdleAdd(func() {
+			err := selectDriveProfile(win, cbProfile, destFolder, profileObjects,
+				uuid, mountPoint)
+			if err != nil {
+				reportError(win, err)
+				return
+			}
+		})
+	})
+	updateMetricsServer(appSettings)
+	updateSSHHostKeyVerification(appSettings)
+	updateHostCredentials(appSettings)
+	updateModuleHealthInterval(appSettings)
+
 	win.Add(box)
 
-	return win, nil
+	return win, cbProfile, nil
 }
 
 // CreateApp creates GtkApplication instance to run.
+// Application is unique: any new launch forward command line
+// to running instance, which raise main window and handle options.
 func CreateApp() (*gtk.Application, error) {
-	app, err := gtk.ApplicationNew(APP_SCHEMA_ID, glib.APPLICATION_FLAGS_NONE)
+	app, err := gtk.ApplicationNew(APP_SCHEMA_ID, glib.APPLICATION_HANDLES_COMMAND_LINE)
 	if err != nil {
 		return nil, err
 	}
@@ -1318,6 +2757,10 @@ func CreateApp() (*gtk.Application, error) {
 		lg.Fatal(err)
 	}
 	locale.SetLanguage(lang)
+	// Default units are used on failure, since it's not critical,
+	// while error is shown once main window created.
+	units, unitsErr := GetSizeUnitsPreference()
+	core.SetSizeUnits(units)
 
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -1327,26 +2770,34 @@ func CreateApp() (*gtk.Application, error) {
 		lg.Info(locale.T(MsgMainAppSubsystemInitialized,
 			struct{ Subsystem string }{Subsystem: "GTK"}))
 
-		// Load GTK+ CSS styles from application assets (base.css file)
-		// and apply it globally at application level.
-		css, err := GetBaseApplicationCSS()
+		// Mirror layout for right to left languages.
+		SetDefaultTextDirection(locale.IsRTL())
+
+		// Load GTK+ CSS styles from application assets (base.css file
+		// and theme specific light.css or dark.css), with user CSS
+		// override file, and apply it globally at application level.
+		appStyles, err = NewAppStyles()
 		if err != nil {
 			lg.Fatal(err)
 		}
-		provider, err := gtk.CssProviderNew()
+		theme, err := GetThemePreference()
 		if err != nil {
 			lg.Fatal(err)
 		}
-		err = provider.LoadFromData(css)
+		err = appStyles.Apply(theme)
 		if err != nil {
 			lg.Fatal(err)
 		}
-		screen, err := gdk.ScreenGetDefault()
+
+		// Remove temporary folders left by plan stage of aborted sessions.
+		tempPath, err := GetPlanStageTempPathPreference()
 		if err != nil {
 			lg.Fatal(err)
 		}
-		// Select "APPLICATION" or "USER" priority to override global "THEME" settings.
-		gtk.AddProviderForScreen(screen, provider, gtk.STYLE_PROVIDER_PRIORITY_APPLICATION)
+		err = backup.RemoveStalePlanStageTempDirs(tempPath)
+		if err != nil {
+			lg.Warn(err)
+		}
 
 	})
 	if err != nil {
@@ -1365,34 +2816,164 @@ func CreateApp() (*gtk.Application, error) {
 		return nil, err
 	}
 
-	_, err = app.Application.Connect("activate", func(application *gtk.Application) {
+	var mainWin *gtk.ApplicationWindow
+	var mainProfile *gtk.ComboBox
+	var watcher *SettingsWatcher
+	var reloadMainForm func(application *gtk.Application, lang string) bool
+
+	// buildMainForm create main window, which is kept
+	// up to date with preferences by settings watcher.
+	buildMainForm := func(application *gtk.Application) *SettingsStore {
 		appSettings, err := NewSettingsStore(SETTINGS_SCHEMA_ID, SETTINGS_SCHEMA_PATH, nil)
 		if err != nil {
 			lg.Fatal(err)
 		}
 
-		win, err := createMainForm(ctx, cancel, application, appSettings)
+		if watcher == nil {
+			watcher, err = NewSettingsWatcher()
+			if err != nil {
+				lg.Fatal(err)
+			}
+			watcher.SetLanguageHandler(func(lang string) bool {
+				return reloadMainForm(application, lang)
+			})
+		}
+
+		win, cbProfile, err := createMainForm(ctx, cancel, application, appSettings, watcher)
 		if err != nil {
 			lg.Fatal(err)
 		}
+		mainWin, mainProfile = win, cbProfile
+		setMainWindowAccels(application)
 
 		win.ShowAll()
+		if unitsErr != nil {
+			reportError(win, unitsErr)
+			unitsErr = nil
+		}
+		return appSettings
+	}
+
+	// reloadMainForm switch UI language on the fly, recreating main window
+	// (and preference dialog, if opened) with selected profile kept.
+	// Postponed, while backup session is running.
+	reloadMainForm = func(application *gtk.Application, lang string) bool {
+		// Profile selector is disabled while backup session is running.
+		if !mainProfile.GetSensitive() {
+			return false
+		}
+		locale.SetLanguage(lang)
+		SetDefaultTextDirection(locale.IsRTL())
+
+		profileID := mainProfile.GetActiveID()
+		width, height := mainWin.GetSize()
+		visible := mainWin.GetVisible()
+		reopenPreferences := preferenceDialog != nil
+		if reopenPreferences {
+			preferenceDialog.Destroy()
+		}
+		if trayIcon != nil {
+			trayIcon.SetEnabled(false)
+		}
+		mainFormReloading = true
+		mainWin.Destroy()
+		mainFormReloading = false
+
+		buildMainForm(application)
+		mainWin.Resize(width, height)
+		if !visible {
+			mainWin.Hide()
+		}
+		mainProfile.SetActiveID(profileID)
+		if reopenPreferences {
+			err := showPreferenceDialog(mainWin, "General_ID")
+			if err != nil {
+				lg.Fatal(err)
+			}
+		}
+		return true
+	}
+
+	// showMainForm create main window once, either raise existing one.
+	// Minimized window is hidden to tray (if enabled), or iconified.
+	showMainForm := func(application *gtk.Application, minimized bool) {
+		if mainWin != nil {
+			if !minimized {
+				mainWin.Present()
+			}
+			return
+		}
+
+		appSettings := buildMainForm(application)
+		win, cbProfile := mainWin, mainProfile
 		win.SetPosition(gtk.WIN_POS_CENTER_ON_PARENT)
 
-		// Run code, when app message queue becomes empty.
-		if !appSettings.settings.GetBoolean(CFG_DONT_SHOW_ABOUT_ON_STARTUP) {
+		if minimized {
+			if trayIcon != nil && trayIcon.IsEnabled() {
+				win.Hide()
+			} else {
+				win.Iconify()
+			}
+		} else if len(appSettings.NewSettingsArray(CFG_BACKUP_LIST).GetArrayIDs()) == 0 {
+			// No any profile configured yet, so guide user with first-run wizard.
+			MustIdleAdd(func() {
+				err := showFirstRunWizard(win, cbProfile)
+				if err != nil {
+					reportError(win, err)
+				}
+			})
+		} else if !appSettings.settings.GetBoolean(CFG_DONT_SHOW_ABOUT_ON_STARTUP) {
+			// Run code, when app message queue becomes empty.
 			MustIdleAdd(func() {
 				actionName := "AboutAction"
 				action := win.LookupAction(actionName)
 				if action == nil {
 					err := errors.New(locale.T(MsgActionDoesNotFound,
 						struct{ ActionName string }{ActionName: actionName}))
-					lg.Fatal(err)
+					reportError(win, err)
+					return
 				}
 				action.Activate(nil)
 			})
 		}
+	}
+
+	_, err = app.Application.Connect("activate", func(application *gtk.Application) {
+		showMainForm(application, false)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Receive command line of any application launch, including
+	// the first one, and forwarded from new application instances.
+	_, err = app.Application.Connect("command-line", func(application *gtk.Application,
+		cmdline *glib.Object) int {
+
+		args := getCommandLineArguments(cmdline)
+		lg.Debugf("Command line received: %v", args)
+		cmdLine, err := ParseCommandLine(args)
+		if err != nil {
+			lg.Warn(locale.T(MsgAppWindowCommandLineError,
+				struct{ Error error }{Error: err}))
+			cmdLine = &CommandLine{}
+		}
 
+		showMainForm(application, cmdLine.Minimized)
+
+		if cmdLine.Profile != "" {
+			err = selectProfile(mainProfile, cmdLine.Profile)
+			if err == nil && cmdLine.Start {
+				err = runScheduledBackup(mainWin, mainProfile, cmdLine.Profile,
+					backup.ParseModuleTags(cmdLine.Tags))
+			}
+			if err != nil {
+				lg.Warn(err)
+			}
+		} else if cmdLine.Start {
+			lg.Warn(locale.T(MsgAppWindowCommandLineStartWithoutProfileError, nil))
+		}
+		return 0
 	})
 	if err != nil {
 		return nil, err
@@ -1412,3 +2993,24 @@ func GetLanguagePreference() (string, error) {
 	lang := appSettings.GetString(CFG_UI_LANGUAGE)
 	return lang, nil
 }
+
+// GetSizeUnitsPreference reads units to display data sizes customized by user.
+func GetSizeUnitsPreference() (core.SizeUnits, error) {
+	appSettings, err := glib.SettingsNew(SETTINGS_SCHEMA_ID)
+	if err != nil {
+		return core.SIZE_UNITS_SI, err
+	}
+	units := appSettings.GetString(CFG_SIZE_UNITS)
+	return core.ParseSizeUnits(units), nil
+}
+
+// GetPlanStageTempPathPreference return folder, where plan stage
+// temporary folders are created (empty for system default).
+func GetPlanStageTempPathPreference() (string, error) {
+	appSettings, err := glib.SettingsNew(SETTINGS_SCHEMA_ID)
+	if err != nil {
+		return "", err
+	}
+	tempPath := appSettings.GetString(CFG_PLAN_STAGE_TEMP_PATH)
+	return tempPath, nil
+}
@@ -0,0 +1,119 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package gtkui
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+
+	"github.com/d2r2/go-rsync/core"
+)
+
+// AUTOSTART_FILE_NAME is a desktop entry file name,
+// which start application on user login.
+const AUTOSTART_FILE_NAME = "gorsync.desktop"
+
+// GetAutostartFilePath return path to desktop entry file in autostart folder:
+// $XDG_CONFIG_HOME/autostart, or ~/.config/autostart by default.
+func GetAutostartFilePath() (string, error) {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		u, err := user.Current()
+		if err != nil {
+			return "", err
+		}
+		configHome = filepath.Join(u.HomeDir, ".config")
+	}
+	return filepath.Join(configHome, "autostart", AUTOSTART_FILE_NAME), nil
+}
+
+// IsAutostartEnabled verify that desktop entry exists in autostart folder.
+func IsAutostartEnabled() (bool, error) {
+	path, err := GetAutostartFilePath()
+	if err != nil {
+		return false, err
+	}
+	_, err = os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// quoteDesktopEntryArg quote argument of desktop entry "Exec" key,
+// according to Desktop Entry Specification.
+func quoteDesktopEntryArg(arg string) string {
+	if !strings.ContainsAny(arg, " \t\n\"'\\><~|&;$*?#()`") {
+		return arg
+	}
+	var b bytes.Buffer
+	b.WriteString(`"`)
+	for _, r := range arg {
+		switch r {
+		case '"', '`', '$', '\\':
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	b.WriteString(`"`)
+	return b.String()
+}
+
+// getAutostartDesktopEntry build desktop entry content, which
+// launch application minimized to tray on user login.
+func getAutostartDesktopEntry() (string, error) {
+	exec, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	var b bytes.Buffer
+	b.WriteString("[Desktop Entry]\n")
+	b.WriteString("Name=" + core.GetAppTitle() + "\n")
+	b.WriteString("Comment=Easy-to-use backup app based on Rsync console utility\n")
+	b.WriteString("Exec=" + quoteDesktopEntryArg(exec) + " --minimized\n")
+	b.WriteString("Icon=media-tape-symbolic\n")
+	b.WriteString("Type=Application\n")
+	b.WriteString("Terminal=false\n")
+	b.WriteString("X-GNOME-Autostart-enabled=true\n")
+	return b.String(), nil
+}
+
+// EnableAutostart create or remove desktop entry in autostart folder.
+func EnableAutostart(enable bool) error {
+	path, err := GetAutostartFilePath()
+	if err != nil {
+		return err
+	}
+	if !enable {
+		err = os.Remove(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	content, err := getAutostartDesktopEntry()
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, []byte(content), 0644)
+}
@@ -0,0 +1,531 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package gtkui
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/d2r2/go-rsync/daemon"
+	"github.com/d2r2/go-rsync/locale"
+	"github.com/d2r2/gotk3/glib"
+	"github.com/d2r2/gotk3/gtk"
+)
+
+// Columns of backup daemon profiles list.
+const (
+	backupDaemonColumnName = iota
+	backupDaemonColumnState
+	backupDaemonColumnLastRun
+	backupDaemonColumnNextRun
+	backupDaemonColumnDetails
+	backupDaemonColumnID
+)
+
+// How often backup daemon profiles state is refreshed.
+const backupDaemonRefreshInterval = 5 * time.Second
+
+// BackupDaemonControl keep widgets of the window, which show
+// profiles served by backup daemon ("gorsync daemon" command)
+// and control their backup via daemon control socket.
+type BackupDaemonControl struct {
+	socketPath string
+	store      *gtk.ListStore
+	selection  *gtk.TreeSelection
+	status     *gtk.Label
+	// Set, once window is destroyed, so responses received
+	// later are ignored.
+	closed bool
+}
+
+// send pass request to backup daemon in background, since daemon might
+// not respond immediately, and show response, once received.
+// Profile list is refreshed after start or stop command.
+func (v *BackupDaemonControl) send(request *daemon.ControlRequest) {
+	go func() {
+		response, err := daemon.SendControlRequest(v.socketPath, request)
+		MustIdleAdd(func() {
+			if v.closed {
+				return
+			}
+			if err != nil {
+				v.store.Clear()
+				v.status.SetText(locale.T(MsgBackupDaemonNotAvailable,
+					struct {
+						SocketPath string
+						Error      error
+					}{SocketPath: v.socketPath, Error: err}))
+				return
+			}
+			if response.Error != "" {
+				v.status.SetText(locale.T(MsgBackupDaemonCommandError,
+					struct{ Error string }{Error: response.Error}))
+				return
+			}
+			if request.Profile != "" {
+				v.refresh()
+				return
+			}
+			err = v.load(response.Profiles)
+			if err != nil {
+				reportError(v.status, err)
+				return
+			}
+		})
+	}()
+}
+
+// refresh request state of all daemon profiles.
+func (v *BackupDaemonControl) refresh() {
+	v.send(&daemon.ControlRequest{Command: daemon.CONTROL_CMD_STATUS})
+}
+
+// getSelectedID return ID of profile selected in the list, or empty string.
+func (v *BackupDaemonControl) getSelectedID() (string, error) {
+	_, iter, ok := v.selection.GetSelected()
+	if !ok {
+		return "", nil
+	}
+	val, err := v.store.GetValue(iter, backupDaemonColumnID)
+	if err != nil {
+		return "", err
+	}
+	return val.GetString()
+}
+
+// load fill the list with daemon profiles state, keeping selection.
+func (v *BackupDaemonControl) load(profiles []daemon.ProfileStatus) error {
+	selectedID, err := v.getSelectedID()
+	if err != nil {
+		return err
+	}
+	v.store.Clear()
+	for _, item := range profiles {
+		iter, err := AppendValues(v.store, item.Name, getBackupDaemonStateText(&item),
+			getBackupDaemonLastRunText(&item), formatBackupDaemonTime(item.NextRun),
+			getBackupDaemonDetailsMarkup(&item), item.ID)
+		if err != nil {
+			return err
+		}
+		if item.ID == selectedID {
+			v.selection.SelectIter(iter)
+		}
+	}
+	if len(profiles) == 0 {
+		v.status.SetText(locale.T(MsgBackupDaemonNothingFound, nil))
+	} else {
+		v.status.SetText(locale.TP(MsgBackupDaemonProfilesFound,
+			struct{ ProfileCount int }{ProfileCount: len(profiles)}, len(profiles)))
+	}
+	return nil
+}
+
+// exportProfile save GUI profile to daemon profiles folder and ask daemon
+// to reload profiles. Profile is saved to default profiles folder,
+// if daemon is not running, so daemon pick it up on start.
+func (v *BackupDaemonControl) exportProfile(profile *daemon.Profile) {
+	v.status.SetText(locale.T(MsgBackupDaemonExportInProgress,
+		struct{ ProfileName string }{ProfileName: profile.Name}))
+	go func() {
+		response, err := v.saveProfile(profile)
+		MustIdleAdd(func() {
+			if v.closed {
+				return
+			}
+			if err != nil {
+				v.status.SetText(locale.T(MsgBackupDaemonExportError,
+					struct {
+						ProfileName string
+						Error       error
+					}{ProfileName: profile.Name, Error: err}))
+				return
+			}
+			if response != nil {
+				err = v.load(response.Profiles)
+				if err != nil {
+					reportError(v.status, err)
+					return
+				}
+			}
+			v.status.SetText(locale.T(MsgBackupDaemonExportDone,
+				struct{ ProfileName string }{ProfileName: profile.Name}))
+		})
+	}()
+}
+
+// saveProfile write profile to profiles folder of running daemon and
+// return daemon response to reload command, or write profile to default
+// profiles folder and return nil response, if daemon is not running.
+// Schedules are not configured in GUI, so schedules of previously
+// exported version of profile are kept.
+func (v *BackupDaemonControl) saveProfile(profile *daemon.Profile) (*daemon.ControlResponse, error) {
+	response, err := daemon.SendControlRequest(v.socketPath,
+		&daemon.ControlRequest{Command: daemon.CONTROL_CMD_STATUS})
+	running := err == nil && response.Error == ""
+	var profilesPath string
+	if running && response.ProfilesPath != "" {
+		profilesPath = response.ProfilesPath
+	} else {
+		profilesPath, err = daemon.GetProfilesPath()
+		if err != nil {
+			return nil, err
+		}
+	}
+	previous, err := daemon.ReadProfile(profilesPath, profile.ID)
+	if err != nil {
+		return nil, err
+	}
+	if previous != nil {
+		profile.ScheduleEvery = previous.ScheduleEvery
+		profile.ScheduleAt = previous.ScheduleAt
+		profile.Schedules = previous.Schedules
+	}
+	err = daemon.SaveProfile(profilesPath, profile)
+	if err != nil || !running {
+		return nil, err
+	}
+	response, err = daemon.SendControlRequest(v.socketPath,
+		&daemon.ControlRequest{Command: daemon.CONTROL_CMD_RELOAD})
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return response, nil
+}
+
+// readBackupDaemonProfile reads from app glib.Settings configuration of
+// profile profileID converted to backup daemon profile, identified by
+// profile UUID.
+func readBackupDaemonProfile(profileID string) (*daemon.Profile, error) {
+	config, modules, err := readBackupConfig(profileID)
+	if err != nil {
+		return nil, err
+	}
+	release, err := readDestinationRelease(profileID)
+	if err != nil {
+		return nil, err
+	}
+	appSettings, err := NewSettingsStore(SETTINGS_SCHEMA_ID, SETTINGS_SCHEMA_PATH, nil)
+	if err != nil {
+		return nil, err
+	}
+	profileSettings, err := getProfileSettings(appSettings, profileID, nil)
+	if err != nil {
+		return nil, err
+	}
+	profile := &daemon.Profile{
+		ID:          config.Profile.ID,
+		Name:        config.Profile.Name,
+		DestPath:    profileSettings.settings.GetString(CFG_PROFILE_DEST_ROOT_PATH),
+		MirrorPaths: release.mirrorPaths,
+		Config:      *config,
+		Modules:     modules,
+	}
+	// Network metered state is not known to daemon, so
+	// corresponding backup window condition is dropped.
+	if profileSettings.settings.GetBoolean(CFG_PROFILE_BACKUP_WINDOW_ENABLED) {
+		profile.BackupWindow = &daemon.ProfileBackupWindow{
+			Start: profileSettings.settings.GetString(CFG_PROFILE_BACKUP_WINDOW_START),
+			End:   profileSettings.settings.GetString(CFG_PROFILE_BACKUP_WINDOW_END),
+			RequireACPower: profileSettings.settings.GetBoolean(
+				CFG_PROFILE_BACKUP_WINDOW_REQUIRE_AC_POWER),
+		}
+	}
+	return profile, nil
+}
+
+// formatBackupDaemonTime return time reported by daemon as text,
+// or empty string, if time is not specified.
+func formatBackupDaemonTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Local().Format("2006 Jan 2 15:04:05")
+}
+
+// getBackupDaemonStateText describe current state of daemon profile.
+func getBackupDaemonStateText(status *daemon.ProfileStatus) string {
+	if status.Running {
+		var progress float32
+		if status.Progress != nil {
+			progress = *status.Progress
+		}
+		return locale.T(MsgBackupDaemonStateRunning,
+			struct{ Progress string }{Progress: fmt.Sprintf("%.0f%%", progress*100)})
+	} else if status.Postponed != "" {
+		return locale.T(MsgBackupDaemonStatePostponed, nil)
+	}
+	return locale.T(MsgBackupDaemonStateIdle, nil)
+}
+
+// getBackupDaemonLastRunText describe start time and
+// completion status of last backup session.
+func getBackupDaemonLastRunText(status *daemon.ProfileStatus) string {
+	if status.LastRun == nil {
+		return ""
+	}
+	var msgID string
+	switch status.LastStatus {
+	case daemon.SESSION_STATUS_DONE:
+		msgID = MsgBackupDaemonLastStatusDone
+	case daemon.SESSION_STATUS_DONE_WITH_ERRORS:
+		msgID = MsgBackupDaemonLastStatusDoneWithErrors
+	case daemon.SESSION_STATUS_FAILED:
+		msgID = MsgBackupDaemonLastStatusFailed
+	case daemon.SESSION_STATUS_TERMINATED, daemon.SESSION_STATUS_STOPPED:
+		msgID = MsgBackupDaemonLastStatusTerminated
+	default:
+		// Session is running.
+		return formatBackupDaemonTime(status.LastRun)
+	}
+	return locale.T(msgID, struct{ Time string }{Time: formatBackupDaemonTime(status.LastRun)})
+}
+
+// getBackupDaemonDetailsMarkup return tooltip markup, which explain
+// why backup is postponed, and errors of last backup session.
+func getBackupDaemonDetailsMarkup(status *daemon.ProfileStatus) string {
+	var lines []string
+	if status.Postponed != "" {
+		lines = append(lines, html.EscapeString(status.Postponed))
+	}
+	if status.LastError != "" {
+		lines = append(lines, html.EscapeString(status.LastError))
+	}
+	for _, mirror := range status.Mirrors {
+		if mirror.Error != "" {
+			lines = append(lines, html.EscapeString(locale.T(MsgBackupDaemonMirrorError,
+				struct{ Path, Error string }{Path: mirror.Path, Error: mirror.Error})))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// CreateBackupDaemonWindow build window to show state of profiles served by
+// backup daemon, start and stop their backup, and export profile selected
+// in main window (profileID) to daemon.
+func CreateBackupDaemonWindow(mainWin *gtk.ApplicationWindow, profileID string) (*gtk.ApplicationWindow, error) {
+	app, err := mainWin.GetApplication()
+	if err != nil {
+		return nil, err
+	}
+	win, err := gtk.ApplicationWindowNew(app)
+	if err != nil {
+		return nil, err
+	}
+	win.SetTransientFor(mainWin)
+	win.SetDestroyWithParent(false)
+	win.SetShowMenubar(false)
+	win.SetDefaultSize(750, 400)
+
+	hdr, err := SetupHeader(locale.T(MsgBackupDaemonWindowCaption, nil), "", true)
+	if err != nil {
+		return nil, err
+	}
+	win.SetTitlebar(hdr)
+
+	box, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 6)
+	if err != nil {
+		return nil, err
+	}
+	SetAllMargins(box, 9)
+
+	store, err := gtk.ListStoreNew(glib.TYPE_STRING, glib.TYPE_STRING, glib.TYPE_STRING,
+		glib.TYPE_STRING, glib.TYPE_STRING, glib.TYPE_STRING)
+	if err != nil {
+		return nil, err
+	}
+	tv, err := gtk.TreeViewNewWithModel(store)
+	if err != nil {
+		return nil, err
+	}
+	columns := []struct {
+		title    string
+		columnID int
+	}{
+		{locale.T(MsgBackupDaemonProfileColumn, nil), backupDaemonColumnName},
+		{locale.T(MsgBackupDaemonStateColumn, nil), backupDaemonColumnState},
+		{locale.T(MsgBackupDaemonLastRunColumn, nil), backupDaemonColumnLastRun},
+		{locale.T(MsgBackupDaemonNextRunColumn, nil), backupDaemonColumnNextRun},
+	}
+	for _, item := range columns {
+		err = appendTextColumn(tv, item.title, item.columnID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	tv.SetTooltipColumn(backupDaemonColumnDetails)
+	selection, err := tv.GetSelection()
+	if err != nil {
+		return nil, err
+	}
+
+	sw, err := gtk.ScrolledWindowNew(nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	sw.SetVExpand(true)
+	sw.Add(tv)
+	box.PackStart(sw, true, true, 0)
+
+	box2, err := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 6)
+	if err != nil {
+		return nil, err
+	}
+	status, err := gtk.LabelNew("")
+	if err != nil {
+		return nil, err
+	}
+	status.SetHAlign(gtk.ALIGN_START)
+	status.SetHExpand(true)
+	status.SetLineWrap(true)
+	box2.PackStart(status, true, true, 0)
+
+	btnExport, err := gtk.ButtonNewWithLabel(locale.T(MsgBackupDaemonExportButton, nil))
+	if err != nil {
+		return nil, err
+	}
+	btnExport.SetTooltipText(locale.T(MsgBackupDaemonExportHint, nil))
+	btnExport.SetSensitive(profileID != "")
+	btnStop, err := gtk.ButtonNewWithLabel(locale.T(MsgBackupDaemonStopButton, nil))
+	if err != nil {
+		return nil, err
+	}
+	btnStop.SetSensitive(false)
+	btnStart, err := gtk.ButtonNewWithLabel(locale.T(MsgBackupDaemonStartButton, nil))
+	if err != nil {
+		return nil, err
+	}
+	btnStart.SetSensitive(false)
+	box2.PackEnd(btnExport, false, false, 0)
+	box2.PackEnd(btnStop, false, false, 0)
+	box2.PackEnd(btnStart, false, false, 0)
+	box.PackStart(box2, false, false, 0)
+
+	control := &BackupDaemonControl{socketPath: daemon.GetControlSocketPath(),
+		store: store, selection: selection, status: status}
+
+	_, err = selection.Connect("changed", func() {
+		_, _, ok := selection.GetSelected()
+		btnStart.SetSensitive(ok)
+		btnStop.SetSensitive(ok)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// sendToSelected send command to backup daemon
+	// for profile selected in the list.
+	sendToSelected := func(command string) {
+		id, err := control.getSelectedID()
+		if err != nil {
+			reportError(win, err)
+			return
+		}
+		if id == "" {
+			return
+		}
+		control.send(&daemon.ControlRequest{Command: command, Profile: id})
+	}
+	_, err = btnStart.Connect("clicked", func() {
+		sendToSelected(daemon.CONTROL_CMD_START)
+	})
+	if err != nil {
+		return nil, err
+	}
+	_, err = btnStop.Connect("clicked", func() {
+		sendToSelected(daemon.CONTROL_CMD_STOP)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = btnExport.Connect("clicked", func() {
+		profile, err := readBackupDaemonProfile(profileID)
+		if err != nil {
+			reportError(win, err)
+			return
+		}
+		control.exportProfile(profile)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Refresh profiles state periodically, while window is open.
+	stopRefresh := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(backupDaemonRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				MustIdleAdd(func() {
+					if !control.closed {
+						control.refresh()
+					}
+				})
+			case <-stopRefresh:
+				return
+			}
+		}
+	}()
+	_, err = win.Connect("destroy", func() {
+		control.closed = true
+		close(stopRefresh)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	win.Add(box)
+
+	control.refresh()
+
+	return win, nil
+}
+
+// createBackupDaemonAction creates action to open window,
+// which show and control profiles of backup daemon.
+func createBackupDaemonAction(mainWin *gtk.ApplicationWindow, profile *gtk.ComboBox) (glib.IAction, error) {
+	act, err := glib.SimpleActionNew("BackupDaemonAction", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = act.Connect("activate", func(action *glib.SimpleAction, param *glib.Variant) {
+		name, state, err := GetActionNameAndState(action)
+		if err != nil {
+			reportError(mainWin, err)
+			return
+		}
+		lg.Debugf("%v action activated with current state %v and args %v",
+			name, state, param)
+
+		win, err := CreateBackupDaemonWindow(mainWin, profile.GetActiveID())
+		if err != nil {
+			reportError(mainWin, err)
+			return
+		}
+
+		win.ShowAll()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return act, nil
+}
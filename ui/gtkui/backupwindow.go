@@ -0,0 +1,63 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package gtkui
+
+// #cgo pkg-config: gio-2.0
+// #include <gio/gio.h>
+import "C"
+import (
+	"strings"
+	"time"
+
+	"github.com/d2r2/go-rsync/backup"
+)
+
+// isNetworkMetered query GNetworkMonitor whether
+// network connection is metered (mobile, limited plan).
+func isNetworkMetered() bool {
+	monitor := C.g_network_monitor_get_default()
+	return C.g_network_monitor_get_network_metered(monitor) != 0
+}
+
+// readBackupWindow reads from app glib.Settings configuration
+// conditions when profile backup may run.
+// Return nil, if backup window is disabled in profile.
+func readBackupWindow(profileID string) (*backup.BackupWindow, error) {
+	appSettings, err := NewSettingsStore(SETTINGS_SCHEMA_ID, SETTINGS_SCHEMA_PATH, nil)
+	if err != nil {
+		return nil, err
+	}
+	profileSettings, err := getProfileSettings(appSettings, profileID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !profileSettings.settings.GetBoolean(CFG_PROFILE_BACKUP_WINDOW_ENABLED) {
+		return nil, nil
+	}
+	start := profileSettings.settings.GetString(CFG_PROFILE_BACKUP_WINDOW_START)
+	end := profileSettings.settings.GetString(CFG_PROFILE_BACKUP_WINDOW_END)
+	requireACPower := profileSettings.settings.GetBoolean(CFG_PROFILE_BACKUP_WINDOW_REQUIRE_AC_POWER)
+	requireUnmetered := profileSettings.settings.GetBoolean(CFG_PROFILE_BACKUP_WINDOW_REQUIRE_UNMETERED)
+	return backup.NewBackupWindow(start, end, requireACPower, requireUnmetered)
+}
+
+// checkBackupWindow verify profile backup window conditions
+// at the moment and return violated ones joined to single string.
+// Empty string means that backup is allowed to run.
+func checkBackupWindow(profileID string) (string, error) {
+	window, err := readBackupWindow(profileID)
+	if err != nil || window == nil {
+		return "", err
+	}
+	reasons := window.Check(time.Now(), window.RequireUnmetered && isNetworkMetered())
+	return strings.Join(reasons, "; "), nil
+}
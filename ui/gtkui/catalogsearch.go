@@ -0,0 +1,389 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package gtkui
+
+import (
+	"context"
+	"net/url"
+	"path/filepath"
+
+	"github.com/d2r2/go-rsync/backup"
+	"github.com/d2r2/go-rsync/core"
+	"github.com/d2r2/go-rsync/locale"
+	"github.com/d2r2/gotk3/glib"
+	"github.com/d2r2/gotk3/gtk"
+)
+
+// Maximum number of files displayed in catalog search results.
+const catalogSearchMaxResults = 1000
+
+// Columns of catalog search results list.
+const (
+	catalogColumnSession = iota
+	catalogColumnPath
+	catalogColumnSize
+	catalogColumnModified
+	catalogColumnComment
+	catalogColumnFullPath
+)
+
+// CatalogSearch keep widgets and state of the window,
+// which search files in backup session catalogs.
+type CatalogSearch struct {
+	store  *gtk.ListStore
+	status *gtk.Label
+	// Context of search in progress.
+	ctx *ContextPack
+}
+
+// search look for files in catalogs of backup sessions located
+// in destPath. Search in progress, if any, is cancelled.
+func (v *CatalogSearch) search(destPath, query string) {
+	if v.ctx != nil {
+		v.ctx.Cancel()
+		v.ctx = nil
+	}
+	v.store.Clear()
+	if destPath == "" || query == "" {
+		v.status.SetText("")
+		return
+	}
+	v.status.SetText(locale.T(MsgCatalogSearchInProgress, nil))
+	ctx := ForkContext(context.Background())
+	v.ctx = ctx
+
+	go func() {
+		matches, err := backup.SearchCatalogs(ctx.Context, destPath, query, catalogSearchMaxResults)
+		MustIdleAdd(func() {
+			// Search cancelled or replaced by new one.
+			if ctx.Context.Err() != nil {
+				return
+			}
+			v.ctx = nil
+			if err != nil {
+				v.status.SetText(locale.T(MsgCatalogSearchError,
+					struct{ Error error }{Error: err}))
+				return
+			}
+			for _, item := range matches {
+				_, err := AppendValues(v.store, filepath.Base(item.SessionPath), item.Path,
+					core.FormatSize(uint64(item.Size), true),
+					item.ModTime.Format("2006 Jan 2 15:04:05"),
+					item.SessionComment,
+					filepath.Join(item.SessionPath, item.GetPath()))
+				if err != nil {
+					lg.Fatal(err)
+				}
+			}
+			if len(matches) == 0 {
+				v.status.SetText(locale.T(MsgCatalogSearchNothingFound, nil))
+			} else {
+				v.status.SetText(locale.TP(MsgCatalogSearchFilesFound,
+					struct{ FileCount int }{FileCount: len(matches)}, len(matches)))
+			}
+		})
+	}()
+}
+
+// cancel interrupt search in progress, if any.
+func (v *CatalogSearch) cancel() {
+	if v.ctx != nil {
+		v.ctx.Cancel()
+		v.ctx = nil
+	}
+}
+
+// appendTextColumn add text column to the search results list.
+func appendTextColumn(tv *gtk.TreeView, title string, columnID int) error {
+	cell, err := gtk.CellRendererTextNew()
+	if err != nil {
+		return err
+	}
+	col, err := gtk.TreeViewColumnNewWithAttribute(title, cell, "text", columnID)
+	if err != nil {
+		return err
+	}
+	col.SetResizable(true)
+	col.SetSortColumnID(columnID)
+	tv.AppendColumn(col)
+	return nil
+}
+
+// CreateCatalogSearchWindow build window to search files in catalogs of backup
+// sessions, so it's possible to find which session contains specific file.
+func CreateCatalogSearchWindow(mainWin *gtk.ApplicationWindow, destPath string) (*gtk.ApplicationWindow, error) {
+	app, err := mainWin.GetApplication()
+	if err != nil {
+		return nil, err
+	}
+	win, err := gtk.ApplicationWindowNew(app)
+	if err != nil {
+		return nil, err
+	}
+	win.SetTransientFor(mainWin)
+	win.SetDestroyWithParent(false)
+	win.SetShowMenubar(false)
+	win.SetDefaultSize(900, 600)
+
+	hdr, err := SetupHeader(locale.T(MsgCatalogSearchWindowCaption, nil), "", true)
+	if err != nil {
+		return nil, err
+	}
+	win.SetTitlebar(hdr)
+
+	box, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 6)
+	if err != nil {
+		return nil, err
+	}
+	SetAllMargins(box, 9)
+
+	grid, err := gtk.GridNew()
+	if err != nil {
+		return nil, err
+	}
+	grid.SetColumnSpacing(12)
+	grid.SetRowSpacing(6)
+	box.PackStart(grid, false, false, 0)
+
+	lbl, err := SetupLabelJustifyRight(locale.T(MsgCatalogSearchDestCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	grid.Attach(lbl, 0, 0, 1, 1)
+
+	destFolder, err := gtk.FileChooserButtonNew("Select destination folder", gtk.FILE_CHOOSER_ACTION_SELECT_FOLDER)
+	if err != nil {
+		return nil, err
+	}
+	destFolder.SetTooltipText(locale.T(MsgCatalogSearchDestHint, nil))
+	destFolder.SetHExpand(true)
+	if destPath != "" {
+		destFolder.SetFilename(destPath)
+	}
+	grid.Attach(destFolder, 1, 0, 1, 1)
+
+	edSearch, err := gtk.SearchEntryNew()
+	if err != nil {
+		return nil, err
+	}
+	edSearch.SetPlaceholderText(locale.T(MsgCatalogSearchPlaceholder, nil))
+	edSearch.SetTooltipText(locale.T(MsgCatalogSearchHint, nil))
+	edSearch.SetHExpand(true)
+	grid.Attach(edSearch, 2, 0, 1, 1)
+
+	store, err := gtk.ListStoreNew(glib.TYPE_STRING, glib.TYPE_STRING,
+		glib.TYPE_STRING, glib.TYPE_STRING, glib.TYPE_STRING, glib.TYPE_STRING)
+	if err != nil {
+		return nil, err
+	}
+	tv, err := gtk.TreeViewNewWithModel(store)
+	if err != nil {
+		return nil, err
+	}
+	columns := []struct {
+		title    string
+		columnID int
+	}{
+		{locale.T(MsgCatalogSearchSessionColumn, nil), catalogColumnSession},
+		{locale.T(MsgCatalogSearchPathColumn, nil), catalogColumnPath},
+		{locale.T(MsgCatalogSearchSizeColumn, nil), catalogColumnSize},
+		{locale.T(MsgCatalogSearchModifiedColumn, nil), catalogColumnModified},
+		{locale.T(MsgCatalogSearchCommentColumn, nil), catalogColumnComment},
+	}
+	for _, item := range columns {
+		err = appendTextColumn(tv, item.title, item.columnID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	tv.SetTooltipText(locale.T(MsgCatalogSearchResultsHint, nil))
+
+	sw, err := gtk.ScrolledWindowNew(nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	sw.SetVExpand(true)
+	sw.Add(tv)
+	box.PackStart(sw, true, true, 0)
+
+	box2, err := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 6)
+	if err != nil {
+		return nil, err
+	}
+	status, err := gtk.LabelNew("")
+	if err != nil {
+		return nil, err
+	}
+	status.SetHAlign(gtk.ALIGN_START)
+	status.SetHExpand(true)
+	box2.PackStart(status, true, true, 0)
+	btnRestore, err := gtk.ButtonNewWithLabel(locale.T(MsgCatalogSearchRestoreButton, nil))
+	if err != nil {
+		return nil, err
+	}
+	btnRestore.SetTooltipText(locale.T(MsgCatalogSearchRestoreHint, nil))
+	btnRestore.SetSensitive(false)
+	box2.PackEnd(btnRestore, false, false, 0)
+	box.PackStart(box2, false, false, 0)
+
+	catalog := &CatalogSearch{store: store, status: status}
+
+	search := func() {
+		text, err := edSearch.GetText()
+		if err != nil {
+			lg.Fatal(err)
+		}
+		catalog.search(destFolder.GetFilename(), text)
+	}
+
+	// Catalogs might be large, so search on Enter only.
+	_, err = edSearch.Connect("activate", search)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = destFolder.Connect("file-set", search)
+	if err != nil {
+		return nil, err
+	}
+
+	// getFullPath return path of the file found in the list row.
+	getFullPath := func(iter *gtk.TreeIter) string {
+		val, err := store.GetValue(iter, catalogColumnFullPath)
+		if err != nil {
+			lg.Fatal(err)
+		}
+		fullPath, err := val.GetString()
+		if err != nil {
+			lg.Fatal(err)
+		}
+		return fullPath
+	}
+
+	selection, err := tv.GetSelection()
+	if err != nil {
+		return nil, err
+	}
+	_, err = selection.Connect("changed", func() {
+		_, _, ok := selection.GetSelected()
+		btnRestore.SetSensitive(ok)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Restore selected file version to location chosen.
+	_, err = btnRestore.Connect("clicked", func() {
+		_, iter, ok := selection.GetSelected()
+		if !ok {
+			return
+		}
+		srcPath := getFullPath(iter)
+		dialog, err := gtk.FileChooserDialogNewWith2Buttons(
+			locale.T(MsgCatalogSearchRestoreTitle, nil), &win.Window,
+			gtk.FILE_CHOOSER_ACTION_SAVE,
+			"_Cancel", gtk.RESPONSE_CANCEL, "_Save", gtk.RESPONSE_ACCEPT)
+		if err != nil {
+			lg.Fatal(err)
+		}
+		dialog.SetDoOverwriteConfirmation(true)
+		dialog.SetCurrentName(filepath.Base(srcPath))
+		response := dialog.Run()
+		destPath := dialog.GetFilename()
+		dialog.Destroy()
+		if response != gtk.RESPONSE_ACCEPT || destPath == "" {
+			return
+		}
+
+		btnRestore.SetSensitive(false)
+		status.SetText(locale.T(MsgCatalogSearchRestoreInProgress,
+			struct{ Path string }{Path: destPath}))
+		go func() {
+			err := backup.RestoreFile(srcPath, destPath)
+			MustIdleAdd(func() {
+				_, _, ok := selection.GetSelected()
+				btnRestore.SetSensitive(ok)
+				if err != nil {
+					status.SetText(locale.T(MsgCatalogSearchRestoreError,
+						struct {
+							Path  string
+							Error error
+						}{Path: destPath, Error: err}))
+					return
+				}
+				status.SetText(locale.T(MsgCatalogSearchRestoreDone,
+					struct{ Path string }{Path: destPath}))
+			})
+		}()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Open folder, which contains file found.
+	_, err = tv.Connect("row-activated", func(tv *gtk.TreeView, path *gtk.TreePath) {
+		iter, err := store.GetIter(path)
+		if err != nil {
+			lg.Fatal(err)
+		}
+		fullPath := getFullPath(iter)
+		uri := &url.URL{Scheme: "file", Path: filepath.Dir(fullPath)}
+		err = ShowUri(&win.Window, uri.String())
+		if err != nil {
+			lg.Warn(err)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = win.Connect("destroy", func() {
+		catalog.cancel()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	win.Add(box)
+
+	return win, nil
+}
+
+// createCatalogSearchAction creates action to open window,
+// which search files in backup session catalogs.
+func createCatalogSearchAction(mainWin *gtk.ApplicationWindow, destPath *string) (glib.IAction, error) {
+	act, err := glib.SimpleActionNew("CatalogSearchAction", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = act.Connect("activate", func(action *glib.SimpleAction, param *glib.Variant) {
+		name, state, err := GetActionNameAndState(action)
+		if err != nil {
+			lg.Fatal(err)
+		}
+		lg.Debugf("%v action activated with current state %v and args %v",
+			name, state, param)
+
+		win, err := CreateCatalogSearchWindow(mainWin, *destPath)
+		if err != nil {
+			lg.Fatal(err)
+		}
+
+		win.ShowAll()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return act, nil
+}
@@ -0,0 +1,177 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package gtkui
+
+// #cgo pkg-config: gio-2.0
+// #include <stdlib.h>
+// #include <gio/gio.h>
+import "C"
+import (
+	"errors"
+	"flag"
+	"io/ioutil"
+	"unsafe"
+
+	"github.com/d2r2/go-rsync/locale"
+	"github.com/d2r2/gotk3/glib"
+	"github.com/d2r2/gotk3/gtk"
+)
+
+// CommandLine keep application options, which are forwarded
+// from any new application instance to already running one.
+type CommandLine struct {
+	// Name of profile to select in main window.
+	Profile string
+	// Start backup of selected profile.
+	Start bool
+	// Don't show main window on launch.
+	Minimized bool
+	// Name of profile to start backup, shortcut for
+	// combination of Profile and Start options.
+	RunProfile string
+	// Comma separated tags to backup only modules tagged with any of them.
+	Tags string
+}
+
+// AddFlags register command line options, handled by GUI application.
+func (v *CommandLine) AddFlags(fs *flag.FlagSet) {
+	fs.StringVar(&v.Profile, "profile", "",
+		`Select profile "name" in main window. Profile ID shown in preferences might be used instead, to survive profile rename.`)
+	fs.BoolVar(&v.Start, "start", false,
+		`Start backup of profile specified with "profile" option.`)
+	fs.BoolVar(&v.Minimized, "minimized", false,
+		`Launch application hidden to tray (if tray icon enabled), either minimized.`)
+	fs.StringVar(&v.RunProfile, "run-profile", "",
+		`Start backup of profile "name" in running application instance, either in new one.`)
+	fs.StringVar(&v.Tags, "tags", "",
+		`Start backup of modules tagged with any of comma separated "tags" only.`)
+}
+
+// Args format options back to command line arguments.
+func (v *CommandLine) Args() []string {
+	var args []string
+	if v.Profile != "" {
+		args = append(args, "--profile", v.Profile)
+	}
+	if v.Start {
+		args = append(args, "--start")
+	}
+	if v.Minimized {
+		args = append(args, "--minimized")
+	}
+	if v.RunProfile != "" {
+		args = append(args, "--run-profile", v.RunProfile)
+	}
+	if v.Tags != "" {
+		args = append(args, "--tags", v.Tags)
+	}
+	return args
+}
+
+// ParseCommandLine decode command line arguments received
+// by primary application instance. First argument is a program name.
+func ParseCommandLine(args []string) (*CommandLine, error) {
+	v := &CommandLine{}
+	if len(args) == 0 {
+		return v, nil
+	}
+	fs := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	v.AddFlags(fs)
+	err := fs.Parse(args[1:])
+	if err != nil {
+		return nil, err
+	}
+	if v.RunProfile != "" {
+		v.Profile = v.RunProfile
+		v.Start = true
+	}
+	return v, nil
+}
+
+// getCommandLineArguments extract arguments from GApplicationCommandLine
+// object, received with "command-line" signal.
+func getCommandLineArguments(cmdline *glib.Object) []string {
+	var argc C.int
+	argv := C.g_application_command_line_get_arguments(
+		(*C.GApplicationCommandLine)(unsafe.Pointer(cmdline.GObject)), &argc)
+	if argv == nil {
+		return nil
+	}
+	defer C.g_strfreev(argv)
+	items := (*[1 << 16]*C.gchar)(unsafe.Pointer(argv))[:argc:argc]
+	args := make([]string, 0, argc)
+	for _, item := range items {
+		args = append(args, C.GoString((*C.char)(item)))
+	}
+	return args
+}
+
+// selectProfile find profile by name and select it in main window,
+// when requested via command line. If no profile has such name,
+// profile with such ID is selected, which never change on rename.
+func selectProfile(profile *gtk.ComboBox, profileName string) error {
+	// Profile selector is disabled while backup session is running.
+	if !profile.GetSensitive() {
+		return errors.New(locale.T(MsgAppWindowRunProfileBackupIsRunningError,
+			struct{ ProfileName string }{ProfileName: profileName}))
+	}
+	profiles, err := getProfileList()
+	if err != nil {
+		return err
+	}
+	for _, item := range profiles {
+		if item.key != "" && item.value == profileName {
+			profile.SetActiveID(item.key)
+			return nil
+		}
+	}
+	for _, item := range profiles {
+		if item.key == "" {
+			continue
+		}
+		identity, err := readProfileIdentity(item.key)
+		if err != nil {
+			return err
+		}
+		if identity.ID == profileName {
+			profile.SetActiveID(item.key)
+			return nil
+		}
+	}
+	return errors.New(locale.T(MsgAppWindowRunProfileNotFoundError,
+		struct{ ProfileName string }{ProfileName: profileName}))
+}
+
+// runScheduledBackup start backup of profile selected in main window,
+// when requested via command line (cron job, systemd timer and so on).
+// Unlike manual start, backup out of profile backup window is skipped
+// without any confirmation. If tags specified, only modules tagged
+// with any of them are selected for backup.
+func runScheduledBackup(win *gtk.ApplicationWindow, profile *gtk.ComboBox, profileName string,
+	tags []string) error {
+	restriction, err := checkBackupWindow(profile.GetActiveID())
+	if err != nil {
+		return err
+	}
+	if restriction != "" {
+		return errors.New(locale.T(MsgAppWindowRunProfileOutOfBackupWindowError,
+			struct{ ProfileName, Reasons string }{ProfileName: profileName, Reasons: restriction}))
+	}
+	if len(tags) > 0 {
+		err = moduleSelector.SelectTags(tags)
+		if err != nil {
+			return err
+		}
+	}
+	return activateAction(win, "RunBackupAction")
+}
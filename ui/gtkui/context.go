@@ -108,8 +108,11 @@ func (v *RunningContexts) GetCount() int {
 // BackupSessionStatus keeps contexts - live multi-thread processes,
 // which life cycle should be controlled.
 type BackupSessionStatus struct {
+	sync.Mutex
 	parent  context.Context
 	running RunningContexts
+	// Closed to interrupt plan stage in progress, if any.
+	planStop chan struct{}
 }
 
 func NewBackupSessionStatus(parent context.Context) *BackupSessionStatus {
@@ -134,6 +137,36 @@ func (v *BackupSessionStatus) Stop() {
 	v.running.CancelAll()
 }
 
+// StartPlanStage return channel to interrupt plan stage,
+// which is closed with StopPlanStage.
+func (v *BackupSessionStatus) StartPlanStage() <-chan struct{} {
+	v.Lock()
+	defer v.Unlock()
+	v.planStop = make(chan struct{})
+	return v.planStop
+}
+
+// FinishPlanStage denote that plan stage is over,
+// so StopPlanStage has no effect anymore.
+func (v *BackupSessionStatus) FinishPlanStage() {
+	v.Lock()
+	defer v.Unlock()
+	v.planStop = nil
+}
+
+// StopPlanStage interrupt plan stage in progress, keeping backup
+// session alive. Return false, if plan stage is not running.
+func (v *BackupSessionStatus) StopPlanStage() bool {
+	v.Lock()
+	defer v.Unlock()
+	if v.planStop == nil {
+		return false
+	}
+	close(v.planStop)
+	v.planStop = nil
+	return true
+}
+
 // Done removes context from the pool of controlled threads.
 func (v *BackupSessionStatus) Done(ctx context.Context) {
 	v.running.RemoveContext(ctx)
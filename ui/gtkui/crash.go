@@ -0,0 +1,205 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package gtkui
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/user"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	logger "github.com/d2r2/go-logger"
+	"github.com/d2r2/go-rsync/core"
+	"github.com/d2r2/go-rsync/locale"
+	"github.com/d2r2/gotk3/glib"
+	"github.com/d2r2/gotk3/gtk"
+)
+
+// Page to open, when user decide to report crash.
+const CRASH_REPORT_ISSUE_URL = "https://github.com/d2r2/go-rsync/issues"
+
+// Count of recent log lines saved to crash report.
+const crashLogTailSize = 200
+
+// LogTail keep recent lines of application log output.
+type LogTail struct {
+	sync.Mutex
+	lines []string
+	size  int
+}
+
+func NewLogTail(size int) *LogTail {
+	v := &LogTail{size: size}
+	return v
+}
+
+// Write implements io.Writer interface to receive log output.
+func (v *LogTail) Write(p []byte) (int, error) {
+	v.Lock()
+	defer v.Unlock()
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		v.lines = append(v.lines, line)
+	}
+	if len(v.lines) > v.size {
+		v.lines = append([]string{}, v.lines[len(v.lines)-v.size:]...)
+	}
+	return len(p), nil
+}
+
+// GetLines return copy of recent log lines.
+func (v *LogTail) GetLines() []string {
+	v.Lock()
+	defer v.Unlock()
+	return append([]string{}, v.lines...)
+}
+
+var (
+	crashLogTail *LogTail
+	// Window used as a parent of crash report dialog.
+	crashDialogParent *gtk.Window
+)
+
+// enableCrashReports start to keep recent log output for crash
+// reports, which are shown over the window specified.
+func enableCrashReports(win *gtk.Window) {
+	crashDialogParent = win
+	if crashLogTail == nil {
+		crashLogTail = NewLogTail(crashLogTailSize)
+		logger.AddCustomLog(crashLogTail, false, logger.DebugLevel)
+	}
+}
+
+// GetCrashReportPath return folder where crash reports are saved:
+// $XDG_DATA_HOME/gorsync/crash, or ~/.local/share/gorsync/crash by default.
+func GetCrashReportPath() (string, error) {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		u, err := user.Current()
+		if err != nil {
+			return "", err
+		}
+		dataHome = filepath.Join(u.HomeDir, ".local", "share")
+	}
+	return filepath.Join(dataHome, "gorsync", "crash"), nil
+}
+
+// writeCrashReport save panic details together with application
+// environment and recent log output. Return crash report file path.
+func writeCrashReport(source string, reason interface{}, stack []byte) (string, error) {
+	crashPath, err := GetCrashReportPath()
+	if err != nil {
+		return "", err
+	}
+	err = os.MkdirAll(crashPath, 0700)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s %s crash report\n", core.GetAppTitle(), core.GetAppVersion())
+	fmt.Fprintf(&buf, "Time: %s\n", now.Format(time.RFC3339))
+	fmt.Fprintf(&buf, "Source: %s\n", source)
+	fmt.Fprintf(&buf, "Go: %s %s\n", core.GetGolangVersion(), core.GetAppArchitecture())
+	glibMajor, glibMinor, glibMicro := GetGlibVersion()
+	fmt.Fprintf(&buf, "GLIB: %d.%d.%d (compiled %s)\n", glibMajor, glibMinor, glibMicro,
+		glib.GetBuildVersion())
+	gtkMajor, gtkMinor, gtkMicro := GetGtkVersion()
+	fmt.Fprintf(&buf, "GTK: %d.%d.%d (compiled %s)\n", gtkMajor, gtkMinor, gtkMicro,
+		gtk.GetBuildVersion())
+	fmt.Fprintf(&buf, "\nPanic: %v\n\n%s", reason, stack)
+	if crashLogTail != nil {
+		buf.WriteString("\nRecent log output:\n")
+		for _, line := range crashLogTail.GetLines() {
+			buf.WriteString(line)
+			buf.WriteString("\n")
+		}
+	}
+
+	fileName := filepath.Join(crashPath, fmt.Sprintf("crash_%s.txt", now.Format("20060102_150405")))
+	err = ioutil.WriteFile(fileName, buf.Bytes(), 0600)
+	if err != nil {
+		return "", err
+	}
+	return fileName, nil
+}
+
+// recoverPanic should be deferred in goroutine entry points
+// to catch panic (including lg.Panic calls), save crash report
+// and notify user, instead of killing the whole application.
+func recoverPanic(source string) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	stack := debug.Stack()
+	lg.Errorf("Panic in %s: %v\n%s", source, r, stack)
+	reportPath, err := writeCrashReport(source, r, stack)
+	if err != nil {
+		lg.Error(err)
+	}
+	MustIdleAdd(func() {
+		err := crashReportDialog(crashDialogParent, r, reportPath, err)
+		if err != nil {
+			lg.Error(err)
+		}
+	})
+}
+
+// crashReportDialog show dialog, which offer to open crash report,
+// or report issue at project page.
+func crashReportDialog(parent *gtk.Window, reason interface{}, reportPath string, saveErr error) error {
+	title := locale.T(MsgAppWindowCrashDlgTitle, nil)
+	titleMarkup := NewMarkup(MARKUP_SIZE_LARGER, 0, 0, nil, nil,
+		NewMarkup(MARKUP_SIZE_LARGER, 0, 0, title, nil))
+	paragraphs := []*DialogParagraph{NewDialogParagraph(locale.T(MsgAppWindowCrashDlgText1,
+		struct{ Error string }{Error: fmt.Sprintf("%v", reason)}))}
+	var buttons []DialogButton
+	if saveErr == nil {
+		paragraphs = append(paragraphs, NewDialogParagraph(locale.T(MsgAppWindowCrashDlgText2,
+			struct{ Path string }{Path: reportPath})))
+		buttons = append(buttons,
+			DialogButton{locale.T(MsgAppWindowCrashDlgOpenButton, nil), gtk.RESPONSE_YES, false, nil})
+	} else {
+		paragraphs = append(paragraphs, NewDialogParagraph(locale.T(MsgAppWindowCrashDlgSaveFailed,
+			struct{ Error string }{Error: saveErr.Error()})))
+	}
+	buttons = append(buttons,
+		DialogButton{locale.T(MsgAppWindowCrashDlgReportButton, nil), gtk.RESPONSE_APPLY, false, nil},
+		DialogButton{locale.T(MsgAppWindowCrashDlgCloseButton, nil), gtk.RESPONSE_CLOSE, true, nil})
+
+	dialog, err := SetupMessageDialog(parent, titleMarkup.String(), "", paragraphs, buttons, nil)
+	if err != nil {
+		return err
+	}
+	response := dialog.Run(false)
+	PrintDialogResponse(response)
+
+	if parent == nil {
+		return nil
+	}
+	switch response {
+	case gtk.RESPONSE_YES:
+		uri := &url.URL{Scheme: "file", Path: reportPath}
+		return ShowUri(parent, uri.String())
+	case gtk.RESPONSE_APPLY:
+		return ShowUri(parent, CRASH_REPORT_ISSUE_URL)
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package gtkui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/d2r2/go-rsync/locale"
+	"github.com/d2r2/go-rsync/rsync"
+	"github.com/d2r2/gotk3/glib"
+	"github.com/d2r2/gotk3/gtk"
+)
+
+// getCredentialSettings create GlibSettings object with change event
+// connected to specific indexed host credential.
+func getCredentialSettings(appStore *SettingsStore, credentialID string, changed func()) (*SettingsStore, error) {
+	pathSuffix := fmt.Sprintf(CREDENTIAL_SCHEMA_SUFFIX_PATH, credentialID)
+	store, err := appStore.GetChildSettingsStore(CREDENTIAL_SCHEMA_SUFFIX_ID, pathSuffix, changed)
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// updateHostCredentials pass host credentials from preferences
+// to RSYNC calls, where they substitute missing module settings.
+func updateHostCredentials(appSettings *SettingsStore) {
+	var list []rsync.HostCredential
+	sarr := appSettings.NewSettingsArray(CFG_CREDENTIAL_LIST)
+	for _, credentialID := range sarr.GetArrayIDs() {
+		store, err := getCredentialSettings(appSettings, credentialID, nil)
+		if err != nil {
+			lg.Warn(err)
+			continue
+		}
+		cred := rsync.HostCredential{
+			Host:     strings.TrimSpace(store.settings.GetString(CFG_CREDENTIAL_HOST)),
+			User:     strings.TrimSpace(store.settings.GetString(CFG_CREDENTIAL_USER)),
+			Password: store.settings.GetString(CFG_CREDENTIAL_PASSWORD),
+			KeyPath:  strings.TrimSpace(store.settings.GetString(CFG_CREDENTIAL_KEY_PATH)),
+		}
+		if cred.Host != "" {
+			list = append(list, cred)
+		}
+	}
+	rsync.SetHostCredentials(list)
+}
+
+// hostCredentialRow keep widgets of single host credential.
+type hostCredentialRow struct {
+	credentialID string
+	store        *SettingsStore
+	bh           *BindingHelper
+	widgets      []*gtk.Widget
+}
+
+// hostCredentialEditor is a small editor of host credentials,
+// each one kept in separate indexed glib.Settings object.
+type hostCredentialEditor struct {
+	appSettings *SettingsStore
+	grid        *gtk.Grid
+	rows        []*hostCredentialRow
+	// grid row to attach next credential, since rows
+	// of removed credentials are left empty
+	nextRow int
+}
+
+// createHostCredentialEditor create editor of host credentials
+// shared by all profiles and backup sources.
+func createHostCredentialEditor(appSettings *SettingsStore) (*gtk.Box, error) {
+	box, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 3)
+	if err != nil {
+		return nil, err
+	}
+	grid, err := gtk.GridNew()
+	if err != nil {
+		return nil, err
+	}
+	grid.SetColumnSpacing(6)
+	grid.SetRowSpacing(3)
+	box.PackStart(grid, false, false, 0)
+
+	editor := &hostCredentialEditor{appSettings: appSettings, grid: grid}
+	sarr := appSettings.NewSettingsArray(CFG_CREDENTIAL_LIST)
+	for _, credentialID := range sarr.GetArrayIDs() {
+		err = editor.addRow(credentialID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	btnAdd, err := SetupButtonWithThemedImage("list-add-symbolic")
+	if err != nil {
+		return nil, err
+	}
+	btnAdd.SetTooltipText(locale.T(MsgPrefDlgHostCredentialAddHint, nil))
+	btnAdd.SetHAlign(gtk.ALIGN_START)
+	_, err = btnAdd.Connect("clicked", func() {
+		credentialID, err := sarr.AddNode()
+		if err != nil {
+			lg.Fatal(err)
+		}
+		err = editor.addRow(credentialID)
+		if err != nil {
+			lg.Fatal(err)
+		}
+		editor.grid.ShowAll()
+	})
+	if err != nil {
+		return nil, err
+	}
+	box.PackStart(btnAdd, false, false, 0)
+	return box, nil
+}
+
+// addRow append widgets to edit host credential identified by credentialID.
+func (v *hostCredentialEditor) addRow(credentialID string) error {
+	store, err := getCredentialSettings(v.appSettings, credentialID, func() {
+		updateHostCredentials(v.appSettings)
+	})
+	if err != nil {
+		return err
+	}
+	row := &hostCredentialRow{credentialID: credentialID, store: store,
+		bh: store.NewBindingHelper()}
+
+	entries := []struct {
+		key         string
+		placeholder string
+		hint        string
+	}{
+		{CFG_CREDENTIAL_HOST, MsgPrefDlgHostCredentialHostPlaceholder, MsgPrefDlgHostCredentialHostHint},
+		{CFG_CREDENTIAL_USER, MsgPrefDlgHostCredentialUserPlaceholder, MsgPrefDlgHostCredentialUserHint},
+		{CFG_CREDENTIAL_PASSWORD, MsgPrefDlgHostCredentialPasswordPlaceholder, MsgPrefDlgHostCredentialPasswordHint},
+		{CFG_CREDENTIAL_KEY_PATH, MsgPrefDlgHostCredentialKeyPathPlaceholder, MsgPrefDlgHostCredentialKeyPathHint},
+	}
+	for i, item := range entries {
+		entry, err := gtk.EntryNew()
+		if err != nil {
+			return err
+		}
+		entry.SetPlaceholderText(locale.T(item.placeholder, nil))
+		entry.SetTooltipText(locale.T(item.hint, nil))
+		entry.SetHExpand(true)
+		if item.key == CFG_CREDENTIAL_PASSWORD {
+			entry.SetVisibility(false)
+		}
+		row.bh.Bind(item.key, entry, "text", glib.SETTINGS_BIND_DEFAULT)
+		v.grid.Attach(entry, i, v.nextRow, 1, 1)
+		row.widgets = append(row.widgets, &entry.Widget)
+	}
+
+	btnRemove, err := SetupButtonWithThemedImage("list-remove-symbolic")
+	if err != nil {
+		return err
+	}
+	btnRemove.SetTooltipText(locale.T(MsgPrefDlgHostCredentialRemoveHint, nil))
+	_, err = btnRemove.Connect("clicked", func() {
+		err := v.removeRow(row)
+		if err != nil {
+			lg.Fatal(err)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	v.grid.Attach(btnRemove, len(entries), v.nextRow, 1, 1)
+	row.widgets = append(row.widgets, &btnRemove.Widget)
+
+	v.nextRow++
+	v.rows = append(v.rows, row)
+	return nil
+}
+
+// removeRow delete widgets and settings of host credential.
+func (v *hostCredentialEditor) removeRow(row *hostCredentialRow) error {
+	for i, item := range v.rows {
+		if item == row {
+			v.rows = append(v.rows[:i], v.rows[i+1:]...)
+			break
+		}
+	}
+	row.bh.Unbind()
+	for _, widget := range row.widgets {
+		widget.Destroy()
+	}
+	sarr := v.appSettings.NewSettingsArray(CFG_CREDENTIAL_LIST)
+	err := sarr.DeleteNode(row.store, row.credentialID)
+	if err != nil {
+		return err
+	}
+	updateHostCredentials(v.appSettings)
+	return nil
+}
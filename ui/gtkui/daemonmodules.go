@@ -0,0 +1,239 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package gtkui
+
+import (
+	"context"
+
+	"github.com/d2r2/go-rsync/core"
+	"github.com/d2r2/go-rsync/locale"
+	"github.com/d2r2/go-rsync/rsync"
+	"github.com/d2r2/gotk3/glib"
+	"github.com/d2r2/gotk3/gtk"
+)
+
+// Columns of RSYNC daemon modules list.
+const (
+	daemonModuleColumnName = iota
+	daemonModuleColumnComment
+	daemonModuleColumnURL
+)
+
+// DaemonModuleList keep widgets of the dialog,
+// which list modules exported by RSYNC daemon.
+type DaemonModuleList struct {
+	store  *gtk.ListStore
+	status *gtk.Label
+	// Context of query in progress.
+	ctx *ContextPack
+}
+
+// query ask RSYNC daemon for list of modules.
+// Query in progress, if any, is cancelled.
+func (v *DaemonModuleList) query(host string, password *string) {
+	v.cancel()
+	v.store.Clear()
+	if host == "" {
+		v.status.SetText("")
+		return
+	}
+	v.status.SetText(locale.T(MsgDaemonModulesQueryInProgress, nil))
+	ctx := ForkContext(context.Background())
+	v.ctx = ctx
+
+	go func() {
+		modules, err := rsync.ListDaemonModules(ctx.Context, password, host)
+		MustIdleAdd(func() {
+			// Query cancelled or replaced by new one.
+			if ctx.Context.Err() != nil {
+				return
+			}
+			v.ctx = nil
+			if err != nil {
+				v.status.SetText(locale.T(MsgDaemonModulesQueryError,
+					struct{ Error error }{Error: err}))
+				return
+			}
+			for _, item := range modules {
+				_, err := AppendValues(v.store, item.Name, item.Comment, item.URL)
+				if err != nil {
+					lg.Fatal(err)
+				}
+			}
+			if len(modules) == 0 {
+				v.status.SetText(locale.T(MsgDaemonModulesNothingFound, nil))
+			} else {
+				v.status.SetText(locale.TP(MsgDaemonModulesFound,
+					struct{ ModuleCount int }{ModuleCount: len(modules)}, len(modules)))
+			}
+		})
+	}()
+}
+
+// cancel interrupt query in progress, if any.
+func (v *DaemonModuleList) cancel() {
+	if v.ctx != nil {
+		v.ctx.Cancel()
+		v.ctx = nil
+	}
+}
+
+// daemonHostFromPath extract "[user@]host[:port]" from RSYNC
+// daemon URL to prefill query, or return empty string.
+func daemonHostFromPath(rsyncPath string) string {
+	url, err := core.ParseRsyncURL(rsyncPath)
+	if err != nil {
+		return ""
+	}
+	host := url.HostPort()
+	if url.User != "" {
+		host = url.User + "@" + host
+	}
+	return host
+}
+
+// selectDaemonModule show dialog to query RSYNC daemon host for
+// exported modules, so user can pick one instead of typing module URL.
+// Return URL of module selected or empty string, if cancelled.
+func selectDaemonModule(parent *gtk.Window, rsyncPath string, password *string) (string, error) {
+	store, err := gtk.ListStoreNew(glib.TYPE_STRING, glib.TYPE_STRING, glib.TYPE_STRING)
+	if err != nil {
+		return "", err
+	}
+	list := &DaemonModuleList{store: store}
+	defer list.cancel()
+	var selection *gtk.TreeSelection
+	var dlg *gtk.Dialog
+
+	buttons := []DialogButton{
+		{locale.T(MsgDaemonModulesCancelButton, nil), gtk.RESPONSE_CANCEL, false, nil},
+		{locale.T(MsgDaemonModulesSelectButton, nil), gtk.RESPONSE_OK, true, func(btn *gtk.Button) error {
+			style, err := btn.GetStyleContext()
+			if err != nil {
+				return err
+			}
+			style.AddClass("suggested-action")
+			return nil
+		}},
+	}
+	dlg, err = SetupDialog(parent, gtk.MESSAGE_OTHER, true,
+		locale.T(MsgDaemonModulesDlgTitle, nil), nil, buttons,
+		func(area *gtk.Box) error {
+			boxHost, err := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 0)
+			if err != nil {
+				return err
+			}
+			style, err := boxHost.GetStyleContext()
+			if err != nil {
+				return err
+			}
+			style.AddClass("linked")
+			edHost, err := gtk.EntryNew()
+			if err != nil {
+				return err
+			}
+			edHost.SetHExpand(true)
+			edHost.SetWidthChars(40)
+			edHost.SetPlaceholderText(locale.T(MsgDaemonModulesHostPlaceholder, nil))
+			edHost.SetTooltipText(locale.T(MsgDaemonModulesHostHint, nil))
+			edHost.SetText(daemonHostFromPath(rsyncPath))
+			boxHost.PackStart(edHost, true, true, 0)
+			btnQuery, err := SetupButtonWithThemedImage("view-refresh-symbolic")
+			if err != nil {
+				return err
+			}
+			btnQuery.SetTooltipText(locale.T(MsgDaemonModulesQueryHint, nil))
+			boxHost.PackStart(btnQuery, false, false, 0)
+			area.PackStart(boxHost, false, false, 0)
+
+			tv, err := gtk.TreeViewNewWithModel(store)
+			if err != nil {
+				return err
+			}
+			err = appendTextColumn(tv, locale.T(MsgDaemonModulesNameColumn, nil),
+				daemonModuleColumnName)
+			if err != nil {
+				return err
+			}
+			err = appendTextColumn(tv, locale.T(MsgDaemonModulesCommentColumn, nil),
+				daemonModuleColumnComment)
+			if err != nil {
+				return err
+			}
+			selection, err = tv.GetSelection()
+			if err != nil {
+				return err
+			}
+			sw, err := gtk.ScrolledWindowNew(nil, nil)
+			if err != nil {
+				return err
+			}
+			sw.SetSizeRequest(-1, 250)
+			sw.SetVExpand(true)
+			sw.Add(tv)
+			area.PackStart(sw, true, true, 0)
+
+			status, err := gtk.LabelNew("")
+			if err != nil {
+				return err
+			}
+			status.SetHAlign(gtk.ALIGN_START)
+			area.PackStart(status, false, false, 0)
+			list.status = status
+
+			query := func() {
+				host, err := edHost.GetText()
+				if err != nil {
+					lg.Fatal(err)
+				}
+				list.query(host, password)
+			}
+			_, err = btnQuery.Connect("clicked", query)
+			if err != nil {
+				return err
+			}
+			_, err = edHost.Connect("activate", query)
+			if err != nil {
+				return err
+			}
+			// Double click pick module.
+			_, err = tv.Connect("row-activated", func() {
+				dlg.Response(gtk.RESPONSE_OK)
+			})
+			if err != nil {
+				return err
+			}
+			if host, _ := edHost.GetText(); host != "" {
+				query()
+			}
+			return nil
+		})
+	if err != nil {
+		return "", err
+	}
+	defer dlg.Destroy()
+
+	dlg.ShowAll()
+	response := dlg.Run()
+	if response != gtk.RESPONSE_OK {
+		return "", nil
+	}
+	_, iter, ok := selection.GetSelected()
+	if !ok {
+		return "", nil
+	}
+	val, err := store.GetValue(iter, daemonModuleColumnURL)
+	if err != nil {
+		return "", err
+	}
+	return val.GetString()
+}
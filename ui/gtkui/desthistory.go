@@ -0,0 +1,131 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package gtkui
+
+import (
+	"github.com/d2r2/go-rsync/locale"
+	"github.com/d2r2/gotk3/gtk"
+)
+
+// Maximum number of destination paths remembered per profile.
+const destHistoryMaxCount = 8
+
+// readDestinationHistory return destination paths recently
+// used by profile, most recent first.
+func readDestinationHistory(profileID string) ([]string, error) {
+	appSettings, err := NewSettingsStore(SETTINGS_SCHEMA_ID, SETTINGS_SCHEMA_PATH, nil)
+	if err != nil {
+		return nil, err
+	}
+	profileSettings, err := getProfileSettings(appSettings, profileID, nil)
+	if err != nil {
+		return nil, err
+	}
+	return profileSettings.settings.GetStrv(CFG_PROFILE_DEST_HISTORY), nil
+}
+
+// addDestinationHistory put destination path on top of profile
+// destination history, removing duplicates and oldest entries.
+func addDestinationHistory(profileID, destPath string) error {
+	appSettings, err := NewSettingsStore(SETTINGS_SCHEMA_ID, SETTINGS_SCHEMA_PATH, nil)
+	if err != nil {
+		return err
+	}
+	profileSettings, err := getProfileSettings(appSettings, profileID, nil)
+	if err != nil {
+		return err
+	}
+	history := []string{destPath}
+	for _, item := range profileSettings.settings.GetStrv(CFG_PROFILE_DEST_HISTORY) {
+		if item != destPath && len(history) < destHistoryMaxCount {
+			history = append(history, item)
+		}
+	}
+	profileSettings.settings.SetStrv(CFG_PROFILE_DEST_HISTORY, history)
+	return nil
+}
+
+// DestinationHistory is a drop-down button next to destination
+// folder of main window, to quickly switch between destination
+// paths recently used by selected profile.
+type DestinationHistory struct {
+	button *gtk.MenuButton
+	// Called with destination path chosen from the list.
+	selected func(destPath string)
+}
+
+// NewDestinationHistory create destination history button.
+func NewDestinationHistory(selected func(destPath string)) (*DestinationHistory, error) {
+	btn, err := SetupMenuButtonWithThemedImage("document-open-recent-symbolic")
+	if err != nil {
+		return nil, err
+	}
+	btn.SetTooltipText(locale.T(MsgAppWindowDestHistoryHint, nil))
+	SetAccessibleNameAndDescription(&btn.Widget, locale.T(MsgAppWindowDestHistoryHint, nil), "")
+	v := &DestinationHistory{button: btn, selected: selected}
+	err = v.SetPaths(nil)
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// GetWidget return widget to pack into the form.
+func (v *DestinationHistory) GetWidget() *gtk.MenuButton {
+	return v.button
+}
+
+// Update reload destination history of profile.
+func (v *DestinationHistory) Update(profileID string) error {
+	var paths []string
+	if profileID != "" {
+		var err error
+		paths, err = readDestinationHistory(profileID)
+		if err != nil {
+			return err
+		}
+	}
+	return v.SetPaths(paths)
+}
+
+// SetPaths rebuild drop-down menu from destination paths.
+func (v *DestinationHistory) SetPaths(paths []string) error {
+	menu, err := gtk.MenuNew()
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		path := path
+		item, err := gtk.MenuItemNewWithLabel(path)
+		if err != nil {
+			return err
+		}
+		_, err = item.Connect("activate", func() {
+			v.selected(path)
+		})
+		if err != nil {
+			return err
+		}
+		menu.Append(item)
+	}
+	if len(paths) == 0 {
+		item, err := gtk.MenuItemNewWithLabel(locale.T(MsgAppWindowDestHistoryEmpty, nil))
+		if err != nil {
+			return err
+		}
+		item.SetSensitive(false)
+		menu.Append(item)
+	}
+	menu.ShowAll()
+	v.button.SetPopup(menu)
+	return nil
+}
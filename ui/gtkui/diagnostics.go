@@ -0,0 +1,296 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package gtkui
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/d2r2/go-rsync/backup"
+	"github.com/d2r2/go-rsync/core"
+	"github.com/d2r2/go-rsync/locale"
+	"github.com/d2r2/go-rsync/rsync"
+	"github.com/d2r2/gotk3/glib"
+	"github.com/d2r2/gotk3/gtk"
+)
+
+// Value printed in settings dump instead of secrets.
+const diagnosticsRedactedValue = "'***'"
+
+// isSecretSettingsKey verify that settings key keep value,
+// which should not leave user computer.
+func isSecretSettingsKey(key string) bool {
+	return key == CFG_CREDENTIAL_PASSWORD || key == CFG_MODULE_AUTH_PASSWORD
+}
+
+// dumpSettings print all keys of settings object with values,
+// where secrets are replaced with placeholder.
+func dumpSettings(buf *bytes.Buffer, store *SettingsStore) error {
+	schema, err := store.GetSchema()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(buf, "[%s]\n", store.path)
+	for _, key := range schema.ListKeys() {
+		value := diagnosticsRedactedValue
+		if !isSecretSettingsKey(key) {
+			value = store.settings.GetValue(key).String()
+		}
+		fmt.Fprintf(buf, "%s = %s\n", key, value)
+	}
+	buf.WriteString("\n")
+	return nil
+}
+
+// dumpAllSettings print application settings with all profiles,
+// backup sources and host credentials.
+func dumpAllSettings(appSettings *SettingsStore) ([]byte, error) {
+	var buf bytes.Buffer
+	err := dumpSettings(&buf, appSettings)
+	if err != nil {
+		return nil, err
+	}
+	for _, profileID := range appSettings.NewSettingsArray(CFG_BACKUP_LIST).GetArrayIDs() {
+		profileSettings, err := getProfileSettings(appSettings, profileID, nil)
+		if err != nil {
+			return nil, err
+		}
+		err = dumpSettings(&buf, profileSettings)
+		if err != nil {
+			return nil, err
+		}
+		for _, sourceID := range profileSettings.NewSettingsArray(CFG_SOURCE_LIST).GetArrayIDs() {
+			sourceSettings, err := getBackupSourceSettings(profileSettings, sourceID, nil)
+			if err != nil {
+				return nil, err
+			}
+			err = dumpSettings(&buf, sourceSettings)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, credentialID := range appSettings.NewSettingsArray(CFG_CREDENTIAL_LIST).GetArrayIDs() {
+		credentialSettings, err := getCredentialSettings(appSettings, credentialID, nil)
+		if err != nil {
+			return nil, err
+		}
+		err = dumpSettings(&buf, credentialSettings)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// getSystemInfo print application, libraries and environment versions.
+func getSystemInfo() []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s %s diagnostics\n", core.GetAppTitle(), core.GetAppVersion())
+	fmt.Fprintf(&buf, "Time: %s\n", time.Now().Format(time.RFC3339))
+	fmt.Fprintf(&buf, "OS: %s\n", runtime.GOOS)
+	fmt.Fprintf(&buf, "Go: %s %s\n", core.GetGolangVersion(), core.GetAppArchitecture())
+	glibMajor, glibMinor, glibMicro := GetGlibVersion()
+	fmt.Fprintf(&buf, "GLIB: %d.%d.%d (compiled %s)\n", glibMajor, glibMinor, glibMicro,
+		glib.GetBuildVersion())
+	gtkMajor, gtkMinor, gtkMicro := GetGtkVersion()
+	fmt.Fprintf(&buf, "GTK: %d.%d.%d (compiled %s)\n", gtkMajor, gtkMinor, gtkMicro,
+		gtk.GetBuildVersion())
+	version, protocol, err := rsync.GetRsyncVersion()
+	if err != nil {
+		fmt.Fprintf(&buf, "RSYNC: %v\n", err)
+	} else {
+		fmt.Fprintf(&buf, "RSYNC: %s (protocol %s)\n", version, protocol)
+	}
+	fmt.Fprintf(&buf, "Language: %s\n", os.Getenv("LANG"))
+	fmt.Fprintf(&buf, "Desktop: %s\n", os.Getenv("XDG_CURRENT_DESKTOP"))
+	fmt.Fprintf(&buf, "Session type: %s\n", os.Getenv("XDG_SESSION_TYPE"))
+	return buf.Bytes()
+}
+
+// getRecentAppLog return recent lines of application log output.
+func getRecentAppLog() []byte {
+	var buf bytes.Buffer
+	if crashLogTail != nil {
+		for _, line := range crashLogTail.GetLines() {
+			buf.WriteString(line)
+			buf.WriteString("\n")
+		}
+	}
+	return buf.Bytes()
+}
+
+// getLastSessionLog return the most recent session
+// log from local archive, if any.
+func getLastSessionLog() (string, []byte, error) {
+	logs, err := backup.ListArchivedLogs()
+	if err != nil || len(logs) == 0 {
+		return "", nil, err
+	}
+	data, err := ioutil.ReadFile(logs[0].Path)
+	if err != nil {
+		return "", nil, err
+	}
+	return filepath.Base(logs[0].Path), data, nil
+}
+
+// writeDiagnosticsBundle save tar.gz archive with application
+// environment, settings and logs to attach to bug reports.
+func writeDiagnosticsBundle(path string, appSettings *SettingsStore) error {
+	type bundleFile struct {
+		name string
+		data []byte
+	}
+	files := []bundleFile{
+		{"system.txt", getSystemInfo()},
+	}
+	settings, err := dumpAllSettings(appSettings)
+	if err != nil {
+		return err
+	}
+	files = append(files, bundleFile{"settings.txt", settings},
+		bundleFile{"app.log", getRecentAppLog()})
+	name, data, err := getLastSessionLog()
+	if err != nil {
+		return err
+	}
+	if data != nil {
+		files = append(files, bundleFile{name, data})
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	gz := gzip.NewWriter(file)
+	tw := tar.NewWriter(gz)
+	folder := strings.TrimSuffix(filepath.Base(path), ".tar.gz")
+	now := time.Now()
+	for _, item := range files {
+		header := &tar.Header{Name: folder + "/" + item.name, Mode: 0600,
+			Size: int64(len(item.data)), ModTime: now, Typeflag: tar.TypeReg}
+		err = tw.WriteHeader(header)
+		if err != nil {
+			return err
+		}
+		_, err = tw.Write(item.data)
+		if err != nil {
+			return err
+		}
+	}
+	err = tw.Close()
+	if err != nil {
+		return err
+	}
+	err = gz.Close()
+	if err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// collectDiagnostics ask user where to save diagnostics bundle, save it
+// and offer to open containing folder.
+func collectDiagnostics(win *gtk.ApplicationWindow, appSettings *SettingsStore) error {
+	dialog, err := gtk.FileChooserDialogNewWith2Buttons(
+		locale.T(MsgAppWindowDiagnosticsSaveDlgTitle, nil), &win.Window,
+		gtk.FILE_CHOOSER_ACTION_SAVE,
+		"_Cancel", gtk.RESPONSE_CANCEL, "_Save", gtk.RESPONSE_ACCEPT)
+	if err != nil {
+		return err
+	}
+	dialog.SetDoOverwriteConfirmation(true)
+	if u, err := user.Current(); err == nil {
+		_ = dialog.SetCurrentFolder(u.HomeDir)
+	}
+	dialog.SetCurrentName(fmt.Sprintf("gorsync_diagnostics_%s.tar.gz",
+		time.Now().Format("2006-01-02_15-04-05")))
+	response := dialog.Run()
+	path := dialog.GetFilename()
+	dialog.Destroy()
+	if response != gtk.RESPONSE_ACCEPT || path == "" {
+		return nil
+	}
+
+	err = writeDiagnosticsBundle(path, appSettings)
+	if err != nil {
+		title := locale.T(MsgAppWindowDiagnosticsErrorTitle, nil)
+		titleMarkup := NewMarkup(MARKUP_SIZE_LARGER, 0, 0, nil, nil,
+			NewMarkup(MARKUP_SIZE_LARGER, 0, 0, title, nil))
+		return ErrorMessage(&win.Window, titleMarkup.String(),
+			[]*DialogParagraph{NewDialogParagraph(err.Error())})
+	}
+
+	title := locale.T(MsgAppWindowDiagnosticsDlgTitle, nil)
+	titleMarkup := NewMarkup(MARKUP_SIZE_LARGER, 0, 0, nil, nil,
+		NewMarkup(MARKUP_SIZE_LARGER, 0, 0, title, nil))
+	paragraphs := []*DialogParagraph{
+		NewDialogParagraph(locale.T(MsgAppWindowDiagnosticsDlgText1,
+			struct{ Path string }{Path: path})),
+		NewDialogParagraph(locale.T(MsgAppWindowDiagnosticsDlgText2, nil)),
+	}
+	buttons := []DialogButton{
+		{locale.T(MsgAppWindowDiagnosticsDlgOpenFolderButton, nil), gtk.RESPONSE_YES, false, nil},
+		{locale.T(MsgAppWindowDiagnosticsDlgCloseButton, nil), gtk.RESPONSE_CLOSE, true, nil},
+	}
+	msgDialog, err := SetupMessageDialog(&win.Window, titleMarkup.String(), "", paragraphs, buttons, nil)
+	if err != nil {
+		return err
+	}
+	response = msgDialog.Run(false)
+	PrintDialogResponse(response)
+	if response == gtk.RESPONSE_YES {
+		uri := &url.URL{Scheme: "file", Path: filepath.Dir(path)}
+		return ShowUri(&win.Window, uri.String())
+	}
+	return nil
+}
+
+// createCollectDiagnosticsAction creates action to save application
+// environment, settings and recent logs in single archive.
+func createCollectDiagnosticsAction(win *gtk.ApplicationWindow, appSettings *SettingsStore) (glib.IAction, error) {
+	act, err := glib.SimpleActionNew("CollectDiagnosticsAction", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = act.Connect("activate", func(action *glib.SimpleAction, param *glib.Variant) {
+		name, state, err := GetActionNameAndState(action)
+		if err != nil {
+			reportError(win, err)
+			return
+		}
+		lg.Debugf("%v action activated with current state %v and args %v",
+			name, state, param)
+
+		err = collectDiagnostics(win, appSettings)
+		if err != nil {
+			reportError(win, err)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return act, nil
+}
@@ -13,11 +13,17 @@ package gtkui
 
 import (
 	"bytes"
+	"strings"
+	"time"
 
+	"github.com/d2r2/go-rsync/backup"
 	"github.com/d2r2/go-rsync/core"
 	"github.com/d2r2/go-rsync/locale"
+	"github.com/d2r2/go-rsync/rsync"
+	"github.com/d2r2/gotk3/gdk"
 	"github.com/d2r2/gotk3/gtk"
 	"github.com/d2r2/gotk3/pango"
+	"github.com/davecgh/go-spew/spew"
 )
 
 // schemaSettingsErrorDialog display error related to GLIB GSettings application configuration.
@@ -123,7 +129,9 @@ const (
 )
 
 // outOfSpaceDialogAsync show dialog once RSYNC out of space issue happens.
-func outOfSpaceDialogAsync(parent *gtk.Window, paths core.SrcDstPath, freeSpace uint64) (OutOfSpaceResponse, error) {
+// RSYNC error explained with suggested action as well.
+func outOfSpaceDialogAsync(parent *gtk.Window, paths core.SrcDstPath, freeSpace uint64,
+	rsyncErr error) (OutOfSpaceResponse, error) {
 	title := locale.T(MsgAppWindowOutOfSpaceDlgTitle, nil)
 	titleMarkup := NewMarkup(MARKUP_SIZE_LARGER, 0, 0, nil, nil,
 		NewMarkup(MARKUP_SIZE_LARGER, 0, 0, title, nil))
@@ -163,6 +171,7 @@ func outOfSpaceDialogAsync(parent *gtk.Window, paths core.SrcDstPath, freeSpace
 		struct{ Path, FreeSpace string }{Path: paths.DestPath,
 			FreeSpace: core.FormatSize(freeSpace, true)})
 	paragraphs := []*DialogParagraph{NewDialogParagraph(text).SetEllipsize(pango.ELLIPSIZE_MIDDLE).SetMaxWidthChars(10)}
+	paragraphs = append(paragraphs, NewDialogParagraph(rsync.FormatErrorWithSuggestion(rsyncErr)))
 	text = locale.T(MsgAppWindowOutOfSpaceDlgText2,
 		struct{ EscapeKey, RetryButton, IgnoreButton, TerminateButton string }{EscapeKey: escapeKeyMarkup.String(),
 			RetryButton: retryButtonMarkup.String(), IgnoreButton: ignoreButtonMarkup.String(),
@@ -192,6 +201,384 @@ func outOfSpaceDialogAsync(parent *gtk.Window, paths core.SrcDstPath, freeSpace
 	}
 }
 
+// PlanInterruptedResponse denote response from plan stage interruption dialog query.
+type PlanInterruptedResponse int
+
+// 3 response type from plan stage interruption dialog query:
+// 1) proceed with backup of RSYNC sources estimated so far;
+// 2) resume plan stage to estimate remaining RSYNC sources;
+// 3) immediately terminate backup process.
+const (
+	PlanInterruptedProceed PlanInterruptedResponse = iota
+	PlanInterruptedResume
+	PlanInterruptedTerminate
+)
+
+// planInterruptedDialogAsync show dialog once plan stage interrupted by user,
+// to decide how to proceed with partial plan. Backup of estimated RSYNC
+// sources is not offered, if none of them are estimated.
+func planInterruptedDialogAsync(parent *gtk.Window, plan *backup.Plan) (PlanInterruptedResponse, error) {
+	title := locale.T(MsgAppWindowPlanInterruptedDlgTitle, nil)
+	titleMarkup := NewMarkup(MARKUP_SIZE_LARGER, 0, 0, nil, nil,
+		NewMarkup(MARKUP_SIZE_LARGER, 0, 0, title, nil))
+	proceedButtonCaption := locale.T(MsgAppWindowPlanInterruptedDlgProceedButton, nil)
+	resumeButtonCaption := locale.T(MsgAppWindowPlanInterruptedDlgResumeButton, nil)
+	terminateButtonCaption := locale.T(MsgAppWindowPlanInterruptedDlgTerminateButton, nil)
+	var buttons []DialogButton
+	if len(plan.Nodes) > 0 {
+		buttons = append(buttons, DialogButton{proceedButtonCaption, gtk.RESPONSE_YES, true,
+			func(btn *gtk.Button) error {
+				style, err2 := btn.GetStyleContext()
+				if err2 != nil {
+					return err2
+				}
+				style.AddClass("suggested-action")
+				return nil
+			}})
+	}
+	buttons = append(buttons,
+		DialogButton{resumeButtonCaption, gtk.RESPONSE_CANCEL, len(plan.Nodes) == 0, nil},
+		DialogButton{terminateButtonCaption, gtk.RESPONSE_NO, false, func(btn *gtk.Button) error {
+			style, err2 := btn.GetStyleContext()
+			if err2 != nil {
+				return err2
+			}
+			style.AddClass("destructive-action")
+			return nil
+		}})
+
+	text := locale.T(MsgAppWindowPlanInterruptedDlgText1,
+		struct{ Estimated, Total int }{Estimated: len(plan.Nodes),
+			Total: len(plan.Nodes) + len(plan.Pending)})
+	paragraphs := []*DialogParagraph{NewDialogParagraph(text)}
+	var buf bytes.Buffer
+	for i, item := range plan.Pending {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		buf.WriteString(NewMarkup(0, 0, 0, item.SourceRsync, nil).String())
+	}
+	paragraphs = append(paragraphs, NewDialogParagraph(buf.String()).SetMarkup(true).
+		SetEllipsize(pango.ELLIPSIZE_MIDDLE))
+	paragraphs = append(paragraphs, NewDialogParagraph(locale.T(MsgAppWindowPlanInterruptedDlgText2, nil)))
+
+	ch := make(chan gtk.ResponseType)
+	defer close(ch)
+
+	MustIdleAdd(func() {
+		dialog, err2 := SetupMessageDialog(parent, titleMarkup.String(), "", paragraphs, buttons, nil)
+		if err2 != nil {
+			lg.Fatal(err2)
+		}
+		ch <- dialog.Run(false)
+	})
+
+	response, _ := <-ch
+	PrintDialogResponse(response)
+
+	if IsResponseYes(response) {
+		return PlanInterruptedProceed, nil
+	} else if IsResponseNo(response) {
+		return PlanInterruptedTerminate, nil
+	} else {
+		return PlanInterruptedResume, nil
+	}
+}
+
+// moduleErrorDialogAsync show dialog once RSYNC source backup failed
+// with critical error, to choose between skipping source and continue
+// backup process, or backup process termination.
+func moduleErrorDialogAsync(parent *gtk.Window, sourceRsync string, moduleErr error) (bool, error) {
+	title := locale.T(MsgAppWindowModuleErrorDlgTitle, nil)
+	titleMarkup := NewMarkup(MARKUP_SIZE_LARGER, 0, 0, nil, nil,
+		NewMarkup(MARKUP_SIZE_LARGER, 0, 0, title, nil))
+	skipButtonCaption := locale.T(MsgAppWindowModuleErrorDlgSkipButton, nil)
+	skipButtonMarkup := NewMarkup(MARKUP_SIZE_LARGER, 0, 0,
+		removeUndescore(skipButtonCaption), nil)
+	terminateButtonCaption := locale.T(MsgAppWindowModuleErrorDlgTerminateButton, nil)
+	terminateButtonMarkup := NewMarkup(MARKUP_SIZE_LARGER, 0, 0,
+		removeUndescore(terminateButtonCaption), nil)
+	escapeKeyMarkup := NewMarkup(MARKUP_SIZE_LARGER, 0, 0, nil, nil,
+		NewMarkup(MARKUP_SIZE_LARGER, 0, 0, "esc", nil))
+	buttons := []DialogButton{
+		{skipButtonCaption, gtk.RESPONSE_YES, true, func(btn *gtk.Button) error {
+			style, err2 := btn.GetStyleContext()
+			if err2 != nil {
+				return err2
+			}
+			style.AddClass("suggested-action")
+			return nil
+		}},
+		{terminateButtonCaption, gtk.RESPONSE_NO, false, func(btn *gtk.Button) error {
+			style, err2 := btn.GetStyleContext()
+			if err2 != nil {
+				return err2
+			}
+			style.AddClass("destructive-action")
+			return nil
+		}},
+	}
+	text := locale.T(MsgAppWindowModuleErrorDlgText1,
+		struct{ RsyncSource string }{RsyncSource: sourceRsync})
+	paragraphs := []*DialogParagraph{NewDialogParagraph(text).SetEllipsize(pango.ELLIPSIZE_MIDDLE).SetMaxWidthChars(10)}
+	paragraphs = append(paragraphs, NewDialogParagraph(rsync.FormatErrorWithSuggestion(moduleErr)))
+	text = locale.T(MsgAppWindowModuleErrorDlgText2,
+		struct{ EscapeKey, SkipButton, TerminateButton string }{EscapeKey: escapeKeyMarkup.String(),
+			SkipButton: skipButtonMarkup.String(), TerminateButton: terminateButtonMarkup.String()})
+	paragraphs = append(paragraphs, NewDialogParagraph(text).SetMarkup(true).SetHorizAlign(gtk.ALIGN_CENTER))
+
+	ch := make(chan gtk.ResponseType)
+	defer close(ch)
+
+	MustIdleAdd(func() {
+		dialog, err2 := SetupMessageDialog(parent, titleMarkup.String(), "", paragraphs, buttons, nil)
+		if err2 != nil {
+			lg.Fatal(err2)
+		}
+		ch <- dialog.Run(false)
+	})
+
+	response, _ := <-ch
+	PrintDialogResponse(response)
+
+	return IsResponseYes(response), nil
+}
+
+// safeDeleteDialogAsync show dialog once too many files deleted in RSYNC
+// source since previous backup, to confirm source backup continuation.
+func safeDeleteDialogAsync(parent *gtk.Window, sourceRsync string, fileCount int,
+	size core.FolderSize) (bool, error) {
+
+	title := locale.T(MsgAppWindowSafeDeleteDlgTitle, nil)
+	titleMarkup := NewMarkup(MARKUP_SIZE_LARGER, 0, 0, nil, nil,
+		NewMarkup(MARKUP_SIZE_LARGER, 0, 0, title, nil))
+	textMarkup := locale.TP(MsgAppWindowSafeDeleteDlgText,
+		struct {
+			RsyncSource string
+			FileCount   int
+			Size        string
+		}{RsyncSource: NewMarkup(0, 0, 0, sourceRsync, nil).String(), FileCount: fileCount,
+			Size: core.GetReadableSize(size)},
+		fileCount)
+
+	ch := make(chan bool)
+	defer close(ch)
+
+	MustIdleAdd(func() {
+		proceed, err2 := questionDialog(parent, titleMarkup.String(), textMarkup, true, true, false)
+		if err2 != nil {
+			lg.Fatal(err2)
+		}
+		ch <- proceed
+	})
+
+	proceed, _ := <-ch
+	return proceed, nil
+}
+
+// destLockDialogAsync show dialog once destination is locked by another
+// backup session, which is stale or can't be verified, to confirm lock override.
+func destLockDialogAsync(parent *gtk.Window, destPath string, info *backup.DestLockInfo,
+	stale bool) (bool, error) {
+
+	title := locale.T(MsgAppWindowDestLockDlgTitle, nil)
+	titleMarkup := NewMarkup(MARKUP_SIZE_LARGER, 0, 0, nil, nil,
+		NewMarkup(MARKUP_SIZE_LARGER, 0, 0, title, nil))
+	msg := MsgAppWindowDestLockDlgForeignText
+	if stale {
+		msg = MsgAppWindowDestLockDlgStaleText
+	}
+	textMarkup := locale.T(msg,
+		struct {
+			Path     string
+			PID      int
+			Hostname string
+			Started  string
+		}{Path: NewMarkup(0, 0, 0, destPath, nil).String(), PID: info.PID,
+			Hostname: NewMarkup(0, 0, 0, info.Hostname, nil).String(),
+			Started:  info.Started.Format("2006 Jan 2 15:04:05")})
+
+	ch := make(chan bool)
+	defer close(ch)
+
+	MustIdleAdd(func() {
+		override, err2 := questionDialog(parent, titleMarkup.String(), textMarkup, true, true, false)
+		if err2 != nil {
+			lg.Fatal(err2)
+		}
+		ch <- override
+	})
+
+	override, _ := <-ch
+	return override, nil
+}
+
+// formatPlanDuration format duration estimated for backup plan.
+func formatPlanDuration(duration time.Duration) string {
+	if duration == 0 {
+		return locale.T(MsgAppWindowBackupPlanDlgDurationUnknown, nil)
+	}
+	var sections = 2
+	return core.FormatDurationToDaysHoursMinsSecs(duration, true, &sections)
+}
+
+// backupPlanDialogAsync show backup plan summary once plan stage
+// completed, to confirm data transfer start.
+func backupPlanDialogAsync(parent *gtk.Window, preview *backup.SessionPreview) (bool, error) {
+	title := locale.T(MsgAppWindowBackupPlanDlgTitle, nil)
+	titleMarkup := NewMarkup(MARKUP_SIZE_LARGER, 0, 0, nil, nil,
+		NewMarkup(MARKUP_SIZE_LARGER, 0, 0, title, nil))
+	startButtonCaption := locale.T(MsgAppWindowBackupPlanDlgStartButton, nil)
+	cancelButtonCaption := locale.T(MsgAppWindowBackupPlanDlgCancelButton, nil)
+	buttons := []DialogButton{
+		{startButtonCaption, gtk.RESPONSE_YES, preview.EnoughSpace(), func(btn *gtk.Button) error {
+			style, err2 := btn.GetStyleContext()
+			if err2 != nil {
+				return err2
+			}
+			style.AddClass("suggested-action")
+			return nil
+		}},
+		{cancelButtonCaption, gtk.RESPONSE_NO, !preview.EnoughSpace(), nil},
+	}
+
+	paragraphs := []*DialogParagraph{NewDialogParagraph(locale.T(MsgAppWindowBackupPlanDlgText, nil))}
+	var buf bytes.Buffer
+	for i, item := range preview.Sources {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		size := locale.T(MsgAppWindowBackupPlanDlgSizeUnknown, nil)
+		if !item.SizeUnknown {
+			size = core.GetReadableSize(item.Size)
+		}
+		buf.WriteString(locale.T(MsgAppWindowBackupPlanDlgSourceText,
+			struct{ RsyncSource, Size, Duration string }{
+				RsyncSource: NewMarkup(0, 0, 0, item.SourceRsync, nil).String(),
+				Size:        size, Duration: formatPlanDuration(item.Duration)}))
+	}
+	paragraphs = append(paragraphs, NewDialogParagraph(buf.String()).SetMarkup(true).
+		SetEllipsize(pango.ELLIPSIZE_MIDDLE))
+
+	buf.Reset()
+	buf.WriteString(locale.T(MsgAppWindowBackupPlanDlgTotalText,
+		struct{ Size string }{Size: core.GetReadableSize(preview.TotalSize)}))
+	buf.WriteString("\n")
+	msg := MsgAppWindowBackupPlanDlgDurationText
+	if preview.DurationPartial && preview.Duration > 0 {
+		msg = MsgAppWindowBackupPlanDlgDurationPartialText
+	}
+	buf.WriteString(locale.T(msg,
+		struct{ Duration string }{Duration: formatPlanDuration(preview.Duration)}))
+	buf.WriteString("\n")
+	var freeSpace *Markup
+	if preview.FreeSpaceErr != nil {
+		freeSpace = NewMarkup(0, MARKUP_COLOR_GOLDENROD, 0,
+			locale.T(MsgAppWindowBackupPlanDlgFreeSpaceUnknownText,
+				struct{ Error error }{Error: preview.FreeSpaceErr}), nil)
+	} else if !preview.EnoughSpace() {
+		freeSpace = NewMarkup(MARKUP_WEIGHT_BOLD, MARKUP_COLOR_ORANGE_RED, 0,
+			locale.T(MsgAppWindowBackupPlanDlgFreeSpaceLowText,
+				struct{ FreeSpace string }{FreeSpace: core.FormatSize(preview.FreeSpace, true)}), nil)
+	} else {
+		freeSpace = NewMarkup(0, MARKUP_COLOR_FOREST_GREEN, 0,
+			locale.T(MsgAppWindowBackupPlanDlgFreeSpaceText,
+				struct{ FreeSpace string }{FreeSpace: core.FormatSize(preview.FreeSpace, true)}), nil)
+	}
+	buf.WriteString(freeSpace.String())
+	paragraphs = append(paragraphs, NewDialogParagraph(buf.String()).SetMarkup(true))
+
+	ch := make(chan gtk.ResponseType)
+	defer close(ch)
+
+	MustIdleAdd(func() {
+		dialog, err2 := SetupMessageDialog(parent, titleMarkup.String(), "", paragraphs, buttons, nil)
+		if err2 != nil {
+			lg.Fatal(err2)
+		}
+		ch <- dialog.Run(false)
+	})
+
+	response, _ := <-ch
+	PrintDialogResponse(response)
+
+	return IsResponseYes(response), nil
+}
+
+// sessionCommentDialogAsync ask for free-text comment to attach to completed
+// backup session. Return empty string, if comment skipped.
+func sessionCommentDialogAsync(parent *gtk.Window) (string, error) {
+	title := locale.T(MsgAppWindowSessionCommentDlgTitle, nil)
+	titleMarkup := NewMarkup(MARKUP_SIZE_LARGER, 0, 0, nil, nil,
+		NewMarkup(MARKUP_SIZE_LARGER, 0, 0, title, nil))
+	saveButtonCaption := locale.T(MsgAppWindowSessionCommentDlgSaveButton, nil)
+	skipButtonCaption := locale.T(MsgAppWindowSessionCommentDlgSkipButton, nil)
+	buttons := []DialogButton{
+		{saveButtonCaption, gtk.RESPONSE_YES, true, func(btn *gtk.Button) error {
+			style, err2 := btn.GetStyleContext()
+			if err2 != nil {
+				return err2
+			}
+			style.AddClass("suggested-action")
+			return nil
+		}},
+		{skipButtonCaption, gtk.RESPONSE_NO, false, nil},
+	}
+	paragraphs := []*DialogParagraph{NewDialogParagraph(locale.T(MsgAppWindowSessionCommentDlgText, nil))}
+
+	type result struct {
+		response gtk.ResponseType
+		comment  string
+		err      error
+	}
+	ch := make(chan result)
+	defer close(ch)
+
+	MustIdleAdd(func() {
+		var edComment *gtk.Entry
+		dialog, err2 := SetupMessageDialog(parent, titleMarkup.String(), "", paragraphs, buttons,
+			func(area *gtk.Box) error {
+				var err error
+				edComment, err = gtk.EntryNew()
+				if err != nil {
+					return err
+				}
+				edComment.SetPlaceholderText(locale.T(MsgAppWindowSessionCommentDlgPlaceholder, nil))
+				edComment.SetActivatesDefault(true)
+				area.PackStart(edComment, false, false, 0)
+				return nil
+			})
+		if err2 != nil {
+			ch <- result{err: err2}
+			return
+		}
+		// Entry destroyed together with dialog, so read text on response.
+		var comment string
+		var errText error
+		_, err2 = dialog.dialog.Connect("response", func() {
+			comment, errText = edComment.GetText()
+		})
+		if err2 != nil {
+			dialog.dialog.Destroy()
+			ch <- result{err: err2}
+			return
+		}
+		response := dialog.Run(false)
+		ch <- result{response: response, comment: comment, err: errText}
+	})
+
+	res, _ := <-ch
+	if res.err != nil {
+		return "", res.err
+	}
+	PrintDialogResponse(res.response)
+
+	if !IsResponseYes(res.response) {
+		return "", nil
+	}
+	return strings.TrimSpace(res.comment), nil
+}
+
 // questionDialog shows standard question dialog with localizable YES/NO selection.
 func questionDialog(parent *gtk.Window, titleMarkup string, textMarkup string,
 	defaultNo bool, yesDestructive bool, noSuggested bool) (bool, error) {
@@ -241,3 +628,112 @@ func questionDialog(parent *gtk.Window, titleMarkup string, textMarkup string,
 		}
 	}
 }
+
+// formatCheckItemMarkup build markup text to display single environment verification result.
+func formatCheckItemMarkup(item backup.CheckItem) *Markup {
+	var status *Markup
+	switch item.Status {
+	case backup.CheckPassed:
+		status = NewMarkup(MARKUP_WEIGHT_BOLD, MARKUP_COLOR_FOREST_GREEN, 0,
+			locale.T(MsgCheckProfileDlgStatusPassed, nil), nil)
+	case backup.CheckWarning:
+		status = NewMarkup(MARKUP_WEIGHT_BOLD, MARKUP_COLOR_GOLDENROD, 0,
+			locale.T(MsgCheckProfileDlgStatusWarning, nil), nil)
+	default:
+		status = NewMarkup(MARKUP_WEIGHT_BOLD, MARKUP_COLOR_ORANGE_RED, 0,
+			locale.T(MsgCheckProfileDlgStatusFailed, nil), nil)
+	}
+	spans := []*Markup{status, NewMarkup(0, 0, 0, " ", nil)}
+	if item.Subject != "" {
+		spans = append(spans, NewMarkup(0, MARKUP_COLOR_LIGHT_GRAY, 0,
+			spew.Sprintf("%s: ", item.Subject), nil))
+	}
+	spans = append(spans, NewMarkup(0, 0, 0, item.Message, nil))
+	return NewMarkup(0, 0, 0, nil, nil, spans...)
+}
+
+// checkProfileReportDialog shows consolidated report of backup profile environment verification.
+func checkProfileReportDialog(parent *gtk.Window, report *backup.CheckReport) error {
+	var title string
+	switch report.GetStatus() {
+	case backup.CheckPassed:
+		title = locale.T(MsgCheckProfileDlgTitlePassed,
+			struct{ ProfileName string }{ProfileName: report.ProfileName})
+	case backup.CheckWarning:
+		title = locale.T(MsgCheckProfileDlgTitleWarning,
+			struct{ ProfileName string }{ProfileName: report.ProfileName})
+	default:
+		title = locale.T(MsgCheckProfileDlgTitleFailed,
+			struct{ ProfileName string }{ProfileName: report.ProfileName})
+	}
+	return checkReportDialog(parent, title, report)
+}
+
+// testDestinationReportDialog shows results of destination folder test.
+func testDestinationReportDialog(parent *gtk.Window, destPath string, report *backup.CheckReport) error {
+	var title string
+	switch report.GetStatus() {
+	case backup.CheckPassed:
+		title = locale.T(MsgTestDestinationDlgTitlePassed, struct{ Path string }{Path: destPath})
+	case backup.CheckWarning:
+		title = locale.T(MsgTestDestinationDlgTitleWarning, struct{ Path string }{Path: destPath})
+	default:
+		title = locale.T(MsgTestDestinationDlgTitleFailed, struct{ Path string }{Path: destPath})
+	}
+	return checkReportDialog(parent, title, report)
+}
+
+// verifySessionReportDialog shows results of backup session integrity verification.
+func verifySessionReportDialog(parent *gtk.Window, sessionPath string, report *backup.CheckReport) error {
+	var title string
+	if report.GetStatus() == backup.CheckPassed {
+		title = locale.T(MsgVerifySessionDlgTitlePassed, struct{ Path string }{Path: sessionPath})
+	} else {
+		title = locale.T(MsgVerifySessionDlgTitleFailed, struct{ Path string }{Path: sessionPath})
+	}
+	return checkReportDialog(parent, title, report)
+}
+
+// checkReportDialog shows report of environment verifications with title specified.
+// Allow to copy machine-readable (JSON) report to the clipboard.
+func checkReportDialog(parent *gtk.Window, title string, report *backup.CheckReport) error {
+	titleMarkup := NewMarkup(MARKUP_SIZE_LARGER, 0, 0, nil, nil,
+		NewMarkup(MARKUP_SIZE_LARGER, 0, 0, title, nil))
+
+	var paragraphs []*DialogParagraph
+	for _, item := range report.Items {
+		paragraphs = append(paragraphs, NewDialogParagraph(formatCheckItemMarkup(item).String()).
+			SetMarkup(true).SetHorizAlign(gtk.ALIGN_START).
+			SetEllipsize(pango.ELLIPSIZE_MIDDLE).SetMaxWidthChars(80))
+	}
+
+	buttons := []DialogButton{
+		{locale.T(MsgCheckProfileDlgCopyJSONButton, nil), gtk.RESPONSE_APPLY, false, nil},
+		{"_OK", gtk.RESPONSE_OK, true, func(btn *gtk.Button) error {
+			style, err2 := btn.GetStyleContext()
+			if err2 != nil {
+				return err2
+			}
+			style.AddClass("suggested-action")
+			return nil
+		}},
+	}
+	dialog, err := SetupMessageDialog(parent, titleMarkup.String(), "", paragraphs, buttons, nil)
+	if err != nil {
+		return err
+	}
+	response := dialog.Run(false)
+	PrintDialogResponse(response)
+	if response == gtk.RESPONSE_APPLY {
+		buf, err := report.JSON()
+		if err != nil {
+			return err
+		}
+		clipboard, err := gtk.ClipboardGet(gdk.SELECTION_CLIPBOARD)
+		if err != nil {
+			return err
+		}
+		clipboard.SetText(string(buf))
+	}
+	return nil
+}
@@ -0,0 +1,287 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package gtkui
+
+import (
+	"sort"
+
+	"github.com/d2r2/go-rsync/backup"
+	"github.com/d2r2/go-rsync/core"
+	"github.com/d2r2/go-rsync/locale"
+	"github.com/d2r2/gotk3/glib"
+	"github.com/d2r2/gotk3/gtk"
+)
+
+// Columns of disk usage tree.
+const (
+	diskUsageColumnName = iota
+	diskUsageColumnSize
+	diskUsageColumnPercent
+	diskUsageColumnBackupType
+	diskUsageColumnSourcePath
+)
+
+// getDirSize return size of folder content, if measured in plan stage.
+// Folders located inside of folders backed up recursively are not measured.
+func getDirSize(dir *core.Dir) (core.FolderSize, bool) {
+	if dir.Metrics.FullSize == nil {
+		return 0, false
+	}
+	return *dir.Metrics.FullSize, true
+}
+
+// appendDirToTree add folder and measured subfolders to disk usage tree,
+// ordered by size, so the largest folders come first.
+// Return tree item created, or nil if folder is not measured.
+func appendDirToTree(store *gtk.TreeStore, parent *gtk.TreeIter, dir *core.Dir,
+	name string, total core.FolderSize) (*gtk.TreeIter, error) {
+
+	size, ok := getDirSize(dir)
+	if !ok {
+		return nil, nil
+	}
+	var percent int
+	if total > 0 {
+		percent = int(size * 100 / total)
+	}
+	iter := store.Append(parent)
+	values := []interface{}{name, core.GetReadableSize(size), percent,
+		backup.GetBackupTypeDescription(dir.Metrics.BackupType), dir.GetPaths().RsyncSourcePath}
+	for i, value := range values {
+		err := store.SetValue(iter, i, value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	childs := make([]*core.Dir, len(dir.Childs))
+	copy(childs, dir.Childs)
+	sort.SliceStable(childs, func(i, j int) bool {
+		size1, _ := getDirSize(childs[i])
+		size2, _ := getDirSize(childs[j])
+		return size1 > size2
+	})
+	for _, item := range childs {
+		_, err := appendDirToTree(store, iter, item, item.Name, total)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return iter, nil
+}
+
+// CreateDiskUsageWindow build window to display source folders sizes measured
+// in plan stage, so it's easy to find where the bulk of backup lies.
+// Local and SSH folders might be marked to skip in next backup sessions.
+func CreateDiskUsageWindow(mainWin *gtk.ApplicationWindow, plan *backup.Plan) (*gtk.ApplicationWindow, error) {
+	app, err := mainWin.GetApplication()
+	if err != nil {
+		return nil, err
+	}
+	win, err := gtk.ApplicationWindowNew(app)
+	if err != nil {
+		return nil, err
+	}
+	win.SetTransientFor(mainWin)
+	win.SetDestroyWithParent(false)
+	win.SetShowMenubar(false)
+	win.SetDefaultSize(800, 600)
+
+	hdr, err := SetupHeader(locale.T(MsgDiskUsageWindowCaption, nil),
+		locale.T(MsgDiskUsageWindowSubcaption,
+			struct{ Size string }{Size: core.GetReadableSize(plan.BackupSize)}), true)
+	if err != nil {
+		return nil, err
+	}
+	win.SetTitlebar(hdr)
+
+	box, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 6)
+	if err != nil {
+		return nil, err
+	}
+	SetAllMargins(box, 9)
+
+	store, err := gtk.TreeStoreNew(glib.TYPE_STRING, glib.TYPE_STRING, glib.TYPE_INT,
+		glib.TYPE_STRING, glib.TYPE_STRING)
+	if err != nil {
+		return nil, err
+	}
+	var roots []*gtk.TreeIter
+	for _, node := range plan.Nodes {
+		size, ok := getDirSize(node.RootDir)
+		if !ok {
+			continue
+		}
+		iter, err := appendDirToTree(store, nil, node.RootDir, node.Module.SourceRsync, size)
+		if err != nil {
+			return nil, err
+		}
+		roots = append(roots, iter)
+	}
+
+	tv, err := gtk.TreeViewNewWithModel(store)
+	if err != nil {
+		return nil, err
+	}
+	err = appendTextColumn(tv, locale.T(MsgDiskUsageFolderColumn, nil), diskUsageColumnName)
+	if err != nil {
+		return nil, err
+	}
+	cell, err := gtk.CellRendererProgressNew()
+	if err != nil {
+		return nil, err
+	}
+	col, err := gtk.TreeViewColumnNewWithAttribute(locale.T(MsgDiskUsageSizeColumn, nil),
+		cell, "value", diskUsageColumnPercent)
+	if err != nil {
+		return nil, err
+	}
+	col.AddAttribute(cell, "text", diskUsageColumnSize)
+	col.SetMinWidth(150)
+	tv.AppendColumn(col)
+	err = appendTextColumn(tv, locale.T(MsgDiskUsageBackupTypeColumn, nil), diskUsageColumnBackupType)
+	if err != nil {
+		return nil, err
+	}
+
+	sw, err := gtk.ScrolledWindowNew(nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	sw.SetVExpand(true)
+	sw.Add(tv)
+	box.PackStart(sw, true, true, 0)
+
+	box2, err := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 6)
+	if err != nil {
+		return nil, err
+	}
+	status, err := gtk.LabelNew("")
+	if err != nil {
+		return nil, err
+	}
+	status.SetHAlign(gtk.ALIGN_START)
+	status.SetHExpand(true)
+	box2.PackStart(status, true, true, 0)
+	btnSkip, err := gtk.ButtonNewWithLabel(locale.T(MsgDiskUsageSkipFolderButton, nil))
+	if err != nil {
+		return nil, err
+	}
+	btnSkip.SetTooltipText(locale.T(MsgDiskUsageSkipFolderHint,
+		struct{ FileName string }{FileName: plan.Config.SigFileIgnoreBackup}))
+	btnSkip.SetSensitive(false)
+	box2.PackEnd(btnSkip, false, false, 0)
+	box.PackStart(box2, false, false, 0)
+
+	selection, err := tv.GetSelection()
+	if err != nil {
+		return nil, err
+	}
+	// getSelectedSourcePath return path of selected folder.
+	getSelectedSourcePath := func() (*gtk.TreeIter, string) {
+		_, iter, ok := selection.GetSelected()
+		if !ok {
+			return nil, ""
+		}
+		val, err := store.GetValue(iter, diskUsageColumnSourcePath)
+		if err != nil {
+			lg.Fatal(err)
+		}
+		path, err := val.GetString()
+		if err != nil {
+			lg.Fatal(err)
+		}
+		return iter, path
+	}
+
+	// Signature file can't be created in RSYNC daemon modules.
+	_, err = selection.Connect("changed", func() {
+		_, path := getSelectedSourcePath()
+		btnSkip.SetSensitive(path != "" && backup.CanManageIgnoreSignature(path) &&
+			plan.Config.SigFileIgnoreBackup != "")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = btnSkip.Connect("clicked", func() {
+		iter, path := getSelectedSourcePath()
+		if iter == nil {
+			return
+		}
+		err := backup.SetIgnoreSignature(path, plan.Config.SigFileIgnoreBackup, true)
+		if err != nil {
+			status.SetText(locale.T(MsgDiskUsageSkipFolderError,
+				struct {
+					Path  string
+					Error error
+				}{Path: path, Error: err}))
+			return
+		}
+		err = store.SetValue(iter, diskUsageColumnBackupType,
+			backup.GetBackupTypeDescription(core.FBT_SKIP))
+		if err != nil {
+			lg.Fatal(err)
+		}
+		status.SetText(locale.T(MsgDiskUsageSkipFolderDone,
+			struct{ Path string }{Path: path}))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Show top level folders of each source.
+	for _, iter := range roots {
+		path, err := store.GetPath(iter)
+		if err != nil {
+			return nil, err
+		}
+		tv.ExpandRow(path, false)
+	}
+
+	win.Add(box)
+
+	return win, nil
+}
+
+// createDiskUsageAction creates action to open window with source
+// folders sizes, measured in plan stage of selected profile.
+func createDiskUsageAction(mainWin *gtk.ApplicationWindow, plan **backup.Plan) (glib.IAction, error) {
+	act, err := glib.SimpleActionNew("DiskUsageAction", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	act.SetEnabled(false)
+	_, err = act.Connect("activate", func(action *glib.SimpleAction, param *glib.Variant) {
+		name, state, err := GetActionNameAndState(action)
+		if err != nil {
+			lg.Fatal(err)
+		}
+		lg.Debugf("%v action activated with current state %v and args %v",
+			name, state, param)
+
+		if *plan == nil {
+			return
+		}
+		win, err := CreateDiskUsageWindow(mainWin, *plan)
+		if err != nil {
+			lg.Fatal(err)
+		}
+
+		win.ShowAll()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return act, nil
+}
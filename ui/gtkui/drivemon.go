@@ -0,0 +1,189 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package gtkui
+
+// #cgo pkg-config: gio-2.0
+// #include <stdlib.h>
+// #include <gio/gio.h>
+//
+// // File system UUID of mounted volume, taken from volume
+// // if available, either from mount itself.
+// static gchar* _mount_get_fs_uuid(GMount *mount) {
+//     gchar *uuid = NULL;
+//     GVolume *volume = g_mount_get_volume(mount);
+//     if (volume != NULL) {
+//         uuid = g_volume_get_uuid(volume);
+//         g_object_unref(volume);
+//     }
+//     if (uuid == NULL) {
+//         uuid = g_mount_get_uuid(mount);
+//     }
+//     return uuid;
+// }
+//
+// static gchar* _mount_get_root_path(GMount *mount) {
+//     GFile *root = g_mount_get_root(mount);
+//     gchar *path = g_file_get_path(root);
+//     g_object_unref(root);
+//     return path;
+// }
+import "C"
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unsafe"
+
+	"github.com/d2r2/go-rsync/locale"
+	"github.com/d2r2/gotk3/glib"
+	"github.com/d2r2/gotk3/gtk"
+)
+
+// DriveMonitor track removable drives plugged in, with help of
+// GVolumeMonitor, to report file system UUID and mount point
+// of each new mount.
+type DriveMonitor struct {
+	sync.Mutex
+	obj     *glib.Object
+	handler func(uuid, mountPoint string)
+}
+
+// Global object to track removable drives, initialized once
+// with main window, since GVolumeMonitor is a singleton.
+var driveMonitor *DriveMonitor
+
+// NewDriveMonitor create monitor of mounts added to the system.
+func NewDriveMonitor() (*DriveMonitor, error) {
+	monitor := C.g_volume_monitor_get()
+	if monitor == nil {
+		return nil, errors.New("can't create GVolumeMonitor")
+	}
+	// Go object own GVolumeMonitor instance now.
+	obj := glib.Take(unsafe.Pointer(monitor))
+	C.g_object_unref(C.gpointer(monitor))
+
+	v := &DriveMonitor{obj: obj}
+	_, err := obj.Connect("mount-added", func(monitor *glib.Object, mount *glib.Object) {
+		v.mountAdded((*C.GMount)(unsafe.Pointer(mount.GObject)))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// SetHandler assign function called from main loop,
+// when file system mounted.
+func (v *DriveMonitor) SetHandler(handler func(uuid, mountPoint string)) {
+	v.Lock()
+	defer v.Unlock()
+	v.handler = handler
+}
+
+func (v *DriveMonitor) mountAdded(mount *C.GMount) {
+	v.Lock()
+	handler := v.handler
+	v.Unlock()
+	if handler == nil {
+		return
+	}
+	cuuid := C._mount_get_fs_uuid(mount)
+	if cuuid == nil {
+		return
+	}
+	defer C.g_free(C.gpointer(cuuid))
+	cpath := C._mount_get_root_path(mount)
+	if cpath == nil {
+		return
+	}
+	defer C.g_free(C.gpointer(cpath))
+	uuid := C.GoString((*C.char)(cuuid))
+	mountPoint := C.GoString((*C.char)(cpath))
+	lg.Debugf("Mount added: UUID=%s at %q", uuid, mountPoint)
+	handler(uuid, mountPoint)
+}
+
+// findDriveProfile find profile bound to removable drive
+// by file system UUID. Return empty profileID if nothing found.
+func findDriveProfile(uuid string) (profileID string, subPath string, askBackup bool, err error) {
+	appSettings, err := NewSettingsStore(SETTINGS_SCHEMA_ID, SETTINGS_SCHEMA_PATH, nil)
+	if err != nil {
+		return "", "", false, err
+	}
+	sarr := appSettings.NewSettingsArray(CFG_BACKUP_LIST)
+	for _, item := range sarr.GetArrayIDs() {
+		profileSettings, err := getProfileSettings(appSettings, item, nil)
+		if err != nil {
+			return "", "", false, err
+		}
+		driveUUID := strings.TrimSpace(profileSettings.settings.GetString(CFG_PROFILE_DEST_DRIVE_UUID))
+		if driveUUID != "" && strings.EqualFold(driveUUID, uuid) {
+			return item, profileSettings.settings.GetString(CFG_PROFILE_DEST_DRIVE_SUBPATH),
+				profileSettings.settings.GetBoolean(CFG_PROFILE_DEST_DRIVE_ASK_BACKUP), nil
+		}
+	}
+	return "", "", false, nil
+}
+
+// selectDriveProfile select profile bound to removable drive plugged in,
+// assign destination located on this drive and, if enabled, ask to start backup.
+func selectDriveProfile(win *gtk.ApplicationWindow, profile *gtk.ComboBox,
+	destFolder *gtk.FileChooserButton, profileObjects *ProfileObjects,
+	uuid, mountPoint string) error {
+
+	// Profile selector is disabled while backup session is running.
+	if !profile.GetSensitive() {
+		return nil
+	}
+	profileID, subPath, askBackup, err := findDriveProfile(uuid)
+	if err != nil || profileID == "" {
+		return err
+	}
+	lg.Debugf("Drive UUID=%s match profile %v", uuid, profileID)
+	profile.SetActiveID(profileID)
+	destFolder.SetFilename(filepath.Join(mountPoint, subPath))
+	err = updateDestPathWidget(destFolder, profileObjects.destControl)
+	if err != nil {
+		return err
+	}
+	profileObjects.lastDestPath = destFolder.GetFilename()
+	if !askBackup || profileObjects.lastDestPath == "" {
+		return nil
+	}
+
+	val, err := GetComboValue(profile, 0)
+	if err != nil {
+		return err
+	}
+	profileName, err := val.GetString()
+	if err != nil {
+		return err
+	}
+	win.Present()
+	title := locale.T(MsgAppWindowDriveBackupDlgTitle, nil)
+	titleMarkup := NewMarkup(MARKUP_SIZE_LARGER, 0, 0, nil, nil,
+		NewMarkup(MARKUP_SIZE_LARGER, 0, 0, title, nil))
+	textMarkup := locale.T(MsgAppWindowDriveBackupDlgText,
+		struct{ ProfileName, MountPoint string }{
+			ProfileName: NewMarkup(MARKUP_WEIGHT_BOLD, 0, 0, profileName, nil).String(),
+			MountPoint:  NewMarkup(0, 0, 0, mountPoint, nil).String()})
+	responseYes, err := questionDialog(&win.Window, titleMarkup.String(),
+		textMarkup, false, false, false)
+	if err != nil {
+		return err
+	}
+	if responseYes {
+		return activateAction(win, "RunBackupAction")
+	}
+	return nil
+}
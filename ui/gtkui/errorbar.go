@@ -0,0 +1,108 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package gtkui
+
+import (
+	"github.com/d2r2/go-rsync/locale"
+	"github.com/d2r2/gotk3/gtk"
+)
+
+// ErrorBar is a non-modal error message shown at the top of the window,
+// which user might close. Used to report recoverable errors raised in
+// GTK+ signal handlers, instead of application termination.
+type ErrorBar struct {
+	bar *gtk.InfoBar
+	// Error text is shown as plain text, since it might contain paths
+	// and RSYNC output with characters reserved by Pango markup.
+	label *gtk.Label
+}
+
+// Error bars of windows found by native window pointer.
+var errorBars = map[uintptr]*ErrorBar{}
+
+// NewErrorBar create hidden error bar, which will
+// show errors reported for the window specified.
+func NewErrorBar(win *gtk.Window) (*ErrorBar, error) {
+	bar, err := gtk.InfoBarNew()
+	if err != nil {
+		return nil, err
+	}
+	bar.SetMessageType(gtk.MESSAGE_ERROR)
+	bar.SetShowCloseButton(true)
+	// Keep bar hidden on window ShowAll call.
+	bar.SetNoShowAll(true)
+	lblTitle, err := gtk.LabelNew("")
+	if err != nil {
+		return nil, err
+	}
+	title := locale.T(MsgAppWindowErrorBarTitle, nil)
+	lblTitle.SetMarkup(NewMarkup(MARKUP_WEIGHT_BOLD, 0, 0, title, nil).String())
+	lblTitle.SetVAlign(gtk.ALIGN_START)
+	lbl, err := gtk.LabelNew("")
+	if err != nil {
+		return nil, err
+	}
+	lbl.SetLineWrap(true)
+	lbl.SetSelectable(true)
+	lbl.SetHAlign(gtk.ALIGN_START)
+	content, err := bar.GetContentArea()
+	if err != nil {
+		return nil, err
+	}
+	content.Add(lblTitle)
+	lblTitle.Show()
+	content.Add(lbl)
+	lbl.Show()
+	_, err = bar.Connect("response", func(bar *gtk.InfoBar) {
+		bar.Hide()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	v := &ErrorBar{bar: bar, label: lbl}
+	key := win.Native()
+	errorBars[key] = v
+	_, err = win.Connect("destroy", func() {
+		delete(errorBars, key)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// GetWidget return widget to place in the window.
+func (v *ErrorBar) GetWidget() *gtk.Widget {
+	return &v.bar.Widget
+}
+
+// ShowError show error message, replacing previous one.
+func (v *ErrorBar) ShowError(err error) {
+	v.label.SetText(err.Error())
+	v.bar.Show()
+}
+
+// reportError log error and show it in the error bar of
+// the window, which contain widget, keeping application running.
+// Should be called from GTK+ main thread.
+func reportError(widget gtk.IWidget, err error) {
+	lg.Error(err)
+	top, err2 := widget.ToWidget().GetToplevel()
+	if err2 != nil {
+		lg.Warn(err2)
+		return
+	}
+	if bar, ok := errorBars[top.ToWidget().Native()]; ok {
+		bar.ShowError(err)
+	}
+}
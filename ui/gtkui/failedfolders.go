@@ -0,0 +1,123 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package gtkui
+
+import (
+	"github.com/d2r2/go-rsync/backup"
+	"github.com/d2r2/go-rsync/core"
+	"github.com/d2r2/go-rsync/locale"
+	"github.com/d2r2/gotk3/gtk"
+	"github.com/d2r2/gotk3/pango"
+)
+
+// Number of failed folders shown in panel without scrolling.
+const failedFoldersVisibleRows = 6
+
+// addFailedFoldersPanel append collapsible panel to progress grid, which
+// list folders failed to backup in the session with error and size,
+// to open or retry each of them without scrolling session log.
+func (v *NotifierUI) addFailedFoldersPanel(backupProgress *backup.Progress) error {
+	sessionPath := backupProgress.GetBackupFullPath(backupProgress.BackupFolder)
+	count := len(backupProgress.FailedFolders)
+	exp, err := gtk.ExpanderNew(locale.TP(MsgAppWindowFailedFoldersCaption,
+		struct{ FolderCount int }{FolderCount: count}, count))
+	if err != nil {
+		return err
+	}
+	exp.SetTooltipText(locale.T(MsgAppWindowFailedFoldersHint, nil))
+	exp.SetExpanded(true)
+
+	grid, err := gtk.GridNew()
+	if err != nil {
+		return err
+	}
+	grid.SetColumnSpacing(12)
+	grid.SetRowSpacing(3)
+	SetMargins(grid, 18, 3, 0, 3)
+
+	for i, folder := range backupProgress.FailedFolders {
+		folder := folder
+		path := core.RsyncPathJoin(folder.SourceRsync, folder.RelativePath)
+		lbl, err := gtk.LabelNew(path)
+		if err != nil {
+			return err
+		}
+		lbl.SetHAlign(gtk.ALIGN_START)
+		lbl.SetEllipsize(pango.ELLIPSIZE_MIDDLE)
+		lbl.SetMaxWidthChars(40)
+		lbl.SetTooltipText(path)
+		grid.Attach(lbl, 0, i, 1, 1)
+
+		lbl, err = gtk.LabelNew(core.GetReadableSize(folder.Size))
+		if err != nil {
+			return err
+		}
+		lbl.SetHAlign(gtk.ALIGN_END)
+		grid.Attach(lbl, 1, i, 1, 1)
+
+		lbl, err = gtk.LabelNew("")
+		if err != nil {
+			return err
+		}
+		lbl.SetMarkup(NewMarkup(0, MARKUP_COLOR_ORANGE_RED, 0, folder.Error, nil).String())
+		lbl.SetHAlign(gtk.ALIGN_START)
+		lbl.SetHExpand(true)
+		lbl.SetEllipsize(pango.ELLIPSIZE_END)
+		lbl.SetTooltipText(folder.Error)
+		grid.Attach(lbl, 2, i, 1, 1)
+
+		destPath := backupProgress.GetFailedFolderPath(folder)
+		btnOpen, err := SetupButtonWithThemedImage("folder-open-symbolic")
+		if err != nil {
+			return err
+		}
+		btnOpen.SetTooltipText(locale.T(MsgAppWindowFailedFolderOpenHint, nil))
+		_, err = btnOpen.Connect("clicked", func() {
+			showNearestFolder(&v.win.Window, destPath)
+		})
+		if err != nil {
+			return err
+		}
+		grid.Attach(btnOpen, 3, i, 1, 1)
+
+		if v.retryHandler != nil {
+			btnRetry, err := SetupButtonWithThemedImage("view-refresh-symbolic")
+			if err != nil {
+				return err
+			}
+			btnRetry.SetTooltipText(locale.T(MsgAppWindowFailedFolderRetryHint, nil))
+			_, err = btnRetry.Connect("clicked", func(btn *gtk.Button) {
+				btn.SetSensitive(false)
+				v.retryHandler(sessionPath, &folder)
+			})
+			if err != nil {
+				return err
+			}
+			grid.Attach(btnRetry, 4, i, 1, 1)
+		}
+	}
+
+	sw, err := gtk.ScrolledWindowNew(nil, nil)
+	if err != nil {
+		return err
+	}
+	sw.SetPolicy(gtk.POLICY_NEVER, gtk.POLICY_AUTOMATIC)
+	if count > failedFoldersVisibleRows {
+		sw.SetSizeRequest(-1, failedFoldersVisibleRows*36)
+	}
+	sw.Add(grid)
+	exp.Add(sw)
+
+	v.gridUI.Attach(exp, 0, PROGRESS_GRID_FAILED_FOLDERS_ROW, 2, 1)
+	exp.ShowAll()
+	return nil
+}
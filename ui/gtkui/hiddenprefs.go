@@ -0,0 +1,327 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package gtkui
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/d2r2/go-rsync/locale"
+	"github.com/d2r2/gotk3/glib"
+	"github.com/d2r2/gotk3/gtk"
+	"github.com/d2r2/gotk3/pango"
+)
+
+// Columns of hidden settings list.
+const (
+	hiddenSettingsColumnName = iota
+	hiddenSettingsColumnType
+	hiddenSettingsColumnValue
+	hiddenSettingsColumnSummary
+	hiddenSettingsColumnWeight
+	hiddenSettingsColumnEditable
+)
+
+// Keys, which keep structure of settings (list of profiles
+// and credentials), so must not be edited manually.
+var hiddenSettingsReadOnlyKeys = []string{CFG_BACKUP_LIST, CFG_CREDENTIAL_LIST}
+
+// HiddenSettings keep widgets of preference page, which list all keys
+// of application settings schema with their values, so rarely used
+// options might be changed without dconf-editor.
+type HiddenSettings struct {
+	settings *glib.Settings
+	keys     []string
+	store    *gtk.ListStore
+	status   *gtk.Label
+}
+
+// hiddenSettingsRowWeight return font weight to highlight value changed by user.
+func hiddenSettingsRowWeight(modified bool) int {
+	if modified {
+		return int(pango.WEIGHT_BOLD)
+	}
+	return int(pango.WEIGHT_NORMAL)
+}
+
+func isHiddenSettingsKeyEditable(key string) bool {
+	for _, item := range hiddenSettingsReadOnlyKeys {
+		if item == key {
+			return false
+		}
+	}
+	return true
+}
+
+// load fill the list with keys, which name or summary contains filter text.
+func (v *HiddenSettings) load(filter string) error {
+	v.store.Clear()
+	v.status.SetText("")
+	filter = strings.ToLower(strings.TrimSpace(filter))
+	for _, name := range v.keys {
+		key := getSettingsKey(v.settings, name)
+		if filter != "" && !strings.Contains(strings.ToLower(key.Name), filter) &&
+			!strings.Contains(strings.ToLower(key.Summary), filter) {
+			continue
+		}
+		_, err := AppendValues(v.store, key.Name, key.Type,
+			getSettingsValueText(v.settings, key), key.Summary,
+			hiddenSettingsRowWeight(isSettingsValueModified(v.settings, key.Name)),
+			isHiddenSettingsKeyEditable(key.Name))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getKeyName return settings key name in the list row.
+func (v *HiddenSettings) getKeyName(iter *gtk.TreeIter) (string, error) {
+	val, err := v.store.GetValue(iter, hiddenSettingsColumnName)
+	if err != nil {
+		return "", err
+	}
+	return val.GetString()
+}
+
+// update refresh value of settings key in the list row.
+func (v *HiddenSettings) update(iter *gtk.TreeIter) error {
+	name, err := v.getKeyName(iter)
+	if err != nil {
+		return err
+	}
+	key := getSettingsKey(v.settings, name)
+	return v.store.Set(iter, []int{hiddenSettingsColumnValue, hiddenSettingsColumnWeight},
+		[]interface{}{getSettingsValueText(v.settings, key),
+			hiddenSettingsRowWeight(isSettingsValueModified(v.settings, key.Name))})
+}
+
+// setValue validate and save value of settings key in the list row.
+func (v *HiddenSettings) setValue(iter *gtk.TreeIter, text string) error {
+	name, err := v.getKeyName(iter)
+	if err != nil {
+		return err
+	}
+	key := getSettingsKey(v.settings, name)
+	err = setSettingsValueText(v.settings, key, text)
+	if err != nil {
+		v.status.SetText(locale.T(MsgHiddenSettingsValueError,
+			struct {
+				Key   string
+				Error error
+			}{Key: key.Name, Error: err}))
+		return nil
+	}
+	v.status.SetText("")
+	return v.update(iter)
+}
+
+// reset restore default value of settings key in the list row.
+func (v *HiddenSettings) reset(iter *gtk.TreeIter) error {
+	name, err := v.getKeyName(iter)
+	if err != nil {
+		return err
+	}
+	if !isHiddenSettingsKeyEditable(name) {
+		return nil
+	}
+	v.settings.Reset(name)
+	v.status.SetText("")
+	return v.update(iter)
+}
+
+// HiddenPreferencesNew create preference page to view and edit
+// all keys of application settings schema, including ones
+// not exposed in other preference pages.
+func HiddenPreferencesNew(appSettings *SettingsStore, prefRow *PreferenceRow) (*gtk.Container, error) {
+	box, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 6)
+	if err != nil {
+		return nil, err
+	}
+	SetAllMargins(box, 18)
+
+	if prefRow != nil {
+		prefRow.Page = &box.Container
+	}
+
+	lbl, err := SetupLabelJustifyLeft(locale.T(MsgHiddenSettingsWarning, nil))
+	if err != nil {
+		return nil, err
+	}
+	lbl.SetLineWrap(true)
+	box.PackStart(lbl, false, false, 0)
+
+	edFilter, err := gtk.SearchEntryNew()
+	if err != nil {
+		return nil, err
+	}
+	edFilter.SetPlaceholderText(locale.T(MsgHiddenSettingsFilterPlaceholder, nil))
+	edFilter.SetHExpand(true)
+	box.PackStart(edFilter, false, false, 0)
+
+	store, err := gtk.ListStoreNew(glib.TYPE_STRING, glib.TYPE_STRING, glib.TYPE_STRING,
+		glib.TYPE_STRING, glib.TYPE_INT, glib.TYPE_BOOLEAN)
+	if err != nil {
+		return nil, err
+	}
+	tv, err := gtk.TreeViewNewWithModel(store)
+	if err != nil {
+		return nil, err
+	}
+	tv.SetTooltipColumn(hiddenSettingsColumnSummary)
+
+	columns := []struct {
+		title    string
+		columnID int
+	}{
+		{locale.T(MsgHiddenSettingsKeyColumn, nil), hiddenSettingsColumnName},
+		{locale.T(MsgHiddenSettingsTypeColumn, nil), hiddenSettingsColumnType},
+		{locale.T(MsgHiddenSettingsValueColumn, nil), hiddenSettingsColumnValue},
+	}
+	var valueCell *gtk.CellRendererText
+	for _, item := range columns {
+		cell, err := gtk.CellRendererTextNew()
+		if err != nil {
+			return nil, err
+		}
+		col, err := gtk.TreeViewColumnNewWithAttribute(item.title, cell, "text", item.columnID)
+		if err != nil {
+			return nil, err
+		}
+		// Highlight values changed by user.
+		col.AddAttribute(cell, "weight", hiddenSettingsColumnWeight)
+		if item.columnID == hiddenSettingsColumnValue {
+			// Value might be edited in place.
+			col.AddAttribute(cell, "editable", hiddenSettingsColumnEditable)
+			valueCell = cell
+		}
+		col.SetResizable(true)
+		col.SetSortColumnID(item.columnID)
+		tv.AppendColumn(col)
+	}
+
+	sw, err := gtk.ScrolledWindowNew(nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	sw.SetVExpand(true)
+	sw.Add(tv)
+	box.PackStart(sw, true, true, 0)
+
+	box2, err := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 6)
+	if err != nil {
+		return nil, err
+	}
+	status, err := gtk.LabelNew("")
+	if err != nil {
+		return nil, err
+	}
+	status.SetHAlign(gtk.ALIGN_START)
+	status.SetHExpand(true)
+	status.SetLineWrap(true)
+	box2.PackStart(status, true, true, 0)
+	btnReset, err := gtk.ButtonNewWithLabel(locale.T(MsgHiddenSettingsResetButton, nil))
+	if err != nil {
+		return nil, err
+	}
+	btnReset.SetTooltipText(locale.T(MsgHiddenSettingsResetHint, nil))
+	btnReset.SetSensitive(false)
+	box2.PackEnd(btnReset, false, false, 0)
+	box.PackStart(box2, false, false, 0)
+
+	schema, err := appSettings.GetSchema()
+	if err != nil {
+		return nil, err
+	}
+	keys := schema.ListKeys()
+	sort.Strings(keys)
+	hidden := &HiddenSettings{settings: appSettings.settings, keys: keys,
+		store: store, status: status}
+
+	load := func() {
+		text, err := edFilter.GetText()
+		if err != nil {
+			reportError(box, err)
+			return
+		}
+		err = hidden.load(text)
+		if err != nil {
+			reportError(box, err)
+			return
+		}
+	}
+
+	_, err = edFilter.Connect("search-changed", load)
+	if err != nil {
+		return nil, err
+	}
+	// Values might be changed in other preference pages,
+	// so refresh the list each time page is shown.
+	_, err = box.Connect("map", load)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = valueCell.Connect("edited", func(cell *gtk.CellRendererText, path string, text string) {
+		iter, err := store.GetIterFromString(path)
+		if err != nil {
+			reportError(box, err)
+			return
+		}
+		err = hidden.setValue(iter, text)
+		if err != nil {
+			reportError(box, err)
+			return
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	selection, err := tv.GetSelection()
+	if err != nil {
+		return nil, err
+	}
+	_, err = selection.Connect("changed", func() {
+		_, iter, ok := selection.GetSelected()
+		if !ok {
+			btnReset.SetSensitive(false)
+			return
+		}
+		name, err := hidden.getKeyName(iter)
+		if err != nil {
+			reportError(box, err)
+			return
+		}
+		btnReset.SetSensitive(isHiddenSettingsKeyEditable(name))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = btnReset.Connect("clicked", func() {
+		_, iter, ok := selection.GetSelected()
+		if !ok {
+			return
+		}
+		err := hidden.reset(iter)
+		if err != nil {
+			reportError(box, err)
+			return
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &box.Container, nil
+}
@@ -0,0 +1,97 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package gtkui
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/d2r2/go-rsync/backup"
+	"github.com/d2r2/go-rsync/locale"
+	"github.com/d2r2/go-rsync/rsync"
+	"github.com/d2r2/gotk3/gtk"
+)
+
+// updateSSHHostKeyVerification enable or disable strict SSH host
+// key verification for RSYNC calls according to preferences.
+func updateSSHHostKeyVerification(appSettings *SettingsStore) {
+	var path string
+	if appSettings.settings.GetBoolean(CFG_VERIFY_SSH_HOST_KEYS) {
+		var err error
+		path, err = rsync.GetKnownHostsPath()
+		if err != nil {
+			lg.Warn(err)
+		}
+	}
+	rsync.SetKnownHostsPath(path)
+}
+
+// verifySSHHostKeys find SSH hosts of modules, which keys are not trusted
+// yet, and ask user to trust each of them, showing key fingerprints.
+// Return false, if user rejected any host or host keys can't be obtained.
+func verifySSHHostKeys(win *gtk.ApplicationWindow, modules []backup.Module) (bool, error) {
+	appSettings, err := NewSettingsStore(SETTINGS_SCHEMA_ID, SETTINGS_SCHEMA_PATH, nil)
+	if err != nil {
+		return false, err
+	}
+	if !appSettings.settings.GetBoolean(CFG_VERIFY_SSH_HOST_KEYS) {
+		return true, nil
+	}
+	checked := make(map[string]bool)
+	for _, module := range modules {
+		host, _, ok := rsync.SplitRemoteShellPath(module.SourceRsync)
+		if !ok || checked[host] {
+			continue
+		}
+		checked[host] = true
+		known, err := rsync.IsHostKnown(host)
+		if err != nil {
+			return false, err
+		}
+		if known {
+			continue
+		}
+		keys, err := rsync.ScanHostKeys(host)
+		if err != nil {
+			title := locale.T(MsgAppWindowHostKeyScanDlgTitle, nil)
+			titleMarkup := NewMarkup(MARKUP_SIZE_LARGER, 0, 0, nil, nil,
+				NewMarkup(MARKUP_SIZE_LARGER, 0, 0, title, nil))
+			err = ErrorMessage(&win.Window, titleMarkup.String(),
+				[]*DialogParagraph{NewDialogParagraph(err.Error())})
+			return false, err
+		}
+		var buf bytes.Buffer
+		for _, key := range keys {
+			buf.WriteString(fmt.Sprintf("\n%s %s", key.KeyType, key.Fingerprint()))
+		}
+		title := locale.T(MsgAppWindowHostKeyDlgTitle, nil)
+		titleMarkup := NewMarkup(MARKUP_SIZE_LARGER, 0, 0, nil, nil,
+			NewMarkup(MARKUP_SIZE_LARGER, 0, 0, title, nil))
+		textMarkup := locale.T(MsgAppWindowHostKeyDlgText,
+			struct{ Host, Fingerprints string }{
+				Host:         NewMarkup(MARKUP_WEIGHT_BOLD, 0, 0, host, nil).String(),
+				Fingerprints: NewMarkup(0, 0, 0, buf.String(), nil).String()})
+		responseYes, err := questionDialog(&win.Window, titleMarkup.String(),
+			textMarkup, true, false, true)
+		if err != nil {
+			return false, err
+		}
+		if !responseYes {
+			return false, nil
+		}
+		err = rsync.TrustHostKeys(keys)
+		if err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,300 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package gtkui
+
+import (
+	"github.com/d2r2/go-rsync/backup"
+	"github.com/d2r2/go-rsync/core"
+	"github.com/d2r2/go-rsync/locale"
+	"github.com/d2r2/gotk3/glib"
+	"github.com/d2r2/gotk3/gtk"
+)
+
+// Columns of skipped folders list.
+const (
+	skippedColumnSource = iota
+	skippedColumnFolder
+	skippedColumnSize
+	skippedColumnState
+	skippedColumnPath
+)
+
+// CreateIgnoreSignatureWindow build window to list folders marked to skip
+// in backup plan and to create or delete "skip backup" signature files
+// in local and SSH sources. Once anything changed, plan might be
+// re-estimated with reestimate call.
+func CreateIgnoreSignatureWindow(mainWin *gtk.ApplicationWindow, plan *backup.Plan,
+	reestimate func()) (*gtk.ApplicationWindow, error) {
+
+	app, err := mainWin.GetApplication()
+	if err != nil {
+		return nil, err
+	}
+	win, err := gtk.ApplicationWindowNew(app)
+	if err != nil {
+		return nil, err
+	}
+	win.SetTransientFor(mainWin)
+	win.SetDestroyWithParent(false)
+	win.SetShowMenubar(false)
+	win.SetDefaultSize(800, 400)
+
+	sigFileName := plan.Config.SigFileIgnoreBackup
+	hdr, err := SetupHeader(locale.T(MsgIgnoreSignatureWindowCaption, nil),
+		locale.T(MsgIgnoreSignatureWindowSubcaption,
+			struct{ FileName string }{FileName: sigFileName}), true)
+	if err != nil {
+		return nil, err
+	}
+	win.SetTitlebar(hdr)
+
+	box, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 6)
+	if err != nil {
+		return nil, err
+	}
+	SetAllMargins(box, 9)
+
+	store, err := gtk.ListStoreNew(glib.TYPE_STRING, glib.TYPE_STRING, glib.TYPE_STRING,
+		glib.TYPE_STRING, glib.TYPE_STRING)
+	if err != nil {
+		return nil, err
+	}
+	skipped := locale.T(MsgIgnoreSignatureStateSkipped, nil)
+	for _, item := range backup.GetSkippedFolders(plan) {
+		_, err = AppendValues(store, item.SourceRsync, item.RelativePath,
+			core.GetReadableSize(item.Size), skipped, item.Path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tv, err := gtk.TreeViewNewWithModel(store)
+	if err != nil {
+		return nil, err
+	}
+	columns := []struct {
+		title    string
+		columnID int
+	}{
+		{locale.T(MsgIgnoreSignatureSourceColumn, nil), skippedColumnSource},
+		{locale.T(MsgIgnoreSignatureFolderColumn, nil), skippedColumnFolder},
+		{locale.T(MsgIgnoreSignatureSizeColumn, nil), skippedColumnSize},
+		{locale.T(MsgIgnoreSignatureStateColumn, nil), skippedColumnState},
+	}
+	for _, item := range columns {
+		err = appendTextColumn(tv, item.title, item.columnID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sw, err := gtk.ScrolledWindowNew(nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	sw.SetVExpand(true)
+	sw.Add(tv)
+	box.PackStart(sw, true, true, 0)
+
+	grid, err := gtk.GridNew()
+	if err != nil {
+		return nil, err
+	}
+	grid.SetColumnSpacing(6)
+	grid.SetRowSpacing(6)
+	box.PackStart(grid, false, false, 0)
+
+	edFolder, err := gtk.EntryNew()
+	if err != nil {
+		return nil, err
+	}
+	edFolder.SetPlaceholderText(locale.T(MsgIgnoreSignatureFolderPlaceholder, nil))
+	edFolder.SetTooltipText(locale.T(MsgIgnoreSignatureFolderHint, nil))
+	edFolder.SetHExpand(true)
+	grid.Attach(edFolder, 0, 0, 1, 1)
+
+	btnAdd, err := gtk.ButtonNewWithLabel(locale.T(MsgIgnoreSignatureAddButton, nil))
+	if err != nil {
+		return nil, err
+	}
+	btnAdd.SetSensitive(false)
+	grid.Attach(btnAdd, 1, 0, 1, 1)
+
+	btnRemove, err := gtk.ButtonNewWithLabel(locale.T(MsgIgnoreSignatureRemoveButton, nil))
+	if err != nil {
+		return nil, err
+	}
+	btnRemove.SetTooltipText(locale.T(MsgIgnoreSignatureRemoveHint, nil))
+	btnRemove.SetSensitive(false)
+	grid.Attach(btnRemove, 2, 0, 1, 1)
+
+	status, err := gtk.LabelNew("")
+	if err != nil {
+		return nil, err
+	}
+	status.SetHAlign(gtk.ALIGN_START)
+	status.SetHExpand(true)
+	grid.Attach(status, 0, 1, 2, 1)
+
+	btnReestimate, err := gtk.ButtonNewWithLabel(locale.T(MsgIgnoreSignatureReestimateButton, nil))
+	if err != nil {
+		return nil, err
+	}
+	btnReestimate.SetTooltipText(locale.T(MsgIgnoreSignatureReestimateHint, nil))
+	btnReestimate.SetSensitive(false)
+	grid.Attach(btnReestimate, 2, 1, 1, 1)
+
+	// setSignature create or delete signature file and report result.
+	setSignature := func(path string, skip bool) bool {
+		err := backup.SetIgnoreSignature(path, sigFileName, skip)
+		if err != nil {
+			status.SetText(err.Error())
+			return false
+		}
+		if skip {
+			status.SetText(locale.T(MsgIgnoreSignatureAdded, struct{ Path string }{Path: path}))
+		} else {
+			status.SetText(locale.T(MsgIgnoreSignatureRemoved, struct{ Path string }{Path: path}))
+		}
+		btnReestimate.SetSensitive(true)
+		return true
+	}
+
+	selection, err := tv.GetSelection()
+	if err != nil {
+		return nil, err
+	}
+	// getSelected return selected folder path and its state.
+	getSelected := func() (*gtk.TreeIter, string, string) {
+		_, iter, ok := selection.GetSelected()
+		if !ok {
+			return nil, "", ""
+		}
+		var values []string
+		for _, column := range []int{skippedColumnPath, skippedColumnState} {
+			val, err := store.GetValue(iter, column)
+			if err != nil {
+				lg.Fatal(err)
+			}
+			str, err := val.GetString()
+			if err != nil {
+				lg.Fatal(err)
+			}
+			values = append(values, str)
+		}
+		return iter, values[0], values[1]
+	}
+
+	_, err = selection.Connect("changed", func() {
+		_, path, state := getSelected()
+		btnRemove.SetSensitive(path != "" && state == skipped &&
+			backup.CanManageIgnoreSignature(path))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = btnRemove.Connect("clicked", func() {
+		iter, path, _ := getSelected()
+		if iter == nil || !setSignature(path, false) {
+			return
+		}
+		err := store.SetValue(iter, skippedColumnState,
+			locale.T(MsgIgnoreSignatureStateRemoved, nil))
+		if err != nil {
+			lg.Fatal(err)
+		}
+		btnRemove.SetSensitive(false)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = edFolder.Connect("changed", func() {
+		path, err := edFolder.GetText()
+		if err != nil {
+			lg.Fatal(err)
+		}
+		btnAdd.SetSensitive(backup.CanManageIgnoreSignature(path))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = btnAdd.Connect("clicked", func() {
+		path, err := edFolder.GetText()
+		if err != nil {
+			lg.Fatal(err)
+		}
+		if setSignature(path, true) {
+			edFolder.SetText("")
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = btnReestimate.Connect("clicked", func() {
+		reestimate()
+		win.Destroy()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	win.Add(box)
+
+	return win, nil
+}
+
+// createIgnoreSignatureAction creates action to open window, which list
+// folders marked to skip in backup plan of selected profile.
+func createIgnoreSignatureAction(mainWin *gtk.ApplicationWindow, plan **backup.Plan,
+	profile *gtk.ComboBox) (glib.IAction, error) {
+
+	act, err := glib.SimpleActionNew("IgnoreSignatureAction", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	act.SetEnabled(false)
+	_, err = act.Connect("activate", func(action *glib.SimpleAction, param *glib.Variant) {
+		name, state, err := GetActionNameAndState(action)
+		if err != nil {
+			lg.Fatal(err)
+		}
+		lg.Debugf("%v action activated with current state %v and args %v",
+			name, state, param)
+
+		if *plan == nil {
+			return
+		}
+		// Run plan stage of selected profile again.
+		reestimate := func() {
+			_, err := profile.Emit("changed")
+			if err != nil {
+				lg.Fatal(err)
+			}
+		}
+		win, err := CreateIgnoreSignatureWindow(mainWin, *plan, reestimate)
+		if err != nil {
+			lg.Fatal(err)
+		}
+
+		win.ShowAll()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return act, nil
+}
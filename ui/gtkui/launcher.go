@@ -0,0 +1,144 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package gtkui
+
+// #cgo pkg-config: gtk+-3.0
+// #include <stdlib.h>
+// #include <gtk/gtk.h>
+//
+// // Emit signal of Unity LauncherEntry API, which is supported by
+// // Unity, Plank, Dash to Dock, KDE task manager and others.
+// static void _launcher_entry_update(const gchar *object_path, const gchar *app_uri,
+//         gdouble progress, gboolean progress_visible, gboolean urgent) {
+//     GApplication *app = g_application_get_default();
+//     if (app == NULL)
+//         return;
+//     GDBusConnection *conn = g_application_get_dbus_connection(app);
+//     if (conn == NULL)
+//         return;
+//     GVariantBuilder props;
+//     g_variant_builder_init(&props, G_VARIANT_TYPE("a{sv}"));
+//     g_variant_builder_add(&props, "{sv}", "progress", g_variant_new_double(progress));
+//     g_variant_builder_add(&props, "{sv}", "progress-visible", g_variant_new_boolean(progress_visible));
+//     g_variant_builder_add(&props, "{sv}", "urgent", g_variant_new_boolean(urgent));
+//     g_dbus_connection_emit_signal(conn, NULL, object_path,
+//         "com.canonical.Unity.LauncherEntry", "Update",
+//         g_variant_new("(sa{sv})", app_uri, &props), NULL);
+// }
+//
+// // Set window progress hint of XApp API, which is supported by
+// // Cinnamon, MATE and Xfce window lists. No-op out of X11.
+// static void _xapp_set_window_progress(GtkWidget *widget, gint progress, gboolean visible) {
+//     GdkWindow *window = gtk_widget_get_window(widget);
+//     if (window == NULL)
+//         return;
+//     GdkAtom atom = gdk_atom_intern_static_string("_NET_WM_XAPP_PROGRESS");
+//     if (visible) {
+//         gulong value = progress;
+//         gdk_property_change(window, atom, gdk_atom_intern_static_string("CARDINAL"),
+//             32, GDK_PROP_MODE_REPLACE, (const guchar *)&value, 1);
+//     } else {
+//         gdk_property_delete(window, atom);
+//     }
+// }
+import "C"
+import (
+	"sync"
+	"unsafe"
+
+	"github.com/d2r2/gotk3/gtk"
+)
+
+const (
+	// Desktop entry, which launcher entry progress is displayed on.
+	LAUNCHER_ENTRY_APP_URI = "application://gorsync.desktop"
+	// D-Bus object path, LauncherEntry signals are emitted from.
+	LAUNCHER_ENTRY_OBJECT_PATH = "/org/d2r2/gorsync/LauncherEntry"
+)
+
+// LauncherEntry export backup session progress to desktop shell,
+// so it's displayed on dock/launcher icon and in window list,
+// while main window is hidden or not focused. Urgent flag is
+// raised on backup failure until main window get focus.
+type LauncherEntry struct {
+	sync.Mutex
+	win      *gtk.ApplicationWindow
+	progress float32
+	visible  bool
+	urgent   bool
+}
+
+// Global object to export progress, initialized with main window.
+var launcherEntry *LauncherEntry
+
+// NewLauncherEntry create launcher entry bound to main window.
+func NewLauncherEntry(win *gtk.ApplicationWindow) (*LauncherEntry, error) {
+	v := &LauncherEntry{win: win}
+	_, err := win.Connect("focus-in-event", func() {
+		v.SetUrgent(false)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// SetProgress display backup session progress.
+// Should be called from GTK+ main loop.
+func (v *LauncherEntry) SetProgress(progress *float32) {
+	v.Lock()
+	if progress != nil {
+		v.progress = *progress
+	}
+	v.visible = true
+	v.Unlock()
+	v.update()
+}
+
+// SetIdle hide progress once backup session is over.
+// Should be called from GTK+ main loop.
+func (v *LauncherEntry) SetIdle() {
+	v.Lock()
+	v.progress = 0
+	v.visible = false
+	v.Unlock()
+	v.update()
+}
+
+// SetUrgent raise or clear attention flag.
+// Should be called from GTK+ main loop.
+func (v *LauncherEntry) SetUrgent(urgent bool) {
+	v.Lock()
+	changed := v.urgent != urgent
+	v.urgent = urgent
+	v.Unlock()
+	if changed {
+		v.win.SetUrgencyHint(urgent)
+		v.update()
+	}
+}
+
+func (v *LauncherEntry) update() {
+	v.Lock()
+	progress, visible, urgent := v.progress, v.visible, v.urgent
+	v.Unlock()
+
+	cstr := C.CString(LAUNCHER_ENTRY_OBJECT_PATH)
+	defer C.free(unsafe.Pointer(cstr))
+	cstr2 := C.CString(LAUNCHER_ENTRY_APP_URI)
+	defer C.free(unsafe.Pointer(cstr2))
+	C._launcher_entry_update(cstr, cstr2, C.gdouble(progress),
+		gboolean(visible), gboolean(urgent))
+
+	widget := (*C.GtkWidget)(unsafe.Pointer(v.win.Native()))
+	C._xapp_set_window_progress(widget, C.gint(progress*100), gboolean(visible))
+}
@@ -0,0 +1,148 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package gtkui
+
+import (
+	"strings"
+	"time"
+)
+
+// Delay to accumulate series of settings changes (typing
+// in preferences for instance) before applying them.
+const settingsWatcherDelayMs = 1000
+
+// Delay to try again apply settings changes postponed,
+// while backup session is running.
+const settingsWatcherRetryMs = 5000
+
+// SettingsWatcher track GSettings changes made either in preference
+// dialog, or outside of application (gsettings, dconf-editor),
+// and apply them to running application without restart:
+// UI language, profile list and backup configuration.
+// All methods should be called from GTK+ main loop.
+type SettingsWatcher struct {
+	appSettings *SettingsStore
+	// Keep profiles and backup sources settings referenced
+	// to receive their change notifications.
+	children    []*SettingsStore
+	childrenKey string
+	lang        string
+	timer       *time.Timer
+	// Handler return false, if changes can't be applied
+	// right now, so they are postponed.
+	languageHandler func(lang string) bool
+	profilesHandler func() bool
+}
+
+// NewSettingsWatcher create SettingsWatcher, tracking application
+// settings with all profiles and backup sources.
+func NewSettingsWatcher() (*SettingsWatcher, error) {
+	v := &SettingsWatcher{}
+	v.timer = time.AfterFunc(time.Millisecond*settingsWatcherDelayMs, func() {
+		MustIdleAdd(func() {
+			err := v.apply()
+			if err != nil {
+				lg.Fatal(err)
+			}
+		})
+	})
+	v.timer.Stop()
+	appSettings, err := NewSettingsStore(SETTINGS_SCHEMA_ID, SETTINGS_SCHEMA_PATH, v.changed)
+	if err != nil {
+		return nil, err
+	}
+	v.appSettings = appSettings
+	v.lang = appSettings.settings.GetString(CFG_UI_LANGUAGE)
+	err = v.subscribe()
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// SetLanguageHandler assign function to switch UI language.
+func (v *SettingsWatcher) SetLanguageHandler(handler func(lang string) bool) {
+	v.languageHandler = handler
+}
+
+// SetProfilesHandler assign function to reload profile list and
+// backup configuration. Handler is replaced, once main window recreated.
+func (v *SettingsWatcher) SetProfilesHandler(handler func() bool) {
+	v.profilesHandler = handler
+}
+
+func (v *SettingsWatcher) changed() {
+	RestartTimer(v.timer, settingsWatcherDelayMs)
+}
+
+// subscribe track changes of profiles and backup sources settings.
+// Subscription is renewed only when profile or backup source added or removed.
+func (v *SettingsWatcher) subscribe() error {
+	var key []string
+	profileIDs := v.appSettings.NewSettingsArray(CFG_BACKUP_LIST).GetArrayIDs()
+	profiles := make([]*SettingsStore, 0, len(profileIDs))
+	for _, profileID := range profileIDs {
+		profileSettings, err := getProfileSettings(v.appSettings, profileID, nil)
+		if err != nil {
+			return err
+		}
+		profiles = append(profiles, profileSettings)
+		sourceIDs := profileSettings.NewSettingsArray(CFG_SOURCE_LIST).GetArrayIDs()
+		key = append(key, profileID+":"+strings.Join(sourceIDs, ","))
+	}
+	childrenKey := strings.Join(key, ";")
+	if childrenKey == v.childrenKey && v.children != nil {
+		return nil
+	}
+
+	var children []*SettingsStore
+	for i, profileID := range profileIDs {
+		profileSettings, err := getProfileSettings(v.appSettings, profileID, v.changed)
+		if err != nil {
+			return err
+		}
+		children = append(children, profileSettings)
+		for _, sourceID := range profiles[i].NewSettingsArray(CFG_SOURCE_LIST).GetArrayIDs() {
+			sourceSettings, err := getBackupSourceSettings(profileSettings, sourceID, v.changed)
+			if err != nil {
+				return err
+			}
+			children = append(children, sourceSettings)
+		}
+	}
+	v.children = children
+	v.childrenKey = childrenKey
+	return nil
+}
+
+// apply run handlers to reflect settings changes in application.
+func (v *SettingsWatcher) apply() error {
+	err := v.subscribe()
+	if err != nil {
+		return err
+	}
+	lang := v.appSettings.settings.GetString(CFG_UI_LANGUAGE)
+	if lang != v.lang && v.languageHandler != nil {
+		if !v.languageHandler(lang) {
+			RestartTimer(v.timer, settingsWatcherRetryMs)
+			return nil
+		}
+		v.lang = lang
+		// Main window is recreated, so profile list
+		// and backup configuration are up to date.
+		return nil
+	}
+	if v.profilesHandler != nil && !v.profilesHandler() {
+		RestartTimer(v.timer, settingsWatcherRetryMs)
+	}
+	return nil
+}
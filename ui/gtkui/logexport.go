@@ -0,0 +1,192 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package gtkui
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/d2r2/go-rsync/core"
+	"github.com/d2r2/go-rsync/locale"
+	"github.com/d2r2/gotk3/gdk"
+	"github.com/d2r2/gotk3/gtk"
+	"github.com/davecgh/go-spew/spew"
+)
+
+// ANSI escape sequences, which reproduce session log
+// colors of TextView tags in terminal.
+var logTagAnsiColors = map[string]string{
+	"BlueColor":      "\x1b[94m",
+	"YellowColor":    "\x1b[33m",
+	"OrangeRedColor": "\x1b[91m",
+	"RedColor":       "\x1b[31m",
+}
+
+const ansiColorReset = "\x1b[0m"
+
+// formatSessionLog prepare session log text to export:
+// colors are either stripped, or kept as ANSI escape sequences.
+func formatSessionLog(text string, colored bool) string {
+	if !colored {
+		return text
+	}
+	var buf bytes.Buffer
+	for _, line := range strings.SplitAfter(text, "\n") {
+		m := core.FindStringSubmatchIndexes(logLineEventsRegex, line)
+		a, ok := m["Event"]
+		if !ok {
+			buf.WriteString(line)
+			continue
+		}
+		value := line[a[0]:a[1]]
+		buf.WriteString(line[:a[0]])
+		for _, event := range logLineEvents {
+			if value == lToU(event.Level) {
+				buf.WriteString(logTagAnsiColors[event.TagName])
+				break
+			}
+		}
+		buf.WriteString(value)
+		buf.WriteString(ansiColorReset)
+		buf.WriteString(line[a[1]:])
+	}
+	return buf.String()
+}
+
+// LogExportButtons provide controls to copy session log
+// to clipboard and save it to file.
+type LogExportButtons struct {
+	box         *gtk.Box
+	parent      *gtk.Window
+	buffer      *gtk.TextBuffer
+	profileName string
+	cbColors    *gtk.CheckButton
+}
+
+// NewLogExportButtons create controls to export session log kept in buffer.
+func NewLogExportButtons(parent *gtk.Window, buffer *gtk.TextBuffer,
+	profileName string) (*LogExportButtons, error) {
+
+	v := &LogExportButtons{parent: parent, buffer: buffer, profileName: profileName}
+	var err error
+	v.box, err = gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 0)
+	if err != nil {
+		return nil, err
+	}
+	style, err := v.box.GetStyleContext()
+	if err != nil {
+		return nil, err
+	}
+	style.AddClass("linked")
+
+	btnCopy, err := SetupButtonWithThemedImage("edit-copy-symbolic")
+	if err != nil {
+		return nil, err
+	}
+	btnCopy.SetTooltipText(locale.T(MsgAppWindowLogCopyHint, nil))
+	_, err = btnCopy.Connect("clicked", func() {
+		err := v.copy()
+		if err != nil {
+			lg.Fatal(err)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	v.box.PackStart(btnCopy, false, false, 0)
+
+	btnSave, err := SetupButtonWithThemedImage("document-save-as-symbolic")
+	if err != nil {
+		return nil, err
+	}
+	btnSave.SetTooltipText(locale.T(MsgAppWindowLogSaveHint, nil))
+	_, err = btnSave.Connect("clicked", func() {
+		err := v.save()
+		if err != nil {
+			lg.Fatal(err)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	v.box.PackStart(btnSave, false, false, 0)
+
+	v.cbColors, err = gtk.CheckButtonNewWithLabel(locale.T(MsgAppWindowLogKeepColorsCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	v.cbColors.SetTooltipText(locale.T(MsgAppWindowLogKeepColorsHint, nil))
+	SetMargins(v.cbColors, 6, 0, 0, 0)
+	v.box.PackStart(v.cbColors, false, false, 0)
+
+	return v, nil
+}
+
+// getText return session log text prepared to export.
+// Lines hidden by severity filter are included.
+func (v *LogExportButtons) getText() (string, error) {
+	text, err := v.buffer.GetText(v.buffer.GetStartIter(), v.buffer.GetEndIter(), true)
+	if err != nil {
+		return "", err
+	}
+	return formatSessionLog(text, v.cbColors.GetActive()), nil
+}
+
+// copy put session log to clipboard.
+func (v *LogExportButtons) copy() error {
+	text, err := v.getText()
+	if err != nil {
+		return err
+	}
+	clipboard, err := gtk.ClipboardGet(gdk.SELECTION_CLIPBOARD)
+	if err != nil {
+		return err
+	}
+	clipboard.SetText(text)
+	return nil
+}
+
+// save ask for file name and write session log there.
+func (v *LogExportButtons) save() error {
+	dialog, err := gtk.FileChooserDialogNewWith2Buttons(
+		locale.T(MsgAppWindowLogSaveDlgTitle, nil), v.parent,
+		gtk.FILE_CHOOSER_ACTION_SAVE,
+		"_Cancel", gtk.RESPONSE_CANCEL, "_Save", gtk.RESPONSE_ACCEPT)
+	if err != nil {
+		return err
+	}
+	dialog.SetDoOverwriteConfirmation(true)
+	dialog.SetCurrentName(spew.Sprintf("%s_%s.log", strings.Replace(v.profileName, "/", "_", -1),
+		time.Now().Format("2006-01-02_15-04-05")))
+	response := dialog.Run()
+	path := dialog.GetFilename()
+	dialog.Destroy()
+	if response != gtk.RESPONSE_ACCEPT || path == "" {
+		return nil
+	}
+
+	text, err := v.getText()
+	if err != nil {
+		return err
+	}
+	err = ioutil.WriteFile(path, []byte(text), 0644)
+	if err != nil {
+		title := locale.T(MsgAppWindowLogSaveErrorTitle, nil)
+		titleMarkup := NewMarkup(MARKUP_SIZE_LARGER, 0, 0, nil, nil,
+			NewMarkup(MARKUP_SIZE_LARGER, 0, 0, title, nil))
+		return ErrorMessage(v.parent, titleMarkup.String(),
+			[]*DialogParagraph{NewDialogParagraph(err.Error())})
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package gtkui
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/d2r2/gotk3/gdk"
+	"github.com/d2r2/gotk3/gtk"
+)
+
+// logPathRegex recognize absolute local path quoted in session log line.
+var logPathRegex = regexp.MustCompile(`"(?P<Path>/[^"\n]+)"`)
+
+// logLink keep location of clickable path in TextView buffer.
+type logLink struct {
+	// Character offsets in buffer.
+	start, end int
+	path       string
+}
+
+// LogLinks make local paths quoted in session log lines clickable:
+// click open corresponding folder in file manager.
+type LogLinks struct {
+	parent   *gtk.Window
+	textView *gtk.TextView
+	buffer   *gtk.TextBuffer
+	// Sorted by offset, since lines are added to the end of buffer.
+	links []logLink
+	// Mouse pointer hover link.
+	hover bool
+}
+
+// NewLogLinks connect click and mouse motion handlers
+// to textView, to open paths found in session log.
+func NewLogLinks(parent *gtk.Window, textView *gtk.TextView) (*LogLinks, error) {
+	buffer, err := textView.GetBuffer()
+	if err != nil {
+		return nil, err
+	}
+	v := &LogLinks{parent: parent, textView: textView, buffer: buffer}
+
+	_, err = textView.Connect("button-release-event", func(tv *gtk.TextView, event *gdk.Event) bool {
+		btn := gdk.EventButtonNewFromEvent(event)
+		// don't interfere with text selection
+		if btn.Button() != gdk.BUTTON_PRIMARY || v.buffer.GetHasSelection() {
+			return false
+		}
+		if link := v.findLink(btn.X(), btn.Y()); link != nil {
+			showNearestFolder(v.parent, link.path)
+		}
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = textView.Connect("motion-notify-event", func(tv *gtk.TextView, event *gdk.Event) bool {
+		x, y := gdk.EventMotionNewFromEvent(event).MotionVal()
+		hover := v.findLink(x, y) != nil
+		if hover != v.hover {
+			v.hover = hover
+			err := v.setPointer(hover)
+			if err != nil {
+				lg.Warn(err)
+			}
+		}
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// AddLine find paths in text chunk added to the buffer
+// at character offset and underline them.
+func (v *LogLinks) AddLine(offset int, line string) {
+	for _, m := range logPathRegex.FindAllStringSubmatchIndex(line, -1) {
+		start := offset + getRuneIndex(line, m[2])
+		end := offset + getRuneIndex(line, m[3])
+		v.links = append(v.links, logLink{start: start, end: end, path: line[m[2]:m[3]]})
+		v.buffer.ApplyTagByName("Path", v.buffer.GetIterAtOffset(start),
+			v.buffer.GetIterAtOffset(end))
+	}
+}
+
+// Clear forget links, once buffer content replaced.
+func (v *LogLinks) Clear() {
+	v.links = nil
+}
+
+// Trim forget links located before character offset, once
+// the beginning of the buffer is removed, and shift the rest.
+func (v *LogLinks) Trim(offset int) {
+	i := 0
+	for i < len(v.links) && v.links[i].start < offset {
+		i++
+	}
+	v.links = v.links[i:]
+	for j := range v.links {
+		v.links[j].start -= offset
+		v.links[j].end -= offset
+	}
+}
+
+// findLink return link located at widget coordinates, if any.
+func (v *LogLinks) findLink(x, y float64) *logLink {
+	bx, by := v.textView.WindowToBufferCoords(gtk.TEXT_WINDOW_WIDGET, int(x), int(y))
+	offset := v.textView.GetIterAtLocation(bx, by).GetOffset()
+	i := sort.Search(len(v.links), func(i int) bool {
+		return v.links[i].end > offset
+	})
+	if i < len(v.links) && v.links[i].start <= offset {
+		return &v.links[i]
+	}
+	return nil
+}
+
+// setPointer show hand pointer over link, default one otherwise.
+func (v *LogLinks) setPointer(hover bool) error {
+	win := v.textView.GetWindow(gtk.TEXT_WINDOW_TEXT)
+	if win == nil {
+		return nil
+	}
+	if !hover {
+		win.SetCursor(nil)
+		return nil
+	}
+	display, err := gdk.DisplayGetDefault()
+	if err != nil {
+		return err
+	}
+	cursor, err := gdk.CursorNewFromName(display, "pointer")
+	if err != nil {
+		return err
+	}
+	win.SetCursor(cursor)
+	return nil
+}
+
+// showNearestFolder show folder in file manager: file path is replaced
+// with containing folder, and path removed since (renamed session folder,
+// for instance) is replaced with the nearest existing parent.
+func showNearestFolder(parent *gtk.Window, path string) {
+	for {
+		stat, err := os.Stat(path)
+		if err == nil && stat.IsDir() {
+			break
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return
+		}
+		path = parent
+	}
+	uri := &url.URL{Scheme: "file", Path: path}
+	err := ShowUri(parent, uri.String())
+	if err != nil {
+		lg.Warn(err)
+	}
+}
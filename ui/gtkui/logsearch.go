@@ -0,0 +1,388 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package gtkui
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	logger "github.com/d2r2/go-logger"
+	"github.com/d2r2/go-rsync/core"
+	"github.com/d2r2/go-rsync/locale"
+	"github.com/d2r2/gotk3/gtk"
+)
+
+// Name of TextView tag to hide session log lines filtered out by severity.
+const logSearchFilteredTag = "FilteredOut"
+
+// LogSeverity specify class of session log lines shown by severity filter.
+type LogSeverity int
+
+const (
+	LOG_SEVERITY_NONE LogSeverity = iota
+	LOG_SEVERITY_WARNING
+	LOG_SEVERITY_ERROR
+)
+
+// logSeverityRegex recognize warning and error entries in session log line.
+var logSeverityRegex = getLogEventsRegex([]struct {
+	Level   logger.LogLevel
+	TagName string
+}{
+	{logger.WarnLevel, ""},
+	{logger.ErrorLevel, ""},
+	{logger.FatalLevel, ""},
+	{logger.PanicLevel, ""},
+})
+
+// getLogLineSeverity identify severity of session log line.
+func getLogLineSeverity(line string) LogSeverity {
+	m := core.FindStringSubmatchIndexes(logSeverityRegex, line)
+	if a, ok := m["Event"]; ok {
+		if line[a[0]:a[1]] == lToU(logger.WarnLevel) {
+			return LOG_SEVERITY_WARNING
+		}
+		return LOG_SEVERITY_ERROR
+	}
+	return LOG_SEVERITY_NONE
+}
+
+// logSearchLine keep text chunk added to session log buffer.
+type logSearchLine struct {
+	// Character offset in buffer.
+	offset int
+	// Text in lower case to search case-insensitive.
+	lower    string
+	length   int
+	severity LogSeverity
+}
+
+// LogSearchBar provide search and severity filter for session log:
+// matches are highlighted incrementally, while log lines are added,
+// lines of other severity are hidden, once filter enabled.
+// Lines are kept aside of the buffer, to avoid text copy
+// on each search in session log with tens of thousands lines.
+type LogSearchBar struct {
+	box        *gtk.Box
+	entry      *gtk.Entry
+	btnWarning *gtk.ToggleButton
+	btnError   *gtk.ToggleButton
+	textView   *gtk.TextView
+	viewPort   *gtk.Viewport
+	buffer     *gtk.TextBuffer
+
+	lines []logSearchLine
+	query string
+	// Search query length in characters.
+	queryLen int
+	// Character offsets of search matches found.
+	matches []int
+	current int
+}
+
+// NewLogSearchBar create search controls for session log
+// displayed in textView placed into viewPort.
+func NewLogSearchBar(textView *gtk.TextView, viewPort *gtk.Viewport) (*LogSearchBar, error) {
+	buffer, err := textView.GetBuffer()
+	if err != nil {
+		return nil, err
+	}
+	err = addSearchTags(buffer)
+	if err != nil {
+		return nil, err
+	}
+	v := &LogSearchBar{textView: textView, viewPort: viewPort, buffer: buffer}
+
+	v.box, err = gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 0)
+	if err != nil {
+		return nil, err
+	}
+	style, err := v.box.GetStyleContext()
+	if err != nil {
+		return nil, err
+	}
+	style.AddClass("linked")
+	v.box.SetHAlign(gtk.ALIGN_END)
+
+	v.entry, err = gtk.EntryNew()
+	if err != nil {
+		return nil, err
+	}
+	v.entry.SetIconFromIconName(gtk.ENTRY_ICON_PRIMARY, "edit-find-symbolic")
+	v.entry.SetPlaceholderText(locale.T(MsgLogViewerSearchPlaceholder, nil))
+	v.entry.SetTooltipText(locale.T(MsgAppWindowLogSearchHint, nil))
+	_, err = v.entry.Connect("changed", func(entry *gtk.Entry) {
+		text, err := entry.GetText()
+		if err != nil {
+			lg.Fatal(err)
+		}
+		v.search(text)
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Jump to next search match on Enter.
+	_, err = v.entry.Connect("activate", func(entry *gtk.Entry) {
+		v.move(1)
+	})
+	if err != nil {
+		return nil, err
+	}
+	v.box.PackStart(v.entry, false, false, 0)
+
+	btnPrev, err := SetupButtonWithThemedImage("go-up-symbolic")
+	if err != nil {
+		return nil, err
+	}
+	btnPrev.SetTooltipText(locale.T(MsgAppWindowLogSearchPrevHint, nil))
+	_, err = btnPrev.Connect("clicked", func() {
+		v.move(-1)
+	})
+	if err != nil {
+		return nil, err
+	}
+	v.box.PackStart(btnPrev, false, false, 0)
+
+	btnNext, err := SetupButtonWithThemedImage("go-down-symbolic")
+	if err != nil {
+		return nil, err
+	}
+	btnNext.SetTooltipText(locale.T(MsgAppWindowLogSearchNextHint, nil))
+	_, err = btnNext.Connect("clicked", func() {
+		v.move(1)
+	})
+	if err != nil {
+		return nil, err
+	}
+	v.box.PackStart(btnNext, false, false, 0)
+
+	v.btnWarning, err = gtk.ToggleButtonNewWithLabel(locale.T(MsgAppWindowLogFilterWarningsCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	v.btnWarning.SetTooltipText(locale.T(MsgAppWindowLogFilterWarningsHint, nil))
+	SetMargins(v.btnWarning, 6, 0, 0, 0)
+	_, err = v.btnWarning.Connect("toggled", func() {
+		v.filter()
+	})
+	if err != nil {
+		return nil, err
+	}
+	v.box.PackStart(v.btnWarning, false, false, 0)
+
+	v.btnError, err = gtk.ToggleButtonNewWithLabel(locale.T(MsgAppWindowLogFilterErrorsCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	v.btnError.SetTooltipText(locale.T(MsgAppWindowLogFilterErrorsHint, nil))
+	_, err = v.btnError.Connect("toggled", func() {
+		v.filter()
+	})
+	if err != nil {
+		return nil, err
+	}
+	v.box.PackStart(v.btnError, false, false, 0)
+
+	return v, nil
+}
+
+// addSearchTags add format tags to highlight search
+// matches and hide filtered out lines in TextView control.
+func addSearchTags(buffer *gtk.TextBuffer) error {
+	table, err := buffer.GetTagTable()
+	if err != nil {
+		return err
+	}
+
+	tag, err := gtk.TextTagNew(logViewerSearchTag)
+	if err != nil {
+		return err
+	}
+	err = tag.SetProperty("background", "Yellow")
+	if err != nil {
+		return err
+	}
+	table.Add(tag)
+
+	tag, err = gtk.TextTagNew(logSearchFilteredTag)
+	if err != nil {
+		return err
+	}
+	err = tag.SetProperty("invisible", true)
+	if err != nil {
+		return err
+	}
+	table.Add(tag)
+
+	return nil
+}
+
+// IsSearching return true, if search query is entered, so
+// session log should not scroll to the most recent line.
+func (v *LogSearchBar) IsSearching() bool {
+	return v.query != ""
+}
+
+// isVisible verify line pass severity filter.
+func (v *LogSearchBar) isVisible(line *logSearchLine) bool {
+	warnings := v.btnWarning.GetActive()
+	errors := v.btnError.GetActive()
+	if !warnings && !errors {
+		return true
+	}
+	return (warnings && line.severity == LOG_SEVERITY_WARNING) ||
+		(errors && line.severity == LOG_SEVERITY_ERROR)
+}
+
+// AddLine register text chunk added to the buffer at character
+// offset, to highlight search matches and apply filter to it.
+func (v *LogSearchBar) AddLine(offset int, text string) {
+	line := logSearchLine{offset: offset, lower: strings.ToLower(text),
+		length: utf8.RuneCountInString(text), severity: getLogLineSeverity(text)}
+	v.lines = append(v.lines, line)
+	if !v.isVisible(&line) {
+		v.buffer.ApplyTagByName(logSearchFilteredTag, v.buffer.GetIterAtOffset(line.offset),
+			v.buffer.GetIterAtOffset(line.offset+line.length))
+		return
+	}
+	v.searchLine(&line)
+}
+
+// LineCount return number of text chunks added to the buffer.
+func (v *LogSearchBar) LineCount() int {
+	return len(v.lines)
+}
+
+// LineOffset return character offset of text chunk in the buffer.
+func (v *LogSearchBar) LineOffset(index int) int {
+	return v.lines[index].offset
+}
+
+// Trim forget text chunks and search matches located before character
+// offset, once the beginning of the buffer is removed, and shift the rest.
+func (v *LogSearchBar) Trim(offset int) {
+	i := 0
+	for i < len(v.lines) && v.lines[i].offset < offset {
+		i++
+	}
+	v.lines = v.lines[i:]
+	for i := range v.lines {
+		v.lines[i].offset -= offset
+	}
+	i = 0
+	for i < len(v.matches) && v.matches[i] < offset {
+		i++
+	}
+	v.matches = v.matches[i:]
+	for j := range v.matches {
+		v.matches[j] -= offset
+	}
+	v.current -= i
+	if v.current < 0 {
+		v.current = 0
+	}
+}
+
+// searchLine highlight search matches found in line.
+func (v *LogSearchBar) searchLine(line *logSearchLine) {
+	if v.query == "" {
+		return
+	}
+	// strings.ToLower keep number of characters, so offsets
+	// calculated on lower case text are valid for original.
+	offset, start := line.offset, 0
+	for {
+		i := strings.Index(line.lower[start:], v.query)
+		if i < 0 {
+			break
+		}
+		offset += utf8.RuneCountInString(line.lower[start : start+i])
+		v.matches = append(v.matches, offset)
+		v.buffer.ApplyTagByName(logViewerSearchTag, v.buffer.GetIterAtOffset(offset),
+			v.buffer.GetIterAtOffset(offset+v.queryLen))
+		offset += v.queryLen
+		start += i + len(v.query)
+	}
+}
+
+// search highlight all case-insensitive occurrences of the query
+// in visible lines and scroll to the first one.
+func (v *LogSearchBar) search(query string) {
+	v.buffer.RemoveTagByName(logViewerSearchTag, v.buffer.GetStartIter(), v.buffer.GetEndIter())
+	v.query = strings.ToLower(query)
+	v.queryLen = utf8.RuneCountInString(query)
+	v.matches = nil
+	v.current = 0
+	for i := range v.lines {
+		if v.isVisible(&v.lines[i]) {
+			v.searchLine(&v.lines[i])
+		}
+	}
+	v.showMatch()
+}
+
+// filter hide lines, which don't pass severity filter,
+// and repeat search in visible lines.
+func (v *LogSearchBar) filter() {
+	v.buffer.RemoveTagByName(logSearchFilteredTag, v.buffer.GetStartIter(), v.buffer.GetEndIter())
+	// Hide adjacent lines with single tag application.
+	start, end := -1, -1
+	for i := range v.lines {
+		line := &v.lines[i]
+		if v.isVisible(line) {
+			continue
+		}
+		if line.offset != end {
+			v.hideRange(start, end)
+			start = line.offset
+		}
+		end = line.offset + line.length
+	}
+	v.hideRange(start, end)
+	v.search(v.query)
+}
+
+// hideRange apply tag to hide characters in range, if any.
+func (v *LogSearchBar) hideRange(start, end int) {
+	if start >= 0 && end > start {
+		v.buffer.ApplyTagByName(logSearchFilteredTag, v.buffer.GetIterAtOffset(start),
+			v.buffer.GetIterAtOffset(end))
+	}
+}
+
+// move go to next (step > 0) or previous (step < 0) search match, cyclically.
+func (v *LogSearchBar) move(step int) {
+	if len(v.matches) > 0 {
+		v.current = (v.current + step + len(v.matches)) % len(v.matches)
+		v.showMatch()
+	}
+}
+
+// showMatch select and scroll to current search match.
+func (v *LogSearchBar) showMatch() {
+	if v.current < len(v.matches) {
+		p1 := v.buffer.GetIterAtOffset(v.matches[v.current])
+		p2 := v.buffer.GetIterAtOffset(v.matches[v.current] + v.queryLen)
+		v.buffer.SelectRange(p1, p2)
+		// TextView placed in viewport has full height,
+		// so scroll viewport instead of TextView.
+		rect := v.textView.GetIterLocation(p1)
+		adj, err := v.viewPort.GetVAdjustment()
+		if err != nil {
+			lg.Fatal(err)
+		}
+		if y := float64(rect.GetY()); y < adj.GetValue() ||
+			y+float64(rect.GetHeight()) > adj.GetValue()+adj.GetPageSize() {
+			adj.SetValue(y - adj.GetPageSize()/3)
+		}
+	}
+}
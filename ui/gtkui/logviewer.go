@@ -0,0 +1,327 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package gtkui
+
+import (
+	"io/ioutil"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/d2r2/go-rsync/backup"
+	"github.com/d2r2/go-rsync/core"
+	"github.com/d2r2/go-rsync/locale"
+	"github.com/d2r2/gotk3/glib"
+	"github.com/d2r2/gotk3/gtk"
+	"github.com/davecgh/go-spew/spew"
+)
+
+// Name of TextView tag to highlight search results.
+const logViewerSearchTag = "SearchMatch"
+
+// LogViewer keep widgets and state of the window, which
+// display session logs saved in local archive.
+type LogViewer struct {
+	textView *gtk.TextView
+	buffer   *gtk.TextBuffer
+	links    *LogLinks
+	// Content of loaded session log.
+	text string
+	// Character offsets of search matches found.
+	matches []int
+	current int
+	// Search query length in characters.
+	queryLen int
+}
+
+// load read session log file and display it colorized
+// the same way as session log in main window.
+func (v *LogViewer) load(path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	v.text = string(b)
+	v.matches = nil
+	v.buffer.SetText("")
+	v.links.Clear()
+	for _, line := range strings.SplitAfter(v.text, "\n") {
+		if line != "" {
+			offset := v.buffer.GetEndIter().GetOffset()
+			addLineToBuffer(v.buffer, line)
+			v.links.AddLine(offset, line)
+		}
+	}
+	return nil
+}
+
+// search highlight all case-insensitive occurrences
+// of the query and scroll to the first one.
+func (v *LogViewer) search(query string) {
+	v.buffer.RemoveTagByName(logViewerSearchTag, v.buffer.GetStartIter(), v.buffer.GetEndIter())
+	v.matches = nil
+	v.current = 0
+	v.queryLen = utf8.RuneCountInString(query)
+	if query == "" {
+		return
+	}
+	// strings.ToLower keep number of characters, so offsets
+	// calculated on lower case text are valid for original.
+	text := strings.ToLower(v.text)
+	query = strings.ToLower(query)
+	offset, start := 0, 0
+	for {
+		i := strings.Index(text[start:], query)
+		if i < 0 {
+			break
+		}
+		offset += utf8.RuneCountInString(text[start : start+i])
+		v.matches = append(v.matches, offset)
+		p1 := v.buffer.GetIterAtOffset(offset)
+		p2 := v.buffer.GetIterAtOffset(offset + v.queryLen)
+		v.buffer.ApplyTagByName(logViewerSearchTag, p1, p2)
+		offset += v.queryLen
+		start += i + len(query)
+	}
+	v.showMatch()
+}
+
+// next move to the following search match, cyclically.
+func (v *LogViewer) next() {
+	if len(v.matches) > 0 {
+		v.current = (v.current + 1) % len(v.matches)
+		v.showMatch()
+	}
+}
+
+// showMatch select and scroll to current search match.
+func (v *LogViewer) showMatch() {
+	if v.current < len(v.matches) {
+		p1 := v.buffer.GetIterAtOffset(v.matches[v.current])
+		p2 := v.buffer.GetIterAtOffset(v.matches[v.current] + v.queryLen)
+		v.buffer.SelectRange(p1, p2)
+		v.textView.ScrollToIter(p1, 0.1, false, 0, 0)
+	}
+}
+
+// CreateLogViewerWindow build window to browse session logs saved in local archive.
+// Window contains session selector, search entry and read-only colorized log view.
+func CreateLogViewerWindow(mainWin *gtk.ApplicationWindow, fontSize string) (*gtk.ApplicationWindow, error) {
+	app, err := mainWin.GetApplication()
+	if err != nil {
+		return nil, err
+	}
+	win, err := gtk.ApplicationWindowNew(app)
+	if err != nil {
+		return nil, err
+	}
+	win.SetTransientFor(mainWin)
+	win.SetDestroyWithParent(false)
+	win.SetShowMenubar(false)
+	win.SetDefaultSize(900, 600)
+
+	hdr, err := SetupHeader(locale.T(MsgLogViewerWindowCaption, nil), "", true)
+	if err != nil {
+		return nil, err
+	}
+	win.SetTitlebar(hdr)
+
+	box, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 6)
+	if err != nil {
+		return nil, err
+	}
+	SetAllMargins(box, 9)
+
+	grid, err := gtk.GridNew()
+	if err != nil {
+		return nil, err
+	}
+	grid.SetColumnSpacing(12)
+	grid.SetRowSpacing(6)
+	box.PackStart(grid, false, false, 0)
+
+	lbl, err := SetupLabelJustifyRight(locale.T(MsgLogViewerSessionCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	grid.Attach(lbl, 0, 0, 1, 1)
+
+	logs, err := backup.ListArchivedLogs()
+	if err != nil {
+		return nil, err
+	}
+	var values []struct{ value, key string }
+	for _, item := range logs {
+		values = append(values, struct{ value, key string }{
+			value: spew.Sprintf("%s (%s)", item.Name,
+				core.FormatSize(uint64(item.Size), true)),
+			key: item.Path})
+	}
+	cbSession, err := CreateNameValueCombo(values)
+	if err != nil {
+		return nil, err
+	}
+	cbSession.SetTooltipText(locale.T(MsgLogViewerSessionHint, nil))
+	cbSession.SetHExpand(true)
+	grid.Attach(cbSession, 1, 0, 1, 1)
+
+	edSearch, err := gtk.EntryNew()
+	if err != nil {
+		return nil, err
+	}
+	edSearch.SetIconFromIconName(gtk.ENTRY_ICON_PRIMARY, "edit-find-symbolic")
+	edSearch.SetPlaceholderText(locale.T(MsgLogViewerSearchPlaceholder, nil))
+	edSearch.SetTooltipText(locale.T(MsgLogViewerSearchHint, nil))
+	grid.Attach(edSearch, 2, 0, 1, 1)
+
+	textView, err := gtk.TextViewNew()
+	if err != nil {
+		return nil, err
+	}
+	buffer, err := textView.GetBuffer()
+	if err != nil {
+		return nil, err
+	}
+	err = addColorTags(buffer)
+	if err != nil {
+		return nil, err
+	}
+	err = addSearchTags(buffer)
+	if err != nil {
+		return nil, err
+	}
+
+	css := `
+textview {
+    font: %s "Monospace";
+}
+	`
+	err = ApplyStyleCSS(&textView.Widget, spew.Sprintf(css, fontSize))
+	if err != nil {
+		return nil, err
+	}
+	textView.SetEditable(false)
+
+	sw, err := gtk.ScrolledWindowNew(nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	sw.SetVExpand(true)
+	sw.Add(textView)
+	box.PackStart(sw, true, true, 0)
+
+	links, err := NewLogLinks(&win.Window, textView)
+	if err != nil {
+		return nil, err
+	}
+	viewer := &LogViewer{textView: textView, buffer: buffer, links: links}
+
+	if len(logs) == 0 {
+		buffer.SetText(locale.T(MsgLogViewerNoLogsFound,
+			struct{ Path string }{Path: getLogArchivePathOrEmpty()}))
+		cbSession.SetSensitive(false)
+		edSearch.SetSensitive(false)
+	}
+
+	_, err = cbSession.Connect("changed", func(cb *gtk.ComboBox) {
+		path := cb.GetActiveID()
+		if path == "" {
+			return
+		}
+		err := viewer.load(path)
+		if err != nil {
+			buffer.SetText(locale.T(MsgLogViewerLoadError,
+				struct {
+					Path  string
+					Error error
+				}{Path: path, Error: err}))
+			return
+		}
+		text, err := edSearch.GetText()
+		if err != nil {
+			lg.Fatal(err)
+		}
+		viewer.search(text)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = edSearch.Connect("changed", func(entry *gtk.Entry) {
+		text, err := entry.GetText()
+		if err != nil {
+			lg.Fatal(err)
+		}
+		viewer.search(text)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Jump to next search match on Enter.
+	_, err = edSearch.Connect("activate", func(entry *gtk.Entry) {
+		viewer.next()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(logs) > 0 {
+		// Show the most recent session log.
+		cbSession.SetActiveID(logs[0].Path)
+	}
+
+	win.Add(box)
+
+	return win, nil
+}
+
+// getLogArchivePathOrEmpty return session logs archive
+// folder, or empty string if can't be identified.
+func getLogArchivePathOrEmpty() string {
+	path, err := backup.GetLogArchivePath()
+	if err != nil {
+		lg.Debug(err)
+		return ""
+	}
+	return path
+}
+
+// createLogViewerAction creates action to open window
+// with session logs saved in local archive.
+func createLogViewerAction(mainWin *gtk.ApplicationWindow, appSettings *SettingsStore) (glib.IAction, error) {
+	act, err := glib.SimpleActionNew("LogViewerAction", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = act.Connect("activate", func(action *glib.SimpleAction, param *glib.Variant) {
+		name, state, err := GetActionNameAndState(action)
+		if err != nil {
+			lg.Fatal(err)
+		}
+		lg.Debugf("%v action activated with current state %v and args %v",
+			name, state, param)
+
+		fontSize := appSettings.settings.GetString(CFG_SESSION_LOG_WIDGET_FONT_SIZE)
+		win, err := CreateLogViewerWindow(mainWin, fontSize)
+		if err != nil {
+			lg.Fatal(err)
+		}
+
+		win.ShowAll()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return act, nil
+}
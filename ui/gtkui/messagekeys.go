@@ -51,6 +51,8 @@ const (
 
 	MsgPrefDlgDoNotShowAtAppStartupCaption = "PrefDlgDoNotShowAtAppStartupCaption"
 	MsgPrefDlgDoNotShowAtAppStartupHint    = "PrefDlgDoNotShowAtAppStartupHint"
+	MsgPrefDlgCheckForUpdatesCaption       = "PrefDlgCheckForUpdatesCaption"
+	MsgPrefDlgCheckForUpdatesHint          = "PrefDlgCheckForUpdatesHint"
 
 	MsgPrefDlgSessionLogControlFontSizeCaption = "PrefDlgSessionLogControlFontSizeCaption"
 	MsgPrefDlgSessionLogControlFontSizeHint    = "PrefDlgSessionLogControlFontSizeHint"
@@ -58,6 +60,7 @@ const (
 	MsgPrefDlgSourcesCaption                  = "PrefDlgSourcesCaption"
 	MsgPrefDlgSourceRsyncPathCaption          = "PrefDlgSourceRsyncPathCaption"
 	MsgPrefDlgSourceRsyncPathRetryHint        = "PrefDlgSourceRsyncPathRetryHint"
+	MsgPrefDlgSourceRsyncDaemonModulesHint    = "PrefDlgSourceRsyncDaemonModulesHint"
 	MsgPrefDlgSourceRsyncPathDescriptionHint  = "PrefDlgSourceRsyncPathDescriptionHint"
 	MsgPrefDlgSourceRsyncPathNotValidatedHint = "PrefDlgSourceRsyncPathNotValidatedHint"
 	MsgPrefDlgSourceRsyncPathEmptyError       = "PrefDlgSourceRsyncPathEmptyError"
@@ -75,10 +78,47 @@ const (
 	MsgPrefDlgAuthPasswordHint            = "PrefDlgAuthPasswordHint"
 	MsgPrefDlgChangeFilePermissionCaption = "PrefDlgChangeFilePermissionCaption"
 	MsgPrefDlgChangeFilePermissionHint    = "PrefDlgChangeFilePermissionHint"
+	MsgPrefDlgModuleDestRootPathCaption   = "PrefDlgModuleDestRootPathCaption"
+	MsgPrefDlgModuleDestRootPathHint      = "PrefDlgModuleDestRootPathHint"
 
 	MsgPrefDlgOverrideRsyncTransferOptionsBoxCaption = "PrefDlgOverrideRsyncTransferOptionsBoxCaption"
 	MsgPrefDlgOverrideRsyncTransferOptionsBoxHint    = "PrefDlgOverrideRsyncTransferOptionsBoxHint"
 
+	MsgPrefDlgSkipFilesLargerThanCaption = "PrefDlgSkipFilesLargerThanCaption"
+	MsgPrefDlgSkipFilesLargerThanHint    = "PrefDlgSkipFilesLargerThanHint"
+	MsgPrefDlgSkipFilesOlderThanCaption  = "PrefDlgSkipFilesOlderThanCaption"
+	MsgPrefDlgSkipFilesOlderThanHint     = "PrefDlgSkipFilesOlderThanHint"
+	MsgPrefDlgSkipFilesNewerThanCaption  = "PrefDlgSkipFilesNewerThanCaption"
+	MsgPrefDlgSkipFilesNewerThanHint     = "PrefDlgSkipFilesNewerThanHint"
+	MsgPrefDlgExcludePatternsCaption     = "PrefDlgExcludePatternsCaption"
+	MsgPrefDlgExcludePatternsHint        = "PrefDlgExcludePatternsHint"
+	MsgPrefDlgUserMapCaption             = "PrefDlgUserMapCaption"
+	MsgPrefDlgUserMapHint                = "PrefDlgUserMapHint"
+	MsgPrefDlgGroupMapCaption            = "PrefDlgGroupMapCaption"
+	MsgPrefDlgGroupMapHint               = "PrefDlgGroupMapHint"
+	MsgPrefDlgChownCaption               = "PrefDlgChownCaption"
+	MsgPrefDlgChownHint                  = "PrefDlgChownHint"
+	MsgPrefDlgSourceCharsetCaption       = "PrefDlgSourceCharsetCaption"
+	MsgPrefDlgSourceCharsetHint          = "PrefDlgSourceCharsetHint"
+	MsgPrefDlgSourceCharsetNoneEntry     = "PrefDlgSourceCharsetNoneEntry"
+	MsgPrefDlgOwnerMapFromPlaceholder    = "PrefDlgOwnerMapFromPlaceholder"
+	MsgPrefDlgOwnerMapToPlaceholder      = "PrefDlgOwnerMapToPlaceholder"
+	MsgPrefDlgOwnerMapAddHint            = "PrefDlgOwnerMapAddHint"
+	MsgPrefDlgOwnerMapRemoveHint         = "PrefDlgOwnerMapRemoveHint"
+	MsgPrefDlgModuleIOTimeoutCaption     = "PrefDlgModuleIOTimeoutCaption"
+	MsgPrefDlgModuleIOTimeoutHint        = "PrefDlgModuleIOTimeoutHint"
+	MsgPrefDlgModuleConnTimeoutCaption   = "PrefDlgModuleConnTimeoutCaption"
+	MsgPrefDlgModuleConnTimeoutHint      = "PrefDlgModuleConnTimeoutHint"
+	MsgPrefDlgModuleNiceLevelHint        = "PrefDlgModuleNiceLevelHint"
+	MsgPrefDlgModuleIOClassHint          = "PrefDlgModuleIOClassHint"
+	MsgPrefDlgModuleIOClassGlobalEntry   = "PrefDlgModuleIOClassGlobalEntry"
+	MsgPrefDlgSkipPlanEstimationCaption  = "PrefDlgSkipPlanEstimationCaption"
+	MsgPrefDlgSkipPlanEstimationHint     = "PrefDlgSkipPlanEstimationHint"
+	MsgPrefDlgModuleTagsCaption          = "PrefDlgModuleTagsCaption"
+	MsgPrefDlgModuleTagsHint             = "PrefDlgModuleTagsHint"
+	MsgPrefDlgSourceAliasesCaption       = "PrefDlgSourceAliasesCaption"
+	MsgPrefDlgSourceAliasesHint          = "PrefDlgSourceAliasesHint"
+
 	MsgPrefDlgEnableBackupBlockCaption = "PrefDlgEnableBackupBlockCaption"
 	MsgPrefDlgEnableBackupBlockHint    = "PrefDlgEnableBackupBlockHint"
 
@@ -95,14 +135,111 @@ const (
 	MsgPrefDlgDefaultDestPathCaption = "PrefDlgDefaultDestPathCaption"
 	MsgPrefDlgDefaultDestPathHint    = "PrefDlgDefaultDestPathHint"
 
+	MsgPrefDlgMountDestinationCaption     = "PrefDlgMountDestinationCaption"
+	MsgPrefDlgMountDestinationHint        = "PrefDlgMountDestinationHint"
+	MsgPrefDlgMountDestinationSpecCaption = "PrefDlgMountDestinationSpecCaption"
+	MsgPrefDlgMountDestinationSpecHint    = "PrefDlgMountDestinationSpecHint"
+	MsgPrefDlgUnmountDestinationCaption   = "PrefDlgUnmountDestinationCaption"
+	MsgPrefDlgUnmountDestinationHint      = "PrefDlgUnmountDestinationHint"
+
+	MsgPrefDlgSyncDestinationCaption  = "PrefDlgSyncDestinationCaption"
+	MsgPrefDlgSyncDestinationHint     = "PrefDlgSyncDestinationHint"
+	MsgPrefDlgEjectDestinationCaption = "PrefDlgEjectDestinationCaption"
+	MsgPrefDlgEjectDestinationHint    = "PrefDlgEjectDestinationHint"
+
+	MsgPrefDlgDestDriveUUIDCaption      = "PrefDlgDestDriveUUIDCaption"
+	MsgPrefDlgDestDriveUUIDHint         = "PrefDlgDestDriveUUIDHint"
+	MsgPrefDlgDestDriveDetectHint       = "PrefDlgDestDriveDetectHint"
+	MsgPrefDlgDestDriveDetectError      = "PrefDlgDestDriveDetectError"
+	MsgPrefDlgDestDriveNotFoundError    = "PrefDlgDestDriveNotFoundError"
+	MsgPrefDlgDestDriveSubpathCaption   = "PrefDlgDestDriveSubpathCaption"
+	MsgPrefDlgDestDriveSubpathHint      = "PrefDlgDestDriveSubpathHint"
+	MsgPrefDlgDestDriveAskBackupCaption = "PrefDlgDestDriveAskBackupCaption"
+	MsgPrefDlgDestDriveAskBackupHint    = "PrefDlgDestDriveAskBackupHint"
+
+	MsgPrefDlgCloudSyncCaption       = "PrefDlgCloudSyncCaption"
+	MsgPrefDlgCloudSyncHint          = "PrefDlgCloudSyncHint"
+	MsgPrefDlgCloudSyncRemoteCaption = "PrefDlgCloudSyncRemoteCaption"
+	MsgPrefDlgCloudSyncRemoteHint    = "PrefDlgCloudSyncRemoteHint"
+	MsgPrefDlgCloudSyncPathCaption   = "PrefDlgCloudSyncPathCaption"
+	MsgPrefDlgCloudSyncPathHint      = "PrefDlgCloudSyncPathHint"
+	MsgPrefDlgMirrorDestPathsCaption = "PrefDlgMirrorDestPathsCaption"
+	MsgPrefDlgMirrorDestPathsHint    = "PrefDlgMirrorDestPathsHint"
+
+	MsgPrefDlgSnapshotModeCaption         = "PrefDlgSnapshotModeCaption"
+	MsgPrefDlgSnapshotModeHint            = "PrefDlgSnapshotModeHint"
+	MsgPrefDlgSnapshotModeNoneEntry       = "PrefDlgSnapshotModeNoneEntry"
+	MsgPrefDlgSnapshotModeAppendEntry     = "PrefDlgSnapshotModeAppendEntry"
+	MsgPrefDlgSnapshotModeReplaceEntry    = "PrefDlgSnapshotModeReplaceEntry"
+	MsgPrefDlgSnapshotNameTemplateCaption = "PrefDlgSnapshotNameTemplateCaption"
+	MsgPrefDlgSnapshotNameTemplateHint    = "PrefDlgSnapshotNameTemplateHint"
+	MsgPrefDlgSnapshotKeepCaption         = "PrefDlgSnapshotKeepCaption"
+	MsgPrefDlgSnapshotKeepHint            = "PrefDlgSnapshotKeepHint"
+
+	MsgPrefDlgModuleErrorPolicyCaption    = "PrefDlgModuleErrorPolicyCaption"
+	MsgPrefDlgModuleErrorPolicyHint       = "PrefDlgModuleErrorPolicyHint"
+	MsgPrefDlgModuleErrorPolicyAbortEntry = "PrefDlgModuleErrorPolicyAbortEntry"
+	MsgPrefDlgModuleErrorPolicySkipEntry  = "PrefDlgModuleErrorPolicySkipEntry"
+	MsgPrefDlgModuleErrorPolicyAskEntry   = "PrefDlgModuleErrorPolicyAskEntry"
+
+	MsgPrefDlgSessionLogVerbosityCaption      = "PrefDlgSessionLogVerbosityCaption"
+	MsgPrefDlgSessionLogVerbosityHint         = "PrefDlgSessionLogVerbosityHint"
+	MsgPrefDlgSessionLogVerbosityQuietEntry   = "PrefDlgSessionLogVerbosityQuietEntry"
+	MsgPrefDlgSessionLogVerbosityNormalEntry  = "PrefDlgSessionLogVerbosityNormalEntry"
+	MsgPrefDlgSessionLogVerbosityVerboseEntry = "PrefDlgSessionLogVerbosityVerboseEntry"
+	MsgPrefDlgSessionLogVerbosityDebugEntry   = "PrefDlgSessionLogVerbosityDebugEntry"
+
+	MsgPrefDlgQuotaSizeCaption      = "PrefDlgQuotaSizeCaption"
+	MsgPrefDlgQuotaSizeHint         = "PrefDlgQuotaSizeHint"
+	MsgPrefDlgQuotaPolicyCaption    = "PrefDlgQuotaPolicyCaption"
+	MsgPrefDlgQuotaPolicyHint       = "PrefDlgQuotaPolicyHint"
+	MsgPrefDlgQuotaPolicyWarnEntry  = "PrefDlgQuotaPolicyWarnEntry"
+	MsgPrefDlgQuotaPolicyPruneEntry = "PrefDlgQuotaPolicyPruneEntry"
+	MsgPrefDlgQuotaPolicyAbortEntry = "PrefDlgQuotaPolicyAbortEntry"
+
+	MsgPrefDlgBackupWindowCaption                 = "PrefDlgBackupWindowCaption"
+	MsgPrefDlgBackupWindowHint                    = "PrefDlgBackupWindowHint"
+	MsgPrefDlgBackupWindowStartCaption            = "PrefDlgBackupWindowStartCaption"
+	MsgPrefDlgBackupWindowEndCaption              = "PrefDlgBackupWindowEndCaption"
+	MsgPrefDlgBackupWindowTimeHint                = "PrefDlgBackupWindowTimeHint"
+	MsgPrefDlgBackupWindowRequireACPowerCaption   = "PrefDlgBackupWindowRequireACPowerCaption"
+	MsgPrefDlgBackupWindowRequireACPowerHint      = "PrefDlgBackupWindowRequireACPowerHint"
+	MsgPrefDlgBackupWindowRequireUnmeteredCaption = "PrefDlgBackupWindowRequireUnmeteredCaption"
+	MsgPrefDlgBackupWindowRequireUnmeteredHint    = "PrefDlgBackupWindowRequireUnmeteredHint"
+
 	MsgPrefDlgSkipFolderBackupFileSignatureCaption = "PrefDlgSkipFolderBackupFileSignatureCaption"
 	MsgPrefDlgSkipFolderBackupFileSignatureHint    = "PrefDlgSkipFolderBackupFileSignatureHint"
 
 	MsgPrefDlgPerformDesktopNotificationCaption = "PrefDlgPerformDesktopNotificationCaption"
 	MsgPrefDlgPerformDesktopNotificationHint    = "PrefDlgPerformDesktopNotificationHint"
 
-	MsgPrefDlgRunNotificationScriptCaption = "PrefDlgRunNotificationScriptCaption"
-	MsgPrefDlgRunNotificationScriptHint    = "PrefDlgRunNotificationScriptHint"
+	MsgPrefDlgConfirmBackupPlanCaption = "PrefDlgConfirmBackupPlanCaption"
+	MsgPrefDlgConfirmBackupPlanHint    = "PrefDlgConfirmBackupPlanHint"
+	MsgPrefDlgAskSessionCommentCaption = "PrefDlgAskSessionCommentCaption"
+	MsgPrefDlgAskSessionCommentHint    = "PrefDlgAskSessionCommentHint"
+	MsgPrefDlgEnableTrayIconCaption    = "PrefDlgEnableTrayIconCaption"
+	MsgPrefDlgEnableTrayIconHint       = "PrefDlgEnableTrayIconHint"
+
+	MsgPrefDlgMetricsEndpointCaption = "PrefDlgMetricsEndpointCaption"
+	MsgPrefDlgMetricsEndpointHint    = "PrefDlgMetricsEndpointHint"
+
+	MsgPrefDlgInhibitSuspendCaption = "PrefDlgInhibitSuspendCaption"
+	MsgPrefDlgInhibitSuspendHint    = "PrefDlgInhibitSuspendHint"
+
+	MsgPrefDlgAutostartCaption = "PrefDlgAutostartCaption"
+	MsgPrefDlgAutostartHint    = "PrefDlgAutostartHint"
+	MsgPrefDlgAutostartError   = "PrefDlgAutostartError"
+
+	MsgPrefDlgRunNotificationScriptCaption       = "PrefDlgRunNotificationScriptCaption"
+	MsgPrefDlgRunNotificationScriptHint          = "PrefDlgRunNotificationScriptHint"
+	MsgPrefDlgNotificationScriptPathCaption      = "PrefDlgNotificationScriptPathCaption"
+	MsgPrefDlgNotificationScriptPathHint         = "PrefDlgNotificationScriptPathHint"
+	MsgPrefDlgNotificationScriptSelectHint       = "PrefDlgNotificationScriptSelectHint"
+	MsgPrefDlgNotificationScriptSelectDlgTitle   = "PrefDlgNotificationScriptSelectDlgTitle"
+	MsgPrefDlgNotificationScriptTestRunHint      = "PrefDlgNotificationScriptTestRunHint"
+	MsgPrefDlgNotificationScriptTestRunTitle     = "PrefDlgNotificationScriptTestRunTitle"
+	MsgPrefDlgNotificationScriptTestRunCompleted = "PrefDlgNotificationScriptTestRunCompleted"
+	MsgPrefDlgNotificationScriptTestRunFailed    = "PrefDlgNotificationScriptTestRunFailed"
 
 	MsgPrefDlgAutoManageBackupBlockSizeCaption = "PrefDlgAutoManageBackupBlockSizeCaption"
 	MsgPrefDlgAutoManageBackupBlockSizeHint    = "PrefDlgAutoManageBackupBlockSizeHint"
@@ -110,14 +247,74 @@ const (
 	MsgPrefDlgBackupBlockSizeCaption = "PrefDlgBackupBlockSizeCaption"
 	MsgPrefDlgBackupBlockSizeHint    = "PrefDlgBackupBlockSizeHint"
 
+	MsgPrefDlgBuildDirTreeInMemoryCaption = "PrefDlgBuildDirTreeInMemoryCaption"
+	MsgPrefDlgBuildDirTreeInMemoryHint    = "PrefDlgBuildDirTreeInMemoryHint"
+
+	MsgPrefDlgIncrementalPlanCaption = "PrefDlgIncrementalPlanCaption"
+	MsgPrefDlgIncrementalPlanHint    = "PrefDlgIncrementalPlanHint"
+	MsgPrefDlgPlanTraceCaption       = "PrefDlgPlanTraceCaption"
+	MsgPrefDlgPlanTraceHint          = "PrefDlgPlanTraceHint"
+
+	MsgPrefDlgPlanStageTempPathCaption = "PrefDlgPlanStageTempPathCaption"
+	MsgPrefDlgPlanStageTempPathHint    = "PrefDlgPlanStageTempPathHint"
+
 	MsgPrefDlgRsyncRetryCountCaption = "PrefDlgRsyncRetryCountCaption"
 	MsgPrefDlgRsyncRetryCountHint    = "PrefDlgRsyncRetryCountHint"
 
+	MsgPrefDlgNetworkWatchdogCaption      = "PrefDlgNetworkWatchdogCaption"
+	MsgPrefDlgNetworkWatchdogHint         = "PrefDlgNetworkWatchdogHint"
+	MsgPrefDlgNetworkOutageMaxWaitCaption = "PrefDlgNetworkOutageMaxWaitCaption"
+	MsgPrefDlgNetworkOutageMaxWaitHint    = "PrefDlgNetworkOutageMaxWaitHint"
+	MsgPrefDlgRsyncHangTimeoutCaption     = "PrefDlgRsyncHangTimeoutCaption"
+	MsgPrefDlgRsyncHangTimeoutHint        = "PrefDlgRsyncHangTimeoutHint"
+	MsgPrefDlgRsyncIOTimeoutCaption       = "PrefDlgRsyncIOTimeoutCaption"
+	MsgPrefDlgRsyncIOTimeoutHint          = "PrefDlgRsyncIOTimeoutHint"
+	MsgPrefDlgRsyncConnectTimeoutCaption  = "PrefDlgRsyncConnectTimeoutCaption"
+	MsgPrefDlgRsyncConnectTimeoutHint     = "PrefDlgRsyncConnectTimeoutHint"
+	MsgPrefDlgVerifySSHHostKeysCaption    = "PrefDlgVerifySSHHostKeysCaption"
+	MsgPrefDlgVerifySSHHostKeysHint       = "PrefDlgVerifySSHHostKeysHint"
+
+	MsgPrefDlgModuleHealthCheckIntervalCaption = "PrefDlgModuleHealthCheckIntervalCaption"
+	MsgPrefDlgModuleHealthCheckIntervalHint    = "PrefDlgModuleHealthCheckIntervalHint"
+
+	MsgPrefDlgHostCredentialsCaption            = "PrefDlgHostCredentialsCaption"
+	MsgPrefDlgHostCredentialsHint               = "PrefDlgHostCredentialsHint"
+	MsgPrefDlgHostCredentialAddHint             = "PrefDlgHostCredentialAddHint"
+	MsgPrefDlgHostCredentialRemoveHint          = "PrefDlgHostCredentialRemoveHint"
+	MsgPrefDlgHostCredentialHostPlaceholder     = "PrefDlgHostCredentialHostPlaceholder"
+	MsgPrefDlgHostCredentialHostHint            = "PrefDlgHostCredentialHostHint"
+	MsgPrefDlgHostCredentialUserPlaceholder     = "PrefDlgHostCredentialUserPlaceholder"
+	MsgPrefDlgHostCredentialUserHint            = "PrefDlgHostCredentialUserHint"
+	MsgPrefDlgHostCredentialPasswordPlaceholder = "PrefDlgHostCredentialPasswordPlaceholder"
+	MsgPrefDlgHostCredentialPasswordHint        = "PrefDlgHostCredentialPasswordHint"
+	MsgPrefDlgHostCredentialKeyPathPlaceholder  = "PrefDlgHostCredentialKeyPathPlaceholder"
+	MsgPrefDlgHostCredentialKeyPathHint         = "PrefDlgHostCredentialKeyPathHint"
+
+	MsgPrefDlgRsyncNiceLevelCaption       = "PrefDlgRsyncNiceLevelCaption"
+	MsgPrefDlgRsyncNiceLevelHint          = "PrefDlgRsyncNiceLevelHint"
+	MsgPrefDlgRsyncIOClassCaption         = "PrefDlgRsyncIOClassCaption"
+	MsgPrefDlgRsyncIOClassHint            = "PrefDlgRsyncIOClassHint"
+	MsgPrefDlgRsyncIOClassDefaultEntry    = "PrefDlgRsyncIOClassDefaultEntry"
+	MsgPrefDlgRsyncIOClassBestEffortEntry = "PrefDlgRsyncIOClassBestEffortEntry"
+	MsgPrefDlgRsyncIOClassIdleEntry       = "PrefDlgRsyncIOClassIdleEntry"
+	MsgPrefDlgRsyncIOLevelCaption         = "PrefDlgRsyncIOLevelCaption"
+	MsgPrefDlgRsyncIOLevelHint            = "PrefDlgRsyncIOLevelHint"
+
 	MsgPrefDlgRsyncLowLevelLogCaption = "PrefDlgRsyncLowLevelLogCaption"
 	MsgPrefDlgRsyncLowLevelLogHint    = "PrefDlgRsyncLowLevelLogHint"
 
 	MsgPrefDlgRsyncIntensiveLowLevelLogCaption = "PrefDlgRsyncIntensiveLowLevelLogCaption"
 	MsgPrefDlgRsyncIntensiveLowLevelLogHint    = "PrefDlgRsyncIntensiveLowLevelLogHint"
+	MsgPrefDlgSessionLogFormatCaption          = "PrefDlgSessionLogFormatCaption"
+	MsgPrefDlgSessionLogFormatHint             = "PrefDlgSessionLogFormatHint"
+	MsgPrefDlgSessionLogFormatTextEntry        = "PrefDlgSessionLogFormatTextEntry"
+	MsgPrefDlgSessionLogFormatJsonEntry        = "PrefDlgSessionLogFormatJsonEntry"
+	MsgPrefDlgLogSegmentMaxSizeCaption         = "PrefDlgLogSegmentMaxSizeCaption"
+	MsgPrefDlgLogSegmentMaxSizeHint            = "PrefDlgLogSegmentMaxSizeHint"
+	MsgPrefDlgGenerateCatalogCaption           = "PrefDlgGenerateCatalogCaption"
+	MsgPrefDlgGenerateCatalogHint              = "PrefDlgGenerateCatalogHint"
+	MsgPrefDlgGenerateChecksumsCaption         = "PrefDlgGenerateChecksumsCaption"
+	MsgPrefDlgGenerateChecksumsHint            = "PrefDlgGenerateChecksumsHint"
 
 	MsgPrefDlgUsePreviousBackupForDedupCaption = "PrefDlgUsePreviousBackupForDedupCaption"
 	MsgPrefDlgUsePreviousBackupForDedupHint    = "PrefDlgUsePreviousBackupForDedupHint"
@@ -125,8 +322,28 @@ const (
 	MsgPrefDlgNumberOfPreviousBackupToUseCaption = "PrefDlgNumberOfPreviousBackupToUseCaption"
 	MsgPrefDlgNumberOfPreviousBackupToUseHint    = "PrefDlgNumberOfPreviousBackupToUseHint"
 
+	MsgPrefDlgSafeDeleteCaption          = "PrefDlgSafeDeleteCaption"
+	MsgPrefDlgSafeDeleteHint             = "PrefDlgSafeDeleteHint"
+	MsgPrefDlgSafeDeleteMaxFilesCaption  = "PrefDlgSafeDeleteMaxFilesCaption"
+	MsgPrefDlgSafeDeleteMaxFilesHint     = "PrefDlgSafeDeleteMaxFilesHint"
+	MsgPrefDlgSafeDeleteMaxSizeMbCaption = "PrefDlgSafeDeleteMaxSizeMbCaption"
+	MsgPrefDlgSafeDeleteMaxSizeMbHint    = "PrefDlgSafeDeleteMaxSizeMbHint"
+
 	MsgPrefDlgRsyncCompressFileTransferCaption = "PrefDlgRsyncCompressFileTransferCaption"
 	MsgPrefDlgRsyncCompressFileTransferHint    = "PrefDlgRsyncCompressFileTransferHint"
+	MsgPrefDlgRsyncCompressChoiceCaption       = "PrefDlgRsyncCompressChoiceCaption"
+	MsgPrefDlgRsyncCompressChoiceHint          = "PrefDlgRsyncCompressChoiceHint"
+	MsgPrefDlgRsyncCompressChoiceAutoEntry     = "PrefDlgRsyncCompressChoiceAutoEntry"
+	MsgPrefDlgRsyncCompressChoiceZlibEntry     = "PrefDlgRsyncCompressChoiceZlibEntry"
+	MsgPrefDlgRsyncCompressLevelCaption        = "PrefDlgRsyncCompressLevelCaption"
+	MsgPrefDlgRsyncCompressLevelHint           = "PrefDlgRsyncCompressLevelHint"
+
+	MsgPrefDlgRsyncPartialTransferCaption = "PrefDlgRsyncPartialTransferCaption"
+	MsgPrefDlgRsyncPartialTransferHint    = "PrefDlgRsyncPartialTransferHint"
+	MsgPrefDlgRsyncSparseFilesCaption     = "PrefDlgRsyncSparseFilesCaption"
+	MsgPrefDlgRsyncSparseFilesHint        = "PrefDlgRsyncSparseFilesHint"
+	MsgPrefDlgRsyncInplaceCaption         = "PrefDlgRsyncInplaceCaption"
+	MsgPrefDlgRsyncInplaceHint            = "PrefDlgRsyncInplaceHint"
 
 	MsgPrefDlgRsyncTransferSourcePermissionsCaption = "PrefDlgRsyncTransferSourcePermissionsCaption"
 	MsgPrefDlgRsyncTransferSourcePermissionsHint    = "PrefDlgRsyncTransferSourcePermissionsHint"
@@ -146,10 +363,26 @@ const (
 	MsgPrefDlgRsyncTransferSpecialFilesCaption = "PrefDlgRsyncTransferSpecialFilesCaption"
 	MsgPrefDlgRsyncTransferSpecialFilesHint    = "PrefDlgRsyncTransferSpecialFilesHint"
 
+	MsgPrefDlgRsyncTransferACLsCaption    = "PrefDlgRsyncTransferACLsCaption"
+	MsgPrefDlgRsyncTransferACLsHint       = "PrefDlgRsyncTransferACLsHint"
+	MsgPrefDlgRsyncTransferXattrsCaption  = "PrefDlgRsyncTransferXattrsCaption"
+	MsgPrefDlgRsyncTransferXattrsHint     = "PrefDlgRsyncTransferXattrsHint"
+	MsgPrefDlgRsyncOptionNotSupportedHint = "PrefDlgRsyncOptionNotSupportedHint"
+
 	MsgPrefDlgLanguageCaption                    = "PrefDlgLanguageCaption"
 	MsgPrefDlgLanguageHint                       = "PrefDlgLanguageHint"
+	MsgPrefDlgUIThemeCaption                     = "PrefDlgUIThemeCaption"
+	MsgPrefDlgUIThemeHint                        = "PrefDlgUIThemeHint"
+	MsgPrefDlgUIThemeSystemEntry                 = "PrefDlgUIThemeSystemEntry"
+	MsgPrefDlgUIThemeLightEntry                  = "PrefDlgUIThemeLightEntry"
+	MsgPrefDlgUIThemeDarkEntry                   = "PrefDlgUIThemeDarkEntry"
+	MsgPrefDlgSizeUnitsCaption                   = "PrefDlgSizeUnitsCaption"
+	MsgPrefDlgSizeUnitsHint                      = "PrefDlgSizeUnitsHint"
+	MsgPrefDlgSizeUnitsSIEntry                   = "PrefDlgSizeUnitsSIEntry"
+	MsgPrefDlgSizeUnitsBinaryEntry               = "PrefDlgSizeUnitsBinaryEntry"
 	MsgPrefDlgDefaultLanguageEntry               = "PrefDlgDefaultLanguageEntry"
 	MsgPrefDlgAddBackupBlockHint                 = "PrefDlgAddBackupBlockHint"
+	MsgPrefDlgAddBackupBlockFromTemplateHint     = "PrefDlgAddBackupBlockFromTemplateHint"
 	MsgPrefDlgProfileConfigIssuesDetectedWarning = "PrefDlgProfileConfigIssuesDetectedWarning"
 	MsgPrefDlgPreferencesDialogCaption           = "PrefDlgPreferencesDialogCaption"
 
@@ -157,9 +390,12 @@ const (
 	MsgPrefDlgProfileTabName        = "PrefDlgProfileTabName"
 	MsgPrefDlgGeneralTabName        = "PrefDlgGeneralTabName"
 	MsgPrefDlgAdvancedTabName       = "PrefDlgAdvancedTabName"
+	MsgPrefDlgHiddenTabName         = "PrefDlgHiddenTabName"
 
 	MsgPrefDlgAddProfileHint           = "PrefDlgAddProfileHint"
 	MsgPrefDlgDeleteProfileHint        = "PrefDlgDeleteProfileHint"
+	MsgPrefDlgDuplicateProfileHint     = "PrefDlgDuplicateProfileHint"
+	MsgPrefDlgDuplicateProfileName     = "PrefDlgDuplicateProfileName"
 	MsgPrefDlgDeleteProfileDialogTitle = "PrefDlgDeleteProfileDialogTitle"
 	MsgPrefDlgDeleteProfileDialogText  = "PrefDlgDeleteProfileDialogText"
 
@@ -168,13 +404,35 @@ const (
 	MsgSchemaConfigDlgSchemaDoesNotFoundError = "SchemaConfigDlgSchemaDoesNotFoundError"
 	MsgSchemaConfigDlgSchemaErrorAdvise       = "SchemaConfigDlgSchemaErrorAdvise"
 
-	MsgAppWindowAboutMenuCaption       = "AppWindowAboutMenuCaption"
-	MsgAppWindowHelpMenuCaption        = "AppWindowHelpMenuCaption"
-	MsgAppWindowPreferencesMenuCaption = "AppWindowPreferencesMenuCaption"
-	MsgAppWindowPreferencesHint        = "AppWindowPreferencesHint"
-	MsgAppWindowQuitMenuCaption        = "AppWindowQuitMenuCaption"
-	MsgAppWindowRunBackupHint          = "AppWindowRunBackupHint"
-	MsgAppWindowStopBackupHint         = "AppWindowStopBackupHint"
+	MsgAppWindowAboutMenuCaption        = "AppWindowAboutMenuCaption"
+	MsgAppWindowHelpMenuCaption         = "AppWindowHelpMenuCaption"
+	MsgAppWindowDiagnosticsMenuCaption  = "AppWindowDiagnosticsMenuCaption"
+	MsgAppWindowPreferencesMenuCaption  = "AppWindowPreferencesMenuCaption"
+	MsgAppWindowCheckProfileMenuCaption = "AppWindowCheckProfileMenuCaption"
+	MsgAppWindowLogViewerMenuCaption    = "AppWindowLogViewerMenuCaption"
+	MsgAppWindowDiskUsageMenuCaption    = "AppWindowDiskUsageMenuCaption"
+	MsgAppWindowSkippedMenuCaption      = "AppWindowSkippedMenuCaption"
+	MsgAppWindowSearchFilesMenuCaption  = "AppWindowSearchFilesMenuCaption"
+	MsgAppWindowSessionListMenuCaption  = "AppWindowSessionListMenuCaption"
+	MsgAppWindowBackupDaemonMenuCaption = "AppWindowBackupDaemonMenuCaption"
+	MsgAppWindowIntegrityMenuCaption    = "AppWindowIntegrityMenuCaption"
+	MsgAppWindowPreferencesHint         = "AppWindowPreferencesHint"
+	MsgAppWindowQuitMenuCaption         = "AppWindowQuitMenuCaption"
+	MsgAppWindowRunBackupHint           = "AppWindowRunBackupHint"
+	MsgAppWindowStopBackupHint          = "AppWindowStopBackupHint"
+	MsgAppWindowMainMenuHint            = "AppWindowMainMenuHint"
+
+	MsgAppWindowCommandLineError                    = "AppWindowCommandLineError"
+	MsgAppWindowRunProfileNotFoundError             = "AppWindowRunProfileNotFoundError"
+	MsgAppWindowRunProfileBackupIsRunningError      = "AppWindowRunProfileBackupIsRunningError"
+	MsgAppWindowCommandLineStartWithoutProfileError = "AppWindowCommandLineStartWithoutProfileError"
+	MsgAppWindowRunProfileOutOfBackupWindowError    = "AppWindowRunProfileOutOfBackupWindowError"
+
+	MsgTrayIconShowMainWindowCaption = "TrayIconShowMainWindowCaption"
+	MsgTrayIconStartBackupCaption    = "TrayIconStartBackupCaption"
+	MsgTrayIconStopBackupCaption     = "TrayIconStopBackupCaption"
+	MsgTrayIconQuitCaption           = "TrayIconQuitCaption"
+	MsgTrayIconBackupProgressHint    = "TrayIconBackupProgressHint"
 
 	MsgAppWindowProfileCaption                      = "AppWindowProfileCaption"
 	MsgAppWindowProfileHint                         = "AppWindowProfileHint"
@@ -188,6 +446,13 @@ const (
 	MsgAppWindowRsyncPathIsEmptyError      = "AppWindowRsyncPathIsEmptyError"
 	MsgAppWindowDestPathCaption            = "AppWindowDestPathCaption"
 	MsgAppWindowDestPathHint               = "AppWindowDestPathHint"
+	MsgAppWindowModuleSelectorCaption      = "AppWindowModuleSelectorCaption"
+	MsgAppWindowModuleSelectorHint         = "AppWindowModuleSelectorHint"
+	MsgAppWindowNoModuleSelectedError      = "AppWindowNoModuleSelectedError"
+	MsgAppWindowNoModuleWithTagsError      = "AppWindowNoModuleWithTagsError"
+	MsgAppWindowModuleTagFilterCaption     = "AppWindowModuleTagFilterCaption"
+	MsgAppWindowModuleTagFilterHint        = "AppWindowModuleTagFilterHint"
+	MsgAppWindowModuleTagFilterAllEntry    = "AppWindowModuleTagFilterAllEntry"
 	MsgAppWindowDestPathIsValidStatusPart1 = "AppWindowDestPathIsValidStatusPart1"
 	MsgAppWindowDestPathIsValidStatusPart2 = "AppWindowDestPathIsValidStatusPart2"
 	MsgAppWindowDestPathIsEmptyError1      = "AppWindowDestPathIsEmptyError1"
@@ -195,21 +460,55 @@ const (
 	MsgAppWindowDestPathIsNotExistError    = "AppWindowDestPathIsNotExistError"
 	MsgAppWindowDestPathIsNotExistAdvise   = "AppWindowDestPathIsNotExistAdvise"
 
+	MsgAppWindowModuleSelectorUnreachableCaption = "AppWindowModuleSelectorUnreachableCaption"
+	MsgAppWindowModuleReachableHint              = "AppWindowModuleReachableHint"
+	MsgAppWindowModuleUnreachableHint            = "AppWindowModuleUnreachableHint"
+
 	MsgAppWindowBackupProgressStartMessage               = "AppWindowBackupProgressStartMessage"
 	MsgAppWindowBackupProgressInquiringSourceID          = "AppWindowBackupProgressInquiringSourceID"
 	MsgAppWindowBackupProgressInquiringSourceDescription = "AppWindowBackupProgressInquiringSourceDescription"
 	MsgAppWindowBackupProgressTimePassedSuffix           = "AppWindowBackupProgressTimePassedSuffix"
 	MsgAppWindowBackupProgressETASuffix                  = "AppWindowBackupProgressETASuffix"
+	MsgAppWindowBackupProgressETARange                   = "AppWindowBackupProgressETARange"
 	MsgAppWindowBackupProgressSizeCompletedSuffix        = "AppWindowBackupProgressSizeCompletedSuffix"
 	MsgAppWindowBackupProgressSizeLeftToProcessSuffix    = "AppWindowBackupProgressSizeLeftToProcessSuffix"
 	MsgAppWindowBackupProgressCompleted                  = "AppWindowBackupProgressCompleted"
 	MsgAppWindowBackupProgressCompletedWithErrors        = "AppWindowBackupProgressCompletedWithErrors"
 	MsgAppWindowBackupProgressTerminated                 = "AppWindowBackupProgressTerminated"
 	MsgAppWindowBackupProgressFailed                     = "AppWindowBackupProgressFailed"
+	MsgAppWindowBackupProgressWaitingForNetwork          = "AppWindowBackupProgressWaitingForNetwork"
+	MsgAppWindowBackupProgressNetworkWaitingDone         = "AppWindowBackupProgressNetworkWaitingDone"
 	MsgAppWindowOverallProgressCaption                   = "AppWindowOverallProgressCaption"
 	MsgAppWindowProgressStatusCaption                    = "AppWindowProgressStatusCaption"
 	MsgAppWindowSessionLogCaption                        = "AppWindowSessionLogCaption"
 	MsgAppWindowCannotStartBackupProcessTitle            = "AppWindowCannotStartBackupProcessTitle"
+	MsgAppWindowBackupWindowDlgTitle                     = "AppWindowBackupWindowDlgTitle"
+	MsgAppWindowBackupWindowDlgText                      = "AppWindowBackupWindowDlgText"
+	MsgAppWindowInhibitSuspendReason                     = "AppWindowInhibitSuspendReason"
+	MsgAppWindowInhibitSuspendError                      = "AppWindowInhibitSuspendError"
+	MsgAppWindowSyncDestinationError                     = "AppWindowSyncDestinationError"
+	MsgAppWindowEjectDestinationError                    = "AppWindowEjectDestinationError"
+	MsgAppWindowEjectDestinationSafeToUnplug             = "AppWindowEjectDestinationSafeToUnplug"
+	MsgAppWindowCloudSyncError                           = "AppWindowCloudSyncError"
+	MsgAppWindowSnapshotError                            = "AppWindowSnapshotError"
+
+	MsgAppWindowModulesProgressCaption = "AppWindowModulesProgressCaption"
+	MsgAppWindowModulesProgressHint    = "AppWindowModulesProgressHint"
+	MsgAppWindowModuleProgressPending  = "AppWindowModuleProgressPending"
+	MsgAppWindowModuleProgressRunning  = "AppWindowModuleProgressRunning"
+	MsgAppWindowModuleProgressDone     = "AppWindowModuleProgressDone"
+	MsgAppWindowModuleProgressError    = "AppWindowModuleProgressError"
+	MsgAppWindowModuleProgressSize     = "AppWindowModuleProgressSize"
+
+	MsgAppWindowRetryFailedFoldersCaption = "AppWindowRetryFailedFoldersCaption"
+	MsgAppWindowRetryFailedFoldersHint    = "AppWindowRetryFailedFoldersHint"
+	MsgAppWindowFailedFoldersCaption      = "AppWindowFailedFoldersCaption"
+	MsgAppWindowFailedFoldersHint         = "AppWindowFailedFoldersHint"
+	MsgAppWindowFailedFolderOpenHint      = "AppWindowFailedFolderOpenHint"
+	MsgAppWindowFailedFolderRetryHint     = "AppWindowFailedFolderRetryHint"
+
+	MsgAppWindowRsyncOutputCaption = "AppWindowRsyncOutputCaption"
+	MsgAppWindowRsyncOutputHint    = "AppWindowRsyncOutputHint"
 
 	MsgAppWindowTerminateBackupDlgTitle = "AppWindowTerminateBackupDlgTitle"
 	MsgAppWindowTerminateBackupDlgText  = "AppWindowTerminateBackupDlgText"
@@ -221,6 +520,72 @@ const (
 	MsgAppWindowOutOfSpaceDlgRetryButton     = "AppWindowOutOfSpaceDlgRetryButton"
 	MsgAppWindowOutOfSpaceDlgTerminateButton = "AppWindowOutOfSpaceDlgTerminateButton"
 
+	MsgAppWindowModuleErrorDlgTitle           = "AppWindowModuleErrorDlgTitle"
+	MsgAppWindowModuleErrorDlgText1           = "AppWindowModuleErrorDlgText1"
+	MsgAppWindowModuleErrorDlgText2           = "AppWindowModuleErrorDlgText2"
+	MsgAppWindowModuleErrorDlgSkipButton      = "AppWindowModuleErrorDlgSkipButton"
+	MsgAppWindowModuleErrorDlgTerminateButton = "AppWindowModuleErrorDlgTerminateButton"
+
+	MsgAppWindowSafeDeleteDlgTitle                = "AppWindowSafeDeleteDlgTitle"
+	MsgAppWindowSafeDeleteDlgText                 = "AppWindowSafeDeleteDlgText"
+	MsgAppWindowDestLockDlgTitle                  = "AppWindowDestLockDlgTitle"
+	MsgAppWindowDestLockDlgStaleText              = "AppWindowDestLockDlgStaleText"
+	MsgAppWindowDestLockDlgForeignText            = "AppWindowDestLockDlgForeignText"
+	MsgAppWindowBackupPlanDlgTitle                = "AppWindowBackupPlanDlgTitle"
+	MsgAppWindowBackupPlanDlgText                 = "AppWindowBackupPlanDlgText"
+	MsgAppWindowBackupPlanDlgSourceText           = "AppWindowBackupPlanDlgSourceText"
+	MsgAppWindowBackupPlanDlgSizeUnknown          = "AppWindowBackupPlanDlgSizeUnknown"
+	MsgAppWindowBackupPlanDlgDurationUnknown      = "AppWindowBackupPlanDlgDurationUnknown"
+	MsgAppWindowBackupPlanDlgTotalText            = "AppWindowBackupPlanDlgTotalText"
+	MsgAppWindowBackupPlanDlgDurationText         = "AppWindowBackupPlanDlgDurationText"
+	MsgAppWindowBackupPlanDlgDurationPartialText  = "AppWindowBackupPlanDlgDurationPartialText"
+	MsgAppWindowBackupPlanDlgFreeSpaceText        = "AppWindowBackupPlanDlgFreeSpaceText"
+	MsgAppWindowBackupPlanDlgFreeSpaceLowText     = "AppWindowBackupPlanDlgFreeSpaceLowText"
+	MsgAppWindowBackupPlanDlgFreeSpaceUnknownText = "AppWindowBackupPlanDlgFreeSpaceUnknownText"
+	MsgAppWindowBackupPlanDlgStartButton          = "AppWindowBackupPlanDlgStartButton"
+	MsgAppWindowBackupPlanDlgCancelButton         = "AppWindowBackupPlanDlgCancelButton"
+	MsgAppWindowBackupPlanRejected                = "AppWindowBackupPlanRejected"
+
+	MsgAppWindowSessionCommentDlgTitle       = "AppWindowSessionCommentDlgTitle"
+	MsgAppWindowSessionCommentDlgText        = "AppWindowSessionCommentDlgText"
+	MsgAppWindowSessionCommentDlgPlaceholder = "AppWindowSessionCommentDlgPlaceholder"
+	MsgAppWindowSessionCommentDlgSaveButton  = "AppWindowSessionCommentDlgSaveButton"
+	MsgAppWindowSessionCommentDlgSkipButton  = "AppWindowSessionCommentDlgSkipButton"
+	MsgAppWindowSessionCommentSaved          = "AppWindowSessionCommentSaved"
+	MsgAppWindowSessionCommentError          = "AppWindowSessionCommentError"
+
+	MsgAppWindowPlanInterruptedDlgTitle           = "AppWindowPlanInterruptedDlgTitle"
+	MsgAppWindowPlanInterruptedDlgText1           = "AppWindowPlanInterruptedDlgText1"
+	MsgAppWindowPlanInterruptedDlgText2           = "AppWindowPlanInterruptedDlgText2"
+	MsgAppWindowPlanInterruptedDlgProceedButton   = "AppWindowPlanInterruptedDlgProceedButton"
+	MsgAppWindowPlanInterruptedDlgResumeButton    = "AppWindowPlanInterruptedDlgResumeButton"
+	MsgAppWindowPlanInterruptedDlgTerminateButton = "AppWindowPlanInterruptedDlgTerminateButton"
+	MsgAppWindowPartialPlanAccepted               = "AppWindowPartialPlanAccepted"
+
+	MsgAppWindowCrashDlgTitle        = "AppWindowCrashDlgTitle"
+	MsgAppWindowCrashDlgText1        = "AppWindowCrashDlgText1"
+	MsgAppWindowCrashDlgText2        = "AppWindowCrashDlgText2"
+	MsgAppWindowCrashDlgSaveFailed   = "AppWindowCrashDlgSaveFailed"
+	MsgAppWindowCrashDlgOpenButton   = "AppWindowCrashDlgOpenButton"
+	MsgAppWindowCrashDlgReportButton = "AppWindowCrashDlgReportButton"
+	MsgAppWindowCrashDlgCloseButton  = "AppWindowCrashDlgCloseButton"
+
+	MsgAppWindowDiagnosticsSaveDlgTitle        = "AppWindowDiagnosticsSaveDlgTitle"
+	MsgAppWindowDiagnosticsErrorTitle          = "AppWindowDiagnosticsErrorTitle"
+	MsgAppWindowDiagnosticsDlgTitle            = "AppWindowDiagnosticsDlgTitle"
+	MsgAppWindowDiagnosticsDlgText1            = "AppWindowDiagnosticsDlgText1"
+	MsgAppWindowDiagnosticsDlgText2            = "AppWindowDiagnosticsDlgText2"
+	MsgAppWindowDiagnosticsDlgOpenFolderButton = "AppWindowDiagnosticsDlgOpenFolderButton"
+	MsgAppWindowDiagnosticsDlgCloseButton      = "AppWindowDiagnosticsDlgCloseButton"
+
+	MsgAppWindowErrorBarTitle = "AppWindowErrorBarTitle"
+
+	MsgAppWindowUpdateBarNewVersion         = "AppWindowUpdateBarNewVersion"
+	MsgAppWindowUpdateBarReleaseNotesButton = "AppWindowUpdateBarReleaseNotesButton"
+
+	MsgAppWindowSessionLogTruncatedButton = "AppWindowSessionLogTruncatedButton"
+	MsgAppWindowSessionLogTruncatedHint   = "AppWindowSessionLogTruncatedHint"
+
 	MsgAppWindowRsyncUtilityDlgTitle         = "AppWindowRsyncUtilityDlgTitle"
 	MsgAppWindowRsyncUtilityDlgNotFoundError = "AppWindowRsyncUtilityDlgNotFoundError"
 
@@ -229,6 +594,182 @@ const (
 	MsgAppWindowNotificationScriptExecutableError = "AppWindowNotificationScriptExecutableError"
 	MsgAppWindowGetExecutableScriptInfoError      = "AppWindowGetExecutableScriptInfoError"
 
+	MsgAppWindowMountDestinationError   = "AppWindowMountDestinationError"
+	MsgAppWindowUnmountDestinationError = "AppWindowUnmountDestinationError"
+
+	MsgAppWindowMetricsEndpointError = "AppWindowMetricsEndpointError"
+
+	MsgCheckProfileDlgTitlePassed          = "CheckProfileDlgTitlePassed"
+	MsgCheckProfileDlgTitleWarning         = "CheckProfileDlgTitleWarning"
+	MsgCheckProfileDlgTitleFailed          = "CheckProfileDlgTitleFailed"
+	MsgCheckProfileDlgStatusPassed         = "CheckProfileDlgStatusPassed"
+	MsgCheckProfileDlgStatusWarning        = "CheckProfileDlgStatusWarning"
+	MsgCheckProfileDlgStatusFailed         = "CheckProfileDlgStatusFailed"
+	MsgCheckProfileDlgCopyJSONButton       = "CheckProfileDlgCopyJSONButton"
+	MsgCheckProfileSchemaInstalled         = "CheckProfileSchemaInstalled"
+	MsgCheckProfileNotificationScriptReady = "CheckProfileNotificationScriptReady"
+
+	MsgTestDestinationDlgTitlePassed  = "TestDestinationDlgTitlePassed"
+	MsgTestDestinationDlgTitleWarning = "TestDestinationDlgTitleWarning"
+	MsgTestDestinationDlgTitleFailed  = "TestDestinationDlgTitleFailed"
+
+	MsgVerifySessionSelectFolderTitle = "VerifySessionSelectFolderTitle"
+	MsgVerifySessionDlgTitlePassed    = "VerifySessionDlgTitlePassed"
+	MsgVerifySessionDlgTitleFailed    = "VerifySessionDlgTitleFailed"
+	MsgAppWindowTestDestinationHint   = "AppWindowTestDestinationHint"
+	MsgAppWindowDestHistoryHint       = "AppWindowDestHistoryHint"
+	MsgAppWindowDestHistoryEmpty      = "AppWindowDestHistoryEmpty"
+	MsgAppWindowDriveBackupDlgTitle   = "AppWindowDriveBackupDlgTitle"
+	MsgAppWindowDriveBackupDlgText    = "AppWindowDriveBackupDlgText"
+	MsgAppWindowHostKeyDlgTitle       = "AppWindowHostKeyDlgTitle"
+	MsgAppWindowHostKeyDlgText        = "AppWindowHostKeyDlgText"
+	MsgAppWindowHostKeyScanDlgTitle   = "AppWindowHostKeyScanDlgTitle"
+
+	MsgLogViewerWindowCaption     = "LogViewerWindowCaption"
+	MsgLogViewerSessionCaption    = "LogViewerSessionCaption"
+	MsgLogViewerSessionHint       = "LogViewerSessionHint"
+	MsgLogViewerSearchPlaceholder = "LogViewerSearchPlaceholder"
+	MsgLogViewerSearchHint        = "LogViewerSearchHint"
+	MsgLogViewerNoLogsFound       = "LogViewerNoLogsFound"
+	MsgLogViewerLoadError         = "LogViewerLoadError"
+
+	MsgAppWindowLogSearchHint            = "AppWindowLogSearchHint"
+	MsgAppWindowLogSearchPrevHint        = "AppWindowLogSearchPrevHint"
+	MsgAppWindowLogSearchNextHint        = "AppWindowLogSearchNextHint"
+	MsgAppWindowLogFilterWarningsCaption = "AppWindowLogFilterWarningsCaption"
+	MsgAppWindowLogFilterWarningsHint    = "AppWindowLogFilterWarningsHint"
+	MsgAppWindowLogFilterErrorsCaption   = "AppWindowLogFilterErrorsCaption"
+	MsgAppWindowLogFilterErrorsHint      = "AppWindowLogFilterErrorsHint"
+	MsgAppWindowLogCopyHint              = "AppWindowLogCopyHint"
+	MsgAppWindowLogSaveHint              = "AppWindowLogSaveHint"
+	MsgAppWindowLogKeepColorsCaption     = "AppWindowLogKeepColorsCaption"
+	MsgAppWindowLogKeepColorsHint        = "AppWindowLogKeepColorsHint"
+	MsgAppWindowLogSaveDlgTitle          = "AppWindowLogSaveDlgTitle"
+	MsgAppWindowLogSaveErrorTitle        = "AppWindowLogSaveErrorTitle"
+
+	MsgCatalogSearchWindowCaption     = "CatalogSearchWindowCaption"
+	MsgCatalogSearchDestCaption       = "CatalogSearchDestCaption"
+	MsgCatalogSearchDestHint          = "CatalogSearchDestHint"
+	MsgCatalogSearchPlaceholder       = "CatalogSearchPlaceholder"
+	MsgCatalogSearchHint              = "CatalogSearchHint"
+	MsgCatalogSearchSessionColumn     = "CatalogSearchSessionColumn"
+	MsgCatalogSearchPathColumn        = "CatalogSearchPathColumn"
+	MsgCatalogSearchSizeColumn        = "CatalogSearchSizeColumn"
+	MsgCatalogSearchModifiedColumn    = "CatalogSearchModifiedColumn"
+	MsgCatalogSearchCommentColumn     = "CatalogSearchCommentColumn"
+	MsgCatalogSearchResultsHint       = "CatalogSearchResultsHint"
+	MsgCatalogSearchInProgress        = "CatalogSearchInProgress"
+	MsgCatalogSearchFilesFound        = "CatalogSearchFilesFound"
+	MsgCatalogSearchNothingFound      = "CatalogSearchNothingFound"
+	MsgCatalogSearchError             = "CatalogSearchError"
+	MsgCatalogSearchRestoreButton     = "CatalogSearchRestoreButton"
+	MsgCatalogSearchRestoreHint       = "CatalogSearchRestoreHint"
+	MsgCatalogSearchRestoreTitle      = "CatalogSearchRestoreTitle"
+	MsgCatalogSearchRestoreInProgress = "CatalogSearchRestoreInProgress"
+	MsgCatalogSearchRestoreDone       = "CatalogSearchRestoreDone"
+	MsgCatalogSearchRestoreError      = "CatalogSearchRestoreError"
+
+	MsgDaemonModulesDlgTitle        = "DaemonModulesDlgTitle"
+	MsgDaemonModulesHostPlaceholder = "DaemonModulesHostPlaceholder"
+	MsgDaemonModulesHostHint        = "DaemonModulesHostHint"
+	MsgDaemonModulesQueryHint       = "DaemonModulesQueryHint"
+	MsgDaemonModulesNameColumn      = "DaemonModulesNameColumn"
+	MsgDaemonModulesCommentColumn   = "DaemonModulesCommentColumn"
+	MsgDaemonModulesQueryInProgress = "DaemonModulesQueryInProgress"
+	MsgDaemonModulesFound           = "DaemonModulesFound"
+	MsgDaemonModulesNothingFound    = "DaemonModulesNothingFound"
+	MsgDaemonModulesQueryError      = "DaemonModulesQueryError"
+	MsgDaemonModulesSelectButton    = "DaemonModulesSelectButton"
+	MsgDaemonModulesCancelButton    = "DaemonModulesCancelButton"
+
+	MsgSessionListWindowCaption   = "SessionListWindowCaption"
+	MsgSessionListDestCaption     = "SessionListDestCaption"
+	MsgSessionListDestHint        = "SessionListDestHint"
+	MsgSessionListFrozenColumn    = "SessionListFrozenColumn"
+	MsgSessionListSessionColumn   = "SessionListSessionColumn"
+	MsgSessionListCompletedColumn = "SessionListCompletedColumn"
+	MsgSessionListCommentColumn   = "SessionListCommentColumn"
+	MsgSessionListHint            = "SessionListHint"
+	MsgSessionListSessionsFound   = "SessionListSessionsFound"
+	MsgSessionListNothingFound    = "SessionListNothingFound"
+	MsgSessionListError           = "SessionListError"
+	MsgSessionListFreezeError     = "SessionListFreezeError"
+	MsgSessionListCommentError    = "SessionListCommentError"
+
+	MsgBackupDaemonWindowCaption            = "BackupDaemonWindowCaption"
+	MsgBackupDaemonProfileColumn            = "BackupDaemonProfileColumn"
+	MsgBackupDaemonStateColumn              = "BackupDaemonStateColumn"
+	MsgBackupDaemonLastRunColumn            = "BackupDaemonLastRunColumn"
+	MsgBackupDaemonNextRunColumn            = "BackupDaemonNextRunColumn"
+	MsgBackupDaemonStartButton              = "BackupDaemonStartButton"
+	MsgBackupDaemonStopButton               = "BackupDaemonStopButton"
+	MsgBackupDaemonExportButton             = "BackupDaemonExportButton"
+	MsgBackupDaemonExportHint               = "BackupDaemonExportHint"
+	MsgBackupDaemonStateIdle                = "BackupDaemonStateIdle"
+	MsgBackupDaemonStateRunning             = "BackupDaemonStateRunning"
+	MsgBackupDaemonStatePostponed           = "BackupDaemonStatePostponed"
+	MsgBackupDaemonLastStatusDone           = "BackupDaemonLastStatusDone"
+	MsgBackupDaemonLastStatusDoneWithErrors = "BackupDaemonLastStatusDoneWithErrors"
+	MsgBackupDaemonLastStatusFailed         = "BackupDaemonLastStatusFailed"
+	MsgBackupDaemonLastStatusTerminated     = "BackupDaemonLastStatusTerminated"
+	MsgBackupDaemonMirrorError              = "BackupDaemonMirrorError"
+	MsgBackupDaemonProfilesFound            = "BackupDaemonProfilesFound"
+	MsgBackupDaemonNothingFound             = "BackupDaemonNothingFound"
+	MsgBackupDaemonNotAvailable             = "BackupDaemonNotAvailable"
+	MsgBackupDaemonCommandError             = "BackupDaemonCommandError"
+	MsgBackupDaemonExportInProgress         = "BackupDaemonExportInProgress"
+	MsgBackupDaemonExportDone               = "BackupDaemonExportDone"
+	MsgBackupDaemonExportError              = "BackupDaemonExportError"
+
+	MsgDiskUsageWindowCaption    = "DiskUsageWindowCaption"
+	MsgDiskUsageWindowSubcaption = "DiskUsageWindowSubcaption"
+	MsgDiskUsageFolderColumn     = "DiskUsageFolderColumn"
+	MsgDiskUsageSizeColumn       = "DiskUsageSizeColumn"
+	MsgDiskUsageBackupTypeColumn = "DiskUsageBackupTypeColumn"
+	MsgDiskUsageSkipFolderButton = "DiskUsageSkipFolderButton"
+	MsgDiskUsageSkipFolderHint   = "DiskUsageSkipFolderHint"
+	MsgDiskUsageSkipFolderError  = "DiskUsageSkipFolderError"
+	MsgDiskUsageSkipFolderDone   = "DiskUsageSkipFolderDone"
+
+	MsgIgnoreSignatureWindowCaption     = "IgnoreSignatureWindowCaption"
+	MsgIgnoreSignatureWindowSubcaption  = "IgnoreSignatureWindowSubcaption"
+	MsgIgnoreSignatureSourceColumn      = "IgnoreSignatureSourceColumn"
+	MsgIgnoreSignatureFolderColumn      = "IgnoreSignatureFolderColumn"
+	MsgIgnoreSignatureSizeColumn        = "IgnoreSignatureSizeColumn"
+	MsgIgnoreSignatureStateColumn       = "IgnoreSignatureStateColumn"
+	MsgIgnoreSignatureStateSkipped      = "IgnoreSignatureStateSkipped"
+	MsgIgnoreSignatureStateRemoved      = "IgnoreSignatureStateRemoved"
+	MsgIgnoreSignatureFolderPlaceholder = "IgnoreSignatureFolderPlaceholder"
+	MsgIgnoreSignatureFolderHint        = "IgnoreSignatureFolderHint"
+	MsgIgnoreSignatureAddButton         = "IgnoreSignatureAddButton"
+	MsgIgnoreSignatureRemoveButton      = "IgnoreSignatureRemoveButton"
+	MsgIgnoreSignatureRemoveHint        = "IgnoreSignatureRemoveHint"
+	MsgIgnoreSignatureReestimateButton  = "IgnoreSignatureReestimateButton"
+	MsgIgnoreSignatureReestimateHint    = "IgnoreSignatureReestimateHint"
+	MsgIgnoreSignatureAdded             = "IgnoreSignatureAdded"
+	MsgIgnoreSignatureRemoved           = "IgnoreSignatureRemoved"
+
+	MsgFirstRunWizardTitle                = "FirstRunWizardTitle"
+	MsgFirstRunWizardNamePageTitle        = "FirstRunWizardNamePageTitle"
+	MsgFirstRunWizardNamePageText         = "FirstRunWizardNamePageText"
+	MsgFirstRunWizardDefaultProfileName   = "FirstRunWizardDefaultProfileName"
+	MsgFirstRunWizardSourcesPageTitle     = "FirstRunWizardSourcesPageTitle"
+	MsgFirstRunWizardSourcesPageText      = "FirstRunWizardSourcesPageText"
+	MsgFirstRunWizardSourcePlaceholder    = "FirstRunWizardSourcePlaceholder"
+	MsgFirstRunWizardAddSourceButton      = "FirstRunWizardAddSourceButton"
+	MsgFirstRunWizardRemoveSourceHint     = "FirstRunWizardRemoveSourceHint"
+	MsgFirstRunWizardSourceValidating     = "FirstRunWizardSourceValidating"
+	MsgFirstRunWizardDestPageTitle        = "FirstRunWizardDestPageTitle"
+	MsgFirstRunWizardDestPageText         = "FirstRunWizardDestPageText"
+	MsgFirstRunWizardDefaultsPageTitle    = "FirstRunWizardDefaultsPageTitle"
+	MsgFirstRunWizardDefaultsPageText     = "FirstRunWizardDefaultsPageText"
+	MsgFirstRunWizardDeduplicationCaption = "FirstRunWizardDeduplicationCaption"
+	MsgFirstRunWizardConfirmPageTitle     = "FirstRunWizardConfirmPageTitle"
+	MsgFirstRunWizardConfirmPageText      = "FirstRunWizardConfirmPageText"
+	MsgFirstRunWizardSummaryProfile       = "FirstRunWizardSummaryProfile"
+	MsgFirstRunWizardSummarySource        = "FirstRunWizardSummarySource"
+	MsgFirstRunWizardSummaryDestination   = "FirstRunWizardSummaryDestination"
+
 	MsgLogBackupStageOutOfSpaceWarning = "LogBackupStageOutOfSpaceWarning"
 
 	MsgGeneralHintStatusCaption      = "GeneralHintStatusCaption"
@@ -242,4 +783,19 @@ const (
 	MsgDesktopNotificationSkippedSize                 = "DesktopNotificationSkippedSize"
 	MsgDesktopNotificationFailedToBackupSize          = "DesktopNotificationFailedToBackupSize"
 	MsgDesktopNotificationTimeTaken                   = "DesktopNotificationTimeTaken"
+	MsgDesktopNotificationErrorReason                 = "DesktopNotificationErrorReason"
+	MsgDesktopNotificationOpenFolderAction            = "DesktopNotificationOpenFolderAction"
+	MsgDesktopNotificationViewLogAction               = "DesktopNotificationViewLogAction"
+
+	MsgHiddenSettingsWarning           = "HiddenSettingsWarning"
+	MsgHiddenSettingsFilterPlaceholder = "HiddenSettingsFilterPlaceholder"
+	MsgHiddenSettingsKeyColumn         = "HiddenSettingsKeyColumn"
+	MsgHiddenSettingsTypeColumn        = "HiddenSettingsTypeColumn"
+	MsgHiddenSettingsValueColumn       = "HiddenSettingsValueColumn"
+	MsgHiddenSettingsResetButton       = "HiddenSettingsResetButton"
+	MsgHiddenSettingsResetHint         = "HiddenSettingsResetHint"
+	MsgHiddenSettingsValueError        = "HiddenSettingsValueError"
+	MsgHiddenSettingsParseError        = "HiddenSettingsParseError"
+	MsgHiddenSettingsOutOfRangeError   = "HiddenSettingsOutOfRangeError"
+	MsgHiddenSettingsNotWritableError  = "HiddenSettingsNotWritableError"
 )
@@ -0,0 +1,305 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package gtkui
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/d2r2/go-rsync/backup"
+	"github.com/d2r2/go-rsync/locale"
+)
+
+// METRICS_PATH is HTTP path, where metrics are served in Prometheus text format.
+const METRICS_PATH = "/metrics"
+
+// profileMetrics keep backup sessions statistics of single profile.
+type profileMetrics struct {
+	// Profile name, as it was at last session start.
+	name          string
+	running       bool
+	lastEnd       time.Time
+	lastSuccess   time.Time
+	lastDuration  time.Duration
+	lastBytes     uint64
+	lastSucceeded bool
+	bytesTotal    uint64
+	failuresTotal int
+	sessionsTotal map[string]int
+}
+
+// MetricsServer collect backup sessions statistics of running application
+// and expose them via HTTP endpoint, so monitoring systems could alert
+// on stale or failing backups. Statistics are collected in memory,
+// and get lost on application restart. Profiles are indexed by ID,
+// so statistics survive profile rename.
+type MetricsServer struct {
+	sync.Mutex
+	startTime time.Time
+	profiles  map[string]*profileMetrics
+	server    *http.Server
+	address   string
+}
+
+// Global object to collect and serve metrics, initialized with main window.
+var metricsServer = NewMetricsServer()
+
+// NewMetricsServer create metrics collector with HTTP endpoint stopped.
+func NewMetricsServer() *MetricsServer {
+	v := &MetricsServer{startTime: time.Now(), profiles: make(map[string]*profileMetrics)}
+	return v
+}
+
+func (v *MetricsServer) getProfile(identity backup.ProfileIdentity) *profileMetrics {
+	profile, ok := v.profiles[identity.ID]
+	if !ok {
+		profile = &profileMetrics{sessionsTotal: make(map[string]int)}
+		v.profiles[identity.ID] = profile
+	}
+	profile.name = identity.String()
+	return profile
+}
+
+// SessionStarted register backup session start.
+func (v *MetricsServer) SessionStarted(identity backup.ProfileIdentity) {
+	v.Lock()
+	defer v.Unlock()
+	v.getProfile(identity).running = true
+}
+
+// SessionCompleted register backup session completion.
+// Progress might be nil, if session failed at early stage.
+func (v *MetricsServer) SessionCompleted(identity backup.ProfileIdentity,
+	completionType BackupCompletionType, backupProgress *backup.Progress) {
+
+	v.Lock()
+	defer v.Unlock()
+	profile := v.getProfile(identity)
+	profile.running = false
+	status := getCompletionStatus(completionType)
+	profile.sessionsTotal[status]++
+	if completionType == BackupTerminated {
+		return
+	}
+	profile.lastEnd = time.Now()
+	profile.lastSucceeded = completionType == BackupSucessfullyCompleted
+	if profile.lastSucceeded {
+		profile.lastSuccess = profile.lastEnd
+	}
+	if completionType == BackupFailed {
+		profile.failuresTotal++
+	}
+	profile.lastDuration = 0
+	profile.lastBytes = 0
+	if backupProgress != nil {
+		profile.lastDuration = backupProgress.GetTotalTimeTaken()
+		if backupProgress.TotalProgress != nil && backupProgress.TotalProgress.Completed != nil {
+			profile.lastBytes = backupProgress.TotalProgress.Completed.GetByteCount()
+		}
+	}
+	profile.bytesTotal += profile.lastBytes
+}
+
+// Start run HTTP endpoint on address (host:port), if not yet started.
+// Endpoint is restarted, if address changed.
+func (v *MetricsServer) Start(address string) error {
+	v.Lock()
+	defer v.Unlock()
+	if v.server != nil {
+		if v.address == address {
+			return nil
+		}
+		v.stop()
+	}
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(METRICS_PATH, v.serveMetrics)
+	server := &http.Server{Handler: mux}
+	go func() {
+		err := server.Serve(listener)
+		if err != nil && err != http.ErrServerClosed {
+			lg.Warnf("Metrics endpoint stopped: %v", err)
+		}
+	}()
+	v.server = server
+	v.address = address
+	lg.Debugf("Metrics endpoint started at http://%s%s", address, METRICS_PATH)
+	return nil
+}
+
+// Stop shut down HTTP endpoint, if started.
+func (v *MetricsServer) Stop() {
+	v.Lock()
+	defer v.Unlock()
+	v.stop()
+}
+
+func (v *MetricsServer) stop() {
+	if v.server != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		err := v.server.Shutdown(ctx)
+		if err != nil {
+			lg.Warnf("Can't stop metrics endpoint: %v", err)
+		}
+		v.server = nil
+		v.address = ""
+	}
+}
+
+func (v *MetricsServer) serveMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, err := w.Write(v.format())
+	if err != nil {
+		lg.Debugf("Can't write metrics: %v", err)
+	}
+}
+
+// format output metrics in Prometheus text exposition format.
+func (v *MetricsServer) format() []byte {
+	v.Lock()
+	defer v.Unlock()
+
+	var ids []string
+	for id := range v.profiles {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	profileLabels := func(id string) string {
+		return formatMetricLabel("profile", v.profiles[id].name) + "," +
+			formatMetricLabel("profile_id", id)
+	}
+
+	var buf bytes.Buffer
+	writeHeader := func(name, metricType, help string) {
+		buf.WriteString(fmt.Sprintf("# HELP %s %s\n", name, help))
+		buf.WriteString(fmt.Sprintf("# TYPE %s %s\n", name, metricType))
+	}
+	writeValue := func(name, labels string, value interface{}) {
+		buf.WriteString(fmt.Sprintf("%s{%s} %v\n", name, labels, value))
+	}
+	eachProfile := func(name, metricType, help string,
+		value func(profile *profileMetrics) (interface{}, bool)) {
+
+		writeHeader(name, metricType, help)
+		for _, id := range ids {
+			if val, ok := value(v.profiles[id]); ok {
+				writeValue(name, profileLabels(id), val)
+			}
+		}
+	}
+
+	writeHeader("gorsync_start_time_seconds", "gauge",
+		"Application start time since unix epoch in seconds.")
+	buf.WriteString(fmt.Sprintf("gorsync_start_time_seconds %d\n", v.startTime.Unix()))
+
+	eachProfile("gorsync_backup_running", "gauge",
+		"Whether backup session is running now.",
+		func(profile *profileMetrics) (interface{}, bool) {
+			if profile.running {
+				return 1, true
+			}
+			return 0, true
+		})
+	eachProfile("gorsync_last_backup_timestamp_seconds", "gauge",
+		"Completion time of the last backup session since unix epoch in seconds.",
+		func(profile *profileMetrics) (interface{}, bool) {
+			return profile.lastEnd.Unix(), !profile.lastEnd.IsZero()
+		})
+	eachProfile("gorsync_last_success_timestamp_seconds", "gauge",
+		"Completion time of the last successful backup session since unix epoch in seconds.",
+		func(profile *profileMetrics) (interface{}, bool) {
+			return profile.lastSuccess.Unix(), !profile.lastSuccess.IsZero()
+		})
+	eachProfile("gorsync_last_backup_success", "gauge",
+		"Whether the last backup session completed without errors.",
+		func(profile *profileMetrics) (interface{}, bool) {
+			if profile.lastSucceeded {
+				return 1, !profile.lastEnd.IsZero()
+			}
+			return 0, !profile.lastEnd.IsZero()
+		})
+	eachProfile("gorsync_last_backup_duration_seconds", "gauge",
+		"Duration of the last backup session in seconds.",
+		func(profile *profileMetrics) (interface{}, bool) {
+			return fmt.Sprintf("%.0f", profile.lastDuration.Seconds()), !profile.lastEnd.IsZero()
+		})
+	eachProfile("gorsync_last_backup_bytes", "gauge",
+		"Size backed up in the last backup session in bytes.",
+		func(profile *profileMetrics) (interface{}, bool) {
+			return profile.lastBytes, !profile.lastEnd.IsZero()
+		})
+	eachProfile("gorsync_backup_bytes_total", "counter",
+		"Total size backed up since application start in bytes.",
+		func(profile *profileMetrics) (interface{}, bool) {
+			return profile.bytesTotal, true
+		})
+	eachProfile("gorsync_backup_failures_total", "counter",
+		"Number of failed backup sessions since application start.",
+		func(profile *profileMetrics) (interface{}, bool) {
+			return profile.failuresTotal, true
+		})
+
+	writeHeader("gorsync_backup_sessions_total", "counter",
+		"Number of backup sessions since application start by completion status.")
+	for _, id := range ids {
+		profile := v.profiles[id]
+		var statuses []string
+		for status := range profile.sessionsTotal {
+			statuses = append(statuses, status)
+		}
+		sort.Strings(statuses)
+		for _, status := range statuses {
+			writeValue("gorsync_backup_sessions_total",
+				profileLabels(id)+","+formatMetricLabel("status", status),
+				profile.sessionsTotal[status])
+		}
+	}
+	return buf.Bytes()
+}
+
+var metricLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// formatMetricLabel return label pair with value escaped.
+func formatMetricLabel(name, value string) string {
+	return fmt.Sprintf(`%s="%s"`, name, metricLabelEscaper.Replace(value))
+}
+
+// updateMetricsServer start or stop metrics endpoint according to preferences.
+// Endpoint is active only when application keep running in tray.
+func updateMetricsServer(appSettings *SettingsStore) {
+	if appSettings.settings.GetBoolean(CFG_ENABLE_TRAY_ICON) &&
+		appSettings.settings.GetBoolean(CFG_METRICS_ENDPOINT_ENABLED) {
+
+		address := strings.TrimSpace(appSettings.settings.GetString(CFG_METRICS_LISTEN_ADDRESS))
+		err := metricsServer.Start(address)
+		if err != nil {
+			lg.Warn(locale.T(MsgAppWindowMetricsEndpointError,
+				struct {
+					Address string
+					Error   error
+				}{Address: address, Error: err}))
+		}
+	} else {
+		metricsServer.Stop()
+	}
+}
@@ -0,0 +1,126 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package gtkui
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/d2r2/go-rsync/backup"
+	"github.com/d2r2/go-rsync/rsync"
+)
+
+// moduleHealth probe reachability of modules shown in main window.
+var moduleHealth *ModuleHealthMonitor
+
+// ModuleHealthMonitor periodically probe reachability of selected profile
+// modules in background, reporting results to module list of main window,
+// so unreachable sources are noticed before backup session is started.
+type ModuleHealthMonitor struct {
+	sync.Mutex
+	parent context.Context
+	// Return true, while probes should be skipped (backup session is running).
+	busy     func() bool
+	modules  []backup.Module
+	interval time.Duration
+	cancel   func()
+}
+
+// NewModuleHealthMonitor create monitor, which is idle until
+// both modules and probe interval are specified.
+func NewModuleHealthMonitor(parent context.Context, busy func() bool) *ModuleHealthMonitor {
+	v := &ModuleHealthMonitor{parent: parent, busy: busy}
+	return v
+}
+
+// SetModules replace modules to probe, restarting probes immediately.
+func (v *ModuleHealthMonitor) SetModules(modules []backup.Module) {
+	v.Lock()
+	defer v.Unlock()
+	v.modules = modules
+	v.restart()
+}
+
+// SetInterval change time between probes, 0 disable probes.
+func (v *ModuleHealthMonitor) SetInterval(interval time.Duration) {
+	v.Lock()
+	defer v.Unlock()
+	if v.interval != interval {
+		v.interval = interval
+		v.restart()
+	}
+}
+
+// Stop terminate probes.
+func (v *ModuleHealthMonitor) Stop() {
+	v.Lock()
+	defer v.Unlock()
+	if v.cancel != nil {
+		v.cancel()
+		v.cancel = nil
+	}
+}
+
+// restart cancel running probes and start new ones. Should be called under lock.
+func (v *ModuleHealthMonitor) restart() {
+	if v.cancel != nil {
+		v.cancel()
+		v.cancel = nil
+	}
+	if v.interval <= 0 || len(v.modules) == 0 {
+		return
+	}
+	pack := ForkContext(v.parent)
+	v.cancel = pack.Cancel
+	go v.run(pack.Context, v.modules, v.interval)
+}
+
+// run probe modules one by one, repeating it with interval specified.
+func (v *ModuleHealthMonitor) run(ctx context.Context, modules []backup.Module, interval time.Duration) {
+	for {
+		if !v.busy() {
+			for i := range modules {
+				probeErr := rsync.GetPathStatus(ctx, modules[i].AuthPassword, modules[i].SourceRsync, false)
+				if ctx.Err() != nil {
+					return
+				}
+				if probeErr != nil {
+					lg.Debugf("Module %q is unreachable: %v", modules[i].SourceRsync, probeErr)
+				}
+				key := getModuleKey(&modules[i])
+				MustIdleAdd(func() {
+					if ctx.Err() != nil {
+						return
+					}
+					err := moduleSelector.SetModuleHealth(key, probeErr)
+					if err != nil {
+						lg.Fatal(err)
+					}
+				})
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// updateModuleHealthInterval apply probe interval from preferences.
+func updateModuleHealthInterval(appSettings *SettingsStore) {
+	if moduleHealth != nil {
+		minutes := appSettings.settings.GetInt(CFG_MODULE_HEALTH_CHECK_INTERVAL_MIN)
+		moduleHealth.SetInterval(time.Duration(minutes) * time.Minute)
+	}
+}
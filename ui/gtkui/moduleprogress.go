@@ -0,0 +1,156 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package gtkui
+
+import (
+	"github.com/d2r2/go-rsync/core"
+	"github.com/d2r2/go-rsync/locale"
+	"github.com/d2r2/gotk3/gtk"
+	"github.com/d2r2/gotk3/pango"
+)
+
+// ModuleProgressState signify backup state of single RSYNC source.
+type ModuleProgressState int
+
+const (
+	ModuleProgressPending ModuleProgressState = iota
+	ModuleProgressRunning
+	ModuleProgressDone
+	ModuleProgressError
+)
+
+// String return localized description of the state.
+func (v ModuleProgressState) String() string {
+	switch v {
+	case ModuleProgressRunning:
+		return locale.T(MsgAppWindowModuleProgressRunning, nil)
+	case ModuleProgressDone:
+		return locale.T(MsgAppWindowModuleProgressDone, nil)
+	case ModuleProgressError:
+		return locale.T(MsgAppWindowModuleProgressError, nil)
+	default:
+		return locale.T(MsgAppWindowModuleProgressPending, nil)
+	}
+}
+
+// ModuleProgress keep state and widgets, which
+// display backup progress of single RSYNC source.
+type ModuleProgress struct {
+	sourceRsync string
+	totalSize   core.FolderSize
+	doneSize    core.FolderSize
+	state       ModuleProgressState
+	// GUI GTK widgets
+	stateLabel  *gtk.Label
+	sizeLabel   *gtk.Label
+	progressBar *gtk.ProgressBar
+}
+
+// ModuleProgressSnapshot is a copy of ModuleProgress state,
+// which safely passed from backup goroutine to GUI thread.
+type ModuleProgressSnapshot struct {
+	TotalSize core.FolderSize
+	DoneSize  core.FolderSize
+	State     ModuleProgressState
+}
+
+// snapshot take a copy of current state.
+func (v *ModuleProgress) snapshot() ModuleProgressSnapshot {
+	return ModuleProgressSnapshot{TotalSize: v.totalSize,
+		DoneSize: v.doneSize, State: v.state}
+}
+
+// addSizeProgress account size processed, switch to error
+// state if any piece of data failed to backup.
+func (v *ModuleProgress) addSizeProgress(sizeDone core.SizeProgress) {
+	v.doneSize = v.doneSize.AddSizeProgress(sizeDone)
+	if sizeDone.Failed != nil {
+		v.state = ModuleProgressError
+	}
+}
+
+// createWidgets attach row of widgets to the grid. Should be called from GUI thread.
+func (v *ModuleProgress) createWidgets(grid *gtk.Grid, row int) error {
+	lbl, err := SetupLabelJustifyLeft(v.sourceRsync)
+	if err != nil {
+		return err
+	}
+	lbl.SetEllipsize(pango.ELLIPSIZE_MIDDLE)
+	lbl.SetMaxWidthChars(40)
+	lbl.SetTooltipText(v.sourceRsync)
+	grid.Attach(lbl, 0, row, 1, 1)
+	v.stateLabel, err = SetupLabelJustifyLeft("")
+	if err != nil {
+		return err
+	}
+	grid.Attach(v.stateLabel, 1, row, 1, 1)
+	v.sizeLabel, err = SetupLabelJustifyRight("")
+	if err != nil {
+		return err
+	}
+	grid.Attach(v.sizeLabel, 2, row, 1, 1)
+	v.progressBar, err = gtk.ProgressBarNew()
+	if err != nil {
+		return err
+	}
+	v.progressBar.SetHExpand(true)
+	v.progressBar.SetVAlign(gtk.ALIGN_CENTER)
+	grid.Attach(v.progressBar, 3, row, 1, 1)
+	grid.ShowAll()
+	return nil
+}
+
+// updateWidgets reflect state in widgets. Should be called from GUI thread.
+func (v *ModuleProgress) updateWidgets(snapshot ModuleProgressSnapshot) {
+	if v.stateLabel == nil {
+		return
+	}
+	v.stateLabel.SetText(snapshot.State.String())
+	// Total size is unknown, if plan stage skipped for the source.
+	if snapshot.TotalSize > 0 {
+		v.sizeLabel.SetText(locale.T(MsgAppWindowModuleProgressSize,
+			struct{ DoneSize, TotalSize string }{
+				DoneSize:  core.GetReadableSize(snapshot.DoneSize),
+				TotalSize: core.GetReadableSize(snapshot.TotalSize)}))
+	} else {
+		v.sizeLabel.SetText(core.GetReadableSize(snapshot.DoneSize))
+	}
+	var fraction float64
+	if snapshot.State == ModuleProgressDone {
+		fraction = 1
+	} else if snapshot.TotalSize > 0 {
+		fraction = float64(snapshot.DoneSize) / float64(snapshot.TotalSize)
+		if fraction > 1 {
+			fraction = 1
+		}
+	}
+	v.progressBar.SetFraction(fraction)
+}
+
+// createModulesProgressControls create expander
+// with per source progress breakdown.
+func createModulesProgressControls() (*gtk.Expander, *gtk.Grid, error) {
+	exp, err := gtk.ExpanderNew(locale.T(MsgAppWindowModulesProgressCaption, nil))
+	if err != nil {
+		return nil, nil, err
+	}
+	exp.SetTooltipText(locale.T(MsgAppWindowModulesProgressHint, nil))
+	grid, err := gtk.GridNew()
+	if err != nil {
+		return nil, nil, err
+	}
+	grid.SetColumnSpacing(12)
+	grid.SetRowSpacing(3)
+	SetMargins(grid, 18, 3, 0, 3)
+	exp.Add(grid)
+	return exp, grid, nil
+}
@@ -0,0 +1,313 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package gtkui
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/d2r2/go-rsync/backup"
+	"github.com/d2r2/go-rsync/locale"
+	"github.com/d2r2/gotk3/gtk"
+)
+
+// moduleSelector is a module list of main window.
+var moduleSelector *ModuleSelector
+
+// ModuleSelector is an expandable list of profile modules with check boxes
+// in the main window, to temporarily exclude modules from backup session
+// without profile configuration change. Modules might be selected by tags
+// as well. Selection is reset to all modules once another profile is selected.
+type ModuleSelector struct {
+	sync.Mutex
+	expander *gtk.Expander
+	box      *gtk.Box
+	tagBox   *gtk.Box
+	cbTags   *gtk.ComboBoxText
+	buttons  []*gtk.CheckButton
+	modules  []backup.Module
+	// Rows with check box and reachability status icon of each module.
+	rows  []*gtk.Box
+	icons []*gtk.Image
+	// Modules deselected by user, identified by getModuleKey.
+	deselected map[string]bool
+	// Last reachability probe result of modules, identified by getModuleKey.
+	health map[string]error
+	// Suppress tag filter reset, while check boxes are changed by filter.
+	applyingTags bool
+}
+
+// NewModuleSelector create module list widget.
+func NewModuleSelector() (*ModuleSelector, error) {
+	expander, err := gtk.ExpanderNew("")
+	if err != nil {
+		return nil, err
+	}
+	expander.SetTooltipText(locale.T(MsgAppWindowModuleSelectorHint, nil))
+	// Visibility is controlled by SetModules.
+	expander.SetNoShowAll(true)
+	box, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 3)
+	if err != nil {
+		return nil, err
+	}
+	box.SetMarginStart(18)
+	expander.Add(box)
+
+	tagBox, err := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 6)
+	if err != nil {
+		return nil, err
+	}
+	lbl, err := gtk.LabelNew(locale.T(MsgAppWindowModuleTagFilterCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	tagBox.PackStart(lbl, false, false, 0)
+	cbTags, err := gtk.ComboBoxTextNew()
+	if err != nil {
+		return nil, err
+	}
+	cbTags.SetTooltipText(locale.T(MsgAppWindowModuleTagFilterHint, nil))
+	tagBox.PackStart(cbTags, false, false, 0)
+	// Hidden, when profile modules are not tagged.
+	tagBox.SetNoShowAll(true)
+	box.Add(tagBox)
+
+	v := &ModuleSelector{expander: expander, box: box, tagBox: tagBox, cbTags: cbTags,
+		deselected: make(map[string]bool), health: make(map[string]error)}
+	_, err = cbTags.Connect("changed", func(cb *gtk.ComboBoxText) {
+		tag := cb.GetActiveID()
+		var tags []string
+		if tag != "" {
+			tags = []string{tag}
+		}
+		v.applyTags(tags)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// GetWidget return top widget to attach to window.
+func (v *ModuleSelector) GetWidget() *gtk.Widget {
+	return &v.expander.Widget
+}
+
+// getModuleKey identify module within profile.
+func getModuleKey(module *backup.Module) string {
+	return module.SourceRsync + "\x00" + module.DestSubPath
+}
+
+// getModuleCaption return module description shown next to check box.
+func getModuleCaption(module *backup.Module) string {
+	caption := module.SourceRsync
+	if module.DestSubPath != "" {
+		caption += " → " + module.DestSubPath
+	}
+	if len(module.Tags) > 0 {
+		caption += " [" + strings.Join(module.Tags, ", ") + "]"
+	}
+	return caption
+}
+
+// SetModules replace module list with modules of selected profile,
+// all of them selected. Should be called from GTK thread.
+func (v *ModuleSelector) SetModules(modules []backup.Module) error {
+	v.Lock()
+	defer v.Unlock()
+	for _, row := range v.rows {
+		row.Destroy()
+	}
+	v.rows = nil
+	v.buttons = nil
+	v.icons = nil
+	v.modules = modules
+	v.deselected = make(map[string]bool)
+	v.health = make(map[string]error)
+	for i := range modules {
+		key := getModuleKey(&modules[i])
+		btn, err := gtk.CheckButtonNewWithLabel(getModuleCaption(&modules[i]))
+		if err != nil {
+			return err
+		}
+		btn.SetActive(true)
+		_, err = btn.Connect("toggled", func(btn *gtk.CheckButton) {
+			v.Lock()
+			if btn.GetActive() {
+				delete(v.deselected, key)
+			} else {
+				v.deselected[key] = true
+			}
+			v.updateCaption()
+			reset := !v.applyingTags
+			v.Unlock()
+			// Manual selection doesn't match tag filter anymore.
+			if reset {
+				v.cbTags.SetActiveID("")
+			}
+		})
+		if err != nil {
+			return err
+		}
+		row, err := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 6)
+		if err != nil {
+			return err
+		}
+		row.PackStart(btn, false, false, 0)
+		// Hidden, until module reachability is probed.
+		icon, err := gtk.ImageNew()
+		if err != nil {
+			return err
+		}
+		icon.SetNoShowAll(true)
+		row.PackStart(icon, false, false, 0)
+		v.box.Add(row)
+		v.rows = append(v.rows, row)
+		v.buttons = append(v.buttons, btn)
+		v.icons = append(v.icons, icon)
+	}
+
+	v.applyingTags = true
+	v.cbTags.RemoveAll()
+	v.cbTags.Append("", locale.T(MsgAppWindowModuleTagFilterAllEntry, nil))
+	tags := backup.CollectModuleTags(modules)
+	for _, tag := range tags {
+		v.cbTags.Append(tag, tag)
+	}
+	v.cbTags.SetActiveID("")
+	v.applyingTags = false
+	v.tagBox.SetVisible(len(tags) > 0)
+
+	v.box.ShowAll()
+	v.updateCaption()
+	v.expander.SetVisible(len(modules) > 0)
+	return nil
+}
+
+// Clear remove module list, when no profile selected.
+func (v *ModuleSelector) Clear() error {
+	return v.SetModules(nil)
+}
+
+// updateCaption show number of selected modules, along with
+// number of unreachable ones, if any. Should be called under lock.
+func (v *ModuleSelector) updateCaption() {
+	total := len(v.modules)
+	unreachable := 0
+	for _, err := range v.health {
+		if err != nil {
+			unreachable++
+		}
+	}
+	if unreachable > 0 {
+		v.expander.SetLabel(locale.T(MsgAppWindowModuleSelectorUnreachableCaption,
+			struct{ Selected, Total, Unreachable int }{Selected: total - len(v.deselected),
+				Total: total, Unreachable: unreachable}))
+	} else {
+		v.expander.SetLabel(locale.T(MsgAppWindowModuleSelectorCaption,
+			struct{ Selected, Total int }{Selected: total - len(v.deselected), Total: total}))
+	}
+}
+
+// SetModuleHealth show result of module reachability probe next to
+// module check box: nil error means module is reachable.
+// Should be called from GTK thread.
+func (v *ModuleSelector) SetModuleHealth(key string, probeErr error) error {
+	v.Lock()
+	defer v.Unlock()
+	for i := range v.modules {
+		if getModuleKey(&v.modules[i]) != key {
+			continue
+		}
+		v.health[key] = probeErr
+		icon := v.icons[i]
+		if probeErr != nil {
+			icon.SetFromIconName(STOCK_NETWORK_ERROR_ICON, gtk.ICON_SIZE_BUTTON)
+			icon.SetTooltipText(locale.T(MsgAppWindowModuleUnreachableHint,
+				struct{ Error string }{Error: probeErr.Error()}))
+			err := AddStyleClass(&icon.Widget, "image-error")
+			if err != nil {
+				return err
+			}
+		} else {
+			icon.SetFromIconName(STOCK_OK_ICON, gtk.ICON_SIZE_BUTTON)
+			icon.SetTooltipText(locale.T(MsgAppWindowModuleReachableHint, nil))
+			err := RemoveStyleClass(&icon.Widget, "image-error")
+			if err != nil {
+				return err
+			}
+		}
+		icon.Show()
+		v.updateCaption()
+		break
+	}
+	return nil
+}
+
+// applyTags select modules tagged with any of tags specified,
+// or all modules if no tags specified. Return number of modules selected.
+// Should be called from GTK thread.
+func (v *ModuleSelector) applyTags(tags []string) int {
+	v.Lock()
+	if v.applyingTags {
+		v.Unlock()
+		return 0
+	}
+	v.applyingTags = true
+	var active []bool
+	count := 0
+	for i := range v.modules {
+		selected := len(tags) == 0 || v.modules[i].HasAnyTag(tags)
+		active = append(active, selected)
+		if selected {
+			count++
+		}
+	}
+	buttons := v.buttons
+	v.Unlock()
+	// Check box signal handler take lock, so it is released here.
+	for i, btn := range buttons {
+		btn.SetActive(active[i])
+	}
+	v.Lock()
+	v.applyingTags = false
+	v.Unlock()
+	return count
+}
+
+// SelectTags select modules tagged with any of tags specified,
+// when requested via command line. Should be called from GTK thread.
+func (v *ModuleSelector) SelectTags(tags []string) error {
+	if v.applyTags(tags) == 0 {
+		return errors.New(locale.T(MsgAppWindowNoModuleWithTagsError,
+			struct{ Tags string }{Tags: strings.Join(tags, ", ")}))
+	}
+	return nil
+}
+
+// FilterModules return modules selected for backup.
+// Error returned, if all modules are deselected.
+func (v *ModuleSelector) FilterModules(modules []backup.Module) ([]backup.Module, error) {
+	v.Lock()
+	defer v.Unlock()
+	var selected []backup.Module
+	for i := range modules {
+		if !v.deselected[getModuleKey(&modules[i])] {
+			selected = append(selected, modules[i])
+		}
+	}
+	if len(modules) > 0 && len(selected) == 0 {
+		return nil, errors.New(locale.T(MsgAppWindowNoModuleSelectedError, nil))
+	}
+	return selected, nil
+}
@@ -13,8 +13,11 @@ package gtkui
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"time"
@@ -35,9 +38,10 @@ import (
 // NotifierUI is an object, than bind backup process
 // notifications with application GUI controls.
 type NotifierUI struct {
-	profileName string
-	gridUI      *gtk.Grid
-	totalDone   core.FolderSize
+	profile   backup.ProfileIdentity
+	win       *gtk.ApplicationWindow
+	gridUI    *gtk.Grid
+	totalDone core.FolderSize
 	// keep overall progress percentage
 	progress *float32
 	// flag informing that backup process is finalized in asynchronous GUI controls
@@ -47,13 +51,54 @@ type NotifierUI struct {
 	statusLabel *gtk.Label
 	logTextView *gtk.TextView
 	logViewPort *gtk.Viewport
+	logSearch   *LogSearchBar
+	logLinks    *LogLinks
+	// shown, once the oldest session log lines are removed
+	logTruncated *gtk.Button
+	// batch session log and progress updates
+	updates *UpdateQueue
+	// per source progress breakdown
+	modules       []*ModuleProgress
+	currentModule *ModuleProgress
+	modulesGrid   *gtk.Grid
+	// called to retry folders failed in completed session
+	retryHandler func(sessionPath string, folder *backup.FailedFolder)
+	// keep desktop notification alive, while its actions might be invoked
+	notification *libnotify.NotifyNotification
 }
 
 // Static cast to verify that struct implement specific interface.
 var _ backup.Notifier = &NotifierUI{}
 
-func NewNotifierUI(profileName string, gridUI *gtk.Grid) *NotifierUI {
-	v := &NotifierUI{profileName: profileName, gridUI: gridUI, done: make(chan struct{})}
+// NOTIFICATION_SCRIPT_PATH is a default location of script,
+// which run on backup completion, once enabled in preferences.
+const NOTIFICATION_SCRIPT_PATH = "/etc/gorsync/notification.sh"
+
+// NOTIFICATION_SCRIPT_TEST_STATUS is a BACKUP_STATUS value passed
+// to notification script started from preferences to test it.
+const NOTIFICATION_SCRIPT_TEST_STATUS = "test"
+
+// PROGRESS_GRID_RETRY_ROW is a row of progress grid below session log,
+// where button to retry failed folders is placed.
+const PROGRESS_GRID_RETRY_ROW = 5
+
+// PROGRESS_GRID_FAILED_FOLDERS_ROW is a row of progress grid,
+// where panel with failed folders is placed.
+const PROGRESS_GRID_FAILED_FOLDERS_ROW = 6
+
+// SESSION_LOG_MAX_LINES is a number of the most recent lines kept
+// in session log widget. Older lines are removed by blocks of
+// SESSION_LOG_TRIM_LINES, full log is available in log viewer.
+const (
+	SESSION_LOG_MAX_LINES  = 10000
+	SESSION_LOG_TRIM_LINES = 1000
+)
+
+func NewNotifierUI(profile backup.ProfileIdentity, win *gtk.ApplicationWindow,
+	gridUI *gtk.Grid) *NotifierUI {
+
+	v := &NotifierUI{profile: profile, win: win, gridUI: gridUI, done: make(chan struct{})}
+	v.updates = NewUpdateQueue(v.sessionLogUpdated)
 	return v
 }
 
@@ -61,6 +106,34 @@ func (v *NotifierUI) Done() chan struct{} {
 	return v.done
 }
 
+// SetRetryHandler set function called, when user ask to retry
+// folders failed to backup in the session: either all of them
+// (folder is nil), or specific one.
+func (v *NotifierUI) SetRetryHandler(handler func(sessionPath string, folder *backup.FailedFolder)) {
+	v.retryHandler = handler
+}
+
+// addRetryButton append button to progress grid, which start
+// session to backup again folders failed in sessionPath.
+func (v *NotifierUI) addRetryButton(sessionPath string) error {
+	btn, err := gtk.ButtonNewWithLabel(locale.T(MsgAppWindowRetryFailedFoldersCaption, nil))
+	if err != nil {
+		return err
+	}
+	btn.SetTooltipText(locale.T(MsgAppWindowRetryFailedFoldersHint, nil))
+	btn.SetHAlign(gtk.ALIGN_END)
+	_, err = btn.Connect("clicked", func(btn *gtk.Button) {
+		btn.SetSensitive(false)
+		v.retryHandler(sessionPath, nil)
+	})
+	if err != nil {
+		return err
+	}
+	v.gridUI.Attach(btn, 0, PROGRESS_GRID_RETRY_ROW, 2, 1)
+	btn.Show()
+	return nil
+}
+
 func formatInqueryProgress(sourceID int, sourceRsync string) string {
 	mp := NewMarkup(0, 0, 0, nil, nil,
 		NewMarkup(MARKUP_SIZE_LARGER, 0, 0, locale.T(MsgAppWindowBackupProgressInquiringSourceID,
@@ -77,32 +150,96 @@ func (v *NotifierUI) NotifyPlanStage_NodeStructureStartInquiry(sourceID int,
 	msg := formatInqueryProgress(sourceID, sourceRsync)
 	err := v.UpdateBackupProgress(nil, msg, true)
 	if err != nil {
-		lg.Fatal(err)
+		return err
 	}
 	return nil
 }
 
 // NotifyPlanStage_NodeStructureDoneInquiry implements core.BackupNotifier interface method.
+// Add source to per source progress breakdown.
 func (v *NotifierUI) NotifyPlanStage_NodeStructureDoneInquiry(sourceID int,
 	sourceRsync string, dir *core.Dir) error {
+
+	module := &ModuleProgress{sourceRsync: sourceRsync, totalSize: dir.GetTotalSize()}
+	v.modules = append(v.modules, module)
+	row := len(v.modules) - 1
+	snapshot := module.snapshot()
+	MustIdleAdd(func() {
+		if v.modulesGrid == nil {
+			return
+		}
+		err := module.createWidgets(v.modulesGrid, row)
+		if err != nil {
+			reportError(v.win, err)
+			return
+		}
+		module.updateWidgets(snapshot)
+	})
+	return nil
+}
+
+// updateModuleProgress refresh widgets of source progress breakdown.
+func (v *NotifierUI) updateModuleProgress(module *ModuleProgress) {
+	snapshot := module.snapshot()
+	MustIdleAdd(func() {
+		module.updateWidgets(snapshot)
+	})
+}
+
+// NotifyBackupStage_NodeStartBackup implements core.BackupNotifier interface method.
+func (v *NotifierUI) NotifyBackupStage_NodeStartBackup(sourceID int,
+	sourceRsync string, totalSize core.FolderSize) error {
+
+	v.currentModule = nil
+	if sourceID < len(v.modules) {
+		v.currentModule = v.modules[sourceID]
+		v.currentModule.state = ModuleProgressRunning
+		v.updateModuleProgress(v.currentModule)
+	}
+	return nil
+}
+
+// NotifyBackupStage_NodeDoneBackup implements core.BackupNotifier interface method.
+func (v *NotifierUI) NotifyBackupStage_NodeDoneBackup(sourceID int,
+	sourceRsync string, sizeDone core.SizeProgress, sessionErr error) error {
+
+	if module := v.currentModule; module != nil {
+		if sessionErr != nil || sizeDone.Failed != nil {
+			module.state = ModuleProgressError
+		} else {
+			module.state = ModuleProgressDone
+		}
+		v.updateModuleProgress(module)
+	}
+	v.currentModule = nil
 	return nil
 }
 
 // formatBackupProgress build markup text to detail progress status.
 func formatBackupProgress(backupType core.FolderBackupType, totalDone, leftToBackup core.FolderSize,
-	timePassed time.Duration, eta *time.Duration, path string) string {
+	timePassed time.Duration, eta *backup.ETA, path string) string {
 
 	sections := 2
 	etaStr := "*"
+	// Range between optimistic and conservative estimations.
+	etaRangeStr := ""
 	if eta != nil {
-		etaStr = core.FormatDurationToDaysHoursMinsSecs(*eta, true, &sections)
+		etaStr = core.FormatDurationToDaysHoursMinsSecs(eta.Expected, true, &sections)
+		optimistic := core.FormatDurationToDaysHoursMinsSecs(eta.Optimistic, true, &sections)
+		conservative := core.FormatDurationToDaysHoursMinsSecs(eta.Conservative, true, &sections)
+		if optimistic != conservative {
+			etaRangeStr = " " + locale.T(MsgAppWindowBackupProgressETARange,
+				struct{ Optimistic, Conservative string }{Optimistic: optimistic,
+					Conservative: conservative})
+		}
 	}
 	passedStr := core.FormatDurationToDaysHoursMinsSecs(timePassed, true, &sections)
 	mp := NewMarkup(0, 0, 0, nil, nil,
 		NewMarkup(MARKUP_SIZE_LARGER, 0, 0, passedStr, " "),
 		NewMarkup(0, 0, 0, locale.T(MsgAppWindowBackupProgressTimePassedSuffix, nil), " | "),
 		NewMarkup(MARKUP_SIZE_LARGER, 0, 0, etaStr, " "),
-		NewMarkup(0, 0, 0, locale.T(MsgAppWindowBackupProgressETASuffix, nil), "\n"),
+		NewMarkup(0, 0, 0, locale.T(MsgAppWindowBackupProgressETASuffix, nil), nil),
+		NewMarkup(MARKUP_SIZE_SMALLER, 0, 0, etaRangeStr, "\n"),
 		NewMarkup(MARKUP_SIZE_LARGER, 0, 0, core.GetReadableSize(totalDone), " "),
 		NewMarkup(0, 0, 0, locale.T(MsgAppWindowBackupProgressSizeCompletedSuffix, nil), " | "),
 		NewMarkup(MARKUP_SIZE_LARGER, 0, 0, core.GetReadableSize(leftToBackup), " "),
@@ -118,7 +255,7 @@ func formatBackupProgress(backupType core.FolderBackupType, totalDone, leftToBac
 func (v *NotifierUI) NotifyBackupStage_FolderStartBackup(rootDest string,
 	paths core.SrcDstPath, backupType core.FolderBackupType,
 	leftToBackup core.FolderSize,
-	timePassed time.Duration, eta *time.Duration) error {
+	timePassed time.Duration, eta *backup.ETA) error {
 
 	path, err := core.GetRelativePath(rootDest, paths.DestPath)
 	if err != nil {
@@ -128,10 +265,6 @@ func (v *NotifierUI) NotifyBackupStage_FolderStartBackup(rootDest string,
 	msg := formatBackupProgress(backupType, v.totalDone, leftToBackup, timePassed, eta, path)
 
 	err = v.UpdateBackupProgress(v.progress, msg, true)
-	if err != nil {
-		lg.Fatal(err)
-	}
-
 	return err
 }
 
@@ -140,7 +273,7 @@ func (v *NotifierUI) NotifyBackupStage_FolderStartBackup(rootDest string,
 func (v *NotifierUI) NotifyBackupStage_FolderDoneBackup(rootDest string,
 	paths core.SrcDstPath, backupType core.FolderBackupType,
 	leftToBackup core.FolderSize, sizeDone core.SizeProgress,
-	timePassed time.Duration, eta *time.Duration,
+	timePassed time.Duration, eta *backup.ETA,
 	sessionErr error) error {
 
 	path, err := core.GetRelativePath(rootDest, paths.DestPath)
@@ -149,23 +282,125 @@ func (v *NotifierUI) NotifyBackupStage_FolderDoneBackup(rootDest string,
 	}
 
 	v.totalDone = v.totalDone.AddSizeProgress(sizeDone)
+	if v.currentModule != nil {
+		v.currentModule.addSizeProgress(sizeDone)
+		v.updateModuleProgress(v.currentModule)
+	}
 
 	msg := formatBackupProgress(backupType, v.totalDone, leftToBackup, timePassed, eta, path)
 
 	lg.Debugf("Total done: %v", v.totalDone)
 	lg.Debugf("Left to backup: %v", leftToBackup.GetByteCount())
-	progress := float32(float64(v.totalDone) / float64(v.totalDone+leftToBackup))
 	const minProgress = 0.002
+	progress := float32(minProgress)
+	// Total size is unknown, if plan stage skipped for all modules.
+	if v.totalDone+leftToBackup > 0 {
+		progress = float32(float64(v.totalDone) / float64(v.totalDone+leftToBackup))
+	}
 	if progress < minProgress {
 		progress = minProgress
 	}
 	v.progress = &progress
 
 	err = v.UpdateBackupProgress(v.progress, msg, true)
+	return err
+}
+
+// NotifyBackupStage_FolderRsyncOutput implements core.BackupNotifier interface method.
+// Add collapsed pane with itemized RSYNC output of the backup step to the session log.
+func (v *NotifierUI) NotifyBackupStage_FolderRsyncOutput(rootDest string,
+	paths core.SrcDstPath, output []string) error {
+
+	if len(output) == 0 {
+		return nil
+	}
+	path, err := core.GetRelativePath(rootDest, paths.DestPath)
+	if err != nil {
+		return err
+	}
+	// GTK accept only valid UTF-8 text, but file names of
+	// sources in legacy charsets might be not converted.
+	path = core.SanitizeUTF8(path)
+	text := core.SanitizeUTF8(strings.Join(output, "\n"))
+
+	call := func() {
+		if v.logTextView == nil {
+			return
+		}
+		exp, err := createRsyncOutputPane(path, len(output), text)
+		if err != nil {
+			reportError(v.win, err)
+			return
+		}
+		buffer, err := v.logTextView.GetBuffer()
+		if err != nil {
+			reportError(v.win, err)
+			return
+		}
+		offset := buffer.GetEndIter().GetOffset()
+		anchor, err := buffer.CreateChildAnchor(buffer.GetEndIter())
+		if err != nil {
+			reportError(v.win, err)
+			return
+		}
+		v.logTextView.AddChildAtAnchor(exp, anchor)
+		buffer.Insert(buffer.GetEndIter(), "\n")
+		exp.ShowAll()
+		// child anchor is represented by object replacement character
+		v.logSearch.AddLine(offset, "\uFFFC\n")
+	}
+	v.updates.Add(call)
+	return nil
+}
+
+// createRsyncOutputPane create expander, which keep RSYNC output lines of backup step.
+func createRsyncOutputPane(path string, count int, text string) (*gtk.Expander, error) {
+	exp, err := gtk.ExpanderNew(locale.T(MsgAppWindowRsyncOutputCaption,
+		struct {
+			Path  string
+			Count int
+		}{Path: path, Count: count}))
+	if err != nil {
+		return nil, err
+	}
+	exp.SetTooltipText(locale.T(MsgAppWindowRsyncOutputHint,
+		struct{ MaxLines int }{MaxLines: backup.RSYNC_OUTPUT_TAIL_LINES}))
+	lbl, err := gtk.LabelNew(text)
+	if err != nil {
+		return nil, err
+	}
+	lbl.SetHAlign(gtk.ALIGN_START)
+	lbl.SetSelectable(true)
+	css := `
+label {
+    font-family: "Monospace";
+}
+	`
+	err = ApplyStyleCSS(&lbl.Widget, css)
 	if err != nil {
-		lg.Fatal(err)
+		return nil, err
 	}
+	SetMargins(lbl, 18, 3, 0, 3)
+	exp.Add(lbl)
+	return exp, nil
+}
+
+// NotifyBackupStage_NetworkStateChanged implements core.BackupNotifier interface method.
+// Show "waiting for network" state with pulsing progress bar, until connection restored.
+func (v *NotifierUI) NotifyBackupStage_NetworkStateChanged(sourceRsync string,
+	waiting bool) error {
 
+	var msg string
+	var progress *float32
+	if waiting {
+		msg = locale.T(MsgAppWindowBackupProgressWaitingForNetwork,
+			struct{ Path string }{Path: sourceRsync})
+	} else {
+		msg = locale.T(MsgAppWindowBackupProgressNetworkWaitingDone, nil)
+		progress = v.progress
+	}
+	mp := NewMarkup(MARKUP_SIZE_LARGER, 0, 0, msg, nil)
+	err := v.UpdateBackupProgress(progress, mp.String(), true)
 	return err
 }
 
@@ -178,6 +413,12 @@ func (v *NotifierUI) ClearProgressGrid() error {
 	}
 	v.logTextView = nil
 	v.logViewPort = nil
+	v.logSearch = nil
+	v.logLinks = nil
+	v.logTruncated = nil
+	v.modules = nil
+	v.currentModule = nil
+	v.modulesGrid = nil
 	lst := v.gridUI.GetChildren()
 	lst.Foreach(func(item interface{}) {
 		if wdg, ok := item.(*gtk.Widget); ok {
@@ -245,13 +486,38 @@ func (v *NotifierUI) CreateProgressControls(sessionLogFontSize string) error {
 	}
 	row++
 
+	if v.modulesGrid == nil {
+		exp, grid, err := createModulesProgressControls()
+		if err != nil {
+			return err
+		}
+		v.modulesGrid = grid
+		v.gridUI.Attach(exp, 0, row, 2, 1)
+	}
+	row++
+
 	if v.logTextView == nil {
 		lbl, err := gtk.LabelNew(locale.T(MsgAppWindowSessionLogCaption, nil))
 		if err != nil {
 			return err
 		}
 		lbl.SetHAlign(gtk.ALIGN_START)
-		v.gridUI.Attach(lbl, 0, row, 2, 1)
+		lbl.SetHExpand(true)
+		box, err := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 6)
+		if err != nil {
+			return err
+		}
+		box.PackStart(lbl, true, true, 0)
+		v.logTruncated, err = gtk.ButtonNewWithLabel(locale.T(MsgAppWindowSessionLogTruncatedButton, nil))
+		if err != nil {
+			return err
+		}
+		v.logTruncated.SetTooltipText(locale.T(MsgAppWindowSessionLogTruncatedHint,
+			struct{ LineCount int }{LineCount: SESSION_LOG_MAX_LINES}))
+		v.logTruncated.SetActionName("win.LogViewerAction")
+		v.logTruncated.SetNoShowAll(true)
+		box.PackStart(v.logTruncated, false, false, 0)
+		v.gridUI.Attach(box, 0, row, 2, 1)
 		row++
 		v.logTextView, err = gtk.TextViewNew()
 		if err != nil {
@@ -290,6 +556,20 @@ textview {
 		sw.Add(v.logViewPort)
 		v.logViewPort.Add(v.logTextView)
 		v.gridUI.Attach(sw, 0, row, 2, 1)
+		v.logSearch, err = NewLogSearchBar(v.logTextView, v.logViewPort)
+		if err != nil {
+			return err
+		}
+		box.PackEnd(v.logSearch.box, false, false, 0)
+		v.logLinks, err = NewLogLinks(&v.win.Window, v.logTextView)
+		if err != nil {
+			return err
+		}
+		export, err := NewLogExportButtons(&v.win.Window, buffer, v.profile.String())
+		if err != nil {
+			return err
+		}
+		box.PackEnd(export.box, false, false, 0)
 	}
 	row++
 
@@ -421,94 +701,89 @@ func getLogEventsRegex(events []struct {
 	return re
 }
 
+// Session log events colorized in TextView GTK widget.
+var logLineEvents = []struct {
+	Level   logger.LogLevel
+	TagName string
+}{
+	{logger.InfoLevel, "BlueColor"},
+	{logger.NotifyLevel, "YellowColor"},
+	{logger.WarnLevel, "OrangeRedColor"},
+	{logger.ErrorLevel, "RedColor"},
+	{logger.FatalLevel, "RedColor"},
+	{logger.PanicLevel, "RedColor"},
+}
+
+var logLineEventsRegex = getLogEventsRegex(logLineEvents)
+
 // addLineToBuffer get next log line received from backup session process
 // to process and display this line in application GUI.
-func (v *NotifierUI) addLineToBuffer(buffer *gtk.TextBuffer, line string) {
+func addLineToBuffer(buffer *gtk.TextBuffer, line string) {
 	end := buffer.GetEndIter()
 	endOffset := end.GetOffset()
 	buffer.Insert(end, line)
 
-	events := []struct {
-		Level   logger.LogLevel
-		TagName string
-	}{
-		{logger.InfoLevel, "BlueColor"},
-		{logger.NotifyLevel, "YellowColor"},
-		{logger.WarnLevel, "OrangeRedColor"},
-		{logger.ErrorLevel, "RedColor"},
-		{logger.FatalLevel, "RedColor"},
-		{logger.PanicLevel, "RedColor"},
-	}
-	re := getLogEventsRegex(events)
-	m := core.FindStringSubmatchIndexes(re, line)
+	m := core.FindStringSubmatchIndexes(logLineEventsRegex, line)
 	if a, ok := m["Event"]; ok {
 		value := line[a[0]:a[1]]
 		p1 := buffer.GetIterAtOffset(getRuneIndex(line, a[0]) + endOffset)
 		p2 := buffer.GetIterAtOffset(getRuneIndex(line, a[1]) + endOffset)
-		for _, event := range events {
+		for _, event := range logLineEvents {
 			if value == lToU(event.Level) {
 				buffer.ApplyTagByName(event.TagName, p1, p2)
 			}
 		}
 	}
-	/*
-			var err error
-		   	re, err = getSubpathRegexp()
-		   	if err != nil {
-		   		return err
-		   	}
-		   	m = core.FindStringSubmatchIndexes(re, line)
-		   	if b, ok := m["Path"]; ok {
-		   		link, err := gtk.LabelNew("/home/")
-		   		if err != nil {
-		   			return err
-		   		}
-		   		// SetAllMargins(link, 0)
-		   		css := `
-		   * {
-		       margin: 0;
-		       padding: 0;
-		       border-style: none;
-		       border-radius: 0;
-		       border-width: 0;
-		       outline-style: none;
-		       outline-offset: 0px;
-		   }
-		   		`
-		   		// end := buffer.GetEndIter()
-		   		p1 := buffer.GetIterAtOffset(getRuneIndex(line, b[0]) + endOffset)
-		   		// p2 := buffer.GetIterAtOffset(getRuneIndex(line, b[1]) + endOffset)
-		   		anchor, err := buffer.CreateChildAnchor(p1)
-		   		if err != nil {
-		   			return err
-		   		}
-		   		v.logTextView.AddChildAtAnchor(link, anchor)
-		   		link.ShowAll()
-		   		applyStyleCSS(&link.Widget, css)
-		   		// buffer.ApplyTagByName("Path", p1, p2)
-		   	}
-	*/
 }
 
 // UpdateTextViewLog add log line to the end of
 // Session Log GTK widget.
 func (v *NotifierUI) UpdateTextViewLog(line string) error {
 	call := func() {
-		//if v.logTextView != nil {
+		if v.logTextView == nil {
+			return
+		}
 		buffer, err := v.logTextView.GetBuffer()
 		if err != nil {
-			lg.Fatal(err)
+			reportError(v.win, err)
+			return
 		}
-		v.addLineToBuffer(buffer, line)
+		offset := buffer.GetEndIter().GetOffset()
+		addLineToBuffer(buffer, line)
+		v.logLinks.AddLine(offset, line)
+		v.logSearch.AddLine(offset, line)
+	}
+	v.updates.Add(call)
+	return nil
+}
 
-		err = v.ScrollView()
+// sessionLogUpdated limit session log length and scroll
+// to the most recent line, once batch of lines added.
+func (v *NotifierUI) sessionLogUpdated() {
+	if v.logTextView == nil {
+		return
+	}
+	if count := v.logSearch.LineCount(); count > SESSION_LOG_MAX_LINES+SESSION_LOG_TRIM_LINES {
+		buffer, err := v.logTextView.GetBuffer()
 		if err != nil {
-			lg.Fatal(err)
+			reportError(v.win, err)
+			return
+		}
+		offset := v.logSearch.LineOffset(count - SESSION_LOG_MAX_LINES)
+		buffer.Delete(buffer.GetStartIter(), buffer.GetIterAtOffset(offset))
+		v.logSearch.Trim(offset)
+		v.logLinks.Trim(offset)
+		v.logTruncated.Show()
+	}
+
+	// keep search match in view
+	if !v.logSearch.IsSearching() {
+		err := v.ScrollView()
+		if err != nil {
+			reportError(v.win, err)
+			return
 		}
-		//}
 	}
-	MustIdleAdd(call)
-	return nil
 }
 
 // UpdateBackupProgress updates visual progress of backup
@@ -521,26 +796,37 @@ func (v *NotifierUI) UpdateBackupProgress(progress *float32,
 			v.pbm.StartPulse()
 			err := v.pbm.AddProgressBarStyleClass("run-animation")
 			if err != nil {
-				lg.Fatal(err)
+				reportError(v.win, err)
+				return
 			}
 		} else {
 			prg := float64(*progress)
 			err := v.pbm.SetFraction(prg)
 			if err != nil {
-				lg.Fatal(err)
+				reportError(v.win, err)
+				return
 			}
 			if prg == 1 {
 				err := v.pbm.RemoveProgressBarStyleClass("run-animation")
 				if err != nil {
-					lg.Fatal(err)
+					reportError(v.win, err)
+					return
 				}
 			}
 		}
 		v.statusLabel.SetMarkup(progressStr)
+		if trayIcon != nil {
+			trayIcon.SetProgress(v.profile.String(), progress)
+		}
+		if launcherEntry != nil {
+			launcherEntry.SetProgress(progress)
+		}
 	}
 	if fromAsync {
-		MustIdleAdd(call)
+		v.updates.Replace(call)
 	} else {
+		// drop outdated progress not yet shown
+		v.updates.Replace(nil)
 		call()
 	}
 	return nil
@@ -581,26 +867,26 @@ func (v *NotifierUI) decodeBackupCompletionType(err error,
 
 // getDesktopNotificationSummaryAndBody prepares desktop notification subject and body text.
 func (v *NotifierUI) getDesktopNotificationSummaryAndBody(completionType BackupCompletionType,
-	backupProgress *backup.Progress) (string, string) {
+	err error, backupProgress *backup.Progress) (string, string) {
 
 	var summary, body string
 	switch completionType {
 	case BackupSucessfullyCompleted:
 		summary = locale.T(
 			MsgDesktopNotificationBackupSuccessfullyCompleted,
-			struct{ ProfileName string }{ProfileName: v.profileName})
+			struct{ ProfileName string }{ProfileName: v.profile.String()})
 	case BackupCompletedWithErrors:
 		summary = locale.T(
 			MsgDesktopNotificationBackupCompletedWithErrors,
-			struct{ ProfileName string }{ProfileName: v.profileName})
+			struct{ ProfileName string }{ProfileName: v.profile.String()})
 	case BackupFailed:
 		summary = locale.T(
 			MsgDesktopNotificationBackupFailed,
-			struct{ ProfileName string }{ProfileName: v.profileName})
+			struct{ ProfileName string }{ProfileName: v.profile.String()})
 	case BackupTerminated:
 		summary = locale.T(
 			MsgDesktopNotificationBackupTerminated,
-			struct{ ProfileName string }{ProfileName: v.profileName})
+			struct{ ProfileName string }{ProfileName: v.profile.String()})
 	}
 
 	var buf bytes.Buffer
@@ -623,6 +909,17 @@ func (v *NotifierUI) getDesktopNotificationSummaryAndBody(completionType BackupC
 					*backupProgress.TotalProgress.Skipped)})))
 		}
 	}
+	// explain the reason of failure and suggest how to fix it
+	var lastErr error
+	if completionType == BackupFailed {
+		lastErr = err
+	} else if completionType == BackupCompletedWithErrors && backupProgress != nil {
+		lastErr = backupProgress.LastSessionError
+	}
+	if lastErr != nil {
+		buf.WriteString(fmt.Sprintln(locale.T(MsgDesktopNotificationErrorReason,
+			struct{ Error string }{Error: rsync.FormatErrorWithSuggestion(lastErr)})))
+	}
 	if backupProgress != nil {
 		timeTaken := backupProgress.GetTotalTimeTaken()
 		sections := 2
@@ -645,46 +942,138 @@ func (v *NotifierUI) checkDesktopNotificationEnabled() (bool, error) {
 }
 
 func (v *NotifierUI) sendDesktopNotification(completionType BackupCompletionType,
-	backupProgress *backup.Progress) error {
+	err error, backupProgress *backup.Progress) error {
 
-	summary, body := v.getDesktopNotificationSummaryAndBody(completionType, backupProgress)
-	notif, err := libnotify.NotifyNotificationNew(summary, body, "")
+	summary, body := v.getDesktopNotificationSummaryAndBody(completionType, err, backupProgress)
+	notif, err := libnotify.NotifyNotificationNew(core.SanitizeUTF8(summary),
+		core.SanitizeUTF8(body), "")
 	if err != nil {
 		return err
 	}
+	if backupProgress != nil && backupProgress.BackupFolder != "" {
+		v.addDesktopNotificationActions(notif, completionType,
+			backupProgress.GetBackupFullPath(backupProgress.BackupFolder), backupProgress)
+	}
 	err = notif.Show()
 	if err != nil {
 		return err
 	}
+	v.notification = notif
 	return nil
 }
 
-func (v *NotifierUI) checkNotificationScriptEnabled() (bool, error) {
+// showSessionUri open backup session folder, or file inside, with default application.
+func (v *NotifierUI) showSessionUri(path string) {
+	uri := &url.URL{Scheme: "file", Path: path}
+	err := ShowUri(&v.win.Window, uri.String())
+	if err != nil {
+		lg.Warn(err)
+	}
+}
+
+// addDesktopNotificationActions attach to desktop notification buttons to open
+// backup session folder, view session log and retry folders failed to backup.
+// Actions are invoked in GTK main loop.
+func (v *NotifierUI) addDesktopNotificationActions(notif *libnotify.NotifyNotification,
+	completionType BackupCompletionType, sessionPath string, backupProgress *backup.Progress) {
+
+	if _, err := os.Stat(sessionPath); err != nil {
+		return
+	}
+	notif.AddAction("open-folder", locale.T(MsgDesktopNotificationOpenFolderAction, nil),
+		func(notif *libnotify.NotifyNotification, action string) {
+			v.showSessionUri(sessionPath)
+		})
+	logPath := filepath.Join(sessionPath, backup.GetLogFileName())
+	if _, err := os.Stat(logPath); err == nil {
+		notif.AddAction("view-log", locale.T(MsgDesktopNotificationViewLogAction, nil),
+			func(notif *libnotify.NotifyNotification, action string) {
+				v.showSessionUri(logPath)
+			})
+	}
+	if completionType == BackupCompletedWithErrors && v.retryHandler != nil &&
+		len(backupProgress.FailedFolders) > 0 {
+		notif.AddAction("retry", locale.T(MsgAppWindowRetryFailedFoldersCaption, nil),
+			func(notif *libnotify.NotifyNotification, action string) {
+				v.win.Present()
+				v.retryHandler(sessionPath, nil)
+			})
+	}
+}
+
+// NotifySafeToUnplug show desktop notification, that destination
+// drive is ejected, if desktop notifications are enabled.
+func (v *NotifierUI) NotifySafeToUnplug(message string) error {
+	enabled, err := v.checkDesktopNotificationEnabled()
+	if err != nil || !enabled {
+		return err
+	}
+	notif, err := libnotify.NotifyNotificationNew(message, "", "drive-removable-media")
+	if err != nil {
+		return err
+	}
+	return notif.Show()
+}
+
+func (v *NotifierUI) checkNotificationScriptEnabled() (bool, string, error) {
 	appSettings, err := glib.SettingsNew(SETTINGS_SCHEMA_ID)
 	if err != nil {
-		return false, err
+		return false, "", err
 	}
 	enabled := appSettings.GetBoolean(CFG_RUN_NOTIFICATION_SCRIPT)
-	return enabled, nil
+	return enabled, getNotificationScriptPath(appSettings), nil
 }
 
-func buildEnvVars(completionType BackupCompletionType,
-	backupProgress *backup.Progress) []string {
+// getNotificationScriptPath return notification script location
+// from preferences, falling back to default one.
+func getNotificationScriptPath(appSettings *glib.Settings) string {
+	return notificationScriptPathOrDefault(appSettings.GetString(CFG_NOTIFICATION_SCRIPT_PATH))
+}
+
+// notificationScriptPathOrDefault substitute empty script path with default one.
+func notificationScriptPathOrDefault(scriptPath string) string {
+	scriptPath = strings.TrimSpace(scriptPath)
+	if scriptPath == "" {
+		scriptPath = NOTIFICATION_SCRIPT_PATH
+	}
+	return scriptPath
+}
 
-	var status string
+// verifyNotificationScript check that notification script exists
+// and is executable (for POSIX-kind OS). Return localized error otherwise.
+func verifyNotificationScript(scriptPath string) error {
+	stat, err := os.Stat(scriptPath)
+	if err != nil {
+		return errors.New(locale.T(MsgAppWindowGetExecutableScriptInfoError,
+			struct{ Error error }{Error: err}))
+	}
+	if stat.IsDir() || shell.IsLinuxMacOSFreeBSD() && stat.Mode()&0111 == 0 {
+		return errors.New(locale.T(MsgAppWindowNotificationScriptExecutableError,
+			struct{ ScriptPath string }{ScriptPath: scriptPath}))
+	}
+	return nil
+}
+
+// getCompletionStatus return machine-readable backup completion status.
+func getCompletionStatus(completionType BackupCompletionType) string {
 	switch completionType {
 	case BackupTerminated:
-		status = "terminated"
+		return "terminated"
 	case BackupFailed:
-		status = "failed"
+		return "failed"
 	case BackupSucessfullyCompleted:
-		status = "done"
+		return "done"
 	case BackupCompletedWithErrors:
-		status = "done_with_errors"
+		return "done_with_errors"
 	}
+	return ""
+}
+
+func buildEnvVars(completionType BackupCompletionType,
+	backupProgress *backup.Progress) []string {
 
 	var vars []string
-	vars = append(vars, fmt.Sprintf("BACKUP_STATUS=%s", status))
+	vars = append(vars, fmt.Sprintf("BACKUP_STATUS=%s", getCompletionStatus(completionType)))
 	if backupProgress != nil {
 		if backupProgress.TotalProgress.Completed != nil {
 			vars = append(vars, fmt.Sprintf("SIZE_BACKEDUP_MB=%d",
@@ -709,6 +1098,17 @@ func buildEnvVars(completionType BackupCompletionType,
 func (v *NotifierUI) runNotificationScript(completionType BackupCompletionType,
 	backupProgress *backup.Progress, scriptPath string) error {
 
+	_, err := executeNotificationScript(scriptPath,
+		buildEnvVars(completionType, backupProgress))
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// executeNotificationScript run script with default shell,
+// passing extra environment variables. Return script exit code.
+func executeNotificationScript(scriptPath string, vars []string) (int, error) {
 	// get default shell
 	shell := os.Getenv("SHELL")
 	// once not found fallback to bash
@@ -716,12 +1116,7 @@ func (v *NotifierUI) runNotificationScript(completionType BackupCompletionType,
 		shell = "/usr/bin/bash"
 	}
 
-	_, err := core.RunExecutableWithExtraVars(shell,
-		buildEnvVars(completionType, backupProgress), "/etc/gorsync/notification.sh")
-	if err != nil {
-		return err
-	}
-	return nil
+	return core.RunExecutableWithExtraVars(shell, vars, scriptPath)
 }
 
 // reportCompletion updates backup process state and progress bar status.
@@ -729,6 +1124,7 @@ func (v *NotifierUI) ReportCompletion(progress float32, err error,
 	backupProgress *backup.Progress, async bool) {
 
 	completionType := v.decodeBackupCompletionType(err, backupProgress)
+	metricsServer.SessionCompleted(v.profile, completionType, backupProgress)
 	var finalMsg string
 	switch completionType {
 	case BackupTerminated:
@@ -744,57 +1140,74 @@ func (v *NotifierUI) ReportCompletion(progress float32, err error,
 	mp := NewMarkup(MARKUP_SIZE_LARGER, 0, 0, finalMsg, nil)
 	err2 := v.UpdateBackupProgress(&progress, mp.String(), async)
 	if err2 != nil {
-		lg.Fatal(err2)
+		lg.Error(err2)
 	}
+	// show final session log lines and progress without delay
+	v.updates.Flush()
+
+	go func(completionType BackupCompletionType, completionErr error, backupProgress *backup.Progress) {
+		// report about real completion via asynchronous method
+		defer close(v.done)
+		defer recoverPanic("notifier")
 
-	go func(completionType BackupCompletionType, backupProgress *backup.Progress) {
 		time.Sleep(time.Millisecond * 200)
 		MustIdleAdd(func() {
 			err := v.ScrollView()
 			if err != nil {
-				lg.Fatal(err)
+				reportError(v.win, err)
+				return
+			}
+			// offer to retry failed folders into the same session folder
+			if completionType == BackupCompletedWithErrors && v.retryHandler != nil &&
+				len(backupProgress.FailedFolders) > 0 {
+				err = v.addRetryButton(backupProgress.GetBackupFullPath(backupProgress.BackupFolder))
+				if err != nil {
+					reportError(v.win, err)
+					return
+				}
+			}
+			// list failed folders with errors
+			if completionType == BackupCompletedWithErrors && len(backupProgress.FailedFolders) > 0 {
+				err = v.addFailedFoldersPanel(backupProgress)
+				if err != nil {
+					reportError(v.win, err)
+					return
+				}
+			}
+			// draw attention to failure, if main window is not focused
+			if launcherEntry != nil && !v.win.IsActive() && (completionType == BackupFailed ||
+				completionType == BackupCompletedWithErrors) {
+				launcherEntry.SetUrgent(true)
 			}
 		})
 
 		enabled, err := v.checkDesktopNotificationEnabled()
 		if err != nil {
-			lg.Fatal(err)
+			lg.Panic(err)
 		}
 		if enabled && completionType != BackupTerminated {
-			err = v.sendDesktopNotification(completionType, backupProgress)
+			err = v.sendDesktopNotification(completionType, completionErr, backupProgress)
 			if err != nil {
 				lg.Warn(locale.T(MsgAppWindowShowNotificationError,
 					struct{ Error error }{Error: err}))
 			}
 		}
-		scriptPath := "/etc/gorsync/notification.sh"
-		enabled, err = v.checkNotificationScriptEnabled()
+		enabled, scriptPath, err := v.checkNotificationScriptEnabled()
 		if err != nil {
-			lg.Fatal(err)
+			lg.Panic(err)
 		}
 		if enabled {
-			if stat, err := os.Stat(scriptPath); err == nil {
-				mode := stat.Mode()
-				// check script is executable for POSIX-kind OS
-				if !shell.IsLinuxMacOSFreeBSD() || mode&0111 != 0 {
-					err = v.runNotificationScript(completionType,
-						backupProgress, scriptPath)
-					if err != nil {
-						lg.Warn(locale.T(MsgAppWindowRunNotificationScriptError,
-							struct{ Error error }{Error: err}))
-					}
-				} else {
-					lg.Warn(locale.T(MsgAppWindowNotificationScriptExecutableError,
-						struct{ ScriptPath string }{ScriptPath: scriptPath}))
+			if err := verifyNotificationScript(scriptPath); err == nil {
+				err = v.runNotificationScript(completionType,
+					backupProgress, scriptPath)
+				if err != nil {
+					lg.Warn(locale.T(MsgAppWindowRunNotificationScriptError,
+						struct{ Error error }{Error: err}))
 				}
 			} else {
-				lg.Warn(locale.T(MsgAppWindowGetExecutableScriptInfoError,
-					struct{ Error error }{Error: err}))
+				lg.Warn(err)
 			}
 		}
-		// report about real completion via asynchronous method
-		close(v.done)
-
-	}(completionType, backupProgress)
+	}(completionType, err, backupProgress)
 
 }
@@ -0,0 +1,191 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package gtkui
+
+import (
+	"strings"
+
+	"github.com/d2r2/go-rsync/locale"
+	"github.com/d2r2/gotk3/gtk"
+)
+
+// Separator of RSYNC --usermap/--groupmap pairs kept in single setting.
+const OWNERSHIP_MAP_SEPARATOR = ","
+
+// splitOwnershipMap convert comma separated RSYNC
+// FROM:TO mapping pairs to list, skipping empty ones.
+func splitOwnershipMap(str string) []string {
+	var pairs []string
+	for _, item := range strings.Split(str, OWNERSHIP_MAP_SEPARATOR) {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			pairs = append(pairs, item)
+		}
+	}
+	return pairs
+}
+
+// ownershipMapRow keep widgets of single FROM:TO mapping pair.
+type ownershipMapRow struct {
+	from   *gtk.Entry
+	to     *gtk.Entry
+	remove *gtk.Button
+}
+
+// ownershipMapEditor is a small editor of RSYNC --usermap/--groupmap
+// pairs, which save them to glib.Settings string on each change.
+type ownershipMapEditor struct {
+	settings *SettingsStore
+	key      string
+	grid     *gtk.Grid
+	rows     []*ownershipMapRow
+	// grid row to attach next pair, since rows
+	// of removed pairs are left empty
+	nextRow int
+}
+
+// createOwnershipMapEditor create editor of mapping pairs
+// kept in settings string specified by key.
+func createOwnershipMapEditor(settings *SettingsStore, key string) (*gtk.Box, error) {
+	box, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 3)
+	if err != nil {
+		return nil, err
+	}
+	grid, err := gtk.GridNew()
+	if err != nil {
+		return nil, err
+	}
+	grid.SetColumnSpacing(6)
+	grid.SetRowSpacing(3)
+	box.PackStart(grid, false, false, 0)
+
+	editor := &ownershipMapEditor{settings: settings, key: key, grid: grid}
+	for _, pair := range splitOwnershipMap(settings.settings.GetString(key)) {
+		var from, to string
+		if i := strings.LastIndex(pair, ":"); i != -1 {
+			from, to = pair[:i], pair[i+1:]
+		} else {
+			from = pair
+		}
+		err = editor.addRow(from, to)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	btnAdd, err := SetupButtonWithThemedImage("list-add-symbolic")
+	if err != nil {
+		return nil, err
+	}
+	btnAdd.SetTooltipText(locale.T(MsgPrefDlgOwnerMapAddHint, nil))
+	btnAdd.SetHAlign(gtk.ALIGN_START)
+	_, err = btnAdd.Connect("clicked", func() {
+		err := editor.addRow("", "")
+		if err != nil {
+			lg.Fatal(err)
+		}
+		editor.grid.ShowAll()
+	})
+	if err != nil {
+		return nil, err
+	}
+	box.PackStart(btnAdd, false, false, 0)
+	return box, nil
+}
+
+// addRow append widgets to edit new mapping pair.
+func (v *ownershipMapEditor) addRow(from, to string) error {
+	row := &ownershipMapRow{}
+	var err error
+	row.from, err = gtk.EntryNew()
+	if err != nil {
+		return err
+	}
+	row.from.SetPlaceholderText(locale.T(MsgPrefDlgOwnerMapFromPlaceholder, nil))
+	row.from.SetText(from)
+	row.from.SetHExpand(true)
+	lbl, err := gtk.LabelNew("→")
+	if err != nil {
+		return err
+	}
+	row.to, err = gtk.EntryNew()
+	if err != nil {
+		return err
+	}
+	row.to.SetPlaceholderText(locale.T(MsgPrefDlgOwnerMapToPlaceholder, nil))
+	row.to.SetText(to)
+	row.to.SetHExpand(true)
+	row.remove, err = SetupButtonWithThemedImage("list-remove-symbolic")
+	if err != nil {
+		return err
+	}
+	row.remove.SetTooltipText(locale.T(MsgPrefDlgOwnerMapRemoveHint, nil))
+
+	for _, entry := range []*gtk.Entry{row.from, row.to} {
+		_, err = entry.Connect("changed", func() {
+			v.save()
+		})
+		if err != nil {
+			return err
+		}
+	}
+	_, err = row.remove.Connect("clicked", func() {
+		v.removeRow(row)
+		lbl.Destroy()
+	})
+	if err != nil {
+		return err
+	}
+
+	v.grid.Attach(row.from, 0, v.nextRow, 1, 1)
+	v.grid.Attach(lbl, 1, v.nextRow, 1, 1)
+	v.grid.Attach(row.to, 2, v.nextRow, 1, 1)
+	v.grid.Attach(row.remove, 3, v.nextRow, 1, 1)
+	v.nextRow++
+	v.rows = append(v.rows, row)
+	return nil
+}
+
+// removeRow delete widgets of mapping pair and save the rest.
+func (v *ownershipMapEditor) removeRow(row *ownershipMapRow) {
+	for i, item := range v.rows {
+		if item == row {
+			v.rows = append(v.rows[:i], v.rows[i+1:]...)
+			break
+		}
+	}
+	row.from.Destroy()
+	row.to.Destroy()
+	row.remove.Destroy()
+	v.save()
+}
+
+// save write complete mapping pairs to settings.
+func (v *ownershipMapEditor) save() {
+	var pairs []string
+	for _, row := range v.rows {
+		from, err := row.from.GetText()
+		if err != nil {
+			lg.Fatal(err)
+		}
+		to, err := row.to.GetText()
+		if err != nil {
+			lg.Fatal(err)
+		}
+		from = strings.TrimSpace(from)
+		to = strings.TrimSpace(to)
+		if from != "" && to != "" {
+			pairs = append(pairs, from+":"+to)
+		}
+	}
+	v.settings.settings.SetString(v.key, strings.Join(pairs, OWNERSHIP_MAP_SEPARATOR))
+}
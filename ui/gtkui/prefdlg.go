@@ -16,6 +16,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"regexp"
 	"sort"
 	"strconv"
@@ -24,9 +25,11 @@ import (
 	"time"
 	"unicode/utf8"
 
+	"github.com/d2r2/go-rsync/backup"
 	"github.com/d2r2/go-rsync/core"
 	"github.com/d2r2/go-rsync/locale"
 	"github.com/d2r2/go-rsync/rsync"
+	"github.com/d2r2/gotk3/gdk"
 	"github.com/d2r2/gotk3/glib"
 	"github.com/d2r2/gotk3/gtk"
 	"github.com/davecgh/go-spew/spew"
@@ -127,6 +130,33 @@ func GeneralPreferencesNew(win *gtk.ApplicationWindow, appSettings *SettingsStor
 	grid.Attach(cbAboutInfo, DesignSecondCol, row, 1, 1)
 	row++
 
+	// Option to check for new application release on startup
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgCheckForUpdatesCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	eb, err = gtk.EventBoxNew()
+	if err != nil {
+		return nil, err
+	}
+	eb.Add(lbl)
+	grid.Attach(eb, DesignFirstCol, row, 1, 1)
+	cbCheckForUpdates, err := gtk.CheckButtonNew()
+	if err != nil {
+		return nil, err
+	}
+	_, err = eb.Connect("button-press-event", func() {
+		cbCheckForUpdates.SetActive(!cbCheckForUpdates.GetActive())
+	})
+	if err != nil {
+		return nil, err
+	}
+	cbCheckForUpdates.SetTooltipText(locale.T(MsgPrefDlgCheckForUpdatesHint, nil))
+	cbCheckForUpdates.SetHAlign(gtk.ALIGN_START)
+	bh.Bind(CFG_CHECK_FOR_UPDATES, cbCheckForUpdates, "active", glib.SETTINGS_BIND_DEFAULT)
+	grid.Attach(cbCheckForUpdates, DesignSecondCol, row, 1, 1)
+	row++
+
 	// Show desktop notification on backup completion
 	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgPerformDesktopNotificationCaption, nil))
 	if err != nil {
@@ -155,6 +185,213 @@ func GeneralPreferencesNew(win *gtk.ApplicationWindow, appSettings *SettingsStor
 	grid.Attach(cbPerformBackupCompletionDesktopNotification, DesignSecondCol, row, 1, 1)
 	row++
 
+	// Confirm backup start with plan summary
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgConfirmBackupPlanCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	eb, err = gtk.EventBoxNew()
+	if err != nil {
+		return nil, err
+	}
+	eb.Add(lbl)
+	grid.Attach(eb, DesignFirstCol, row, 1, 1)
+	cbConfirmBackupPlan, err := gtk.CheckButtonNew()
+	if err != nil {
+		return nil, err
+	}
+	_, err = eb.Connect("button-press-event", func() {
+		cbConfirmBackupPlan.SetActive(!cbConfirmBackupPlan.GetActive())
+	})
+	if err != nil {
+		return nil, err
+	}
+	cbConfirmBackupPlan.SetTooltipText(locale.T(MsgPrefDlgConfirmBackupPlanHint, nil))
+	cbConfirmBackupPlan.SetHAlign(gtk.ALIGN_START)
+	bh.Bind(CFG_CONFIRM_BACKUP_PLAN, cbConfirmBackupPlan, "active", glib.SETTINGS_BIND_DEFAULT)
+	grid.Attach(cbConfirmBackupPlan, DesignSecondCol, row, 1, 1)
+	row++
+
+	// Ask for backup session comment
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgAskSessionCommentCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	eb, err = gtk.EventBoxNew()
+	if err != nil {
+		return nil, err
+	}
+	eb.Add(lbl)
+	grid.Attach(eb, DesignFirstCol, row, 1, 1)
+	cbAskSessionComment, err := gtk.CheckButtonNew()
+	if err != nil {
+		return nil, err
+	}
+	_, err = eb.Connect("button-press-event", func() {
+		cbAskSessionComment.SetActive(!cbAskSessionComment.GetActive())
+	})
+	if err != nil {
+		return nil, err
+	}
+	cbAskSessionComment.SetTooltipText(locale.T(MsgPrefDlgAskSessionCommentHint, nil))
+	cbAskSessionComment.SetHAlign(gtk.ALIGN_START)
+	bh.Bind(CFG_ASK_SESSION_COMMENT, cbAskSessionComment, "active", glib.SETTINGS_BIND_DEFAULT)
+	grid.Attach(cbAskSessionComment, DesignSecondCol, row, 1, 1)
+	row++
+
+	// Show tray icon and keep running in background
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgEnableTrayIconCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	eb, err = gtk.EventBoxNew()
+	if err != nil {
+		return nil, err
+	}
+	eb.Add(lbl)
+	grid.Attach(eb, DesignFirstCol, row, 1, 1)
+	cbEnableTrayIcon, err := gtk.CheckButtonNew()
+	if err != nil {
+		return nil, err
+	}
+	_, err = eb.Connect("button-press-event", func() {
+		cbEnableTrayIcon.SetActive(!cbEnableTrayIcon.GetActive())
+	})
+	if err != nil {
+		return nil, err
+	}
+	cbEnableTrayIcon.SetTooltipText(locale.T(MsgPrefDlgEnableTrayIconHint, nil))
+	cbEnableTrayIcon.SetHAlign(gtk.ALIGN_START)
+	bh.Bind(CFG_ENABLE_TRAY_ICON, cbEnableTrayIcon, "active", glib.SETTINGS_BIND_DEFAULT)
+	grid.Attach(cbEnableTrayIcon, DesignSecondCol, row, 1, 1)
+	// Show or hide tray icon at once.
+	_, err = cbEnableTrayIcon.Connect("toggled", func(v *gtk.CheckButton) {
+		if trayIcon != nil {
+			trayIcon.SetEnabled(v.GetActive())
+		}
+		updateMetricsServer(appSettings)
+	})
+	if err != nil {
+		return nil, err
+	}
+	row++
+
+	// Expose metrics endpoint, while running in tray
+	cbMetricsEndpoint, err := gtk.CheckButtonNew()
+	if err != nil {
+		return nil, err
+	}
+	cbMetricsEndpoint.SetLabel(locale.T(MsgPrefDlgMetricsEndpointCaption, nil))
+	cbMetricsEndpoint.SetTooltipText(locale.T(MsgPrefDlgMetricsEndpointHint, nil))
+	cbMetricsEndpoint.SetHAlign(gtk.ALIGN_START)
+	bh.Bind(CFG_METRICS_ENDPOINT_ENABLED, cbMetricsEndpoint, "active", glib.SETTINGS_BIND_DEFAULT)
+	bh.Bind(CFG_ENABLE_TRAY_ICON, cbMetricsEndpoint, "sensitive", glib.SETTINGS_BIND_GET)
+	grid.Attach(cbMetricsEndpoint, DesignFirstCol, row, 1, 1)
+	edMetricsAddress, err := gtk.EntryNew()
+	if err != nil {
+		return nil, err
+	}
+	edMetricsAddress.SetTooltipText(locale.T(MsgPrefDlgMetricsEndpointHint, nil))
+	edMetricsAddress.SetHAlign(gtk.ALIGN_START)
+	bh.Bind(CFG_METRICS_LISTEN_ADDRESS, edMetricsAddress, "text", glib.SETTINGS_BIND_DEFAULT)
+	bh.Bind(CFG_ENABLE_TRAY_ICON, edMetricsAddress, "sensitive", glib.SETTINGS_BIND_GET)
+	grid.Attach(edMetricsAddress, DesignSecondCol, row, 1, 1)
+	_, err = cbMetricsEndpoint.Connect("toggled", func() {
+		updateMetricsServer(appSettings)
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Restart endpoint, once address edited.
+	_, err = edMetricsAddress.Connect("activate", func() {
+		updateMetricsServer(appSettings)
+	})
+	if err != nil {
+		return nil, err
+	}
+	_, err = edMetricsAddress.Connect("focus-out-event", func() bool {
+		updateMetricsServer(appSettings)
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+	row++
+
+	// Prevent logout and suspend while backup session is running
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgInhibitSuspendCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	eb, err = gtk.EventBoxNew()
+	if err != nil {
+		return nil, err
+	}
+	eb.Add(lbl)
+	grid.Attach(eb, DesignFirstCol, row, 1, 1)
+	cbInhibitSuspend, err := gtk.CheckButtonNew()
+	if err != nil {
+		return nil, err
+	}
+	_, err = eb.Connect("button-press-event", func() {
+		cbInhibitSuspend.SetActive(!cbInhibitSuspend.GetActive())
+	})
+	if err != nil {
+		return nil, err
+	}
+	cbInhibitSuspend.SetTooltipText(locale.T(MsgPrefDlgInhibitSuspendHint, nil))
+	cbInhibitSuspend.SetHAlign(gtk.ALIGN_START)
+	bh.Bind(CFG_INHIBIT_SUSPEND_DURING_BACKUP, cbInhibitSuspend, "active", glib.SETTINGS_BIND_DEFAULT)
+	grid.Attach(cbInhibitSuspend, DesignSecondCol, row, 1, 1)
+	row++
+
+	// Start application at login
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgAutostartCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	eb, err = gtk.EventBoxNew()
+	if err != nil {
+		return nil, err
+	}
+	eb.Add(lbl)
+	grid.Attach(eb, DesignFirstCol, row, 1, 1)
+	cbAutostart, err := gtk.CheckButtonNew()
+	if err != nil {
+		return nil, err
+	}
+	_, err = eb.Connect("button-press-event", func() {
+		cbAutostart.SetActive(!cbAutostart.GetActive())
+	})
+	if err != nil {
+		return nil, err
+	}
+	autostartPath, err := GetAutostartFilePath()
+	if err != nil {
+		return nil, err
+	}
+	cbAutostart.SetTooltipText(locale.T(MsgPrefDlgAutostartHint,
+		struct{ Path string }{Path: autostartPath}))
+	cbAutostart.SetHAlign(gtk.ALIGN_START)
+	// Option is not kept in settings, but identified by autostart file existence.
+	autostart, err := IsAutostartEnabled()
+	if err != nil {
+		lg.Warn(err)
+	}
+	cbAutostart.SetActive(autostart)
+	grid.Attach(cbAutostart, DesignSecondCol, row, 1, 1)
+	_, err = cbAutostart.Connect("toggled", func(v *gtk.CheckButton) {
+		err := EnableAutostart(v.GetActive())
+		if err != nil {
+			lg.Warn(locale.T(MsgPrefDlgAutostartError,
+				struct{ Error error }{Error: err}))
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	row++
+
 	// UI Language
 	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgLanguageCaption, nil))
 	if err != nil {
@@ -172,23 +409,69 @@ func GeneralPreferencesNew(win *gtk.ApplicationWindow, appSettings *SettingsStor
 	}
 	cbUILanguage.SetTooltipText(locale.T(MsgPrefDlgLanguageHint, nil))
 	bh.Bind(CFG_UI_LANGUAGE, cbUILanguage, "active-id", glib.SETTINGS_BIND_DEFAULT)
+	// Language is switched on the fly by SettingsWatcher.
 	grid.Attach(cbUILanguage, DesignSecondCol, row, 1, 1)
-	initialLang := cbUILanguage.GetActiveID()
-	const restartServiceActivationMs = 500
-	restartServiceActivationTimer := time.AfterFunc(time.Millisecond*restartServiceActivationMs, func() {
-		MustIdleAdd(func() {
-			activate := initialLang != cbUILanguage.GetActiveID()
-			// Show "restart app" panel only when language has changed
-			// from original setting. Otherwise - hide panel.
-			err := prefRow.ActivateRestartService(activate)
+	row++
+
+	// UI theme
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgUIThemeCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	grid.Attach(lbl, DesignFirstCol, row, 1, 1)
+	values = []struct{ value, key string }{
+		{locale.T(MsgPrefDlgUIThemeSystemEntry, nil), THEME_SYSTEM},
+		{locale.T(MsgPrefDlgUIThemeLightEntry, nil), THEME_LIGHT},
+		{locale.T(MsgPrefDlgUIThemeDarkEntry, nil), THEME_DARK},
+	}
+	cbUITheme, err := CreateNameValueCombo(values)
+	if err != nil {
+		return nil, err
+	}
+	userCSSPath, err := GetUserCSSPath()
+	if err != nil {
+		return nil, err
+	}
+	cbUITheme.SetTooltipText(locale.T(MsgPrefDlgUIThemeHint,
+		struct{ Path string }{Path: userCSSPath}))
+	bh.Bind(CFG_UI_THEME, cbUITheme, "active-id", glib.SETTINGS_BIND_DEFAULT)
+	grid.Attach(cbUITheme, DesignSecondCol, row, 1, 1)
+	// Apply selected theme at once, without application restart.
+	_, err = cbUITheme.Connect("changed", func(v *gtk.ComboBox) {
+		if appStyles != nil {
+			err := appStyles.Apply(v.GetActiveID())
 			if err != nil {
-				lg.Fatal(err)
+				reportError(win, err)
+				return
 			}
-		})
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	row++
+
+	// Units to display data sizes
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgSizeUnitsCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	grid.Attach(lbl, DesignFirstCol, row, 1, 1)
+	values = []struct{ value, key string }{
+		{locale.T(MsgPrefDlgSizeUnitsSIEntry, nil), core.SIZE_UNITS_SI.String()},
+		{locale.T(MsgPrefDlgSizeUnitsBinaryEntry, nil), core.SIZE_UNITS_BINARY.String()},
+	}
+	cbSizeUnits, err := CreateNameValueCombo(values)
+	if err != nil {
+		return nil, err
+	}
+	cbSizeUnits.SetTooltipText(locale.T(MsgPrefDlgSizeUnitsHint, nil))
+	bh.Bind(CFG_SIZE_UNITS, cbSizeUnits, "active-id", glib.SETTINGS_BIND_DEFAULT)
+	grid.Attach(cbSizeUnits, DesignSecondCol, row, 1, 1)
+	// Apply selected units at once, sizes printed next time follow them.
+	_, err = cbSizeUnits.Connect("changed", func(v *gtk.ComboBox) {
+		core.SetSizeUnits(core.ParseSizeUnits(v.GetActiveID()))
 	})
-	_, err = cbUILanguage.Connect("changed", func(v *gtk.ComboBox, tmr *time.Timer) {
-		RestartTimer(tmr, restartServiceActivationMs)
-	}, restartServiceActivationTimer)
 	if err != nil {
 		return nil, err
 	}
@@ -283,7 +566,8 @@ func GeneralPreferencesNew(win *gtk.ApplicationWindow, appSettings *SettingsStor
 					if action == nil {
 						err := errors.New(locale.T(MsgActionDoesNotFound,
 							struct{ ActionName string }{ActionName: actionName}))
-						lg.Fatal(err)
+						reportError(win, err)
+						return
 					}
 					// close preference dialog window
 					win.Close()
@@ -338,7 +622,6 @@ func GeneralPreferencesNew(win *gtk.ApplicationWindow, appSettings *SettingsStor
 // | (vertical bar or pipe)
 // ? (question mark)
 // * (asterisk)
-//
 func GetSubpathNotAllowedCharsNotFoundRegexp() (*regexp.Regexp, error) {
 	template := spew.Sprintf(`^\%[1]c?([^\<\>\:\"\|\?\*\%[1]c]+\%[1]c?)*$`, os.PathSeparator)
 	lg.Debugf("Subpath regex template: %s", template)
@@ -359,8 +642,8 @@ func GetFolderNamesEmptyOrLeadingTrailingSpacesFoundRegexp() (*regexp.Regexp, er
 	return rexp, nil
 }
 
-func createBackupSourceBlock(profileID, sourceID string, sourceSettings *SettingsStore,
-	prefRow *PreferenceRow, validator *UIValidator) (*gtk.Container, error) {
+func createBackupSourceBlock(win *gtk.ApplicationWindow, profileID, sourceID string,
+	sourceSettings *SettingsStore, prefRow *PreferenceRow, validator *UIValidator) (*gtk.Container, error) {
 
 	box, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 6)
 	if err != nil {
@@ -391,7 +674,48 @@ func createBackupSourceBlock(profileID, sourceID string, sourceSettings *Setting
 	edRsyncPath.SetHExpand(true)
 	edRsyncPath.SetIconTooltipText(gtk.ENTRY_ICON_SECONDARY, locale.T(MsgPrefDlgSourceRsyncPathRetryHint, nil))
 
-	grid.Attach(edRsyncPath, 1, row, 1, 1)
+	// Pick module from the list exported by RSYNC daemon
+	btnDaemonModules, err := SetupButtonWithThemedImage("network-server-symbolic")
+	if err != nil {
+		return nil, err
+	}
+	btnDaemonModules.SetTooltipText(locale.T(MsgPrefDlgSourceRsyncDaemonModulesHint, nil))
+	_, err = btnDaemonModules.Connect("clicked", func() {
+		rsyncPath, err := edRsyncPath.GetText()
+		if err != nil {
+			reportError(win, err)
+			return
+		}
+		var password *string
+		authPass := sourceSettings.settings.GetString(CFG_MODULE_AUTH_PASSWORD)
+		if authPass != "" {
+			password = &authPass
+		}
+		moduleURL, err := selectDaemonModule(&win.Window, rsyncPath, password)
+		if err != nil {
+			reportError(win, err)
+			return
+		}
+		if moduleURL != "" {
+			edRsyncPath.SetText(moduleURL)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	boxRsyncPath, err := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 0)
+	if err != nil {
+		return nil, err
+	}
+	style, err := boxRsyncPath.GetStyleContext()
+	if err != nil {
+		return nil, err
+	}
+	style.AddClass("linked")
+	boxRsyncPath.PackStart(edRsyncPath, true, true, 0)
+	boxRsyncPath.PackStart(btnDaemonModules, false, false, 0)
+
+	grid.Attach(boxRsyncPath, 1, row, 1, 1)
 	row++
 
 	// Destination root path
@@ -627,64 +951,389 @@ func createBackupSourceBlock(profileID, sourceID string, sourceSettings *Setting
 	grid3.Attach(cbTransferSpecialFiles, DesignSecondCol, row3, 1, 1)
 	row3++
 
-	// Extra options
-	expExtraOptions, err := gtk.ExpanderNew(locale.T(MsgPrefDlgExtraOptionsBoxCaption, nil))
+	// Enable/disable RSYNC transfer of ACLs
+	cbTransferACLs, err := gtk.CheckButtonNew()
 	if err != nil {
 		return nil, err
 	}
-	expExtraOptions.SetTooltipText(locale.T(MsgPrefDlgExtraOptionsBoxHint, nil))
-	grid.Attach(expExtraOptions, 0, row, 2, 1)
-	row++
+	cbTransferACLs.SetLabel(locale.T(MsgPrefDlgRsyncTransferACLsCaption, nil))
+	cbTransferACLs.SetTooltipText(locale.T(MsgPrefDlgRsyncTransferACLsHint, nil))
+	cbTransferACLs.SetHAlign(gtk.ALIGN_START)
+	bh.Bind(CFG_RSYNC_TRANSFER_ACLS_INCONSISTENT, cbTransferACLs, "inconsistent", glib.SETTINGS_BIND_DEFAULT)
+	bh.Bind(CFG_RSYNC_TRANSFER_ACLS, cbTransferACLs, "active", glib.SETTINGS_BIND_DEFAULT)
+	markUnsupportedRsyncOption(cbTransferACLs, (*rsync.Capabilities).SupportsACLs)
 
-	box2, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 6)
+	cbTransferACLsHandlerEnabled := true
+	_, err = cbTransferACLs.Connect("clicked", func(checkBox *gtk.CheckButton) {
+		if cbTransferACLsHandlerEnabled {
+			if checkBox.GetInconsistent() {
+				checkBox.SetInconsistent(false)
+			} else if !checkBox.GetInconsistent() && checkBox.GetActive() {
+				checkBox.SetInconsistent(true)
+				cbTransferACLsHandlerEnabled = false
+				checkBox.SetActive(false)
+				cbTransferACLsHandlerEnabled = true
+			}
+		}
+	})
 	if err != nil {
 		return nil, err
 	}
-	SetMargins(box2, 0, 9, 0, 9)
-	expExtraOptions.Add(box2)
+	grid3.Attach(cbTransferACLs, DesignFirstCol, row3, 1, 1)
 
-	frame, err := gtk.FrameNew("")
+	// Enable/disable RSYNC transfer of extended attributes
+	cbTransferXattrs, err := gtk.CheckButtonNew()
 	if err != nil {
 		return nil, err
 	}
-	box2.PackStart(frame, true, true, 0)
+	cbTransferXattrs.SetLabel(locale.T(MsgPrefDlgRsyncTransferXattrsCaption, nil))
+	cbTransferXattrs.SetTooltipText(locale.T(MsgPrefDlgRsyncTransferXattrsHint, nil))
+	cbTransferXattrs.SetHAlign(gtk.ALIGN_START)
+	bh.Bind(CFG_RSYNC_TRANSFER_XATTRS_INCONSISTENT, cbTransferXattrs, "inconsistent", glib.SETTINGS_BIND_DEFAULT)
+	bh.Bind(CFG_RSYNC_TRANSFER_XATTRS, cbTransferXattrs, "active", glib.SETTINGS_BIND_DEFAULT)
+	markUnsupportedRsyncOption(cbTransferXattrs, (*rsync.Capabilities).SupportsXattrs)
 
-	box3, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 6)
+	cbTransferXattrsHandlerEnabled := true
+	_, err = cbTransferXattrs.Connect("clicked", func(checkBox *gtk.CheckButton) {
+		if cbTransferXattrsHandlerEnabled {
+			if checkBox.GetInconsistent() {
+				checkBox.SetInconsistent(false)
+			} else if !checkBox.GetInconsistent() && checkBox.GetActive() {
+				checkBox.SetInconsistent(true)
+				cbTransferXattrsHandlerEnabled = false
+				checkBox.SetActive(false)
+				cbTransferXattrsHandlerEnabled = true
+			}
+		}
+	})
 	if err != nil {
 		return nil, err
 	}
-	SetMargins(box3, 18, 9, 18, 9)
-	frame.Add(box3)
-
-	grid2, err := gtk.GridNew()
-	grid2.SetColumnSpacing(12)
-	grid2.SetRowSpacing(6)
-	grid2.SetHAlign(gtk.ALIGN_FILL)
-	box3.PackStart(grid2, true, true, 0)
-	row2 := 0
+	grid3.Attach(cbTransferXattrs, DesignSecondCol, row3, 1, 1)
+	row3++
 
-	// Authenticate password
-	markup = NewMarkup(MARKUP_WEIGHT_NORMAL, 0, 0,
-		locale.T(MsgPrefDlgAuthPasswordCaption, nil), "")
-	lbl, err = SetupLabelMarkupJustifyLeft(markup)
+	// Skip files larger than N MB
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgSkipFilesLargerThanCaption, nil))
 	if err != nil {
 		return nil, err
 	}
-	grid2.Attach(lbl, 0, row2, 1, 1)
-	edAuthPasswd, err := gtk.EntryNew()
+	grid3.Attach(lbl, DesignFirstCol, row3, 1, 1)
+	sbMaxFileSize, err := gtk.SpinButtonNewWithRange(0, 1000000, 1)
 	if err != nil {
 		return nil, err
 	}
-	edAuthPasswd.SetTooltipText(locale.T(MsgPrefDlgAuthPasswordHint, nil))
-	edAuthPasswd.SetHExpand(true)
-	edAuthPasswd.SetInvisibleChar('*')
-	edAuthPasswd.SetVisibility(false)
-	grid2.Attach(edAuthPasswd, 1, row2, 1, 1)
-	row2++
+	sbMaxFileSize.SetTooltipText(locale.T(MsgPrefDlgSkipFilesLargerThanHint, nil))
+	sbMaxFileSize.SetHAlign(gtk.ALIGN_START)
+	bh.Bind(CFG_MODULE_MAX_FILE_SIZE_MB, sbMaxFileSize, "value", glib.SETTINGS_BIND_DEFAULT)
+	grid3.Attach(sbMaxFileSize, DesignSecondCol, row3, 1, 1)
+	row3++
 
-	// Change file permission
-	markup = NewMarkup(MARKUP_WEIGHT_NORMAL, 0, 0,
-		locale.T(MsgPrefDlgChangeFilePermissionCaption, nil), "")
+	// Skip files older than N days
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgSkipFilesOlderThanCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	grid3.Attach(lbl, DesignFirstCol, row3, 1, 1)
+	sbMaxFileAge, err := gtk.SpinButtonNewWithRange(0, 36500, 1)
+	if err != nil {
+		return nil, err
+	}
+	sbMaxFileAge.SetTooltipText(locale.T(MsgPrefDlgSkipFilesOlderThanHint, nil))
+	sbMaxFileAge.SetHAlign(gtk.ALIGN_START)
+	bh.Bind(CFG_MODULE_MAX_FILE_AGE_DAYS, sbMaxFileAge, "value", glib.SETTINGS_BIND_DEFAULT)
+	grid3.Attach(sbMaxFileAge, DesignSecondCol, row3, 1, 1)
+	row3++
+
+	// Skip files newer than N days
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgSkipFilesNewerThanCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	grid3.Attach(lbl, DesignFirstCol, row3, 1, 1)
+	sbMinFileAge, err := gtk.SpinButtonNewWithRange(0, 36500, 1)
+	if err != nil {
+		return nil, err
+	}
+	sbMinFileAge.SetTooltipText(locale.T(MsgPrefDlgSkipFilesNewerThanHint, nil))
+	sbMinFileAge.SetHAlign(gtk.ALIGN_START)
+	bh.Bind(CFG_MODULE_MIN_FILE_AGE_DAYS, sbMinFileAge, "value", glib.SETTINGS_BIND_DEFAULT)
+	grid3.Attach(sbMinFileAge, DesignSecondCol, row3, 1, 1)
+	row3++
+
+	// Skip files and folders matching patterns
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgExcludePatternsCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	grid3.Attach(lbl, DesignFirstCol, row3, 1, 1)
+	edExcludePatterns, err := gtk.EntryNew()
+	if err != nil {
+		return nil, err
+	}
+	edExcludePatterns.SetTooltipText(locale.T(MsgPrefDlgExcludePatternsHint, nil))
+	edExcludePatterns.SetHExpand(true)
+	edExcludePatterns.SetHAlign(gtk.ALIGN_FILL)
+	bh.Bind(CFG_MODULE_EXCLUDE_PATTERNS, edExcludePatterns, "text", glib.SETTINGS_BIND_DEFAULT)
+	grid3.Attach(edExcludePatterns, DesignSecondCol, row3, 1, 1)
+	row3++
+
+	// Remap source users and groups to destination ones
+	ownershipMaps := []struct {
+		key     string
+		caption string
+		hint    string
+	}{
+		{CFG_MODULE_USER_MAP, MsgPrefDlgUserMapCaption, MsgPrefDlgUserMapHint},
+		{CFG_MODULE_GROUP_MAP, MsgPrefDlgGroupMapCaption, MsgPrefDlgGroupMapHint},
+	}
+	for _, item := range ownershipMaps {
+		lbl, err = SetupLabelJustifyRight(locale.T(item.caption, nil))
+		if err != nil {
+			return nil, err
+		}
+		lbl.SetVAlign(gtk.ALIGN_START)
+		grid3.Attach(lbl, DesignFirstCol, row3, 1, 1)
+		editor, err := createOwnershipMapEditor(sourceSettings, item.key)
+		if err != nil {
+			return nil, err
+		}
+		editor.SetTooltipText(locale.T(item.hint, nil))
+		grid3.Attach(editor, DesignSecondCol, row3, 1, 1)
+		row3++
+	}
+
+	// Force owner and group of destination files
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgChownCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	grid3.Attach(lbl, DesignFirstCol, row3, 1, 1)
+	edChown, err := gtk.EntryNew()
+	if err != nil {
+		return nil, err
+	}
+	edChown.SetTooltipText(locale.T(MsgPrefDlgChownHint, nil))
+	edChown.SetHExpand(true)
+	edChown.SetHAlign(gtk.ALIGN_FILL)
+	bh.Bind(CFG_MODULE_CHOWN, edChown, "text", glib.SETTINGS_BIND_DEFAULT)
+	grid3.Attach(edChown, DesignSecondCol, row3, 1, 1)
+	row3++
+
+	// Charset of file names in source, converted to UTF-8 via RSYNC --iconv
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgSourceCharsetCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	grid3.Attach(lbl, DesignFirstCol, row3, 1, 1)
+	charsets := []struct{ value, key string }{
+		{locale.T(MsgPrefDlgSourceCharsetNoneEntry, nil), ""},
+	}
+	for _, charset := range []string{"CP1251", "CP1252", "CP866", "KOI8-R",
+		"ISO-8859-1", "ISO-8859-15", "SHIFT_JIS", "EUC-JP", "GB18030", "BIG5"} {
+		charsets = append(charsets, struct{ value, key string }{charset, charset})
+	}
+	cbSourceCharset, err := CreateNameValueCombo(charsets)
+	if err != nil {
+		return nil, err
+	}
+	cbSourceCharset.SetTooltipText(locale.T(MsgPrefDlgSourceCharsetHint, nil))
+	bh.Bind(CFG_MODULE_SOURCE_CHARSET, cbSourceCharset, "active-id", glib.SETTINGS_BIND_DEFAULT)
+	grid3.Attach(cbSourceCharset, DesignSecondCol, row3, 1, 1)
+	row3++
+
+	// RSYNC I/O timeout override
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgModuleIOTimeoutCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	grid3.Attach(lbl, DesignFirstCol, row3, 1, 1)
+	sbIOTimeout, err := gtk.SpinButtonNewWithRange(0, 86400, 1)
+	if err != nil {
+		return nil, err
+	}
+	sbIOTimeout.SetTooltipText(locale.T(MsgPrefDlgModuleIOTimeoutHint, nil))
+	sbIOTimeout.SetHAlign(gtk.ALIGN_START)
+	bh.Bind(CFG_MODULE_IO_TIMEOUT_SEC, sbIOTimeout, "value", glib.SETTINGS_BIND_DEFAULT)
+	grid3.Attach(sbIOTimeout, DesignSecondCol, row3, 1, 1)
+	row3++
+
+	// RSYNC connection timeout override
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgModuleConnTimeoutCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	grid3.Attach(lbl, DesignFirstCol, row3, 1, 1)
+	sbConnectTimeout, err := gtk.SpinButtonNewWithRange(0, 3600, 1)
+	if err != nil {
+		return nil, err
+	}
+	sbConnectTimeout.SetTooltipText(locale.T(MsgPrefDlgModuleConnTimeoutHint, nil))
+	sbConnectTimeout.SetHAlign(gtk.ALIGN_START)
+	bh.Bind(CFG_MODULE_CONNECT_TIMEOUT_SEC, sbConnectTimeout, "value", glib.SETTINGS_BIND_DEFAULT)
+	grid3.Attach(sbConnectTimeout, DesignSecondCol, row3, 1, 1)
+	row3++
+
+	// RSYNC process CPU niceness override
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgRsyncNiceLevelCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	grid3.Attach(lbl, DesignFirstCol, row3, 1, 1)
+	sbNiceLevel, err := gtk.SpinButtonNewWithRange(0, rsync.NICE_LEVEL_MAX, 1)
+	if err != nil {
+		return nil, err
+	}
+	sbNiceLevel.SetTooltipText(locale.T(MsgPrefDlgModuleNiceLevelHint, nil))
+	sbNiceLevel.SetHAlign(gtk.ALIGN_START)
+	bh.Bind(CFG_MODULE_NICE_LEVEL, sbNiceLevel, "value", glib.SETTINGS_BIND_DEFAULT)
+	grid3.Attach(sbNiceLevel, DesignSecondCol, row3, 1, 1)
+	row3++
+
+	// RSYNC process I/O scheduling class override
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgRsyncIOClassCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	grid3.Attach(lbl, DesignFirstCol, row3, 1, 1)
+	values := []struct{ value, key string }{
+		{locale.T(MsgPrefDlgModuleIOClassGlobalEntry, nil), ""},
+		{locale.T(MsgPrefDlgRsyncIOClassDefaultEntry, nil), rsync.IO_CLASS_DEFAULT},
+		{locale.T(MsgPrefDlgRsyncIOClassBestEffortEntry, nil), rsync.IO_CLASS_BEST_EFFORT},
+		{locale.T(MsgPrefDlgRsyncIOClassIdleEntry, nil), rsync.IO_CLASS_IDLE},
+	}
+	cbIOClass, err := CreateNameValueCombo(values)
+	if err != nil {
+		return nil, err
+	}
+	cbIOClass.SetTooltipText(locale.T(MsgPrefDlgModuleIOClassHint, nil))
+	bh.Bind(CFG_MODULE_IO_CLASS, cbIOClass, "active-id", glib.SETTINGS_BIND_DEFAULT)
+	grid3.Attach(cbIOClass, DesignSecondCol, row3, 1, 1)
+	row3++
+
+	// RSYNC process I/O priority level override
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgRsyncIOLevelCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	grid3.Attach(lbl, DesignFirstCol, row3, 1, 1)
+	sbIOLevel, err := gtk.SpinButtonNewWithRange(0, rsync.IO_LEVEL_MAX, 1)
+	if err != nil {
+		return nil, err
+	}
+	sbIOLevel.SetTooltipText(locale.T(MsgPrefDlgRsyncIOLevelHint, nil))
+	sbIOLevel.SetHAlign(gtk.ALIGN_START)
+	bh.Bind(CFG_MODULE_IO_LEVEL, sbIOLevel, "value", glib.SETTINGS_BIND_DEFAULT)
+	err = bindIOLevelSensitivity(cbIOClass, lbl, sbIOLevel)
+	if err != nil {
+		return nil, err
+	}
+	grid3.Attach(sbIOLevel, DesignSecondCol, row3, 1, 1)
+	row3++
+
+	// Skip plan stage estimation (fast mode)
+	cbSkipPlanEstimation, err := gtk.CheckButtonNew()
+	if err != nil {
+		return nil, err
+	}
+	cbSkipPlanEstimation.SetLabel(locale.T(MsgPrefDlgSkipPlanEstimationCaption, nil))
+	cbSkipPlanEstimation.SetTooltipText(locale.T(MsgPrefDlgSkipPlanEstimationHint, nil))
+	cbSkipPlanEstimation.SetHAlign(gtk.ALIGN_START)
+	bh.Bind(CFG_MODULE_SKIP_PLAN_ESTIMATION, cbSkipPlanEstimation, "active", glib.SETTINGS_BIND_DEFAULT)
+	grid3.Attach(cbSkipPlanEstimation, DesignSecondCol, row3, 1, 1)
+	row3++
+
+	// Tags to run only part of profile modules
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgModuleTagsCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	grid3.Attach(lbl, DesignFirstCol, row3, 1, 1)
+	edTags, err := gtk.EntryNew()
+	if err != nil {
+		return nil, err
+	}
+	edTags.SetTooltipText(locale.T(MsgPrefDlgModuleTagsHint, nil))
+	edTags.SetHExpand(true)
+	edTags.SetHAlign(gtk.ALIGN_FILL)
+	bh.Bind(CFG_MODULE_TAGS, edTags, "text", glib.SETTINGS_BIND_DEFAULT)
+	grid3.Attach(edTags, DesignSecondCol, row3, 1, 1)
+	row3++
+
+	// Previous source URLs to find backup sessions for deduplication
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgSourceAliasesCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	grid3.Attach(lbl, DesignFirstCol, row3, 1, 1)
+	edSourceAliases, err := gtk.EntryNew()
+	if err != nil {
+		return nil, err
+	}
+	edSourceAliases.SetTooltipText(locale.T(MsgPrefDlgSourceAliasesHint, nil))
+	edSourceAliases.SetHExpand(true)
+	edSourceAliases.SetHAlign(gtk.ALIGN_FILL)
+	bh.Bind(CFG_MODULE_SOURCE_ALIASES, edSourceAliases, "text", glib.SETTINGS_BIND_DEFAULT)
+	grid3.Attach(edSourceAliases, DesignSecondCol, row3, 1, 1)
+	row3++
+
+	// Extra options
+	expExtraOptions, err := gtk.ExpanderNew(locale.T(MsgPrefDlgExtraOptionsBoxCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	expExtraOptions.SetTooltipText(locale.T(MsgPrefDlgExtraOptionsBoxHint, nil))
+	grid.Attach(expExtraOptions, 0, row, 2, 1)
+	row++
+
+	box2, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 6)
+	if err != nil {
+		return nil, err
+	}
+	SetMargins(box2, 0, 9, 0, 9)
+	expExtraOptions.Add(box2)
+
+	frame, err := gtk.FrameNew("")
+	if err != nil {
+		return nil, err
+	}
+	box2.PackStart(frame, true, true, 0)
+
+	box3, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 6)
+	if err != nil {
+		return nil, err
+	}
+	SetMargins(box3, 18, 9, 18, 9)
+	frame.Add(box3)
+
+	grid2, err := gtk.GridNew()
+	grid2.SetColumnSpacing(12)
+	grid2.SetRowSpacing(6)
+	grid2.SetHAlign(gtk.ALIGN_FILL)
+	box3.PackStart(grid2, true, true, 0)
+	row2 := 0
+
+	// Authenticate password
+	markup = NewMarkup(MARKUP_WEIGHT_NORMAL, 0, 0,
+		locale.T(MsgPrefDlgAuthPasswordCaption, nil), "")
+	lbl, err = SetupLabelMarkupJustifyLeft(markup)
+	if err != nil {
+		return nil, err
+	}
+	grid2.Attach(lbl, 0, row2, 1, 1)
+	edAuthPasswd, err := gtk.EntryNew()
+	if err != nil {
+		return nil, err
+	}
+	edAuthPasswd.SetTooltipText(locale.T(MsgPrefDlgAuthPasswordHint, nil))
+	edAuthPasswd.SetHExpand(true)
+	edAuthPasswd.SetInvisibleChar('*')
+	edAuthPasswd.SetVisibility(false)
+	grid2.Attach(edAuthPasswd, 1, row2, 1, 1)
+	row2++
+
+	// Change file permission
+	markup = NewMarkup(MARKUP_WEIGHT_NORMAL, 0, 0,
+		locale.T(MsgPrefDlgChangeFilePermissionCaption, nil), "")
 	lbl, err = SetupLabelMarkupJustifyLeft(markup)
 	if err != nil {
 		return nil, err
@@ -699,6 +1348,23 @@ func createBackupSourceBlock(profileID, sourceID string, sourceSettings *Setting
 	grid2.Attach(edChmod, 1, row2, 1, 1)
 	row2++
 
+	// Override destination root path
+	markup = NewMarkup(MARKUP_WEIGHT_NORMAL, 0, 0,
+		locale.T(MsgPrefDlgModuleDestRootPathCaption, nil), "")
+	lbl, err = SetupLabelMarkupJustifyLeft(markup)
+	if err != nil {
+		return nil, err
+	}
+	grid2.Attach(lbl, 0, row2, 1, 1)
+	edDestRootPath, err := gtk.EntryNew()
+	if err != nil {
+		return nil, err
+	}
+	edDestRootPath.SetTooltipText(locale.T(MsgPrefDlgModuleDestRootPathHint, nil))
+	edDestRootPath.SetHExpand(true)
+	grid2.Attach(edDestRootPath, 1, row2, 1, 1)
+	row2++
+
 	// Enable/disable backup block
 	markup = NewMarkup(MARKUP_WEIGHT_NORMAL, 0, 0,
 		locale.T(MsgPrefDlgEnableBackupBlockCaption, nil), "")
@@ -786,6 +1452,15 @@ func createBackupSourceBlock(profileID, sourceID string, sourceSettings *Setting
 					msg := locale.T(MsgPrefDlgSourceRsyncPathEmptyError, nil)
 					groupLock.Unlock()
 					warning = &msg
+				} else if issue := backup.ValidateModules([]backup.Module{{SourceRsync: rsyncURL,
+					MaxFileAgeDays: sourceSettings.settings.GetInt(CFG_MODULE_MAX_FILE_AGE_DAYS),
+					MinFileAgeDays: sourceSettings.settings.GetInt(CFG_MODULE_MIN_FILE_AGE_DAYS)}},
+					false).FirstError(); issue != nil {
+					// Age limits are not applicable to remote source.
+					groupLock.Lock()
+					msg := issue.Message()
+					groupLock.Unlock()
+					warning = &msg
 				} else {
 					lg.Debugf("Start rsync utility to validate rsync source")
 					//					sourceSettings, err := getBackupSourceSettings(profileID, sourceID, nil)
@@ -803,7 +1478,7 @@ func createBackupSourceBlock(profileID, sourceID string, sourceSettings *Setting
 					if err != nil {
 						lg.Debug(err)
 						if !rsync.IsProcessTerminatedError(err) {
-							msg := err.Error()
+							msg := rsync.FormatErrorWithSuggestion(err)
 							warning = &msg
 						}
 					}
@@ -837,16 +1512,19 @@ func createBackupSourceBlock(profileID, sourceID string, sourceSettings *Setting
 				if swtch.GetActive() {
 					err := RemoveStyleClass(&entry.Widget, "entry-image-right-spin")
 					if err != nil {
-						lg.Fatal(err)
+						reportError(win, err)
+						return
 					}
 					warning, ok := results[0].(*string)
 					if !ok {
-						lg.Fatal(validatorConversionError("interface{}[0]", "*string"))
+						reportError(win, validatorConversionError("interface{}[0]", "*string"))
+						return
 					}
 					if warning != nil {
 						err = AddStyleClasses(&entry.Widget, []string{"entry-image-right-error", "entry-image-right-shake"})
 						if err != nil {
-							lg.Fatal(err)
+							reportError(win, err)
+							return
 						}
 						entry.SetIconFromIconName(gtk.ENTRY_ICON_SECONDARY, STOCK_IMPORTANT_ICON)
 						markup := markupTooltip(NewMarkup(MARKUP_WEIGHT_BOLD, MARKUP_COLOR_ORANGE_RED, 0, *warning, nil),
@@ -854,14 +1532,16 @@ func createBackupSourceBlock(profileID, sourceID string, sourceSettings *Setting
 						entry.SetTooltipMarkup(markup.String())
 						err = row.AddStatus(entry.Native(), ProfileStatusError, *warning)
 						if err != nil {
-							lg.Fatal(err)
+							reportError(win, err)
+							return
 						}
 					} else {
 						entry.SetIconFromIconName(gtk.ENTRY_ICON_SECONDARY, STOCK_OK_ICON)
 						entry.SetTooltipText(RsyncSourcePathDescription)
 						err := row.RemoveStatus(entry.Native())
 						if err != nil {
-							lg.Fatal(err)
+							reportError(win, err)
+							return
 						}
 					}
 				} else {
@@ -871,7 +1551,8 @@ func createBackupSourceBlock(profileID, sourceID string, sourceSettings *Setting
 					entry.SetTooltipMarkup(markup.String())
 					err := row.RemoveStatus(entry.Native())
 					if err != nil {
-						lg.Fatal(err)
+						reportError(win, err)
+						return
 					}
 				}
 			})
@@ -882,7 +1563,8 @@ func createBackupSourceBlock(profileID, sourceID string, sourceSettings *Setting
 		MustIdleAdd(func() {
 			err := validator.Validate(rsyncPathValidatorGroup, rsyncPathValidatorIndex)
 			if err != nil {
-				lg.Fatal(err)
+				reportError(win, err)
+				return
 			}
 		})
 	})
@@ -903,7 +1585,8 @@ func createBackupSourceBlock(profileID, sourceID string, sourceSettings *Setting
 		lg.Debug("Destroy edRsyncPath")
 		err := prefRow.RemoveStatus(entry.Native())
 		if err != nil {
-			lg.Fatal(err)
+			reportError(win, err)
+			return
 		}
 		validator.RemoveEntry(rsyncPathValidateIndex)
 	})
@@ -1043,16 +1726,19 @@ func createBackupSourceBlock(profileID, sourceID string, sourceSettings *Setting
 				if swtch.GetActive() {
 					err := RemoveStyleClass(&entry.Widget, "entry-image-right-spin")
 					if err != nil {
-						lg.Fatal(err)
+						reportError(win, err)
+						return
 					}
 					warning, ok := results[0].(*string)
 					if !ok {
-						lg.Fatal(validatorConversionError("interface{}[0]", "*string"))
+						reportError(win, validatorConversionError("interface{}[0]", "*string"))
+						return
 					}
 					if warning != nil {
 						err = AddStyleClasses(&entry.Widget, []string{"entry-image-right-error", "entry-image-right-shake"})
 						if err != nil {
-							lg.Fatal(err)
+							reportError(win, err)
+							return
 						}
 						entry.SetIconFromIconName(gtk.ENTRY_ICON_SECONDARY, STOCK_IMPORTANT_ICON)
 						markup := markupTooltip(NewMarkup(MARKUP_WEIGHT_BOLD, MARKUP_COLOR_ORANGE_RED, 0, *warning, nil),
@@ -1061,14 +1747,16 @@ func createBackupSourceBlock(profileID, sourceID string, sourceSettings *Setting
 						//entry.SetTooltipText(*warning)
 						err = row.AddStatus(entry.Native(), ProfileStatusError, *warning)
 						if err != nil {
-							lg.Fatal(err)
+							reportError(win, err)
+							return
 						}
 					} else {
 						entry.SetIconFromIconName(gtk.ENTRY_ICON_SECONDARY, STOCK_OK_ICON)
 						entry.SetTooltipText(destSubpathHint)
 						err = row.RemoveStatus(entry.Native())
 						if err != nil {
-							lg.Fatal(err)
+							reportError(win, err)
+							return
 						}
 					}
 				} else {
@@ -1078,7 +1766,8 @@ func createBackupSourceBlock(profileID, sourceID string, sourceSettings *Setting
 					entry.SetTooltipMarkup(markup.String())
 					err := row.RemoveStatus(entry.Native())
 					if err != nil {
-						lg.Fatal(err)
+						reportError(win, err)
+						return
 					}
 				}
 			})
@@ -1088,7 +1777,8 @@ func createBackupSourceBlock(profileID, sourceID string, sourceSettings *Setting
 		MustIdleAdd(func() {
 			err := validator.Validate(destSubPathValidatorGroup, destSubPathValidatorIndex)
 			if err != nil {
-				lg.Fatal(err)
+				reportError(win, err)
+				return
 			}
 		})
 	})
@@ -1103,7 +1793,8 @@ func createBackupSourceBlock(profileID, sourceID string, sourceSettings *Setting
 		lg.Debug("Destroy edDestSubpath")
 		err := prefRow.RemoveStatus(entry.Native())
 		if err != nil {
-			lg.Fatal(err)
+			reportError(win, err)
+			return
 		}
 		validator.RemoveEntry(destSubPathValidateIndex)
 		RestartTimer(destSubpathChangeTimer, 50)
@@ -1124,6 +1815,7 @@ func createBackupSourceBlock(profileID, sourceID string, sourceSettings *Setting
 	bh.Bind(CFG_MODULE_DEST_SUBPATH, edDestSubpath, "text", glib.SETTINGS_BIND_DEFAULT)
 
 	bh.Bind(CFG_MODULE_CHANGE_FILE_PERMISSION, edChmod, "text", glib.SETTINGS_BIND_DEFAULT)
+	bh.Bind(CFG_MODULE_DEST_ROOT_PATH, edDestRootPath, "text", glib.SETTINGS_BIND_DEFAULT)
 	bh.Bind(CFG_MODULE_AUTH_PASSWORD, edAuthPasswd, "text", glib.SETTINGS_BIND_DEFAULT)
 
 	// Expand control's block if found that internal settings not in default state.
@@ -1133,12 +1825,30 @@ func createBackupSourceBlock(profileID, sourceID string, sourceSettings *Setting
 			!sourceSettings.settings.GetBoolean(CFG_RSYNC_TRANSFER_SOURCE_PERMISSIONS_INCONSISTENT) ||
 			!sourceSettings.settings.GetBoolean(CFG_RSYNC_RECREATE_SYMLINKS_INCONSISTENT) ||
 			!sourceSettings.settings.GetBoolean(CFG_RSYNC_TRANSFER_DEVICE_FILES_INCONSISTENT) ||
-			!sourceSettings.settings.GetBoolean(CFG_RSYNC_TRANSFER_SPECIAL_FILES_INCONSISTENT))
+			!sourceSettings.settings.GetBoolean(CFG_RSYNC_TRANSFER_SPECIAL_FILES_INCONSISTENT) ||
+			!sourceSettings.settings.GetBoolean(CFG_RSYNC_TRANSFER_ACLS_INCONSISTENT) ||
+			!sourceSettings.settings.GetBoolean(CFG_RSYNC_TRANSFER_XATTRS_INCONSISTENT) ||
+			sourceSettings.settings.GetInt(CFG_MODULE_MAX_FILE_SIZE_MB) > 0 ||
+			sourceSettings.settings.GetInt(CFG_MODULE_MAX_FILE_AGE_DAYS) > 0 ||
+			sourceSettings.settings.GetInt(CFG_MODULE_MIN_FILE_AGE_DAYS) > 0 ||
+			sourceSettings.settings.GetString(CFG_MODULE_EXCLUDE_PATTERNS) != "" ||
+			sourceSettings.settings.GetString(CFG_MODULE_USER_MAP) != "" ||
+			sourceSettings.settings.GetString(CFG_MODULE_GROUP_MAP) != "" ||
+			sourceSettings.settings.GetString(CFG_MODULE_CHOWN) != "" ||
+			sourceSettings.settings.GetString(CFG_MODULE_SOURCE_CHARSET) != "" ||
+			sourceSettings.settings.GetInt(CFG_MODULE_IO_TIMEOUT_SEC) > 0 ||
+			sourceSettings.settings.GetInt(CFG_MODULE_CONNECT_TIMEOUT_SEC) > 0 ||
+			sourceSettings.settings.GetInt(CFG_MODULE_NICE_LEVEL) > 0 ||
+			sourceSettings.settings.GetString(CFG_MODULE_IO_CLASS) != "" ||
+			sourceSettings.settings.GetBoolean(CFG_MODULE_SKIP_PLAN_ESTIMATION) ||
+			sourceSettings.settings.GetString(CFG_MODULE_TAGS) != "" ||
+			sourceSettings.settings.GetString(CFG_MODULE_SOURCE_ALIASES) != "")
 
 	// Expand control's block if found that internal settings not in default state.
 	expExtraOptions.SetExpanded(
 		sourceSettings.settings.GetString(CFG_MODULE_AUTH_PASSWORD) != "" ||
-			sourceSettings.settings.GetString(CFG_MODULE_CHANGE_FILE_PERMISSION) != "")
+			sourceSettings.settings.GetString(CFG_MODULE_CHANGE_FILE_PERMISSION) != "" ||
+			sourceSettings.settings.GetString(CFG_MODULE_DEST_ROOT_PATH) != "")
 
 	_, err = swEnabled.Connect("state-set", func(v *gtk.Switch) {
 		RestartTimer(rsyncPathChangeTimer, 50)
@@ -1147,6 +1857,15 @@ func createBackupSourceBlock(profileID, sourceID string, sourceSettings *Setting
 	if err != nil {
 		return nil, err
 	}
+	// Age limits are verified along with RSYNC source path.
+	for _, sb := range []*gtk.SpinButton{sbMaxFileAge, sbMinFileAge} {
+		_, err = sb.Connect("value-changed", func() {
+			RestartTimer(rsyncPathChangeTimer, 500)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
 	bh.Bind(CFG_MODULE_ENABLED, swEnabled, "active", glib.SETTINGS_BIND_DEFAULT)
 
 	box.PackStart(grid, true, true, 0)
@@ -1196,10 +1915,10 @@ func createBackupSourceBlock2(win *gtk.ApplicationWindow, profileSettings *Setti
 
 	sourceSettings, err := getBackupSourceSettings(profileSettings, sourceID, profileChanged)
 	if err != nil {
-		lg.Fatal(err)
+		return nil, err
 	}
 
-	box2, err := createBackupSourceBlock(profileID, sourceID, sourceSettings, prefRow, validator /*, profileChanged*/)
+	box2, err := createBackupSourceBlock(win, profileID, sourceID, sourceSettings, prefRow, validator /*, profileChanged*/)
 	if err != nil {
 		return nil, err
 	}
@@ -1241,6 +1960,8 @@ func createBackupSourceBlock2(win *gtk.ApplicationWindow, profileSettings *Setti
 		return nil, err
 	}
 	SetMargins(&srclbr.Widget, 5, 5, 5, 5)
+	// Let Tab key move focus directly to row entries.
+	srclbr.SetCanFocus(false)
 	srclbr.Add(box)
 
 	btnDeleteSource, err := SetupButtonWithThemedImage(STOCK_DELETE_ICON)
@@ -1250,6 +1971,8 @@ func createBackupSourceBlock2(win *gtk.ApplicationWindow, profileSettings *Setti
 	btnDeleteSource.SetVAlign(gtk.ALIGN_START)
 	btnDeleteSource.SetHAlign(gtk.ALIGN_CENTER)
 	btnDeleteSource.SetTooltipText(locale.T(MsgPrefDlgDeleteBackupBlockHint, nil))
+	SetAccessibleNameAndDescription(&btnDeleteSource.Widget,
+		locale.T(MsgPrefDlgDeleteBackupBlockHint, nil), "")
 	_, err = btnDeleteSource.Connect("clicked", func(btn *gtk.Button, box *gtk.ListBoxRow) {
 		title := locale.T(MsgPrefDlgDeleteBackupBlockDialogTitle, nil)
 		titleMarkup := NewMarkup(MARKUP_SIZE_LARGER, 0, 0, nil, nil,
@@ -1260,7 +1983,8 @@ func createBackupSourceBlock2(win *gtk.ApplicationWindow, profileSettings *Setti
 			struct{ YesButton string }{YesButton: yesButtonMarkup.String()})
 		responseYes, err := questionDialog(&win.Window, titleMarkup.String(), textMarkup, true, true, false)
 		if err != nil {
-			lg.Fatal(err)
+			reportError(win, err)
+			return
 		}
 
 		if responseYes {
@@ -1270,7 +1994,8 @@ func createBackupSourceBlock2(win *gtk.ApplicationWindow, profileSettings *Setti
 			sarr := profileSettings.NewSettingsArray(CFG_SOURCE_LIST)
 			err = sarr.DeleteNode(sourceSettings, sourceID)
 			if err != nil {
-				lg.Fatal(err)
+				reportError(win, err)
+				return
 			}
 			prefRow.EnableDisableDeleteButtonsAndRecalculateIndexes()
 		}
@@ -1334,7 +2059,8 @@ func ProfilePreferencesNew(win *gtk.ApplicationWindow, appSettings *SettingsStor
 			if current == nil {
 				sep, err := gtk.SeparatorNew(gtk.ORIENTATION_HORIZONTAL)
 				if err != nil {
-					lg.Fatal(err)
+					reportError(win, err)
+					return
 				}
 				row.SetHeader(&sep.Widget)
 			}
@@ -1346,6 +2072,10 @@ func ProfilePreferencesNew(win *gtk.ApplicationWindow, appSettings *SettingsStor
 	if err != nil {
 		return nil, "", err
 	}
+	identity, err := getProfileIdentity(profileSettings)
+	if err != nil {
+		return nil, "", err
+	}
 
 	sarr := profileSettings.NewSettingsArray(CFG_SOURCE_LIST)
 
@@ -1461,7 +2191,8 @@ func ProfilePreferencesNew(win *gtk.ApplicationWindow, appSettings *SettingsStor
 		// Use groupLock object, to limit simultaneous access to some not-thread-safe resources.
 		func(groupLock *sync.Mutex, data *ValidatorData, results []interface{}) error {
 			groupLock.Lock()
-			profileNameHint := locale.T(MsgPrefDlgProfileNameHint, nil)
+			profileNameHint := locale.T(MsgPrefDlgProfileNameHint,
+				struct{ ProfileID string }{ProfileID: identity.ID})
 			groupLock.Unlock()
 			entry, ok := data.Items[0].(*gtk.Entry)
 			if !ok {
@@ -1480,7 +2211,8 @@ func ProfilePreferencesNew(win *gtk.ApplicationWindow, appSettings *SettingsStor
 				if warning != nil {
 					err := AddStyleClasses(&entry.Widget, []string{"entry-image-right-error", "entry-image-right-shake"})
 					if err != nil {
-						lg.Fatal(err)
+						reportError(win, err)
+						return
 					}
 					entry.SetIconFromIconName(gtk.ENTRY_ICON_SECONDARY, STOCK_IMPORTANT_ICON)
 					markup := markupTooltip(NewMarkup(MARKUP_WEIGHT_BOLD, MARKUP_COLOR_ORANGE_RED, 0, *warning, nil),
@@ -1488,14 +2220,16 @@ func ProfilePreferencesNew(win *gtk.ApplicationWindow, appSettings *SettingsStor
 					entry.SetTooltipMarkup(markup.String())
 					err = row.AddStatus(entry.Native(), ProfileStatusError, *warning)
 					if err != nil {
-						lg.Fatal(err)
+						reportError(win, err)
+						return
 					}
 				} else {
 					entry.SetIconFromIconName(gtk.ENTRY_ICON_SECONDARY, "")
 					entry.SetTooltipText(profileNameHint)
 					err = row.RemoveStatus(entry.Native())
 					if err != nil {
-						lg.Fatal(err)
+						reportError(win, err)
+						return
 					}
 				}
 			})
@@ -1507,12 +2241,14 @@ func ProfilePreferencesNew(win *gtk.ApplicationWindow, appSettings *SettingsStor
 		MustIdleAdd(func() {
 			name, err := edProfileName.GetText()
 			if err != nil {
-				lg.Fatal(err)
+				reportError(win, err)
+				return
 			}
 			prefRow.SetName(name)
 			err = validator.Validate(profileValidatorGroup, profileValidatorIndex)
 			if err != nil {
-				lg.Fatal(err)
+				reportError(win, err)
+				return
 			}
 		})
 	})
@@ -1526,7 +2262,8 @@ func ProfilePreferencesNew(win *gtk.ApplicationWindow, appSettings *SettingsStor
 		validator.RemoveEntry(profileValidateIndex)
 		err = validator.Validate(profileValidatorGroup, profileValidatorIndex)
 		if err != nil {
-			lg.Fatal(err)
+			reportError(win, err)
+			return
 		}
 	})
 	if err != nil {
@@ -1572,159 +2309,1334 @@ func ProfilePreferencesNew(win *gtk.ApplicationWindow, appSettings *SettingsStor
 	grid.Attach(destFolder, 1, row, 1, 1)
 	row++
 
+	// Mount destination device or network share before backup
 	markup = NewMarkup(MARKUP_WEIGHT_BOLD, 0, 0,
-		locale.T(MsgPrefDlgSourcesCaption, nil), "")
+		locale.T(MsgPrefDlgMountDestinationCaption, nil), "")
 	lbl, err = SetupLabelMarkupJustifyLeft(markup)
 	if err != nil {
 		return nil, "", err
 	}
 	grid.Attach(lbl, 0, row, 1, 1)
-
-	btnAddSource, err := SetupButtonWithThemedImage("list-add-symbolic")
+	cbMountDestination, err := gtk.CheckButtonNew()
 	if err != nil {
 		return nil, "", err
 	}
-	btnAddSource.SetTooltipText(locale.T(MsgPrefDlgAddBackupBlockHint, nil))
-	_, err = btnAddSource.Connect("clicked", func() {
-		sarr := profileSettings.NewSettingsArray(CFG_SOURCE_LIST)
-		sourceID, err := sarr.AddNode()
-		if err != nil {
-			lg.Fatal(err)
-		}
-
-		cntr, err := createBackupSourceBlock2(win, profileSettings, profileID,
-			sourceID, prefRow, validator, profileChanged)
-		if err != nil {
-			lg.Fatal(err)
-		}
-
-		srclb.Add(cntr)
-
-		srclb.ShowAll()
+	cbMountDestination.SetTooltipText(locale.T(MsgPrefDlgMountDestinationHint, nil))
+	cbMountDestination.SetHAlign(gtk.ALIGN_START)
+	profileBH.Bind(CFG_PROFILE_DEST_MOUNT_ENABLED, cbMountDestination, "active", glib.SETTINGS_BIND_DEFAULT)
+	grid.Attach(cbMountDestination, 1, row, 1, 1)
+	row++
 
-		destSubPathValidatorGroup := "DestSubpath"
-		destSubPathValidatorIndex := profileID
-		err = validator.Validate(destSubPathValidatorGroup, destSubPathValidatorIndex)
-		if err != nil {
-			lg.Fatal(err)
-		}
-	})
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgMountDestinationSpecCaption, nil))
 	if err != nil {
 		return nil, "", err
 	}
-
-	box2, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 6)
+	profileBH.Bind(CFG_PROFILE_DEST_MOUNT_ENABLED, lbl, "sensitive", glib.SETTINGS_BIND_GET)
+	grid.Attach(lbl, 0, row, 1, 1)
+	edMountSpec, err := gtk.EntryNew()
 	if err != nil {
 		return nil, "", err
 	}
-	SetAllMargins(box2, 18)
-	box2.Add(grid)
-	box2.Add(frame)
-	box2.Add(btnAddSource)
+	edMountSpec.SetTooltipText(locale.T(MsgPrefDlgMountDestinationSpecHint, nil))
+	edMountSpec.SetHExpand(true)
+	edMountSpec.SetHAlign(gtk.ALIGN_FILL)
+	profileBH.Bind(CFG_PROFILE_DEST_MOUNT_SPEC, edMountSpec, "text", glib.SETTINGS_BIND_DEFAULT)
+	profileBH.Bind(CFG_PROFILE_DEST_MOUNT_ENABLED, edMountSpec, "sensitive", glib.SETTINGS_BIND_GET)
+	grid.Attach(edMountSpec, 1, row, 1, 1)
+	row++
 
-	vp, err := gtk.ViewportNew(nil, nil)
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgUnmountDestinationCaption, nil))
 	if err != nil {
 		return nil, "", err
 	}
-	vp.Add(box2)
-
-	sw.Add(vp)
-	_, err = sw.Connect("destroy", func(b gtk.IWidget) {
-		appBH.Unbind()
-		profileBH.Unbind()
-	})
+	profileBH.Bind(CFG_PROFILE_DEST_MOUNT_ENABLED, lbl, "sensitive", glib.SETTINGS_BIND_GET)
+	grid.Attach(lbl, 0, row, 1, 1)
+	cbUnmountDestination, err := gtk.CheckButtonNew()
 	if err != nil {
 		return nil, "", err
 	}
+	cbUnmountDestination.SetTooltipText(locale.T(MsgPrefDlgUnmountDestinationHint, nil))
+	cbUnmountDestination.SetHAlign(gtk.ALIGN_START)
+	profileBH.Bind(CFG_PROFILE_DEST_UNMOUNT_AFTER_BACKUP, cbUnmountDestination, "active", glib.SETTINGS_BIND_DEFAULT)
+	profileBH.Bind(CFG_PROFILE_DEST_MOUNT_ENABLED, cbUnmountDestination, "sensitive", glib.SETTINGS_BIND_GET)
+	grid.Attach(cbUnmountDestination, 1, row, 1, 1)
+	row++
 
-	name := profileSettings.settings.GetString(CFG_PROFILE_NAME)
-	return &sw.Container, name, nil
-}
+	// Flush destination and eject removable drive after backup
+	for _, item := range []struct {
+		key     string
+		caption string
+		hint    string
+	}{
+		{CFG_PROFILE_DEST_SYNC_AFTER_BACKUP, MsgPrefDlgSyncDestinationCaption,
+			MsgPrefDlgSyncDestinationHint},
+		{CFG_PROFILE_DEST_EJECT_AFTER_BACKUP, MsgPrefDlgEjectDestinationCaption,
+			MsgPrefDlgEjectDestinationHint},
+	} {
+		lbl, err = SetupLabelJustifyRight(locale.T(item.caption, nil))
+		if err != nil {
+			return nil, "", err
+		}
+		grid.Attach(lbl, 0, row, 1, 1)
+		cbDestination, err := gtk.CheckButtonNew()
+		if err != nil {
+			return nil, "", err
+		}
+		cbDestination.SetTooltipText(locale.T(item.hint, nil))
+		cbDestination.SetHAlign(gtk.ALIGN_START)
+		profileBH.Bind(item.key, cbDestination, "active", glib.SETTINGS_BIND_DEFAULT)
+		grid.Attach(cbDestination, 1, row, 1, 1)
+		row++
+	}
 
-// AdvancedPreferencesNew create preference dialog with "Advanced" page, where controls
-// bound to GLib Setting object for save/restore functionality.
-func AdvancedPreferencesNew(appSettings *SettingsStore, prefRow *PreferenceRow) (*gtk.Container, error) {
-	box, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 6)
+	// Removable drive, which select profile once plugged in
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgDestDriveUUIDCaption, nil))
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
-	SetAllMargins(box, 18)
-
+	grid.Attach(lbl, 0, row, 1, 1)
+	boxDrive, err := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 0)
+	if err != nil {
+		return nil, "", err
+	}
+	boxDrive.SetHExpand(true)
+	style, err := boxDrive.GetStyleContext()
+	if err != nil {
+		return nil, "", err
+	}
+	style.AddClass("linked")
+	edDriveUUID, err := gtk.EntryNew()
+	if err != nil {
+		return nil, "", err
+	}
+	edDriveUUID.SetTooltipText(locale.T(MsgPrefDlgDestDriveUUIDHint, nil))
+	edDriveUUID.SetHExpand(true)
+	profileBH.Bind(CFG_PROFILE_DEST_DRIVE_UUID, edDriveUUID, "text", glib.SETTINGS_BIND_DEFAULT)
+	boxDrive.PackStart(edDriveUUID, true, true, 0)
+	btnDetectDrive, err := SetupButtonWithThemedImage("drive-removable-media-symbolic")
+	if err != nil {
+		return nil, "", err
+	}
+	btnDetectDrive.SetTooltipText(locale.T(MsgPrefDlgDestDriveDetectHint, nil))
+	_, err = btnDetectDrive.Connect("clicked", func() {
+		err := detectDestinationDrive(win, profileSettings)
+		if err != nil {
+			reportError(win, err)
+			return
+		}
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	boxDrive.PackStart(btnDetectDrive, false, false, 0)
+	grid.Attach(boxDrive, 1, row, 1, 1)
+	row++
+
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgDestDriveSubpathCaption, nil))
+	if err != nil {
+		return nil, "", err
+	}
+	grid.Attach(lbl, 0, row, 1, 1)
+	edDriveSubpath, err := gtk.EntryNew()
+	if err != nil {
+		return nil, "", err
+	}
+	edDriveSubpath.SetTooltipText(locale.T(MsgPrefDlgDestDriveSubpathHint, nil))
+	edDriveSubpath.SetHExpand(true)
+	edDriveSubpath.SetHAlign(gtk.ALIGN_FILL)
+	profileBH.Bind(CFG_PROFILE_DEST_DRIVE_SUBPATH, edDriveSubpath, "text", glib.SETTINGS_BIND_DEFAULT)
+	grid.Attach(edDriveSubpath, 1, row, 1, 1)
+	row++
+
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgDestDriveAskBackupCaption, nil))
+	if err != nil {
+		return nil, "", err
+	}
+	grid.Attach(lbl, 0, row, 1, 1)
+	cbDriveAskBackup, err := gtk.CheckButtonNew()
+	if err != nil {
+		return nil, "", err
+	}
+	cbDriveAskBackup.SetTooltipText(locale.T(MsgPrefDlgDestDriveAskBackupHint, nil))
+	cbDriveAskBackup.SetHAlign(gtk.ALIGN_START)
+	profileBH.Bind(CFG_PROFILE_DEST_DRIVE_ASK_BACKUP, cbDriveAskBackup, "active", glib.SETTINGS_BIND_DEFAULT)
+	grid.Attach(cbDriveAskBackup, 1, row, 1, 1)
+	row++
+
+	// Mirror backup session to other destination roots
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgMirrorDestPathsCaption, nil))
+	if err != nil {
+		return nil, "", err
+	}
+	grid.Attach(lbl, 0, row, 1, 1)
+	edMirrorDestPaths, err := gtk.EntryNew()
+	if err != nil {
+		return nil, "", err
+	}
+	edMirrorDestPaths.SetTooltipText(locale.T(MsgPrefDlgMirrorDestPathsHint, nil))
+	edMirrorDestPaths.SetHExpand(true)
+	edMirrorDestPaths.SetHAlign(gtk.ALIGN_FILL)
+	profileBH.Bind(CFG_PROFILE_MIRROR_DEST_PATHS, edMirrorDestPaths, "text", glib.SETTINGS_BIND_DEFAULT)
+	grid.Attach(edMirrorDestPaths, 1, row, 1, 1)
+	row++
+
+	// Mirror backup session to cloud storage with rclone
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgCloudSyncCaption, nil))
+	if err != nil {
+		return nil, "", err
+	}
+	grid.Attach(lbl, 0, row, 1, 1)
+	cbCloudSync, err := gtk.CheckButtonNew()
+	if err != nil {
+		return nil, "", err
+	}
+	cbCloudSync.SetTooltipText(locale.T(MsgPrefDlgCloudSyncHint, nil))
+	cbCloudSync.SetHAlign(gtk.ALIGN_START)
+	profileBH.Bind(CFG_PROFILE_CLOUD_SYNC_ENABLED, cbCloudSync, "active", glib.SETTINGS_BIND_DEFAULT)
+	grid.Attach(cbCloudSync, 1, row, 1, 1)
+	row++
+
+	for _, item := range []struct {
+		key     string
+		caption string
+		hint    string
+	}{
+		{CFG_PROFILE_CLOUD_SYNC_REMOTE, MsgPrefDlgCloudSyncRemoteCaption,
+			MsgPrefDlgCloudSyncRemoteHint},
+		{CFG_PROFILE_CLOUD_SYNC_PATH, MsgPrefDlgCloudSyncPathCaption,
+			MsgPrefDlgCloudSyncPathHint},
+	} {
+		lbl, err = SetupLabelJustifyRight(locale.T(item.caption, nil))
+		if err != nil {
+			return nil, "", err
+		}
+		profileBH.Bind(CFG_PROFILE_CLOUD_SYNC_ENABLED, lbl, "sensitive", glib.SETTINGS_BIND_GET)
+		grid.Attach(lbl, 0, row, 1, 1)
+		edCloudSync, err := gtk.EntryNew()
+		if err != nil {
+			return nil, "", err
+		}
+		edCloudSync.SetTooltipText(locale.T(item.hint, nil))
+		edCloudSync.SetHExpand(true)
+		edCloudSync.SetHAlign(gtk.ALIGN_FILL)
+		profileBH.Bind(item.key, edCloudSync, "text", glib.SETTINGS_BIND_DEFAULT)
+		profileBH.Bind(CFG_PROFILE_CLOUD_SYNC_ENABLED, edCloudSync, "sensitive", glib.SETTINGS_BIND_GET)
+		grid.Attach(edCloudSync, 1, row, 1, 1)
+		row++
+	}
+
+	// Snapshot btrfs/ZFS destination after backup
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgSnapshotModeCaption, nil))
+	if err != nil {
+		return nil, "", err
+	}
+	grid.Attach(lbl, 0, row, 1, 1)
+	values := []struct{ value, key string }{
+		{locale.T(MsgPrefDlgSnapshotModeNoneEntry, nil), ""},
+		{locale.T(MsgPrefDlgSnapshotModeAppendEntry, nil), backup.SNAPSHOT_MODE_APPEND},
+		{locale.T(MsgPrefDlgSnapshotModeReplaceEntry, nil), backup.SNAPSHOT_MODE_REPLACE},
+	}
+	cbSnapshotMode, err := CreateNameValueCombo(values)
+	if err != nil {
+		return nil, "", err
+	}
+	cbSnapshotMode.SetTooltipText(locale.T(MsgPrefDlgSnapshotModeHint, nil))
+	cbSnapshotMode.SetHAlign(gtk.ALIGN_START)
+	profileBH.Bind(CFG_PROFILE_SNAPSHOT_MODE, cbSnapshotMode, "active-id", glib.SETTINGS_BIND_DEFAULT)
+	grid.Attach(cbSnapshotMode, 1, row, 1, 1)
+	row++
+
+	lblSnapshotNameTemplate, err := SetupLabelJustifyRight(locale.T(MsgPrefDlgSnapshotNameTemplateCaption, nil))
+	if err != nil {
+		return nil, "", err
+	}
+	grid.Attach(lblSnapshotNameTemplate, 0, row, 1, 1)
+	edSnapshotNameTemplate, err := gtk.EntryNew()
+	if err != nil {
+		return nil, "", err
+	}
+	edSnapshotNameTemplate.SetTooltipText(locale.T(MsgPrefDlgSnapshotNameTemplateHint, nil))
+	edSnapshotNameTemplate.SetHExpand(true)
+	edSnapshotNameTemplate.SetHAlign(gtk.ALIGN_FILL)
+	profileBH.Bind(CFG_PROFILE_SNAPSHOT_NAME_TEMPLATE, edSnapshotNameTemplate, "text", glib.SETTINGS_BIND_DEFAULT)
+	grid.Attach(edSnapshotNameTemplate, 1, row, 1, 1)
+	row++
+
+	lblSnapshotKeep, err := SetupLabelJustifyRight(locale.T(MsgPrefDlgSnapshotKeepCaption, nil))
+	if err != nil {
+		return nil, "", err
+	}
+	grid.Attach(lblSnapshotKeep, 0, row, 1, 1)
+	sbSnapshotKeep, err := gtk.SpinButtonNewWithRange(0, 10000, 1)
+	if err != nil {
+		return nil, "", err
+	}
+	sbSnapshotKeep.SetTooltipText(locale.T(MsgPrefDlgSnapshotKeepHint, nil))
+	sbSnapshotKeep.SetHAlign(gtk.ALIGN_START)
+	profileBH.Bind(CFG_PROFILE_SNAPSHOT_KEEP, sbSnapshotKeep, "value", glib.SETTINGS_BIND_DEFAULT)
+	grid.Attach(sbSnapshotKeep, 1, row, 1, 1)
+	row++
+	err = bindSnapshotSensitivity(cbSnapshotMode, lblSnapshotNameTemplate, edSnapshotNameTemplate,
+		lblSnapshotKeep, sbSnapshotKeep)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Action on RSYNC source critical error
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgModuleErrorPolicyCaption, nil))
+	if err != nil {
+		return nil, "", err
+	}
+	grid.Attach(lbl, 0, row, 1, 1)
+	values = []struct{ value, key string }{
+		{locale.T(MsgPrefDlgModuleErrorPolicyAbortEntry, nil), string(backup.MODULE_ERROR_POLICY_ABORT)},
+		{locale.T(MsgPrefDlgModuleErrorPolicySkipEntry, nil), string(backup.MODULE_ERROR_POLICY_SKIP)},
+		{locale.T(MsgPrefDlgModuleErrorPolicyAskEntry, nil), string(backup.MODULE_ERROR_POLICY_ASK)},
+	}
+	cbModuleErrorPolicy, err := CreateNameValueCombo(values)
+	if err != nil {
+		return nil, "", err
+	}
+	cbModuleErrorPolicy.SetTooltipText(locale.T(MsgPrefDlgModuleErrorPolicyHint, nil))
+	cbModuleErrorPolicy.SetHAlign(gtk.ALIGN_START)
+	profileBH.Bind(CFG_PROFILE_MODULE_ERROR_POLICY, cbModuleErrorPolicy, "active-id", glib.SETTINGS_BIND_DEFAULT)
+	grid.Attach(cbModuleErrorPolicy, 1, row, 1, 1)
+	row++
+
+	// What backup session log records
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgSessionLogVerbosityCaption, nil))
+	if err != nil {
+		return nil, "", err
+	}
+	grid.Attach(lbl, 0, row, 1, 1)
+	values = []struct{ value, key string }{
+		{locale.T(MsgPrefDlgSessionLogVerbosityQuietEntry, nil), string(backup.LOG_VERBOSITY_QUIET)},
+		{locale.T(MsgPrefDlgSessionLogVerbosityNormalEntry, nil), string(backup.LOG_VERBOSITY_NORMAL)},
+		{locale.T(MsgPrefDlgSessionLogVerbosityVerboseEntry, nil), string(backup.LOG_VERBOSITY_VERBOSE)},
+		{locale.T(MsgPrefDlgSessionLogVerbosityDebugEntry, nil), string(backup.LOG_VERBOSITY_DEBUG)},
+	}
+	cbSessionLogVerbosity, err := CreateNameValueCombo(values)
+	if err != nil {
+		return nil, "", err
+	}
+	cbSessionLogVerbosity.SetTooltipText(locale.T(MsgPrefDlgSessionLogVerbosityHint, nil))
+	cbSessionLogVerbosity.SetHAlign(gtk.ALIGN_START)
+	profileBH.Bind(CFG_PROFILE_SESSION_LOG_VERBOSITY, cbSessionLogVerbosity, "active-id", glib.SETTINGS_BIND_DEFAULT)
+	grid.Attach(cbSessionLogVerbosity, 1, row, 1, 1)
+	row++
+
+	// Destination usage budget
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgQuotaSizeCaption, nil))
+	if err != nil {
+		return nil, "", err
+	}
+	grid.Attach(lbl, 0, row, 1, 1)
+	sbQuotaSize, err := gtk.SpinButtonNewWithRange(0, 1000000, 1)
+	if err != nil {
+		return nil, "", err
+	}
+	sbQuotaSize.SetTooltipText(locale.T(MsgPrefDlgQuotaSizeHint, nil))
+	sbQuotaSize.SetHAlign(gtk.ALIGN_START)
+	profileBH.Bind(CFG_PROFILE_QUOTA_SIZE_GB, sbQuotaSize, "value", glib.SETTINGS_BIND_DEFAULT)
+	grid.Attach(sbQuotaSize, 1, row, 1, 1)
+	row++
+
+	lblQuotaPolicy, err := SetupLabelJustifyRight(locale.T(MsgPrefDlgQuotaPolicyCaption, nil))
+	if err != nil {
+		return nil, "", err
+	}
+	grid.Attach(lblQuotaPolicy, 0, row, 1, 1)
+	values = []struct{ value, key string }{
+		{locale.T(MsgPrefDlgQuotaPolicyWarnEntry, nil), string(backup.QUOTA_POLICY_WARN)},
+		{locale.T(MsgPrefDlgQuotaPolicyPruneEntry, nil), string(backup.QUOTA_POLICY_PRUNE)},
+		{locale.T(MsgPrefDlgQuotaPolicyAbortEntry, nil), string(backup.QUOTA_POLICY_ABORT)},
+	}
+	cbQuotaPolicy, err := CreateNameValueCombo(values)
+	if err != nil {
+		return nil, "", err
+	}
+	cbQuotaPolicy.SetTooltipText(locale.T(MsgPrefDlgQuotaPolicyHint, nil))
+	cbQuotaPolicy.SetHAlign(gtk.ALIGN_START)
+	profileBH.Bind(CFG_PROFILE_QUOTA_POLICY, cbQuotaPolicy, "active-id", glib.SETTINGS_BIND_DEFAULT)
+	grid.Attach(cbQuotaPolicy, 1, row, 1, 1)
+	row++
+	err = bindQuotaSensitivity(sbQuotaSize, lblQuotaPolicy, cbQuotaPolicy)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Restrict time and conditions when backup may run
+	markup = NewMarkup(MARKUP_WEIGHT_BOLD, 0, 0,
+		locale.T(MsgPrefDlgBackupWindowCaption, nil), "")
+	lbl, err = SetupLabelMarkupJustifyLeft(markup)
+	if err != nil {
+		return nil, "", err
+	}
+	grid.Attach(lbl, 0, row, 1, 1)
+	cbBackupWindow, err := gtk.CheckButtonNew()
+	if err != nil {
+		return nil, "", err
+	}
+	cbBackupWindow.SetTooltipText(locale.T(MsgPrefDlgBackupWindowHint, nil))
+	cbBackupWindow.SetHAlign(gtk.ALIGN_START)
+	profileBH.Bind(CFG_PROFILE_BACKUP_WINDOW_ENABLED, cbBackupWindow, "active", glib.SETTINGS_BIND_DEFAULT)
+	grid.Attach(cbBackupWindow, 1, row, 1, 1)
+	row++
+
+	for _, item := range []struct {
+		key     string
+		caption string
+	}{
+		{CFG_PROFILE_BACKUP_WINDOW_START, MsgPrefDlgBackupWindowStartCaption},
+		{CFG_PROFILE_BACKUP_WINDOW_END, MsgPrefDlgBackupWindowEndCaption},
+	} {
+		lbl, err = SetupLabelJustifyRight(locale.T(item.caption, nil))
+		if err != nil {
+			return nil, "", err
+		}
+		profileBH.Bind(CFG_PROFILE_BACKUP_WINDOW_ENABLED, lbl, "sensitive", glib.SETTINGS_BIND_GET)
+		grid.Attach(lbl, 0, row, 1, 1)
+		edTime, err := gtk.EntryNew()
+		if err != nil {
+			return nil, "", err
+		}
+		edTime.SetTooltipText(locale.T(MsgPrefDlgBackupWindowTimeHint, nil))
+		edTime.SetWidthChars(5)
+		edTime.SetMaxLength(5)
+		edTime.SetHAlign(gtk.ALIGN_START)
+		profileBH.Bind(item.key, edTime, "text", glib.SETTINGS_BIND_DEFAULT)
+		profileBH.Bind(CFG_PROFILE_BACKUP_WINDOW_ENABLED, edTime, "sensitive", glib.SETTINGS_BIND_GET)
+		grid.Attach(edTime, 1, row, 1, 1)
+		row++
+	}
+
+	for _, item := range []struct {
+		key     string
+		caption string
+		hint    string
+	}{
+		{CFG_PROFILE_BACKUP_WINDOW_REQUIRE_AC_POWER, MsgPrefDlgBackupWindowRequireACPowerCaption,
+			MsgPrefDlgBackupWindowRequireACPowerHint},
+		{CFG_PROFILE_BACKUP_WINDOW_REQUIRE_UNMETERED, MsgPrefDlgBackupWindowRequireUnmeteredCaption,
+			MsgPrefDlgBackupWindowRequireUnmeteredHint},
+	} {
+		lbl, err = SetupLabelJustifyRight(locale.T(item.caption, nil))
+		if err != nil {
+			return nil, "", err
+		}
+		profileBH.Bind(CFG_PROFILE_BACKUP_WINDOW_ENABLED, lbl, "sensitive", glib.SETTINGS_BIND_GET)
+		grid.Attach(lbl, 0, row, 1, 1)
+		cbRequire, err := gtk.CheckButtonNew()
+		if err != nil {
+			return nil, "", err
+		}
+		cbRequire.SetTooltipText(locale.T(item.hint, nil))
+		cbRequire.SetHAlign(gtk.ALIGN_START)
+		profileBH.Bind(item.key, cbRequire, "active", glib.SETTINGS_BIND_DEFAULT)
+		profileBH.Bind(CFG_PROFILE_BACKUP_WINDOW_ENABLED, cbRequire, "sensitive", glib.SETTINGS_BIND_GET)
+		grid.Attach(cbRequire, 1, row, 1, 1)
+		row++
+	}
+
+	markup = NewMarkup(MARKUP_WEIGHT_BOLD, 0, 0,
+		locale.T(MsgPrefDlgSourcesCaption, nil), "")
+	lbl, err = SetupLabelMarkupJustifyLeft(markup)
+	if err != nil {
+		return nil, "", err
+	}
+	grid.Attach(lbl, 0, row, 1, 1)
+
+	btnAddSource, err := SetupButtonWithThemedImage("list-add-symbolic")
+	if err != nil {
+		return nil, "", err
+	}
+	btnAddSource.SetTooltipText(locale.T(MsgPrefDlgAddBackupBlockHint, nil))
+	// Add new backup source block, pre-filled from preset, if specified.
+	addSource := func(preset *backup.ModulePreset) {
+		sarr := profileSettings.NewSettingsArray(CFG_SOURCE_LIST)
+		sourceID, err := sarr.AddNode()
+		if err != nil {
+			reportError(win, err)
+			return
+		}
+
+		if preset != nil {
+			sourceSettings, err := getBackupSourceSettings(profileSettings, sourceID, profileChanged)
+			if err != nil {
+				reportError(win, err)
+				return
+			}
+			sourceRsync, err := preset.GetSourceRsync()
+			if err != nil {
+				reportError(win, err)
+				return
+			}
+			sourceSettings.settings.SetString(CFG_MODULE_RSYNC_SOURCE_PATH, sourceRsync)
+			sourceSettings.settings.SetString(CFG_MODULE_DEST_SUBPATH, preset.DestSubPath)
+			sourceSettings.settings.SetString(CFG_MODULE_EXCLUDE_PATTERNS,
+				strings.Join(preset.ExcludePatterns, EXCLUDE_PATTERNS_SEPARATOR+" "))
+		}
+
+		cntr, err := createBackupSourceBlock2(win, profileSettings, profileID,
+			sourceID, prefRow, validator, profileChanged)
+		if err != nil {
+			reportError(win, err)
+			return
+		}
+
+		srclb.Add(cntr)
+
+		srclb.ShowAll()
+
+		destSubPathValidatorGroup := "DestSubpath"
+		destSubPathValidatorIndex := profileID
+		err = validator.Validate(destSubPathValidatorGroup, destSubPathValidatorIndex)
+		if err != nil {
+			reportError(win, err)
+			return
+		}
+	}
+	_, err = btnAddSource.Connect("clicked", func() {
+		addSource(nil)
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Add new backup source block from preset library
+	btnAddSourceFromTemplate, err := SetupMenuButtonWithThemedImage("document-new-symbolic")
+	if err != nil {
+		return nil, "", err
+	}
+	btnAddSourceFromTemplate.SetTooltipText(locale.T(MsgPrefDlgAddBackupBlockFromTemplateHint, nil))
+	menuTemplates, err := gtk.MenuNew()
+	if err != nil {
+		return nil, "", err
+	}
+	for _, preset := range backup.GetModulePresets() {
+		preset := preset
+		item, err := gtk.MenuItemNewWithLabel(preset.Name())
+		if err != nil {
+			return nil, "", err
+		}
+		_, err = item.Connect("activate", func() {
+			addSource(&preset)
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		menuTemplates.Append(item)
+	}
+	menuTemplates.ShowAll()
+	btnAddSourceFromTemplate.SetPopup(menuTemplates)
+
+	boxAddSource, err := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 6)
+	if err != nil {
+		return nil, "", err
+	}
+	boxAddSource.PackStart(btnAddSource, true, true, 0)
+	boxAddSource.PackStart(btnAddSourceFromTemplate, false, false, 0)
+
+	box2, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 6)
+	if err != nil {
+		return nil, "", err
+	}
+	SetAllMargins(box2, 18)
+	box2.Add(grid)
+	box2.Add(frame)
+	box2.Add(boxAddSource)
+
+	vp, err := gtk.ViewportNew(nil, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	vp.Add(box2)
+
+	sw.Add(vp)
+	_, err = sw.Connect("destroy", func(b gtk.IWidget) {
+		appBH.Unbind()
+		profileBH.Unbind()
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	name := profileSettings.settings.GetString(CFG_PROFILE_NAME)
+	return &sw.Container, name, nil
+}
+
+// selectNotificationScript ask for notification script file
+// and put selected path to the entry.
+func selectNotificationScript(win *gtk.ApplicationWindow, entry *gtk.Entry) error {
+	dialog, err := gtk.FileChooserDialogNewWith2Buttons(
+		locale.T(MsgPrefDlgNotificationScriptSelectDlgTitle, nil), &win.Window,
+		gtk.FILE_CHOOSER_ACTION_OPEN,
+		"_Cancel", gtk.RESPONSE_CANCEL, "_Open", gtk.RESPONSE_ACCEPT)
+	if err != nil {
+		return err
+	}
+	scriptPath, err := entry.GetText()
+	if err != nil {
+		return err
+	}
+	dialog.SetFilename(notificationScriptPathOrDefault(scriptPath))
+	response := dialog.Run()
+	path := dialog.GetFilename()
+	dialog.Destroy()
+	if response == gtk.RESPONSE_ACCEPT && path != "" {
+		entry.SetText(path)
+	}
+	return nil
+}
+
+// testRunNotificationScript start notification script with BACKUP_STATUS=test
+// in background and report result in message dialog.
+func testRunNotificationScript(win *gtk.ApplicationWindow, btn *gtk.Button,
+	appSettings *SettingsStore) error {
+
+	scriptPath := getNotificationScriptPath(appSettings.settings)
+	title := NewMarkup(MARKUP_SIZE_LARGER, 0, 0, nil, nil,
+		NewMarkup(MARKUP_SIZE_LARGER, 0, 0,
+			locale.T(MsgPrefDlgNotificationScriptTestRunTitle, nil), nil)).String()
+	if err := verifyNotificationScript(scriptPath); err != nil {
+		return ErrorMessage(&win.Window, title, []*DialogParagraph{NewDialogParagraph(err.Error())})
+	}
+	btn.SetSensitive(false)
+	go func() {
+		vars := []string{fmt.Sprintf("BACKUP_STATUS=%s", NOTIFICATION_SCRIPT_TEST_STATUS)}
+		exitCode, err := executeNotificationScript(scriptPath, vars)
+		var msg string
+		if err != nil {
+			msg = locale.T(MsgAppWindowRunNotificationScriptError,
+				struct{ Error error }{Error: err})
+		} else if exitCode != 0 {
+			msg = locale.T(MsgPrefDlgNotificationScriptTestRunFailed,
+				struct {
+					ScriptPath string
+					ExitCode   int
+				}{ScriptPath: scriptPath, ExitCode: exitCode})
+		} else {
+			msg = locale.T(MsgPrefDlgNotificationScriptTestRunCompleted,
+				struct{ ScriptPath string }{ScriptPath: scriptPath})
+		}
+		MustIdleAdd(func() {
+			btn.SetSensitive(true)
+			err := ErrorMessage(&win.Window, title, []*DialogParagraph{NewDialogParagraph(msg)})
+			if err != nil {
+				reportError(win, err)
+				return
+			}
+		})
+	}()
+	return nil
+}
+
+// detectDestinationDrive find out file system UUID of drive, which contain
+// profile default destination, to select profile once drive plugged in.
+func detectDestinationDrive(win *gtk.ApplicationWindow, profileSettings *SettingsStore) error {
+	destPath := profileSettings.settings.GetString(CFG_PROFILE_DEST_ROOT_PATH)
+	title := NewMarkup(MARKUP_SIZE_LARGER, 0, 0, nil, nil,
+		NewMarkup(MARKUP_SIZE_LARGER, 0, 0,
+			locale.T(MsgPrefDlgDestDriveUUIDCaption, nil), nil)).String()
+	var uuid, mountPoint string
+	var err error
+	if destPath != "" {
+		uuid, mountPoint, err = backup.FindFileSystemUUID(destPath)
+	}
+	if err != nil {
+		return ErrorMessage(&win.Window, title, []*DialogParagraph{NewDialogParagraph(
+			locale.T(MsgPrefDlgDestDriveDetectError,
+				struct {
+					Path  string
+					Error error
+				}{Path: destPath, Error: err}))})
+	} else if uuid == "" {
+		return ErrorMessage(&win.Window, title, []*DialogParagraph{NewDialogParagraph(
+			locale.T(MsgPrefDlgDestDriveNotFoundError, struct{ Path string }{Path: destPath}))})
+	}
+	subPath, err := filepath.Rel(mountPoint, destPath)
+	if err != nil {
+		return err
+	}
+	if subPath == "." {
+		subPath = ""
+	}
+	profileSettings.settings.SetString(CFG_PROFILE_DEST_DRIVE_UUID, uuid)
+	profileSettings.settings.SetString(CFG_PROFILE_DEST_DRIVE_SUBPATH, subPath)
+	return nil
+}
+
+// AdvancedPreferencesNew create preference dialog with "Advanced" page, where controls
+// bound to GLib Setting object for save/restore functionality.
+func AdvancedPreferencesNew(win *gtk.ApplicationWindow, appSettings *SettingsStore,
+	validator *UIValidator, prefRow *PreferenceRow) (*gtk.Container, error) {
+	box, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 6)
+	if err != nil {
+		return nil, err
+	}
+	SetAllMargins(box, 18)
+
 	if prefRow != nil {
 		prefRow.Page = &box.Container
 	}
 
-	bh := appSettings.NewBindingHelper()
+	bh := appSettings.NewBindingHelper()
+
+	grid, err := gtk.GridNew()
+	if err != nil {
+		return nil, err
+	}
+	grid.SetColumnSpacing(12)
+	grid.SetRowSpacing(6)
+	row := 0
+
+	// ---------------------------------------------------------
+	// Backup settings block
+	// ---------------------------------------------------------
+	markup := NewMarkup(MARKUP_WEIGHT_BOLD, 0, 0,
+		locale.T(MsgPrefDlgAdvancedBackupSettingsSection, nil), "")
+	lbl, err := SetupLabelMarkupJustifyLeft(markup)
+	if err != nil {
+		return nil, err
+	}
+	grid.Attach(lbl, DesignIndentCol, row, DesignTotalColCount, 1)
+	row++
+
+	// Enable/disable automatic backup block size
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgAutoManageBackupBlockSizeCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	eb, err := gtk.EventBoxNew()
+	if err != nil {
+		return nil, err
+	}
+	eb.Add(lbl)
+	grid.Attach(eb, DesignFirstCol, row, 1, 1)
+	cbAutoManageBackupBlockSize, err := gtk.CheckButtonNew()
+	if err != nil {
+		return nil, err
+	}
+	_, err = eb.Connect("button-press-event", func() {
+		cbAutoManageBackupBlockSize.SetActive(!cbAutoManageBackupBlockSize.GetActive())
+	})
+	if err != nil {
+		return nil, err
+	}
+	cbAutoManageBackupBlockSize.SetTooltipText(locale.T(MsgPrefDlgAutoManageBackupBlockSizeHint, nil))
+	cbAutoManageBackupBlockSize.SetHAlign(gtk.ALIGN_START)
+	bh.Bind(CFG_MANAGE_AUTO_BACKUP_BLOCK_SIZE, cbAutoManageBackupBlockSize, "active", glib.SETTINGS_BIND_DEFAULT)
+	grid.Attach(cbAutoManageBackupBlockSize, DesignSecondCol, row, 1, 1)
+	row++
+
+	// Backup block size
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgBackupBlockSizeCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	bh.Bind(CFG_MANAGE_AUTO_BACKUP_BLOCK_SIZE, lbl, "sensitive",
+		glib.SETTINGS_BIND_GET|glib.SETTINGS_BIND_INVERT_BOOLEAN)
+	grid.Attach(lbl, DesignFirstCol, row, 1, 1)
+	sbBackupBlockSize, err := gtk.SpinButtonNewWithRange(50, 10000, 1)
+	if err != nil {
+		return nil, err
+	}
+	sbBackupBlockSize.SetTooltipText(locale.T(MsgPrefDlgBackupBlockSizeHint, nil))
+	sbBackupBlockSize.SetHAlign(gtk.ALIGN_START)
+	bh.Bind(CFG_MAX_BACKUP_BLOCK_SIZE_MB, sbBackupBlockSize, "value", glib.SETTINGS_BIND_DEFAULT)
+	bh.Bind(CFG_MANAGE_AUTO_BACKUP_BLOCK_SIZE, sbBackupBlockSize, "sensitive",
+		glib.SETTINGS_BIND_GET|glib.SETTINGS_BIND_INVERT_BOOLEAN)
+	grid.Attach(sbBackupBlockSize, DesignSecondCol, row, 1, 1)
+	row++
+
+	// Build source directory tree in memory
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgBuildDirTreeInMemoryCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	eb, err = gtk.EventBoxNew()
+	if err != nil {
+		return nil, err
+	}
+	eb.Add(lbl)
+	grid.Attach(eb, DesignFirstCol, row, 1, 1)
+	cbBuildDirTreeInMemory, err := gtk.CheckButtonNew()
+	if err != nil {
+		return nil, err
+	}
+	_, err = eb.Connect("button-press-event", func() {
+		cbBuildDirTreeInMemory.SetActive(!cbBuildDirTreeInMemory.GetActive())
+	})
+	if err != nil {
+		return nil, err
+	}
+	cbBuildDirTreeInMemory.SetTooltipText(locale.T(MsgPrefDlgBuildDirTreeInMemoryHint, nil))
+	cbBuildDirTreeInMemory.SetHAlign(gtk.ALIGN_START)
+	bh.Bind(CFG_BUILD_DIR_TREE_IN_MEMORY, cbBuildDirTreeInMemory, "active", glib.SETTINGS_BIND_DEFAULT)
+	grid.Attach(cbBuildDirTreeInMemory, DesignSecondCol, row, 1, 1)
+	row++
+
+	// Reuse metrics of folders not changed since previous session
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgIncrementalPlanCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	eb, err = gtk.EventBoxNew()
+	if err != nil {
+		return nil, err
+	}
+	eb.Add(lbl)
+	grid.Attach(eb, DesignFirstCol, row, 1, 1)
+	cbIncrementalPlan, err := gtk.CheckButtonNew()
+	if err != nil {
+		return nil, err
+	}
+	_, err = eb.Connect("button-press-event", func() {
+		cbIncrementalPlan.SetActive(!cbIncrementalPlan.GetActive())
+	})
+	if err != nil {
+		return nil, err
+	}
+	cbIncrementalPlan.SetTooltipText(locale.T(MsgPrefDlgIncrementalPlanHint, nil))
+	cbIncrementalPlan.SetHAlign(gtk.ALIGN_START)
+	bh.Bind(CFG_INCREMENTAL_PLAN, cbIncrementalPlan, "active", glib.SETTINGS_BIND_DEFAULT)
+	grid.Attach(cbIncrementalPlan, DesignSecondCol, row, 1, 1)
+	row++
+
+	// Write plan stage decision made for each folder to trace file
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgPlanTraceCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	eb, err = gtk.EventBoxNew()
+	if err != nil {
+		return nil, err
+	}
+	eb.Add(lbl)
+	grid.Attach(eb, DesignFirstCol, row, 1, 1)
+	cbPlanTrace, err := gtk.CheckButtonNew()
+	if err != nil {
+		return nil, err
+	}
+	_, err = eb.Connect("button-press-event", func() {
+		cbPlanTrace.SetActive(!cbPlanTrace.GetActive())
+	})
+	if err != nil {
+		return nil, err
+	}
+	cbPlanTrace.SetTooltipText(locale.T(MsgPrefDlgPlanTraceHint, nil))
+	cbPlanTrace.SetHAlign(gtk.ALIGN_START)
+	bh.Bind(CFG_PLAN_TRACE, cbPlanTrace, "active", glib.SETTINGS_BIND_DEFAULT)
+	grid.Attach(cbPlanTrace, DesignSecondCol, row, 1, 1)
+	row++
+
+	// Plan stage temporary folder location
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgPlanStageTempPathCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	grid.Attach(lbl, DesignFirstCol, row, 1, 1)
+	edPlanStageTempPath, err := gtk.EntryNew()
+	if err != nil {
+		return nil, err
+	}
+	edPlanStageTempPath.SetHExpand(true)
+	edPlanStageTempPath.SetPlaceholderText(os.TempDir())
+	edPlanStageTempPath.SetTooltipText(locale.T(MsgPrefDlgPlanStageTempPathHint, nil))
+	bh.Bind(CFG_PLAN_STAGE_TEMP_PATH, edPlanStageTempPath, "text", glib.SETTINGS_BIND_DEFAULT)
+	grid.Attach(edPlanStageTempPath, DesignSecondCol, row, 1, 1)
+	row++
+
+	// Run notification script on backup completion
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgRunNotificationScriptCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	eb, err = gtk.EventBoxNew()
+	if err != nil {
+		return nil, err
+	}
+	eb.Add(lbl)
+	grid.Attach(eb, DesignFirstCol, row, 1, 1)
+	cbRunBackupCompletionNotificationScript, err := gtk.CheckButtonNew()
+	if err != nil {
+		return nil, err
+	}
+	_, err = eb.Connect("button-press-event", func() {
+		cbRunBackupCompletionNotificationScript.SetActive(!cbRunBackupCompletionNotificationScript.GetActive())
+	})
+	if err != nil {
+		return nil, err
+	}
+	cbRunBackupCompletionNotificationScript.SetTooltipText(locale.T(MsgPrefDlgRunNotificationScriptHint, nil))
+	cbRunBackupCompletionNotificationScript.SetHAlign(gtk.ALIGN_START)
+	bh.Bind(CFG_RUN_NOTIFICATION_SCRIPT, cbRunBackupCompletionNotificationScript,
+		"active", glib.SETTINGS_BIND_DEFAULT)
+	grid.Attach(cbRunBackupCompletionNotificationScript, DesignSecondCol, row, 1, 1)
+	row++
+
+	// Notification script location
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgNotificationScriptPathCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	bh.Bind(CFG_RUN_NOTIFICATION_SCRIPT, lbl, "sensitive", glib.SETTINGS_BIND_GET)
+	grid.Attach(lbl, DesignFirstCol, row, 1, 1)
+	boxScript, err := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 0)
+	if err != nil {
+		return nil, err
+	}
+	boxScript.SetHExpand(true)
+	style, err := boxScript.GetStyleContext()
+	if err != nil {
+		return nil, err
+	}
+	style.AddClass("linked")
+	edNotificationScriptPath, err := gtk.EntryNew()
+	if err != nil {
+		return nil, err
+	}
+	edNotificationScriptPath.SetHExpand(true)
+	edNotificationScriptPath.SetPlaceholderText(NOTIFICATION_SCRIPT_PATH)
+	edNotificationScriptPath.SetTooltipText(locale.T(MsgPrefDlgNotificationScriptPathHint, nil))
+	bh.Bind(CFG_NOTIFICATION_SCRIPT_PATH, edNotificationScriptPath, "text", glib.SETTINGS_BIND_DEFAULT)
+	boxScript.PackStart(edNotificationScriptPath, true, true, 0)
+	btnSelectScript, err := SetupButtonWithThemedImage("document-open-symbolic")
+	if err != nil {
+		return nil, err
+	}
+	btnSelectScript.SetTooltipText(locale.T(MsgPrefDlgNotificationScriptSelectHint, nil))
+	_, err = btnSelectScript.Connect("clicked", func() {
+		err := selectNotificationScript(win, edNotificationScriptPath)
+		if err != nil {
+			reportError(win, err)
+			return
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	boxScript.PackStart(btnSelectScript, false, false, 0)
+	btnTestScript, err := SetupButtonWithThemedImage("media-playback-start-symbolic")
+	if err != nil {
+		return nil, err
+	}
+	btnTestScript.SetTooltipText(locale.T(MsgPrefDlgNotificationScriptTestRunHint, nil))
+	_, err = btnTestScript.Connect("clicked", func() {
+		err := testRunNotificationScript(win, btnTestScript, appSettings)
+		if err != nil {
+			reportError(win, err)
+			return
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	boxScript.PackStart(btnTestScript, false, false, 0)
+	bh.Bind(CFG_RUN_NOTIFICATION_SCRIPT, boxScript, "sensitive", glib.SETTINGS_BIND_GET)
+	grid.Attach(boxScript, DesignSecondCol, row, 1, 1)
+	row++
+
+	// Verify in background, that notification script exists and is executable.
+	scriptValidatorGroup := "NotificationScript"
+	scriptValidatorIndex := "1"
+	validator.AddEntry(scriptValidatorGroup, scriptValidatorIndex,
+		// 1st stage of UIValidator. Perform data initialization here, which will be used in next steps.
+		// Synchronized call: can update GTK+ widgets from here.
+		func(data *ValidatorData, group []*ValidatorData) error {
+			entry, ok := data.Items[0].(*gtk.Entry)
+			if !ok {
+				return validatorConversionError("ValidatorData.Items[0]", "*gtk.Entry")
+			}
+			err := RemoveStyleClassesAll(&entry.Widget)
+			if err != nil {
+				return err
+			}
+			return nil
+		},
+		// 2nd stage of UIValidator. Execute long-running validation processes here.
+		// Asynchronous call: doesn't allowed to change GTK+ widgets from here (only read)!
+		// Use groupLock object, to limit simultaneous access to some not-thread-safe resources.
+		func(groupLock *sync.Mutex, ctx context.Context, data *ValidatorData, group []*ValidatorData) ([]interface{}, error) {
+			entry, ok := data.Items[0].(*gtk.Entry)
+			if !ok {
+				return nil, validatorConversionError("ValidatorData.Items[0]", "*gtk.Entry")
+			}
+			cb, ok := data.Items[1].(*gtk.CheckButton)
+			if !ok {
+				return nil, validatorConversionError("ValidatorData.Items[1]", "*gtk.CheckButton")
+			}
+			var warning *string
+			if cb.GetActive() {
+				scriptPath, err := entry.GetText()
+				if err != nil {
+					return nil, err
+				}
+				groupLock.Lock()
+				err = verifyNotificationScript(notificationScriptPathOrDefault(scriptPath))
+				groupLock.Unlock()
+				if err != nil {
+					msg := err.Error()
+					warning = &msg
+				}
+			}
+			return []interface{}{warning}, nil
+		},
+		// 3rd stage of UIValidator. Final step of data validation.
+		// Asynchronous call: can't update GTK+ widgets directly, but only when code is wrapped
+		// to glib.IdleAdd method.
+		// Use groupLock object, to limit simultaneous access to some not-thread-safe resources.
+		func(groupLock *sync.Mutex, data *ValidatorData, results []interface{}) error {
+			entry, ok := data.Items[0].(*gtk.Entry)
+			if !ok {
+				return validatorConversionError("ValidatorData.Items[0]", "*gtk.Entry")
+			}
+			row, ok := data.Items[2].(*PreferenceRow)
+			if !ok {
+				return validatorConversionError("ValidatorData.Items[2]", "*PreferenceRow")
+			}
+			warning, ok := results[0].(*string)
+			if !ok {
+				return validatorConversionError("interface{}[0]", "*string")
+			}
+			groupLock.Lock()
+			scriptPathHint := locale.T(MsgPrefDlgNotificationScriptPathHint, nil)
+			groupLock.Unlock()
+			MustIdleAdd(func() {
+				if warning != nil {
+					err := AddStyleClasses(&entry.Widget, []string{"entry-image-right-error", "entry-image-right-shake"})
+					if err != nil {
+						reportError(win, err)
+						return
+					}
+					entry.SetIconFromIconName(gtk.ENTRY_ICON_SECONDARY, STOCK_IMPORTANT_ICON)
+					markup := markupTooltip(NewMarkup(MARKUP_WEIGHT_BOLD, MARKUP_COLOR_ORANGE_RED, 0, *warning, nil),
+						scriptPathHint)
+					entry.SetTooltipMarkup(markup.String())
+					err = row.AddStatus(entry.Native(), ProfileStatusError, *warning)
+					if err != nil {
+						reportError(win, err)
+						return
+					}
+				} else {
+					entry.SetIconFromIconName(gtk.ENTRY_ICON_SECONDARY, "")
+					entry.SetTooltipText(scriptPathHint)
+					err := row.RemoveStatus(entry.Native())
+					if err != nil {
+						reportError(win, err)
+						return
+					}
+				}
+			})
+			return nil
+		}, edNotificationScriptPath, cbRunBackupCompletionNotificationScript, prefRow)
 
-	grid, err := gtk.GridNew()
+	scriptPathChangeTimer := time.AfterFunc(time.Millisecond*500, func() {
+		MustIdleAdd(func() {
+			err := validator.Validate(scriptValidatorGroup, scriptValidatorIndex)
+			if err != nil {
+				reportError(win, err)
+				return
+			}
+		})
+	})
+	_, err = edNotificationScriptPath.Connect("changed", func() {
+		RestartTimer(scriptPathChangeTimer, 500)
+	})
 	if err != nil {
 		return nil, err
 	}
-	grid.SetColumnSpacing(12)
-	grid.SetRowSpacing(6)
-	row := 0
+	_, err = cbRunBackupCompletionNotificationScript.Connect("toggled", func() {
+		RestartTimer(scriptPathChangeTimer, 50)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sep, err := gtk.SeparatorNew(gtk.ORIENTATION_HORIZONTAL)
+	if err != nil {
+		return nil, err
+	}
+	SetAllMargins(&sep.Widget, 6)
+	grid.Attach(sep, DesignIndentCol, row, DesignTotalColCount, 1)
+	row++
 
 	// ---------------------------------------------------------
-	// Backup settings block
+	// Rsync general block
 	// ---------------------------------------------------------
-	markup := NewMarkup(MARKUP_WEIGHT_BOLD, 0, 0,
-		locale.T(MsgPrefDlgAdvancedBackupSettingsSection, nil), "")
-	lbl, err := SetupLabelMarkupJustifyLeft(markup)
+	markup = NewMarkup(MARKUP_WEIGHT_BOLD, 0, 0,
+		locale.T(MsgPrefDlgAdvansedRsyncSettingsSection, nil), "")
+	lbl, err = SetupLabelMarkupJustifyLeft(markup)
 	if err != nil {
 		return nil, err
 	}
 	grid.Attach(lbl, DesignIndentCol, row, DesignTotalColCount, 1)
 	row++
 
-	// Enable/disable automatic backup block size
-	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgAutoManageBackupBlockSizeCaption, nil))
+	// Rsync utility retry count
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgRsyncRetryCountCaption, nil))
 	if err != nil {
 		return nil, err
 	}
-	eb, err := gtk.EventBoxNew()
+	grid.Attach(lbl, DesignFirstCol, row, 1, 1)
+	sbRetryCount, err := gtk.SpinButtonNewWithRange(0, 5, 1)
+	if err != nil {
+		return nil, err
+	}
+	sbRetryCount.SetTooltipText(locale.T(MsgPrefDlgRsyncRetryCountHint, nil))
+	sbRetryCount.SetHAlign(gtk.ALIGN_START)
+	bh.Bind(CFG_RSYNC_RETRY_COUNT, sbRetryCount, "value", glib.SETTINGS_BIND_DEFAULT)
+	grid.Attach(sbRetryCount, DesignSecondCol, row, 1, 1)
+	row++
+
+	// Pause backup on network outage
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgNetworkWatchdogCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	eb, err = gtk.EventBoxNew()
 	if err != nil {
 		return nil, err
 	}
 	eb.Add(lbl)
 	grid.Attach(eb, DesignFirstCol, row, 1, 1)
-	cbAutoManageBackupBlockSize, err := gtk.CheckButtonNew()
+	cbNetworkWatchdog, err := gtk.CheckButtonNew()
 	if err != nil {
 		return nil, err
 	}
 	_, err = eb.Connect("button-press-event", func() {
-		cbAutoManageBackupBlockSize.SetActive(!cbAutoManageBackupBlockSize.GetActive())
+		cbNetworkWatchdog.SetActive(!cbNetworkWatchdog.GetActive())
 	})
 	if err != nil {
 		return nil, err
 	}
-	cbAutoManageBackupBlockSize.SetTooltipText(locale.T(MsgPrefDlgAutoManageBackupBlockSizeHint, nil))
-	cbAutoManageBackupBlockSize.SetHAlign(gtk.ALIGN_START)
-	bh.Bind(CFG_MANAGE_AUTO_BACKUP_BLOCK_SIZE, cbAutoManageBackupBlockSize, "active", glib.SETTINGS_BIND_DEFAULT)
-	grid.Attach(cbAutoManageBackupBlockSize, DesignSecondCol, row, 1, 1)
+	cbNetworkWatchdog.SetTooltipText(locale.T(MsgPrefDlgNetworkWatchdogHint, nil))
+	cbNetworkWatchdog.SetHAlign(gtk.ALIGN_START)
+	bh.Bind(CFG_NETWORK_WATCHDOG_ENABLED, cbNetworkWatchdog, "active", glib.SETTINGS_BIND_DEFAULT)
+	grid.Attach(cbNetworkWatchdog, DesignSecondCol, row, 1, 1)
 	row++
 
-	// Backup block size
-	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgBackupBlockSizeCaption, nil))
+	// Maximum time to wait for network
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgNetworkOutageMaxWaitCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	bh.Bind(CFG_NETWORK_WATCHDOG_ENABLED, lbl, "sensitive", glib.SETTINGS_BIND_GET)
+	grid.Attach(lbl, DesignFirstCol, row, 1, 1)
+	sbNetworkOutageMaxWait, err := gtk.SpinButtonNewWithRange(0, 1440, 1)
+	if err != nil {
+		return nil, err
+	}
+	sbNetworkOutageMaxWait.SetTooltipText(locale.T(MsgPrefDlgNetworkOutageMaxWaitHint, nil))
+	sbNetworkOutageMaxWait.SetHAlign(gtk.ALIGN_START)
+	bh.Bind(CFG_NETWORK_OUTAGE_MAX_WAIT_MIN, sbNetworkOutageMaxWait, "value", glib.SETTINGS_BIND_DEFAULT)
+	bh.Bind(CFG_NETWORK_WATCHDOG_ENABLED, sbNetworkOutageMaxWait, "sensitive", glib.SETTINGS_BIND_GET)
+	grid.Attach(sbNetworkOutageMaxWait, DesignSecondCol, row, 1, 1)
+	row++
+
+	// Time between module reachability probes
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgModuleHealthCheckIntervalCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	grid.Attach(lbl, DesignFirstCol, row, 1, 1)
+	sbModuleHealthCheckInterval, err := gtk.SpinButtonNewWithRange(0, 1440, 1)
+	if err != nil {
+		return nil, err
+	}
+	sbModuleHealthCheckInterval.SetTooltipText(locale.T(MsgPrefDlgModuleHealthCheckIntervalHint, nil))
+	sbModuleHealthCheckInterval.SetHAlign(gtk.ALIGN_START)
+	bh.Bind(CFG_MODULE_HEALTH_CHECK_INTERVAL_MIN, sbModuleHealthCheckInterval, "value", glib.SETTINGS_BIND_DEFAULT)
+	grid.Attach(sbModuleHealthCheckInterval, DesignSecondCol, row, 1, 1)
+	_, err = sbModuleHealthCheckInterval.Connect("value-changed", func() {
+		updateModuleHealthInterval(appSettings)
+	})
+	if err != nil {
+		return nil, err
+	}
+	row++
+
+	// Time without RSYNC output to consider process hung
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgRsyncHangTimeoutCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	grid.Attach(lbl, DesignFirstCol, row, 1, 1)
+	sbHangTimeout, err := gtk.SpinButtonNewWithRange(0, 1440, 1)
+	if err != nil {
+		return nil, err
+	}
+	sbHangTimeout.SetTooltipText(locale.T(MsgPrefDlgRsyncHangTimeoutHint, nil))
+	sbHangTimeout.SetHAlign(gtk.ALIGN_START)
+	bh.Bind(CFG_RSYNC_HANG_TIMEOUT_MIN, sbHangTimeout, "value", glib.SETTINGS_BIND_DEFAULT)
+	grid.Attach(sbHangTimeout, DesignSecondCol, row, 1, 1)
+	row++
+
+	// RSYNC I/O timeout
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgRsyncIOTimeoutCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	grid.Attach(lbl, DesignFirstCol, row, 1, 1)
+	sbIOTimeout, err := gtk.SpinButtonNewWithRange(0, 86400, 1)
+	if err != nil {
+		return nil, err
+	}
+	sbIOTimeout.SetTooltipText(locale.T(MsgPrefDlgRsyncIOTimeoutHint, nil))
+	sbIOTimeout.SetHAlign(gtk.ALIGN_START)
+	bh.Bind(CFG_RSYNC_IO_TIMEOUT_SEC, sbIOTimeout, "value", glib.SETTINGS_BIND_DEFAULT)
+	grid.Attach(sbIOTimeout, DesignSecondCol, row, 1, 1)
+	row++
+
+	// RSYNC connection timeout
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgRsyncConnectTimeoutCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	grid.Attach(lbl, DesignFirstCol, row, 1, 1)
+	sbConnectTimeout, err := gtk.SpinButtonNewWithRange(0, 3600, 1)
+	if err != nil {
+		return nil, err
+	}
+	sbConnectTimeout.SetTooltipText(locale.T(MsgPrefDlgRsyncConnectTimeoutHint, nil))
+	sbConnectTimeout.SetHAlign(gtk.ALIGN_START)
+	bh.Bind(CFG_RSYNC_CONNECT_TIMEOUT_SEC, sbConnectTimeout, "value", glib.SETTINGS_BIND_DEFAULT)
+	grid.Attach(sbConnectTimeout, DesignSecondCol, row, 1, 1)
+	row++
+
+	// Verify SSH host keys, asking to trust unknown host
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgVerifySSHHostKeysCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	eb, err = gtk.EventBoxNew()
+	if err != nil {
+		return nil, err
+	}
+	eb.Add(lbl)
+	grid.Attach(eb, DesignFirstCol, row, 1, 1)
+	cbVerifySSHHostKeys, err := gtk.CheckButtonNew()
+	if err != nil {
+		return nil, err
+	}
+	_, err = eb.Connect("button-press-event", func() {
+		cbVerifySSHHostKeys.SetActive(!cbVerifySSHHostKeys.GetActive())
+	})
+	if err != nil {
+		return nil, err
+	}
+	cbVerifySSHHostKeys.SetTooltipText(locale.T(MsgPrefDlgVerifySSHHostKeysHint, nil))
+	cbVerifySSHHostKeys.SetHAlign(gtk.ALIGN_START)
+	bh.Bind(CFG_VERIFY_SSH_HOST_KEYS, cbVerifySSHHostKeys, "active", glib.SETTINGS_BIND_DEFAULT)
+	grid.Attach(cbVerifySSHHostKeys, DesignSecondCol, row, 1, 1)
+	_, err = cbVerifySSHHostKeys.Connect("toggled", func() {
+		updateSSHHostKeyVerification(appSettings)
+	})
+	if err != nil {
+		return nil, err
+	}
+	row++
+
+	// Credentials shared by sources located on the same host
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgHostCredentialsCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	lbl.SetVAlign(gtk.ALIGN_START)
+	grid.Attach(lbl, DesignFirstCol, row, 1, 1)
+	credentialEditor, err := createHostCredentialEditor(appSettings)
+	if err != nil {
+		return nil, err
+	}
+	credentialEditor.SetTooltipText(locale.T(MsgPrefDlgHostCredentialsHint, nil))
+	grid.Attach(credentialEditor, DesignSecondCol, row, 1, 1)
+	row++
+
+	// RSYNC process CPU niceness
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgRsyncNiceLevelCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	grid.Attach(lbl, DesignFirstCol, row, 1, 1)
+	sbNiceLevel, err := gtk.SpinButtonNewWithRange(0, rsync.NICE_LEVEL_MAX, 1)
+	if err != nil {
+		return nil, err
+	}
+	sbNiceLevel.SetTooltipText(locale.T(MsgPrefDlgRsyncNiceLevelHint, nil))
+	sbNiceLevel.SetHAlign(gtk.ALIGN_START)
+	bh.Bind(CFG_RSYNC_NICE_LEVEL, sbNiceLevel, "value", glib.SETTINGS_BIND_DEFAULT)
+	grid.Attach(sbNiceLevel, DesignSecondCol, row, 1, 1)
+	row++
+
+	// RSYNC process I/O scheduling class
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgRsyncIOClassCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	grid.Attach(lbl, DesignFirstCol, row, 1, 1)
+	values := []struct{ value, key string }{
+		{locale.T(MsgPrefDlgRsyncIOClassDefaultEntry, nil), rsync.IO_CLASS_DEFAULT},
+		{locale.T(MsgPrefDlgRsyncIOClassBestEffortEntry, nil), rsync.IO_CLASS_BEST_EFFORT},
+		{locale.T(MsgPrefDlgRsyncIOClassIdleEntry, nil), rsync.IO_CLASS_IDLE},
+	}
+	cbIOClass, err := CreateNameValueCombo(values)
+	if err != nil {
+		return nil, err
+	}
+	cbIOClass.SetTooltipText(locale.T(MsgPrefDlgRsyncIOClassHint, nil))
+	bh.Bind(CFG_RSYNC_IO_CLASS, cbIOClass, "active-id", glib.SETTINGS_BIND_DEFAULT)
+	grid.Attach(cbIOClass, DesignSecondCol, row, 1, 1)
+	row++
+
+	// RSYNC process I/O priority level
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgRsyncIOLevelCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	grid.Attach(lbl, DesignFirstCol, row, 1, 1)
+	sbIOLevel, err := gtk.SpinButtonNewWithRange(0, rsync.IO_LEVEL_MAX, 1)
+	if err != nil {
+		return nil, err
+	}
+	sbIOLevel.SetTooltipText(locale.T(MsgPrefDlgRsyncIOLevelHint, nil))
+	sbIOLevel.SetHAlign(gtk.ALIGN_START)
+	bh.Bind(CFG_RSYNC_IO_LEVEL, sbIOLevel, "value", glib.SETTINGS_BIND_DEFAULT)
+	err = bindIOLevelSensitivity(cbIOClass, lbl, sbIOLevel)
+	if err != nil {
+		return nil, err
+	}
+	grid.Attach(sbIOLevel, DesignSecondCol, row, 1, 1)
+	row++
+
+	// Enable/disable RSYNC low level log
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgRsyncLowLevelLogCaption, nil))
 	if err != nil {
 		return nil, err
 	}
-	bh.Bind(CFG_MANAGE_AUTO_BACKUP_BLOCK_SIZE, lbl, "sensitive",
-		glib.SETTINGS_BIND_GET|glib.SETTINGS_BIND_INVERT_BOOLEAN)
-	grid.Attach(lbl, DesignFirstCol, row, 1, 1)
-	sbBackupBlockSize, err := gtk.SpinButtonNewWithRange(50, 10000, 1)
+	eb, err = gtk.EventBoxNew()
 	if err != nil {
 		return nil, err
 	}
-	sbBackupBlockSize.SetTooltipText(locale.T(MsgPrefDlgBackupBlockSizeHint, nil))
-	sbBackupBlockSize.SetHAlign(gtk.ALIGN_START)
-	bh.Bind(CFG_MAX_BACKUP_BLOCK_SIZE_MB, sbBackupBlockSize, "value", glib.SETTINGS_BIND_DEFAULT)
-	bh.Bind(CFG_MANAGE_AUTO_BACKUP_BLOCK_SIZE, sbBackupBlockSize, "sensitive",
-		glib.SETTINGS_BIND_GET|glib.SETTINGS_BIND_INVERT_BOOLEAN)
-	grid.Attach(sbBackupBlockSize, DesignSecondCol, row, 1, 1)
+	eb.Add(lbl)
+	grid.Attach(eb, DesignFirstCol, row, 1, 1)
+	cbLowLevelRsyncLog, err := gtk.CheckButtonNew()
+	if err != nil {
+		return nil, err
+	}
+	_, err = eb.Connect("button-press-event", func() {
+		cbLowLevelRsyncLog.SetActive(!cbLowLevelRsyncLog.GetActive())
+	})
+	if err != nil {
+		return nil, err
+	}
+	cbLowLevelRsyncLog.SetTooltipText(locale.T(MsgPrefDlgRsyncLowLevelLogHint, nil))
+	cbLowLevelRsyncLog.SetHAlign(gtk.ALIGN_START)
+	bh.Bind(CFG_ENABLE_LOW_LEVEL_LOG_OF_RSYNC, cbLowLevelRsyncLog, "active", glib.SETTINGS_BIND_DEFAULT)
+	grid.Attach(cbLowLevelRsyncLog, DesignSecondCol, row, 1, 1)
 	row++
 
-	// Run notification script on backup completion
-	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgRunNotificationScriptCaption, nil))
+	// Enable/disable RSYNC intensive low level log
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgRsyncIntensiveLowLevelLogCaption, nil))
 	if err != nil {
 		return nil, err
 	}
@@ -1733,62 +3645,64 @@ func AdvancedPreferencesNew(appSettings *SettingsStore, prefRow *PreferenceRow)
 		return nil, err
 	}
 	eb.Add(lbl)
+	bh.Bind(CFG_ENABLE_LOW_LEVEL_LOG_OF_RSYNC, eb, "sensitive", glib.SETTINGS_BIND_GET)
 	grid.Attach(eb, DesignFirstCol, row, 1, 1)
-	cbRunBackupCompletionNotificationScript, err := gtk.CheckButtonNew()
+	cbIntensiveLowLevelRsyncLog, err := gtk.CheckButtonNew()
 	if err != nil {
 		return nil, err
 	}
 	_, err = eb.Connect("button-press-event", func() {
-		cbRunBackupCompletionNotificationScript.SetActive(!cbRunBackupCompletionNotificationScript.GetActive())
+		cbIntensiveLowLevelRsyncLog.SetActive(!cbIntensiveLowLevelRsyncLog.GetActive())
 	})
 	if err != nil {
 		return nil, err
 	}
-	cbRunBackupCompletionNotificationScript.SetTooltipText(locale.T(MsgPrefDlgRunNotificationScriptHint, nil))
-	cbRunBackupCompletionNotificationScript.SetHAlign(gtk.ALIGN_START)
-	bh.Bind(CFG_RUN_NOTIFICATION_SCRIPT, cbRunBackupCompletionNotificationScript,
+	cbIntensiveLowLevelRsyncLog.SetTooltipText(locale.T(MsgPrefDlgRsyncIntensiveLowLevelLogHint, nil))
+	cbIntensiveLowLevelRsyncLog.SetHAlign(gtk.ALIGN_START)
+	bh.Bind(CFG_ENABLE_INTENSIVE_LOW_LEVEL_LOG_OF_RSYNC, cbIntensiveLowLevelRsyncLog,
 		"active", glib.SETTINGS_BIND_DEFAULT)
-	grid.Attach(cbRunBackupCompletionNotificationScript, DesignSecondCol, row, 1, 1)
+	bh.Bind(CFG_ENABLE_LOW_LEVEL_LOG_OF_RSYNC, cbIntensiveLowLevelRsyncLog,
+		"sensitive", glib.SETTINGS_BIND_GET)
+	grid.Attach(cbIntensiveLowLevelRsyncLog, DesignSecondCol, row, 1, 1)
 	row++
 
-	sep, err := gtk.SeparatorNew(gtk.ORIENTATION_HORIZONTAL)
+	// Session log format
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgSessionLogFormatCaption, nil))
 	if err != nil {
 		return nil, err
 	}
-	SetAllMargins(&sep.Widget, 6)
-	grid.Attach(sep, DesignIndentCol, row, DesignTotalColCount, 1)
-	row++
-
-	// ---------------------------------------------------------
-	// Rsync general block
-	// ---------------------------------------------------------
-	markup = NewMarkup(MARKUP_WEIGHT_BOLD, 0, 0,
-		locale.T(MsgPrefDlgAdvansedRsyncSettingsSection, nil), "")
-	lbl, err = SetupLabelMarkupJustifyLeft(markup)
+	grid.Attach(lbl, DesignFirstCol, row, 1, 1)
+	values = []struct{ value, key string }{
+		{locale.T(MsgPrefDlgSessionLogFormatTextEntry, nil), string(core.LOG_FORMAT_TEXT)},
+		{locale.T(MsgPrefDlgSessionLogFormatJsonEntry, nil), string(core.LOG_FORMAT_JSON)},
+	}
+	cbSessionLogFormat, err := CreateNameValueCombo(values)
 	if err != nil {
 		return nil, err
 	}
-	grid.Attach(lbl, DesignIndentCol, row, DesignTotalColCount, 1)
+	cbSessionLogFormat.SetTooltipText(locale.T(MsgPrefDlgSessionLogFormatHint, nil))
+	bh.Bind(CFG_SESSION_LOG_FORMAT, cbSessionLogFormat, "active-id", glib.SETTINGS_BIND_DEFAULT)
+	grid.Attach(cbSessionLogFormat, DesignSecondCol, row, 1, 1)
 	row++
 
-	// Rsync utility retry count
-	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgRsyncRetryCountCaption, nil))
+	// Log segment size limit
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgLogSegmentMaxSizeCaption, nil))
 	if err != nil {
 		return nil, err
 	}
 	grid.Attach(lbl, DesignFirstCol, row, 1, 1)
-	sbRetryCount, err := gtk.SpinButtonNewWithRange(0, 5, 1)
+	sbLogSegmentMaxSize, err := gtk.SpinButtonNewWithRange(0, 100000, 1)
 	if err != nil {
 		return nil, err
 	}
-	sbRetryCount.SetTooltipText(locale.T(MsgPrefDlgRsyncRetryCountHint, nil))
-	sbRetryCount.SetHAlign(gtk.ALIGN_START)
-	bh.Bind(CFG_RSYNC_RETRY_COUNT, sbRetryCount, "value", glib.SETTINGS_BIND_DEFAULT)
-	grid.Attach(sbRetryCount, DesignSecondCol, row, 1, 1)
+	sbLogSegmentMaxSize.SetTooltipText(locale.T(MsgPrefDlgLogSegmentMaxSizeHint, nil))
+	sbLogSegmentMaxSize.SetHAlign(gtk.ALIGN_START)
+	bh.Bind(CFG_LOG_SEGMENT_MAX_SIZE_MB, sbLogSegmentMaxSize, "value", glib.SETTINGS_BIND_DEFAULT)
+	grid.Attach(sbLogSegmentMaxSize, DesignSecondCol, row, 1, 1)
 	row++
 
-	// Enable/disable RSYNC low level log
-	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgRsyncLowLevelLogCaption, nil))
+	// Enable/disable backup catalog
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgGenerateCatalogCaption, nil))
 	if err != nil {
 		return nil, err
 	}
@@ -1798,24 +3712,24 @@ func AdvancedPreferencesNew(appSettings *SettingsStore, prefRow *PreferenceRow)
 	}
 	eb.Add(lbl)
 	grid.Attach(eb, DesignFirstCol, row, 1, 1)
-	cbLowLevelRsyncLog, err := gtk.CheckButtonNew()
+	cbGenerateCatalog, err := gtk.CheckButtonNew()
 	if err != nil {
 		return nil, err
 	}
 	_, err = eb.Connect("button-press-event", func() {
-		cbLowLevelRsyncLog.SetActive(!cbLowLevelRsyncLog.GetActive())
+		cbGenerateCatalog.SetActive(!cbGenerateCatalog.GetActive())
 	})
 	if err != nil {
 		return nil, err
 	}
-	cbLowLevelRsyncLog.SetTooltipText(locale.T(MsgPrefDlgRsyncLowLevelLogHint, nil))
-	cbLowLevelRsyncLog.SetHAlign(gtk.ALIGN_START)
-	bh.Bind(CFG_ENABLE_LOW_LEVEL_LOG_OF_RSYNC, cbLowLevelRsyncLog, "active", glib.SETTINGS_BIND_DEFAULT)
-	grid.Attach(cbLowLevelRsyncLog, DesignSecondCol, row, 1, 1)
+	cbGenerateCatalog.SetTooltipText(locale.T(MsgPrefDlgGenerateCatalogHint, nil))
+	cbGenerateCatalog.SetHAlign(gtk.ALIGN_START)
+	bh.Bind(CFG_GENERATE_BACKUP_CATALOG, cbGenerateCatalog, "active", glib.SETTINGS_BIND_DEFAULT)
+	grid.Attach(cbGenerateCatalog, DesignSecondCol, row, 1, 1)
 	row++
 
-	// Enable/disable RSYNC intensive low level log
-	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgRsyncIntensiveLowLevelLogCaption, nil))
+	// Enable/disable checksum manifest
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgGenerateChecksumsCaption, nil))
 	if err != nil {
 		return nil, err
 	}
@@ -1824,25 +3738,21 @@ func AdvancedPreferencesNew(appSettings *SettingsStore, prefRow *PreferenceRow)
 		return nil, err
 	}
 	eb.Add(lbl)
-	bh.Bind(CFG_ENABLE_LOW_LEVEL_LOG_OF_RSYNC, eb, "sensitive", glib.SETTINGS_BIND_GET)
 	grid.Attach(eb, DesignFirstCol, row, 1, 1)
-	cbIntensiveLowLevelRsyncLog, err := gtk.CheckButtonNew()
+	cbGenerateChecksums, err := gtk.CheckButtonNew()
 	if err != nil {
 		return nil, err
 	}
 	_, err = eb.Connect("button-press-event", func() {
-		cbIntensiveLowLevelRsyncLog.SetActive(!cbIntensiveLowLevelRsyncLog.GetActive())
+		cbGenerateChecksums.SetActive(!cbGenerateChecksums.GetActive())
 	})
 	if err != nil {
 		return nil, err
 	}
-	cbIntensiveLowLevelRsyncLog.SetTooltipText(locale.T(MsgPrefDlgRsyncIntensiveLowLevelLogHint, nil))
-	cbIntensiveLowLevelRsyncLog.SetHAlign(gtk.ALIGN_START)
-	bh.Bind(CFG_ENABLE_INTENSIVE_LOW_LEVEL_LOG_OF_RSYNC, cbIntensiveLowLevelRsyncLog,
-		"active", glib.SETTINGS_BIND_DEFAULT)
-	bh.Bind(CFG_ENABLE_LOW_LEVEL_LOG_OF_RSYNC, cbIntensiveLowLevelRsyncLog,
-		"sensitive", glib.SETTINGS_BIND_GET)
-	grid.Attach(cbIntensiveLowLevelRsyncLog, DesignSecondCol, row, 1, 1)
+	cbGenerateChecksums.SetTooltipText(locale.T(MsgPrefDlgGenerateChecksumsHint, nil))
+	cbGenerateChecksums.SetHAlign(gtk.ALIGN_START)
+	bh.Bind(CFG_GENERATE_CHECKSUM_MANIFEST, cbGenerateChecksums, "active", glib.SETTINGS_BIND_DEFAULT)
+	grid.Attach(cbGenerateChecksums, DesignSecondCol, row, 1, 1)
 	row++
 
 	sep, err = gtk.SeparatorNew(gtk.ORIENTATION_HORIZONTAL)
@@ -1908,6 +3818,51 @@ func AdvancedPreferencesNew(appSettings *SettingsStore, prefRow *PreferenceRow)
 	grid.Attach(sbNumberOfPreviousBackupToUse, DesignSecondCol, row, 1, 1)
 	row++
 
+	// Ask before backup of source, which lost too many files since previous backup
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgSafeDeleteCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	grid.Attach(lbl, DesignFirstCol, row, 1, 1)
+	cbSafeDelete, err := gtk.CheckButtonNew()
+	if err != nil {
+		return nil, err
+	}
+	cbSafeDelete.SetTooltipText(locale.T(MsgPrefDlgSafeDeleteHint, nil))
+	cbSafeDelete.SetHAlign(gtk.ALIGN_START)
+	bh.Bind(CFG_SAFE_DELETE_ENABLED, cbSafeDelete, "active", glib.SETTINGS_BIND_DEFAULT)
+	grid.Attach(cbSafeDelete, DesignSecondCol, row, 1, 1)
+	row++
+
+	for _, item := range []struct {
+		key     string
+		caption string
+		hint    string
+		max     float64
+	}{
+		{CFG_SAFE_DELETE_MAX_FILES, MsgPrefDlgSafeDeleteMaxFilesCaption,
+			MsgPrefDlgSafeDeleteMaxFilesHint, 1000000},
+		{CFG_SAFE_DELETE_MAX_SIZE_MB, MsgPrefDlgSafeDeleteMaxSizeMbCaption,
+			MsgPrefDlgSafeDeleteMaxSizeMbHint, 10000000},
+	} {
+		lbl, err = SetupLabelJustifyRight(locale.T(item.caption, nil))
+		if err != nil {
+			return nil, err
+		}
+		bh.Bind(CFG_SAFE_DELETE_ENABLED, lbl, "sensitive", glib.SETTINGS_BIND_GET)
+		grid.Attach(lbl, DesignFirstCol, row, 1, 1)
+		sbThreshold, err := gtk.SpinButtonNewWithRange(0, item.max, 1)
+		if err != nil {
+			return nil, err
+		}
+		sbThreshold.SetTooltipText(locale.T(item.hint, nil))
+		sbThreshold.SetHAlign(gtk.ALIGN_START)
+		bh.Bind(item.key, sbThreshold, "value", glib.SETTINGS_BIND_DEFAULT)
+		bh.Bind(CFG_SAFE_DELETE_ENABLED, sbThreshold, "sensitive", glib.SETTINGS_BIND_GET)
+		grid.Attach(sbThreshold, DesignSecondCol, row, 1, 1)
+		row++
+	}
+
 	sep, err = gtk.SeparatorNew(gtk.ORIENTATION_HORIZONTAL)
 	if err != nil {
 		return nil, err
@@ -1997,6 +3952,31 @@ func AdvancedPreferencesNew(appSettings *SettingsStore, prefRow *PreferenceRow)
 	grid.Attach(cbTransferSpecialFiles, DesignSecondCol, row, 1, 1)
 	row++
 
+	// Enable/disable RSYNC transfer of ACLs
+	cbTransferACLs, err := gtk.CheckButtonNew()
+	if err != nil {
+		return nil, err
+	}
+	cbTransferACLs.SetLabel(locale.T(MsgPrefDlgRsyncTransferACLsCaption, nil))
+	cbTransferACLs.SetTooltipText(locale.T(MsgPrefDlgRsyncTransferACLsHint, nil))
+	cbTransferACLs.SetHAlign(gtk.ALIGN_START)
+	bh.Bind(CFG_RSYNC_TRANSFER_ACLS, cbTransferACLs, "active", glib.SETTINGS_BIND_DEFAULT)
+	markUnsupportedRsyncOption(cbTransferACLs, (*rsync.Capabilities).SupportsACLs)
+	grid.Attach(cbTransferACLs, DesignFirstCol, row, 1, 1)
+
+	// Enable/disable RSYNC transfer of extended attributes
+	cbTransferXattrs, err := gtk.CheckButtonNew()
+	if err != nil {
+		return nil, err
+	}
+	cbTransferXattrs.SetLabel(locale.T(MsgPrefDlgRsyncTransferXattrsCaption, nil))
+	cbTransferXattrs.SetTooltipText(locale.T(MsgPrefDlgRsyncTransferXattrsHint, nil))
+	cbTransferXattrs.SetHAlign(gtk.ALIGN_START)
+	bh.Bind(CFG_RSYNC_TRANSFER_XATTRS, cbTransferXattrs, "active", glib.SETTINGS_BIND_DEFAULT)
+	markUnsupportedRsyncOption(cbTransferXattrs, (*rsync.Capabilities).SupportsXattrs)
+	grid.Attach(cbTransferXattrs, DesignSecondCol, row, 1, 1)
+	row++
+
 	// Enable/disable RSYNC compress file transfer
 	cbCompressFileTransfer, err := gtk.CheckButtonNew()
 	if err != nil {
@@ -2007,6 +3987,86 @@ func AdvancedPreferencesNew(appSettings *SettingsStore, prefRow *PreferenceRow)
 	cbCompressFileTransfer.SetHAlign(gtk.ALIGN_START)
 	bh.Bind(CFG_RSYNC_COMPRESS_FILE_TRANSFER, cbCompressFileTransfer, "active", glib.SETTINGS_BIND_DEFAULT)
 	grid.Attach(cbCompressFileTransfer, DesignFirstCol, row, 1, 1)
+
+	// Enable/disable RSYNC partial transfers
+	cbPartialTransfer, err := gtk.CheckButtonNew()
+	if err != nil {
+		return nil, err
+	}
+	cbPartialTransfer.SetLabel(locale.T(MsgPrefDlgRsyncPartialTransferCaption, nil))
+	cbPartialTransfer.SetTooltipText(locale.T(MsgPrefDlgRsyncPartialTransferHint, nil))
+	cbPartialTransfer.SetHAlign(gtk.ALIGN_START)
+	bh.Bind(CFG_RSYNC_PARTIAL_TRANSFER, cbPartialTransfer, "active", glib.SETTINGS_BIND_DEFAULT)
+	// RSYNC doesn't allow to combine --partial-dir with --inplace
+	bh.Bind(CFG_RSYNC_INPLACE, cbPartialTransfer, "sensitive",
+		glib.SETTINGS_BIND_GET|glib.SETTINGS_BIND_INVERT_BOOLEAN)
+	grid.Attach(cbPartialTransfer, DesignSecondCol, row, 1, 1)
+	row++
+
+	// Enable/disable RSYNC sparse files handling
+	cbSparseFiles, err := gtk.CheckButtonNew()
+	if err != nil {
+		return nil, err
+	}
+	cbSparseFiles.SetLabel(locale.T(MsgPrefDlgRsyncSparseFilesCaption, nil))
+	cbSparseFiles.SetTooltipText(locale.T(MsgPrefDlgRsyncSparseFilesHint, nil))
+	cbSparseFiles.SetHAlign(gtk.ALIGN_START)
+	bh.Bind(CFG_RSYNC_SPARSE_FILES, cbSparseFiles, "active", glib.SETTINGS_BIND_DEFAULT)
+	grid.Attach(cbSparseFiles, DesignFirstCol, row, 1, 1)
+
+	// Enable/disable RSYNC in-place update of destination files
+	cbInplace, err := gtk.CheckButtonNew()
+	if err != nil {
+		return nil, err
+	}
+	cbInplace.SetLabel(locale.T(MsgPrefDlgRsyncInplaceCaption, nil))
+	cbInplace.SetTooltipText(locale.T(MsgPrefDlgRsyncInplaceHint, nil))
+	cbInplace.SetHAlign(gtk.ALIGN_START)
+	bh.Bind(CFG_RSYNC_INPLACE, cbInplace, "active", glib.SETTINGS_BIND_DEFAULT)
+	bh.Bind(CFG_RSYNC_PARTIAL_TRANSFER, cbInplace, "sensitive",
+		glib.SETTINGS_BIND_GET|glib.SETTINGS_BIND_INVERT_BOOLEAN)
+	grid.Attach(cbInplace, DesignSecondCol, row, 1, 1)
+	row++
+
+	// RSYNC compression algorithm
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgRsyncCompressChoiceCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	bh.Bind(CFG_RSYNC_COMPRESS_FILE_TRANSFER, lbl, "sensitive", glib.SETTINGS_BIND_GET)
+	grid.Attach(lbl, DesignFirstCol, row, 1, 1)
+	values = []struct{ value, key string }{
+		{locale.T(MsgPrefDlgRsyncCompressChoiceAutoEntry, nil), rsync.COMPRESS_CHOICE_AUTO},
+		{locale.T(MsgPrefDlgRsyncCompressChoiceZlibEntry, nil), rsync.COMPRESS_CHOICE_ZLIB},
+		{"zstd", rsync.COMPRESS_CHOICE_ZSTD},
+		{"lz4", rsync.COMPRESS_CHOICE_LZ4},
+	}
+	cbCompressChoice, err := CreateNameValueCombo(values)
+	if err != nil {
+		return nil, err
+	}
+	cbCompressChoice.SetTooltipText(locale.T(MsgPrefDlgRsyncCompressChoiceHint, nil))
+	bh.Bind(CFG_RSYNC_COMPRESS_CHOICE, cbCompressChoice, "active-id", glib.SETTINGS_BIND_DEFAULT)
+	bh.Bind(CFG_RSYNC_COMPRESS_FILE_TRANSFER, cbCompressChoice, "sensitive", glib.SETTINGS_BIND_GET)
+	grid.Attach(cbCompressChoice, DesignSecondCol, row, 1, 1)
+	row++
+
+	// RSYNC compression level
+	lbl, err = SetupLabelJustifyRight(locale.T(MsgPrefDlgRsyncCompressLevelCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	bh.Bind(CFG_RSYNC_COMPRESS_FILE_TRANSFER, lbl, "sensitive", glib.SETTINGS_BIND_GET)
+	grid.Attach(lbl, DesignFirstCol, row, 1, 1)
+	sbCompressLevel, err := gtk.SpinButtonNewWithRange(0, 22, 1)
+	if err != nil {
+		return nil, err
+	}
+	sbCompressLevel.SetTooltipText(locale.T(MsgPrefDlgRsyncCompressLevelHint, nil))
+	sbCompressLevel.SetHAlign(gtk.ALIGN_START)
+	bh.Bind(CFG_RSYNC_COMPRESS_LEVEL, sbCompressLevel, "value", glib.SETTINGS_BIND_DEFAULT)
+	bh.Bind(CFG_RSYNC_COMPRESS_FILE_TRANSFER, sbCompressLevel, "sensitive", glib.SETTINGS_BIND_GET)
+	grid.Attach(sbCompressLevel, DesignSecondCol, row, 1, 1)
 	row++
 
 	box.Add(grid)
@@ -2021,6 +4081,69 @@ func AdvancedPreferencesNew(appSettings *SettingsStore, prefRow *PreferenceRow)
 	return &box.Container, nil
 }
 
+// markUnsupportedRsyncOption make check box insensitive, if installed
+// RSYNC is built without capability required by option.
+func markUnsupportedRsyncOption(cb *gtk.CheckButton, supported func(caps *rsync.Capabilities) bool) {
+	caps, err := rsync.GetCapabilities()
+	if err != nil || supported(caps) {
+		return
+	}
+	cb.SetSensitive(false)
+	cb.SetTooltipText(locale.T(MsgPrefDlgRsyncOptionNotSupportedHint,
+		struct{ Version string }{Version: caps.Version}))
+}
+
+// bindIOLevelSensitivity enable I/O priority level widgets
+// only for best-effort I/O scheduling class.
+func bindIOLevelSensitivity(cbIOClass *gtk.ComboBox, widgets ...gtk.IWidget) error {
+	update := func() {
+		sensitive := cbIOClass.GetActiveID() == rsync.IO_CLASS_BEST_EFFORT
+		for _, widget := range widgets {
+			widget.ToWidget().SetSensitive(sensitive)
+		}
+	}
+	_, err := cbIOClass.Connect("changed", update)
+	if err != nil {
+		return err
+	}
+	update()
+	return nil
+}
+
+// bindSnapshotSensitivity enable snapshot options widgets
+// only when destination snapshot mode is selected.
+func bindSnapshotSensitivity(cbSnapshotMode *gtk.ComboBox, widgets ...gtk.IWidget) error {
+	update := func() {
+		sensitive := cbSnapshotMode.GetActiveID() != ""
+		for _, widget := range widgets {
+			widget.ToWidget().SetSensitive(sensitive)
+		}
+	}
+	_, err := cbSnapshotMode.Connect("changed", update)
+	if err != nil {
+		return err
+	}
+	update()
+	return nil
+}
+
+// bindQuotaSensitivity enable quota policy widgets
+// only when destination quota is specified.
+func bindQuotaSensitivity(sbQuotaSize *gtk.SpinButton, widgets ...gtk.IWidget) error {
+	update := func() {
+		sensitive := sbQuotaSize.GetValueAsInt() > 0
+		for _, widget := range widgets {
+			widget.ToWidget().SetSensitive(sensitive)
+		}
+	}
+	_, err := sbQuotaSize.Connect("value-changed", update)
+	if err != nil {
+		return err
+	}
+	update()
+	return nil
+}
+
 // ProfileStatusState is used to denote profile validating status.
 type ProfileStatusState int
 
@@ -2338,7 +4461,7 @@ func (v *PreferenceRow) updateErrorStatus(lastStatus ProfileStatusState) error {
 			v.setTooltipMarkup(markup.String())
 			err := v.setThemedIcon(STOCK_SYNCHRONIZING_ICON, []string{"image-spin"})
 			if err != nil {
-				lg.Fatal(err)
+				return err
 			}
 		} else if newStatus&ProfileStatusError != 0 {
 			lg.Debug("Error found")
@@ -2347,7 +4470,7 @@ func (v *PreferenceRow) updateErrorStatus(lastStatus ProfileStatusState) error {
 			v.setTooltipMarkup(markup.String())
 			err := v.setThemedIcon(STOCK_IMPORTANT_ICON, []string{"image-error", "image-shake"})
 			if err != nil {
-				lg.Fatal(err)
+				return err
 			}
 		} else {
 			lg.Debug("No errors found")
@@ -2392,6 +4515,15 @@ func (v *PreferenceRowList) Get(rowID uintptr) *PreferenceRow {
 	return v.m[rowID]
 }
 
+func (v *PreferenceRowList) GetByID(id string) *PreferenceRow {
+	for _, rowID := range v.sorted {
+		if v.m[rowID].ID == id {
+			return v.m[rowID]
+		}
+	}
+	return nil
+}
+
 func (v *PreferenceRowList) GetLastProfileListIndex() int {
 	lastIndex := -1
 	for _, rowID := range v.sorted {
@@ -2451,10 +4583,58 @@ func addProfilePage(win *gtk.ApplicationWindow, profileID string, initProfileNam
 	return nil
 }
 
+// duplicateProfileSettings create new profile with deep copy of
+// all profile settings and backup sources of profile srcProfileID.
+func duplicateProfileSettings(appSettings *SettingsStore, profileSettingsArray *SettingsArray,
+	srcProfileID string, changed func()) (string, error) {
+
+	profileID, err := profileSettingsArray.AddNode()
+	if err != nil {
+		return "", err
+	}
+	srcProfileSettings, err := getProfileSettings(appSettings, srcProfileID, nil)
+	if err != nil {
+		return "", err
+	}
+	profileSettings, err := getProfileSettings(appSettings, profileID, changed)
+	if err != nil {
+		return "", err
+	}
+	// Source list is copied here too, since source identifiers
+	// are local to profile settings path.
+	err = profileSettingsArray.CopyNode(srcProfileSettings, profileSettings)
+	if err != nil {
+		return "", err
+	}
+	// Copy must not own backup sessions of original profile.
+	_, err = assignProfileUUID(profileSettings)
+	if err != nil {
+		return "", err
+	}
+	srcArr := srcProfileSettings.NewSettingsArray(CFG_SOURCE_LIST)
+	sarr := profileSettings.NewSettingsArray(CFG_SOURCE_LIST)
+	for _, sourceID := range srcArr.GetArrayIDs() {
+		srcSourceSettings, err := getBackupSourceSettings(srcProfileSettings, sourceID, nil)
+		if err != nil {
+			return "", err
+		}
+		sourceSettings, err := getBackupSourceSettings(profileSettings, sourceID, changed)
+		if err != nil {
+			return "", err
+		}
+		err = sarr.CopyNode(srcSourceSettings, sourceSettings)
+		if err != nil {
+			return "", err
+		}
+	}
+	return profileID, nil
+}
+
 // CreatePreferenceDialog creates multi-page preference dialog
 // with save/restore functionality to/from the GLib Setting object.
+// Page pageID is selected, if specified.
 func CreatePreferenceDialog(settingsID, settingsPath string, mainWin *gtk.ApplicationWindow,
-	profileChanged func()) (*gtk.ApplicationWindow, error) {
+	profileChanged func(), pageID string) (*gtk.ApplicationWindow, error) {
 
 	app, err := mainWin.GetApplication()
 	if err != nil {
@@ -2544,6 +4724,10 @@ func CreatePreferenceDialog(settingsID, settingsPath string, mainWin *gtk.Applic
 		if err != nil {
 			return nil, err
 		}
+		_, err = assignProfileUUID(profileSettings)
+		if err != nil {
+			return nil, err
+		}
 		sarr := profileSettings.NewSettingsArray(CFG_SOURCE_LIST)
 		_, err = sarr.AddNode()
 		if err != nil {
@@ -2584,7 +4768,7 @@ func CreatePreferenceDialog(settingsID, settingsPath string, mainWin *gtk.Applic
 	if err != nil {
 		return nil, err
 	}
-	ap, err := AdvancedPreferencesNew(appSettings, pr)
+	ap, err := AdvancedPreferencesNew(win, appSettings, validator, pr)
 	if err != nil {
 		return nil, err
 	}
@@ -2592,6 +4776,18 @@ func CreatePreferenceDialog(settingsID, settingsPath string, mainWin *gtk.Applic
 	list.Append(pr)
 	lbSide.Add(pr.Row)
 
+	pr, err = PreferenceRowNew("Hidden_ID", locale.T(MsgPrefDlgHiddenTabName, nil), nil, false, false)
+	if err != nil {
+		return nil, err
+	}
+	hp, err := HiddenPreferencesNew(appSettings, pr)
+	if err != nil {
+		return nil, err
+	}
+	pages.AddTitled(hp, "Hidden_ID", locale.T(MsgPrefDlgHiddenTabName, nil))
+	list.Append(pr)
+	lbSide.Add(pr.Row)
+
 	sw, err := gtk.ScrolledWindowNew(nil, nil)
 	if err != nil {
 		return nil, err
@@ -2624,19 +4820,28 @@ func CreatePreferenceDialog(settingsID, settingsPath string, mainWin *gtk.Applic
 		return nil, err
 	}
 	btnAddProfile.SetTooltipText(locale.T(MsgPrefDlgAddProfileHint, nil))
+	SetAccessibleNameAndDescription(&btnAddProfile.Widget, locale.T(MsgPrefDlgAddProfileHint, nil), "")
 	_, err = btnAddProfile.Connect("clicked", func() {
 		profileID, err := profileSettingsArray.AddNode()
 		if err != nil {
-			lg.Fatal(err)
+			reportError(win, err)
+			return
 		}
 		profileSettings, err := getProfileSettings(appSettings, profileID, profileChanged)
 		if err != nil {
-			lg.Fatal(err)
+			reportError(win, err)
+			return
+		}
+		_, err = assignProfileUUID(profileSettings)
+		if err != nil {
+			reportError(win, err)
+			return
 		}
 		sarr := profileSettings.NewSettingsArray(CFG_SOURCE_LIST)
 		_, err = sarr.AddNode()
 		if err != nil {
-			lg.Fatal(err)
+			reportError(win, err)
+			return
 		}
 
 		profileName := profileID
@@ -2646,7 +4851,8 @@ func CreatePreferenceDialog(settingsID, settingsPath string, mainWin *gtk.Applic
 		err = addProfilePage(win, profileID, &profileName, appSettings, list,
 			validator, lbSide, pages, true, profileChanged)
 		if err != nil {
-			lg.Fatal(err)
+			reportError(win, err)
+			return
 		}
 		if profileChanged != nil {
 			profileChanged()
@@ -2657,6 +4863,45 @@ func CreatePreferenceDialog(settingsID, settingsPath string, mainWin *gtk.Applic
 	}
 	bButtons.PackStart(btnAddProfile, false, false, 0)
 
+	btnDuplicateProfile, err := SetupButtonWithThemedImage("edit-copy-symbolic")
+	if err != nil {
+		return nil, err
+	}
+	btnDuplicateProfile.SetTooltipText(locale.T(MsgPrefDlgDuplicateProfileHint, nil))
+	SetAccessibleNameAndDescription(&btnDuplicateProfile.Widget, locale.T(MsgPrefDlgDuplicateProfileHint, nil), "")
+	_, err = btnDuplicateProfile.Connect("clicked", func() {
+		sr := lbSide.GetSelectedRow()
+		if sr == nil {
+			return
+		}
+		pr := list.Get(sr.Native())
+		if !pr.Profile {
+			return
+		}
+		profileID, err := duplicateProfileSettings(appSettings, profileSettingsArray,
+			pr.ID, profileChanged)
+		if err != nil {
+			reportError(win, err)
+			return
+		}
+
+		profileName := locale.T(MsgPrefDlgDuplicateProfileName,
+			struct{ ProfileName string }{ProfileName: pr.GetName()})
+		err = addProfilePage(win, profileID, &profileName, appSettings, list,
+			validator, lbSide, pages, true, profileChanged)
+		if err != nil {
+			reportError(win, err)
+			return
+		}
+		if profileChanged != nil {
+			profileChanged()
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	bButtons.PackStart(btnDuplicateProfile, false, false, 0)
+
 	// Function to manage (enable/disable) "delete backup profile" button.
 	updateBtnDeleteProfileSensitive := func(deleteBtn *gtk.Button, row *gtk.ListBoxRow) {
 		var pr *PreferenceRow
@@ -2666,6 +4911,7 @@ func CreatePreferenceDialog(settingsID, settingsPath string, mainWin *gtk.Applic
 			hbMain.SetTitle(pr.Title)
 		}
 		deleteBtn.SetSensitive(pr != nil && pr.Profile && list.GetProfileCount() > 1)
+		btnDuplicateProfile.SetSensitive(pr != nil && pr.Profile)
 	}
 
 	btnDeleteProfile, err := SetupButtonWithThemedImage("list-remove-symbolic")
@@ -2673,6 +4919,7 @@ func CreatePreferenceDialog(settingsID, settingsPath string, mainWin *gtk.Applic
 		return nil, err
 	}
 	btnDeleteProfile.SetTooltipText(locale.T(MsgPrefDlgDeleteProfileHint, nil))
+	SetAccessibleNameAndDescription(&btnDeleteProfile.Widget, locale.T(MsgPrefDlgDeleteProfileHint, nil), "")
 	_, err = btnDeleteProfile.Connect("clicked", func() {
 		title := locale.T(MsgPrefDlgDeleteProfileDialogTitle, nil)
 		titleMarkup := NewMarkup(MARKUP_SIZE_LARGER, 0, 0, nil, nil,
@@ -2683,7 +4930,8 @@ func CreatePreferenceDialog(settingsID, settingsPath string, mainWin *gtk.Applic
 			struct{ YesButton string }{YesButton: yesButtonMarkup.String()})
 		responseYes, err := questionDialog(&win.Window, titleMarkup.String(), textMarkup, true, true, false)
 		if err != nil {
-			lg.Fatal(err)
+			reportError(win, err)
+			return
 		}
 
 		if responseYes {
@@ -2694,24 +4942,28 @@ func CreatePreferenceDialog(settingsID, settingsPath string, mainWin *gtk.Applic
 				profileID := pr.ID
 				profileSettings, err := getProfileSettings(appSettings, profileID, profileChanged)
 				if err != nil {
-					lg.Fatal(err)
+					reportError(win, err)
+					return
 				}
 				sarr := profileSettings.NewSettingsArray(CFG_SOURCE_LIST)
 				ids := sarr.GetArrayIDs()
 				for _, sourceID := range ids {
 					sourceSettings, err := getBackupSourceSettings(profileSettings, sourceID, profileChanged)
 					if err != nil {
-						lg.Fatal(err)
+						reportError(win, err)
+						return
 					}
 					err = sarr.DeleteNode(sourceSettings, sourceID)
 					if err != nil {
-						lg.Fatal(err)
+						reportError(win, err)
+						return
 					}
 				}
 
 				err = profileSettingsArray.DeleteNode(profileSettings, profileID)
 				if err != nil {
-					lg.Fatal(err)
+					reportError(win, err)
+					return
 				}
 				nsr := lbSide.GetRowAtIndex(sri + 1)
 				lbSide.SelectRow(nsr)
@@ -2752,7 +5004,46 @@ func CreatePreferenceDialog(settingsID, settingsPath string, mainWin *gtk.Applic
 	box.Add(div)
 	box.Add(pages)
 
-	win.Add(box)
+	// Keyboard navigation: F6 return focus to the list of preference
+	// pages from anywhere (Tab move focus forward to page content),
+	// Escape close dialog.
+	_, err = win.Connect("key-press-event", func(w *gtk.ApplicationWindow, event *gdk.Event) bool {
+		key := gdk.EventKeyNewFromEvent(event)
+		switch key.KeyVal() {
+		case gdk.KEY_F6:
+			if row := lbSide.GetSelectedRow(); row != nil {
+				row.GrabFocus()
+			}
+			return true
+		case gdk.KEY_Escape:
+			w.Close()
+			return true
+		}
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if pageID != "" {
+		if pr := list.GetByID(pageID); pr != nil {
+			lbSide.SelectRow(pr.Row)
+		}
+	}
+
+	// Show errors raised in signal handlers above preference pages.
+	errorBar, err := NewErrorBar(&win.Window)
+	if err != nil {
+		return nil, err
+	}
+	boxMain, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 0)
+	if err != nil {
+		return nil, err
+	}
+	boxMain.Add(errorBar.GetWidget())
+	box.SetVExpand(true)
+	boxMain.Add(box)
+	win.Add(boxMain)
 
 	sgSide, err := gtk.SizeGroupNew(gtk.SIZE_GROUP_HORIZONTAL)
 	if err != nil {
@@ -0,0 +1,362 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package gtkui
+
+import (
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/d2r2/go-rsync/backup"
+	"github.com/d2r2/go-rsync/locale"
+	"github.com/d2r2/gotk3/glib"
+	"github.com/d2r2/gotk3/gtk"
+	"github.com/d2r2/gotk3/pango"
+)
+
+// Columns of backup sessions list.
+const (
+	sessionColumnFrozen = iota
+	sessionColumnName
+	sessionColumnCompleted
+	sessionColumnComment
+	sessionColumnWeight
+	sessionColumnPath
+)
+
+// SessionList keep widgets of the window,
+// which list backup sessions found in destination.
+type SessionList struct {
+	store  *gtk.ListStore
+	status *gtk.Label
+}
+
+// sessionRowWeight return font weight to highlight frozen session.
+func sessionRowWeight(frozen bool) int {
+	if frozen {
+		return int(pango.WEIGHT_BOLD)
+	}
+	return int(pango.WEIGHT_NORMAL)
+}
+
+// load fill the list with backup sessions located in destPath.
+func (v *SessionList) load(destPath string) error {
+	v.store.Clear()
+	v.status.SetText("")
+	if destPath == "" {
+		return nil
+	}
+	sessions, err := backup.ListSessions(destPath)
+	if err != nil {
+		v.status.SetText(locale.T(MsgSessionListError,
+			struct{ Error error }{Error: err}))
+		return nil
+	}
+	for _, item := range sessions {
+		_, err := AppendValues(v.store, item.Frozen, filepath.Base(item.Path),
+			item.ModTime.Format("2006 Jan 2 15:04:05"), item.Comment,
+			sessionRowWeight(item.Frozen), item.Path)
+		if err != nil {
+			return err
+		}
+	}
+	if len(sessions) == 0 {
+		v.status.SetText(locale.T(MsgSessionListNothingFound, nil))
+	} else {
+		v.status.SetText(locale.TP(MsgSessionListSessionsFound,
+			struct{ SessionCount int }{SessionCount: len(sessions)}, len(sessions)))
+	}
+	return nil
+}
+
+// getSessionPath return path of the session in the list row.
+func (v *SessionList) getSessionPath(iter *gtk.TreeIter) (string, error) {
+	val, err := v.store.GetValue(iter, sessionColumnPath)
+	if err != nil {
+		return "", err
+	}
+	return val.GetString()
+}
+
+// toggleFrozen freeze or unfreeze the session in the list row.
+func (v *SessionList) toggleFrozen(iter *gtk.TreeIter) error {
+	sessionPath, err := v.getSessionPath(iter)
+	if err != nil {
+		return err
+	}
+	frozen := !backup.IsSessionFrozen(sessionPath)
+	if frozen {
+		err = backup.FreezeSession(sessionPath)
+	} else {
+		err = backup.UnfreezeSession(sessionPath)
+	}
+	if err != nil {
+		v.status.SetText(locale.T(MsgSessionListFreezeError,
+			struct {
+				Path  string
+				Error error
+			}{Path: sessionPath, Error: err}))
+		return nil
+	}
+	return v.store.Set(iter, []int{sessionColumnFrozen, sessionColumnWeight},
+		[]interface{}{frozen, sessionRowWeight(frozen)})
+}
+
+// setComment save comment attached to the session in the list row.
+func (v *SessionList) setComment(iter *gtk.TreeIter, comment string) error {
+	sessionPath, err := v.getSessionPath(iter)
+	if err != nil {
+		return err
+	}
+	err = backup.SetSessionComment(sessionPath, comment)
+	if err != nil {
+		v.status.SetText(locale.T(MsgSessionListCommentError,
+			struct {
+				Path  string
+				Error error
+			}{Path: sessionPath, Error: err}))
+		return nil
+	}
+	return v.store.SetValue(iter, sessionColumnComment, strings.TrimSpace(comment))
+}
+
+// CreateSessionListWindow build window to list backup sessions found in
+// destination, where sessions might be frozen to protect them from pruning.
+func CreateSessionListWindow(mainWin *gtk.ApplicationWindow, destPath string) (*gtk.ApplicationWindow, error) {
+	app, err := mainWin.GetApplication()
+	if err != nil {
+		return nil, err
+	}
+	win, err := gtk.ApplicationWindowNew(app)
+	if err != nil {
+		return nil, err
+	}
+	win.SetTransientFor(mainWin)
+	win.SetDestroyWithParent(false)
+	win.SetShowMenubar(false)
+	win.SetDefaultSize(700, 500)
+
+	hdr, err := SetupHeader(locale.T(MsgSessionListWindowCaption, nil), "", true)
+	if err != nil {
+		return nil, err
+	}
+	win.SetTitlebar(hdr)
+
+	box, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 6)
+	if err != nil {
+		return nil, err
+	}
+	SetAllMargins(box, 9)
+
+	grid, err := gtk.GridNew()
+	if err != nil {
+		return nil, err
+	}
+	grid.SetColumnSpacing(12)
+	grid.SetRowSpacing(6)
+	box.PackStart(grid, false, false, 0)
+
+	lbl, err := SetupLabelJustifyRight(locale.T(MsgSessionListDestCaption, nil))
+	if err != nil {
+		return nil, err
+	}
+	grid.Attach(lbl, 0, 0, 1, 1)
+
+	destFolder, err := gtk.FileChooserButtonNew("Select destination folder", gtk.FILE_CHOOSER_ACTION_SELECT_FOLDER)
+	if err != nil {
+		return nil, err
+	}
+	destFolder.SetTooltipText(locale.T(MsgSessionListDestHint, nil))
+	destFolder.SetHExpand(true)
+	if destPath != "" {
+		destFolder.SetFilename(destPath)
+	}
+	grid.Attach(destFolder, 1, 0, 1, 1)
+
+	store, err := gtk.ListStoreNew(glib.TYPE_BOOLEAN, glib.TYPE_STRING,
+		glib.TYPE_STRING, glib.TYPE_STRING, glib.TYPE_INT, glib.TYPE_STRING)
+	if err != nil {
+		return nil, err
+	}
+	tv, err := gtk.TreeViewNewWithModel(store)
+	if err != nil {
+		return nil, err
+	}
+
+	toggle, err := gtk.CellRendererToggleNew()
+	if err != nil {
+		return nil, err
+	}
+	toggle.SetActivatable(true)
+	col, err := gtk.TreeViewColumnNewWithAttribute(locale.T(MsgSessionListFrozenColumn, nil),
+		toggle, "active", sessionColumnFrozen)
+	if err != nil {
+		return nil, err
+	}
+	tv.AppendColumn(col)
+
+	columns := []struct {
+		title    string
+		columnID int
+	}{
+		{locale.T(MsgSessionListSessionColumn, nil), sessionColumnName},
+		{locale.T(MsgSessionListCompletedColumn, nil), sessionColumnCompleted},
+		{locale.T(MsgSessionListCommentColumn, nil), sessionColumnComment},
+	}
+	var commentCell *gtk.CellRendererText
+	for _, item := range columns {
+		cell, err := gtk.CellRendererTextNew()
+		if err != nil {
+			return nil, err
+		}
+		col, err := gtk.TreeViewColumnNewWithAttribute(item.title, cell, "text", item.columnID)
+		if err != nil {
+			return nil, err
+		}
+		// Highlight frozen sessions.
+		col.AddAttribute(cell, "weight", sessionColumnWeight)
+		if item.columnID == sessionColumnComment {
+			// Comment might be edited in place.
+			err = cell.SetProperty("editable", true)
+			if err != nil {
+				return nil, err
+			}
+			commentCell = cell
+		}
+		col.SetResizable(true)
+		col.SetSortColumnID(item.columnID)
+		tv.AppendColumn(col)
+	}
+	tv.SetTooltipText(locale.T(MsgSessionListHint, nil))
+
+	sw, err := gtk.ScrolledWindowNew(nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	sw.SetVExpand(true)
+	sw.Add(tv)
+	box.PackStart(sw, true, true, 0)
+
+	status, err := gtk.LabelNew("")
+	if err != nil {
+		return nil, err
+	}
+	status.SetHAlign(gtk.ALIGN_START)
+	box.PackStart(status, false, false, 0)
+
+	list := &SessionList{store: store, status: status}
+
+	_, err = toggle.Connect("toggled", func(cell *gtk.CellRendererToggle, path string) {
+		iter, err := store.GetIterFromString(path)
+		if err != nil {
+			reportError(win, err)
+			return
+		}
+		err = list.toggleFrozen(iter)
+		if err != nil {
+			reportError(win, err)
+			return
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	_, err = commentCell.Connect("edited", func(cell *gtk.CellRendererText, path string, text string) {
+		iter, err := store.GetIterFromString(path)
+		if err != nil {
+			reportError(win, err)
+			return
+		}
+		err = list.setComment(iter, text)
+		if err != nil {
+			reportError(win, err)
+			return
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = destFolder.Connect("file-set", func() {
+		err := list.load(destFolder.GetFilename())
+		if err != nil {
+			reportError(win, err)
+			return
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Open session folder.
+	_, err = tv.Connect("row-activated", func(tv *gtk.TreeView, path *gtk.TreePath) {
+		iter, err := store.GetIter(path)
+		if err != nil {
+			reportError(win, err)
+			return
+		}
+		sessionPath, err := list.getSessionPath(iter)
+		if err != nil {
+			reportError(win, err)
+			return
+		}
+		uri := &url.URL{Scheme: "file", Path: sessionPath}
+		err = ShowUri(&win.Window, uri.String())
+		if err != nil {
+			lg.Warn(err)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	win.Add(box)
+
+	err = list.load(destPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return win, nil
+}
+
+// createSessionListAction creates action to open window,
+// which list backup sessions found in destination.
+func createSessionListAction(mainWin *gtk.ApplicationWindow, destPath *string) (glib.IAction, error) {
+	act, err := glib.SimpleActionNew("SessionListAction", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = act.Connect("activate", func(action *glib.SimpleAction, param *glib.Variant) {
+		name, state, err := GetActionNameAndState(action)
+		if err != nil {
+			reportError(mainWin, err)
+			return
+		}
+		lg.Debugf("%v action activated with current state %v and args %v",
+			name, state, param)
+
+		win, err := CreateSessionListWindow(mainWin, *destPath)
+		if err != nil {
+			reportError(mainWin, err)
+			return
+		}
+
+		win.ShowAll()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return act, nil
+}
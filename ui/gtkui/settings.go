@@ -19,17 +19,41 @@ const (
 	PROFILE_SCHEMA_SUFFIX_PATH = "profiles/%s"
 	SOURCE_SCHEMA_SUFFIX_ID    = "Source"
 	SOURCE_SCHEMA_SUFFIX_PATH  = "sources/%s"
+
+	CREDENTIAL_SCHEMA_SUFFIX_ID   = "Credential"
+	CREDENTIAL_SCHEMA_SUFFIX_PATH = "credentials/%s"
 )
 
 const (
 	CFG_IGNORE_FILE_SIGNATURE                          = "ignore-file-signature"
 	CFG_RSYNC_RETRY_COUNT                              = "rsync-retry-count"
+	CFG_NETWORK_WATCHDOG_ENABLED                       = "network-watchdog-enabled"
+	CFG_NETWORK_OUTAGE_MAX_WAIT_MIN                    = "network-outage-max-wait-min"
+	CFG_MODULE_HEALTH_CHECK_INTERVAL_MIN               = "module-health-check-interval-min"
+	CFG_RSYNC_HANG_TIMEOUT_MIN                         = "rsync-hang-timeout-min"
+	CFG_RSYNC_IO_TIMEOUT_SEC                           = "rsync-io-timeout-sec"
+	CFG_RSYNC_CONNECT_TIMEOUT_SEC                      = "rsync-connect-timeout-sec"
+	CFG_VERIFY_SSH_HOST_KEYS                           = "verify-ssh-host-keys"
+	CFG_RSYNC_NICE_LEVEL                               = "rsync-nice-level"
+	CFG_RSYNC_IO_CLASS                                 = "rsync-io-class"
+	CFG_RSYNC_IO_LEVEL                                 = "rsync-io-level"
 	CFG_MANAGE_AUTO_BACKUP_BLOCK_SIZE                  = "manage-automatically-backup-block-size"
 	CFG_MAX_BACKUP_BLOCK_SIZE_MB                       = "max-backup-block-size-mb"
+	CFG_BUILD_DIR_TREE_IN_MEMORY                       = "build-dir-tree-in-memory"
+	CFG_PLAN_STAGE_TEMP_PATH                           = "plan-stage-temp-path"
+	CFG_INCREMENTAL_PLAN                               = "incremental-plan"
+	CFG_PLAN_TRACE                                     = "plan-trace"
 	CFG_ENABLE_USE_OF_PREVIOUS_BACKUP                  = "enable-use-of-previous-backup"
 	CFG_NUMBER_OF_PREVIOUS_BACKUP_TO_USE               = "number-of-previous-backup-to-use"
+	CFG_SAFE_DELETE_ENABLED                            = "safe-delete-enabled"
+	CFG_SAFE_DELETE_MAX_FILES                          = "safe-delete-max-files"
+	CFG_SAFE_DELETE_MAX_SIZE_MB                        = "safe-delete-max-size-mb"
 	CFG_ENABLE_LOW_LEVEL_LOG_OF_RSYNC                  = "enable-low-level-log-for-rsync"
 	CFG_ENABLE_INTENSIVE_LOW_LEVEL_LOG_OF_RSYNC        = "enable-intensive-low-level-log-for-rsync"
+	CFG_SESSION_LOG_FORMAT                             = "session-log-format"
+	CFG_LOG_SEGMENT_MAX_SIZE_MB                        = "log-segment-max-size-mb"
+	CFG_GENERATE_BACKUP_CATALOG                        = "generate-backup-catalog"
+	CFG_GENERATE_CHECKSUM_MANIFEST                     = "generate-checksum-manifest"
 	CFG_RSYNC_TRANSFER_SOURCE_GROUP_INCONSISTENT       = "rsync-transfer-source-group-inconsistent"
 	CFG_RSYNC_TRANSFER_SOURCE_GROUP                    = "rsync-transfer-source-group"
 	CFG_RSYNC_TRANSFER_SOURCE_OWNER_INCONSISTENT       = "rsync-transfer-source-owner-inconsistent"
@@ -42,19 +66,87 @@ const (
 	CFG_RSYNC_TRANSFER_DEVICE_FILES                    = "rsync-transfer-device-files"
 	CFG_RSYNC_TRANSFER_SPECIAL_FILES_INCONSISTENT      = "rsync-transfer-special-files-inconsistent"
 	CFG_RSYNC_TRANSFER_SPECIAL_FILES                   = "rsync-transfer-special-files"
+	CFG_RSYNC_TRANSFER_ACLS_INCONSISTENT               = "rsync-transfer-acls-inconsistent"
+	CFG_RSYNC_TRANSFER_ACLS                            = "rsync-transfer-acls"
+	CFG_RSYNC_TRANSFER_XATTRS_INCONSISTENT             = "rsync-transfer-xattrs-inconsistent"
+	CFG_RSYNC_TRANSFER_XATTRS                          = "rsync-transfer-xattrs"
 	CFG_RSYNC_COMPRESS_FILE_TRANSFER                   = "rsync-compress-file-transfer"
+	CFG_RSYNC_COMPRESS_CHOICE                          = "rsync-compress-choice"
+	CFG_RSYNC_COMPRESS_LEVEL                           = "rsync-compress-level"
+	CFG_RSYNC_PARTIAL_TRANSFER                         = "rsync-partial-transfer"
+	CFG_RSYNC_SPARSE_FILES                             = "rsync-sparse-files"
+	CFG_RSYNC_INPLACE                                  = "rsync-inplace"
 	CFG_BACKUP_LIST                                    = "profile-list"
 	CFG_SOURCE_LIST                                    = "source-list"
+	CFG_CREDENTIAL_LIST                                = "credential-list"
+	CFG_CREDENTIAL_HOST                                = "host"
+	CFG_CREDENTIAL_USER                                = "user"
+	CFG_CREDENTIAL_PASSWORD                            = "password"
+	CFG_CREDENTIAL_KEY_PATH                            = "key-path"
 	CFG_DONT_SHOW_ABOUT_ON_STARTUP                     = "dont-show-about-dialog-on-startup"
+	CFG_CHECK_FOR_UPDATES                              = "check-for-updates"
+	CFG_UPDATE_DISMISSED_VERSION                       = "update-dismissed-version"
 	CFG_UI_LANGUAGE                                    = "ui-language"
+	CFG_UI_THEME                                       = "ui-theme"
+	CFG_SIZE_UNITS                                     = "size-units"
 	CFG_SESSION_LOG_WIDGET_FONT_SIZE                   = "session-log-widget-font-size"
 	CFG_PROFILE_NAME                                   = "profile-name"
+	CFG_PROFILE_UUID                                   = "profile-uuid"
 	CFG_PROFILE_DEST_ROOT_PATH                         = "destination-root-path"
+	CFG_PROFILE_DEST_HISTORY                           = "destination-history"
+	CFG_PROFILE_DEST_MOUNT_ENABLED                     = "destination-mount-enabled"
+	CFG_PROFILE_DEST_MOUNT_SPEC                        = "destination-mount-spec"
+	CFG_PROFILE_DEST_UNMOUNT_AFTER_BACKUP              = "destination-unmount-after-backup"
+	CFG_PROFILE_DEST_SYNC_AFTER_BACKUP                 = "destination-sync-after-backup"
+	CFG_PROFILE_DEST_EJECT_AFTER_BACKUP                = "destination-eject-after-backup"
+	CFG_PROFILE_DEST_DRIVE_UUID                        = "destination-drive-uuid"
+	CFG_PROFILE_DEST_DRIVE_SUBPATH                     = "destination-drive-subpath"
+	CFG_PROFILE_DEST_DRIVE_ASK_BACKUP                  = "destination-drive-ask-backup"
+	CFG_PROFILE_MIRROR_DEST_PATHS                      = "mirror-destination-paths"
+	CFG_PROFILE_CLOUD_SYNC_ENABLED                     = "cloud-sync-enabled"
+	CFG_PROFILE_CLOUD_SYNC_REMOTE                      = "cloud-sync-remote"
+	CFG_PROFILE_CLOUD_SYNC_PATH                        = "cloud-sync-path"
+	CFG_PROFILE_SNAPSHOT_MODE                          = "snapshot-mode"
+	CFG_PROFILE_SNAPSHOT_NAME_TEMPLATE                 = "snapshot-name-template"
+	CFG_PROFILE_SNAPSHOT_KEEP                          = "snapshot-keep"
+	CFG_PROFILE_MODULE_ERROR_POLICY                    = "module-error-policy"
+	CFG_PROFILE_SESSION_LOG_VERBOSITY                  = "session-log-verbosity"
+	CFG_PROFILE_QUOTA_SIZE_GB                          = "quota-size-gb"
+	CFG_PROFILE_QUOTA_POLICY                           = "quota-policy"
+	CFG_PROFILE_BACKUP_WINDOW_ENABLED                  = "backup-window-enabled"
+	CFG_PROFILE_BACKUP_WINDOW_START                    = "backup-window-start"
+	CFG_PROFILE_BACKUP_WINDOW_END                      = "backup-window-end"
+	CFG_PROFILE_BACKUP_WINDOW_REQUIRE_AC_POWER         = "backup-window-require-ac-power"
+	CFG_PROFILE_BACKUP_WINDOW_REQUIRE_UNMETERED        = "backup-window-require-unmetered"
 	CFG_MODULE_RSYNC_SOURCE_PATH                       = "rsync-source-path"
 	CFG_MODULE_DEST_SUBPATH                            = "dest-subpath"
+	CFG_MODULE_DEST_ROOT_PATH                          = "dest-root-path"
 	CFG_MODULE_CHANGE_FILE_PERMISSION                  = "change-file-permission"
 	CFG_MODULE_AUTH_PASSWORD                           = "auth-password"
+	CFG_MODULE_MAX_FILE_SIZE_MB                        = "max-file-size-mb"
+	CFG_MODULE_MAX_FILE_AGE_DAYS                       = "max-file-age-days"
+	CFG_MODULE_MIN_FILE_AGE_DAYS                       = "min-file-age-days"
+	CFG_MODULE_EXCLUDE_PATTERNS                        = "exclude-patterns"
+	CFG_MODULE_USER_MAP                                = "user-map"
+	CFG_MODULE_GROUP_MAP                               = "group-map"
+	CFG_MODULE_CHOWN                                   = "chown"
+	CFG_MODULE_SOURCE_CHARSET                          = "source-charset"
+	CFG_MODULE_IO_TIMEOUT_SEC                          = "io-timeout-sec"
+	CFG_MODULE_CONNECT_TIMEOUT_SEC                     = "connect-timeout-sec"
+	CFG_MODULE_NICE_LEVEL                              = "nice-level"
+	CFG_MODULE_IO_CLASS                                = "io-class"
+	CFG_MODULE_IO_LEVEL                                = "io-level"
+	CFG_MODULE_TAGS                                    = "tags"
+	CFG_MODULE_SOURCE_ALIASES                          = "source-aliases"
+	CFG_MODULE_SKIP_PLAN_ESTIMATION                    = "skip-plan-estimation"
 	CFG_MODULE_ENABLED                                 = "source-dest-block-enabled"
 	CFG_PERFORM_DESKTOP_NOTIFICATION                   = "perform-backup-completion-desktop-notification"
+	CFG_CONFIRM_BACKUP_PLAN                            = "confirm-backup-plan"
+	CFG_ASK_SESSION_COMMENT                            = "ask-session-comment"
+	CFG_ENABLE_TRAY_ICON                               = "enable-tray-icon"
+	CFG_METRICS_ENDPOINT_ENABLED                       = "metrics-endpoint-enabled"
+	CFG_METRICS_LISTEN_ADDRESS                         = "metrics-listen-address"
+	CFG_INHIBIT_SUSPEND_DURING_BACKUP                  = "inhibit-suspend-during-backup"
 	CFG_RUN_NOTIFICATION_SCRIPT                        = "run-backup-completion-notification-script"
+	CFG_NOTIFICATION_SCRIPT_PATH                       = "notification-script-path"
 )
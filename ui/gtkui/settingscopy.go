@@ -0,0 +1,48 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package gtkui
+
+// #cgo pkg-config: gio-2.0
+// #include <stdlib.h>
+// #include <gio/gio.h>
+//
+// // Copy value explicitly set by user, otherwise reset key to default.
+// static gboolean _g_settings_copy_user_value(GSettings *src, GSettings *dst, const gchar *key) {
+//     GVariant *value = g_settings_get_user_value(src, key);
+//     if (value == NULL) {
+//         g_settings_reset(dst, key);
+//         return TRUE;
+//     }
+//     gboolean ok = g_settings_set_value(dst, key, value);
+//     g_variant_unref(value);
+//     return ok;
+// }
+import "C"
+import (
+	"errors"
+	"unsafe"
+
+	"github.com/d2r2/gotk3/glib"
+)
+
+// copySettingsValue copy key value between two glib.Settings objects
+// of the same schema. Keys not customized by user remain default.
+func copySettingsValue(src, dst *glib.Settings, key string) error {
+	cstr := C.CString(key)
+	defer C.free(unsafe.Pointer(cstr))
+	ok := C._g_settings_copy_user_value((*C.GSettings)(unsafe.Pointer(src.Native())),
+		(*C.GSettings)(unsafe.Pointer(dst.Native())), (*C.gchar)(cstr))
+	if ok == 0 {
+		return errors.New("GLib settings key \"" + key + "\" is not writable")
+	}
+	return nil
+}
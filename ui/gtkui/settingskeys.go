@@ -0,0 +1,187 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package gtkui
+
+// #cgo pkg-config: gio-2.0
+// #include <stdlib.h>
+// #include <gio/gio.h>
+//
+// static GSettingsSchemaKey *_g_settings_get_schema_key(GSettings *settings, const gchar *key) {
+//     GSettingsSchema *schema = NULL;
+//     g_object_get(settings, "settings-schema", &schema, NULL);
+//     GSettingsSchemaKey *skey = g_settings_schema_get_key(schema, key);
+//     g_settings_schema_unref(schema);
+//     return skey;
+// }
+//
+// // Return value type of the key in GVariant type string format, like "b", "i" or "s".
+// static gchar *_g_settings_key_type(GSettings *settings, const gchar *key) {
+//     GSettingsSchemaKey *skey = _g_settings_get_schema_key(settings, key);
+//     gchar *result = g_variant_type_dup_string(g_settings_schema_key_get_value_type(skey));
+//     g_settings_schema_key_unref(skey);
+//     return result;
+// }
+//
+// static gchar *_g_settings_key_summary(GSettings *settings, const gchar *key) {
+//     GSettingsSchemaKey *skey = _g_settings_get_schema_key(settings, key);
+//     gchar *result = g_strdup(g_settings_schema_key_get_summary(skey));
+//     g_settings_schema_key_unref(skey);
+//     return result;
+// }
+//
+// // Return values allowed for the key, or NULL if any value of key type is allowed.
+// static gchar *_g_settings_key_range(GSettings *settings, const gchar *key) {
+//     GSettingsSchemaKey *skey = _g_settings_get_schema_key(settings, key);
+//     GVariant *range = g_settings_schema_key_get_range(skey);
+//     const gchar *kind = NULL;
+//     GVariant *values = NULL;
+//     gchar *result = NULL;
+//     g_variant_get(range, "(&sv)", &kind, &values);
+//     if (g_strcmp0(kind, "range") == 0) {
+//         GVariant *min = g_variant_get_child_value(values, 0);
+//         GVariant *max = g_variant_get_child_value(values, 1);
+//         gchar *minText = g_variant_print(min, FALSE);
+//         gchar *maxText = g_variant_print(max, FALSE);
+//         result = g_strdup_printf("%s..%s", minText, maxText);
+//         g_free(minText);
+//         g_free(maxText);
+//         g_variant_unref(min);
+//         g_variant_unref(max);
+//     } else if (g_strcmp0(kind, "enum") == 0) {
+//         result = g_variant_print(values, FALSE);
+//     }
+//     g_variant_unref(values);
+//     g_variant_unref(range);
+//     g_settings_schema_key_unref(skey);
+//     return result;
+// }
+//
+// static gboolean _g_settings_key_modified(GSettings *settings, const gchar *key) {
+//     GVariant *value = g_settings_get_user_value(settings, key);
+//     if (value == NULL) {
+//         return FALSE;
+//     }
+//     g_variant_unref(value);
+//     return TRUE;
+// }
+//
+// // Parse text as key value and save it. Return 0 on success, 1 if text is not parsed
+// // (error message returned), 2 if value is out of range, 3 if key is not writable.
+// static int _g_settings_set_value_from_text(GSettings *settings, const gchar *key,
+//         const gchar *text, gchar **message) {
+//     GSettingsSchemaKey *skey = _g_settings_get_schema_key(settings, key);
+//     const GVariantType *type = g_settings_schema_key_get_value_type(skey);
+//     GVariant *value = NULL;
+//     int result = 0;
+//     if (g_variant_type_equal(type, G_VARIANT_TYPE_STRING)) {
+//         // Strings are edited without quotes of GVariant text format.
+//         value = g_variant_ref_sink(g_variant_new_string(text));
+//     } else {
+//         GError *error = NULL;
+//         value = g_variant_parse(type, text, NULL, NULL, &error);
+//         if (value == NULL) {
+//             *message = g_strdup(error->message);
+//             g_error_free(error);
+//             g_settings_schema_key_unref(skey);
+//             return 1;
+//         }
+//     }
+//     if (!g_settings_schema_key_range_check(skey, value)) {
+//         result = 2;
+//     } else if (!g_settings_set_value(settings, key, value)) {
+//         result = 3;
+//     }
+//     g_variant_unref(value);
+//     g_settings_schema_key_unref(skey);
+//     return result;
+// }
+import "C"
+import (
+	"errors"
+	"unsafe"
+
+	"github.com/d2r2/go-rsync/locale"
+	"github.com/d2r2/gotk3/glib"
+)
+
+// settingsKey describe GLib settings key as it's declared in schema.
+type settingsKey struct {
+	Name    string
+	Type    string
+	Summary string
+	// Values allowed, if limited by schema.
+	Range string
+}
+
+// takeGString convert string allocated by GLib to Go string and free it.
+func takeGString(str *C.gchar) string {
+	if str == nil {
+		return ""
+	}
+	defer C.g_free(C.gpointer(str))
+	return C.GoString((*C.char)(str))
+}
+
+func nativeSettings(settings *glib.Settings) *C.GSettings {
+	return (*C.GSettings)(unsafe.Pointer(settings.Native()))
+}
+
+// getSettingsKey read key declaration from settings schema.
+func getSettingsKey(settings *glib.Settings, key string) *settingsKey {
+	cstr := (*C.gchar)(C.CString(key))
+	defer C.free(unsafe.Pointer(cstr))
+	native := nativeSettings(settings)
+	return &settingsKey{
+		Name:    key,
+		Type:    takeGString(C._g_settings_key_type(native, cstr)),
+		Summary: takeGString(C._g_settings_key_summary(native, cstr)),
+		Range:   takeGString(C._g_settings_key_range(native, cstr)),
+	}
+}
+
+// isSettingsValueModified return true, if key value was set by user,
+// otherwise default value from schema is used.
+func isSettingsValueModified(settings *glib.Settings, key string) bool {
+	cstr := (*C.gchar)(C.CString(key))
+	defer C.free(unsafe.Pointer(cstr))
+	return C._g_settings_key_modified(nativeSettings(settings), cstr) != 0
+}
+
+// getSettingsValueText return key value in GVariant text format,
+// except strings, which are returned as is.
+func getSettingsValueText(settings *glib.Settings, key *settingsKey) string {
+	if key.Type == "s" {
+		return settings.GetString(key.Name)
+	}
+	return settings.GetValue(key.Name).String()
+}
+
+// setSettingsValueText parse text in GVariant text format (strings are taken
+// as is) and save it as key value, if value is allowed by schema.
+func setSettingsValueText(settings *glib.Settings, key *settingsKey, text string) error {
+	cstr := (*C.gchar)(C.CString(key.Name))
+	defer C.free(unsafe.Pointer(cstr))
+	ctext := (*C.gchar)(C.CString(text))
+	defer C.free(unsafe.Pointer(ctext))
+	var message *C.gchar
+	switch C._g_settings_set_value_from_text(nativeSettings(settings), cstr, ctext, &message) {
+	case 1:
+		return errors.New(locale.T(MsgHiddenSettingsParseError,
+			struct{ Type, Error string }{Type: key.Type, Error: takeGString(message)}))
+	case 2:
+		return errors.New(locale.T(MsgHiddenSettingsOutOfRangeError,
+			struct{ Range string }{Range: key.Range}))
+	case 3:
+		return errors.New(locale.T(MsgHiddenSettingsNotWritableError, nil))
+	}
+	return nil
+}
@@ -155,6 +155,23 @@ func (v *SettingsArray) AddNode() (nodeID string, err error) {
 	return list[len(list)-1], nil
 }
 
+// CopyNode copy all keys of indexed glib.Settings to another one.
+// Nested settings (if any) should be copied separately.
+func (v *SettingsArray) CopyNode(srcStore, dstStore *SettingsStore) error {
+	schema, err := srcStore.GetSchema()
+	if err != nil {
+		return err
+	}
+	keys := schema.ListKeys()
+	for _, key := range keys {
+		err = copySettingsValue(srcStore.settings, dstStore.settings, key)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // GetArrayIDs return identifiers of glib.Settings with common schema,
 // which can be accessed using id from the list.
 func (v *SettingsArray) GetArrayIDs() []string {
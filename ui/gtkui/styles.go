@@ -0,0 +1,248 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package gtkui
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/d2r2/gotk3/gdk"
+	"github.com/d2r2/gotk3/glib"
+	"github.com/d2r2/gotk3/gtk"
+)
+
+// Application theme variants, which might be selected in preferences.
+const (
+	// Follow desktop settings.
+	THEME_SYSTEM = "system"
+	THEME_LIGHT  = "light"
+	THEME_DARK   = "dark"
+)
+
+// GTK+ settings, which identify active theme variant.
+const (
+	gtkPreferDarkThemeProperty = "gtk-application-prefer-dark-theme"
+	gtkThemeNameProperty       = "gtk-theme-name"
+)
+
+// AppStyles manage application CSS styles: base styles, common for all
+// themes, styles specific to light or dark theme variant and user CSS
+// override file. Styles are applied globally at screen level.
+type AppStyles struct {
+	sync.Mutex
+	screen   *gdk.Screen
+	settings *gtk.Settings
+	// Desktop preference of dark theme, saved before any change.
+	systemPreferDark bool
+	theme            string
+	variant          *gtk.CssProvider
+	user             *gtk.CssProvider
+}
+
+// Global object to manage application styles, initialized on startup.
+var appStyles *AppStyles
+
+// NewAppStyles load base application CSS and
+// prepare to apply theme specific styles.
+func NewAppStyles() (*AppStyles, error) {
+	screen, err := gdk.ScreenGetDefault()
+	if err != nil {
+		return nil, err
+	}
+	settings, err := gtk.SettingsGetDefault()
+	if err != nil {
+		return nil, err
+	}
+	v := &AppStyles{screen: screen, settings: settings}
+	v.systemPreferDark, err = v.getPreferDarkTheme()
+	if err != nil {
+		return nil, err
+	}
+
+	css, err := GetBaseApplicationCSS()
+	if err != nil {
+		return nil, err
+	}
+	provider, err := gtk.CssProviderNew()
+	if err != nil {
+		return nil, err
+	}
+	err = provider.LoadFromData(css)
+	if err != nil {
+		return nil, err
+	}
+	// Select "APPLICATION" or "USER" priority to override global "THEME" settings.
+	gtk.AddProviderForScreen(screen, provider, gtk.STYLE_PROVIDER_PRIORITY_APPLICATION)
+
+	// Reload theme specific styles, once desktop theme changed.
+	for _, property := range []string{gtkPreferDarkThemeProperty, gtkThemeNameProperty} {
+		_, err = settings.Connect("notify::"+property, func() {
+			v.Lock()
+			theme := v.theme
+			v.Unlock()
+			if theme == THEME_SYSTEM {
+				err := v.applyVariant()
+				if err != nil {
+					lg.Fatal(err)
+				}
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+func (v *AppStyles) getPreferDarkTheme() (bool, error) {
+	value, err := v.settings.GetProperty(gtkPreferDarkThemeProperty)
+	if err != nil {
+		return false, err
+	}
+	preferDark, _ := value.(bool)
+	return preferDark, nil
+}
+
+// IsDarkThemeActive verify that dark theme variant is used either
+// via preference setting, or selected theme name (like "Adwaita-dark").
+func (v *AppStyles) IsDarkThemeActive() (bool, error) {
+	preferDark, err := v.getPreferDarkTheme()
+	if err != nil {
+		return false, err
+	}
+	if preferDark {
+		return true, nil
+	}
+	value, err := v.settings.GetProperty(gtkThemeNameProperty)
+	if err != nil {
+		return false, err
+	}
+	themeName, _ := value.(string)
+	themeName = strings.ToLower(themeName)
+	return strings.HasSuffix(themeName, "-dark") || strings.HasSuffix(themeName, ":dark"), nil
+}
+
+// Apply select theme variant (system, light or dark) and
+// reload theme specific styles with user CSS override file.
+func (v *AppStyles) Apply(theme string) error {
+	v.Lock()
+	v.theme = theme
+	v.Unlock()
+
+	var preferDark bool
+	switch theme {
+	case THEME_DARK:
+		preferDark = true
+	case THEME_LIGHT:
+		preferDark = false
+	default:
+		preferDark = v.systemPreferDark
+	}
+	current, err := v.getPreferDarkTheme()
+	if err != nil {
+		return err
+	}
+	if current != preferDark {
+		// Change of property reload styles via notify signal,
+		// but do it explicitly to apply selected theme at once.
+		err = v.settings.SetProperty(gtkPreferDarkThemeProperty, preferDark)
+		if err != nil {
+			return err
+		}
+	}
+	return v.applyVariant()
+}
+
+// applyVariant replace theme specific and user styles.
+func (v *AppStyles) applyVariant() error {
+	v.Lock()
+	defer v.Unlock()
+
+	dark, err := v.IsDarkThemeActive()
+	if err != nil {
+		return err
+	}
+	assetName := "light.css"
+	if dark {
+		assetName = "dark.css"
+	}
+	css, err := GetAssetsCSS(assetName)
+	if err != nil {
+		return err
+	}
+	provider, err := gtk.CssProviderNew()
+	if err != nil {
+		return err
+	}
+	err = provider.LoadFromData(css)
+	if err != nil {
+		return err
+	}
+	if v.variant != nil {
+		gtk.RemoveProviderForScreen(v.screen, v.variant)
+	}
+	v.variant = provider
+	gtk.AddProviderForScreen(v.screen, provider, gtk.STYLE_PROVIDER_PRIORITY_APPLICATION)
+
+	// User CSS override file is applied with highest priority.
+	if v.user != nil {
+		gtk.RemoveProviderForScreen(v.screen, v.user)
+		v.user = nil
+	}
+	path, err := GetUserCSSPath()
+	if err != nil {
+		lg.Debug(err)
+		return nil
+	}
+	if _, err := os.Stat(path); err == nil {
+		provider, err := gtk.CssProviderNew()
+		if err != nil {
+			return err
+		}
+		// Syntax errors in user file shouldn't break application.
+		err = provider.LoadFromPath(path)
+		if err != nil {
+			lg.Warnf("Can't load user CSS file %q: %v", path, err)
+			return nil
+		}
+		v.user = provider
+		gtk.AddProviderForScreen(v.screen, provider, gtk.STYLE_PROVIDER_PRIORITY_USER)
+	}
+	return nil
+}
+
+// GetUserCSSPath return path to user CSS override file:
+// $XDG_CONFIG_HOME/gorsync/user.css, or ~/.config/gorsync/user.css by default.
+func GetUserCSSPath() (string, error) {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		u, err := user.Current()
+		if err != nil {
+			return "", err
+		}
+		configHome = filepath.Join(u.HomeDir, ".config")
+	}
+	return filepath.Join(configHome, "gorsync", "user.css"), nil
+}
+
+// GetThemePreference reads application theme preference customized by user.
+func GetThemePreference() (string, error) {
+	appSettings, err := glib.SettingsNew(SETTINGS_SCHEMA_ID)
+	if err != nil {
+		return "", err
+	}
+	theme := appSettings.GetString(CFG_UI_THEME)
+	return theme, nil
+}
@@ -0,0 +1,313 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package gtkui
+
+// #cgo pkg-config: gtk+-3.0
+// #include <stdlib.h>
+// #include <gtk/gtk.h>
+//
+// // GtkStatusIcon is deprecated since GTK+ 3.14, but still the only
+// // tray icon implementation available without extra dependencies.
+// // Desktops with StatusNotifier protocol support it via XEmbed proxy.
+// static GtkStatusIcon* _gtk_status_icon_new_from_icon_name(const gchar *icon_name) {
+//     G_GNUC_BEGIN_IGNORE_DEPRECATIONS
+//     return gtk_status_icon_new_from_icon_name(icon_name);
+//     G_GNUC_END_IGNORE_DEPRECATIONS
+// }
+//
+// static void _gtk_status_icon_set_from_icon_name(GtkStatusIcon *icon, const gchar *icon_name) {
+//     G_GNUC_BEGIN_IGNORE_DEPRECATIONS
+//     gtk_status_icon_set_from_icon_name(icon, icon_name);
+//     G_GNUC_END_IGNORE_DEPRECATIONS
+// }
+//
+// static void _gtk_status_icon_set_tooltip_text(GtkStatusIcon *icon, const gchar *text) {
+//     G_GNUC_BEGIN_IGNORE_DEPRECATIONS
+//     gtk_status_icon_set_tooltip_text(icon, text);
+//     G_GNUC_END_IGNORE_DEPRECATIONS
+// }
+//
+// static void _gtk_status_icon_set_title(GtkStatusIcon *icon, const gchar *title) {
+//     G_GNUC_BEGIN_IGNORE_DEPRECATIONS
+//     gtk_status_icon_set_title(icon, title);
+//     G_GNUC_END_IGNORE_DEPRECATIONS
+// }
+//
+// static void _gtk_status_icon_set_visible(GtkStatusIcon *icon, gboolean visible) {
+//     G_GNUC_BEGIN_IGNORE_DEPRECATIONS
+//     gtk_status_icon_set_visible(icon, visible);
+//     G_GNUC_END_IGNORE_DEPRECATIONS
+// }
+//
+// static void _gtk_status_icon_popup_menu(GtkStatusIcon *icon, GtkMenu *menu) {
+//     G_GNUC_BEGIN_IGNORE_DEPRECATIONS
+//     gtk_menu_popup(menu, NULL, NULL, gtk_status_icon_position_menu, icon,
+//         0, gtk_get_current_event_time());
+//     G_GNUC_END_IGNORE_DEPRECATIONS
+// }
+import "C"
+import (
+	"errors"
+	"sync"
+	"unsafe"
+
+	"github.com/d2r2/go-rsync/core"
+	"github.com/d2r2/go-rsync/locale"
+	"github.com/d2r2/gotk3/glib"
+	"github.com/d2r2/gotk3/gtk"
+)
+
+// Icons displayed in system tray.
+const (
+	TRAY_IDLE_ICON    = "media-tape-symbolic"
+	TRAY_RUNNING_ICON = STOCK_SYNCHRONIZING_ICON
+)
+
+// TrayIcon keep status icon displayed in system tray, which allow
+// to close main window and keep backup session running in background.
+// Tray menu give access to backup start/stop for each profile and
+// reopen main window.
+type TrayIcon struct {
+	sync.Mutex
+	icon       *C.GtkStatusIcon
+	obj        *glib.Object
+	menu       *gtk.Menu
+	win        *gtk.ApplicationWindow
+	profile    *gtk.ComboBox
+	backupSync *BackupSessionStatus
+	enabled    bool
+	// Name of profile with backup session running.
+	runningProfile string
+}
+
+// Global object to manage tray icon, initialized with main window.
+var trayIcon *TrayIcon
+
+// NewTrayIcon create tray icon bound to main window.
+// Icon is hidden until enabled in preferences.
+func NewTrayIcon(win *gtk.ApplicationWindow, profile *gtk.ComboBox,
+	backupSync *BackupSessionStatus, enabled bool) (*TrayIcon, error) {
+
+	cstr := C.CString(TRAY_IDLE_ICON)
+	defer C.free(unsafe.Pointer(cstr))
+	icon := C._gtk_status_icon_new_from_icon_name(cstr)
+	if icon == nil {
+		return nil, errors.New("can't create GtkStatusIcon")
+	}
+	// Go object own GtkStatusIcon instance now.
+	obj := glib.Take(unsafe.Pointer(icon))
+	C.g_object_unref(C.gpointer(icon))
+
+	v := &TrayIcon{icon: icon, obj: obj, win: win, profile: profile,
+		backupSync: backupSync}
+	cstr2 := C.CString(core.GetAppTitle())
+	defer C.free(unsafe.Pointer(cstr2))
+	C._gtk_status_icon_set_title(icon, cstr2)
+	v.updateTooltip(nil)
+
+	// Left click restore main window.
+	_, err := obj.Connect("activate", func() {
+		v.ShowMainWindow()
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Right click show tray menu.
+	_, err = obj.Connect("popup-menu", func() {
+		err := v.popupMenu()
+		if err != nil {
+			lg.Fatal(err)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	v.SetEnabled(enabled)
+	return v, nil
+}
+
+// SetEnabled show or hide tray icon.
+func (v *TrayIcon) SetEnabled(enabled bool) {
+	v.Lock()
+	v.enabled = enabled
+	v.Unlock()
+	C._gtk_status_icon_set_visible(v.icon, gboolean(enabled))
+}
+
+// IsEnabled verify that tray icon is shown, so main
+// window could be hidden instead of application exit.
+func (v *TrayIcon) IsEnabled() bool {
+	v.Lock()
+	defer v.Unlock()
+	return v.enabled
+}
+
+// ShowMainWindow restore main window hidden to tray.
+func (v *TrayIcon) ShowMainWindow() {
+	v.win.Present()
+}
+
+// SetProgress display backup session progress in tooltip.
+// Should be called from GTK+ main loop.
+func (v *TrayIcon) SetProgress(profileName string, progress *float32) {
+	v.Lock()
+	started := v.runningProfile == ""
+	v.runningProfile = profileName
+	v.Unlock()
+	if started {
+		v.setIconName(TRAY_RUNNING_ICON)
+	}
+	v.updateTooltip(progress)
+}
+
+// SetIdle reset tray icon state once backup session is over.
+// Should be called from GTK+ main loop.
+func (v *TrayIcon) SetIdle() {
+	v.Lock()
+	v.runningProfile = ""
+	v.Unlock()
+	v.setIconName(TRAY_IDLE_ICON)
+	v.updateTooltip(nil)
+}
+
+func (v *TrayIcon) setIconName(iconName string) {
+	cstr := C.CString(iconName)
+	defer C.free(unsafe.Pointer(cstr))
+	C._gtk_status_icon_set_from_icon_name(v.icon, cstr)
+}
+
+func (v *TrayIcon) updateTooltip(progress *float32) {
+	v.Lock()
+	profileName := v.runningProfile
+	v.Unlock()
+	text := core.GetAppTitle()
+	if profileName != "" {
+		var percent string
+		if progress != nil {
+			percent = locale.N(float64(*progress)*100, 0) + "%"
+		} else {
+			percent = "…"
+		}
+		text = locale.T(MsgTrayIconBackupProgressHint,
+			struct{ AppTitle, ProfileName, Progress string }{
+				AppTitle: text, ProfileName: profileName, Progress: percent})
+	}
+	cstr := C.CString(text)
+	defer C.free(unsafe.Pointer(cstr))
+	C._gtk_status_icon_set_tooltip_text(v.icon, cstr)
+}
+
+// popupMenu build tray menu with actual profile list and backup status.
+func (v *TrayIcon) popupMenu() error {
+	menu, err := gtk.MenuNew()
+	if err != nil {
+		return err
+	}
+
+	item, err := gtk.MenuItemNewWithLabel(locale.T(MsgTrayIconShowMainWindowCaption, nil))
+	if err != nil {
+		return err
+	}
+	_, err = item.Connect("activate", func() {
+		v.ShowMainWindow()
+	})
+	if err != nil {
+		return err
+	}
+	menu.Append(item)
+
+	div, err := gtk.SeparatorMenuItemNew()
+	if err != nil {
+		return err
+	}
+	menu.Append(div)
+
+	profiles, err := getProfileList()
+	if err != nil {
+		return err
+	}
+	running := v.backupSync.IsRunning()
+	runningID := v.profile.GetActiveID()
+	for _, profile := range profiles {
+		if profile.key == "" {
+			continue
+		}
+		profileID := profile.key
+		var caption string
+		if running && profileID == runningID {
+			caption = locale.T(MsgTrayIconStopBackupCaption,
+				struct{ ProfileName string }{ProfileName: profile.value})
+		} else {
+			caption = locale.T(MsgTrayIconStartBackupCaption,
+				struct{ ProfileName string }{ProfileName: profile.value})
+		}
+		item, err := gtk.MenuItemNewWithLabel(caption)
+		if err != nil {
+			return err
+		}
+		// Only one backup session could run at a time.
+		item.SetSensitive(!running || profileID == runningID)
+		_, err = item.Connect("activate", func() {
+			var err error
+			if v.backupSync.IsRunning() {
+				// Confirmation dialog require visible main window.
+				v.ShowMainWindow()
+				err = activateAction(v.win, "StopBackupAction")
+			} else {
+				v.profile.SetActiveID(profileID)
+				err = activateAction(v.win, "RunBackupAction")
+			}
+			if err != nil {
+				lg.Fatal(err)
+			}
+		})
+		if err != nil {
+			return err
+		}
+		menu.Append(item)
+	}
+
+	div, err = gtk.SeparatorMenuItemNew()
+	if err != nil {
+		return err
+	}
+	menu.Append(div)
+
+	item, err = gtk.MenuItemNewWithLabel(locale.T(MsgTrayIconQuitCaption, nil))
+	if err != nil {
+		return err
+	}
+	_, err = item.Connect("activate", func() {
+		v.ShowMainWindow()
+		err := activateAction(v.win, "QuitAction")
+		if err != nil {
+			lg.Fatal(err)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	menu.Append(item)
+
+	menu.ShowAll()
+	// Keep reference until next popup.
+	v.menu = menu
+	C._gtk_status_icon_popup_menu(v.icon, (*C.GtkMenu)(unsafe.Pointer(menu.GObject)))
+	return nil
+}
+
+func gboolean(b bool) C.gboolean {
+	if b {
+		return C.gboolean(1)
+	}
+	return C.gboolean(0)
+}
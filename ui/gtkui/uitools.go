@@ -37,6 +37,19 @@ func SetupLabelJustifyRight(caption string) (*gtk.Label, error) {
 	return lbl, nil
 }
 
+// SetupLabelMnemonicJustifyRight create GtkLabel with justification to the right by default.
+// Underscore in the caption point to mnemonic character, which activate widget
+// assigned via SetMnemonicWidget call.
+func SetupLabelMnemonicJustifyRight(caption string) (*gtk.Label, error) {
+	lbl, err := gtk.LabelNewWithMnemonic(caption)
+	if err != nil {
+		return nil, err
+	}
+	lbl.SetHAlign(gtk.ALIGN_END)
+	lbl.SetJustify(gtk.JUSTIFY_RIGHT)
+	return lbl, nil
+}
+
 // SetupLabelJustifyLeft create GtkLabel with justification to the left by default.
 func SetupLabelJustifyLeft(caption string) (*gtk.Label, error) {
 	lbl, err := gtk.LabelNew(caption)
@@ -0,0 +1,138 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package gtkui
+
+import (
+	"context"
+	"sync"
+
+	"github.com/d2r2/go-rsync/core"
+	"github.com/d2r2/go-rsync/locale"
+	"github.com/d2r2/gotk3/gtk"
+)
+
+// Latest release is queried once per application run,
+// since main window might be recreated many times.
+var (
+	latestReleaseOnce sync.Once
+	latestRelease     *core.ReleaseInfo
+)
+
+// getLatestReleaseOnce query GitHub for the latest release on first
+// call, and return cached result afterwards (nil, if check failed).
+func getLatestReleaseOnce(ctx context.Context) *core.ReleaseInfo {
+	latestReleaseOnce.Do(func() {
+		release, err := core.GetLatestRelease(ctx)
+		if err != nil {
+			lg.Debugf("Update check failed: %v", err)
+			return
+		}
+		latestRelease = release
+	})
+	return latestRelease
+}
+
+// UpdateBar is a non-modal message shown at the top of the main window,
+// when newer application version is published on GitHub.
+type UpdateBar struct {
+	bar         *gtk.InfoBar
+	label       *gtk.Label
+	appSettings *SettingsStore
+	release     *core.ReleaseInfo
+	destroyed   bool
+}
+
+// NewUpdateBar create hidden update bar for the window specified.
+func NewUpdateBar(win *gtk.Window, appSettings *SettingsStore) (*UpdateBar, error) {
+	bar, err := gtk.InfoBarNew()
+	if err != nil {
+		return nil, err
+	}
+	bar.SetMessageType(gtk.MESSAGE_INFO)
+	bar.SetShowCloseButton(true)
+	// Keep bar hidden on window ShowAll call.
+	bar.SetNoShowAll(true)
+	bar.AddButton(locale.T(MsgAppWindowUpdateBarReleaseNotesButton, nil), gtk.RESPONSE_ACCEPT)
+	lbl, err := gtk.LabelNew("")
+	if err != nil {
+		return nil, err
+	}
+	lbl.SetLineWrap(true)
+	lbl.SetHAlign(gtk.ALIGN_START)
+	content, err := bar.GetContentArea()
+	if err != nil {
+		return nil, err
+	}
+	content.Add(lbl)
+	lbl.Show()
+
+	v := &UpdateBar{bar: bar, label: lbl, appSettings: appSettings}
+	_, err = bar.Connect("response", func(bar *gtk.InfoBar, response int) {
+		if gtk.ResponseType(response) == gtk.RESPONSE_ACCEPT {
+			err := ShowUri(win, v.release.URL)
+			if err != nil {
+				reportError(win, err)
+			}
+			return
+		}
+		// Don't disturb user with the same release anymore.
+		appSettings.settings.SetString(CFG_UPDATE_DISMISSED_VERSION, v.release.Version)
+		bar.Hide()
+	})
+	if err != nil {
+		return nil, err
+	}
+	_, err = win.Connect("destroy", func() {
+		v.destroyed = true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// GetWidget return widget to place in the window.
+func (v *UpdateBar) GetWidget() *gtk.Widget {
+	return &v.bar.Widget
+}
+
+// CheckInBackground query the latest release without blocking UI,
+// if enabled in preferences, and show bar once newer version found.
+func (v *UpdateBar) CheckInBackground(ctx context.Context) {
+	if !v.appSettings.settings.GetBoolean(CFG_CHECK_FOR_UPDATES) {
+		return
+	}
+	go func() {
+		defer recoverPanic("update check")
+		release := getLatestReleaseOnce(ctx)
+		if release == nil || !release.IsNewerThanAppVersion() {
+			return
+		}
+		MustIdleAdd(func() {
+			if v.destroyed || release.Version ==
+				v.appSettings.settings.GetString(CFG_UPDATE_DISMISSED_VERSION) {
+				return
+			}
+			v.show(release)
+		})
+	}()
+}
+
+// show describe newer release available.
+func (v *UpdateBar) show(release *core.ReleaseInfo) {
+	v.release = release
+	msg := locale.T(MsgAppWindowUpdateBarNewVersion,
+		struct{ Version, CurrentVersion string }{Version: release.Version,
+			CurrentVersion: core.GetAppVersion()})
+	v.label.SetText(msg)
+	v.bar.Show()
+}
@@ -0,0 +1,107 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package gtkui
+
+import (
+	"sync"
+	"time"
+)
+
+// updateQueueIntervalMs define delay to accumulate GUI updates,
+// which limit GUI refresh rate to ~10 times per second.
+const updateQueueIntervalMs = 100
+
+// UpdateQueue batch GUI updates coming from backup process at high rate,
+// to apply them with single call in GTK+ main loop, instead of separate
+// call for each update. Otherwise intensive RSYNC logging might freeze GUI.
+// Ordered updates (session log lines) are applied all in sequence,
+// while only the latest of replaceable updates (progress) is applied.
+// Methods could be called from any goroutine.
+type UpdateQueue struct {
+	sync.Mutex
+	timer     *time.Timer
+	scheduled bool
+	calls     []func()
+	latest    func()
+	// Called in GTK+ main loop, once batch of ordered updates applied.
+	applied func()
+}
+
+// NewUpdateQueue create UpdateQueue, which call applied
+// after each batch of ordered updates.
+func NewUpdateQueue(applied func()) *UpdateQueue {
+	v := &UpdateQueue{applied: applied}
+	v.timer = time.AfterFunc(time.Millisecond*updateQueueIntervalMs, func() {
+		MustIdleAdd(v.apply)
+	})
+	v.timer.Stop()
+	return v
+}
+
+// Add append update, which is applied in order with others.
+func (v *UpdateQueue) Add(call func()) {
+	v.Lock()
+	defer v.Unlock()
+	v.calls = append(v.calls, call)
+	v.schedule()
+}
+
+// Replace substitute previous replaceable update, not yet applied.
+// Nil call cancel update pending.
+func (v *UpdateQueue) Replace(call func()) {
+	v.Lock()
+	defer v.Unlock()
+	v.latest = call
+	if call != nil {
+		v.schedule()
+	}
+}
+
+// Flush apply pending updates without delay. Since GTK+ main loop
+// run idle calls in order, updates are applied before any
+// idle call added after Flush.
+func (v *UpdateQueue) Flush() {
+	v.Lock()
+	defer v.Unlock()
+	if v.scheduled {
+		v.timer.Stop()
+	}
+	v.scheduled = true
+	MustIdleAdd(v.apply)
+}
+
+// schedule start delay to accumulate updates, if not yet started.
+func (v *UpdateQueue) schedule() {
+	if !v.scheduled {
+		v.scheduled = true
+		v.timer.Reset(time.Millisecond * updateQueueIntervalMs)
+	}
+}
+
+// apply run pending updates. Should be called from GTK+ main loop.
+func (v *UpdateQueue) apply() {
+	v.Lock()
+	calls, latest := v.calls, v.latest
+	v.calls, v.latest = nil, nil
+	v.scheduled = false
+	v.Unlock()
+
+	for _, call := range calls {
+		call()
+	}
+	if len(calls) > 0 && v.applied != nil {
+		v.applied()
+	}
+	if latest != nil {
+		latest()
+	}
+}
@@ -334,6 +334,13 @@ func (v *ControlWithStatus) GetBox() *gtk.Box {
 	return v.box
 }
 
+// SetAccessible assign accessible name and description to the
+// composite widget and wrapped control, to be announced by screen readers.
+func (v *ControlWithStatus) SetAccessible(name, description string) {
+	SetAccessibleNameAndDescription(&v.box.Widget, name, description)
+	SetAccessibleNameAndDescription(v.control, name, description)
+}
+
 type GLibIdleCallStub struct {
 	sync.Mutex
 }
@@ -375,8 +382,13 @@ var MustIdleAddWait = func(call func()) {
 // GetBaseApplicationCSS read from assets CSS file, which
 // give UI styles used for customization of application interface.
 func GetBaseApplicationCSS() (string, error) {
+	return GetAssetsCSS("base.css")
+}
+
+// GetAssetsCSS read CSS file from application assets.
+func GetAssetsCSS(assetName string) (string, error) {
 	// Load CSS styles
-	file, err := data.Assets.Open("base.css")
+	file, err := data.Assets.Open(assetName)
 	if err != nil {
 		return "", err
 	}
@@ -421,48 +433,52 @@ func markupTooltip(status *Markup, description string) *Markup {
 	return mp
 }
 
+// validationIssueMessage format backup profile validation issue
+// in terms of main window messages.
+func validationIssueMessage(issue *backup.ValidationIssue, formatMultiline bool) string {
+	switch issue.Code {
+	case backup.ISSUE_DEST_IS_EMPTY:
+		return locale.T(MsgAppWindowDestPathIsEmptyError1, nil)
+	case backup.ISSUE_DEST_NOT_EXIST:
+		var buf bytes.Buffer
+		buf.WriteString(locale.T(MsgAppWindowDestPathIsNotExistError,
+			struct{ FolderPath string }{FolderPath: issue.Path}))
+		if formatMultiline {
+			buf.WriteString(spew.Sprintln())
+		} else {
+			buf.WriteString(" ")
+		}
+		buf.WriteString(locale.T(MsgAppWindowDestPathIsNotExistAdvise, nil))
+		return buf.String()
+	case backup.ISSUE_DEST_NOT_ACCESSIBLE:
+		return issue.Error
+	case backup.ISSUE_SOURCE_IS_EMPTY:
+		msg := locale.T(MsgAppWindowRsyncPathIsEmptyError, nil)
+		if !formatMultiline {
+			// Do not use strings.ReplaceAll() since it was implemented
+			// only in Go 1.12. Instead use strings.Replace().
+			msg = strings.Replace(msg, "\n", " ", -1)
+		}
+		return msg
+	default:
+		return issue.Message()
+	}
+}
+
 // isDestPathError verify file system path availability status.
 // Returns error, if path isn't reachable.
 func isDestPathError(destPath string, formatMultiline bool) (bool, string) {
-	if destPath == "" {
-		msg := locale.T(MsgAppWindowDestPathIsEmptyError1, nil)
-		return true, msg
-	} else {
-		_, err := os.Stat(destPath)
-		if err != nil {
-			var msg string
-			if os.IsNotExist(err) {
-				var buf bytes.Buffer
-				buf.WriteString(locale.T(MsgAppWindowDestPathIsNotExistError,
-					struct{ FolderPath string }{FolderPath: destPath}))
-				if formatMultiline {
-					buf.WriteString(spew.Sprintln())
-				} else {
-					buf.WriteString(" ")
-				}
-				buf.WriteString(locale.T(MsgAppWindowDestPathIsNotExistAdvise, nil))
-				msg = buf.String()
-			} else {
-				msg = err.Error()
-			}
-			return true, msg
-		}
+	if issue := backup.ValidateDestPath(destPath).FirstError(); issue != nil {
+		return true, validationIssueMessage(issue, formatMultiline)
 	}
 	return false, ""
 }
 
+// isModulesConfigError verify modules configuration and
+// module own destination root availability.
 func isModulesConfigError(modules []backup.Module, formatMultiline bool) (bool, string) {
-	for _, module := range modules {
-		// check for empty RSYNC source path
-		if module.SourceRsync == "" {
-			msg := locale.T(MsgAppWindowRsyncPathIsEmptyError, nil)
-			if !formatMultiline {
-				// Do not use strings.ReplaceAll() since it was implemented
-				// only in Go 1.12. Instead use strings.Replace().
-				msg = strings.Replace(msg, "\n", " ", -1)
-			}
-			return true, msg
-		}
+	if issue := backup.ValidateModules(modules, true).FirstError(); issue != nil {
+		return true, validationIssueMessage(issue, formatMultiline)
 	}
 	return false, ""
 }
@@ -230,7 +230,7 @@ func (v *UIValidator) callRun(groupLock *sync.Mutex, ctx context.Context,
 func (v *UIValidator) callEnd(groupLock *sync.Mutex, r resultsOrError) {
 	err := r.Entry.end(groupLock, r.Entry.Data, r.Results)
 	if err != nil {
-		lg.Fatal(err)
+		lg.Panic(err)
 	}
 }
 
@@ -251,6 +251,7 @@ func (v *UIValidator) runAsync(group, index string, entryList []*ValidatorEntry,
 	// from 2nd validation steps.
 	go func() {
 		defer wait.Done()
+		defer recoverPanic("validator")
 
 		terminated := false
 		for {
@@ -263,7 +264,7 @@ func (v *UIValidator) runAsync(group, index string, entryList []*ValidatorEntry,
 						lg.Debugf("Call Validator End")
 						v.callEnd(groupLock, r)
 					} else {
-						lg.Fatal(err)
+						lg.Panic(err)
 					}
 				} else {
 					lg.Debugf("Complete group %q validation 2", getFullIndex(group, index))
@@ -280,6 +281,15 @@ func (v *UIValidator) runAsync(group, index string, entryList []*ValidatorEntry,
 
 	// Run 2nd validation step.
 	go func() {
+		defer func() {
+			lg.Debugf("Complete group %q validation 1", getFullIndex(group, index))
+			close(resultCh)
+			// Wait for completion of 3rd validation step (finalizer), before exit.
+			wait.Wait()
+			v.runningContexts.RemoveContext(ctxPack.Context)
+			v.groupRunning.Remove(group, index)
+		}()
+		defer recoverPanic("validator")
 		terminated := false
 		for _, item := range entryList {
 			r := resultsOrError{Entry: item}
@@ -298,12 +308,6 @@ func (v *UIValidator) runAsync(group, index string, entryList []*ValidatorEntry,
 				break
 			}
 		}
-		lg.Debugf("Complete group %q validation 1", getFullIndex(group, index))
-		close(resultCh)
-		// Wait for completion of 3rd validation step (finalizer), before exit.
-		wait.Wait()
-		v.runningContexts.RemoveContext(ctxPack.Context)
-		v.groupRunning.Remove(group, index)
 	}()
 }
 
@@ -0,0 +1,532 @@
+//--------------------------------------------------------------------------------------------------
+// This file is a part of Gorsync Backup project (backup RSYNC frontend).
+// Copyright (c) 2017-2022 Denis Dyakov <denis.dyakov@gma**.com>
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR IMPLIED, INCLUDING
+// BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM,
+// DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//--------------------------------------------------------------------------------------------------
+
+package gtkui
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/d2r2/go-rsync/locale"
+	"github.com/d2r2/go-rsync/rsync"
+	"github.com/d2r2/gotk3/gtk"
+)
+
+// wizardSource keep source path entry of first-run wizard
+// along with state of its asynchronous validation.
+type wizardSource struct {
+	row    *gtk.Box
+	entry  *gtk.Entry
+	timer  *time.Timer
+	cancel func()
+	valid  bool
+}
+
+// FirstRunWizard guide user through creation of the first backup
+// profile: profile name, sources, destination and common defaults.
+type FirstRunWizard struct {
+	assistant       *gtk.Assistant
+	edName          *gtk.Entry
+	pageSources     *gtk.Box
+	boxSources      *gtk.Box
+	sources         []*wizardSource
+	destFolder      *gtk.FileChooserButton
+	cbDeduplication *gtk.CheckButton
+	cbNotification  *gtk.CheckButton
+	lblSummary      *gtk.Label
+}
+
+// setupWizardPage create page container with description text on top.
+func setupWizardPage(text string) (*gtk.Box, error) {
+	page, err := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 12)
+	if err != nil {
+		return nil, err
+	}
+	SetAllMargins(page, 18)
+	lbl, err := gtk.LabelNew(text)
+	if err != nil {
+		return nil, err
+	}
+	lbl.SetLineWrap(true)
+	lbl.SetXAlign(0)
+	page.PackStart(lbl, false, false, 0)
+	return page, nil
+}
+
+// CreateFirstRunWizard build assistant shown on first launch, when
+// no any profile is configured yet. Once profile is saved, created
+// callback receive new profile identifier.
+func CreateFirstRunWizard(mainWin *gtk.ApplicationWindow,
+	created func(profileID string)) (*gtk.Assistant, error) {
+
+	v := &FirstRunWizard{}
+	var err error
+	v.assistant, err = gtk.AssistantNew()
+	if err != nil {
+		return nil, err
+	}
+	v.assistant.SetTransientFor(mainWin)
+	v.assistant.SetModal(true)
+	v.assistant.SetTitle(locale.T(MsgFirstRunWizardTitle, nil))
+	v.assistant.SetDefaultSize(640, 420)
+
+	for _, create := range []func() error{v.createNamePage, v.createSourcesPage,
+		v.createDestinationPage, v.createDefaultsPage, v.createConfirmPage} {
+		err = create()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = v.assistant.Connect("prepare", func(assistant *gtk.Assistant) {
+		if assistant.GetCurrentPage() == assistant.GetNPages()-1 {
+			v.updateSummary()
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	_, err = v.assistant.Connect("apply", func() {
+		profileID, err := v.save()
+		if err != nil {
+			lg.Fatal(err)
+		}
+		created(profileID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	_, err = v.assistant.Connect("cancel", func(assistant *gtk.Assistant) {
+		assistant.Destroy()
+	})
+	if err != nil {
+		return nil, err
+	}
+	_, err = v.assistant.Connect("close", func(assistant *gtk.Assistant) {
+		assistant.Destroy()
+	})
+	if err != nil {
+		return nil, err
+	}
+	_, err = v.assistant.Connect("destroy", func() {
+		for _, src := range v.sources {
+			src.stop()
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return v.assistant, nil
+}
+
+// createNamePage add page to specify profile name.
+func (v *FirstRunWizard) createNamePage() error {
+	page, err := setupWizardPage(locale.T(MsgFirstRunWizardNamePageText, nil))
+	if err != nil {
+		return err
+	}
+	v.edName, err = gtk.EntryNew()
+	if err != nil {
+		return err
+	}
+	v.edName.SetText(locale.T(MsgFirstRunWizardDefaultProfileName, nil))
+	page.PackStart(v.edName, false, false, 0)
+	_, err = v.edName.Connect("changed", func(entry *gtk.Entry) {
+		v.assistant.SetPageComplete(page, strings.TrimSpace(v.getProfileName()) != "")
+	})
+	if err != nil {
+		return err
+	}
+	v.assistant.AppendPage(page)
+	v.assistant.SetPageType(page, gtk.ASSISTANT_PAGE_INTRO)
+	v.assistant.SetPageTitle(page, locale.T(MsgFirstRunWizardNamePageTitle, nil))
+	v.assistant.SetPageComplete(page, true)
+	return nil
+}
+
+// createSourcesPage add page to specify one or more sources to backup.
+func (v *FirstRunWizard) createSourcesPage() error {
+	var err error
+	v.pageSources, err = setupWizardPage(locale.T(MsgFirstRunWizardSourcesPageText, nil))
+	if err != nil {
+		return err
+	}
+	v.boxSources, err = gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 6)
+	if err != nil {
+		return err
+	}
+	v.pageSources.PackStart(v.boxSources, false, false, 0)
+	btnAdd, err := gtk.ButtonNewWithLabel(locale.T(MsgFirstRunWizardAddSourceButton, nil))
+	if err != nil {
+		return err
+	}
+	btnAdd.SetHAlign(gtk.ALIGN_START)
+	_, err = btnAdd.Connect("clicked", func() {
+		err := v.addSource()
+		if err != nil {
+			lg.Fatal(err)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	v.pageSources.PackStart(btnAdd, false, false, 0)
+	err = v.addSource()
+	if err != nil {
+		return err
+	}
+	v.assistant.AppendPage(v.pageSources)
+	v.assistant.SetPageType(v.pageSources, gtk.ASSISTANT_PAGE_CONTENT)
+	v.assistant.SetPageTitle(v.pageSources, locale.T(MsgFirstRunWizardSourcesPageTitle, nil))
+	return nil
+}
+
+// addSource append source path entry, which is validated
+// with RSYNC in background, once text is changed.
+func (v *FirstRunWizard) addSource() error {
+	src := &wizardSource{}
+	var err error
+	src.row, err = gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 6)
+	if err != nil {
+		return err
+	}
+	src.entry, err = gtk.EntryNew()
+	if err != nil {
+		return err
+	}
+	src.entry.SetPlaceholderText(locale.T(MsgFirstRunWizardSourcePlaceholder, nil))
+	src.entry.SetHExpand(true)
+	src.row.PackStart(src.entry, true, true, 0)
+	btnRemove, err := SetupButtonWithThemedImage("list-remove-symbolic")
+	if err != nil {
+		return err
+	}
+	btnRemove.SetTooltipText(locale.T(MsgFirstRunWizardRemoveSourceHint, nil))
+	src.row.PackStart(btnRemove, false, false, 0)
+
+	src.timer = time.AfterFunc(time.Millisecond*1000, func() {
+		MustIdleAdd(func() {
+			v.validateSource(src)
+		})
+	})
+	src.timer.Stop()
+	_, err = src.entry.Connect("changed", func() {
+		src.stop()
+		src.valid = false
+		v.updateSourcesComplete()
+		RestartTimer(src.timer, 1000)
+	})
+	if err != nil {
+		return err
+	}
+	_, err = btnRemove.Connect("clicked", func() {
+		src.stop()
+		for i, item := range v.sources {
+			if item == src {
+				v.sources = append(v.sources[:i], v.sources[i+1:]...)
+				break
+			}
+		}
+		src.row.Destroy()
+		v.updateSourcesComplete()
+	})
+	if err != nil {
+		return err
+	}
+
+	v.sources = append(v.sources, src)
+	v.boxSources.PackStart(src.row, false, false, 0)
+	v.boxSources.ShowAll()
+	v.updateSourcesComplete()
+	return nil
+}
+
+// stop cancel pending or running source validation.
+func (v *wizardSource) stop() {
+	v.timer.Stop()
+	if v.cancel != nil {
+		v.cancel()
+		v.cancel = nil
+	}
+}
+
+// validateSource run RSYNC in background to verify that source is reachable.
+func (v *FirstRunWizard) validateSource(src *wizardSource) {
+	text, err := src.entry.GetText()
+	if err != nil {
+		lg.Fatal(err)
+	}
+	rsyncURL := strings.TrimSpace(text)
+	err = RemoveStyleClassesAll(&src.entry.Widget)
+	if err != nil {
+		lg.Fatal(err)
+	}
+	if rsyncURL == "" {
+		src.entry.SetIconFromIconName(gtk.ENTRY_ICON_SECONDARY, "")
+		src.entry.SetTooltipText("")
+		return
+	}
+	src.entry.SetIconFromIconName(gtk.ENTRY_ICON_SECONDARY, STOCK_SYNCHRONIZING_ICON)
+	src.entry.SetTooltipText(locale.T(MsgFirstRunWizardSourceValidating, nil))
+	err = AddStyleClass(&src.entry.Widget, "entry-image-right-spin")
+	if err != nil {
+		lg.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	src.cancel = cancel
+	go func() {
+		err := rsync.GetPathStatus(ctx, nil, rsyncURL, false)
+		if ctx.Err() != nil {
+			return
+		}
+		MustIdleAdd(func() {
+			// Validation might be restarted meantime.
+			if ctx.Err() != nil {
+				return
+			}
+			src.cancel = nil
+			cancel()
+			err2 := RemoveStyleClassesAll(&src.entry.Widget)
+			if err2 != nil {
+				lg.Fatal(err2)
+			}
+			if err != nil {
+				src.entry.SetIconFromIconName(gtk.ENTRY_ICON_SECONDARY, STOCK_IMPORTANT_ICON)
+				src.entry.SetTooltipText(rsync.FormatErrorWithSuggestion(err))
+				err2 = AddStyleClass(&src.entry.Widget, "entry-image-right-error")
+				if err2 != nil {
+					lg.Fatal(err2)
+				}
+			} else {
+				src.entry.SetIconFromIconName(gtk.ENTRY_ICON_SECONDARY, STOCK_OK_ICON)
+				src.entry.SetTooltipText("")
+				src.valid = true
+			}
+			v.updateSourcesComplete()
+		})
+	}()
+}
+
+// updateSourcesComplete let user go forward, once
+// all specified sources are verified successfully.
+func (v *FirstRunWizard) updateSourcesComplete() {
+	complete := len(v.sources) > 0
+	for _, src := range v.sources {
+		complete = complete && src.valid
+	}
+	v.assistant.SetPageComplete(v.pageSources, complete)
+}
+
+// createDestinationPage add page to select destination folder.
+func (v *FirstRunWizard) createDestinationPage() error {
+	page, err := setupWizardPage(locale.T(MsgFirstRunWizardDestPageText, nil))
+	if err != nil {
+		return err
+	}
+	v.destFolder, err = gtk.FileChooserButtonNew("Select destination folder",
+		gtk.FILE_CHOOSER_ACTION_SELECT_FOLDER)
+	if err != nil {
+		return err
+	}
+	page.PackStart(v.destFolder, false, false, 0)
+	_, err = v.destFolder.Connect("file-set", func(fcb *gtk.FileChooserButton) {
+		_, err := os.Stat(fcb.GetFilename())
+		v.assistant.SetPageComplete(page, err == nil)
+	})
+	if err != nil {
+		return err
+	}
+	v.assistant.AppendPage(page)
+	v.assistant.SetPageType(page, gtk.ASSISTANT_PAGE_CONTENT)
+	v.assistant.SetPageTitle(page, locale.T(MsgFirstRunWizardDestPageTitle, nil))
+	return nil
+}
+
+// createDefaultsPage add page to choose deduplication and notification defaults.
+func (v *FirstRunWizard) createDefaultsPage() error {
+	page, err := setupWizardPage(locale.T(MsgFirstRunWizardDefaultsPageText, nil))
+	if err != nil {
+		return err
+	}
+	appSettings, err := NewSettingsStore(SETTINGS_SCHEMA_ID, SETTINGS_SCHEMA_PATH, nil)
+	if err != nil {
+		return err
+	}
+	v.cbDeduplication, err = gtk.CheckButtonNewWithLabel(
+		locale.T(MsgFirstRunWizardDeduplicationCaption, nil))
+	if err != nil {
+		return err
+	}
+	v.cbDeduplication.SetTooltipText(locale.T(MsgPrefDlgUsePreviousBackupForDedupHint, nil))
+	v.cbDeduplication.SetActive(appSettings.settings.GetBoolean(CFG_ENABLE_USE_OF_PREVIOUS_BACKUP))
+	page.PackStart(v.cbDeduplication, false, false, 0)
+	v.cbNotification, err = gtk.CheckButtonNewWithLabel(
+		locale.T(MsgPrefDlgPerformDesktopNotificationCaption, nil))
+	if err != nil {
+		return err
+	}
+	v.cbNotification.SetTooltipText(locale.T(MsgPrefDlgPerformDesktopNotificationHint, nil))
+	v.cbNotification.SetActive(appSettings.settings.GetBoolean(CFG_PERFORM_DESKTOP_NOTIFICATION))
+	page.PackStart(v.cbNotification, false, false, 0)
+	v.assistant.AppendPage(page)
+	v.assistant.SetPageType(page, gtk.ASSISTANT_PAGE_CONTENT)
+	v.assistant.SetPageTitle(page, locale.T(MsgFirstRunWizardDefaultsPageTitle, nil))
+	v.assistant.SetPageComplete(page, true)
+	return nil
+}
+
+// createConfirmPage add final page with summary of profile to create.
+func (v *FirstRunWizard) createConfirmPage() error {
+	page, err := setupWizardPage(locale.T(MsgFirstRunWizardConfirmPageText, nil))
+	if err != nil {
+		return err
+	}
+	v.lblSummary, err = gtk.LabelNew("")
+	if err != nil {
+		return err
+	}
+	v.lblSummary.SetLineWrap(true)
+	v.lblSummary.SetXAlign(0)
+	v.lblSummary.SetSelectable(true)
+	page.PackStart(v.lblSummary, false, false, 0)
+	v.assistant.AppendPage(page)
+	v.assistant.SetPageType(page, gtk.ASSISTANT_PAGE_CONFIRM)
+	v.assistant.SetPageTitle(page, locale.T(MsgFirstRunWizardConfirmPageTitle, nil))
+	v.assistant.SetPageComplete(page, true)
+	return nil
+}
+
+// getProfileName return profile name typed by user.
+func (v *FirstRunWizard) getProfileName() string {
+	name, err := v.edName.GetText()
+	if err != nil {
+		lg.Fatal(err)
+	}
+	return strings.TrimSpace(name)
+}
+
+// getSourcePaths return RSYNC source paths typed by user.
+func (v *FirstRunWizard) getSourcePaths() []string {
+	var paths []string
+	for _, src := range v.sources {
+		text, err := src.entry.GetText()
+		if err != nil {
+			lg.Fatal(err)
+		}
+		paths = append(paths, strings.TrimSpace(text))
+	}
+	return paths
+}
+
+// updateSummary describe profile to create on confirmation page.
+func (v *FirstRunWizard) updateSummary() {
+	var buf bytes.Buffer
+	buf.WriteString(locale.T(MsgFirstRunWizardSummaryProfile,
+		struct{ ProfileName string }{ProfileName: v.getProfileName()}))
+	for _, sourcePath := range v.getSourcePaths() {
+		buf.WriteString("\n")
+		buf.WriteString(locale.T(MsgFirstRunWizardSummarySource,
+			struct{ Path string }{Path: sourcePath}))
+	}
+	buf.WriteString("\n")
+	buf.WriteString(locale.T(MsgFirstRunWizardSummaryDestination,
+		struct{ Path string }{Path: v.destFolder.GetFilename()}))
+	v.lblSummary.SetText(buf.String())
+}
+
+// getWizardDestSubpath build unique destination subpath
+// from the last element of RSYNC source path.
+func getWizardDestSubpath(sourcePath string, index int, used map[string]bool) string {
+	name := path.Base(strings.TrimRight(sourcePath, "/"))
+	if i := strings.LastIndexAny(name, ":"); i != -1 {
+		name = name[i+1:]
+	}
+	if name == "" || name == "." || name == "/" {
+		name = fmt.Sprintf("source%d", index+1)
+	}
+	subpath := name
+	for i := 2; used[subpath]; i++ {
+		subpath = fmt.Sprintf("%s_%d", name, i)
+	}
+	used[subpath] = true
+	return subpath
+}
+
+// save create profile with sources and destination specified,
+// and apply defaults chosen by user to application settings.
+func (v *FirstRunWizard) save() (string, error) {
+	appSettings, err := NewSettingsStore(SETTINGS_SCHEMA_ID, SETTINGS_SCHEMA_PATH, nil)
+	if err != nil {
+		return "", err
+	}
+	appSettings.settings.SetBoolean(CFG_ENABLE_USE_OF_PREVIOUS_BACKUP, v.cbDeduplication.GetActive())
+	appSettings.settings.SetBoolean(CFG_PERFORM_DESKTOP_NOTIFICATION, v.cbNotification.GetActive())
+
+	profileID, err := appSettings.NewSettingsArray(CFG_BACKUP_LIST).AddNode()
+	if err != nil {
+		return "", err
+	}
+	profileSettings, err := getProfileSettings(appSettings, profileID, nil)
+	if err != nil {
+		return "", err
+	}
+	_, err = assignProfileUUID(profileSettings)
+	if err != nil {
+		return "", err
+	}
+	profileSettings.settings.SetString(CFG_PROFILE_NAME, v.getProfileName())
+	profileSettings.settings.SetString(CFG_PROFILE_DEST_ROOT_PATH, v.destFolder.GetFilename())
+
+	sarr := profileSettings.NewSettingsArray(CFG_SOURCE_LIST)
+	used := make(map[string]bool)
+	for i, sourcePath := range v.getSourcePaths() {
+		sourceID, err := sarr.AddNode()
+		if err != nil {
+			return "", err
+		}
+		sourceSettings, err := getBackupSourceSettings(profileSettings, sourceID, nil)
+		if err != nil {
+			return "", err
+		}
+		sourceSettings.settings.SetString(CFG_MODULE_RSYNC_SOURCE_PATH, sourcePath)
+		sourceSettings.settings.SetString(CFG_MODULE_DEST_SUBPATH,
+			getWizardDestSubpath(sourcePath, i, used))
+	}
+	return profileID, nil
+}
+
+// showFirstRunWizard run first-run wizard and select
+// created profile in main window.
+func showFirstRunWizard(win *gtk.ApplicationWindow, profile *gtk.ComboBox) error {
+	assistant, err := CreateFirstRunWizard(win, func(profileID string) {
+		lst, err := getProfileList()
+		if err != nil {
+			lg.Fatal(err)
+		}
+		err = UpdateNameValueCombo(profile, lst)
+		if err != nil {
+			lg.Fatal(err)
+		}
+		profile.SetActiveID(profileID)
+	})
+	if err != nil {
+		return err
+	}
+	assistant.ShowAll()
+	return nil
+}